@@ -2,27 +2,38 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"strings"
 	"time"
+	_ "time/tzdata" // the runtime image ships without a zoneinfo database
 
-	"github.com/redis/go-redis/v9"
 	"github.com/rogerio-castellano/inventory-tracker/internal/auth"
+	"github.com/rogerio-castellano/inventory-tracker/internal/avscan"
+	"github.com/rogerio-castellano/inventory-tracker/internal/consignment"
 	"github.com/rogerio-castellano/inventory-tracker/internal/db"
+	"github.com/rogerio-castellano/inventory-tracker/internal/expiry"
+	"github.com/rogerio-castellano/inventory-tracker/internal/fxrate"
 	"github.com/rogerio-castellano/inventory-tracker/internal/http/ban"
 	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
 	mw "github.com/rogerio-castellano/inventory-tracker/internal/http/middleware"
 	rl "github.com/rogerio-castellano/inventory-tracker/internal/http/rate_limiter"
 	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+	"github.com/rogerio-castellano/inventory-tracker/internal/incident"
+	"github.com/rogerio-castellano/inventory-tracker/internal/promotion"
 	"github.com/rogerio-castellano/inventory-tracker/internal/redissvc"
 	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+	"github.com/rogerio-castellano/inventory-tracker/internal/reservation"
+	"github.com/rogerio-castellano/inventory-tracker/internal/security"
+	"github.com/rogerio-castellano/inventory-tracker/internal/snapshot"
+	"github.com/rogerio-castellano/inventory-tracker/internal/storage"
+	"github.com/rogerio-castellano/inventory-tracker/internal/usage"
+	"github.com/rogerio-castellano/inventory-tracker/internal/webhook"
 	"github.com/spf13/viper"
 )
 
-var rdb = redis.NewClient(&redis.Options{
-	Addr: "inventory-redis:6379",
-})
 var ctx = context.Background()
 
 // @title Inventory Tracker API
@@ -38,6 +49,25 @@ func main() {
 	go ban.StartDailyBanSummary(time.Hour * 24)
 	go rl.StartVisitorCleanupLoop()
 
+	viper.SetConfigName("config") // no extension
+	viper.SetConfigType("yaml")
+	configPath := os.Getenv("CONFIG_PATH")
+	if configPath == "" {
+		configPath = "." // default
+	}
+	viper.AddConfigPath(configPath)
+	if err := viper.ReadInConfig(); err != nil {
+		log.Fatalf("Error reading config file: %v", err)
+	}
+	viper.AutomaticEnv()
+
+	viper.SetDefault("REDIS_ADDRS", "inventory-redis:6379")
+	rdb := redissvc.NewClient(redissvc.Config{
+		Addrs:            splitList(viper.GetString("REDIS_ADDRS")),
+		MasterName:       viper.GetString("REDIS_SENTINEL_MASTER"),
+		Password:         viper.GetString("REDIS_PASSWORD"),
+		SentinelPassword: viper.GetString("REDIS_SENTINEL_PASSWORD"),
+	})
 	if err := rdb.Ping(ctx).Err(); err != nil {
 		log.Fatalf("Could not connect to Redis: %v", err)
 	}
@@ -47,6 +77,7 @@ func main() {
 	handlers.SetRedisService(redisService)
 	ban.SetRedisService(redisService)
 	mw.SetRedisService(redisService)
+	usage.SetRedisService(redisService)
 
 	database, err := db.Connect()
 	if err != nil {
@@ -54,24 +85,126 @@ func main() {
 	}
 	defer database.Close()
 
-	handlers.SetProductRepo(repo.NewPostgresProductRepository(database))
+	incidentRepo := repo.NewPostgresIncidentRepository(database)
+	handlers.SetIncidentRepo(incidentRepo)
+	incident.Start(incidentRepo)
+	eventLogRepo := repo.NewPostgresEventLogRepository(database)
+	handlers.SetEventLogRepo(eventLogRepo)
+	security.SetOutbox(eventLogRepo)
+
+	productRepo := repo.NewPostgresProductRepository(database)
+	handlers.SetProductRepo(productRepo)
+	handlers.SetProductChangeRepo(repo.NewPostgresProductChangeRepository(database))
+	handlers.SetWarehouseRepo(repo.NewPostgresWarehouseRepository(database))
 	handlers.SetMovementRepo(repo.NewPostgresMovementRepository(database))
 	handlers.SetUserRepo(repo.NewPostgresUserRepository(database))
 	handlers.SetMetricsRepo(repo.NewPostgresMetricsRepository(database))
+	handlers.SetPeriodRepo(repo.NewPostgresPeriodRepository(database))
+	handlers.SetAuditRepo(repo.NewPostgresAuditRepository(database))
+	handlers.SetDocumentRepo(repo.NewPostgresDocumentRepository(database))
+	handlers.SetMovementAttachmentRepo(repo.NewPostgresMovementAttachmentRepository(database))
+	handlers.SetWriteOffRepo(repo.NewPostgresWriteOffRepository(database))
+	handlers.SetReturnRepo(repo.NewPostgresReturnRepository(database))
+	handlers.SetWorkOrderRepo(repo.NewPostgresWorkOrderRepository(database))
+	handlers.SetSyncRepo(repo.NewPostgresSyncRepository(database))
+	handlers.SetMappingRepo(repo.NewPostgresMappingRepository(database))
+	snapshotRepo := repo.NewPostgresSnapshotRepository(database)
+	handlers.SetSnapshotRepo(snapshotRepo)
+	handlers.SetTaxClassRepo(repo.NewPostgresTaxClassRepository(database))
+	handlers.SetValidationPolicyRepo(repo.NewPostgresValidationPolicyRepository(database))
+	handlers.SetRuntimeConfigRepo(repo.NewPostgresRuntimeConfigRepository(database))
+	handlers.SetSubstituteRepo(repo.NewPostgresSubstituteRepository(database))
+	handlers.SetPriceListRepo(repo.NewPostgresPriceListRepository(database))
+	handlers.SetBinRepo(repo.NewPostgresBinRepository(database))
+	handlers.SetCapacityRepo(repo.NewPostgresCapacityRepository(database))
+	handlers.SetASNRepo(repo.NewPostgresASNRepository(database))
+	handlers.SetLandedCostRepo(repo.NewPostgresLandedCostRepository(database))
 
-	viper.SetConfigName("config") // no extension
-	viper.SetConfigType("yaml")
-	configPath := os.Getenv("CONFIG_PATH")
-	if configPath == "" {
-		configPath = "." // default
+	promotionRepo := repo.NewPostgresPromotionRepository(database)
+	handlers.SetPromotionRepo(promotionRepo)
+	go promotion.StartScheduler(promotionRepo, time.Minute)
+
+	consignmentRepo := repo.NewPostgresConsignmentRepository(database)
+	handlers.SetConsignmentRepo(consignmentRepo)
+	go consignment.StartMonthlySettlement(consignmentRepo)
+
+	lotRepo := repo.NewPostgresLotRepository(database)
+	handlers.SetLotRepo(lotRepo)
+	go expiry.StartDailyNotifier(lotRepo, productRepo, 30*24*time.Hour)
+
+	reservationRepo := repo.NewPostgresReservationRepository(database)
+	handlers.SetReservationRepo(reservationRepo)
+	go reservation.StartExpiryWorker(reservationRepo, time.Minute)
+
+	handlers.SetSupplierRepo(repo.NewPostgresSupplierRepository(database))
+
+	webhookRepo := repo.NewPostgresWebhookRepository(database)
+	handlers.SetWebhookRepo(webhookRepo)
+	webhook.SetRepo(webhookRepo)
+	go webhook.StartDispatcher(webhookRepo, 30*time.Second)
+	handlers.SetLoginEventRepo(repo.NewPostgresLoginEventRepository(database))
+	handlers.SetAccessGrantRepo(repo.NewPostgresAccessGrantRepository(database))
+	handlers.SetApprovalRepo(repo.NewPostgresApprovalRepository(database))
+	handlers.SetExportRecipientRepo(repo.NewPostgresExportRecipientRepository(database))
+	handlers.SetAdjustmentLimitRepo(repo.NewPostgresAdjustmentLimitRepository(database))
+	handlers.SetPriceRulesRepo(repo.NewPostgresPriceRulesRepository(database))
+	handlers.SetProductFlagRepo(repo.NewPostgresProductFlagRepository(database))
+
+	usageRepo := repo.NewPostgresUsageRepository(database)
+	handlers.SetUsageRepo(usageRepo)
+	go usage.StartAggregator(usageRepo, time.Hour)
+
+	auth.SetSecret(viper.GetString("JWT_SECRET"))
+	if key := viper.GetString("AUDIT_DIGEST_KEY"); key != "" {
+		repo.SetAuditDigestKey(key)
+	} else {
+		log.Println("⚠️ AUDIT_DIGEST_KEY is not set; audit digests are keyed with JWT_SECRET")
+		repo.SetAuditDigestKey(viper.GetString("JWT_SECRET"))
 	}
-	viper.AddConfigPath(configPath)
-	err = viper.ReadInConfig()
+	auth.SetSessionIdleTimeout(time.Duration(viper.GetInt("SESSION_IDLE_TIMEOUT_HOURS")) * time.Hour)
+	handlers.SetSeedingEnabled(viper.GetString("APP_ENV") == "development")
+	handlers.SetSandboxEnabled(viper.GetBool("SANDBOX_MODE"))
+	handlers.SetQueryDiagnosticsEnabled(viper.GetBool("QUERY_DIAGNOSTICS"))
+	handlers.SetProductQuota(viper.GetInt("PRODUCT_QUOTA"))
+	handlers.SetDeleteApprovalRequired(viper.GetBool("PRODUCT_DELETE_APPROVAL"))
+	handlers.SetPasswordMaxAge(time.Duration(viper.GetInt("PASSWORD_MAX_AGE_DAYS")) * 24 * time.Hour)
+	viper.SetDefault("TENANT", "default")
+	handlers.SetTenant(viper.GetString("TENANT"))
+	if err := handlers.LoadRuntimeConfig(); err != nil {
+		log.Fatalf("❌ Could not load applied configuration: %v", err)
+	}
+	handlers.SetSlackCommands(viper.GetString("SLACK_SIGNING_SECRET"), viper.GetString("DASHBOARD_URL"))
+	viper.SetDefault("RATE_LIMIT_FAIL_MODE", rl.FailOpen)
+	if err := rl.SetFailMode(viper.GetString("RATE_LIMIT_FAIL_MODE")); err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	documentStore, err := newDocumentStore()
 	if err != nil {
-		log.Fatalf("Error reading config file: %v", err)
+		log.Fatalf("❌ Could not prepare document storage: %v", err)
+	}
+	handlers.SetDocumentStore(documentStore)
+	fileScanner, err := newFileScanner()
+	if err != nil {
+		log.Fatalf("❌ Could not configure file scanning: %v", err)
+	}
+	handlers.SetFileScanner(fileScanner)
+	exchangeRates, err := newExchangeRates()
+	if err != nil {
+		log.Fatalf("❌ Could not configure exchange rates: %v", err)
+	}
+	handlers.SetExchangeRates(exchangeRates)
+
+	viper.SetDefault("SNAPSHOT_RETENTION_DAYS", 90)
+	viper.SetDefault("SNAPSHOT_RETENTION_MONTHS", 24)
+	go snapshot.StartDailyScheduler(snapshotRepo, snapshot.Retention{
+		Days:   viper.GetInt("SNAPSHOT_RETENTION_DAYS"),
+		Months: viper.GetInt("SNAPSHOT_RETENTION_MONTHS"),
+	})
+
+	if err := configureSecurityEvents(); err != nil {
+		log.Fatalf("❌ Could not configure security event sinks: %v", err)
 	}
-	viper.AutomaticEnv()
-	auth.SetSecret(viper.GetString("JWT_SECRET"))
 
 	r := router.NewRouter()
 	log.Println("✅ Server running on :8080")
@@ -79,3 +212,129 @@ func main() {
 		log.Fatal(err)
 	}
 }
+
+// newDocumentStore opens the store for product documents and movement
+// attachments chosen by STORAGE_BACKEND: local (DOCUMENTS_DIR), s3 or gcs,
+// the latter two in STORAGE_BUCKET under STORAGE_PREFIX.
+func newDocumentStore() (storage.Store, error) {
+	viper.SetDefault("STORAGE_BACKEND", "local")
+	viper.SetDefault("DOCUMENTS_DIR", "./data/documents")
+	switch backend := viper.GetString("STORAGE_BACKEND"); backend {
+	case "local":
+		return storage.NewLocalStore(viper.GetString("DOCUMENTS_DIR"))
+	case "s3":
+		return storage.NewS3Store(storage.S3Config{
+			Endpoint:        viper.GetString("S3_ENDPOINT"),
+			Region:          viper.GetString("S3_REGION"),
+			Bucket:          viper.GetString("STORAGE_BUCKET"),
+			Prefix:          viper.GetString("STORAGE_PREFIX"),
+			AccessKeyID:     viper.GetString("S3_ACCESS_KEY_ID"),
+			SecretAccessKey: viper.GetString("S3_SECRET_ACCESS_KEY"),
+			PathStyle:       viper.GetBool("S3_PATH_STYLE"),
+		})
+	case "gcs":
+		return storage.NewGCSStore(storage.GCSConfig{
+			Bucket:          viper.GetString("STORAGE_BUCKET"),
+			Prefix:          viper.GetString("STORAGE_PREFIX"),
+			CredentialsFile: viper.GetString("GCS_CREDENTIALS_FILE"),
+			Endpoint:        viper.GetString("GCS_ENDPOINT"),
+		})
+	default:
+		return nil, fmt.Errorf("unknown STORAGE_BACKEND %q (want local, s3 or gcs)", backend)
+	}
+}
+
+// newFileScanner picks the scanner uploads go through with FILE_SCANNER:
+// clamav (a clamd daemon at CLAMAV_ADDR), http (the API at SCAN_API_URL,
+// called with SCAN_API_TOKEN) or none.
+func newFileScanner() (avscan.Scanner, error) {
+	viper.SetDefault("FILE_SCANNER", "none")
+	viper.SetDefault("CLAMAV_ADDR", "clamav:3310")
+	viper.SetDefault("SCAN_TIMEOUT", "30s")
+	timeout := viper.GetDuration("SCAN_TIMEOUT")
+	switch scanner := viper.GetString("FILE_SCANNER"); scanner {
+	case "none":
+		return avscan.Nop{}, nil
+	case "clamav":
+		return avscan.NewClamAV(viper.GetString("CLAMAV_ADDR"), timeout), nil
+	case "http":
+		if viper.GetString("SCAN_API_URL") == "" {
+			return nil, fmt.Errorf("SCAN_API_URL is required with FILE_SCANNER=http")
+		}
+		return avscan.NewHTTPScanner(viper.GetString("SCAN_API_URL"), viper.GetString("SCAN_API_TOKEN"), timeout), nil
+	default:
+		return nil, fmt.Errorf("unknown FILE_SCANNER %q (want none, clamav or http)", scanner)
+	}
+}
+
+// newExchangeRates picks where exchange rates against BASE_CURRENCY come
+// from with EXCHANGE_RATE_PROVIDER: static (EXCHANGE_RATES, e.g.
+// "EUR=0.92,GBP=0.79") or http (the API at EXCHANGE_RATE_API_URL, refreshed
+// every EXCHANGE_RATE_TTL).
+func newExchangeRates() (fxrate.Provider, error) {
+	viper.SetDefault("BASE_CURRENCY", "USD")
+	viper.SetDefault("EXCHANGE_RATE_PROVIDER", "static")
+	viper.SetDefault("EXCHANGE_RATE_TTL", "1h")
+	base := strings.ToUpper(viper.GetString("BASE_CURRENCY"))
+	switch provider := viper.GetString("EXCHANGE_RATE_PROVIDER"); provider {
+	case "static":
+		rates, err := fxrate.ParseRates(viper.GetString("EXCHANGE_RATES"))
+		if err != nil {
+			return nil, err
+		}
+		return fxrate.NewStatic(base, rates), nil
+	case "http":
+		if viper.GetString("EXCHANGE_RATE_API_URL") == "" {
+			return nil, fmt.Errorf("EXCHANGE_RATE_API_URL is required with EXCHANGE_RATE_PROVIDER=http")
+		}
+		return fxrate.NewHTTP(base, viper.GetString("EXCHANGE_RATE_API_URL"), viper.GetDuration("EXCHANGE_RATE_TTL"), 10*time.Second), nil
+	default:
+		return nil, fmt.Errorf("unknown EXCHANGE_RATE_PROVIDER %q (want static or http)", provider)
+	}
+}
+
+// configureSecurityEvents sends security events to SECURITY_WEBHOOK_URLS
+// (comma-separated) and, when SYSLOG_ADDR is set, to a syslog collector.
+// Each event type can be turned on or off with SECURITY_EVENT_<TYPE>, e.g.
+// SECURITY_EVENT_ROLE_CHANGE=false; all but audit are on by default.
+func configureSecurityEvents() error {
+	var sinks []security.Sink
+	for _, url := range splitList(viper.GetString("SECURITY_WEBHOOK_URLS")) {
+		sinks = append(sinks, security.NewWebhookSink(url, viper.GetString("SECURITY_WEBHOOK_SECRET")))
+	}
+	if addr := viper.GetString("SYSLOG_ADDR"); addr != "" {
+		sink, err := security.NewSyslogSink(security.SyslogConfig{
+			Network:            viper.GetString("SYSLOG_NETWORK"),
+			Addr:               addr,
+			Format:             viper.GetString("SYSLOG_FORMAT"),
+			Facility:           viper.GetString("SYSLOG_FACILITY"),
+			SeverityMap:        viper.GetString("SYSLOG_SEVERITY_MAP"),
+			CAFile:             viper.GetString("SYSLOG_TLS_CA_FILE"),
+			InsecureSkipVerify: viper.GetBool("SYSLOG_TLS_INSECURE"),
+		})
+		if err != nil {
+			return err
+		}
+		sinks = append(sinks, sink)
+	}
+
+	enabled := map[string]bool{}
+	for _, t := range security.EventTypes {
+		key := "SECURITY_EVENT_" + strings.ToUpper(t)
+		viper.SetDefault(key, t != security.EventAudit)
+		enabled[t] = viper.GetBool(key)
+	}
+	security.Configure(sinks, enabled)
+	return nil
+}
+
+// splitList parses a comma-separated setting, dropping blank entries.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// client calls the API with the stored credentials, refreshing the access
+// token once when it has expired.
+type client struct {
+	creds credentials
+	http  *http.Client
+}
+
+func newClient() (*client, error) {
+	creds, err := loadCredentials()
+	if err != nil {
+		return nil, err
+	}
+	return &client{creds: creds, http: &http.Client{Timeout: 60 * time.Second}}, nil
+}
+
+// apiError is a non-2xx answer.
+type apiError struct {
+	Status int
+	Body   string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("server answered %d: %s", e.Status, strings.TrimSpace(e.Body))
+}
+
+// do sends body, JSON-encoded unless it is an io.Reader, and decodes the
+// answer into out when out is not nil.
+func (c *client) do(method, path, contentType string, body any, out any) error {
+	var raw []byte
+	switch b := body.(type) {
+	case nil:
+	case io.Reader:
+		var err error
+		if raw, err = io.ReadAll(b); err != nil {
+			return err
+		}
+	default:
+		var err error
+		if raw, err = json.Marshal(b); err != nil {
+			return err
+		}
+		contentType = "application/json"
+	}
+
+	resp, err := c.send(method, path, contentType, raw)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusUnauthorized && c.creds.RefreshToken != "" {
+		resp.Body.Close()
+		if err := c.refresh(); err != nil {
+			return fmt.Errorf("session expired and could not be refreshed (%v); run `invctl login` again", err)
+		}
+		if resp, err = c.send(method, path, contentType, raw); err != nil {
+			return err
+		}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return &apiError{Status: resp.StatusCode, Body: string(msg)}
+	}
+	switch out := out.(type) {
+	case nil:
+		return nil
+	case io.Writer:
+		_, err = io.Copy(out, resp.Body)
+		return err
+	default:
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+}
+
+func (c *client) send(method, path, contentType string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequest(method, strings.TrimRight(c.creds.URL, "/")+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.creds.AccessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.creds.AccessToken)
+	}
+	req.Header.Set("User-Agent", "invctl")
+	return c.http.Do(req)
+}
+
+func (c *client) refresh() error {
+	c.creds.AccessToken = ""
+	var tokens tokenPair
+	body, _ := json.Marshal(map[string]string{"username": c.creds.Username, "refresh_token": c.creds.RefreshToken})
+	resp, err := c.send(http.MethodPost, "/refresh", "application/json", body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return &apiError{Status: resp.StatusCode, Body: string(msg)}
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokens); err != nil {
+		return err
+	}
+	c.creds.AccessToken, c.creds.RefreshToken = tokens.AccessToken, tokens.RefreshToken
+	return saveCredentials(c.creds)
+}
+
+type tokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+}
@@ -0,0 +1,415 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+type product struct {
+	ID        int     `json:"id"`
+	Name      string  `json:"name"`
+	Price     float64 `json:"price"`
+	Quantity  int     `json:"quantity"`
+	Threshold int     `json:"threshold"`
+	SKU       string  `json:"sku,omitempty"`
+	Category  string  `json:"category,omitempty"`
+}
+
+// secret reads a password from the environment variable, or else the first
+// line of stdin, so it never shows up in the process list or shell history.
+func secret(env, prompt string) (string, error) {
+	if v := os.Getenv(env); v != "" {
+		return v, nil
+	}
+	fmt.Fprint(os.Stderr, prompt)
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	if line = strings.TrimRight(line, "\r\n"); line == "" {
+		return "", fmt.Errorf("no password given; set %s or pipe it on stdin", env)
+	}
+	return line, nil
+}
+
+func printJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func runLogin(args []string) error {
+	fs := flag.NewFlagSet("login", flag.ContinueOnError)
+	server := fs.String("url", envOr("INVCTL_URL", "http://localhost:8080"), "API base URL")
+	username := fs.String("username", "", "user to sign in as")
+	if _, err := parse(fs, args, 0); err != nil {
+		return err
+	}
+	if *username == "" {
+		return errors.New("-username is required")
+	}
+	password, err := secret("INVCTL_PASSWORD", "Password: ")
+	if err != nil {
+		return err
+	}
+
+	c := &client{creds: credentials{URL: *server, Username: *username}, http: &http.Client{Timeout: 30 * time.Second}}
+	var tokens tokenPair
+	if err := c.do(http.MethodPost, "/login", "", map[string]string{"username": *username, "password": password}, &tokens); err != nil {
+		return err
+	}
+	c.creds.AccessToken, c.creds.RefreshToken = tokens.AccessToken, tokens.RefreshToken
+	if err := saveCredentials(c.creds); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Logged in to %s as %s\n", *server, *username)
+	return nil
+}
+
+func runLogout(args []string) error {
+	if _, err := parse(flag.NewFlagSet("logout", flag.ContinueOnError), args, 0); err != nil {
+		return err
+	}
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	if err := c.do(http.MethodPost, "/logout", "", nil, nil); err != nil {
+		fmt.Fprintln(os.Stderr, "invctl: server logout failed:", err)
+	}
+	return removeCredentials()
+}
+
+func runProductsList(args []string) error {
+	fs := flag.NewFlagSet("products list", flag.ContinueOnError)
+	name := fs.String("name", "", "only products whose name contains TEXT")
+	lowStock := fs.Bool("low-stock", false, "only products below their threshold")
+	limit := fs.Int("limit", 100, "maximum products")
+	asJSON := fs.Bool("json", false, "print JSON")
+	if _, err := parse(fs, args, 0); err != nil {
+		return err
+	}
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	q := url.Values{"limit": {strconv.Itoa(*limit)}, "include_total": {"false"}}
+	if *name != "" {
+		q.Set("name", *name)
+	}
+	if *lowStock {
+		q.Set("low_stock", "true")
+	}
+	var result struct {
+		Data []product `json:"data"`
+	}
+	if err := c.do(http.MethodGet, "/products/filter?"+q.Encode(), "", nil, &result); err != nil {
+		return err
+	}
+	if *asJSON {
+		return printJSON(result.Data)
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tNAME\tQUANTITY\tTHRESHOLD\tPRICE")
+	for _, p := range result.Data {
+		fmt.Fprintf(tw, "%d\t%s\t%d\t%d\t%.2f\n", p.ID, p.Name, p.Quantity, p.Threshold, p.Price)
+	}
+	return tw.Flush()
+}
+
+func runProductsCreate(args []string) error {
+	fs := flag.NewFlagSet("products create", flag.ContinueOnError)
+	var p product
+	fs.StringVar(&p.Name, "name", "", "product name")
+	fs.Float64Var(&p.Price, "price", 0, "unit price")
+	fs.IntVar(&p.Quantity, "quantity", 0, "initial stock")
+	fs.IntVar(&p.Threshold, "threshold", 0, "low-stock threshold")
+	fs.StringVar(&p.SKU, "sku", "", "SKU")
+	fs.StringVar(&p.Category, "category", "", "category")
+	asJSON := fs.Bool("json", false, "print JSON")
+	if _, err := parse(fs, args, 0); err != nil {
+		return err
+	}
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	var created product
+	if err := c.do(http.MethodPost, "/products", "", p, &created); err != nil {
+		return err
+	}
+	if *asJSON {
+		return printJSON(created)
+	}
+	fmt.Printf("Created product %d (%s)\n", created.ID, created.Name)
+	return nil
+}
+
+func runAdjust(args []string) error {
+	fs := flag.NewFlagSet("adjust", flag.ContinueOnError)
+	externalID := fs.String("external-id", "", "UUID making the adjustment safe to retry")
+	pos, err := parse(fs, args, 2)
+	if err != nil {
+		return err
+	}
+	id, err := strconv.Atoi(pos[0])
+	if err != nil {
+		return fmt.Errorf("invalid product ID %q", pos[0])
+	}
+	delta, err := strconv.Atoi(pos[1])
+	if err != nil {
+		return fmt.Errorf("invalid delta %q", pos[1])
+	}
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	var p product
+	body := map[string]any{"delta": delta, "external_id": *externalID}
+	if err := c.do(http.MethodPost, fmt.Sprintf("/products/%d/adjust", id), "", body, &p); err != nil {
+		return err
+	}
+	fmt.Printf("%s: %d in stock\n", p.Name, p.Quantity)
+	return nil
+}
+
+func runImport(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	mode := fs.String("mode", "skip", "skip or update existing products")
+	pos, err := parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	file, err := os.Open(pos[0])
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	form := multipart.NewWriter(&buf)
+	part, err := form.CreateFormFile("file", filepath.Base(pos[0]))
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(part, file); err != nil {
+		return err
+	}
+	if err := form.Close(); err != nil {
+		return err
+	}
+
+	var result struct {
+		Imported int `json:"imported"`
+		Errors   []struct {
+			Description string `json:"description"`
+		} `json:"errors"`
+	}
+	if err := c.do(http.MethodPost, "/products/import?mode="+url.QueryEscape(*mode), form.FormDataContentType(), &buf, &result); err != nil {
+		return err
+	}
+	fmt.Printf("Imported %d products\n", result.Imported)
+	for _, e := range result.Errors {
+		fmt.Fprintln(os.Stderr, "  "+e.Description)
+	}
+	if len(result.Errors) > 0 {
+		return fmt.Errorf("%d rows rejected", len(result.Errors))
+	}
+	return nil
+}
+
+func runExport(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	output := fs.String("o", "", "file to write (default stdout)")
+	columns := fs.String("columns", "", "comma-separated columns, in output order")
+	if _, err := parse(fs, args, 0); err != nil {
+		return err
+	}
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	path := "/products/export"
+	if *columns != "" {
+		path += "?columns=" + url.QueryEscape(*columns)
+	}
+	var out io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+	return c.do(http.MethodGet, path, "", nil, out)
+}
+
+func runUsersCreate(args []string) error {
+	fs := flag.NewFlagSet("users create", flag.ContinueOnError)
+	username := fs.String("username", "", "new user's name")
+	role := fs.String("role", "user", "user, admin or warehouse")
+	if _, err := parse(fs, args, 0); err != nil {
+		return err
+	}
+	if *username == "" {
+		return errors.New("-username is required")
+	}
+	password, err := secret("INVCTL_NEW_PASSWORD", "New user's password: ")
+	if err != nil {
+		return err
+	}
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	body := map[string]string{"username": *username, "password": password, "role": *role}
+	if err := c.do(http.MethodPost, "/admin/users", "", body, nil); err != nil {
+		return err
+	}
+	fmt.Printf("Created %s user %s\n", *role, *username)
+	return nil
+}
+
+func runBansList(args []string) error {
+	fs := flag.NewFlagSet("bans list", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print JSON")
+	if _, err := parse(fs, args, 0); err != nil {
+		return err
+	}
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	var bans []struct {
+		ID        string    `json:"id"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	if err := c.do(http.MethodGet, "/admin/bans", "", nil, &bans); err != nil {
+		return err
+	}
+	if *asJSON {
+		return printJSON(bans)
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tEXPIRES")
+	for _, b := range bans {
+		fmt.Fprintf(tw, "%s\t%s\n", b.ID, b.ExpiresAt.Local().Format(time.DateTime))
+	}
+	return tw.Flush()
+}
+
+func runBansRemove(args []string) error {
+	pos, err := parse(flag.NewFlagSet("bans remove", flag.ContinueOnError), args, 1)
+	if err != nil {
+		return err
+	}
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	if err := c.do(http.MethodDelete, "/admin/bans/"+url.PathEscape(pos[0]), "", nil, nil); err != nil {
+		return err
+	}
+	fmt.Printf("Removed ban on %s\n", pos[0])
+	return nil
+}
+
+func runApply(args []string) error {
+	fs := flag.NewFlagSet("apply", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "only show what would change")
+	asJSON := fs.Bool("json", false, "print JSON")
+	pos, err := parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	var manifest []byte
+	if pos[0] == "-" {
+		manifest, err = io.ReadAll(os.Stdin)
+	} else {
+		manifest, err = os.ReadFile(pos[0])
+	}
+	if err != nil {
+		return err
+	}
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	contentType := "application/yaml"
+	if strings.EqualFold(filepath.Ext(pos[0]), ".json") {
+		contentType = "application/json"
+	}
+	var result struct {
+		DryRun  bool `json:"dry_run"`
+		Changes []struct {
+			Section string `json:"section"`
+			Key     string `json:"key"`
+			Action  string `json:"action"`
+			Before  any    `json:"before"`
+			After   any    `json:"after"`
+		} `json:"changes"`
+	}
+	path := "/admin/apply?dry_run=" + strconv.FormatBool(*dryRun)
+	if err := c.do(http.MethodPost, path, contentType, bytes.NewReader(manifest), &result); err != nil {
+		return err
+	}
+	if *asJSON {
+		return printJSON(result)
+	}
+	if len(result.Changes) == 0 {
+		fmt.Println("Already up to date")
+		return nil
+	}
+	marks := map[string]string{"add": "+", "remove": "-", "update": "~"}
+	for _, ch := range result.Changes {
+		line := fmt.Sprintf("%s %s/%s", marks[ch.Action], ch.Section, ch.Key)
+		switch ch.Action {
+		case "add":
+			line += fmt.Sprintf(": %v", ch.After)
+		case "remove":
+			line += fmt.Sprintf(": %v", ch.Before)
+		default:
+			line += fmt.Sprintf(": %v -> %v", ch.Before, ch.After)
+		}
+		fmt.Println(line)
+	}
+	if result.DryRun {
+		fmt.Printf("%d changes not applied (dry run)\n", len(result.Changes))
+	} else {
+		fmt.Printf("Applied %d changes\n", len(result.Changes))
+	}
+	return nil
+}
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
@@ -0,0 +1,85 @@
+// Command invctl drives the inventory API from the shell, for scripts and
+// ops runbooks. `invctl login` stores the server URL and tokens; every other
+// command reuses them.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+type command struct {
+	usage string
+	run   func(args []string) error
+}
+
+// commands is filled in init: its entries refer back to it through parse.
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{
+		"login":           {"login [-url URL] -username NAME  (password from INVCTL_PASSWORD or stdin)", runLogin},
+		"logout":          {"logout", runLogout},
+		"products list":   {"products list [-name TEXT] [-low-stock] [-limit N] [-json]", runProductsList},
+		"products create": {"products create -name NAME -price N [-quantity N] [-threshold N] [-sku SKU] [-category C] [-json]", runProductsCreate},
+		"adjust":          {"adjust [-external-id UUID] PRODUCT_ID DELTA", runAdjust},
+		"import":          {"import [-mode skip|update] FILE.csv", runImport},
+		"export":          {"export [-o FILE] [-columns a,b,c]", runExport},
+		"users create":    {"users create -username NAME [-role user|admin|warehouse]  (password from INVCTL_NEW_PASSWORD or stdin)", runUsersCreate},
+		"bans list":       {"bans list [-json]", runBansList},
+		"bans remove":     {"bans remove ID", runBansRemove},
+		"apply":           {"apply [-dry-run] [-json] FILE.yaml|FILE.json|-", runApply},
+	}
+}
+
+func main() {
+	if err := dispatch(os.Args[1:]); err != nil && !errors.Is(err, flag.ErrHelp) {
+		fmt.Fprintln(os.Stderr, "invctl:", err)
+		os.Exit(1)
+	}
+}
+
+func dispatch(args []string) error {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		usage()
+		return nil
+	}
+	if len(args) >= 2 {
+		if cmd, ok := commands[args[0]+" "+args[1]]; ok {
+			return cmd.run(args[2:])
+		}
+	}
+	if cmd, ok := commands[args[0]]; ok {
+		return cmd.run(args[1:])
+	}
+	usage()
+	return fmt.Errorf("unknown command %q", strings.Join(args[:min(2, len(args))], " "))
+}
+
+func usage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintln(os.Stderr, "Usage: invctl COMMAND [flags]\n\nCommands:")
+	for _, name := range names {
+		fmt.Fprintln(os.Stderr, "  "+commands[name].usage)
+	}
+	fmt.Fprintln(os.Stderr, "\nCredentials are kept in $INVCTL_CONFIG or the user config directory (invctl/credentials.json).")
+}
+
+// parse parses flags and checks the number of positional arguments.
+func parse(fs *flag.FlagSet, args []string, positional int) ([]string, error) {
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() != positional {
+		return nil, errors.New("usage: invctl " + commands[fs.Name()].usage)
+	}
+	return fs.Args(), nil
+}
@@ -1,15 +1,21 @@
 package auth
 
 import (
+	"context"
+	"errors"
 	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/redis/go-redis/v9"
 	"github.com/rogerio-castellano/inventory-tracker/internal/models"
 )
 
 var jwtSecret []byte
 
+// AccessTokenTTL is how long access tokens stay valid.
+const AccessTokenTTL = 15 * time.Minute
+
 func SetSecret(secret string) {
 	jwtSecret = []byte(secret)
 }
@@ -37,11 +43,14 @@ func TokenClaims(auth string) (*jwt.Token, jwt.MapClaims, error) {
 }
 
 func buildTokenWithClaims(user models.User, impersonator string) (string, error) {
+	now := time.Now()
 	claims := jwt.MapClaims{
 		"sub":      user.ID,
 		"username": user.Username,
 		"role":     user.Role,
-		"exp":      time.Now().Add(15 * time.Minute).Unix(),
+		"iat":      now.Unix(),
+		"iat_ms":   now.UnixMilli(),
+		"exp":      now.Add(AccessTokenTTL).Unix(),
 	}
 
 	if impersonator != "" {
@@ -51,3 +60,68 @@ func buildTokenWithClaims(user models.User, impersonator string) (string, error)
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString(jwtSecret)
 }
+
+// RevokedBeforeKey is the Redis key holding the Unix time, in
+// milliseconds, up to which the access tokens of username are rejected. It
+// outlives the tokens it revokes by setting it with AccessTokenTTL as
+// expiry.
+func RevokedBeforeKey(username string) string {
+	return "auth:revoked_before:" + username
+}
+
+// IssuedBy reports whether the token with claims was issued at or before
+// cutoff, a Unix time in milliseconds. The iat_ms claim dates tokens to the
+// millisecond, so logging in again right after a revocation yields a token
+// it does not cover; tokens without it fall back on iat, in seconds, and
+// tokens without either predate every cutoff.
+func IssuedBy(claims jwt.MapClaims, cutoff int64) bool {
+	if issuedMs, ok := claims["iat_ms"].(float64); ok {
+		return int64(issuedMs) <= cutoff
+	}
+	iat, _ := claims["iat"].(float64)
+	return int64(iat)*1000 <= cutoff
+}
+
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrTokenRevoked = errors.New("token revoked")
+)
+
+// VerifyToken returns the claims of the bearer token in authorization once
+// its signature checks out and it has not been revoked: a service token
+// must still be listed under ServiceTokensKey, and a user token must have
+// been issued after the user's RevokedBeforeKey cutoff. Everything that
+// trusts a token goes through it, so a revoked token is refused everywhere
+// rather than only where access is denied.
+func VerifyToken(ctx context.Context, rdb redis.UniversalClient, authorization string) (jwt.MapClaims, error) {
+	token, claims, err := TokenClaims(authorization)
+	if err != nil || token == nil || !token.Valid {
+		return nil, ErrInvalidToken
+	}
+
+	if _, ok := TokenScopes(claims); ok {
+		id, _ := claims["jti"].(string)
+		exists, err := rdb.HExists(ctx, ServiceTokensKey, id).Result()
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, ErrTokenRevoked
+		}
+		return claims, nil
+	}
+
+	// A role change or disabled account revokes every access token of the
+	// user issued up to then.
+	username, _ := claims["username"].(string)
+	cutoff, err := rdb.Get(ctx, RevokedBeforeKey(username)).Int64()
+	switch {
+	case errors.Is(err, redis.Nil):
+		return claims, nil
+	case err != nil:
+		return nil, err
+	case IssuedBy(claims, cutoff):
+		return nil, ErrTokenRevoked
+	}
+	return claims, nil
+}
@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"slices"
+	"sync"
+)
+
+const (
+	// PermPricingRead allows seeing prices, stock valuation and any other
+	// monetary figure in API responses.
+	PermPricingRead = "pricing:read"
+	// PermAdjustmentOverride allows stock adjustments larger than the
+	// maximum set for the product.
+	PermAdjustmentOverride = "adjustments:override"
+	// PermPriceOverride allows writing prices that break the price rules.
+	PermPriceOverride = "pricing:override"
+)
+
+// KnownPermissions lists every permission a role can be granted.
+var KnownPermissions = []string{PermPricingRead, PermAdjustmentOverride, PermPriceOverride}
+
+// defaultRolePermissions maps each role to the permissions it is granted
+// until an administrator applies a configuration. Admins implicitly hold
+// every permission. Anonymous callers are treated as "guest", so it holds
+// none: dropping a token must not reveal more than sending it.
+var defaultRolePermissions = map[string][]string{
+	"user":      {PermPricingRead},
+	"guest":     {},
+	"warehouse": {},
+}
+
+var (
+	permissionsMu   sync.RWMutex
+	rolePermissions = defaultRolePermissions
+)
+
+// PricingFields are the JSON keys removed from responses for callers lacking PermPricingRead.
+var PricingFields = []string{"price", "cost", "average_price", "total_stock_value", "unit_cost", "total_cost", "written_off_value",
+	"total_value", "total_value_delta", "value_before", "value_after", "value_delta",
+	"unit_margin", "margin_percent", "projected_profit", "stock_value", "stock_cost",
+	"allocated_cost", "unit_cost_before", "unit_cost_after", "basis", "cost_price", "margin_score"}
+
+// HasPermission reports whether role has been granted permission.
+func HasPermission(role, permission string) bool {
+	if role == "admin" {
+		return true
+	}
+	permissionsMu.RLock()
+	defer permissionsMu.RUnlock()
+	return slices.Contains(rolePermissions[role], permission)
+}
+
+// Permissions returns the permissions granted to role.
+func Permissions(role string) []string {
+	if role == "admin" {
+		return slices.Clone(KnownPermissions)
+	}
+	permissionsMu.RLock()
+	defer permissionsMu.RUnlock()
+	return slices.Clone(rolePermissions[role])
+}
+
+// RolePermissions returns a copy of the permissions of every configured role.
+// The admin role is not listed: it always holds every permission.
+func RolePermissions() map[string][]string {
+	permissionsMu.RLock()
+	defer permissionsMu.RUnlock()
+	roles := make(map[string][]string, len(rolePermissions))
+	for role, perms := range rolePermissions {
+		roles[role] = slices.Clone(perms)
+	}
+	return roles
+}
+
+// SetRolePermissions replaces the role table; roles left out lose every
+// permission. A nil map restores the built-in defaults.
+func SetRolePermissions(roles map[string][]string) {
+	permissionsMu.Lock()
+	defer permissionsMu.Unlock()
+	if roles == nil {
+		rolePermissions = defaultRolePermissions
+		return
+	}
+	rolePermissions = make(map[string][]string, len(roles))
+	for role, perms := range roles {
+		rolePermissions[role] = slices.Clone(perms)
+	}
+}
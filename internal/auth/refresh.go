@@ -1,6 +1,8 @@
 package auth
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -15,11 +17,32 @@ type RefreshTokenEntry struct {
 	CreatedAt time.Time `json:"created_at"`
 	IPAddress string    `json:"ip_address"`
 	UserAgent string    `json:"user_agent"`
+	// DeviceName is the label the client gave the session at login.
+	DeviceName string `json:"device_name,omitempty"`
 }
 
 const refreshTokenFile = "refresh_tokens.json"
 const RefreshTokenMaxAge = 7 * 24 * time.Hour // 7 days
 
+// sessionIdleTimeout ends sessions that made no request for that long, even
+// before RefreshTokenMaxAge; 0 turns it off.
+var sessionIdleTimeout time.Duration
+
+func SetSessionIdleTimeout(timeout time.Duration) {
+	sessionIdleTimeout = timeout
+}
+
+func SessionIdleTimeout() time.Duration {
+	return sessionIdleTimeout
+}
+
+// SessionKey identifies a user's session by the client it was started from.
+func SessionKey(ip, ua string) string {
+	h := sha256.New()
+	h.Write([]byte(ip + ua))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
 var tokenStore = map[string]map[string]RefreshTokenEntry{}
 var mu sync.Mutex
 
@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// ServiceRole is the role of service tokens. It holds no permissions of
+	// its own: what a service token may call is set by its scopes.
+	ServiceRole = "service"
+	// ServiceTokensKey is the Redis hash of issued service tokens, keyed by
+	// token ID. A token whose ID is missing has been revoked.
+	ServiceTokensKey = "service_tokens"
+)
+
+// GenerateServiceToken signs a token for a device or integration that cannot
+// use the refresh flow. A zero ttl yields a token that never expires; it stays
+// valid until revoked.
+func GenerateServiceToken(id, name string, scopes []string, ttl time.Duration) (string, error) {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"sub":      0,
+		"jti":      id,
+		"username": "service:" + name,
+		"role":     ServiceRole,
+		"scope":    scopes,
+		"iat":      now.Unix(),
+	}
+	if ttl > 0 {
+		claims["exp"] = now.Add(ttl).Unix()
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(jwtSecret)
+}
+
+// TokenScopes returns the scope claim of a token and whether it has one.
+// Tokens without a scope are not restricted to particular endpoints.
+func TokenScopes(claims jwt.MapClaims) ([]string, bool) {
+	raw, ok := claims["scope"].([]any)
+	if !ok {
+		return nil, false
+	}
+	scopes := make([]string, 0, len(raw))
+	for _, s := range raw {
+		if str, ok := s.(string); ok {
+			scopes = append(scopes, str)
+		}
+	}
+	return scopes, true
+}
+
+// ValidScope reports whether s is a well formed scope: a path starting with
+// "/", optionally preceded by an HTTP method. A "*" segment matches any single
+// segment, such as a product ID, and a trailing "/*" covers everything below
+// the path, e.g. "POST /scan", "POST /products/*/adjust" or "GET /products/*".
+func ValidScope(s string) bool {
+	method, path := splitScope(s)
+	switch method {
+	case "", http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+	default:
+		return false
+	}
+	return strings.HasPrefix(path, "/") && !strings.Contains(path, " ")
+}
+
+// ScopeAllows reports whether any scope covers a request.
+func ScopeAllows(scopes []string, method, path string) bool {
+	for _, s := range scopes {
+		scopeMethod, scopePath := splitScope(s)
+		if scopeMethod != "" && scopeMethod != method {
+			continue
+		}
+		if pathMatches(scopePath, path) {
+			return true
+		}
+	}
+	return false
+}
+
+func splitScope(s string) (method, path string) {
+	method, path, found := strings.Cut(s, " ")
+	if !found {
+		return "", method
+	}
+	return method, path
+}
+
+func pathMatches(pattern, path string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "/*"); ok {
+		segments := strings.Split(path, "/")
+		want := strings.Split(prefix, "/")
+		return len(segments) >= len(want) && segmentsMatch(want, segments[:len(want)])
+	}
+	return segmentsMatch(strings.Split(pattern, "/"), strings.Split(path, "/"))
+}
+
+func segmentsMatch(pattern, path []string) bool {
+	if len(pattern) != len(path) {
+		return false
+	}
+	for i := range pattern {
+		if pattern[i] != "*" && pattern[i] != path[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,160 @@
+// Package avscan checks uploaded files for malware before they are processed
+// or stored.
+package avscan
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Verdict is the outcome of scanning one file.
+type Verdict struct {
+	Infected bool
+	Threat   string // what was found, e.g. "Eicar-Test-Signature"
+}
+
+// Scanner inspects a file's content. An error means the file could not be
+// scanned, not that it is infected.
+type Scanner interface {
+	Scan(filename string, r io.Reader) (Verdict, error)
+	// Name identifies the scanner in audit entries.
+	Name() string
+}
+
+// Nop passes every file; it is used when no scanner is configured.
+type Nop struct{}
+
+var _ Scanner = Nop{}
+
+func (Nop) Scan(string, io.Reader) (Verdict, error) { return Verdict{}, nil }
+func (Nop) Name() string                            { return "none" }
+
+// clamdChunkSize is how much of the file goes in each INSTREAM chunk.
+const clamdChunkSize = 64 << 10
+
+// ClamAV streams files to a clamd daemon with the INSTREAM command.
+type ClamAV struct {
+	network, addr string
+	timeout       time.Duration
+}
+
+var _ Scanner = (*ClamAV)(nil)
+
+// NewClamAV talks to clamd at addr: host:port for TCP, or unix:/path for
+// its local socket.
+func NewClamAV(addr string, timeout time.Duration) *ClamAV {
+	if path, ok := strings.CutPrefix(addr, "unix:"); ok {
+		return &ClamAV{network: "unix", addr: path, timeout: timeout}
+	}
+	return &ClamAV{network: "tcp", addr: addr, timeout: timeout}
+}
+
+func (c *ClamAV) Name() string { return "clamav" }
+
+func (c *ClamAV) Scan(_ string, r io.Reader) (Verdict, error) {
+	conn, err := net.DialTimeout(c.network, c.addr, c.timeout)
+	if err != nil {
+		return Verdict{}, fmt.Errorf("failed to reach clamd: %w", err)
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(c.timeout))
+
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return Verdict{}, err
+	}
+	buf := make([]byte, clamdChunkSize)
+	size := make([]byte, 4)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			binary.BigEndian.PutUint32(size, uint32(n))
+			if _, err := conn.Write(append(size, buf[:n]...)); err != nil {
+				return Verdict{}, err
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return Verdict{}, err
+		}
+	}
+	// A zero-length chunk ends the stream.
+	if _, err := conn.Write([]byte{0, 0, 0, 0}); err != nil {
+		return Verdict{}, err
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return Verdict{}, err
+	}
+	return parseClamdReply(strings.TrimRight(reply, "\x00\n"))
+}
+
+// parseClamdReply reads "stream: OK" or "stream: <threat> FOUND".
+func parseClamdReply(reply string) (Verdict, error) {
+	result := strings.TrimSpace(strings.TrimPrefix(reply, "stream:"))
+	switch {
+	case result == "OK":
+		return Verdict{}, nil
+	case strings.HasSuffix(result, " FOUND"):
+		return Verdict{Infected: true, Threat: strings.TrimSuffix(result, " FOUND")}, nil
+	}
+	return Verdict{}, fmt.Errorf("clamd: %s", result)
+}
+
+// HTTPScanner posts files to a scanning API. The API receives the raw file
+// with its name in the X-Filename header and answers 200 with a JSON body of
+// the form {"infected": true, "threat": "..."}.
+type HTTPScanner struct {
+	url, token string
+	client     *http.Client
+}
+
+var _ Scanner = (*HTTPScanner)(nil)
+
+// NewHTTPScanner sends token, when set, as a bearer token.
+func NewHTTPScanner(url, token string, timeout time.Duration) *HTTPScanner {
+	return &HTTPScanner{url: url, token: token, client: &http.Client{Timeout: timeout}}
+}
+
+func (s *HTTPScanner) Name() string { return "http" }
+
+func (s *HTTPScanner) Scan(filename string, r io.Reader) (Verdict, error) {
+	req, err := http.NewRequest(http.MethodPost, s.url, r)
+	if err != nil {
+		return Verdict{}, err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("X-Filename", filename)
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return Verdict{}, fmt.Errorf("failed to reach scanning API: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return Verdict{}, fmt.Errorf("scanning API answered %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+
+	var result struct {
+		Infected bool   `json:"infected"`
+		Threat   string `json:"threat"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return Verdict{}, fmt.Errorf("invalid scanning API response: %w", err)
+	}
+	return Verdict{Infected: result.Infected, Threat: result.Threat}, nil
+}
@@ -0,0 +1,70 @@
+// Package consignment emails the monthly settlement of consignment stock:
+// what was consumed of each supplier's stock and is now owed to them.
+package consignment
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/jobs"
+	"github.com/rogerio-castellano/inventory-tracker/internal/mailer"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+)
+
+// PreviousMonth returns the bounds of the calendar month before the one of
+// now, in UTC.
+func PreviousMonth(now time.Time) (from, to time.Time) {
+	now = now.UTC()
+	to = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return to.AddDate(0, -1, 0), to
+}
+
+// StartMonthlySettlement sends the settlement of the previous month on the
+// first of every month at 06:00 UTC.
+func StartMonthlySettlement(consignments repo.ConsignmentRepository) {
+	jobs.Register("consignment_settlement", "monthly on the 1st at 06:00 UTC")
+	for {
+		now := time.Now().UTC()
+		next := time.Date(now.Year(), now.Month(), 1, 6, 0, 0, 0, time.UTC)
+		if !next.After(now) {
+			next = next.AddDate(0, 1, 0)
+		}
+		time.Sleep(time.Until(next))
+		from, to := PreviousMonth(time.Now())
+		err := jobs.Retry("consignment_settlement", "settlement of "+from.Format("2006-01"), func() error {
+			return SendSettlement(consignments, from, to)
+		})
+		if err != nil {
+			log.Printf("consignment settlement not sent: %v", err)
+		}
+		jobs.Record("consignment_settlement", err)
+	}
+}
+
+// SendSettlement emails what was consumed in [from, to), per supplier.
+// Nothing is sent when nothing was consumed.
+func SendSettlement(consignments repo.ConsignmentRepository, from, to time.Time) error {
+	settlements, err := consignments.Settlement(from, to, "")
+	if err != nil {
+		return fmt.Errorf("fetch settlement: %w", err)
+	}
+	if len(settlements) == 0 {
+		return nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString("<h2>🤝 Consignment Settlement</h2>")
+	sb.WriteString(fmt.Sprintf("<p>Consigned stock consumed from %s to %s.</p>", from.Format(time.DateOnly), to.AddDate(0, 0, -1).Format(time.DateOnly)))
+	for _, s := range settlements {
+		sb.WriteString(fmt.Sprintf("<h3>%s — %.2f owed</h3><ul>", html.EscapeString(s.Supplier), s.TotalCost))
+		for _, l := range s.Lines {
+			sb.WriteString(fmt.Sprintf("<li>%s — %d × %.2f = %.2f</li>", html.EscapeString(l.Name), l.Quantity, l.UnitCost, l.TotalCost))
+		}
+		sb.WriteString("</ul>")
+	}
+
+	return mailer.SendHTML("🤝 Monthly Consignment Settlement", sb.String())
+}
@@ -0,0 +1,69 @@
+// Package expiry emails a daily digest of lots about to expire, so stock can
+// be sold, moved or discounted before it turns into waste.
+package expiry
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/jobs"
+	"github.com/rogerio-castellano/inventory-tracker/internal/mailer"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+)
+
+// StartDailyNotifier sends the digest every morning at 07:00 local time.
+func StartDailyNotifier(lots repo.LotRepository, products repo.ProductRepository, within time.Duration) {
+	jobs.Register("expiry_digest", "daily at 07:00")
+	for {
+		now := time.Now()
+		next := time.Date(now.Year(), now.Month(), now.Day(), 7, 0, 0, 0, now.Location())
+		if !next.After(now) {
+			next = next.AddDate(0, 0, 1)
+		}
+		time.Sleep(time.Until(next))
+		err := jobs.Retry("expiry_digest", "digest of "+next.Format(time.DateOnly), func() error {
+			return SendDigest(lots, products, within)
+		})
+		if err != nil {
+			log.Printf("expiry digest not sent: %v", err)
+		}
+		jobs.Record("expiry_digest", err)
+	}
+}
+
+// SendDigest emails the lots with stock left that expire within the window,
+// expired ones first. Nothing is sent when no lot qualifies.
+func SendDigest(lots repo.LotRepository, products repo.ProductRepository, within time.Duration) error {
+	now := time.Now().UTC()
+	expiring, err := lots.GetExpiringBefore(now.Add(within))
+	if err != nil {
+		return fmt.Errorf("fetch expiring lots: %w", err)
+	}
+	if len(expiring) == 0 {
+		return nil
+	}
+
+	names := map[int]string{}
+	var sb strings.Builder
+	sb.WriteString("<h2>⏳ Expiring Stock</h2>")
+	sb.WriteString(fmt.Sprintf("<p>Lots expiring within %d days: <strong>%d</strong></p><ul>", int(within.Hours()/24), len(expiring)))
+	for _, l := range expiring {
+		if _, ok := names[l.ProductID]; !ok {
+			if p, err := products.GetByID(l.ProductID); err == nil {
+				names[l.ProductID] = p.Name
+			}
+		}
+		when := "expires " + l.ExpiresAt.Format(time.DateOnly)
+		if l.ExpiresAt.Before(now) {
+			when = "<b>expired</b> " + l.ExpiresAt.Format(time.DateOnly)
+		}
+		sb.WriteString(fmt.Sprintf("<li>%s — lot <code>%s</code>, %d left, %s</li>",
+			html.EscapeString(names[l.ProductID]), html.EscapeString(l.LotNumber), l.Quantity, when))
+	}
+	sb.WriteString("</ul>")
+
+	return mailer.SendHTML("⏳ Daily Expiring Stock Report", sb.String())
+}
@@ -0,0 +1,167 @@
+// Package fxrate provides the exchange rates used to report money held in
+// several currencies in a single one.
+package fxrate
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrUnknownCurrency is returned when a provider has no rate for a currency.
+var ErrUnknownCurrency = errors.New("no exchange rate for currency")
+
+// Provider gives exchange rates against a base currency.
+type Provider interface {
+	// Base is the ISO 4217 code of the currency rates are quoted against.
+	Base() string
+	// Rates returns, per currency, how many units of it one unit of the
+	// base currency buys. The base currency itself may be left out.
+	Rates() (map[string]float64, error)
+}
+
+// Rate returns how many units of to one unit of from buys, crossing through
+// the provider's base currency.
+func Rate(p Provider, from, to string) (float64, error) {
+	if from == to {
+		return 1, nil
+	}
+	rates, err := p.Rates()
+	if err != nil {
+		return 0, err
+	}
+	quote := func(currency string) (float64, error) {
+		if currency == p.Base() {
+			return 1, nil
+		}
+		if r, ok := rates[currency]; ok && r > 0 {
+			return r, nil
+		}
+		return 0, fmt.Errorf("%w %s", ErrUnknownCurrency, currency)
+	}
+	fromRate, err := quote(from)
+	if err != nil {
+		return 0, err
+	}
+	toRate, err := quote(to)
+	if err != nil {
+		return 0, err
+	}
+	return toRate / fromRate, nil
+}
+
+// Static serves fixed rates, typically from configuration. Without rates it
+// only knows its base currency.
+type Static struct {
+	base  string
+	rates map[string]float64
+}
+
+var _ Provider = (*Static)(nil)
+
+func NewStatic(base string, rates map[string]float64) *Static {
+	return &Static{base: base, rates: rates}
+}
+
+func (s *Static) Base() string                       { return s.base }
+func (s *Static) Rates() (map[string]float64, error) { return s.rates, nil }
+
+// ParseRates reads rates written as "EUR=0.92,GBP=0.79".
+func ParseRates(s string) (map[string]float64, error) {
+	rates := map[string]float64{}
+	for _, pair := range strings.Split(s, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		code, value, ok := strings.Cut(pair, "=")
+		var rate float64
+		if ok {
+			_, err := fmt.Sscan(strings.TrimSpace(value), &rate)
+			ok = err == nil && rate > 0
+		}
+		if !ok {
+			return nil, fmt.Errorf("invalid exchange rate %q (want CODE=rate)", pair)
+		}
+		rates[strings.ToUpper(strings.TrimSpace(code))] = rate
+	}
+	return rates, nil
+}
+
+// HTTP fetches rates from an API and keeps them for ttl. The API is called
+// with GET <url>?base=<base> and answers 200 with a JSON body of the form
+// {"rates": {"EUR": 0.92, ...}}, as exchangerate.host and Frankfurter do.
+// When a refresh fails, the last rates are served until they are twice ttl
+// old.
+type HTTP struct {
+	base, url string
+	ttl       time.Duration
+	client    *http.Client
+
+	mu        sync.Mutex
+	rates     map[string]float64
+	fetchedAt time.Time
+}
+
+var _ Provider = (*HTTP)(nil)
+
+func NewHTTP(base, url string, ttl, timeout time.Duration) *HTTP {
+	return &HTTP{base: base, url: url, ttl: ttl, client: &http.Client{Timeout: timeout}}
+}
+
+func (h *HTTP) Base() string { return h.base }
+
+func (h *HTTP) Rates() (map[string]float64, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	age := time.Since(h.fetchedAt)
+	if h.rates != nil && age < h.ttl {
+		return h.rates, nil
+	}
+	rates, err := h.fetch()
+	if err != nil {
+		if h.rates != nil && age < 2*h.ttl {
+			return h.rates, nil
+		}
+		return nil, err
+	}
+	h.rates, h.fetchedAt = rates, time.Now()
+	return rates, nil
+}
+
+func (h *HTTP) fetch() (map[string]float64, error) {
+	u, err := url.Parse(h.url)
+	if err != nil {
+		return nil, err
+	}
+	q := u.Query()
+	q.Set("base", h.base)
+	u.RawQuery = q.Encode()
+
+	resp, err := h.client.Get(u.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach exchange rate API: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("exchange rate API answered %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+
+	var result struct {
+		Rates map[string]float64 `json:"rates"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("invalid exchange rate API response: %w", err)
+	}
+	if len(result.Rates) == 0 {
+		return nil, errors.New("exchange rate API returned no rates")
+	}
+	return result.Rates, nil
+}
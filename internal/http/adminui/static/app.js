@@ -0,0 +1,109 @@
+"use strict";
+
+// The token lives in sessionStorage, so closing the tab signs out.
+const tokenKey = "inventory-admin-token";
+const $ = (id) => document.getElementById(id);
+
+async function api(path) {
+  const resp = await fetch(path, { headers: { Authorization: "Bearer " + sessionStorage.getItem(tokenKey) } });
+  if (resp.status === 401 || resp.status === 403) {
+    signOut();
+    throw new Error("Your session has expired or lacks admin rights.");
+  }
+  if (!resp.ok) {
+    throw new Error(path + ": " + resp.status + " " + (await resp.text()));
+  }
+  return resp.json();
+}
+
+function rows(tableId, items, cells) {
+  const body = $(tableId).querySelector("tbody");
+  body.replaceChildren();
+  if (items.length === 0) {
+    const td = document.createElement("td");
+    td.colSpan = $(tableId).querySelectorAll("th").length;
+    td.textContent = "None";
+    body.append(document.createElement("tr"));
+    body.lastChild.append(td);
+    return;
+  }
+  for (const item of items) {
+    const tr = document.createElement("tr");
+    for (const [value, className] of cells(item)) {
+      const td = document.createElement("td");
+      td.textContent = value;
+      if (className) td.className = className;
+      tr.append(td);
+    }
+    body.append(tr);
+  }
+}
+
+const when = (t) => (t ? new Date(t).toLocaleString() : "never");
+
+async function load() {
+  $("error").textContent = "";
+  try {
+    const [metrics, lowStock, bans, jobs] = await Promise.all([
+      api("/metrics/dashboard"),
+      api("/products/filter?low_stock=true&limit=50&include_total=false"),
+      api("/admin/bans"),
+      api("/admin/jobs"),
+    ]);
+
+    const dl = $("metrics");
+    dl.replaceChildren();
+    for (const [key, value] of Object.entries(metrics)) {
+      if (value === null || typeof value === "object") continue;
+      const dt = document.createElement("dt");
+      dt.textContent = key.replaceAll("_", " ");
+      const dd = document.createElement("dd");
+      dd.textContent = value;
+      dl.append(dt, dd);
+    }
+
+    rows("low-stock", lowStock.data, (p) => [[p.name], [p.quantity, "number"], [p.threshold, "number"]]);
+    rows("bans", bans, (b) => [[b.id], [when(b.expires_at)]]);
+    rows("jobs", jobs, (j) => [
+      [j.name], [j.schedule], [when(j.last_run)], [j.runs, "number"], [j.failures, "number"],
+      [j.last_error || "", j.last_error ? "failed" : ""],
+    ]);
+  } catch (err) {
+    $("error").textContent = err.message;
+  }
+}
+
+function show() {
+  const signedIn = sessionStorage.getItem(tokenKey) !== null;
+  $("login").hidden = signedIn;
+  $("dashboard").hidden = !signedIn;
+  $("logout").hidden = !signedIn;
+  if (signedIn) load();
+}
+
+function signOut() {
+  sessionStorage.removeItem(tokenKey);
+  show();
+}
+
+$("login").addEventListener("submit", async (event) => {
+  event.preventDefault();
+  const form = new FormData(event.target);
+  $("login-error").textContent = "";
+  const resp = await fetch("/login", {
+    method: "POST",
+    headers: { "Content-Type": "application/json" },
+    body: JSON.stringify({ username: form.get("username"), password: form.get("password") }),
+  });
+  if (!resp.ok) {
+    $("login-error").textContent = resp.status === 429 ? "Too many attempts, try again later." : "Sign-in failed.";
+    return;
+  }
+  sessionStorage.setItem(tokenKey, (await resp.json()).access_token);
+  event.target.reset();
+  show();
+});
+
+$("logout").addEventListener("click", signOut);
+show();
+setInterval(() => { if (sessionStorage.getItem(tokenKey) !== null) load(); }, 60000);
@@ -24,7 +24,7 @@ var (
 	smtpPassword     = os.Getenv("SMTP_PASS")
 	smtpAuthDisabled = os.Getenv("SMTP_AUTH_DISABLED")
 
-	rdb *redis.Client
+	rdb redis.UniversalClient
 	ctx context.Context
 )
 
@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+)
+
+// accessScope returns the categories and warehouses the caller may see and
+// change. Admins and callers without grants are not restricted. Requests
+// without a valid token see nothing once any user has grants, so that a
+// restricted user cannot lift them by leaving the token out.
+func accessScope(r *http.Request) (repo.AccessScope, error) {
+	if role, _ := GetRoleFromContext(r); role == "admin" {
+		return repo.AccessScope{}, nil
+	}
+	username, _ := GetUsernameFromContext(r)
+	if username == "" {
+		inUse, err := accessGrantRepo.InUse()
+		if err != nil || !inUse {
+			return repo.AccessScope{}, err
+		}
+		return repo.AccessScope{Categories: []string{}, WarehouseIDs: []int{}}, nil
+	}
+	grants, err := accessGrantRepo.List(username)
+	if err != nil {
+		return repo.AccessScope{}, err
+	}
+	return repo.ScopeOf(grants), nil
+}
+
+// productInScope fails with repo.ErrProductNotFound when the product with id
+// is outside scope, so callers answer as if it did not exist.
+func productInScope(scope repo.AccessScope, id int) error {
+	if scope.Categories == nil {
+		return nil
+	}
+	p, err := productRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if !scope.AllowsCategory(p.Category) {
+		return repo.ErrProductNotFound
+	}
+	return nil
+}
+
+// warehouseInScope reports whether an adjustment of the warehouse, nil for
+// none, is allowed: users granted warehouses only change stock in those.
+func warehouseInScope(scope repo.AccessScope, id *int) bool {
+	if scope.WarehouseIDs == nil {
+		return true
+	}
+	return id != nil && scope.AllowsWarehouse(*id)
+}
+
+// productAccessible checks that the caller may see the product, writing
+// the error response itself if not: products outside the caller's grants
+// are answered with 404 as if they did not exist.
+func productAccessible(w http.ResponseWriter, r *http.Request, id int) bool {
+	scope, err := accessScope(r)
+	if err != nil {
+		http.Error(w, "could not load access grants", http.StatusInternalServerError)
+		return false
+	}
+	if err := productInScope(scope, id); err != nil {
+		if errors.Is(err, repo.ErrProductNotFound) {
+			http.Error(w, "product not found", http.StatusNotFound)
+			return false
+		}
+		http.Error(w, "could not fetch product", http.StatusInternalServerError)
+		return false
+	}
+	return true
+}
+
+// scopedProducts drops the products outside scope.
+func scopedProducts(scope repo.AccessScope, products []models.Product) []models.Product {
+	return slices.DeleteFunc(products, func(p models.Product) bool { return !scope.AllowsCategory(p.Category) })
+}
+
+// ListAccessGrantsHandler godoc
+// @Summary List a user's access grants
+// @Description A user with category grants only sees and changes products of those categories; one with warehouse grants only sees those warehouses and adjusts stock in them. A user without grants is not restricted.
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param username path string true "Username"
+// @Success 200 {array} models.AccessGrant
+// @Failure 403 {string} string "Forbidden"
+// @Failure 404 {string} string "User not found"
+// @Failure 500 {string} string "Internal error"
+// @Router /admin/users/{username}/grants [get]
+func ListAccessGrantsHandler(w http.ResponseWriter, r *http.Request) {
+	username := chi.URLParam(r, "username")
+	if _, err := userRepo.GetByUsername(username); err != nil {
+		http.Error(w, "user not found", http.StatusNotFound)
+		return
+	}
+
+	grants, err := accessGrantRepo.List(username)
+	if err != nil {
+		http.Error(w, "could not fetch access grants", http.StatusInternalServerError)
+		return
+	}
+	if err := writeJSON(w, http.StatusOK, grants); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// CreateAccessGrantHandler godoc
+// @Summary Grant a user a category or warehouse
+// @Description Restricts the user to the granted categories or warehouses, adding to their earlier grants of the same kind. The first grant of a kind takes away everything else of that kind.
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param username path string true "Username"
+// @Param grant body AccessGrantRequest true "Category or warehouse ID"
+// @Success 201 {object} models.AccessGrant
+// @Failure 400 {string} string "Invalid input"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 404 {string} string "User or warehouse not found"
+// @Failure 409 {string} string "Already granted"
+// @Failure 500 {string} string "Internal error"
+// @Router /admin/users/{username}/grants [post]
+func CreateAccessGrantHandler(w http.ResponseWriter, r *http.Request) {
+	username := chi.URLParam(r, "username")
+	var req AccessGrantRequest
+	if err := readJSON(w, r, &req); err != nil {
+		http.Error(w, "invalid input", http.StatusBadRequest)
+		return
+	}
+	g := models.AccessGrant{Username: username, Category: strings.ToLower(strings.TrimSpace(req.Category)), WarehouseID: req.WarehouseID}
+	if (g.Category == "") == (g.WarehouseID == nil) {
+		http.Error(w, "exactly one of category and warehouse_id is required", http.StatusBadRequest)
+		return
+	}
+
+	user, err := userRepo.GetByUsername(username)
+	if err != nil {
+		http.Error(w, "user not found", http.StatusNotFound)
+		return
+	}
+	if user.Role == "admin" {
+		http.Error(w, "admins are not restricted by grants", http.StatusBadRequest)
+		return
+	}
+	g.CreatedBy, _ = GetUsernameFromContext(r)
+
+	created, err := accessGrantRepo.Create(g)
+	if err != nil {
+		switch {
+		case errors.Is(err, repo.ErrWarehouseNotFound):
+			http.Error(w, "warehouse not found", http.StatusNotFound)
+		case errors.Is(err, repo.ErrDuplicatedValueUnique):
+			http.Error(w, "the user already has this grant", http.StatusConflict)
+		default:
+			http.Error(w, "could not create access grant", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	recordAudit(r, "grant", "user", username, created)
+	if err := writeJSON(w, http.StatusCreated, created); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// DeleteAccessGrantHandler godoc
+// @Summary Remove an access grant
+// @Description Removing a user's last grant of a kind lifts that restriction.
+// @Tags admin
+// @Security BearerAuth
+// @Param username path string true "Username"
+// @Param id path int true "Grant ID"
+// @Success 204 "Removed"
+// @Failure 400 {string} string "Invalid ID"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 404 {string} string "Grant not found"
+// @Failure 500 {string} string "Internal error"
+// @Router /admin/users/{username}/grants/{id} [delete]
+func DeleteAccessGrantHandler(w http.ResponseWriter, r *http.Request) {
+	username := chi.URLParam(r, "username")
+	id, err := parseID(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid grant ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := accessGrantRepo.Delete(username, id); err != nil {
+		if errors.Is(err, repo.ErrAccessGrantNotFound) {
+			http.Error(w, "access grant not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "could not remove access grant", http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(r, "revoke_grant", "user", username, map[string]int{"grant_id": id})
+	w.WriteHeader(http.StatusNoContent)
+}
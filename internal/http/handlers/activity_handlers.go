@@ -0,0 +1,343 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rogerio-castellano/inventory-tracker/internal/auth"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+)
+
+const (
+	defaultActivityLimit = 50
+	maxActivityLimit     = 200
+
+	// defaultMyActivityWindow covers a shift and then some.
+	defaultMyActivityWindow = 24 * time.Hour
+)
+
+// Activity item types.
+const (
+	ActivityMovement = "movement"
+	ActivityRevision = "revision"
+	ActivityImport   = "import"
+	ActivityAlert    = "alert"
+	ActivityEvent    = "event"
+)
+
+// revisionActions are the audited product actions that change its fields.
+var revisionActions = map[string]bool{"create": true, "update": true, "reprice": true}
+
+// movementActions are audited actions already shown by their movements.
+var movementActions = map[string]bool{"adjust": true, "adjust-batch": true}
+
+// activityIgnoredFields are product fields left out of revision diffs: they
+// change as a side effect of every write.
+var activityIgnoredFields = map[string]bool{"created_at": true, "updated_at": true, "baseline_quantity": true}
+
+// activityCursor is the position of the last item returned; the next page
+// starts right after it in the feed's newest-first order.
+type activityCursor struct {
+	At time.Time `json:"at"`
+	ID string    `json:"id"`
+}
+
+// GetProductActivityHandler godoc
+// @Summary Product activity feed
+// @Description Interleaves the product's movements, field changes, import touches, low-stock alerts and other audited events, newest first. Alerts are derived from the movement history: one is raised by each movement that takes the stock below the product's current threshold.
+// @Tags products
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param cursor query string false "Cursor returned by the previous page"
+// @Param limit query int false "Maximum items (default 50, max 200)"
+// @Success 200 {object} ActivityFeed
+// @Failure 400 {string} string "Invalid ID, cursor or limit"
+// @Failure 404 {string} string "Product not found"
+// @Failure 500 {string} string "Internal error"
+// @Router /products/{id}/activity [get]
+func GetProductActivityHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid product ID", http.StatusBadRequest)
+		return
+	}
+	cursor, err := decodeActivityCursor(r.URL.Query().Get("cursor"))
+	if err != nil {
+		http.Error(w, "invalid cursor", http.StatusBadRequest)
+		return
+	}
+	limit := defaultActivityLimit
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		limit = min(n, maxActivityLimit)
+	}
+
+	product, err := productRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, repo.ErrProductNotFound) {
+			http.Error(w, "product not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "could not fetch product", http.StatusInternalServerError)
+		return
+	}
+	movements, err := movementRepo.GetHistory(id)
+	if err != nil {
+		log.Printf("failed to load movements of product %d: %v", id, err)
+		http.Error(w, "could not fetch activity", http.StatusInternalServerError)
+		return
+	}
+	entries, err := auditRepo.Find(repo.AuditFilter{Entity: "product", EntityID: strconv.Itoa(id)})
+	if err != nil {
+		log.Printf("failed to load audit entries of product %d: %v", id, err)
+		http.Error(w, "could not fetch activity", http.StatusInternalServerError)
+		return
+	}
+
+	role, _ := GetRoleFromContext(r)
+	items := append(movementActivity(product, movements), auditActivity(entries, auth.HasPermission(role, auth.PermPricingRead))...)
+	sort.Slice(items, func(i, j int) bool { return items[i].position().precedes(items[j].position()) })
+
+	start := 0
+	if cursor != nil {
+		start = sort.Search(len(items), func(i int) bool { return cursor.precedes(items[i].position()) })
+	}
+	page := items[start:min(start+limit, len(items))]
+
+	feed := ActivityFeed{Items: page}
+	if start+len(page) < len(items) {
+		if feed.NextCursor, err = encodeActivityCursor(page[len(page)-1].position()); err != nil {
+			http.Error(w, "could not encode cursor", http.StatusInternalServerError)
+			return
+		}
+	}
+	if err := writeJSON(w, http.StatusOK, feed); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// MeActivityHandler godoc
+// @Summary Recent actions of the current user
+// @Description Lists what the caller recorded, such as adjustments, imports and product edits, newest first, so it can be checked against the shift's paperwork.
+// @Tags auth
+// @Security BearerAuth
+// @Produce json
+// @Param since query string false "Only actions from this timestamp (RFC3339), defaults to 24 hours ago"
+// @Param limit query int false "Maximum actions (default 50, max 200)"
+// @Success 200 {array} UserAction
+// @Failure 400 {string} string "Invalid since or limit"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 500 {string} string "Internal error"
+// @Router /me/activity [get]
+func MeActivityHandler(w http.ResponseWriter, r *http.Request) {
+	username, err := GetUsernameFromContext(r)
+	if err != nil || username == "" {
+		http.Error(w, "invalid token", http.StatusUnauthorized)
+		return
+	}
+	since, err := parseTime(r.URL.Query().Get("since"))
+	if err != nil {
+		http.Error(w, "invalid since date format", http.StatusBadRequest)
+		return
+	}
+	if since == nil {
+		t := time.Now().Add(-defaultMyActivityWindow)
+		since = &t
+	}
+	limit := defaultActivityLimit
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		limit = min(n, maxActivityLimit)
+	}
+
+	entries, err := auditRepo.Find(repo.AuditFilter{Actor: username, Since: since})
+	if err != nil {
+		http.Error(w, "could not retrieve activity", http.StatusInternalServerError)
+		return
+	}
+
+	actions := make([]UserAction, 0, min(len(entries), limit))
+	for i := len(entries) - 1; i >= 0 && len(actions) < limit; i-- {
+		e := entries[i]
+		actions = append(actions, UserAction{
+			ID:       e.ID,
+			At:       e.CreatedAt.UTC().Format(time.RFC3339),
+			Action:   e.Action,
+			Entity:   e.Entity,
+			EntityID: e.EntityID,
+			Details:  e.Details,
+		})
+	}
+	if err := writeJSON(w, http.StatusOK, actions); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// precedes reports whether c comes before o in the feed: newest first, ties
+// broken by ID so that higher serial IDs of the same kind come first.
+func (c activityCursor) precedes(o activityCursor) bool {
+	if !c.At.Equal(o.At) {
+		return c.At.After(o.At)
+	}
+	if len(c.ID) != len(o.ID) {
+		return len(c.ID) > len(o.ID)
+	}
+	return c.ID > o.ID
+}
+
+// movementActivity turns the movement history into feed items and derives
+// the low-stock alerts, replaying the stock level from the baseline.
+func movementActivity(product models.Product, movements []models.Movement) []ActivityItem {
+	items := make([]ActivityItem, 0, len(movements))
+	quantity := product.BaselineQuantity
+	for _, m := range movements {
+		at, err := time.Parse(time.RFC3339, m.CreatedAt)
+		if err != nil {
+			log.Printf("skipping movement %d with invalid timestamp %q", m.ID, m.CreatedAt)
+			continue
+		}
+		before := quantity
+		quantity += m.Delta
+		delta, after := m.Delta, quantity
+		items = append(items, ActivityItem{
+			ID:       fmt.Sprintf("movement:%d", m.ID),
+			Type:     ActivityMovement,
+			At:       at.Format(time.RFC3339),
+			Delta:    &delta,
+			Quantity: &after,
+			at:       at,
+		})
+		if before >= product.Threshold && quantity < product.Threshold {
+			items = append(items, ActivityItem{
+				ID:       fmt.Sprintf("alert:%d", m.ID),
+				Type:     ActivityAlert,
+				At:       at.Format(time.RFC3339),
+				Action:   "low_stock",
+				Quantity: &after,
+				at:       at,
+			})
+		}
+	}
+	return items
+}
+
+// auditActivity turns audit entries about the product into feed items.
+// Revisions and import touches carry the fields they changed.
+func auditActivity(entries []models.AuditEntry, pricing bool) []ActivityItem {
+	items := make([]ActivityItem, 0, len(entries))
+	for _, e := range entries {
+		if movementActions[e.Action] {
+			continue
+		}
+		item := ActivityItem{
+			ID:     fmt.Sprintf("audit:%d", e.ID),
+			Type:   ActivityEvent,
+			At:     e.CreatedAt.UTC().Format(time.RFC3339),
+			Actor:  e.Actor,
+			Action: e.Action,
+			at:     e.CreatedAt.UTC().Truncate(time.Second),
+		}
+		switch {
+		case e.Action == "import":
+			item.Type = ActivityImport
+			item.Changes = revisionChanges(e.Action, e.Details, pricing)
+		case revisionActions[e.Action]:
+			item.Type = ActivityRevision
+			item.Changes = revisionChanges(e.Action, e.Details, pricing)
+		default:
+			item.Details = e.Details
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// revisionChanges lists the fields changed by an audited revision. Pricing
+// fields are dropped for callers that may not see them.
+func revisionChanges(action string, details json.RawMessage, pricing bool) []FieldChange {
+	var changes []FieldChange
+	if action == "reprice" {
+		var d struct {
+			PriceBefore float64 `json:"price_before"`
+			PriceAfter  float64 `json:"price_after"`
+		}
+		if err := json.Unmarshal(details, &d); err == nil {
+			changes = []FieldChange{{Field: "price", From: d.PriceBefore, To: d.PriceAfter}}
+		}
+	} else {
+		var d struct {
+			Before map[string]any `json:"before"`
+			After  map[string]any `json:"after"`
+		}
+		if err := json.Unmarshal(details, &d); err != nil || d.Before == nil || d.After == nil {
+			return nil
+		}
+		fields := make([]string, 0, len(d.After))
+		for field := range d.After {
+			fields = append(fields, field)
+		}
+		for field := range d.Before {
+			if _, ok := d.After[field]; !ok {
+				fields = append(fields, field)
+			}
+		}
+		sort.Strings(fields)
+		for _, field := range fields {
+			if activityIgnoredFields[field] || reflect.DeepEqual(d.Before[field], d.After[field]) {
+				continue
+			}
+			changes = append(changes, FieldChange{Field: field, From: d.Before[field], To: d.After[field]})
+		}
+	}
+	if !pricing {
+		changes = slices.DeleteFunc(changes, func(c FieldChange) bool { return slices.Contains(auth.PricingFields, c.Field) })
+	}
+	return changes
+}
+
+func decodeActivityCursor(raw string) (*activityCursor, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, err
+	}
+	var cursor activityCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, err
+	}
+	if cursor.At.IsZero() || !strings.Contains(cursor.ID, ":") {
+		return nil, errors.New("incomplete cursor")
+	}
+	return &cursor, nil
+}
+
+func encodeActivityCursor(cursor activityCursor) (string, error) {
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rogerio-castellano/inventory-tracker/internal/auth"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+)
+
+var errAdjustmentLimitExceeded = errors.New("adjustment limit exceeded")
+
+// adjustmentLimitOf returns the adjustment limit of the product, or the
+// global one when it has none. The zero limit allows anything.
+func adjustmentLimitOf(productID int) (models.AdjustmentLimit, error) {
+	limits, err := adjustmentLimitRepo.List()
+	if err != nil {
+		return models.AdjustmentLimit{}, fmt.Errorf("could not fetch adjustment limits: %w", err)
+	}
+	var limit models.AdjustmentLimit
+	for _, l := range limits {
+		if l.ProductID == productID || (l.ProductID == 0 && limit.ID == 0) {
+			limit = l
+		}
+	}
+	return limit, nil
+}
+
+// checkAdjustmentLimit compares the size of an adjustment by delta with
+// limit. It returns a warning above the warning tier, and above the maximum
+// errAdjustmentLimitExceeded, or a warning for callers allowed to override.
+func checkAdjustmentLimit(r *http.Request, limit models.AdjustmentLimit, delta int) ([]string, error) {
+	size := delta
+	if size < 0 {
+		size = -size
+	}
+	switch {
+	case limit.MaxDelta > 0 && size > limit.MaxDelta:
+		msg := fmt.Sprintf("adjustment of %+d units exceeds the maximum of %d", delta, limit.MaxDelta)
+		if role, err := GetRoleFromContext(r); err != nil || !auth.HasPermission(role, auth.PermAdjustmentOverride) {
+			return nil, fmt.Errorf("%w: %s", errAdjustmentLimitExceeded, msg)
+		}
+		return []string{msg + "; allowed by override"}, nil
+	case limit.WarnDelta > 0 && size > limit.WarnDelta:
+		return []string{fmt.Sprintf("adjustment of %+d units is over the warning limit of %d", delta, limit.WarnDelta)}, nil
+	}
+	return nil, nil
+}
+
+// checkProductAdjustment checks an adjustment of the product by delta
+// against its adjustment limit.
+func checkProductAdjustment(r *http.Request, productID, delta int) ([]string, error) {
+	limit, err := adjustmentLimitOf(productID)
+	if err != nil {
+		return nil, err
+	}
+	return checkAdjustmentLimit(r, limit, delta)
+}
+
+// writeAdjustmentLimitError answers for an error of checkAdjustmentLimit.
+func writeAdjustmentLimitError(w http.ResponseWriter, err error) {
+	if errors.Is(err, errAdjustmentLimitExceeded) {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	log.Printf("adjustment limit: %v", err)
+	http.Error(w, "could not verify adjustment limits", http.StatusInternalServerError)
+}
+
+// PutAdjustmentLimitHandler godoc
+// @Summary Limit the size of stock adjustments
+// @Description Sets how many units a single adjustment of the product, or of every product without a limit of its own when product_id is left out, may add or remove. Larger than warn_delta it is applied with a warning; larger than max_delta it is refused with 422 unless the caller holds the adjustments:override permission. Either may be left out. Setting the limit of the same product again replaces it.
+// @Tags inventory
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param limit body AdjustmentLimitRequest true "Limit"
+// @Success 200 {object} models.AdjustmentLimit
+// @Failure 400 {string} string "Invalid input"
+// @Failure 404 {string} string "Product not found"
+// @Failure 500 {string} string "Internal error"
+// @Router /admin/adjustment-limits [put]
+func PutAdjustmentLimitHandler(w http.ResponseWriter, r *http.Request) {
+	var req AdjustmentLimitRequest
+	if err := readJSON(w, r, &req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	l := models.AdjustmentLimit{ProductID: req.ProductID, WarnDelta: req.WarnDelta, MaxDelta: req.MaxDelta}
+	switch {
+	case l.ProductID < 0:
+		http.Error(w, "invalid product ID", http.StatusBadRequest)
+		return
+	case l.WarnDelta < 0 || l.MaxDelta < 0:
+		http.Error(w, "warn_delta and max_delta cannot be negative", http.StatusBadRequest)
+		return
+	case l.WarnDelta == 0 && l.MaxDelta == 0:
+		http.Error(w, "warn_delta or max_delta is required", http.StatusBadRequest)
+		return
+	case l.MaxDelta > 0 && l.WarnDelta >= l.MaxDelta:
+		http.Error(w, "warn_delta must be below max_delta", http.StatusBadRequest)
+		return
+	}
+	if l.ProductID != 0 {
+		if _, err := productRepo.GetByID(l.ProductID); err != nil {
+			if errors.Is(err, repo.ErrProductNotFound) {
+				http.Error(w, "product not found", http.StatusNotFound)
+				return
+			}
+			http.Error(w, "could not fetch product", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	saved, err := adjustmentLimitRepo.Put(l)
+	if err != nil {
+		http.Error(w, "could not save adjustment limit", http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(r, "set", "adjustment_limit", saved.ID, saved)
+	if err := writeJSON(w, http.StatusOK, saved); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// ListAdjustmentLimitsHandler godoc
+// @Summary List stock adjustment limits
+// @Tags inventory
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.AdjustmentLimit
+// @Failure 500 {string} string "Internal error"
+// @Router /admin/adjustment-limits [get]
+func ListAdjustmentLimitsHandler(w http.ResponseWriter, r *http.Request) {
+	limits, err := adjustmentLimitRepo.List()
+	if err != nil {
+		http.Error(w, "could not fetch adjustment limits", http.StatusInternalServerError)
+		return
+	}
+	if err := writeJSON(w, http.StatusOK, limits); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// DeleteAdjustmentLimitHandler godoc
+// @Summary Remove a stock adjustment limit
+// @Tags inventory
+// @Security BearerAuth
+// @Param id path int true "Adjustment limit ID"
+// @Success 204 "No Content"
+// @Failure 400 {string} string "Invalid ID"
+// @Failure 404 {string} string "Adjustment limit not found"
+// @Failure 500 {string} string "Internal error"
+// @Router /admin/adjustment-limits/{id} [delete]
+func DeleteAdjustmentLimitHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid adjustment limit ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := adjustmentLimitRepo.Delete(id); err != nil {
+		if errors.Is(err, repo.ErrAdjustmentLimitNotFound) {
+			http.Error(w, "adjustment limit not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "could not delete adjustment limit", http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(r, "delete", "adjustment_limit", id, nil)
+	w.WriteHeader(http.StatusNoContent)
+}
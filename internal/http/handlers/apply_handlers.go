@@ -0,0 +1,372 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"maps"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/auth"
+	rl "github.com/rogerio-castellano/inventory-tracker/internal/http/rate_limiter"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+	"gopkg.in/yaml.v3"
+)
+
+const maxManifestBytes = 1 << 20
+
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+
+// ApplyConfigHandler godoc
+// @Summary Apply a configuration manifest
+// @Description Reconciles roles, per-role rate limits, the product validation policy and the webhook subscriptions with a YAML or JSON manifest and reports what changed. Sections left out are not touched; a section that is present replaces the live one. Admins always hold every permission, so the admin role cannot be listed under roles. Webhooks are matched by URL: their events are updated in place, and the secret of each webhook added is returned once in webhook_secrets. This deployment has no alert rules, so that section must be empty. Other instances pick up the roles and rate limits when they restart.
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Accept x-yaml
+// @Produce json
+// @Param manifest body ConfigManifest true "Desired configuration"
+// @Param dry_run query bool false "Only report the changes"
+// @Success 200 {object} ApplyResult
+// @Failure 400 {string} string "Invalid manifest"
+// @Failure 500 {string} string "Internal error"
+// @Router /admin/apply [post]
+func ApplyConfigHandler(w http.ResponseWriter, r *http.Request) {
+	dryRun := false
+	if raw := r.URL.Query().Get("dry_run"); raw != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(raw); err != nil {
+			http.Error(w, "dry_run must be a boolean", http.StatusBadRequest)
+			return
+		}
+	}
+
+	manifest, err := readManifest(w, r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid manifest: %v", err), http.StatusBadRequest)
+		return
+	}
+	if len(manifest.AlertRules) > 0 {
+		http.Error(w, "alert_rules are not supported by this server", http.StatusBadRequest)
+		return
+	}
+
+	var roles map[string][]string
+	if manifest.Roles != nil {
+		if roles, err = manifestRoles(manifest.Roles); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	var limits map[string]rl.RoleLimit
+	if manifest.RateLimits != nil {
+		if limits, err = manifestRateLimits(manifest.RateLimits); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	var fields []string
+	if manifest.ValidationPolicy != nil {
+		if fields, err = normalizeRequiredFields(manifest.ValidationPolicy.RequiredFields); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	var hooks []models.Webhook
+	if manifest.Webhooks != nil {
+		if hooks, err = manifestWebhooks(manifest.Webhooks); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	changes := []ConfigChange{}
+	if roles != nil {
+		changes = append(changes, roleChanges(auth.RolePermissions(), roles)...)
+	}
+	if limits != nil {
+		changes = append(changes, rateLimitChanges(rl.RoleLimits(), limits)...)
+	}
+	policy, err := validationPolicyRepo.Get(tenant)
+	if err != nil {
+		http.Error(w, "could not fetch validation policy", http.StatusInternalServerError)
+		return
+	}
+	policyChanged := fields != nil && !slices.Equal(policy.RequiredFields, fields)
+	if policyChanged {
+		changes = append(changes, ConfigChange{Section: "validation_policy", Key: "required_fields", Action: "update", Before: policy.RequiredFields, After: fields})
+	}
+	var plan webhookPlan
+	if hooks != nil {
+		live, err := webhookRepo.List()
+		if err != nil {
+			http.Error(w, "could not fetch webhooks", http.StatusInternalServerError)
+			return
+		}
+		var hookChanges []ConfigChange
+		hookChanges, plan = planWebhooks(live, hooks)
+		changes = append(changes, hookChanges...)
+	}
+
+	if dryRun || len(changes) == 0 {
+		if err := writeJSON(w, http.StatusOK, ApplyResult{DryRun: dryRun, Changes: changes}); err != nil {
+			log.Printf("Failed to write JSON response: %v", err)
+		}
+		return
+	}
+
+	config, err := runtimeConfigRepo.Get(tenant)
+	if err != nil {
+		http.Error(w, "could not fetch configuration", http.StatusInternalServerError)
+		return
+	}
+	if roles != nil {
+		config.Roles = roles
+	}
+	if manifest.RateLimits != nil {
+		config.RateLimits = manifest.RateLimits
+	}
+	if config, err = runtimeConfigRepo.Put(config); err != nil {
+		http.Error(w, "could not save configuration", http.StatusInternalServerError)
+		return
+	}
+	applyRuntimeConfig(config)
+	if policyChanged {
+		if _, err := validationPolicyRepo.Put(models.ValidationPolicy{Tenant: tenant, RequiredFields: fields}); err != nil {
+			http.Error(w, "could not save validation policy", http.StatusInternalServerError)
+			return
+		}
+	}
+	secrets, err := applyWebhookPlan(r, plan)
+	if err != nil {
+		log.Printf("apply: %v", err)
+		http.Error(w, "could not save webhooks", http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(r, "apply", "config", tenant, map[string]any{"changes": changes})
+	if err := writeJSON(w, http.StatusOK, ApplyResult{Changes: changes, WebhookSecrets: secrets}); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// LoadRuntimeConfig puts the configuration last applied through
+// POST /admin/apply into effect; it is called once at startup.
+func LoadRuntimeConfig() error {
+	config, err := runtimeConfigRepo.Get(tenant)
+	if err != nil {
+		return err
+	}
+	applyRuntimeConfig(config)
+	return nil
+}
+
+// applyRuntimeConfig swaps the live role table and rate limits; nil
+// sections restore the built-in defaults. The config was validated when it
+// was applied.
+func applyRuntimeConfig(config models.RuntimeConfig) {
+	auth.SetRolePermissions(config.Roles)
+	limits, _ := manifestRateLimits(config.RateLimits)
+	rl.SetRoleLimits(limits)
+}
+
+// readManifest decodes a YAML manifest; JSON is accepted as the YAML subset
+// it is. Unknown keys are rejected so typos don't silently do nothing.
+func readManifest(w http.ResponseWriter, r *http.Request) (ConfigManifest, error) {
+	var manifest ConfigManifest
+	dec := yaml.NewDecoder(http.MaxBytesReader(w, r.Body, maxManifestBytes))
+	dec.KnownFields(true)
+	if err := dec.Decode(&manifest); err != nil {
+		if errors.Is(err, io.EOF) {
+			return manifest, errors.New("empty body")
+		}
+		return manifest, err
+	}
+	return manifest, nil
+}
+
+func manifestRoles(in map[string]ManifestRole) (map[string][]string, error) {
+	roles := make(map[string][]string, len(in))
+	for name, role := range in {
+		if !roleNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("invalid role name %q", name)
+		}
+		if name == "admin" {
+			return nil, errors.New("the admin role always holds every permission and cannot be configured")
+		}
+		perms := []string{}
+		for _, p := range role.Permissions {
+			p = strings.TrimSpace(p)
+			if !slices.Contains(auth.KnownPermissions, p) {
+				return nil, fmt.Errorf("role %s: unknown permission %q; allowed: %s", name, p, strings.Join(auth.KnownPermissions, ", "))
+			}
+			if !slices.Contains(perms, p) {
+				perms = append(perms, p)
+			}
+		}
+		slices.Sort(perms)
+		roles[name] = perms
+	}
+	return roles, nil
+}
+
+// manifestRateLimits converts the manifest limits; the guest entry also
+// covers roles without one of their own.
+func manifestRateLimits(in map[string]models.RateLimitSetting) (map[string]rl.RoleLimit, error) {
+	if in == nil {
+		return nil, nil
+	}
+	limits := make(map[string]rl.RoleLimit, len(in))
+	for role, s := range in {
+		if !roleNamePattern.MatchString(role) {
+			return nil, fmt.Errorf("invalid role name %q", role)
+		}
+		if strings.TrimSpace(s.Tier) == "" || s.MaxRequests <= 0 || s.WindowSeconds <= 0 {
+			return nil, fmt.Errorf("rate limit of %s needs a tier and a positive max_requests and window_seconds", role)
+		}
+		limits[role] = rl.RoleLimit{Tier: strings.TrimSpace(s.Tier), MaxRequests: s.MaxRequests, Window: time.Duration(s.WindowSeconds) * time.Second}
+	}
+	return limits, nil
+}
+
+func roleChanges(live, desired map[string][]string) []ConfigChange {
+	var changes []ConfigChange
+	for _, name := range sortedUnion(live, desired) {
+		before, had := live[name]
+		after, wants := desired[name]
+		before = slices.Sorted(slices.Values(before))
+		switch {
+		case !wants:
+			changes = append(changes, ConfigChange{Section: "roles", Key: name, Action: "remove", Before: before})
+		case !had:
+			changes = append(changes, ConfigChange{Section: "roles", Key: name, Action: "add", After: after})
+		case !slices.Equal(before, after):
+			changes = append(changes, ConfigChange{Section: "roles", Key: name, Action: "update", Before: before, After: after})
+		}
+	}
+	return changes
+}
+
+func rateLimitChanges(live, desired map[string]rl.RoleLimit) []ConfigChange {
+	setting := func(l rl.RoleLimit) models.RateLimitSetting {
+		return models.RateLimitSetting{Tier: l.Tier, MaxRequests: l.MaxRequests, WindowSeconds: int(l.Window.Seconds())}
+	}
+	var changes []ConfigChange
+	for _, role := range sortedUnion(live, desired) {
+		before, had := live[role]
+		after, wants := desired[role]
+		switch {
+		case !wants:
+			changes = append(changes, ConfigChange{Section: "rate_limits", Key: role, Action: "remove", Before: setting(before)})
+		case !had:
+			changes = append(changes, ConfigChange{Section: "rate_limits", Key: role, Action: "add", After: setting(after)})
+		case before != after:
+			changes = append(changes, ConfigChange{Section: "rate_limits", Key: role, Action: "update", Before: setting(before), After: setting(after)})
+		}
+	}
+	return changes
+}
+
+// manifestWebhooks validates the webhooks of a manifest; each URL may only
+// be listed once, since it is what matches them to the live ones.
+func manifestWebhooks(in []WebhookRequest) ([]models.Webhook, error) {
+	hooks := make([]models.Webhook, 0, len(in))
+	for _, req := range in {
+		hook, err := newWebhook(req)
+		if err != nil {
+			return nil, fmt.Errorf("webhook %s: %w", strings.TrimSpace(req.URL), err)
+		}
+		if slices.ContainsFunc(hooks, func(h models.Webhook) bool { return h.URL == hook.URL }) {
+			return nil, fmt.Errorf("webhook %s is listed more than once", hook.URL)
+		}
+		hooks = append(hooks, hook)
+	}
+	return hooks, nil
+}
+
+// webhookPlan is what applying the webhooks section does: the webhooks to
+// create, those whose events change (by ID) and the IDs to delete.
+type webhookPlan struct {
+	add    []models.Webhook
+	update []models.Webhook
+	remove []int
+}
+
+// planWebhooks matches the live webhooks to the desired ones by URL. A URL
+// subscribed more than once keeps its oldest webhook.
+func planWebhooks(live, desired []models.Webhook) ([]ConfigChange, webhookPlan) {
+	var changes []ConfigChange
+	var plan webhookPlan
+	matched := map[string]bool{}
+	for _, h := range live {
+		i := slices.IndexFunc(desired, func(d models.Webhook) bool { return d.URL == h.URL })
+		if i < 0 || matched[h.URL] {
+			plan.remove = append(plan.remove, h.ID)
+			changes = append(changes, ConfigChange{Section: "webhooks", Key: h.URL, Action: "remove", Before: h.Events})
+			continue
+		}
+		matched[h.URL] = true
+		before, after := slices.Sorted(slices.Values(h.Events)), slices.Sorted(slices.Values(desired[i].Events))
+		if !slices.Equal(before, after) {
+			plan.update = append(plan.update, models.Webhook{ID: h.ID, Events: desired[i].Events})
+			changes = append(changes, ConfigChange{Section: "webhooks", Key: h.URL, Action: "update", Before: h.Events, After: desired[i].Events})
+		}
+	}
+	for _, d := range desired {
+		if !matched[d.URL] {
+			plan.add = append(plan.add, d)
+			changes = append(changes, ConfigChange{Section: "webhooks", Key: d.URL, Action: "add", After: d.Events})
+		}
+	}
+	slices.SortStableFunc(changes, func(a, b ConfigChange) int { return strings.Compare(a.Key, b.Key) })
+	return changes, plan
+}
+
+// applyWebhookPlan carries out plan and returns the secrets of the webhooks
+// it created, by URL.
+func applyWebhookPlan(r *http.Request, plan webhookPlan) (map[string]string, error) {
+	for _, id := range plan.remove {
+		if err := webhookRepo.Delete(id); err != nil && !errors.Is(err, repo.ErrWebhookNotFound) {
+			return nil, fmt.Errorf("delete webhook %d: %w", id, err)
+		}
+	}
+	for _, h := range plan.update {
+		if _, err := webhookRepo.SetEvents(h.ID, h.Events); err != nil {
+			return nil, fmt.Errorf("update webhook %d: %w", h.ID, err)
+		}
+	}
+	var secrets map[string]string
+	createdBy, _ := GetUsernameFromContext(r)
+	for _, h := range plan.add {
+		h.Secret, h.CreatedBy = rand.Text(), createdBy
+		created, err := webhookRepo.Create(h)
+		if err != nil {
+			return nil, fmt.Errorf("create webhook %s: %w", h.URL, err)
+		}
+		if secrets == nil {
+			secrets = map[string]string{}
+		}
+		secrets[created.URL] = created.Secret
+	}
+	return secrets, nil
+}
+
+func sortedUnion[V any](a, b map[string]V) []string {
+	keys := slices.Collect(maps.Keys(a))
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
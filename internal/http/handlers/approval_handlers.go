@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rogerio-castellano/inventory-tracker/internal/mailer"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+)
+
+// requestProductDeletion records a pending request to delete p for a second
+// admin to approve, instead of deleting it.
+func requestProductDeletion(w http.ResponseWriter, r *http.Request, p models.Product) {
+	requester, err := GetUsernameFromContext(r)
+	if err != nil {
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	approval, err := approvalRepo.Create(models.Approval{
+		Action:      models.ApprovalActionDeleteProduct,
+		Entity:      "product",
+		EntityID:    p.ID,
+		Details:     fmt.Sprintf("%s (%d units in stock)", p.Name, p.Quantity),
+		RequestedBy: requester,
+	})
+	if err != nil {
+		if errors.Is(err, repo.ErrDuplicatedValueUnique) {
+			http.Error(w, "a deletion request for this product is already pending", http.StatusConflict)
+			return
+		}
+		http.Error(w, "could not request deletion", http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(r, "request_delete", "product", p.ID, approval)
+	go notifyApproval(approval)
+	if err := writeJSON(w, http.StatusAccepted, approval); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// notifyApproval emails the requester of a and, once it is decided, the
+// admin who decided it. Pending requests go to the alert recipient too, so
+// another admin picks them up.
+func notifyApproval(a models.Approval) {
+	subject := fmt.Sprintf("Approval #%d %s: %s", a.ID, a.Status, a.Action)
+	body := fmt.Sprintf("<h2>🔏 Approval #%d</h2><p>%s %s %d: %s</p><p>Requested by <strong>%s</strong>; status <strong>%s</strong>",
+		a.ID, html.EscapeString(a.Action), html.EscapeString(a.Entity), a.EntityID, html.EscapeString(a.Details), html.EscapeString(a.RequestedBy), a.Status)
+	if a.DecidedBy != "" {
+		body += fmt.Sprintf(" by <strong>%s</strong>", html.EscapeString(a.DecidedBy))
+	}
+	body += ".</p>"
+
+	recipients := []string{a.RequestedBy}
+	if a.Status == models.ApprovalStatusPending {
+		if err := mailer.SendHTML(subject, body); err != nil {
+			log.Printf("approval %d: could not notify admins: %v", a.ID, err)
+		}
+	} else if a.DecidedBy != a.RequestedBy {
+		recipients = append(recipients, a.DecidedBy)
+	}
+	for _, username := range recipients {
+		user, err := userRepo.GetByUsername(username)
+		if err != nil || user.Email == "" {
+			continue
+		}
+		if err := mailer.SendHTMLTo(user.Email, subject, body); err != nil {
+			log.Printf("approval %d: could not notify %s: %v", a.ID, username, err)
+		}
+	}
+}
+
+// ListApprovalsHandler godoc
+// @Summary List approval requests
+// @Description With PRODUCT_DELETE_APPROVAL on, deleting a product that has stock creates a pending request that another admin approves or rejects here.
+// @Tags approvals
+// @Security BearerAuth
+// @Produce json
+// @Param status query string false "pending (default), approved, rejected or all"
+// @Success 200 {array} models.Approval
+// @Failure 400 {string} string "Invalid status"
+// @Failure 403 {string} string "Admins only"
+// @Failure 500 {string} string "Internal error"
+// @Router /approvals [get]
+func ListApprovalsHandler(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "":
+		status = models.ApprovalStatusPending
+	case "all":
+		status = ""
+	case models.ApprovalStatusPending, models.ApprovalStatusApproved, models.ApprovalStatusRejected:
+	default:
+		http.Error(w, "status must be pending, approved, rejected or all", http.StatusBadRequest)
+		return
+	}
+
+	approvals, err := approvalRepo.List(status)
+	if err != nil {
+		http.Error(w, "could not fetch approvals", http.StatusInternalServerError)
+		return
+	}
+	if err := writeJSON(w, http.StatusOK, approvals); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// ApproveHandler godoc
+// @Summary Approve a pending request and carry it out
+// @Description Only an admin other than the requester can approve. Approving a product deletion deletes the product.
+// @Tags approvals
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Approval ID"
+// @Success 200 {object} models.Approval
+// @Failure 400 {string} string "Invalid ID"
+// @Failure 403 {string} string "Admins only, or the caller requested it"
+// @Failure 404 {string} string "Approval not found"
+// @Failure 409 {string} string "Already decided"
+// @Failure 500 {string} string "Internal error"
+// @Router /approvals/{id}/approve [post]
+func ApproveHandler(w http.ResponseWriter, r *http.Request) {
+	a, actor, ok := pendingApproval(w, r)
+	if !ok {
+		return
+	}
+	if a.RequestedBy == actor {
+		http.Error(w, "a request must be approved by another admin", http.StatusForbidden)
+		return
+	}
+
+	switch a.Action {
+	case models.ApprovalActionDeleteProduct:
+		// A product deleted meanwhile needs nothing more.
+		if err := productRepo.Delete(a.EntityID); err != nil && !errors.Is(err, repo.ErrProductNotFound) {
+			http.Error(w, "could not delete product", http.StatusInternalServerError)
+			return
+		}
+		recordAudit(r, "delete", "product", a.EntityID, map[string]any{"approval_id": a.ID, "requested_by": a.RequestedBy})
+	}
+	decideApproval(w, r, a.ID, models.ApprovalStatusApproved, actor)
+}
+
+// RejectHandler godoc
+// @Summary Reject a pending request
+// @Description The requester may reject their own request to withdraw it.
+// @Tags approvals
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Approval ID"
+// @Success 200 {object} models.Approval
+// @Failure 400 {string} string "Invalid ID"
+// @Failure 403 {string} string "Admins only"
+// @Failure 404 {string} string "Approval not found"
+// @Failure 409 {string} string "Already decided"
+// @Failure 500 {string} string "Internal error"
+// @Router /approvals/{id}/reject [post]
+func RejectHandler(w http.ResponseWriter, r *http.Request) {
+	a, actor, ok := pendingApproval(w, r)
+	if !ok {
+		return
+	}
+	decideApproval(w, r, a.ID, models.ApprovalStatusRejected, actor)
+}
+
+// pendingApproval loads the approval named in the URL and the caller,
+// writing the error response itself unless it is pending.
+func pendingApproval(w http.ResponseWriter, r *http.Request) (models.Approval, string, bool) {
+	id, err := parseID(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid approval ID", http.StatusBadRequest)
+		return models.Approval{}, "", false
+	}
+	actor, err := GetUsernameFromContext(r)
+	if err != nil {
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return models.Approval{}, "", false
+	}
+	a, err := approvalRepo.GetByID(id)
+	if err != nil {
+		writeApprovalError(w, err)
+		return models.Approval{}, "", false
+	}
+	if a.Status != models.ApprovalStatusPending {
+		writeApprovalError(w, repo.ErrApprovalDecided)
+		return models.Approval{}, "", false
+	}
+	return a, actor, true
+}
+
+func decideApproval(w http.ResponseWriter, r *http.Request, id int, status, actor string) {
+	decided, err := approvalRepo.Decide(id, status, actor)
+	if err != nil {
+		writeApprovalError(w, err)
+		return
+	}
+
+	recordAudit(r, status, "approval", id, decided)
+	go notifyApproval(decided)
+	if err := writeJSON(w, http.StatusOK, decided); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+func writeApprovalError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, repo.ErrApprovalNotFound):
+		http.Error(w, "approval not found", http.StatusNotFound)
+	case errors.Is(err, repo.ErrApprovalDecided):
+		http.Error(w, "approval already decided", http.StatusConflict)
+	default:
+		http.Error(w, "could not process approval", http.StatusInternalServerError)
+	}
+}
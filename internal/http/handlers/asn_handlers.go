@@ -0,0 +1,368 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rogerio-castellano/inventory-tracker/internal/mailer"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+)
+
+// CreateASNHandler godoc
+// @Summary Record an advance shipping notice
+// @Description Records the quantities a supplier announced for an inbound shipment. Stock is not touched until the shipment is received.
+// @Tags receiving
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param asn body ASNRequest true "Supplier, reference and expected quantities"
+// @Success 201 {object} models.ASN
+// @Failure 400 {object} ErrorResponse "Invalid input"
+// @Failure 404 {object} ErrorResponse "Product not found"
+// @Failure 500 {object} ErrorResponse "Internal error"
+// @Router /asns [post]
+func CreateASNHandler(w http.ResponseWriter, r *http.Request) {
+	var req ASNRequest
+	if err := readJSON(w, r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidInput, "invalid input")
+		return
+	}
+	a := models.ASN{
+		Reference:     strings.TrimSpace(req.Reference),
+		Supplier:      strings.TrimSpace(req.Supplier),
+		SupplierEmail: strings.TrimSpace(req.SupplierEmail),
+	}
+	switch {
+	case a.Reference == "" || a.Supplier == "":
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidInput, "reference and supplier are required")
+		return
+	case len(req.Lines) == 0:
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidInput, "at least one line is required")
+		return
+	}
+	if a.SupplierEmail != "" {
+		if _, err := mail.ParseAddress(a.SupplierEmail); err != nil {
+			writeError(w, http.StatusBadRequest, ErrCodeInvalidInput, "supplier_email is not a valid address")
+			return
+		}
+	}
+	expectedAt, err := parseTime(req.ExpectedAt)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidInput, "expected_at must be an RFC 3339 time")
+		return
+	}
+	if expectedAt != nil {
+		t := expectedAt.UTC()
+		a.ExpectedAt = &t
+	}
+
+	seen := map[int]bool{}
+	for _, l := range req.Lines {
+		switch {
+		case l.Quantity <= 0:
+			writeError(w, http.StatusBadRequest, ErrCodeInvalidInput, "line quantities must be positive")
+			return
+		case seen[l.ProductID]:
+			writeError(w, http.StatusBadRequest, ErrCodeInvalidInput, fmt.Sprintf("product %d is listed more than once", l.ProductID))
+			return
+		}
+		seen[l.ProductID] = true
+		a.Lines = append(a.Lines, models.ASNLine{ProductID: l.ProductID, Expected: l.Quantity})
+	}
+
+	if a.CreatedBy, err = GetUsernameFromContext(r); err != nil {
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "Internal error")
+		return
+	}
+	created, err := asnRepo.Create(a)
+	if err != nil {
+		if errors.Is(err, repo.ErrProductNotFound) {
+			writeError(w, http.StatusNotFound, ErrCodeNotFound, err.Error())
+			return
+		}
+		log.Printf("failed to create ASN: %v", err)
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "could not create ASN")
+		return
+	}
+
+	recordAudit(r, "create", "asn", created.ID, created)
+	if err := writeJSON(w, http.StatusCreated, created); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// ListASNsHandler godoc
+// @Summary List advance shipping notices
+// @Tags receiving
+// @Security BearerAuth
+// @Produce json
+// @Param status query string false "open or received"
+// @Success 200 {array} models.ASN
+// @Failure 400 {object} ErrorResponse "Invalid status"
+// @Failure 500 {object} ErrorResponse "Internal error"
+// @Router /asns [get]
+func ListASNsHandler(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	if status != "" && status != models.ASNStatusOpen && status != models.ASNStatusReceived {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidInput, "status must be open or received")
+		return
+	}
+
+	asns, err := asnRepo.List(status)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "could not fetch ASNs")
+		return
+	}
+	if err := writeJSON(w, http.StatusOK, asns); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// GetASNHandler godoc
+// @Summary Get an advance shipping notice
+// @Tags receiving
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ASN ID"
+// @Success 200 {object} models.ASN
+// @Failure 400 {object} ErrorResponse "Invalid ID"
+// @Failure 404 {object} ErrorResponse "ASN not found"
+// @Failure 500 {object} ErrorResponse "Internal error"
+// @Router /asns/{id} [get]
+func GetASNHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidInput, "invalid ASN ID")
+		return
+	}
+
+	a, err := asnRepo.GetByID(id)
+	if err != nil {
+		writeASNError(w, err)
+		return
+	}
+	if err := writeJSON(w, http.StatusOK, a); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// ReceiveASNHandler godoc
+// @Summary Receive the shipment of an advance shipping notice
+// @Description Matches the scanned or counted items against the ASN, adds them to stock in one transaction and closes the ASN. Items the ASN did not list are received too and flagged as unexpected. When anything arrived short or over, the discrepancy report is emailed to the supplier contact.
+// @Tags receiving
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ASN ID"
+// @Param items body ReceiveASNRequest true "Scanned or counted items"
+// @Success 200 {object} ASNReport
+// @Failure 400 {object} ErrorResponse "Invalid input"
+// @Failure 403 {object} ErrorResponse "Caller restricted to warehouses"
+// @Failure 404 {object} ErrorResponse "ASN or product not found"
+// @Failure 409 {object} ErrorResponse "Already received, ambiguous barcode or period closed"
+// @Failure 500 {object} ErrorResponse "Internal error"
+// @Router /asns/{id}/receive [post]
+func ReceiveASNHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidInput, "invalid ASN ID")
+		return
+	}
+	var req ReceiveASNRequest
+	if err := readJSON(w, r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidInput, "invalid input")
+		return
+	}
+	if len(req.Items) == 0 {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidInput, "at least one item is required")
+		return
+	}
+
+	scope, err := accessScope(r)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "could not load access grants")
+		return
+	}
+	if !warehouseInScope(scope, nil) {
+		writeError(w, http.StatusForbidden, ErrCodeOutOfScope, "warehouse outside your access grants: shipments are received into stock of every warehouse")
+		return
+	}
+
+	received := map[int]int{}
+	for _, item := range req.Items {
+		productID, err := receivedProductID(item)
+		if err == nil {
+			if err = productInScope(scope, productID); errors.Is(err, repo.ErrProductNotFound) {
+				err = fmt.Errorf("product %d: %w", productID, err)
+			}
+		}
+		if err != nil {
+			switch {
+			case errors.Is(err, errUnnamedItem):
+				writeError(w, http.StatusBadRequest, ErrCodeInvalidInput, err.Error())
+			case errors.Is(err, repo.ErrProductNotFound):
+				writeError(w, http.StatusNotFound, ErrCodeNotFound, err.Error())
+			case errors.Is(err, repo.ErrAmbiguousBarcode):
+				writeError(w, http.StatusConflict, ErrCodeConflict, err.Error())
+			default:
+				writeError(w, http.StatusInternalServerError, ErrCodeInternal, "could not look up barcode")
+			}
+			return
+		}
+		quantity := item.Quantity
+		if quantity == 0 {
+			quantity = 1
+		}
+		if quantity < 0 {
+			writeError(w, http.StatusBadRequest, ErrCodeInvalidInput, "quantities cannot be negative")
+			return
+		}
+		received[productID] += quantity
+	}
+
+	if err := ensurePeriodOpen(time.Now().UTC()); err != nil {
+		if errors.Is(err, errPeriodClosed) {
+			writeError(w, http.StatusConflict, ErrCodeConflict, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "could not verify accounting period")
+		return
+	}
+
+	username, err := GetUsernameFromContext(r)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "Internal error")
+		return
+	}
+	a, err := asnRepo.Receive(id, received, username)
+	if err != nil {
+		writeASNError(w, err)
+		return
+	}
+
+	report := ASNReport{ASN: a, Discrepancies: asnDiscrepancies(a)}
+	if len(report.Discrepancies) > 0 && a.SupplierEmail != "" {
+		if err := mailer.SendHTMLTo(a.SupplierEmail, fmt.Sprintf("Receiving discrepancies for ASN %s", a.Reference), discrepancyEmail(a, report.Discrepancies)); err != nil {
+			log.Printf("discrepancy report for ASN %d not sent: %v", a.ID, err)
+			report.ReportError = err.Error()
+		} else {
+			report.ReportSent = true
+		}
+	}
+
+	recordAudit(r, "receive", "asn", a.ID, map[string]any{"received": received, "discrepancies": len(report.Discrepancies)})
+	if err := writeJSON(w, http.StatusOK, report); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// ASNDiscrepanciesHandler godoc
+// @Summary Discrepancy report of a received shipment
+// @Tags receiving
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ASN ID"
+// @Success 200 {array} ASNDiscrepancy
+// @Failure 400 {object} ErrorResponse "Invalid ID"
+// @Failure 404 {object} ErrorResponse "ASN not found"
+// @Failure 409 {object} ErrorResponse "ASN not received yet"
+// @Failure 500 {object} ErrorResponse "Internal error"
+// @Router /asns/{id}/discrepancies [get]
+func ASNDiscrepanciesHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidInput, "invalid ASN ID")
+		return
+	}
+
+	a, err := asnRepo.GetByID(id)
+	if err != nil {
+		writeASNError(w, err)
+		return
+	}
+	if a.Status != models.ASNStatusReceived {
+		writeError(w, http.StatusConflict, ErrCodeConflict, "ASN has not been received yet")
+		return
+	}
+	if err := writeJSON(w, http.StatusOK, asnDiscrepancies(a)); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+var errUnnamedItem = errors.New("each item needs exactly one of barcode and product_id")
+
+// receivedProductID resolves a received item to a product ID.
+func receivedProductID(item ReceivedItem) (int, error) {
+	barcode := strings.TrimSpace(item.Barcode)
+	switch {
+	case (barcode == "") == (item.ProductID == 0):
+		return 0, errUnnamedItem
+	case barcode == "":
+		return item.ProductID, nil
+	}
+
+	p, err := productRepo.GetByBarcode(barcode)
+	if err != nil {
+		return 0, fmt.Errorf("barcode %s: %w", barcode, err)
+	}
+	return p.ID, nil
+}
+
+// asnDiscrepancies lists the lines of a that did not arrive as announced.
+func asnDiscrepancies(a models.ASN) []ASNDiscrepancy {
+	discrepancies := []ASNDiscrepancy{}
+	for _, l := range a.Lines {
+		if l.Received == l.Expected {
+			continue
+		}
+		d := ASNDiscrepancy{ProductID: l.ProductID, Expected: l.Expected, Received: l.Received, Difference: l.Received - l.Expected}
+		switch {
+		case l.Expected == 0:
+			d.Kind = "unexpected"
+		case l.Received < l.Expected:
+			d.Kind = "short"
+		default:
+			d.Kind = "over"
+		}
+		if p, err := productRepo.GetByID(l.ProductID); err == nil {
+			d.Name = p.Name
+		}
+		discrepancies = append(discrepancies, d)
+	}
+	return discrepancies
+}
+
+func discrepancyEmail(a models.ASN, discrepancies []ASNDiscrepancy) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("<h2>Receiving discrepancies</h2><p>ASN <strong>%s</strong> from %s was received on %s with the differences below.</p>",
+		html.EscapeString(a.Reference), html.EscapeString(a.Supplier), a.ReceivedAt.Format(time.DateOnly)))
+	sb.WriteString("<table><tr><th>Product</th><th>Expected</th><th>Received</th><th>Difference</th></tr>")
+	for _, d := range discrepancies {
+		sb.WriteString(fmt.Sprintf("<tr><td>%s</td><td>%d</td><td>%d</td><td>%+d (%s)</td></tr>",
+			html.EscapeString(d.Name), d.Expected, d.Received, d.Difference, d.Kind))
+	}
+	sb.WriteString("</table>")
+	return sb.String()
+}
+
+func writeASNError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, repo.ErrASNNotFound):
+		writeError(w, http.StatusNotFound, ErrCodeNotFound, "ASN not found")
+	case errors.Is(err, repo.ErrProductNotFound):
+		writeError(w, http.StatusNotFound, ErrCodeNotFound, err.Error())
+	case errors.Is(err, repo.ErrASNNotOpen):
+		writeError(w, http.StatusConflict, ErrCodeConflict, "ASN has already been received")
+	default:
+		log.Printf("ASN: %v", err)
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "could not process ASN")
+	}
+}
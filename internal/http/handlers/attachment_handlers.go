@@ -0,0 +1,270 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+	"github.com/rogerio-castellano/inventory-tracker/internal/storage"
+)
+
+const maxAttachmentDescriptionLength = 200
+
+// attachableType reports whether files of contentType can be attached to a
+// movement: images and PDFs.
+func attachableType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && (strings.HasPrefix(mediaType, "image/") || mediaType == "application/pdf")
+}
+
+// UploadAttachmentHandler godoc
+// @Summary Attach an image or PDF to a movement
+// @Description Keeps evidence with the stock change it explains, such as photos of damaged stock for an insurance claim or a signed delivery note.
+// @Tags movements
+// @Security BearerAuth
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path int true "Movement ID"
+// @Param file formData file true "Image or PDF (max 20 MB)"
+// @Param description formData string false "What the file shows"
+// @Success 201 {object} models.MovementAttachment
+// @Failure 400 {string} string "Invalid input"
+// @Failure 404 {string} string "Movement not found"
+// @Failure 415 {string} string "Not an image or PDF"
+// @Failure 422 {object} ErrorResponse "Infected file"
+// @Failure 500 {string} string "Internal error"
+// @Failure 503 {object} ErrorResponse "File could not be scanned"
+// @Router /movements/{id}/attachments [post]
+func UploadAttachmentHandler(w http.ResponseWriter, r *http.Request) {
+	movementID, err := parseID(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid movement ID", http.StatusBadRequest)
+		return
+	}
+	if _, err := movementRepo.GetByID(movementID); err != nil {
+		if errors.Is(err, repo.ErrMovementNotFound) {
+			http.Error(w, "movement not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "could not fetch movement", http.StatusInternalServerError)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxDocumentBytes+1<<20)
+	if err := r.ParseMultipartForm(maxDocumentBytes); err != nil {
+		http.Error(w, "file too large or invalid form", http.StatusBadRequest)
+		return
+	}
+	description := strings.TrimSpace(r.FormValue("description"))
+	if len(description) > maxAttachmentDescriptionLength {
+		http.Error(w, fmt.Sprintf("description must be at most %d characters", maxAttachmentDescriptionLength), http.StatusBadRequest)
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "missing file", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+	if !scanUpload(w, r, file, header.Filename) {
+		return
+	}
+
+	contentType, err := uploadContentType(file, header)
+	if err != nil {
+		http.Error(w, "could not read file", http.StatusInternalServerError)
+		return
+	}
+	if !attachableType(contentType) {
+		http.Error(w, "only images and PDFs can be attached", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	key, err := storageKey("movements", movementID, header.Filename)
+	if err != nil {
+		http.Error(w, "could not store attachment", http.StatusInternalServerError)
+		return
+	}
+	size, err := documentStore.Put(key, file)
+	if err != nil {
+		log.Printf("failed to store attachment for movement %d: %v", movementID, err)
+		http.Error(w, "could not store attachment", http.StatusInternalServerError)
+		return
+	}
+
+	username, _ := GetUsernameFromContext(r)
+	attachment, err := attachmentRepo.Create(models.MovementAttachment{
+		MovementID:  movementID,
+		Filename:    filepath.Base(header.Filename),
+		ContentType: contentType,
+		Size:        size,
+		StorageKey:  key,
+		Description: description,
+		UploadedBy:  username,
+	})
+	if err != nil {
+		_ = documentStore.Delete(key)
+		if errors.Is(err, repo.ErrMovementNotFound) {
+			http.Error(w, "movement not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "could not save attachment", http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(r, "attach", "movement", movementID, attachment)
+	if err := writeJSON(w, http.StatusCreated, attachment); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// ListAttachmentsHandler godoc
+// @Summary List a movement's attachments
+// @Tags movements
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Movement ID"
+// @Success 200 {array} models.MovementAttachment
+// @Failure 400 {string} string "Invalid input"
+// @Failure 500 {string} string "Internal error"
+// @Router /movements/{id}/attachments [get]
+func ListAttachmentsHandler(w http.ResponseWriter, r *http.Request) {
+	movementID, err := parseID(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid movement ID", http.StatusBadRequest)
+		return
+	}
+
+	attachments, err := attachmentRepo.GetByMovementID(movementID)
+	if err != nil {
+		http.Error(w, "could not fetch attachments", http.StatusInternalServerError)
+		return
+	}
+
+	if err := writeJSON(w, http.StatusOK, attachments); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// DownloadAttachmentHandler godoc
+// @Summary Download a movement attachment
+// @Tags movements
+// @Security BearerAuth
+// @Produce octet-stream
+// @Param id path int true "Movement ID"
+// @Param attachmentId path int true "Attachment ID"
+// @Success 200 {file} file "Attachment content"
+// @Failure 400 {string} string "Invalid input"
+// @Failure 404 {string} string "Not found"
+// @Failure 500 {string} string "Internal error"
+// @Router /movements/{id}/attachments/{attachmentId} [get]
+func DownloadAttachmentHandler(w http.ResponseWriter, r *http.Request) {
+	attachment, ok := movementAttachment(w, r)
+	if !ok {
+		return
+	}
+
+	content, err := documentStore.Get(attachment.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			http.Error(w, "attachment content missing", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "could not read attachment", http.StatusInternalServerError)
+		return
+	}
+	defer content.Close()
+
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename}))
+	w.Header().Set("Content-Length", fmt.Sprint(attachment.Size))
+	if _, err := io.Copy(w, content); err != nil {
+		log.Printf("failed to send attachment %d: %v", attachment.ID, err)
+	}
+}
+
+// AttachmentDownloadURLHandler godoc
+// @Summary Get a temporary download URL for a movement attachment
+// @Description With S3 or GCS storage, returns a presigned URL that downloads the attachment straight from the bucket, without a token, for 15 minutes.
+// @Tags movements
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Movement ID"
+// @Param attachmentId path int true "Attachment ID"
+// @Success 200 {object} DownloadURL
+// @Failure 400 {string} string "Invalid input"
+// @Failure 404 {string} string "Not found"
+// @Failure 500 {string} string "Internal error"
+// @Failure 501 {string} string "Storage cannot presign URLs"
+// @Router /movements/{id}/attachments/{attachmentId}/url [get]
+func AttachmentDownloadURLHandler(w http.ResponseWriter, r *http.Request) {
+	attachment, ok := movementAttachment(w, r)
+	if !ok {
+		return
+	}
+	writeDownloadURL(w, attachment.StorageKey, attachment.Filename)
+}
+
+// DeleteAttachmentHandler godoc
+// @Summary Delete a movement attachment
+// @Tags movements
+// @Security BearerAuth
+// @Param id path int true "Movement ID"
+// @Param attachmentId path int true "Attachment ID"
+// @Success 204 "Deleted successfully"
+// @Failure 400 {string} string "Invalid input"
+// @Failure 404 {string} string "Not found"
+// @Failure 500 {string} string "Internal error"
+// @Router /movements/{id}/attachments/{attachmentId} [delete]
+func DeleteAttachmentHandler(w http.ResponseWriter, r *http.Request) {
+	attachment, ok := movementAttachment(w, r)
+	if !ok {
+		return
+	}
+
+	if err := attachmentRepo.Delete(attachment.ID); err != nil {
+		http.Error(w, "could not delete attachment", http.StatusInternalServerError)
+		return
+	}
+	if err := documentStore.Delete(attachment.StorageKey); err != nil && !errors.Is(err, storage.ErrNotFound) {
+		log.Printf("failed to delete stored file of attachment %d: %v", attachment.ID, err)
+	}
+
+	recordAudit(r, "delete", "attachment", attachment.ID, attachment)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// movementAttachment loads the attachment named in the URL, making sure it
+// belongs to the movement in the URL. It writes the error response itself.
+func movementAttachment(w http.ResponseWriter, r *http.Request) (models.MovementAttachment, bool) {
+	movementID, err := parseID(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid movement ID", http.StatusBadRequest)
+		return models.MovementAttachment{}, false
+	}
+	attachmentID, err := parseID(chi.URLParam(r, "attachmentId"))
+	if err != nil {
+		http.Error(w, "invalid attachment ID", http.StatusBadRequest)
+		return models.MovementAttachment{}, false
+	}
+
+	attachment, err := attachmentRepo.GetByID(attachmentID)
+	if err != nil || attachment.MovementID != movementID {
+		if err != nil && !errors.Is(err, repo.ErrAttachmentNotFound) {
+			http.Error(w, "could not fetch attachment", http.StatusInternalServerError)
+			return models.MovementAttachment{}, false
+		}
+		http.Error(w, "attachment not found", http.StatusNotFound)
+		return models.MovementAttachment{}, false
+	}
+	return attachment, true
+}
@@ -0,0 +1,286 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+	"github.com/rogerio-castellano/inventory-tracker/internal/security"
+)
+
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+// recordAudit appends an entry to the audit log on behalf of the caller.
+// Failures are logged rather than returned: the audited action has already happened.
+func recordAudit(r *http.Request, action, entity string, entityID any, details any) {
+	actor, err := GetUsernameFromContext(r)
+	if err != nil || actor == "" {
+		actor = "anonymous"
+	}
+
+	entry := models.AuditEntry{
+		Actor:     actor,
+		Action:    action,
+		Entity:    entity,
+		EntityID:  toEntityID(entityID),
+		RequestID: RequestID(r),
+	}
+	if details != nil {
+		raw, err := json.Marshal(details)
+		if err != nil {
+			log.Printf("failed to encode audit details for %s %s: %v", action, entity, err)
+		}
+		entry.Details = raw
+	}
+
+	if _, err := auditRepo.Append(entry); err != nil {
+		log.Printf("failed to record audit entry %s %s %s: %v", action, entity, entry.EntityID, err)
+	}
+
+	security.Publish(security.Event{
+		Type:     security.EventAudit,
+		Severity: security.SeverityInfo,
+		Actor:    actor,
+		Target:   entity + ":" + entry.EntityID,
+		SourceIP: clientIP(r),
+		Details:  map[string]any{"action": action, "entity": entity, "entity_id": entry.EntityID, "request_id": entry.RequestID, "details": entry.Details},
+	})
+}
+
+func toEntityID(id any) string {
+	switch v := id.(type) {
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	default:
+		return ""
+	}
+}
+
+// ListAuditLogHandler godoc
+// @Summary Search the audit log
+// @Description Lists audit entries newest first. Every create, update, delete and stock adjustment is recorded with the acting user, the time, the values involved and the ID of the request that made it (also returned in the X-Request-ID response header).
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param user query string false "Acting user"
+// @Param action query string false "Action, e.g. create, update, delete or adjust"
+// @Param entity query string false "Entity type, e.g. product or movement"
+// @Param entity_id query string false "Entity ID (requires entity)"
+// @Param since query string false "Filter from timestamp (RFC3339)"
+// @Param until query string false "Filter until timestamp (RFC3339)"
+// @Param limit query int false "Maximum entries (default 100, max 1000)"
+// @Param offset query int false "Entries to skip"
+// @Success 200 {object} AuditLogPage
+// @Failure 400 {string} string "Invalid input"
+// @Failure 500 {string} string "Internal error"
+// @Router /admin/audit [get]
+func ListAuditLogHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter, err := parseAuditRange(q.Get("since"), q.Get("until"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	filter.Actor = strings.TrimSpace(q.Get("user"))
+	filter.Action = strings.TrimSpace(q.Get("action"))
+	filter.Entity = strings.TrimSpace(q.Get("entity"))
+	filter.EntityID = strings.TrimSpace(q.Get("entity_id"))
+	if filter.EntityID != "" && filter.Entity == "" {
+		http.Error(w, "entity_id requires entity", http.StatusBadRequest)
+		return
+	}
+	if filter.Since != nil && filter.Until != nil && filter.Until.Before(*filter.Since) {
+		http.Error(w, "until must not be before since", http.StatusBadRequest)
+		return
+	}
+
+	limit := defaultAuditLimit
+	if raw := q.Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		limit = min(n, maxAuditLimit)
+	}
+	offset := 0
+	if raw := q.Get("offset"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			http.Error(w, "offset must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+		offset = n
+	}
+	filter.Newest = true
+	// One extra entry tells whether another page follows.
+	filter.Limit, filter.Offset = limit+1, offset
+
+	entries, err := auditRepo.Find(filter)
+	if err != nil {
+		http.Error(w, "could not retrieve audit log", http.StatusInternalServerError)
+		return
+	}
+	page := AuditLogPage{Entries: entries, Limit: limit, Offset: offset}
+	if len(entries) > limit {
+		page.Entries, page.HasMore = entries[:limit], true
+	}
+	if err := writeJSON(w, http.StatusOK, page); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// ExportAuditLogHandler godoc
+// @Summary Export the audit log with its hash chain
+// @Description Each entry carries the hash of the previous entry, so any edit or deletion breaks the chain. With encryption=zip the file comes in a ZIP archive protected with the password in the X-Export-Password header; with encryption=pgp it is encrypted to the PGP key of the export recipient recipient_id and emailed to them.
+// @Tags admin
+// @Security BearerAuth
+// @Produce text/csv, application/json, application/zip
+// @Param format query string true "Export format (csv or json)"
+// @Param since query string false "Filter from timestamp (RFC3339)"
+// @Param until query string false "Filter until timestamp (RFC3339)"
+// @Param encryption query string false "zip or pgp"
+// @Param recipient_id query int false "Export recipient, for pgp"
+// @Param X-Export-Password header string false "Archive password, for zip (at least 12 characters; the archive is only as strong as the password, so use a long random one)"
+// @Success 200 {file} file
+// @Success 202 {object} ExportDelivery "Encrypted and being emailed"
+// @Failure 400 {string} string "Invalid input"
+// @Failure 404 {string} string "Export recipient not found"
+// @Failure 500 {string} string "Internal error"
+// @Router /admin/audit/export [get]
+func ExportAuditLogHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	format := q.Get("format")
+	if format != "csv" && format != "json" {
+		http.Error(w, "format must be 'csv' or 'json'", http.StatusBadRequest)
+		return
+	}
+
+	filter, err := parseAuditRange(q.Get("since"), q.Get("until"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	entries, err := auditRepo.Find(filter)
+	if err != nil {
+		http.Error(w, "could not retrieve audit log", http.StatusInternalServerError)
+		return
+	}
+
+	var buf bytes.Buffer
+	switch format {
+	case "json":
+		if err := json.NewEncoder(&buf).Encode(entries); err != nil {
+			http.Error(w, "could not encode audit log", http.StatusInternalServerError)
+			return
+		}
+		deliverExport(w, r, "audit_log.json", "application/json", buf.Bytes())
+	case "csv":
+		csvWriter := newSafeCSVWriter(&buf)
+		_ = csvWriter.Write([]string{"id", "created_at", "actor", "action", "entity", "entity_id", "details", "request_id", "prev_hash", "hash"})
+		for _, e := range entries {
+			_ = csvWriter.Write([]string{
+				strconv.Itoa(e.ID),
+				e.CreatedAt.UTC().Format(time.RFC3339Nano),
+				e.Actor,
+				e.Action,
+				e.Entity,
+				e.EntityID,
+				string(e.Details),
+				e.RequestID,
+				e.PrevHash,
+				e.Hash,
+			})
+		}
+		csvWriter.Flush()
+		deliverExport(w, r, "audit_log.csv", "text/csv", buf.Bytes())
+	}
+}
+
+// VerifyAuditLogHandler godoc
+// @Summary Verify the integrity of the audit log hash chain
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param since query string false "Verify from timestamp (RFC3339)"
+// @Param until query string false "Verify until timestamp (RFC3339)"
+// @Success 200 {object} AuditVerificationResult
+// @Failure 400 {string} string "Invalid input"
+// @Failure 500 {string} string "Internal error"
+// @Router /admin/audit/verify [get]
+func VerifyAuditLogHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter, err := parseAuditRange(q.Get("since"), q.Get("until"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	entries, err := auditRepo.Find(filter)
+	if err != nil {
+		http.Error(w, "could not retrieve audit log", http.StatusInternalServerError)
+		return
+	}
+
+	// The first entry in range must link to the entry right before the range.
+	prevHash := ""
+	if filter.Since != nil {
+		prev, err := auditRepo.GetLastBefore(*filter.Since)
+		if err != nil && !errors.Is(err, repo.ErrAuditEntryNotFound) {
+			http.Error(w, "could not retrieve audit log", http.StatusInternalServerError)
+			return
+		}
+		prevHash = prev.Hash
+	}
+
+	result := verifyAuditChain(entries, prevHash)
+	if err := writeJSON(w, http.StatusOK, result); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+func verifyAuditChain(entries []models.AuditEntry, prevHash string) AuditVerificationResult {
+	result := AuditVerificationResult{Valid: true}
+	for _, e := range entries {
+		result.Checked++
+		switch {
+		case e.PrevHash != prevHash:
+			result.Valid = false
+			result.Reason = "entry does not link to the previous entry"
+		case repo.AuditHash(e) != e.Hash:
+			result.Valid = false
+			result.Reason = "entry content does not match its hash"
+		}
+		if !result.Valid {
+			result.FirstInvalidID = e.ID
+			return result
+		}
+		prevHash = e.Hash
+	}
+	return result
+}
+
+func parseAuditRange(rawSince, rawUntil string) (repo.AuditFilter, error) {
+	since, err := parseTime(rawSince)
+	if err != nil {
+		return repo.AuditFilter{}, errors.New("invalid since date format")
+	}
+	until, err := parseTime(rawUntil)
+	if err != nil {
+		return repo.AuditFilter{}, errors.New("invalid until date format")
+	}
+	return repo.AuditFilter{Since: since, Until: until}, nil
+}
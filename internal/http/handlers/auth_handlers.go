@@ -2,7 +2,6 @@ package handlers
 
 import (
 	"crypto/rand"
-	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
@@ -12,12 +11,18 @@ import (
 	"net/http"
 	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/rogerio-castellano/inventory-tracker/internal/auth"
 	"github.com/rogerio-castellano/inventory-tracker/internal/http/ban"
+	rl "github.com/rogerio-castellano/inventory-tracker/internal/http/rate_limiter"
 	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+	"github.com/rogerio-castellano/inventory-tracker/internal/redissvc"
 	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+	"github.com/rogerio-castellano/inventory-tracker/internal/security"
+	"github.com/rogerio-castellano/inventory-tracker/internal/usage"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -30,6 +35,11 @@ import (
 // @Success 201 {object} map[string]string
 // @Failure 400 {string} string "Invalid input"
 // @Failure 409 {string} string "User exists"
+// @Failure 429 {string} string "Too many requests"
+// @Header all {integer} X-RateLimit-Limit "Requests allowed per window"
+// @Header all {integer} X-RateLimit-Remaining "Requests left in the current window"
+// @Header all {integer} X-RateLimit-Reset "Seconds until the window resets"
+// @Header 429 {integer} Retry-After "Seconds to wait before retrying"
 // @Router /register [post]
 func RegisterHandler(w http.ResponseWriter, r *http.Request) {
 	var creds CredentialsRequest
@@ -70,6 +80,8 @@ func RegisterHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordAudit(r, "register", "user", user.Username, map[string]string{"role": user.Role})
+
 	token, err := auth.GenerateToken(user)
 	if err != nil {
 		http.Error(w, "failed to generate token", http.StatusInternalServerError)
@@ -141,6 +153,17 @@ func RegisterAsAdminHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	recordAudit(r, "create", "user", req.Username, map[string]string{"role": req.Role})
+	actor, _ := GetUsernameFromContext(r)
+	security.Publish(security.Event{
+		Type:     security.EventRoleChange,
+		Severity: roleChangeSeverity(req.Role),
+		Actor:    actor,
+		Target:   req.Username,
+		SourceIP: clientIP(r),
+		Details:  map[string]any{"role": req.Role, "previous_role": nil},
+	})
+
 	err = writeJSON(w, http.StatusCreated, map[string]string{
 		"message": "User created",
 	})
@@ -159,6 +182,12 @@ func RegisterAsAdminHandler(w http.ResponseWriter, r *http.Request) {
 // @Success 200 {object} map[string]string
 // @Failure 400 {string} string "Invalid input"
 // @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {object} ErrorResponse "Password expired; change it with /password/change"
+// @Failure 429 {string} string "Too many requests"
+// @Header all {integer} X-RateLimit-Limit "Requests allowed per window"
+// @Header all {integer} X-RateLimit-Remaining "Requests left in the current window"
+// @Header all {integer} X-RateLimit-Reset "Seconds until the window resets"
+// @Header 429 {integer} Retry-After "Seconds to wait before retrying"
 // @Router /login [post]
 func LoginHandler(w http.ResponseWriter, r *http.Request) {
 	var credentials CredentialsRequest
@@ -166,18 +195,55 @@ func LoginHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "invalid input", http.StatusBadRequest)
 		return
 	}
+	deviceName, err := parseDeviceName(credentials.DeviceName)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	user, err := userRepo.GetByUsername(credentials.Username)
 	if err != nil {
+		recordLoginFailure(r, credentials.Username, models.LoginReasonUnknownUser)
 		http.Error(w, "invalid credentials", http.StatusUnauthorized)
 		return
 	}
 
 	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(credentials.Password)) != nil {
+		recordLoginFailure(r, credentials.Username, models.LoginReasonInvalidPassword)
 		http.Error(w, "invalid credentials", http.StatusUnauthorized)
 		return
 	}
+	if user.Disabled {
+		recordLogin(r, user.Username, models.LoginOutcomeFailure, models.LoginReasonAccountDisabled)
+		http.Error(w, "account disabled", http.StatusForbidden)
+		return
+	}
+	if passwordExpired(user) {
+		recordLogin(r, user.Username, models.LoginOutcomeFailure, models.LoginReasonPasswordExpired)
+		writeError(w, http.StatusForbidden, ErrCodePasswordExpired, "password change required: set a new password with POST /password/change")
+		return
+	}
+
+	startSession(w, r, user, deviceName)
+}
 
+const maxDeviceNameLength = 64
+
+// parseDeviceName trims the device name a client gave its session.
+func parseDeviceName(raw string) (string, error) {
+	name := strings.TrimSpace(raw)
+	if utf8.RuneCountInString(name) > maxDeviceNameLength {
+		return "", fmt.Errorf("device_name must be at most %d characters", maxDeviceNameLength)
+	}
+	if strings.ContainsFunc(name, unicode.IsControl) {
+		return "", errors.New("device_name must not contain control characters")
+	}
+	return name, nil
+}
+
+// startSession issues an access and a refresh token for user and writes
+// them. deviceName labels the session in session listings.
+func startSession(w http.ResponseWriter, r *http.Request, user models.User, deviceName string) {
 	accessToken, err := auth.GenerateToken(user)
 	if err != nil {
 		http.Error(w, "could not generate token", http.StatusInternalServerError)
@@ -192,15 +258,18 @@ func LoginHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ua := r.UserAgent()
-	key := sessionKey(host, ua)
+	key := auth.SessionKey(host, ua)
 	err = auth.SetRefreshToken(user.Username, key, auth.RefreshTokenEntry{
-		Token:     refreshToken,
-		IPAddress: host,
-		UserAgent: ua,
+		Token:      refreshToken,
+		IPAddress:  host,
+		UserAgent:  ua,
+		DeviceName: deviceName,
 	})
 	if err != nil {
 		log.Printf("Failed to set refresh token: %v", err)
 	}
+	touchSession(user.Username, key)
+	recordLogin(r, user.Username, models.LoginOutcomeSuccess, "")
 
 	err = writeJSON(w, http.StatusOK, LoginResult{AccessToken: accessToken, RefreshToken: refreshToken})
 	if err != nil {
@@ -208,12 +277,115 @@ func LoginHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// ChangePasswordHandler godoc
+// @Summary Change a user's password and log in
+// @Description Takes the current password rather than a token, so accounts whose password expired or is temporary, which cannot log in until it is changed, can use it too.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body ChangePasswordRequest true "Username, current and new password"
+// @Success 200 {object} LoginResult
+// @Failure 400 {string} string "Invalid input"
+// @Failure 401 {string} string "Invalid credentials"
+// @Failure 429 {string} string "Too many requests"
+// @Router /password/change [post]
+func ChangePasswordHandler(w http.ResponseWriter, r *http.Request) {
+	var req ChangePasswordRequest
+	if err := readJSON(w, r, &req); err != nil {
+		http.Error(w, "invalid input", http.StatusBadRequest)
+		return
+	}
+	deviceName, err := parseDeviceName(req.DeviceName)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user, err := userRepo.GetByUsername(req.Username)
+	if err != nil {
+		recordLoginFailure(r, req.Username, models.LoginReasonUnknownUser)
+		http.Error(w, "invalid credentials", http.StatusUnauthorized)
+		return
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.CurrentPassword)) != nil {
+		recordLoginFailure(r, req.Username, models.LoginReasonInvalidPassword)
+		http.Error(w, "invalid credentials", http.StatusUnauthorized)
+		return
+	}
+	if user.Disabled {
+		recordLogin(r, user.Username, models.LoginOutcomeFailure, models.LoginReasonAccountDisabled)
+		http.Error(w, "account disabled", http.StatusForbidden)
+		return
+	}
+	switch {
+	case len(req.NewPassword) < 6:
+		http.Error(w, "password too short", http.StatusBadRequest)
+		return
+	case req.NewPassword == req.CurrentPassword:
+		http.Error(w, "the new password must differ from the current one", http.StatusBadRequest)
+		return
+	}
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		http.Error(w, "failed to hash password", http.StatusInternalServerError)
+		return
+	}
+	if err := userRepo.SetPassword(user.Username, string(hashed)); err != nil {
+		http.Error(w, "could not change password", http.StatusInternalServerError)
+		return
+	}
+	user.MustChangePassword = false
+	user.PasswordChangedAt = time.Now().UTC()
+	recordAudit(r, "change_password", "user", user.Username, nil)
+
+	startSession(w, r, user, deviceName)
+}
+
+const (
+	loginFailureWindow = 10 * time.Minute
+	// loginFailureBurst failed logins for one username within the window
+	// raise a security event; further failures in the same window do not.
+	loginFailureBurst = 5
+)
+
+func recordLoginFailure(r *http.Request, username, reason string) {
+	recordLogin(r, username, models.LoginOutcomeFailure, reason)
+
+	key := "security:login_failures:" + strings.ToLower(username)
+	failures, err := Rdb.Incr(Ctx, key).Result()
+	if err != nil {
+		log.Printf("Failed to count login failure: %v", err)
+		return
+	}
+	if failures == 1 {
+		Rdb.Expire(Ctx, key, loginFailureWindow)
+	}
+	if failures == loginFailureBurst {
+		security.Publish(security.Event{
+			Type:     security.EventLoginFailureBurst,
+			Severity: security.SeverityWarning,
+			Target:   username,
+			SourceIP: clientIP(r),
+			Details:  map[string]any{"failures": failures, "window_seconds": int(loginFailureWindow.Seconds())},
+		})
+	}
+}
+
+func roleChangeSeverity(role string) string {
+	if role == "admin" {
+		return security.SeverityCritical
+	}
+	return security.SeverityInfo
+}
+
 // @Summary Get current user info
 // @Tags auth
 // @Security BearerAuth
 // @Produce json
 // @Success 200 {object} MeResponse
 // @Failure 401 {string} string "Unauthorized"
+// @Failure 500 {string} string "Internal error"
 // @Router /me [get]
 func MeHandler(w http.ResponseWriter, r *http.Request) {
 	authorization := r.Header.Get("Authorization")
@@ -222,9 +394,24 @@ func MeHandler(w http.ResponseWriter, r *http.Request) {
 		log.Printf("Error getting claims: %v", err)
 	}
 
+	role := claims["role"].(string)
+	limit := rl.LimitForRole(role)
 	resp := MeResponse{
-		Username: claims["username"].(string),
-		Role:     claims["role"].(string),
+		Username:    claims["username"].(string),
+		Role:        role,
+		Tenant:      tenant,
+		Permissions: auth.Permissions(role),
+		RateLimit:   RateLimitTier{Tier: limit.Tier, MaxRequests: limit.MaxRequests, WindowSeconds: int(limit.Window.Seconds())},
+	}
+	if u, err := userRepo.GetByUsername(resp.Username); err == nil {
+		resp.Timezone = u.Timezone
+	}
+	if resp.Quota.ProductsUsed, err = countProducts(); err != nil {
+		http.Error(w, "could not count products", http.StatusInternalServerError)
+		return
+	}
+	if productQuota > 0 {
+		resp.Quota.ProductsAllowed = &productQuota
 	}
 
 	if err := writeJSON(w, http.StatusOK, resp); err != nil {
@@ -232,6 +419,46 @@ func MeHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// SetTimezoneHandler godoc
+// @Summary Set the timezone of the current user
+// @Description Exports show timestamps in this timezone unless the request names one with tz.
+// @Tags auth
+// @Security BearerAuth
+// @Accept json
+// @Param timezone body TimezoneRequest true "IANA timezone"
+// @Success 204 "No Content"
+// @Failure 400 {string} string "Unknown timezone"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 500 {string} string "Internal error"
+// @Router /me/timezone [put]
+func SetTimezoneHandler(w http.ResponseWriter, r *http.Request) {
+	username, err := GetUsernameFromContext(r)
+	if err != nil || username == "" {
+		http.Error(w, "invalid token", http.StatusUnauthorized)
+		return
+	}
+
+	var req TimezoneRequest
+	if err := readJSON(w, r, &req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	req.Timezone = strings.TrimSpace(req.Timezone)
+	if req.Timezone != "" {
+		if _, err := loadTimezone(req.Timezone); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if err := userRepo.SetTimezone(username, req.Timezone); err != nil {
+		http.Error(w, "could not update timezone", http.StatusInternalServerError)
+		return
+	}
+	recordAudit(r, "set_timezone", "user", username, map[string]string{"timezone": req.Timezone})
+	w.WriteHeader(http.StatusNoContent)
+}
+
 // @Summary Refresh access token
 // @Tags auth
 // @Accept json
@@ -240,6 +467,12 @@ func MeHandler(w http.ResponseWriter, r *http.Request) {
 // @Success 200 {object} map[string]string
 // @Failure 400 {string} string "Bad request"
 // @Failure 401 {string} string "Invalid token"
+// @Failure 403 {object} ErrorResponse "Password expired"
+// @Failure 429 {string} string "Too many requests"
+// @Header all {integer} X-RateLimit-Limit "Requests allowed per window"
+// @Header all {integer} X-RateLimit-Remaining "Requests left in the current window"
+// @Header all {integer} X-RateLimit-Reset "Seconds until the window resets"
+// @Header 429 {integer} Retry-After "Seconds to wait before retrying"
 // @Router /refresh [post]
 func RefreshHandler(w http.ResponseWriter, r *http.Request) {
 	var req RefreshRequest
@@ -255,7 +488,7 @@ func RefreshHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ua := r.UserAgent()
-	key := sessionKey(host, ua)
+	key := auth.SessionKey(host, ua)
 	userSessions, ok, err := auth.GetRefreshToken(req.Username)
 	if err != nil {
 		http.Error(w, "Internal error", http.StatusInternalServerError)
@@ -279,12 +512,35 @@ func RefreshHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Refresh token expired", http.StatusUnauthorized)
 		return
 	}
+	if auth.SessionIdleTimeout() > 0 {
+		active, err := usage.SessionActive(req.Username, key)
+		if err != nil {
+			http.Error(w, "Internal error", http.StatusInternalServerError)
+			return
+		}
+		if !active {
+			if err := auth.RemoveRefreshToken(req.Username, key); err != nil {
+				http.Error(w, "Failed to handle refresh token", http.StatusInternalServerError)
+				return
+			}
+			http.Error(w, "Session expired after inactivity", http.StatusUnauthorized)
+			return
+		}
+	}
 
 	user, err := userRepo.GetByUsername(req.Username)
 	if err != nil {
 		http.Error(w, "User not found", http.StatusUnauthorized)
 		return
 	}
+	if user.Disabled {
+		http.Error(w, "account disabled", http.StatusForbidden)
+		return
+	}
+	if passwordExpired(user) {
+		writeError(w, http.StatusForbidden, ErrCodePasswordExpired, "password change required: set a new password with POST /password/change")
+		return
+	}
 
 	newToken, err := auth.GenerateToken(user)
 	if err != nil {
@@ -295,9 +551,10 @@ func RefreshHandler(w http.ResponseWriter, r *http.Request) {
 	// Rotate refresh token
 	newRefreshToken := generateRandomToken()
 	err = auth.SetRefreshToken(user.Username, key, auth.RefreshTokenEntry{
-		Token:     newRefreshToken,
-		IPAddress: host,
-		UserAgent: ua,
+		Token:      newRefreshToken,
+		IPAddress:  host,
+		UserAgent:  ua,
+		DeviceName: stored.DeviceName,
 	})
 	if err != nil {
 		log.Printf("Failed to set refresh token: %v", err)
@@ -324,14 +581,8 @@ func ListRefreshTokensHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	for username, sessions := range refreshTokens {
-		for _, entry := range sessions {
-			tokens = append(tokens, RefreshTokenInfo{
-				Username:  username,
-				IssuedAt:  entry.CreatedAt,
-				ExpiresAt: entry.CreatedAt.Add(auth.RefreshTokenMaxAge),
-				IPAddress: entry.IPAddress,
-				UserAgent: entry.UserAgent,
-			})
+		for key, entry := range sessions {
+			tokens = append(tokens, sessionInfo(username, key, entry))
 		}
 	}
 
@@ -391,7 +642,7 @@ func LogoutHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	ua := r.UserAgent()
-	key := sessionKey(host, ua)
+	key := auth.SessionKey(host, ua)
 	if err := auth.RemoveRefreshToken(username, key); err != nil {
 		http.Error(w, "Failed to handle refresh token", http.StatusInternalServerError)
 		return
@@ -458,14 +709,7 @@ func ListUserTokensHandler(w http.ResponseWriter, r *http.Request) {
 
 	tokens := []RefreshTokenInfo{}
 	for sessionKey, entry := range userSessions {
-		tokens = append(tokens, RefreshTokenInfo{
-			SessionKey: sessionKey,
-			Username:   username,
-			IssuedAt:   entry.CreatedAt,
-			ExpiresAt:  entry.CreatedAt.Add(auth.RefreshTokenMaxAge),
-			IPAddress:  entry.IPAddress,
-			UserAgent:  entry.UserAgent,
-		})
+		tokens = append(tokens, sessionInfo(username, sessionKey, entry))
 	}
 
 	if err := writeJSON(w, http.StatusOK, tokens); err != nil {
@@ -543,7 +787,13 @@ func RevokeUserSessionHandler(w http.ResponseWriter, r *http.Request) {
 // @Success 200 {object} map[string]string
 // @Failure 403 {string} string "Forbidden"
 // @Failure 404 {string} string "User not found"
+// @Failure 409 {string} string "Account disabled"
 // @Failure 500 {string} string "Failed to generate token"
+// @Failure 429 {string} string "Too many requests"
+// @Header all {integer} X-RateLimit-Limit "Requests allowed per window"
+// @Header all {integer} X-RateLimit-Remaining "Requests left in the current window"
+// @Header all {integer} X-RateLimit-Reset "Seconds until the window resets"
+// @Header 429 {integer} Retry-After "Seconds to wait before retrying"
 // @Router /admin/users/{username}/tokens [post]
 func AdminImpersonateUserHandler(w http.ResponseWriter, r *http.Request) {
 
@@ -554,6 +804,10 @@ func AdminImpersonateUserHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "User not found", http.StatusNotFound)
 		return
 	}
+	if user.Disabled {
+		http.Error(w, "account disabled", http.StatusConflict)
+		return
+	}
 
 	host, _, err := net.SplitHostPort(r.RemoteAddr)
 	if err != nil {
@@ -561,7 +815,7 @@ func AdminImpersonateUserHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	ua := r.UserAgent()
-	key := sessionKey(host, ua)
+	key := auth.SessionKey(host, ua)
 
 	authorization := r.Header.Get("Authorization")
 	_, claims, err := auth.TokenClaims(authorization)
@@ -577,7 +831,16 @@ func AdminImpersonateUserHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Optional: mark token as impersonated via claims (for audit log, future)
+	recordAudit(r, "impersonate", "user", user.Username, nil)
+	security.Publish(security.Event{
+		Type:     security.EventImpersonation,
+		Severity: security.SeverityWarning,
+		Actor:    impersonator,
+		Target:   user.Username,
+		SourceIP: host,
+		Details:  map[string]any{"role": user.Role},
+	})
+
 	refreshToken := generateRandomToken()
 
 	err = auth.SetRefreshToken(user.Username, key, auth.RefreshTokenEntry{
@@ -588,6 +851,7 @@ func AdminImpersonateUserHandler(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		log.Printf("Failed to set refresh token: %v", err)
 	}
+	touchSession(user.Username, key)
 
 	if err := writeJSON(w, http.StatusOK, LoginResult{AccessToken: accessToken, RefreshToken: refreshToken}); err != nil {
 		log.Printf("Failed to write JSON response: %v", err)
@@ -602,7 +866,7 @@ func AdminImpersonateUserHandler(w http.ResponseWriter, r *http.Request) {
 // @Failure 500 {string} string "Redis error"
 // @Router /admin/bans [get]
 func ListActiveBansHandler(w http.ResponseWriter, r *http.Request) {
-	keys, err := Rdb.Keys(Ctx, "ratelimit:ban:*").Result()
+	keys, err := redissvc.Keys(Ctx, Rdb, "ratelimit:ban:*")
 	if err != nil {
 		http.Error(w, "Failed to read bans", http.StatusInternalServerError)
 		return
@@ -653,6 +917,7 @@ func UnbanHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Ban not found", http.StatusNotFound)
 		return
 	}
+	recordAudit(r, "unban", "ban", id, nil)
 
 	w.WriteHeader(http.StatusNoContent)
 }
@@ -685,10 +950,13 @@ func TriggerDailyBanSummaryHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func sessionKey(ip, ua string) string {
-	h := sha256.New()
-	h.Write([]byte(ip + ua))
-	return hex.EncodeToString(h.Sum(nil))
+// touchSession starts the idle timeout of a new session.
+func touchSession(username, key string) {
+	if idle := auth.SessionIdleTimeout(); idle > 0 {
+		if err := usage.TouchSession(username, key, idle); err != nil {
+			log.Printf("Failed to record session activity: %v", err)
+		}
+	}
 }
 
 func generateRandomToken() string {
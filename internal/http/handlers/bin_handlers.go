@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+)
+
+const maxBinNameLength = 50
+
+// normalizeBinName trims name and checks it can name a bin or warehouse.
+func normalizeBinName(field, name string, required bool) (string, error) {
+	name = strings.TrimSpace(name)
+	switch {
+	case name == "" && required:
+		return name, fmt.Errorf("%s is required", field)
+	case len(name) > maxBinNameLength:
+		return name, fmt.Errorf("%s must be at most %d characters", field, maxBinNameLength)
+	}
+	return name, nil
+}
+
+// GetBinsHandler godoc
+// @Summary List where a product's stock sits
+// @Tags inventory
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param warehouse query string false "Only bins of this warehouse"
+// @Success 200 {object} BinsResponse
+// @Failure 400 {string} string "Invalid ID"
+// @Failure 404 {string} string "Product not found"
+// @Failure 500 {string} string "Internal error"
+// @Router /products/{id}/bins [get]
+func GetBinsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid product ID", http.StatusBadRequest)
+		return
+	}
+	if !productAccessible(w, r, id) {
+		return
+	}
+	writeBins(w, id, r.URL.Query().Get("warehouse"), nil)
+}
+
+// SetBinHandler godoc
+// @Summary Record how much of a product is in a bin
+// @Description Sets the count in one bin, such as after putting stock away or a recount. A zero quantity empties the bin. Bins cannot hold more than the product's quantity between them. Adding units beyond the capacity of the bin or its warehouse is refused or answered with warnings, depending on the capacity's mode.
+// @Tags inventory
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param bin body BinRequest true "Bin and quantity"
+// @Success 200 {object} BinsResponse
+// @Failure 400 {string} string "Invalid input"
+// @Failure 404 {string} string "Product not found"
+// @Failure 409 {string} string "Bins would exceed the product's quantity or a capacity in reject mode"
+// @Failure 500 {string} string "Internal error"
+// @Router /products/{id}/bins [put]
+func SetBinHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid product ID", http.StatusBadRequest)
+		return
+	}
+	var req BinRequest
+	if err := readJSON(w, r, &req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	b := models.BinStock{ProductID: id, Quantity: req.Quantity}
+	if b.Warehouse, err = normalizeBinName("warehouse", req.Warehouse, false); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if b.Bin, err = normalizeBinName("bin", req.Bin, true); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if b.Quantity < 0 {
+		http.Error(w, "quantity cannot be negative", http.StatusBadRequest)
+		return
+	}
+	if !productAccessible(w, r, id) {
+		return
+	}
+
+	bins, err := binRepo.List(id)
+	if err != nil {
+		http.Error(w, "could not fetch bins", http.StatusInternalServerError)
+		return
+	}
+	increase := b.Quantity
+	for _, held := range bins {
+		if held.Warehouse == b.Warehouse && held.Bin == b.Bin {
+			increase -= held.Quantity
+		}
+	}
+	warnings, err := checkCapacity(b.Warehouse, b.Bin, increase, true)
+	if err != nil {
+		writeBinError(w, err)
+		return
+	}
+	if err := binRepo.Set(b); err != nil {
+		writeBinError(w, err)
+		return
+	}
+
+	recordAudit(r, "set_bin", "product", id, b)
+	writeBins(w, id, b.Warehouse, warnings)
+}
+
+// MoveBinStockHandler godoc
+// @Summary Move stock between bins
+// @Description Moves units of a product from one bin to another in the same warehouse. The product's quantity does not change. The destination bin's capacity applies as when setting a bin.
+// @Tags inventory
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param move body BinMoveRequest true "Source and destination bins"
+// @Success 200 {object} BinsResponse
+// @Failure 400 {string} string "Invalid input"
+// @Failure 404 {string} string "Product or source bin not found"
+// @Failure 409 {string} string "Not enough stock in the source bin, or the destination is full"
+// @Failure 500 {string} string "Internal error"
+// @Router /products/{id}/bins/move [post]
+func MoveBinStockHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid product ID", http.StatusBadRequest)
+		return
+	}
+	var req BinMoveRequest
+	if err := readJSON(w, r, &req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	warehouse, err := normalizeBinName("warehouse", req.Warehouse, false)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	from, err := normalizeBinName("from", req.From, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	to, err := normalizeBinName("to", req.To, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	switch {
+	case from == to:
+		http.Error(w, "from and to must be different bins", http.StatusBadRequest)
+		return
+	case req.Quantity <= 0:
+		http.Error(w, "quantity must be positive", http.StatusBadRequest)
+		return
+	}
+
+	if !productAccessible(w, r, id) {
+		return
+	}
+	if _, err := productRepo.GetByID(id); err != nil {
+		writeBinError(w, err)
+		return
+	}
+	// The stock stays in the warehouse, so only the destination bin can fill up.
+	warnings, err := checkCapacity(warehouse, to, req.Quantity, false)
+	if err != nil {
+		writeBinError(w, err)
+		return
+	}
+	if err := binRepo.Move(id, warehouse, from, to, req.Quantity); err != nil {
+		writeBinError(w, err)
+		return
+	}
+
+	recordAudit(r, "move_bin", "product", id, map[string]any{"warehouse": warehouse, "from": from, "to": to, "quantity": req.Quantity})
+	writeBins(w, id, warehouse, warnings)
+}
+
+// writeBins answers with the product's bins, in warehouse when given.
+func writeBins(w http.ResponseWriter, productID int, warehouse string, warnings []string) {
+	product, err := productRepo.GetByID(productID)
+	if err != nil {
+		writeBinError(w, err)
+		return
+	}
+	bins, err := binRepo.List(productID)
+	if err != nil {
+		http.Error(w, "could not fetch bins", http.StatusInternalServerError)
+		return
+	}
+
+	resp := BinsResponse{ProductID: productID, Quantity: product.Quantity, Unassigned: product.Quantity, Bins: []models.BinStock{}, Warnings: warnings}
+	warehouse = strings.TrimSpace(warehouse)
+	for _, b := range bins {
+		resp.Unassigned -= b.Quantity
+		if warehouse == "" || strings.EqualFold(b.Warehouse, warehouse) {
+			resp.Bins = append(resp.Bins, b)
+		}
+	}
+	if err := writeJSON(w, http.StatusOK, resp); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// scanBins returns the product's bins for a scan response, in warehouse when
+// given. Scans still succeed when bins cannot be read; they just omit them.
+func scanBins(productID int, warehouse string) []BinLocation {
+	bins, err := binRepo.List(productID)
+	if err != nil {
+		log.Printf("failed to fetch bins of product %d: %v", productID, err)
+		return nil
+	}
+	warehouse = strings.TrimSpace(warehouse)
+	var locations []BinLocation
+	for _, b := range bins {
+		if warehouse == "" || strings.EqualFold(b.Warehouse, warehouse) {
+			locations = append(locations, BinLocation{Warehouse: b.Warehouse, Bin: b.Bin, Quantity: b.Quantity})
+		}
+	}
+	return locations
+}
+
+func writeBinError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, repo.ErrProductNotFound):
+		http.Error(w, "product not found", http.StatusNotFound)
+	case errors.Is(err, repo.ErrBinNotFound):
+		http.Error(w, "source bin is empty", http.StatusNotFound)
+	case errors.Is(err, repo.ErrBinsExceedStock), errors.Is(err, errCapacityExceeded):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, repo.ErrInvalidQuantityChange):
+		http.Error(w, "not enough stock in the source bin", http.StatusConflict)
+	default:
+		log.Printf("bins: %v", err)
+		http.Error(w, "could not process bins", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+)
+
+const (
+	// maxBulkBytes is well above readJSON's limit: bulk loads exist to move a lot of data.
+	maxBulkBytes = 32 << 20
+	maxBulkRows  = 100000
+)
+
+// BulkInsertProductsHandler godoc
+// @Summary Bulk insert products
+// @Description Inserts every product with a single COPY, or none of them if any row is invalid or already exists. Intended for load tests and benchmarks. Prices that break the price rules make the batch invalid in reject mode; accepted ones are listed in the audit log.
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param products body []ProductRequest true "Products to insert"
+// @Param override_price_rules query bool false "Accept prices that break the price rules"
+// @Success 201 {object} BulkInsertResult
+// @Failure 400 {object} []ProductValidationError
+// @Failure 403 {object} ErrorResponse "Product quota exceeded"
+// @Failure 409 {string} string "Duplicated product name"
+// @Failure 500 {string} string "Internal error"
+// @Router /admin/bulk/products [post]
+func BulkInsertProductsHandler(w http.ResponseWriter, r *http.Request) {
+	var reqs []ProductRequest
+	if err := readBulkJSON(w, r, &reqs); err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidInput, err.Error())
+		return
+	}
+
+	required, err := requiredFields()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "could not load validation policy")
+		return
+	}
+	rules, err := priceRulesRepo.Get(tenant)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "could not load price rules")
+		return
+	}
+
+	var errs []ProductValidationError
+	var priceFlags []string
+	seen := make(map[string]bool, len(reqs))
+	products := make([]models.Product, len(reqs))
+	now := nowRFC3339()
+	for i, p := range reqs {
+		for _, e := range validateProduct(p) {
+			e.Description = fmt.Sprintf("item %d: %s", i, e.Description)
+			errs = append(errs, e)
+		}
+		if seen[repo.NameKey(p.Name)] {
+			errs = append(errs, ProductValidationError{Field: "Name", Code: ErrCodeDuplicateName, Description: fmt.Sprintf("item %d: duplicated name %q", i, p.Name)})
+		}
+		seen[repo.NameKey(p.Name)] = true
+		products[i] = models.Product{Name: p.Name, Price: p.Price, Quantity: p.Quantity, Threshold: p.Threshold, ExternalID: strings.ToLower(strings.TrimSpace(p.ExternalID)), CreatedAt: now, UpdatedAt: now}
+		for _, e := range validateRequiredFields(products[i], required) {
+			e.Description = fmt.Sprintf("item %d: %s", i, e.Description)
+			errs = append(errs, e)
+		}
+		check := screenPrice(r, rules, p.Price, nil)
+		for _, v := range check.Violations {
+			if check.Rejected {
+				errs = append(errs, ProductValidationError{Field: "Price", Code: ErrCodeSuspiciousPrice, Description: fmt.Sprintf("item %d: %s", i, v)})
+			} else {
+				priceFlags = append(priceFlags, fmt.Sprintf("item %d: %s", i, v))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		if err := writeJSON(w, http.StatusBadRequest, errs); err != nil {
+			log.Printf("Failed to write JSON response: %v", err)
+		}
+		return
+	}
+
+	if err := checkProductQuota(len(products)); err != nil {
+		writeQuotaError(w, err)
+		return
+	}
+
+	start := time.Now()
+	n, err := productRepo.CreateBatch(products)
+	if err != nil {
+		if errors.Is(err, repo.ErrDuplicatedExternalID) {
+			writeError(w, http.StatusConflict, ErrCodeDuplicateExternalID, "one of these external IDs is already in use")
+			return
+		}
+		if errors.Is(err, repo.ErrDuplicatedValueUnique) {
+			writeError(w, http.StatusConflict, ErrCodeDuplicateName, "a product with one of these names already exists")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "could not insert products")
+		return
+	}
+
+	details := map[string]any{"inserted": n}
+	if len(priceFlags) > 0 {
+		details["price_flags"] = priceFlags
+	}
+	recordAudit(r, "bulk_create", "product", "", details)
+	if err := writeJSON(w, http.StatusCreated, BulkInsertResult{Inserted: n, ElapsedMs: time.Since(start).Milliseconds()}); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// BulkInsertMovementsHandler godoc
+// @Summary Bulk insert movements
+// @Description Applies every movement to product quantities and logs it with a single COPY, or rejects the whole batch. Intended for load tests and benchmarks.
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param movements body []models.Movement true "Movements to insert; created_at is optional (RFC3339)"
+// @Success 201 {object} BulkInsertResult
+// @Failure 400 {string} string "Invalid input"
+// @Failure 409 {string} string "Quantity would become negative or period closed"
+// @Failure 500 {string} string "Internal error"
+// @Router /admin/bulk/movements [post]
+func BulkInsertMovementsHandler(w http.ResponseWriter, r *http.Request) {
+	var movements []models.Movement
+	if err := readBulkJSON(w, r, &movements); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	deltas := make(map[int]int)
+	periods := make(map[string]time.Time)
+	now := time.Now().UTC()
+	for i, m := range movements {
+		if m.Delta == 0 {
+			http.Error(w, fmt.Sprintf("item %d: delta must not be zero", i), http.StatusBadRequest)
+			return
+		}
+		at := now
+		if m.CreatedAt != "" {
+			t, err := time.Parse(time.RFC3339, m.CreatedAt)
+			if err != nil || t.After(now) {
+				http.Error(w, fmt.Sprintf("item %d: created_at must be a past RFC3339 timestamp", i), http.StatusBadRequest)
+				return
+			}
+			at = t
+		}
+		deltas[m.ProductID] += m.Delta
+		periods[repo.PeriodOf(at)] = at
+	}
+
+	for _, at := range periods {
+		if err := ensurePeriodOpen(at); err != nil {
+			if errors.Is(err, errPeriodClosed) {
+				http.Error(w, err.Error(), http.StatusConflict)
+				return
+			}
+			http.Error(w, "could not verify accounting period", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	start := time.Now()
+	if err := productRepo.AdjustQuantities(deltas); err != nil {
+		if errors.Is(err, repo.ErrInvalidQuantityChange) {
+			http.Error(w, "a product is missing or its quantity would become negative", http.StatusConflict)
+			return
+		}
+		http.Error(w, "could not update quantities", http.StatusInternalServerError)
+		return
+	}
+
+	n, err := movementRepo.LogBatch(movements)
+	if err != nil {
+		// Undo the quantity change so stock still matches the ledger.
+		undo := make(map[int]int, len(deltas))
+		for id, d := range deltas {
+			undo[id] = -d
+		}
+		if undoErr := productRepo.AdjustQuantities(undo); undoErr != nil {
+			log.Printf("⚠️ ALERT: bulk movement log failed and quantities could not be restored: %v", undoErr)
+		}
+		http.Error(w, "could not log movements", http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(r, "bulk_adjust", "movement", "", map[string]any{"inserted": n, "deltas": deltas})
+	if err := writeJSON(w, http.StatusCreated, BulkInsertResult{Inserted: n, ElapsedMs: time.Since(start).Milliseconds()}); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// readBulkJSON decodes a JSON array body of at most maxBulkRows items.
+func readBulkJSON[T any](w http.ResponseWriter, r *http.Request, items *[]T) error {
+	r.Body = http.MaxBytesReader(w, r.Body, maxBulkBytes)
+	if err := json.NewDecoder(r.Body).Decode(items); err != nil {
+		return errors.New("invalid input")
+	}
+	if len(*items) == 0 {
+		return errors.New("no items to insert")
+	}
+	if len(*items) > maxBulkRows {
+		return fmt.Errorf("at most %d items per request", maxBulkRows)
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+)
+
+var errCapacityExceeded = errors.New("capacity exceeded")
+
+// checkCapacity looks for capacities that adding increase units to a bin of
+// warehouse would overfill: the bin's own and, unless the units are already
+// in the warehouse, the warehouse's. It returns a warning for each one in
+// warn mode and errCapacityExceeded for the first in reject mode.
+func checkCapacity(warehouse, bin string, increase int, intoWarehouse bool) ([]string, error) {
+	if increase <= 0 {
+		return nil, nil
+	}
+	capacities, err := capacityRepo.List()
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch capacities: %w", err)
+	}
+
+	var warnings []string
+	for _, c := range capacities {
+		if c.Warehouse != warehouse || (c.Bin != bin && (c.Bin != "" || !intoWarehouse)) {
+			continue
+		}
+		used, err := binRepo.Occupancy(c.Warehouse, c.Bin)
+		if err != nil {
+			return nil, fmt.Errorf("could not measure occupancy: %w", err)
+		}
+		if used+increase <= c.MaxUnits {
+			continue
+		}
+		msg := fmt.Sprintf("%s would hold %d units, over its capacity of %d", capacityName(c), used+increase, c.MaxUnits)
+		if c.Mode == models.CapacityModeReject {
+			return nil, fmt.Errorf("%w: %s", errCapacityExceeded, msg)
+		}
+		warnings = append(warnings, msg)
+	}
+	return warnings, nil
+}
+
+func capacityName(c models.Capacity) string {
+	name := "warehouse"
+	if c.Warehouse != "" {
+		name = fmt.Sprintf("warehouse %q", c.Warehouse)
+	}
+	if c.Bin != "" {
+		name = fmt.Sprintf("bin %q of %s", c.Bin, name)
+	}
+	return name
+}
+
+// PutCapacityHandler godoc
+// @Summary Set the capacity of a bin or warehouse
+// @Description Limits the units, across all products, that a bin holds, or a whole warehouse when bin is left out. Placing stock beyond it is answered with warnings in warn mode and refused in reject mode. Setting the capacity of the same bin again replaces it.
+// @Tags inventory
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param capacity body CapacityRequest true "Capacity"
+// @Success 200 {object} models.Capacity
+// @Failure 400 {string} string "Invalid input"
+// @Failure 500 {string} string "Internal error"
+// @Router /admin/capacities [put]
+func PutCapacityHandler(w http.ResponseWriter, r *http.Request) {
+	var req CapacityRequest
+	if err := readJSON(w, r, &req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	c := models.Capacity{MaxUnits: req.MaxUnits, Mode: req.Mode}
+	var err error
+	if c.Warehouse, err = normalizeBinName("warehouse", req.Warehouse, false); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if c.Bin, err = normalizeBinName("bin", req.Bin, false); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if c.Mode == "" {
+		c.Mode = models.CapacityModeWarn
+	}
+	switch {
+	case c.MaxUnits <= 0:
+		http.Error(w, "max_units must be positive", http.StatusBadRequest)
+		return
+	case c.Mode != models.CapacityModeWarn && c.Mode != models.CapacityModeReject:
+		http.Error(w, "mode must be warn or reject", http.StatusBadRequest)
+		return
+	}
+
+	saved, err := capacityRepo.Put(c)
+	if err != nil {
+		http.Error(w, "could not save capacity", http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(r, "set", "capacity", saved.ID, saved)
+	if err := writeJSON(w, http.StatusOK, saved); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// ListCapacitiesHandler godoc
+// @Summary List bin and warehouse capacities
+// @Tags inventory
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.Capacity
+// @Failure 500 {string} string "Internal error"
+// @Router /admin/capacities [get]
+func ListCapacitiesHandler(w http.ResponseWriter, r *http.Request) {
+	capacities, err := capacityRepo.List()
+	if err != nil {
+		http.Error(w, "could not fetch capacities", http.StatusInternalServerError)
+		return
+	}
+	if err := writeJSON(w, http.StatusOK, capacities); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// DeleteCapacityHandler godoc
+// @Summary Remove a capacity limit
+// @Tags inventory
+// @Security BearerAuth
+// @Param id path int true "Capacity ID"
+// @Success 204 "No Content"
+// @Failure 400 {string} string "Invalid ID"
+// @Failure 404 {string} string "Capacity not found"
+// @Failure 500 {string} string "Internal error"
+// @Router /admin/capacities/{id} [delete]
+func DeleteCapacityHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid capacity ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := capacityRepo.Delete(id); err != nil {
+		if errors.Is(err, repo.ErrCapacityNotFound) {
+			http.Error(w, "capacity not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "could not delete capacity", http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(r, "delete", "capacity", id, nil)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CapacityReportHandler godoc
+// @Summary Utilization of bins and warehouses with a capacity
+// @Tags reports
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} CapacityUsage
+// @Failure 500 {string} string "Internal error"
+// @Router /reports/capacity [get]
+func CapacityReportHandler(w http.ResponseWriter, r *http.Request) {
+	capacities, err := capacityRepo.List()
+	if err != nil {
+		http.Error(w, "could not fetch capacities", http.StatusInternalServerError)
+		return
+	}
+
+	report := make([]CapacityUsage, len(capacities))
+	for i, c := range capacities {
+		used, err := binRepo.Occupancy(c.Warehouse, c.Bin)
+		if err != nil {
+			http.Error(w, "could not measure occupancy", http.StatusInternalServerError)
+			return
+		}
+		report[i] = CapacityUsage{
+			Capacity:    c,
+			Used:        used,
+			Utilization: math.Round(float64(used)/float64(c.MaxUnits)*1000) / 10,
+			Over:        used > c.MaxUnits,
+		}
+	}
+	if err := writeJSON(w, http.StatusOK, report); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
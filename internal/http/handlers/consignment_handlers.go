@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rogerio-castellano/inventory-tracker/internal/consignment"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+)
+
+const maxConsumptionNoteLength = 200
+
+// SetConsignmentHandler godoc
+// @Summary Flag a product's stock as consignment
+// @Description Marks how many units of the product's stock still belong to a supplier. Consigned units stay in the product's quantity but are left out of the valuation of owned stock until consumed. Setting it again replaces it; a zero quantity clears it.
+// @Tags inventory
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param consignment body ConsignmentRequest true "Supplier, units and unit cost"
+// @Success 200 {object} models.ConsignmentStock
+// @Failure 400 {string} string "Invalid input"
+// @Failure 404 {string} string "Product not found"
+// @Failure 409 {string} string "More units than the product holds"
+// @Failure 500 {string} string "Internal error"
+// @Router /products/{id}/consignment [put]
+func SetConsignmentHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid product ID", http.StatusBadRequest)
+		return
+	}
+	var req ConsignmentRequest
+	if err := readJSON(w, r, &req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Quantity < 0 {
+		http.Error(w, "quantity cannot be negative", http.StatusBadRequest)
+		return
+	}
+	if req.UnitCost < 0 {
+		http.Error(w, "unit_cost cannot be negative", http.StatusBadRequest)
+		return
+	}
+
+	p, err := productRepo.GetByID(id)
+	if err != nil {
+		writeConsignmentError(w, err)
+		return
+	}
+	c := models.ConsignmentStock{ProductID: id, Supplier: strings.TrimSpace(req.Supplier), Quantity: req.Quantity, UnitCost: roundMoney(req.UnitCost)}
+	if c.Supplier == "" {
+		c.Supplier = p.Supplier
+	}
+	if c.UnitCost == 0 {
+		c.UnitCost = p.Cost
+	}
+	if c.Quantity > 0 {
+		switch {
+		case c.Supplier == "":
+			http.Error(w, "supplier is required for a product without one", http.StatusBadRequest)
+			return
+		case c.UnitCost <= 0:
+			http.Error(w, "unit_cost is required for a product without a cost", http.StatusBadRequest)
+			return
+		}
+	}
+
+	if err := consignmentRepo.Set(c); err != nil {
+		writeConsignmentError(w, err)
+		return
+	}
+
+	recordAudit(r, "set_consignment", "product", id, c)
+	if err := writeJSON(w, http.StatusOK, c); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// ConsumeConsignmentHandler godoc
+// @Summary Report consumption of consignment stock
+// @Description Takes consigned units out of stock, logging a movement, and records them as owed to the supplier at the consignment's unit cost. They appear in the settlement report.
+// @Tags inventory
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param consumption body ConsumeConsignmentRequest true "Units consumed"
+// @Success 201 {object} models.ConsignmentConsumption
+// @Failure 400 {string} string "Invalid input"
+// @Failure 403 {string} string "Caller restricted to warehouses"
+// @Failure 404 {string} string "Product has no consigned stock"
+// @Failure 409 {string} string "Not enough consigned stock, or period closed"
+// @Failure 500 {string} string "Internal error"
+// @Router /products/{id}/consignment/consume [post]
+func ConsumeConsignmentHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid product ID", http.StatusBadRequest)
+		return
+	}
+	var req ConsumeConsignmentRequest
+	if err := readJSON(w, r, &req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	c := models.ConsignmentConsumption{ProductID: id, Quantity: req.Quantity, Note: strings.TrimSpace(req.Note)}
+	switch {
+	case c.Quantity <= 0:
+		http.Error(w, "quantity must be positive", http.StatusBadRequest)
+		return
+	case len(c.Note) > maxConsumptionNoteLength:
+		http.Error(w, fmt.Sprintf("note must be at most %d characters", maxConsumptionNoteLength), http.StatusBadRequest)
+		return
+	}
+	if !adjustmentInScope(w, r, id, nil) {
+		return
+	}
+
+	if err := ensurePeriodOpen(time.Now().UTC()); err != nil {
+		if errors.Is(err, errPeriodClosed) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		http.Error(w, "could not verify accounting period", http.StatusInternalServerError)
+		return
+	}
+	if c.ConsumedBy, err = GetUsernameFromContext(r); err != nil {
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	consumed, err := consignmentRepo.Consume(c)
+	if err != nil {
+		writeConsignmentError(w, err)
+		return
+	}
+
+	recordAudit(r, "consume_consignment", "product", id, consumed)
+	if err := writeJSON(w, http.StatusCreated, consumed); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// ListConsignmentsHandler godoc
+// @Summary List consignment stock
+// @Tags inventory
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.ConsignmentStock
+// @Failure 500 {string} string "Internal error"
+// @Router /consignments [get]
+func ListConsignmentsHandler(w http.ResponseWriter, r *http.Request) {
+	stock, err := consignmentRepo.List()
+	if err != nil {
+		http.Error(w, "could not fetch consignment stock", http.StatusInternalServerError)
+		return
+	}
+	if err := writeJSON(w, http.StatusOK, stock); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// ConsignmentSettlementHandler godoc
+// @Summary What is owed to suppliers for consumed consignment stock
+// @Description Sums consumption per supplier and product between from and to. The same report for the previous month is emailed on the first of every month.
+// @Tags reports
+// @Security BearerAuth
+// @Produce json
+// @Param from query string false "Start date (YYYY-MM-DD or RFC3339, default the first of last month)"
+// @Param to query string false "End date, exclusive (default the first of this month)"
+// @Param supplier query string false "Only this supplier"
+// @Success 200 {object} ConsignmentSettlementReport
+// @Failure 400 {string} string "Invalid input"
+// @Failure 403 {string} string "Missing pricing:read permission"
+// @Failure 500 {string} string "Internal error"
+// @Router /reports/consignment-settlement [get]
+func ConsignmentSettlementHandler(w http.ResponseWriter, r *http.Request) {
+	from, to := consignment.PreviousMonth(time.Now())
+	for _, bound := range []struct {
+		name string
+		t    *time.Time
+	}{{"from", &from}, {"to", &to}} {
+		if raw := r.URL.Query().Get(bound.name); raw != "" {
+			t, err := parseDate(raw)
+			if err != nil {
+				http.Error(w, bound.name+" must be a date (YYYY-MM-DD or RFC3339)", http.StatusBadRequest)
+				return
+			}
+			*bound.t = t
+		}
+	}
+	if !to.After(from) {
+		http.Error(w, "to must be after from", http.StatusBadRequest)
+		return
+	}
+
+	settlements, err := consignmentRepo.Settlement(from, to, strings.TrimSpace(r.URL.Query().Get("supplier")))
+	if err != nil {
+		http.Error(w, "could not fetch consumption", http.StatusInternalServerError)
+		return
+	}
+	report := ConsignmentSettlementReport{From: from.Format(time.RFC3339), To: to.Format(time.RFC3339), Suppliers: settlements}
+	for _, s := range settlements {
+		report.TotalCost += s.TotalCost
+	}
+	report.TotalCost = roundMoney(report.TotalCost)
+	if err := writeJSON(w, http.StatusOK, report); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+func writeConsignmentError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, repo.ErrProductNotFound):
+		http.Error(w, "product not found", http.StatusNotFound)
+	case errors.Is(err, repo.ErrConsignmentNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, repo.ErrConsignmentExceedsStock):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, repo.ErrInvalidQuantityChange):
+		http.Error(w, "not enough consigned stock", http.StatusConflict)
+	default:
+		log.Printf("consignment: %v", err)
+		http.Error(w, "could not process consignment", http.StatusInternalServerError)
+	}
+}
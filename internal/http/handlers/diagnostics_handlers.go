@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+)
+
+// ExplainMovementsQueryHandler godoc
+// @Summary Explain the movement history queries
+// @Description Runs EXPLAIN ANALYZE on the queries behind GET /products/{id}/movements. Only available when QUERY_DIAGNOSTICS is enabled.
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param since query string false "Filter movements from this timestamp (RFC3339)"
+// @Param until query string false "Filter movements until this timestamp (RFC3339)"
+// @Param offset query int false "Offset for pagination"
+// @Param limit query int false "Limit for pagination"
+// @Success 200 {array} repo.QueryPlan
+// @Failure 400 {string} string "Invalid input"
+// @Failure 404 {string} string "Not found"
+// @Failure 501 {string} string "Not supported by the storage backend"
+// @Failure 500 {string} string "Internal error"
+// @Router /admin/diagnostics/products/{id}/movements/explain [get]
+func ExplainMovementsQueryHandler(w http.ResponseWriter, r *http.Request) {
+	if !queryDiagnosticsEnabled {
+		http.NotFound(w, r)
+		return
+	}
+
+	explainer, ok := movementRepo.(repo.MovementQueryExplainer)
+	if !ok {
+		http.Error(w, "query plans are not available for this storage backend", http.StatusNotImplemented)
+		return
+	}
+
+	id, err := parseID(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid product ID", http.StatusBadRequest)
+		return
+	}
+	filter, err := parseMovementFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	plans, err := explainer.ExplainGetByProductID(id, filter)
+	if err != nil {
+		log.Printf("failed to explain movement queries for product %d: %v", id, err)
+		http.Error(w, "could not explain queries", http.StatusInternalServerError)
+		return
+	}
+
+	if err := writeJSON(w, http.StatusOK, plans); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
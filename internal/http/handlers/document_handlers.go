@@ -0,0 +1,397 @@
+package handlers
+
+import (
+	"cmp"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+	"github.com/rogerio-castellano/inventory-tracker/internal/storage"
+)
+
+const maxDocumentBytes = 20 << 20 // 20 MB
+
+// downloadURLTTL is how long presigned download URLs stay valid.
+const downloadURLTTL = 15 * time.Minute
+
+// defaultExpiryWindow is how far ahead expiry reports look unless told otherwise.
+const defaultExpiryWindow = 30 * 24 * time.Hour
+
+// UploadDocumentHandler godoc
+// @Summary Attach a document to a product
+// @Tags documents
+// @Security BearerAuth
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param file formData file true "Document file (max 20 MB)"
+// @Param type formData string true "datasheet, certificate, manual or other"
+// @Param expires_at formData string false "Expiry date (YYYY-MM-DD or RFC3339), typically for certificates"
+// @Success 201 {object} models.ProductDocument
+// @Failure 400 {string} string "Invalid input"
+// @Failure 404 {string} string "Product not found"
+// @Failure 422 {object} ErrorResponse "Infected file"
+// @Failure 500 {string} string "Internal error"
+// @Failure 503 {object} ErrorResponse "File could not be scanned"
+// @Router /products/{id}/documents [post]
+func UploadDocumentHandler(w http.ResponseWriter, r *http.Request) {
+	productID, err := parseID(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid product ID", http.StatusBadRequest)
+		return
+	}
+	if _, err := productRepo.GetByID(productID); err != nil {
+		http.Error(w, "product not found", http.StatusNotFound)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxDocumentBytes+1<<20)
+	if err := r.ParseMultipartForm(maxDocumentBytes); err != nil {
+		http.Error(w, "file too large or invalid form", http.StatusBadRequest)
+		return
+	}
+
+	docType := strings.ToLower(r.FormValue("type"))
+	if !models.ValidDocumentType(docType) {
+		http.Error(w, "type must be datasheet, certificate, manual or other", http.StatusBadRequest)
+		return
+	}
+
+	var expiresAt *time.Time
+	if raw := r.FormValue("expires_at"); raw != "" {
+		t, err := parseDate(raw)
+		if err != nil {
+			http.Error(w, "invalid expires_at date format", http.StatusBadRequest)
+			return
+		}
+		expiresAt = &t
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "missing file", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+	if !scanUpload(w, r, file, header.Filename) {
+		return
+	}
+
+	contentType, err := uploadContentType(file, header)
+	if err != nil {
+		http.Error(w, "could not read file", http.StatusInternalServerError)
+		return
+	}
+
+	key, err := storageKey("products", productID, header.Filename)
+	if err != nil {
+		http.Error(w, "could not store document", http.StatusInternalServerError)
+		return
+	}
+	size, err := documentStore.Put(key, file)
+	if err != nil {
+		log.Printf("failed to store document for product %d: %v", productID, err)
+		http.Error(w, "could not store document", http.StatusInternalServerError)
+		return
+	}
+
+	username, _ := GetUsernameFromContext(r)
+	doc, err := documentRepo.Create(models.ProductDocument{
+		ProductID:   productID,
+		Type:        docType,
+		Filename:    filepath.Base(header.Filename),
+		ContentType: contentType,
+		Size:        size,
+		StorageKey:  key,
+		ExpiresAt:   expiresAt,
+		UploadedBy:  username,
+	})
+	if err != nil {
+		_ = documentStore.Delete(key)
+		http.Error(w, "could not save document", http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(r, "create", "document", doc.ID, doc)
+	if err := writeJSON(w, http.StatusCreated, doc); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// ListDocumentsHandler godoc
+// @Summary List a product's documents
+// @Tags documents
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Product ID"
+// @Success 200 {array} models.ProductDocument
+// @Failure 400 {string} string "Invalid input"
+// @Failure 500 {string} string "Internal error"
+// @Router /products/{id}/documents [get]
+func ListDocumentsHandler(w http.ResponseWriter, r *http.Request) {
+	productID, err := parseID(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid product ID", http.StatusBadRequest)
+		return
+	}
+
+	docs, err := documentRepo.GetByProductID(productID)
+	if err != nil {
+		http.Error(w, "could not fetch documents", http.StatusInternalServerError)
+		return
+	}
+
+	if err := writeJSON(w, http.StatusOK, docs); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// DownloadDocumentHandler godoc
+// @Summary Download a product document
+// @Tags documents
+// @Security BearerAuth
+// @Produce octet-stream
+// @Param id path int true "Product ID"
+// @Param docId path int true "Document ID"
+// @Success 200 {file} file "Document content"
+// @Failure 400 {string} string "Invalid input"
+// @Failure 404 {string} string "Not found"
+// @Failure 500 {string} string "Internal error"
+// @Router /products/{id}/documents/{docId} [get]
+func DownloadDocumentHandler(w http.ResponseWriter, r *http.Request) {
+	doc, ok := productDocument(w, r)
+	if !ok {
+		return
+	}
+
+	content, err := documentStore.Get(doc.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			http.Error(w, "document content missing", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "could not read document", http.StatusInternalServerError)
+		return
+	}
+	defer content.Close()
+
+	w.Header().Set("Content-Type", doc.ContentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": doc.Filename}))
+	w.Header().Set("Content-Length", fmt.Sprint(doc.Size))
+	if _, err := io.Copy(w, content); err != nil {
+		log.Printf("failed to send document %d: %v", doc.ID, err)
+	}
+}
+
+// DocumentDownloadURLHandler godoc
+// @Summary Get a temporary download URL for a product document
+// @Description With S3 or GCS storage, returns a presigned URL that downloads the document straight from the bucket, without a token, for 15 minutes.
+// @Tags documents
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param docId path int true "Document ID"
+// @Success 200 {object} DownloadURL
+// @Failure 400 {string} string "Invalid input"
+// @Failure 404 {string} string "Not found"
+// @Failure 500 {string} string "Internal error"
+// @Failure 501 {string} string "Storage cannot presign URLs"
+// @Router /products/{id}/documents/{docId}/url [get]
+func DocumentDownloadURLHandler(w http.ResponseWriter, r *http.Request) {
+	doc, ok := productDocument(w, r)
+	if !ok {
+		return
+	}
+	writeDownloadURL(w, doc.StorageKey, doc.Filename)
+}
+
+// DeleteDocumentHandler godoc
+// @Summary Delete a product document
+// @Tags documents
+// @Security BearerAuth
+// @Param id path int true "Product ID"
+// @Param docId path int true "Document ID"
+// @Success 204 "Deleted successfully"
+// @Failure 400 {string} string "Invalid input"
+// @Failure 404 {string} string "Not found"
+// @Failure 500 {string} string "Internal error"
+// @Router /products/{id}/documents/{docId} [delete]
+func DeleteDocumentHandler(w http.ResponseWriter, r *http.Request) {
+	doc, ok := productDocument(w, r)
+	if !ok {
+		return
+	}
+
+	if err := documentRepo.Delete(doc.ID); err != nil {
+		http.Error(w, "could not delete document", http.StatusInternalServerError)
+		return
+	}
+	if err := documentStore.Delete(doc.StorageKey); err != nil && !errors.Is(err, storage.ErrNotFound) {
+		log.Printf("failed to delete stored file of document %d: %v", doc.ID, err)
+	}
+
+	recordAudit(r, "delete", "document", doc.ID, doc)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ExpiringCertificatesHandler godoc
+// @Summary Report certificates that expired or expire soon
+// @Tags documents
+// @Security BearerAuth
+// @Produce json
+// @Param within query string false "Look-ahead window, e.g. 30d or 72h (default 30d)"
+// @Success 200 {array} ExpiringDocument
+// @Failure 400 {string} string "Invalid input"
+// @Failure 500 {string} string "Internal error"
+// @Router /reports/expiring-certificates [get]
+func ExpiringCertificatesHandler(w http.ResponseWriter, r *http.Request) {
+	within, err := parseWithin(r.URL.Query().Get("within"), defaultExpiryWindow)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now().UTC()
+	docs, err := documentRepo.GetExpiringBefore(now.Add(within))
+	if err != nil {
+		http.Error(w, "could not fetch documents", http.StatusInternalServerError)
+		return
+	}
+
+	names := map[int]string{}
+	report := []ExpiringDocument{}
+	for _, d := range docs {
+		if d.Type != models.DocumentTypeCertificate {
+			continue
+		}
+		if _, ok := names[d.ProductID]; !ok {
+			p, err := productRepo.GetByID(d.ProductID)
+			if err == nil {
+				names[d.ProductID] = p.Name
+			}
+		}
+		report = append(report, ExpiringDocument{
+			ProductDocument: d,
+			ProductName:     names[d.ProductID],
+			Expired:         d.ExpiresAt.Before(now),
+			DaysLeft:        int(d.ExpiresAt.Sub(now).Hours() / 24),
+		})
+	}
+
+	if err := writeJSON(w, http.StatusOK, report); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// productDocument loads the document named in the URL, making sure it
+// belongs to the product in the URL. It writes the error response itself.
+func productDocument(w http.ResponseWriter, r *http.Request) (models.ProductDocument, bool) {
+	productID, err := parseID(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid product ID", http.StatusBadRequest)
+		return models.ProductDocument{}, false
+	}
+	docID, err := parseID(chi.URLParam(r, "docId"))
+	if err != nil {
+		http.Error(w, "invalid document ID", http.StatusBadRequest)
+		return models.ProductDocument{}, false
+	}
+
+	doc, err := documentRepo.GetByID(docID)
+	if err != nil || doc.ProductID != productID {
+		if err != nil && !errors.Is(err, repo.ErrDocumentNotFound) {
+			http.Error(w, "could not fetch document", http.StatusInternalServerError)
+			return models.ProductDocument{}, false
+		}
+		http.Error(w, "document not found", http.StatusNotFound)
+		return models.ProductDocument{}, false
+	}
+	return doc, true
+}
+
+// uploadContentType returns the content type the client declared for an
+// uploaded file, or sniffs it from the content when none was given.
+func uploadContentType(file multipart.File, header *multipart.FileHeader) (string, error) {
+	contentType := header.Header.Get("Content-Type")
+	if contentType != "" && contentType != "application/octet-stream" {
+		return contentType, nil
+	}
+	sniff := make([]byte, 512)
+	n, _ := io.ReadFull(file, sniff)
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	return http.DetectContentType(sniff[:n]), nil
+}
+
+// scanUpload runs an uploaded file through the file scanner and rewinds it.
+// It writes the error response itself: 422 for an infected file, which is
+// also recorded in the audit trail, and 503 when the file could not be
+// scanned, so nothing unscanned gets through.
+func scanUpload(w http.ResponseWriter, r *http.Request, file multipart.File, filename string) bool {
+	verdict, err := fileScanner.Scan(filename, file)
+	if err != nil {
+		log.Printf("failed to scan upload %q: %v", filename, err)
+		writeError(w, http.StatusServiceUnavailable, ErrCodeInternal, "could not scan file, try again later")
+		return false
+	}
+	if verdict.Infected {
+		threat := cmp.Or(verdict.Threat, "malware")
+		log.Printf("rejected upload %q: %s", filename, threat)
+		recordAudit(r, "reject_upload", "file", filepath.Base(filename), map[string]string{"threat": threat, "scanner": fileScanner.Name(), "path": r.URL.Path})
+		writeError(w, http.StatusUnprocessableEntity, ErrCodeInfectedFile, fmt.Sprintf("file rejected: %s detected", threat))
+		return false
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "could not read file")
+		return false
+	}
+	return true
+}
+
+// writeDownloadURL answers with a presigned URL for the stored file at key,
+// or 501 when the document store cannot presign.
+func writeDownloadURL(w http.ResponseWriter, key, filename string) {
+	presigner, ok := documentStore.(storage.Presigner)
+	if !ok {
+		http.Error(w, "the storage backend cannot presign URLs; download the file directly", http.StatusNotImplemented)
+		return
+	}
+	expiresAt := time.Now().UTC().Add(downloadURLTTL)
+	link, err := presigner.PresignGet(key, filename, downloadURLTTL)
+	if err != nil {
+		log.Printf("failed to presign %s: %v", key, err)
+		http.Error(w, "could not create download URL", http.StatusInternalServerError)
+		return
+	}
+	if err := writeJSON(w, http.StatusOK, DownloadURL{URL: link, ExpiresAt: expiresAt}); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// storageKey builds a unique storage key for a file of the owner entity with
+// the given ID. The client's filename only contributes its extension, so it
+// cannot influence the storage path.
+func storageKey(owner string, id int, filename string) (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	ext := strings.ToLower(filepath.Ext(filepath.Base(filename)))
+	if len(ext) > 10 {
+		ext = ""
+	}
+	return fmt.Sprintf("%s/%d/%s%s", owner, id, hex.EncodeToString(b), ext), nil
+}
@@ -1,26 +1,166 @@
 package handlers
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+	"github.com/rogerio-castellano/inventory-tracker/internal/security"
+)
 
 type ProductRequest struct {
-	Id        int     `json:"id,omitempty"`
-	Name      string  `json:"name"`
-	Price     float64 `json:"price"`
-	Quantity  int     `json:"quantity"`
-	Threshold int     `json:"threshold"`
+	Id          int     `json:"id,omitempty"`
+	Name        string  `json:"name"`
+	Price       float64 `json:"price"`
+	Cost        float64 `json:"cost,omitempty"`
+	Quantity    int     `json:"quantity"`
+	Threshold   int     `json:"threshold"`
+	Category    string  `json:"category,omitempty"`
+	SKU         string  `json:"sku,omitempty"`
+	Barcode     string  `json:"barcode,omitempty"`
+	Supplier    string  `json:"supplier,omitempty"`
+	MaxQuantity int     `json:"max_quantity,omitempty"`
+	Status      string  `json:"status,omitempty"`
+	ExternalID  string  `json:"external_id,omitempty"` // optional UUID chosen by the caller
+	TaxClassID  *int    `json:"tax_class_id,omitempty"`
+	Currency    string  `json:"currency,omitempty" example:"EUR"` // ISO 4217; empty means the base currency
+
+	Description  string            `json:"description,omitempty"`
+	Brand        string            `json:"brand,omitempty"`
+	Manufacturer string            `json:"manufacturer,omitempty"`
+	Weight       float64           `json:"weight,omitempty" example:"0.25"` // kilograms per unit
+	Dimensions   models.Dimensions `json:"dimensions,omitzero"`             // centimetres per unit
 }
 
 type ProductResponse struct {
-	Id        int     `json:"id"`
-	Name      string  `json:"name"`
-	Price     float64 `json:"price"`
-	Quantity  int     `json:"quantity"`
-	Threshold int     `json:"threshold"`
-	LowStock  bool    `json:"low_stock,omitempty"`
+	Id          int      `json:"id"`
+	Name        string   `json:"name"`
+	Price       float64  `json:"price"`
+	Cost        float64  `json:"cost,omitempty"`
+	Quantity    int      `json:"quantity"`
+	Threshold   int      `json:"threshold"`
+	LowStock    bool     `json:"low_stock,omitempty"`
+	Category    string   `json:"category,omitempty"`
+	SKU         string   `json:"sku,omitempty"`
+	Barcode     string   `json:"barcode,omitempty"`
+	Supplier    string   `json:"supplier,omitempty"`
+	MaxQuantity int      `json:"max_quantity,omitempty"`
+	Status      string   `json:"status,omitempty"`
+	ExternalID  string   `json:"external_id,omitempty"`
+	TaxClassID  *int     `json:"tax_class_id,omitempty"`
+	TaxRate     *float64 `json:"tax_rate,omitempty"`
+	Currency    string   `json:"currency,omitempty"`
+
+	Description  string            `json:"description,omitempty"`
+	Brand        string            `json:"brand,omitempty"`
+	Manufacturer string            `json:"manufacturer,omitempty"`
+	Weight       float64           `json:"weight,omitempty"`
+	Dimensions   models.Dimensions `json:"dimensions,omitzero"`
+	// PriceIncludesTax is set when tax=inclusive was asked for: Price is
+	// then the net price plus the tax rate.
+	PriceIncludesTax bool `json:"price_includes_tax,omitempty"`
+	// PriceList names the list Price comes from when price_list was asked
+	// for and the product has a price there.
+	PriceList string `json:"price_list,omitempty"`
+	// Promotion is set when an active promotion lowered Price.
+	Promotion *AppliedPromotion `json:"promotion,omitempty"`
+	// Seq counts the writes to the product; see GET /products/{id}/changes.
+	Seq int `json:"seq"`
+	// NeedsReview flags a placeholder created by a movement import for an unknown SKU.
+	NeedsReview bool `json:"needs_review,omitempty"`
+	// Highlights is set on search results: the matching fields, HTML-escaped,
+	// with the matched words in <mark>.
+	Highlights map[string]string `json:"highlights,omitempty"`
+	// CreatedAt and UpdatedAt are RFC3339 in UTC; UpdatedAt changes on
+	// every write, stock adjustments included.
+	CreatedAt string `json:"created_at,omitempty"`
+	UpdatedAt string `json:"updated_at,omitempty"`
+	// Warehouses splits Quantity by location; only GET /products/{id} fills
+	// it in. Units adjusted without a warehouse are in none of them.
+	Warehouses []models.WarehouseStock `json:"warehouses,omitempty"`
+	// Reserved is the part of Quantity held by reservations and Available
+	// the rest. Only GET /products/{id}, GET /products/filter and POST
+	// /products/{id}/adjust fill them in.
+	Reserved  *int `json:"reserved,omitempty"`
+	Available *int `json:"available,omitempty"`
+	// Suppliers is filled in when include=suppliers was asked for.
+	Suppliers []models.ProductSupplier `json:"suppliers,omitempty"`
+	// Stats is filled in by GET /products/{id} when include=stats was asked
+	// for.
+	Stats *ProductStats `json:"stats,omitempty"`
+	// Health is filled in when include=health was asked for.
+	Health *repo.ProductHealth `json:"health,omitempty"`
+	// Warnings is set by POST /products/{id}/adjust when the adjustment was
+	// larger than the product's adjustment limits but went through, and by
+	// product writes whose price broke the price rules but was accepted.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// ProductStats summarizes a product's activity for the dashboard.
+type ProductStats struct {
+	repo.MovementStats
+	// UnitsOut30d is how many units left stock in the last 30 days and
+	// Velocity30d the same per day.
+	UnitsOut30d   int     `json:"units_out_30d"`
+	Velocity30d   float64 `json:"velocity_30d"`
+	ReservedUnits int     `json:"reserved_units"`
+	Reservations  int     `json:"reservations"`
+}
+
+// AppliedPromotion flags a promotional price in a product response.
+type AppliedPromotion struct {
+	ID           int       `json:"id"`
+	Name         string    `json:"name"`
+	RegularPrice float64   `json:"regular_price"` // what Price would be without the promotion
+	EndsAt       time.Time `json:"ends_at"`
+}
+
+func newProductResponse(p models.Product) ProductResponse {
+	return ProductResponse{
+		Id:          p.ID,
+		Name:        p.Name,
+		Price:       p.Price,
+		Cost:        p.Cost,
+		Quantity:    p.Quantity,
+		Threshold:   p.Threshold,
+		LowStock:    p.Quantity < p.Threshold,
+		Category:    p.Category,
+		SKU:         p.SKU,
+		Barcode:     p.Barcode,
+		Supplier:    p.Supplier,
+		MaxQuantity: p.MaxQuantity,
+		Status:      p.Status,
+		ExternalID:  p.ExternalID,
+		TaxClassID:  p.TaxClassID,
+		Currency:    p.Currency,
+		Seq:         p.Seq,
+		NeedsReview: p.NeedsReview,
+		CreatedAt:   utcTimestamp(p.CreatedAt),
+		UpdatedAt:   utcTimestamp(p.UpdatedAt),
+
+		Description:  p.Description,
+		Brand:        p.Brand,
+		Manufacturer: p.Manufacturer,
+		Weight:       p.Weight,
+		Dimensions:   p.Dimensions,
+	}
+}
+
+// utcTimestamp rewrites a stored RFC3339 timestamp in UTC to the second,
+// the precision updated_since filters take. Anything else passes through.
+func utcTimestamp(s string) string {
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return s
+	}
+	return t.UTC().Format(time.RFC3339)
 }
 
 type Meta struct {
-	TotalCount int `json:"total_count"`
+	// TotalCount is -1 when the caller opted out of counting.
+	TotalCount int   `json:"total_count"`
+	HasMore    *bool `json:"has_more,omitempty"`
 }
 
 type ProductsSearchResult struct {
@@ -29,14 +169,35 @@ type ProductsSearchResult struct {
 }
 
 type QuantityAdjustmentRequest struct {
-	Delta int `json:"delta"` // can be positive or negative
+	Delta      int    `json:"delta"`                 // can be positive or negative
+	OccurredAt string `json:"occurred_at,omitempty"` // RFC3339; backdates the movement when set
+	ExternalID string `json:"external_id,omitempty"` // optional UUID identifying the movement
+	// WarehouseID also changes that warehouse's stock of the product.
+	WarehouseID *int `json:"warehouse_id,omitempty"`
+	// Reason is sale, return, damage, recount or transfer.
+	Reason string `json:"reason,omitempty"`
+	Note   string `json:"note,omitempty"` // free text, at most 500 characters
+}
+
+type BatchAdjustmentResult struct {
+	Product  ProductResponse `json:"product"`
+	Applied  int             `json:"applied"`   // movements logged
+	NetDelta int             `json:"net_delta"` // sum of their deltas
+	// Warnings lists the adjustments larger than the product's limits that
+	// went through.
+	Warnings []string `json:"warnings,omitempty"`
 }
 
 type MovementResponse struct {
-	ID        int    `json:"id"`
-	ProductID int    `json:"product_id"`
-	Delta     int    `json:"delta"`
-	CreatedAt string `json:"created_at"`
+	ID          int    `json:"id"`
+	ProductID   int    `json:"product_id"`
+	Delta       int    `json:"delta"`
+	CreatedAt   string `json:"created_at"`
+	WorkOrderID *int   `json:"work_order_id,omitempty"`
+	ExternalID  string `json:"external_id,omitempty"`
+	WarehouseID *int   `json:"warehouse_id,omitempty"`
+	Reason      string `json:"reason,omitempty"`
+	Note        string `json:"note,omitempty"`
 }
 
 type MovementsSearchResult struct {
@@ -57,11 +218,62 @@ type RegisterResult struct {
 type ImportProductsResult struct {
 	ImportedProductsCount int                      `json:"imported"`
 	Errors                []ProductValidationError `json:"errors"`
+	// Warnings lists the imported rows whose price broke the price rules.
+	Warnings []ProductValidationError `json:"warnings,omitempty"`
+}
+
+// ReviewQueueEntry is a product flag with enough of its product to act on.
+type ReviewQueueEntry struct {
+	models.ProductFlag
+	ProductName string `json:"product_name"`
+	SKU         string `json:"sku,omitempty"`
+}
+
+type ReviewQueueResult struct {
+	Data []ReviewQueueEntry `json:"data"`
+	Meta Meta               `json:"meta"`
+}
+
+type CloseFlagRequest struct {
+	Note string `json:"note,omitempty"` // at most 500 characters
+}
+
+type ImportMovementsResult struct {
+	Imported int `json:"imported"`
+	// Placeholders lists the IDs of the products created for unknown SKUs.
+	Placeholders []int                    `json:"placeholders,omitempty"`
+	Errors       []ProductValidationError `json:"errors"`
+}
+
+type ExpiringDocument struct {
+	models.ProductDocument
+	ProductName string `json:"product_name"`
+	Expired     bool   `json:"expired"`
+	DaysLeft    int    `json:"days_left"`
+}
+
+type MergeProductsRequest struct {
+	SourceID int `json:"source_id"`
+	TargetID int `json:"target_id"`
+}
+
+type BulkInsertResult struct {
+	Inserted  int   `json:"inserted"`
+	ElapsedMs int64 `json:"elapsed_ms"`
 }
 
 type CredentialsRequest struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
+	// DeviceName labels the session in session listings, e.g. "Warehouse Tablet 3".
+	DeviceName string `json:"device_name,omitempty"`
+}
+
+type ChangePasswordRequest struct {
+	Username        string `json:"username"`
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+	DeviceName      string `json:"device_name,omitempty"`
 }
 
 type RegisterAsAdminRequest struct {
@@ -71,8 +283,28 @@ type RegisterAsAdminRequest struct {
 }
 
 type MeResponse struct {
-	Username string `json:"username"`
-	Role     string `json:"role"`
+	Username    string        `json:"username"`
+	Role        string        `json:"role"`
+	Tenant      string        `json:"tenant"`
+	Timezone    string        `json:"timezone,omitempty"` // used by exports when no tz is given
+	Permissions []string      `json:"permissions"`
+	Quota       QuotaUsage    `json:"quota"`
+	RateLimit   RateLimitTier `json:"rate_limit"`
+}
+
+type TimezoneRequest struct {
+	Timezone string `json:"timezone" example:"Europe/Madrid"` // IANA name; empty resets to UTC
+}
+
+type QuotaUsage struct {
+	ProductsUsed    int  `json:"products_used"`
+	ProductsAllowed *int `json:"products_allowed"` // null when unlimited
+}
+
+type RateLimitTier struct {
+	Tier          string `json:"tier"`
+	MaxRequests   int    `json:"max_requests"`
+	WindowSeconds int    `json:"window_seconds"`
 }
 
 type RefreshRequest struct {
@@ -88,4 +320,883 @@ type RefreshTokenInfo struct {
 	ExpiresAt  time.Time `json:"expires_at"`
 	IPAddress  string    `json:"ip_address"`
 	UserAgent  string    `json:"user_agent"`
+	DeviceName string    `json:"device_name,omitempty"`
+	// Current marks the caller's own session in GET /me/sessions.
+	Current bool `json:"current,omitempty"`
+}
+
+// ActiveSession is a user who made a request recently, with their open
+// refresh-token sessions.
+type ActiveSession struct {
+	Username      string             `json:"username"`
+	LastSeenAt    time.Time          `json:"last_seen_at"`
+	LastRequest   string             `json:"last_request"`
+	IPAddress     string             `json:"ip_address"`
+	UserAgent     string             `json:"user_agent"`
+	RequestsToday int64              `json:"requests_today"`
+	Sessions      []RefreshTokenInfo `json:"sessions"`
+}
+
+type UsageTotals struct {
+	Requests int64 `json:"requests"`
+	BytesIn  int64 `json:"bytes_in"`
+	BytesOut int64 `json:"bytes_out"`
+}
+
+type UsageReport struct {
+	Month  string               `json:"month"`
+	User   string               `json:"user,omitempty"`
+	Totals UsageTotals          `json:"totals"`
+	ByUser []models.UsageRecord `json:"by_user"`
+	Days   []models.UsageRecord `json:"days"`
+}
+
+type AuditLogPage struct {
+	Entries []models.AuditEntry `json:"entries"`
+	Limit   int                 `json:"limit"`
+	Offset  int                 `json:"offset"`
+	HasMore bool                `json:"has_more"`
+}
+
+type AuditVerificationResult struct {
+	Valid          bool   `json:"valid"`
+	Checked        int    `json:"checked"`
+	FirstInvalidID int    `json:"first_invalid_id,omitempty"`
+	Reason         string `json:"reason,omitempty"`
+}
+
+type PersonalProfile struct {
+	ID        int       `json:"id"`
+	Username  string    `json:"username"`
+	Email     string    `json:"email,omitempty"`
+	Role      string    `json:"role"`
+	Timezone  string    `json:"timezone,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type PersonalDataExport struct {
+	Profile      PersonalProfile      `json:"profile"`
+	Sessions     []RefreshTokenInfo   `json:"sessions"`
+	AuditEntries []models.AuditEntry  `json:"audit_entries"`
+	Usage        []models.UsageRecord `json:"usage"`
+	Logins       []models.LoginEvent  `json:"logins"`
+}
+
+type LoginHistoryPage struct {
+	Logins  []models.LoginEvent `json:"logins"`
+	Limit   int                 `json:"limit"`
+	Offset  int                 `json:"offset"`
+	HasMore bool                `json:"has_more"`
+}
+
+type ReconciliationEntry struct {
+	ProductID        int    `json:"product_id"`
+	ProductName      string `json:"product_name"`
+	Quantity         int    `json:"quantity"`
+	BaselineQuantity int    `json:"baseline_quantity"`
+	MovementSum      int    `json:"movement_sum"`
+	ExpectedQuantity int    `json:"expected_quantity"`
+	Difference       int    `json:"difference"`
+	Corrected        bool   `json:"corrected,omitempty"`
+}
+
+type ReconciliationReport struct {
+	CheckedProducts int                   `json:"checked_products"`
+	Discrepancies   []ReconciliationEntry `json:"discrepancies"`
+}
+
+type LotRequest struct {
+	LotNumber string `json:"lot_number"`
+	Quantity  int    `json:"quantity"`
+	ExpiresAt string `json:"expires_at"`
+}
+
+type LotQuantityRequest struct {
+	Quantity int `json:"quantity"`
+}
+
+type ExpiringLot struct {
+	models.Lot
+	ProductName string `json:"product_name"`
+	Expired     bool   `json:"expired"`
+	DaysLeft    int    `json:"days_left"`
+}
+
+type WriteOffRequest struct {
+	Quantity   int      `json:"quantity"`
+	Reason     string   `json:"reason"`
+	UnitCost   *float64 `json:"unit_cost,omitempty"` // defaults to the product price
+	Note       string   `json:"note,omitempty"`
+	OccurredAt string   `json:"occurred_at,omitempty"` // RFC3339; backdates the write-off when set
+}
+
+type WasteGroup struct {
+	Name            string  `json:"name"`
+	Quantity        int     `json:"quantity"`
+	WrittenOffValue float64 `json:"written_off_value"`
+}
+
+type WasteReport struct {
+	Since           string       `json:"since"`
+	Quantity        int          `json:"quantity"`
+	WrittenOffValue float64      `json:"written_off_value"`
+	ByReason        []WasteGroup `json:"by_reason"`
+	ByCategory      []WasteGroup `json:"by_category"`
+}
+
+type ReturnRequest struct {
+	MovementID int    `json:"movement_id"`
+	Quantity   int    `json:"quantity"`
+	Reason     string `json:"reason,omitempty"`
+	OrderRef   string `json:"order_ref,omitempty"`
+}
+
+type InspectReturnRequest struct {
+	Status string `json:"status"` // restock, refurbish or scrap
+	Note   string `json:"note,omitempty"`
+}
+
+type WorkOrderRequest struct {
+	ProductID  int                         `json:"product_id"`
+	Quantity   int                         `json:"quantity"`
+	Components []models.WorkOrderComponent `json:"components"`
+	Note       string                      `json:"note,omitempty"`
+}
+
+// ShortageResponse is the 409 body when a work order cannot be completed.
+type ShortageResponse struct {
+	ErrorResponse
+	Shortages []ShortageItem `json:"shortages"`
+}
+
+// ShortageItem is a short component and, when one has enough stock, a
+// substitute that could replace it.
+type ShortageItem struct {
+	repo.Shortage
+	Substitute *SuggestedSubstitute `json:"substitute,omitempty"`
+}
+
+type SuggestedSubstitute struct {
+	ProductID int    `json:"product_id"`
+	Name      string `json:"name"`
+	Quantity  int    `json:"quantity"`
+}
+
+type SubstitutesRequest struct {
+	ProductIDs []int `json:"product_ids"` // preferred first
+}
+
+type ScanRequest struct {
+	Barcode   string `json:"barcode"`
+	Delta     int    `json:"delta"`
+	Warehouse string `json:"warehouse,omitempty"`
+}
+
+// ScanResponse is deliberately small: scanners on weak Wi-Fi only need to
+// confirm which product moved and what is left.
+type ScanResponse struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	Quantity int    `json:"quantity"`
+	LowStock bool   `json:"low_stock,omitempty"`
+	// Bins says where the product sits, in the scanned warehouse when one
+	// was given.
+	Bins []BinLocation `json:"bins,omitempty"`
+	// Warnings is set when the adjustment was larger than the product's
+	// limits but went through.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+type BinLocation struct {
+	Warehouse string `json:"warehouse,omitempty"`
+	Bin       string `json:"bin"`
+	Quantity  int    `json:"quantity"`
+}
+
+type BinRequest struct {
+	Warehouse string `json:"warehouse,omitempty"`
+	Bin       string `json:"bin"` // e.g. "aisle 3, shelf B"
+	Quantity  int    `json:"quantity"`
+}
+
+type BinMoveRequest struct {
+	Warehouse string `json:"warehouse,omitempty"`
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Quantity  int    `json:"quantity"`
+}
+
+// BinsResponse lists where a product's stock sits. Unassigned is the stock
+// in no bin; it goes negative when stock left the warehouse without being
+// taken out of its bin, which calls for a recount.
+type BinsResponse struct {
+	ProductID  int               `json:"product_id"`
+	Quantity   int               `json:"quantity"`
+	Unassigned int               `json:"unassigned"`
+	Bins       []models.BinStock `json:"bins"`
+	// Warnings lists capacities the change overfilled in warn mode.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+type ASNRequest struct {
+	Reference     string           `json:"reference"`
+	Supplier      string           `json:"supplier"`
+	SupplierEmail string           `json:"supplier_email,omitempty"` // receives the discrepancy report
+	ExpectedAt    string           `json:"expected_at,omitempty"`    // RFC 3339
+	Lines         []ASNLineRequest `json:"lines"`
+}
+
+type ASNLineRequest struct {
+	ProductID int `json:"product_id"`
+	Quantity  int `json:"quantity"`
+}
+
+type ReceiveASNRequest struct {
+	Items []ReceivedItem `json:"items"`
+}
+
+// ReceivedItem is one scan, or one counted product, of a received shipment.
+// Exactly one of Barcode and ProductID names the product.
+type ReceivedItem struct {
+	Barcode   string `json:"barcode,omitempty"`
+	ProductID int    `json:"product_id,omitempty"`
+	Quantity  int    `json:"quantity,omitempty"` // defaults to 1, a single scan
+}
+
+// ASNDiscrepancy is an ASN line that arrived short or over. Difference is
+// received minus expected.
+type ASNDiscrepancy struct {
+	ProductID  int    `json:"product_id"`
+	Name       string `json:"name"`
+	Expected   int    `json:"expected"`
+	Received   int    `json:"received"`
+	Difference int    `json:"difference"`
+	Kind       string `json:"kind"` // short, over or unexpected
+}
+
+// ASNReport is a received ASN with its discrepancies. ReportSent says
+// whether they were emailed to the supplier contact.
+type ASNReport struct {
+	ASN           models.ASN       `json:"asn"`
+	Discrepancies []ASNDiscrepancy `json:"discrepancies"`
+	ReportSent    bool             `json:"report_sent"`
+	ReportError   string           `json:"report_error,omitempty"`
+}
+
+// LandedCostRequest attaches a freight, duty or similar charge to a received
+// ASN. Allocating by weight needs the unit weight of every received product.
+type LandedCostRequest struct {
+	Description string              `json:"description"`
+	TotalCost   float64             `json:"total_cost"`
+	Method      string              `json:"method"`            // value or weight
+	Weights     []UnitWeightRequest `json:"weights,omitempty"` // overrides the products' own weights
+}
+
+type UnitWeightRequest struct {
+	ProductID  int     `json:"product_id"`
+	UnitWeight float64 `json:"unit_weight"`
+}
+
+type ConsignmentRequest struct {
+	Supplier string  `json:"supplier,omitempty"`  // defaults to the product's supplier
+	Quantity int     `json:"quantity"`            // zero clears the flag
+	UnitCost float64 `json:"unit_cost,omitempty"` // defaults to the product's cost
+}
+
+type ConsumeConsignmentRequest struct {
+	Quantity int    `json:"quantity"`
+	Note     string `json:"note,omitempty"`
+}
+
+type ConsignmentSettlementReport struct {
+	From      string                    `json:"from"`
+	To        string                    `json:"to"` // exclusive
+	Suppliers []repo.SupplierSettlement `json:"suppliers"`
+	TotalCost float64                   `json:"total_cost"`
+}
+
+type CapacityRequest struct {
+	Warehouse string `json:"warehouse,omitempty"`
+	Bin       string `json:"bin,omitempty"` // empty for the whole warehouse
+	MaxUnits  int    `json:"max_units"`
+	Mode      string `json:"mode,omitempty"` // warn (default) or reject
+}
+
+type AdjustmentLimitRequest struct {
+	ProductID int `json:"product_id,omitempty"` // empty for every product
+	WarnDelta int `json:"warn_delta,omitempty"`
+	MaxDelta  int `json:"max_delta,omitempty"`
+}
+
+// CapacityUsage is one line of the utilization report.
+type CapacityUsage struct {
+	models.Capacity
+	Used        int     `json:"used"`
+	Utilization float64 `json:"utilization"` // percent of MaxUnits
+	Over        bool    `json:"over"`
+}
+
+type SyncChangesResponse struct {
+	Products          []ProductResponse  `json:"products"`
+	Movements         []MovementResponse `json:"movements"`
+	DeletedProductIDs []int              `json:"deleted_product_ids"`
+	Cursor            string             `json:"cursor"`
+	HasMore           bool               `json:"has_more"`
+}
+
+// ProductChangeResponse is one entry of the product change feed. Product
+// is omitted for deletions.
+type ProductChangeResponse struct {
+	Action    string           `json:"action"` // created, updated or deleted
+	ProductID int              `json:"product_id"`
+	At        string           `json:"at"`
+	Product   *ProductResponse `json:"product,omitempty"`
+}
+
+type ProductChangesResponse struct {
+	Changes []ProductChangeResponse `json:"changes"`
+	Cursor  string                  `json:"cursor"`
+	HasMore bool                    `json:"has_more"`
+}
+
+type SyncOperationRequest struct {
+	ClientID   string `json:"client_id"` // UUID generated by the client when queuing the operation
+	ProductID  int    `json:"product_id"`
+	Delta      int    `json:"delta"`
+	OccurredAt string `json:"occurred_at,omitempty"` // RFC3339; when the adjustment happened on the device
+}
+
+type SyncBatchRequest struct {
+	Operations []SyncOperationRequest `json:"operations"`
+}
+
+type SyncResult struct {
+	ClientID string `json:"client_id"`
+	Status   string `json:"status"`
+	Reason   string `json:"reason,omitempty"`
+	// Duplicate marks operations already received in an earlier batch; Status
+	// is then the outcome recorded the first time.
+	Duplicate bool `json:"duplicate,omitempty"`
+	// Quantity is the server stock after the operation, so the client can
+	// reconcile its local copy whether or not the operation applied.
+	Quantity *int `json:"quantity,omitempty"`
+}
+
+type SyncBatchResponse struct {
+	Results []SyncResult `json:"results"`
+}
+
+type MappingRequest struct {
+	ProductID    int    `json:"product_id"`
+	System       string `json:"system"`        // e.g. sap or shopify; stored lowercase
+	ExternalCode string `json:"external_code"` // the product's identifier in that system
+}
+
+// MappingResolution is a product together with its codes in one external system.
+type MappingResolution struct {
+	Product  ProductResponse         `json:"product"`
+	Mappings []models.ProductMapping `json:"mappings"`
+}
+
+type BulkUpsertResult struct {
+	Upserted int `json:"upserted"`
+}
+
+type ServiceTokenRequest struct {
+	Name      string   `json:"name"`                 // e.g. the kiosk or device it is issued to
+	Scopes    []string `json:"scopes"`               // e.g. ["POST /scan", "GET /products/*"]
+	ExpiresIn string   `json:"expires_in,omitempty"` // e.g. 365d; never expires when empty
+}
+
+type ServiceTokenInfo struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	CreatedBy string     `json:"created_by"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at"` // null for tokens that never expire
+}
+
+type ServiceTokenResponse struct {
+	ServiceTokenInfo
+	Token string `json:"token"` // shown once; only its ID is kept
+}
+
+type PartnerRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"` // endpoints the partner may call, e.g. ["POST /products/*/adjust"]
+}
+
+type PartnerInfo struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Scopes    []string  `json:"scopes"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type PartnerSecretResponse struct {
+	PartnerInfo
+	Secret string `json:"secret"` // shown only when created or rotated
+}
+
+type SnapshotRequest struct {
+	Label string `json:"label"`
+}
+
+// SnapshotDiff compares two snapshots. Only products whose quantity or price
+// differ are listed.
+type SnapshotDiff struct {
+	From            models.Snapshot  `json:"from"`
+	To              models.Snapshot  `json:"to"`
+	Added           int              `json:"added"`
+	Removed         int              `json:"removed"`
+	Changed         int              `json:"changed"`
+	QuantityDelta   int              `json:"quantity_delta"`
+	TotalValueDelta float64          `json:"total_value_delta"`
+	Changes         []SnapshotChange `json:"changes"`
+}
+
+type SnapshotChange struct {
+	ProductID      int     `json:"product_id"`
+	Name           string  `json:"name"`
+	Status         string  `json:"status"` // added, removed or changed
+	QuantityBefore int     `json:"quantity_before"`
+	QuantityAfter  int     `json:"quantity_after"`
+	QuantityDelta  int     `json:"quantity_delta"`
+	ValueBefore    float64 `json:"value_before"`
+	ValueAfter     float64 `json:"value_after"`
+	ValueDelta     float64 `json:"value_delta"`
+}
+
+// StockHistory is a series of stock levels from the daily snapshots, for the
+// whole inventory or, when ProductID is set, a single product.
+type StockHistory struct {
+	ProductID *int                `json:"product_id,omitempty"`
+	Points    []StockHistoryPoint `json:"points"`
+}
+
+type StockHistoryPoint struct {
+	At         time.Time `json:"at"`
+	Quantity   int       `json:"quantity"`
+	TotalValue float64   `json:"total_value"`
+}
+
+// DuplicateCandidate is a pair of products that are probably the same item.
+// Score is between 0 and 1; Reasons lists the signals that matched.
+type DuplicateCandidate struct {
+	Products       [2]ProductResponse   `json:"products"`
+	Score          float64              `json:"score"`
+	Reasons        []string             `json:"reasons"` // barcode, sku, name, price_category
+	NameSimilarity float64              `json:"name_similarity"`
+	SuggestedMerge MergeProductsRequest `json:"suggested_merge"` // body for POST /admin/products/merge
+}
+
+// RepricingRequest previews a pricing rule over the filtered products, or,
+// when PreviewID is set, applies a preview made earlier. Filter and Rule are
+// ignored when applying.
+type RepricingRequest struct {
+	PreviewID string          `json:"preview_id,omitempty"`
+	Filter    RepricingFilter `json:"filter"`
+	Rule      PricingRule     `json:"rule"`
+}
+
+// RepricingFilter selects products; empty fields match everything.
+type RepricingFilter struct {
+	ProductIDs []int    `json:"product_ids,omitempty"`
+	Name       string   `json:"name,omitempty"` // substring, case-insensitive
+	Category   string   `json:"category,omitempty"`
+	Supplier   string   `json:"supplier,omitempty"`
+	MinPrice   *float64 `json:"min_price,omitempty"`
+	MaxPrice   *float64 `json:"max_price,omitempty"`
+}
+
+type PricingRule struct {
+	// Type is percentage, which changes the current price by Percent (e.g.
+	// -10), or margin, which prices at a gross margin of Percent over cost
+	// (e.g. 40 sells a 6.00 cost at 10.00).
+	Type     string         `json:"type"`
+	Percent  float64        `json:"percent"`
+	Rounding *PriceRounding `json:"rounding,omitempty"`
+}
+
+type PriceRounding struct {
+	Step      float64  `json:"step,omitempty"`      // round to a multiple of step, e.g. 0.05
+	Ending    *float64 `json:"ending,omitempty"`    // end prices in this fraction instead, e.g. 0.99
+	Direction string   `json:"direction,omitempty"` // nearest (default), up or down
+}
+
+type RepricingPreview struct {
+	PreviewID string            `json:"preview_id,omitempty"` // send back to apply; empty when nothing would change
+	ExpiresAt *time.Time        `json:"expires_at,omitempty"`
+	Matched   int               `json:"matched"`
+	Unchanged int               `json:"unchanged"`
+	Changes   []RepricingChange `json:"changes"`
+	Skipped   []RepricingSkip   `json:"skipped"`
+}
+
+type RepricingChange struct {
+	ProductID   int     `json:"product_id"`
+	Name        string  `json:"name"`
+	Cost        float64 `json:"cost"`
+	PriceBefore float64 `json:"price_before"`
+	PriceAfter  float64 `json:"price_after"`
+}
+
+type RepricingSkip struct {
+	ProductID int    `json:"product_id"`
+	Name      string `json:"name"`
+	Reason    string `json:"reason"` // no_cost or not_positive
+}
+
+type RepricingResult struct {
+	PreviewID string `json:"preview_id"`
+	Updated   int    `json:"updated"`
+}
+
+type PromotionRequest struct {
+	Name string `json:"name"`
+	// Exactly one of ProductID and Category picks what is on promotion.
+	ProductID *int   `json:"product_id,omitempty"`
+	Category  string `json:"category,omitempty"`
+	// Exactly one of Price and PercentOff sets the promotional price.
+	Price      float64 `json:"price,omitempty"`
+	PercentOff float64 `json:"percent_off,omitempty"`
+	StartsAt   string  `json:"starts_at"` // RFC 3339
+	EndsAt     string  `json:"ends_at"`   // RFC 3339
+}
+
+type PriceListRequest struct {
+	Name        string `json:"name"` // e.g. wholesale
+	Description string `json:"description,omitempty"`
+}
+
+// PriceListDetail is a price list with every price it holds.
+type PriceListDetail struct {
+	models.PriceList
+	Prices []models.PriceListItem `json:"prices"`
+}
+
+type TaxClassRequest struct {
+	Name string  `json:"name"`
+	Rate float64 `json:"rate"` // percent, e.g. 21 for 21%
+}
+
+type ValidationPolicyRequest struct {
+	RequiredFields []string `json:"required_fields" yaml:"required_fields"` // e.g. ["sku", "category"]
+}
+
+type PriceRulesRequest struct {
+	MaxPrice         float64 `json:"max_price,omitempty"`
+	MaxChangePercent float64 `json:"max_change_percent,omitempty"`
+	Currency         string  `json:"currency,omitempty"` // ISO 4217, e.g. JPY
+	Mode             string  `json:"mode,omitempty"`     // reject (default) or warn
+}
+
+// ConfigManifest is the desired configuration sent to POST /admin/apply.
+// Sections left out are not touched; a section that is present replaces the
+// live one, so roles, limits or webhooks missing from it are removed.
+type ConfigManifest struct {
+	Roles            map[string]ManifestRole            `json:"roles,omitempty" yaml:"roles"`
+	RateLimits       map[string]models.RateLimitSetting `json:"rate_limits,omitempty" yaml:"rate_limits"`
+	ValidationPolicy *ValidationPolicyRequest           `json:"validation_policy,omitempty" yaml:"validation_policy"`
+	AlertRules       []any                              `json:"alert_rules,omitempty" yaml:"alert_rules"` // not supported; must be empty
+	Webhooks         []WebhookRequest                   `json:"webhooks,omitempty" yaml:"webhooks"`       // matched to the subscriptions by URL
+}
+
+type ManifestRole struct {
+	Permissions []string `json:"permissions" yaml:"permissions"` // e.g. ["pricing:read"]
+}
+
+type ApplyResult struct {
+	DryRun  bool           `json:"dry_run"`
+	Changes []ConfigChange `json:"changes"`
+	// WebhookSecrets holds the signing secret of each webhook added, by
+	// URL; it is not shown again.
+	WebhookSecrets map[string]string `json:"webhook_secrets,omitempty"`
+}
+
+type ConfigChange struct {
+	Section string `json:"section"` // roles, rate_limits, validation_policy or webhooks
+	Key     string `json:"key"`     // role name, required_fields or webhook URL
+	Action  string `json:"action"`  // add, update or remove
+	Before  any    `json:"before,omitempty"`
+	After   any    `json:"after,omitempty"`
+}
+
+type SlackCommandResponse struct {
+	ResponseType string `json:"response_type"` // ephemeral: only the caller sees it
+	Text         string `json:"text"`
+}
+
+type ReadinessResponse struct {
+	Status      string            `json:"status"` // ready, degraded or unavailable
+	Redis       string            `json:"redis"`  // ok or unreachable
+	RateLimiter RateLimiterStatus `json:"rate_limiter"`
+}
+
+type RateLimiterStatus struct {
+	FailMode   string   `json:"fail_mode"`   // open or closed
+	Degraded   bool     `json:"degraded"`    // some routes are limited without Redis
+	OpenRoutes []string `json:"open_routes"` // routes whose circuit breaker is open
+}
+
+type ActivityFeed struct {
+	Items      []ActivityItem `json:"items"`
+	NextCursor string         `json:"next_cursor,omitempty"` // empty on the last page
+}
+
+type ActivityItem struct {
+	ID       string          `json:"id"`   // e.g. movement:12 or audit:5
+	Type     string          `json:"type"` // movement, revision, import, alert or event
+	At       string          `json:"at"`
+	Actor    string          `json:"actor,omitempty"`
+	Action   string          `json:"action,omitempty"`   // audited action, or low_stock for alerts
+	Delta    *int            `json:"delta,omitempty"`    // movements only
+	Quantity *int            `json:"quantity,omitempty"` // stock after a movement or alert
+	Changes  []FieldChange   `json:"changes,omitempty"`
+	Details  json.RawMessage `json:"details,omitempty"` // other events only
+
+	at time.Time
+}
+
+func (i ActivityItem) position() activityCursor {
+	return activityCursor{At: i.at, ID: i.ID}
+}
+
+type FieldChange struct {
+	Field string `json:"field"`
+	From  any    `json:"from"`
+	To    any    `json:"to"`
+}
+
+type UserAction struct {
+	ID       int             `json:"id"`
+	At       string          `json:"at"`
+	Action   string          `json:"action"`
+	Entity   string          `json:"entity"`
+	EntityID string          `json:"entity_id,omitempty"`
+	Details  json.RawMessage `json:"details,omitempty"`
+}
+
+type SandboxSimulateRequest struct {
+	Kind      string `json:"kind" example:"stock_changes"` // stock_changes, rate_limit or webhook_events
+	Count     int    `json:"count,omitempty" example:"5"`  // changes or events to generate, default 1
+	Seed      int64  `json:"seed,omitempty" example:"42"`  // default 1
+	ProductID int    `json:"product_id,omitempty"`         // stock_changes only; picked at random when zero
+}
+
+type SandboxSimulation struct {
+	Kind         string                 `json:"kind"`
+	Seed         int64                  `json:"seed"`
+	StockChanges []SimulatedStockChange `json:"stock_changes,omitempty"`
+	Events       []security.Event       `json:"events,omitempty"` // as published, before the sinks stamp their IDs
+}
+
+type SimulatedStockChange struct {
+	ProductID int `json:"product_id"`
+	Delta     int `json:"delta"`
+	Quantity  int `json:"quantity"` // after the change
+}
+
+type EventLogPage struct {
+	Events      []security.Event `json:"events"`
+	NextSinceID int              `json:"next_since_id"` // pass as since_id to continue; unchanged when caught up
+}
+
+type ProductChangesPage struct {
+	ProductID int                    `json:"product_id"`
+	Seq       int                    `json:"seq"` // the product's current seq; more changes follow while the last one is below it
+	Changes   []models.ProductChange `json:"changes"`
+}
+
+// ShippingEstimateRequest lists the products and quantities of a shipment.
+type ShippingEstimateRequest struct {
+	Items []ShippingItem `json:"items"`
+	// VolumetricDivisor turns cm³ into billable kg the way the carrier does;
+	// it defaults to 5000.
+	VolumetricDivisor float64 `json:"volumetric_divisor,omitempty"`
+}
+
+type ShippingItem struct {
+	ProductID int `json:"product_id"`
+	Quantity  int `json:"quantity"`
+}
+
+type ShippingEstimateLine struct {
+	ProductID int     `json:"product_id"`
+	Name      string  `json:"name"`
+	Quantity  int     `json:"quantity"`
+	Weight    float64 `json:"weight_kg"`
+	Volume    float64 `json:"volume_m3"`
+}
+
+// ShippingEstimate sums the weight and volume of a shipment. The chargeable
+// weight is the greater of the actual and the volumetric weight. Products
+// without a weight or dimensions add nothing to that total and are listed.
+type ShippingEstimate struct {
+	Items            []ShippingEstimateLine `json:"items"`
+	Weight           float64                `json:"weight_kg"`
+	Volume           float64                `json:"volume_m3"`
+	VolumetricWeight float64                `json:"volumetric_weight_kg"`
+	ChargeableWeight float64                `json:"chargeable_weight_kg"`
+	Unweighed        []int                  `json:"unweighed,omitempty"`
+	Unmeasured       []int                  `json:"unmeasured,omitempty"`
+}
+
+type WarehouseRequest struct {
+	Name    string `json:"name"`
+	Address string `json:"address,omitempty"`
+}
+
+type ReservationRequest struct {
+	Quantity  int    `json:"quantity"`
+	Reference string `json:"reference,omitempty"` // the pending order, e.g. its number
+	// TTLSeconds is how long the units are held, 15 minutes by default.
+	TTLSeconds int `json:"ttl_seconds,omitempty"`
+}
+
+type UserImportResult struct {
+	Created []ImportedUser    `json:"created"`
+	Errors  []UserImportError `json:"errors"`
+}
+
+// ImportedUser is an account created by an import. TemporaryPassword is
+// shown only once; the user must change it before logging in.
+type ImportedUser struct {
+	Row               int    `json:"row"`
+	Username          string `json:"username"`
+	Email             string `json:"email,omitempty"`
+	Role              string `json:"role"`
+	TemporaryPassword string `json:"temporary_password"`
+}
+
+type UserImportError struct {
+	Row         int    `json:"row"`
+	Username    string `json:"username,omitempty"`
+	Description string `json:"description"`
+}
+
+type SupplierRequest struct {
+	Name  string `json:"name"`
+	Email string `json:"email,omitempty"`
+	Phone string `json:"phone,omitempty"`
+}
+
+type ProductSupplierRequest struct {
+	SupplierSKU  string  `json:"supplier_sku,omitempty"`
+	CostPrice    float64 `json:"cost_price"`
+	LeadTimeDays int     `json:"lead_time_days"`
+}
+
+// AccessGrantRequest grants either a category or a warehouse.
+type AccessGrantRequest struct {
+	Category    string `json:"category,omitempty"`
+	WarehouseID *int   `json:"warehouse_id,omitempty"`
+}
+
+type RoleChangeRequest struct {
+	Role string `json:"role"`
+}
+
+// UserAccessResponse is a user's role and whether the account is disabled.
+type UserAccessResponse struct {
+	Username string `json:"username"`
+	Role     string `json:"role"`
+	Disabled bool   `json:"disabled"`
+}
+
+type PasswordRotationRequest struct {
+	Usernames []string `json:"usernames"`
+}
+
+// PasswordRotationResult lists the users whose password was expired and the
+// usernames that matched no user.
+type PasswordRotationResult struct {
+	Expired  []string `json:"expired"`
+	NotFound []string `json:"not_found"`
+}
+
+type WebhookRequest struct {
+	URL    string   `json:"url" yaml:"url"`
+	Events []string `json:"events" yaml:"events"`
+}
+
+// StockLowEvent is the data of a stock.low webhook event.
+type StockLowEvent struct {
+	ProductID int    `json:"product_id"`
+	Name      string `json:"name"`
+	Quantity  int    `json:"quantity"`
+	Threshold int    `json:"threshold"`
+}
+
+// ExportRecipientRequest registers someone encrypted exports can be
+// emailed to.
+type ExportRecipientRequest struct {
+	Name      string `json:"name"`
+	Email     string `json:"email"`
+	PublicKey string `json:"public_key"` // ASCII-armored PGP public key
+}
+
+// ExportDelivery tells where a PGP-encrypted export is being emailed.
+type ExportDelivery struct {
+	Filename    string `json:"filename"`
+	RecipientID int    `json:"recipient_id"`
+	Email       string `json:"email"`
+	Fingerprint string `json:"fingerprint"`
+}
+
+// DownloadURL is a presigned link to a stored file.
+type DownloadURL struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// DashboardWidgets holds the widgets GET /metrics/dashboard?widgets= asked
+// for; the others are left out. Field names match the full dashboard's.
+type DashboardWidgets struct {
+	Summary   *SummaryWidget   `json:"summary,omitempty"`
+	LowStock  *LowStockWidget  `json:"low_stock,omitempty"`
+	TopMovers *TopMoversWidget `json:"top_movers,omitempty"`
+	Valuation *ValuationWidget `json:"valuation,omitempty"`
+	StockLoad *StockLoadWidget `json:"stock_load,omitempty"`
+	LowMargin *LowMarginWidget `json:"low_margin,omitempty"`
+	Expiring  *ExpiringWidget  `json:"expiring,omitempty"`
+	Trends    *repo.Trends     `json:"trends,omitempty"`
+}
+
+type SummaryWidget struct {
+	TotalProducts       int   `json:"total_products"`
+	TotalMovements      int   `json:"total_movements"`
+	TotalQuantity       int   `json:"total_quantity"`
+	MovementLogFailures int64 `json:"movement_log_failures"`
+}
+
+type LowStockWidget struct {
+	LowStockCount int `json:"low_stock_count"`
+}
+
+type TopMoversWidget struct {
+	MostMovedProduct repo.MostMovedProduct `json:"most_moved_product"`
+	Top5Movers       []repo.TopMover       `json:"top_5_movers"`
+}
+
+type ValuationWidget struct {
+	Currency        string               `json:"currency"`
+	AveragePrice    float64              `json:"average_price"`
+	TotalStockValue float64              `json:"total_stock_value"`
+	StockByCurrency []repo.CurrencyStock `json:"stock_by_currency"`
+}
+
+type StockLoadWidget struct {
+	TotalStockWeight float64 `json:"total_stock_weight_kg"`
+	TotalStockVolume float64 `json:"total_stock_volume_m3"`
+}
+
+type LowMarginWidget struct {
+	LowMarginMovers []repo.LowMarginMover `json:"low_margin_movers"`
+}
+
+type ExpiringWidget struct {
+	ExpiringSoonCount int `json:"expiring_soon_count"`
 }
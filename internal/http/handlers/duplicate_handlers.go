@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"cmp"
+	"log"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+const (
+	defaultDuplicateMinScore = 0.6
+	defaultDuplicateLimit    = 100
+	maxDuplicateLimit        = 1000
+	// minNameSimilarity is the trigram overlap below which names are not
+	// considered alike at all.
+	minNameSimilarity = 0.5
+	// maxTrigramProducts skips trigrams shared by more products than this
+	// ("the", "pro"...): they say little about similarity and would make the
+	// comparison quadratic.
+	maxTrigramProducts = 200
+)
+
+// Weight of each signal. Signals are combined as independent evidence, so
+// two weak matches score higher than either alone.
+var duplicateSignals = map[string]float64{
+	"barcode":        0.95,
+	"sku":            0.9,
+	"price_category": 0.4,
+}
+
+// FindDuplicatesHandler godoc
+// @Summary Find products that are likely duplicates
+// @Description Pairs products with the same barcode or SKU, similar names, or the same price within a category, scored from 0 to 1. Each pair carries a suggested body for the merge endpoint, keeping the older product.
+// @Tags products
+// @Security BearerAuth
+// @Produce json
+// @Param min_score query number false "Lowest score to report (default 0.6)"
+// @Param limit query int false "Maximum number of pairs (default 100, max 1000)"
+// @Success 200 {array} DuplicateCandidate
+// @Failure 400 {string} string "Invalid input"
+// @Failure 500 {string} string "Internal error"
+// @Router /products/duplicates [get]
+func FindDuplicatesHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	minScore := defaultDuplicateMinScore
+	if raw := q.Get("min_score"); raw != "" {
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil || v <= 0 || v > 1 {
+			http.Error(w, "min_score must be a number between 0 and 1", http.StatusBadRequest)
+			return
+		}
+		minScore = v
+	}
+	limit := defaultDuplicateLimit
+	if raw := q.Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		limit = min(n, maxDuplicateLimit)
+	}
+
+	products, err := productRepo.GetAll()
+	if err != nil {
+		http.Error(w, "could not fetch products", http.StatusInternalServerError)
+		return
+	}
+
+	candidates := findDuplicates(products, minScore)
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+	if err := writeJSON(w, http.StatusOK, candidates); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+type productPair struct{ a, b int } // indices into the product slice, a < b
+
+type pairEvidence struct {
+	reasons        []string
+	nameSimilarity float64
+}
+
+func findDuplicates(products []models.Product, minScore float64) []DuplicateCandidate {
+	slices.SortFunc(products, func(a, b models.Product) int { return a.ID - b.ID })
+	evidence := map[productPair]*pairEvidence{}
+	add := func(p productPair, reason string) *pairEvidence {
+		e, ok := evidence[p]
+		if !ok {
+			e = &pairEvidence{}
+			evidence[p] = e
+		}
+		if reason != "" && !slices.Contains(e.reasons, reason) {
+			e.reasons = append(e.reasons, reason)
+		}
+		return e
+	}
+
+	pairWithin := func(reason string, key func(models.Product) string) {
+		groups := map[string][]int{}
+		for i, p := range products {
+			if k := key(p); k != "" {
+				groups[k] = append(groups[k], i)
+			}
+		}
+		for _, group := range groups {
+			for x := range group {
+				for _, j := range group[x+1:] {
+					add(productPair{group[x], j}, reason)
+				}
+			}
+		}
+	}
+	pairWithin("barcode", func(p models.Product) string { return strings.TrimSpace(p.Barcode) })
+	pairWithin("sku", func(p models.Product) string { return strings.ToLower(strings.TrimSpace(p.SKU)) })
+
+	for pair, similarity := range similarNames(products) {
+		add(pair, "name").nameSimilarity = similarity
+	}
+
+	// Same price in the same category is weak evidence. Alone it cannot reach
+	// the default threshold, so every such pair is only enumerated when asked
+	// for; otherwise it just strengthens pairs found above.
+	priceCategory := func(p models.Product) string {
+		if p.Category == "" || p.Price <= 0 {
+			return ""
+		}
+		return strings.ToLower(p.Category) + "\x00" + strconv.FormatInt(int64(math.Round(p.Price*100)), 10)
+	}
+	if minScore <= duplicateSignals["price_category"] {
+		pairWithin("price_category", priceCategory)
+	} else {
+		for pair := range evidence {
+			if k := priceCategory(products[pair.a]); k != "" && k == priceCategory(products[pair.b]) {
+				add(pair, "price_category")
+			}
+		}
+	}
+
+	candidates := []DuplicateCandidate{}
+	for pair, e := range evidence {
+		miss := 1.0
+		for _, reason := range e.reasons {
+			weight := duplicateSignals[reason]
+			if reason == "name" {
+				weight = e.nameSimilarity
+			}
+			miss *= 1 - weight
+		}
+		score := math.Round((1-miss)*1000) / 1000
+		if score < minScore {
+			continue
+		}
+
+		a, b := products[pair.a], products[pair.b]
+		slices.SortFunc(e.reasons, func(x, y string) int { return cmp.Compare(duplicateSignals[y], duplicateSignals[x]) })
+		candidates = append(candidates, DuplicateCandidate{
+			Products:       [2]ProductResponse{newProductResponse(a), newProductResponse(b)},
+			Score:          score,
+			Reasons:        e.reasons,
+			NameSimilarity: math.Round(e.nameSimilarity*1000) / 1000,
+			SuggestedMerge: MergeProductsRequest{SourceID: b.ID, TargetID: a.ID},
+		})
+	}
+	slices.SortFunc(candidates, func(x, y DuplicateCandidate) int {
+		if c := cmp.Compare(y.Score, x.Score); c != 0 {
+			return c
+		}
+		if c := x.Products[0].Id - y.Products[0].Id; c != 0 {
+			return c
+		}
+		return x.Products[1].Id - y.Products[1].Id
+	})
+	return candidates
+}
+
+// similarNames compares names by their character trigrams (Dice coefficient)
+// using an inverted index, so only products sharing trigrams are compared.
+func similarNames(products []models.Product) map[productPair]float64 {
+	grams := make([]map[string]bool, len(products))
+	index := map[string][]int{}
+	for i, p := range products {
+		grams[i] = trigrams(p.Name)
+		for g := range grams[i] {
+			index[g] = append(index[g], i)
+		}
+	}
+
+	shared := map[productPair]int{}
+	for _, holders := range index {
+		if len(holders) > maxTrigramProducts {
+			continue
+		}
+		for x := range holders {
+			for _, j := range holders[x+1:] {
+				shared[productPair{holders[x], j}]++
+			}
+		}
+	}
+
+	similar := map[productPair]float64{}
+	for pair, n := range shared {
+		dice := 2 * float64(n) / float64(len(grams[pair.a])+len(grams[pair.b]))
+		if dice >= minNameSimilarity {
+			similar[pair] = dice
+		}
+	}
+	return similar
+}
+
+// trigrams returns the set of three-letter sequences of a name, lowercased and
+// with punctuation collapsed, padded so that short words still count.
+func trigrams(name string) map[string]bool {
+	fields := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	set := map[string]bool{}
+	for _, f := range fields {
+		runes := []rune("  " + f + " ")
+		for i := 0; i+3 <= len(runes); i++ {
+			set[string(runes[i:i+3])] = true
+		}
+	}
+	return set
+}
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+)
+
+// Error codes let clients tell failures apart without parsing messages.
+const (
+	ErrCodeInvalidInput        = "invalid_input"
+	ErrCodeNotFound            = "not_found"
+	ErrCodeDuplicateName       = "duplicate_name"
+	ErrCodeDuplicateExternalID = "duplicate_external_id"
+	ErrCodeInvalidRow          = "invalid_row"
+	ErrCodeShortage            = "component_shortage"
+	ErrCodeConflict            = "conflict"
+	ErrCodeQuotaExceeded       = "quota_exceeded"
+	ErrCodeOutOfScope          = "out_of_scope"
+	ErrCodeInfectedFile        = "infected_file"
+	ErrCodeSuspiciousPrice     = "suspicious_price"
+	ErrCodePasswordExpired     = "PASSWORD_EXPIRED"
+	ErrCodeInternal            = "internal_error"
+)
+
+// ErrorResponse is the JSON envelope for errors.
+type ErrorResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// writeError sends an ErrorResponse with the given status.
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	if err := writeJSON(w, status, ErrorResponse{Code: code, Message: message}); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+)
+
+const (
+	defaultEventLogLimit = 100
+	maxEventLogLimit     = 1000
+)
+
+// ListEventLogHandler godoc
+// @Summary Replay published events
+// @Description Returns the events delivered to the security webhook and syslog sinks, oldest first, as stored before delivery. Each carries an increasing sequence number, so a consumer that missed deliveries passes the last sequence it saw as since_id and pages with next_since_id until no events are left, instead of resyncing everything. Only event types enabled with SECURITY_EVENT_<TYPE> are stored.
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param since_id query int false "Return events after this sequence (default 0, the beginning)"
+// @Param limit query int false "Maximum events (default 100, max 1000)"
+// @Success 200 {object} EventLogPage
+// @Failure 400 {string} string "Invalid since_id or limit"
+// @Failure 403 {string} string "Admins only"
+// @Failure 500 {string} string "Internal error"
+// @Router /events [get]
+func ListEventLogHandler(w http.ResponseWriter, r *http.Request) {
+	sinceID := 0
+	if raw := r.URL.Query().Get("since_id"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			http.Error(w, "since_id must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+		sinceID = n
+	}
+	limit := defaultEventLogLimit
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		limit = min(n, maxEventLogLimit)
+	}
+
+	events, err := eventLogRepo.Since(sinceID, limit)
+	if err != nil {
+		http.Error(w, "could not fetch events", http.StatusInternalServerError)
+		return
+	}
+	page := EventLogPage{Events: events, NextSinceID: sinceID}
+	if len(events) > 0 {
+		page.NextSinceID = events[len(events)-1].Sequence
+	}
+	if err := writeJSON(w, http.StatusOK, page); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
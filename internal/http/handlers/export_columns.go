@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// parseExportColumns reads a ?columns= list: the CSV columns wanted, in the
+// order wanted. Every name must be one of allowed; an empty list selects all
+// of allowed in their default order.
+func parseExportColumns(raw string, allowed []string) ([]string, error) {
+	if strings.TrimSpace(raw) == "" {
+		return allowed, nil
+	}
+
+	var columns []string
+	for _, c := range strings.Split(raw, ",") {
+		c = strings.ToLower(strings.TrimSpace(c))
+		switch {
+		case c == "":
+			return nil, fmt.Errorf("empty column name in columns")
+		case !slices.Contains(allowed, c):
+			return nil, fmt.Errorf("unknown column %q, expected any of %s", c, strings.Join(allowed, ", "))
+		case slices.Contains(columns, c):
+			return nil, fmt.Errorf("column %q is listed twice", c)
+		}
+		columns = append(columns, c)
+	}
+	return columns, nil
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// formulaPrefixes are the first characters that make Excel and other
+// spreadsheets evaluate a cell as a formula.
+const formulaPrefixes = "=+-@\t\r"
+
+// isFormula reports whether a spreadsheet would evaluate value as a formula
+// rather than show it. Plain numbers, negative ones included, are safe.
+func isFormula(value string) bool {
+	if value == "" || !strings.ContainsRune(formulaPrefixes, rune(value[0])) {
+		return false
+	}
+	_, err := strconv.ParseFloat(value, 64)
+	return err != nil
+}
+
+// csvCell is an imported value with the column it came from.
+type csvCell struct {
+	column, value string
+}
+
+// rejectFormulas fails on the first cell a spreadsheet would evaluate as a
+// formula, so imported text cannot come back out of an export as one.
+func rejectFormulas(cells ...csvCell) error {
+	for _, c := range cells {
+		if isFormula(c.value) {
+			return fmt.Errorf("%s must not start with =, +, -, @, a tab or a carriage return", c.column)
+		}
+	}
+	return nil
+}
+
+// safeCSVWriter writes CSV whose cells cannot run as formulas when the file
+// is opened in a spreadsheet: cells that would are prefixed with a single
+// quote, which spreadsheets take as "show as text".
+type safeCSVWriter struct {
+	*csv.Writer
+}
+
+func newSafeCSVWriter(w io.Writer) safeCSVWriter {
+	return safeCSVWriter{csv.NewWriter(w)}
+}
+
+func (w safeCSVWriter) Write(record []string) error {
+	safe := make([]string, len(record))
+	for i, cell := range record {
+		if isFormula(cell) {
+			cell = "'" + cell
+		}
+		safe[i] = cell
+	}
+	return w.Writer.Write(safe)
+}
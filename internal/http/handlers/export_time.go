@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// exportDateFormats are the named values of ?date_format=. Any Go reference
+// layout (one that contains "2006") is accepted as well.
+var exportDateFormats = map[string]string{
+	"rfc3339":  time.RFC3339,
+	"datetime": time.DateTime,
+	"date":     time.DateOnly,
+}
+
+// exportClock renders timestamps in exported files.
+type exportClock struct {
+	loc    *time.Location
+	layout string
+}
+
+// parseExportClock reads ?tz= and ?date_format=. Without tz, timestamps are
+// shown in the caller's profile timezone, or in UTC for anonymous callers and
+// users who never set one.
+func parseExportClock(r *http.Request) (exportClock, error) {
+	q := r.URL.Query()
+	c := exportClock{loc: time.UTC, layout: time.RFC3339}
+
+	if tz := q.Get("tz"); tz != "" {
+		loc, err := loadTimezone(tz)
+		if err != nil {
+			return c, err
+		}
+		c.loc = loc
+	} else if tz := profileTimezone(r); tz != "" {
+		if loc, err := loadTimezone(tz); err == nil {
+			c.loc = loc
+		} else {
+			log.Printf("ignoring profile timezone: %v", err)
+		}
+	}
+
+	if f := q.Get("date_format"); f != "" {
+		layout, ok := exportDateFormats[strings.ToLower(f)]
+		if !ok {
+			if !strings.Contains(f, "2006") {
+				return c, fmt.Errorf("date_format must be rfc3339, datetime, date or a Go layout such as 02/01/2006 15:04")
+			}
+			layout = f
+		}
+		c.layout = layout
+	}
+	return c, nil
+}
+
+// format re-renders an RFC3339 timestamp; anything else is returned as is.
+func (c exportClock) format(raw string) string {
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return raw
+	}
+	return t.In(c.loc).Format(c.layout)
+}
+
+// loadTimezone resolves an IANA timezone name. "Local" is refused: it would
+// make output depend on the server's configuration.
+func loadTimezone(name string) (*time.Location, error) {
+	if name == "Local" {
+		return nil, errors.New(`timezone "Local" is not supported, use an IANA name such as Europe/Madrid`)
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone %q", name)
+	}
+	return loc, nil
+}
+
+func profileTimezone(r *http.Request) string {
+	username, err := GetUsernameFromContext(r)
+	if err != nil || username == "" {
+		return ""
+	}
+	u, err := userRepo.GetByUsername(username)
+	if err != nil {
+		return ""
+	}
+	return u.Timezone
+}
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rogerio-castellano/inventory-tracker/internal/auth"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+	"github.com/rogerio-castellano/inventory-tracker/internal/usage"
+)
+
+// MeDataExportHandler godoc
+// @Summary Export all personal data held about the current user
+// @Tags auth
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} PersonalDataExport
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 500 {string} string "Internal error"
+// @Router /me/data-export [get]
+func MeDataExportHandler(w http.ResponseWriter, r *http.Request) {
+	username, err := GetUsernameFromContext(r)
+	if err != nil || username == "" {
+		http.Error(w, "invalid token", http.StatusUnauthorized)
+		return
+	}
+
+	user, err := userRepo.GetByUsername(username)
+	if err != nil {
+		http.Error(w, "could not fetch user", http.StatusInternalServerError)
+		return
+	}
+
+	sessions, _, err := auth.GetRefreshToken(username)
+	if err != nil {
+		http.Error(w, "could not fetch sessions", http.StatusInternalServerError)
+		return
+	}
+
+	entries, err := auditRepo.Find(repo.AuditFilter{Actor: username})
+	if err != nil {
+		http.Error(w, "could not fetch audit entries", http.StatusInternalServerError)
+		return
+	}
+
+	usageRecords, err := usageRepo.GetByUser(username)
+	if err != nil {
+		http.Error(w, "could not fetch usage", http.StatusInternalServerError)
+		return
+	}
+
+	logins, err := loginEventRepo.List(username, 0, 0)
+	if err != nil {
+		http.Error(w, "could not fetch login history", http.StatusInternalServerError)
+		return
+	}
+
+	export := PersonalDataExport{
+		Profile: PersonalProfile{
+			ID:        user.ID,
+			Username:  user.Username,
+			Email:     user.Email,
+			Role:      user.Role,
+			Timezone:  user.Timezone,
+			CreatedAt: user.CreatedAt,
+			UpdatedAt: user.UpdatedAt,
+		},
+		Sessions:     []RefreshTokenInfo{},
+		AuditEntries: entries,
+		Usage:        usageRecords,
+		Logins:       logins,
+	}
+	for key, entry := range sessions {
+		export.Sessions = append(export.Sessions, sessionInfo(username, key, entry))
+	}
+
+	w.Header().Set("Content-Disposition", `attachment; filename="personal_data.json"`)
+	if err := writeJSON(w, http.StatusOK, export); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// ErasePersonalDataHandler godoc
+// @Summary Erase a user's personal data
+// @Description Anonymizes the account and every record referencing it instead of deleting them, so audit history stays intact and verifiable. The account is anonymized last, so an erasure that fails part way can be retried.
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param username path string true "Username"
+// @Success 200 {object} map[string]string
+// @Failure 404 {string} string "User not found"
+// @Failure 500 {string} string "Internal error"
+// @Router /admin/users/{username}/personal-data [delete]
+func ErasePersonalDataHandler(w http.ResponseWriter, r *http.Request) {
+	username := chi.URLParam(r, "username")
+
+	user, err := userRepo.GetByUsername(username)
+	if err != nil || user.ID == 0 {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+	pseudonym := fmt.Sprintf("deleted-user-%d", user.ID)
+
+	// Records referencing the username go first: once the account is
+	// renamed, a retry could no longer find them.
+	if err := auth.RemoveUserRefreshTokens(username); err != nil {
+		log.Printf("failed to revoke sessions of erased user %d: %v", user.ID, err)
+	}
+	if err := auditRepo.Pseudonymize(username, pseudonym); err != nil {
+		http.Error(w, "could not anonymize audit entries", http.StatusInternalServerError)
+		return
+	}
+	if err := usageRepo.Pseudonymize(username, pseudonym); err != nil {
+		http.Error(w, "could not anonymize usage records", http.StatusInternalServerError)
+		return
+	}
+	if err := usage.Forget(username); err != nil {
+		log.Printf("failed to drop live usage of erased user %d: %v", user.ID, err)
+	}
+	if err := loginEventRepo.DeleteByUsername(username); err != nil {
+		http.Error(w, "could not erase login history", http.StatusInternalServerError)
+		return
+	}
+	if err := accessGrantRepo.DeleteByUsername(username); err != nil {
+		http.Error(w, "could not erase access grants", http.StatusInternalServerError)
+		return
+	}
+	if err := userRepo.Anonymize(username, pseudonym); err != nil {
+		if errors.Is(err, repo.ErrUserNotFound) {
+			http.Error(w, "User not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "could not anonymize user", http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(r, "erase", "user", pseudonym, nil)
+
+	if err := writeJSON(w, http.StatusOK, map[string]string{"pseudonym": pseudonym}); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	rl "github.com/rogerio-castellano/inventory-tracker/internal/http/rate_limiter"
+)
+
+// ReadyzHandler godoc
+// @Summary Readiness probe
+// @Description Reports whether Redis is reachable and which rate-limited routes run degraded. While rate limiting fails closed, degraded routes reject requests, so the instance reports itself unavailable.
+// @Tags health
+// @Produce json
+// @Success 200 {object} ReadinessResponse
+// @Failure 503 {object} ReadinessResponse
+// @Router /readyz [get]
+func ReadyzHandler(w http.ResponseWriter, r *http.Request) {
+	resp := ReadinessResponse{
+		Status: "ready",
+		Redis:  "ok",
+		RateLimiter: RateLimiterStatus{
+			FailMode:   rl.FailMode(),
+			OpenRoutes: rl.OpenBreakers(),
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+	if err := Rdb.Ping(ctx).Err(); err != nil {
+		resp.Redis = "unreachable"
+	}
+
+	resp.RateLimiter.Degraded = len(resp.RateLimiter.OpenRoutes) > 0
+	status := http.StatusOK
+	switch {
+	case resp.RateLimiter.Degraded && resp.RateLimiter.FailMode == rl.FailClosed:
+		resp.Status = "unavailable"
+		status = http.StatusServiceUnavailable
+	case resp.RateLimiter.Degraded || resp.Redis != "ok":
+		resp.Status = "degraded"
+	}
+
+	if err := writeJSON(w, status, resp); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
@@ -1,19 +1,40 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/rogerio-castellano/inventory-tracker/internal/auth"
 )
 
-func GetRoleFromContext(r *http.Request) (string, error) {
-	authorization := r.Header.Get("Authorization")
+type verifiedClaimsKey struct{}
+
+// WithVerifiedClaims returns a shallow copy of r carrying the claims the
+// authentication middleware verified, so they are not checked again.
+func WithVerifiedClaims(r *http.Request, claims jwt.MapClaims) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), verifiedClaimsKey{}, claims))
+}
 
-	_, claims, err := auth.TokenClaims(authorization)
+// requestClaims returns the claims of the caller's token, verified the same
+// way as by the authentication middleware on routes it does not cover.
+func requestClaims(r *http.Request) (jwt.MapClaims, error) {
+	if claims, ok := r.Context().Value(verifiedClaimsKey{}).(jwt.MapClaims); ok {
+		return claims, nil
+	}
+	return auth.VerifyToken(Ctx, Rdb, r.Header.Get("Authorization"))
+}
+
+func GetRoleFromContext(r *http.Request) (string, error) {
+	claims, err := requestClaims(r)
 	if err != nil {
 		return "", err
 	}
@@ -24,6 +45,40 @@ func GetRoleFromContext(r *http.Request) (string, error) {
 	return "", nil
 }
 
+type requestIDKey struct{}
+
+// WithRequestID returns a shallow copy of r carrying id as its request ID.
+func WithRequestID(r *http.Request, id string) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id))
+}
+
+// RequestID returns the ID the request was tagged with, or "" if it has none.
+func RequestID(r *http.Request) string {
+	id, _ := r.Context().Value(requestIDKey{}).(string)
+	return id
+}
+
+// clientIP returns the caller's address without the port.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func GetUsernameFromContext(r *http.Request) (string, error) {
+	claims, err := requestClaims(r)
+	if err != nil {
+		return "", err
+	}
+
+	if username, ok := claims["username"].(string); ok {
+		return username, nil
+	}
+	return "", nil
+}
+
 // readJSON tries to read the body of a request and converts it into JSON
 func readJSON(w http.ResponseWriter, r *http.Request, data any) error {
 	maxBytes := 1048576 // one megabyte
@@ -49,6 +104,9 @@ func writeJSON(w http.ResponseWriter, status int, data any, headers ...http.Head
 	if err != nil {
 		return fmt.Errorf("failed to read JSON: %w", err)
 	}
+	if pricingHidden(w) {
+		out = stripPricing(out)
+	}
 
 	if len(headers) > 0 {
 		for key, value := range headers[0] {
@@ -65,3 +123,33 @@ func writeJSON(w http.ResponseWriter, status int, data any, headers ...http.Head
 
 	return nil
 }
+
+// parseWithin reads a look-ahead window such as "30d" or "72h". Days are
+// accepted on top of the units time.ParseDuration knows. Empty means def.
+func parseWithin(raw string, def time.Duration) (time.Duration, error) {
+	if raw == "" {
+		return def, nil
+	}
+	var d time.Duration
+	var err error
+	if days, ok := strings.CutSuffix(raw, "d"); ok {
+		var n int
+		n, err = strconv.Atoi(days)
+		d = time.Duration(n) * 24 * time.Hour
+	} else {
+		d, err = time.ParseDuration(raw)
+	}
+	if err != nil || d <= 0 {
+		return 0, errors.New("within must be a positive duration such as 30d or 72h")
+	}
+	return d, nil
+}
+
+// parseDate accepts a calendar date (YYYY-MM-DD) or a full RFC3339 timestamp.
+func parseDate(raw string) (time.Time, error) {
+	if t, err := time.Parse(time.DateOnly, raw); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	return t.UTC(), err
+}
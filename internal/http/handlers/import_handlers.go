@@ -1,17 +1,25 @@
 package handlers
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/csv"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"log"
 	"mime/multipart"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/rogerio-castellano/inventory-tracker/internal/auth"
 	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+	"github.com/rogerio-castellano/inventory-tracker/internal/xlsx"
 )
 
 // ImportProductsHandler godoc
@@ -21,9 +29,12 @@ import (
 // @Produce json
 // @Param file formData file true "CSV file"
 // @Param mode query string false "Import mode (skip|update)"
+// @Param override_price_rules query bool false "Accept prices that break the price rules (needs pricing:override)"
 // @Success 200 {object} map[string]any
 // @Failure 400 {string} string "Invalid file"
+// @Failure 422 {object} ErrorResponse "Infected file"
 // @Failure 500 {string} string "Internal error"
+// @Failure 503 {object} ErrorResponse "File could not be scanned"
 // @Router /products/import [post]
 // @Security BearerAuth
 func ImportProductsHandler(w http.ResponseWriter, r *http.Request) {
@@ -32,78 +43,576 @@ func ImportProductsHandler(w http.ResponseWriter, r *http.Request) {
 		mode = "skip" // default
 	}
 
-	file, _, err := r.FormFile("file")
+	file, header, err := r.FormFile("file")
 	if err != nil {
-		http.Error(w, "missing file", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidInput, "missing file")
 		return
 	}
 	defer file.Close()
+	if !scanUpload(w, r, file, header.Filename) {
+		return
+	}
 
 	records, err := parseCSV(file)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidInput, err.Error())
+		return
+	}
+
+	// Existing products are loaded in one query rather than one per row.
+	names := make([]string, 0, len(records))
+	for _, rec := range records {
+		names = append(names, rec.Name)
+	}
+	existingByName, err := productRepo.GetByNames(names)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "could not load existing products")
+		return
+	}
+	classes, err := taxClassRepo.List()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "could not load tax classes")
+		return
+	}
+	required, err := requiredFields()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "could not load validation policy")
+		return
+	}
+	rules, err := priceRulesRepo.Get(tenant)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "could not load price rules")
+		return
+	}
+	scope, err := accessScope(r)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "could not load access grants")
 		return
 	}
+	outOfScope := func(rowNum int) ProductValidationError {
+		return ProductValidationError{Code: ErrCodeOutOfScope, Description: fmt.Sprintf("row %d: category outside your access grants", rowNum)}
+	}
 
 	var imported int
-	var errorsList []ProductValidationError
+	var errorsList, warnings []ProductValidationError
+	// screen applies the price rules to a row, reporting it as an error
+	// when its price is refused and as a warning when it is let through.
+	screen := func(rowNum int, price float64, before *float64) (priceCheck, bool) {
+		c := screenPrice(r, rules, price, before)
+		for _, v := range c.Violations {
+			e := ProductValidationError{Field: "Price", Code: ErrCodeSuspiciousPrice, Description: fmt.Sprintf("row %d: %s", rowNum, v)}
+			if c.Rejected {
+				errorsList = append(errorsList, e)
+			} else {
+				warnings = append(warnings, e)
+			}
+		}
+		return c, !c.Rejected
+	}
+
+	// New products are collected and inserted in one batch; pending maps
+	// their names to positions so repeated rows behave as if the earlier
+	// row had already been stored.
+	var newProducts []models.Product
+	var newRows []int
+	var newChecks []priceCheck
+	pending := map[string]int{}
 
 	for i, rec := range records {
 		rowNum := i + 2 // header is row 1
 
 		if err := validateRow(rec); err != nil {
-			errorsList = append(errorsList, ProductValidationError{Description: fmt.Sprintf("row %d: %v", rowNum, err)})
+			errorsList = append(errorsList, ProductValidationError{Code: ErrCodeInvalidRow, Description: fmt.Sprintf("row %d: %v", rowNum, err)})
+			continue
+		}
+		if err := rec.resolveTaxClass(classes); err != nil {
+			errorsList = append(errorsList, ProductValidationError{Code: ErrCodeInvalidRow, Description: fmt.Sprintf("row %d: %v", rowNum, err)})
 			continue
 		}
 
-		existing, err := productRepo.GetByName(rec.Name)
-		if err == nil && existing.ID != 0 {
+		key := repo.NameKey(rec.Name)
+		if idx, ok := pending[key]; ok {
 			if mode == "skip" {
-				errorsList = append(errorsList, ProductValidationError{Description: fmt.Sprintf("row %d: product '%s' already exists", rowNum, rec.Name)})
+				errorsList = append(errorsList, ProductValidationError{Code: ErrCodeDuplicateName, Description: fmt.Sprintf("row %d: product '%s' already exists", rowNum, rec.Name)})
+				continue
+			}
+			product := newProducts[idx]
+			rec.apply(&product)
+			if !scope.AllowsCategory(product.Category) {
+				errorsList = append(errorsList, outOfScope(rowNum))
+				continue
+			}
+			check, ok := screen(rowNum, product.Price, nil)
+			if !ok {
+				continue
+			}
+			newProducts[idx] = product
+			newChecks[idx] = check
+			imported++
+			continue
+		}
+
+		if existing, ok := existingByName[key]; ok {
+			if mode == "skip" {
+				errorsList = append(errorsList, ProductValidationError{Code: ErrCodeDuplicateName, Description: fmt.Sprintf("row %d: product '%s' already exists", rowNum, rec.Name)})
+				continue
+			}
+			before := existing
+			rec.apply(&existing)
+			if !scope.AllowsCategory(before.Category) || !scope.AllowsCategory(existing.Category) {
+				errorsList = append(errorsList, outOfScope(rowNum))
+				continue
+			}
+			check, ok := screen(rowNum, existing.Price, &before.Price)
+			if !ok {
 				continue
 			}
-			existing.Price = rec.Price
-			existing.Quantity = rec.Quantity
-			existing.Threshold = rec.Threshold
 			existing.UpdatedAt = nowRFC3339()
-			if _, err := productRepo.Update(existing); err != nil {
-				errorsList = append(errorsList, ProductValidationError{Description: fmt.Sprintf("row %d: failed to update '%s'", rowNum, rec.Name)})
+			updated, err := productRepo.Update(existing)
+			if err != nil {
+				errorsList = append(errorsList, ProductValidationError{Code: ErrCodeInternal, Description: fmt.Sprintf("row %d: failed to update '%s'", rowNum, rec.Name)})
 				continue
 			}
+			existingByName[key] = updated
+			recordAudit(r, "import", "product", updated.ID, map[string]any{"row": rowNum, "before": before, "after": updated})
+			recordPriceCheck(r, updated.ID, updated.Price, check)
 			imported++
 			continue
 		}
 
-		newProduct := models.Product{
-			Name:      rec.Name,
-			Price:     rec.Price,
-			Quantity:  rec.Quantity,
-			Threshold: rec.Threshold,
-			CreatedAt: nowRFC3339(),
-			UpdatedAt: nowRFC3339(),
+		product := models.Product{Name: rec.Name, CreatedAt: nowRFC3339(), UpdatedAt: nowRFC3339()}
+		rec.apply(&product)
+		if !scope.AllowsCategory(product.Category) {
+			errorsList = append(errorsList, outOfScope(rowNum))
+			continue
 		}
-		if _, err := productRepo.Create(newProduct); err != nil {
-			errorsList = append(errorsList, ProductValidationError{Description: fmt.Sprintf("row %d: %v", rowNum, err)})
+		if missing := validateRequiredFields(product, required); len(missing) > 0 {
+			for _, e := range missing {
+				errorsList = append(errorsList, ProductValidationError{Field: e.Field, Code: ErrCodeInvalidRow, Description: fmt.Sprintf("row %d: %s", rowNum, e.Description)})
+			}
 			continue
 		}
-		imported++
+		check, ok := screen(rowNum, product.Price, nil)
+		if !ok {
+			continue
+		}
+		pending[key] = len(newProducts)
+		newProducts = append(newProducts, product)
+		newRows = append(newRows, rowNum)
+		newChecks = append(newChecks, check)
+	}
+
+	if len(newProducts) > 0 {
+		if err := checkProductQuota(len(newProducts)); err != nil {
+			code := ErrCodeInternal
+			if errors.Is(err, errQuotaExceeded) {
+				code = ErrCodeQuotaExceeded
+			}
+			for _, row := range newRows {
+				errorsList = append(errorsList, ProductValidationError{Code: code, Description: fmt.Sprintf("row %d: %v", row, err)})
+			}
+			newProducts = nil
+		}
+	}
+
+	if len(newProducts) > 0 {
+		n, err := productRepo.CreateBatch(newProducts)
+		if err == nil {
+			imported += n
+			recordImportedProducts(r, newProducts, newRows, newChecks)
+		} else {
+			// A concurrent insert can make the batch fail as a whole; retry
+			// row by row so only the conflicting rows are reported.
+			for i, p := range newProducts {
+				created, err := productRepo.Create(p)
+				if err != nil {
+					code := ErrCodeInternal
+					if errors.Is(err, repo.ErrDuplicatedValueUnique) {
+						code = ErrCodeDuplicateName
+					}
+					errorsList = append(errorsList, ProductValidationError{Code: code, Description: fmt.Sprintf("row %d: %v", newRows[i], err)})
+					continue
+				}
+				recordAudit(r, "import", "product", created.ID, map[string]any{"row": newRows[i], "after": created})
+				recordPriceCheck(r, created.ID, created.Price, newChecks[i])
+				imported++
+			}
+		}
 	}
 
 	err = writeJSON(w, http.StatusOK, ImportProductsResult{
 		ImportedProductsCount: imported,
 		Errors:                errorsList,
+		Warnings:              warnings,
 	})
 
 	if err != nil {
-		http.Error(w, "", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "")
 	}
 }
 
+// recordImportedProducts audits products inserted by CreateBatch, along
+// with the prices let through despite the price rules. CreateBatch does not
+// return their IDs, so they are looked up by name.
+func recordImportedProducts(r *http.Request, products []models.Product, rows []int, checks []priceCheck) {
+	names := make([]string, len(products))
+	for i, p := range products {
+		names[i] = p.Name
+	}
+	stored, err := productRepo.GetByNames(names)
+	if err != nil {
+		log.Printf("failed to load imported products for audit: %v", err)
+		return
+	}
+	for i, p := range products {
+		if created, ok := stored[repo.NameKey(p.Name)]; ok {
+			recordAudit(r, "import", "product", created.ID, map[string]any{"row": rows[i], "after": created})
+			recordPriceCheck(r, created.ID, created.Price, checks[i])
+		}
+	}
+}
+
+// ExportProductsHandler godoc
+// @Summary Export products as CSV, JSON or Excel
+// @Description Uses the same columns as the CSV import, so a CSV file can be edited and imported back with mode=update. The catalog streams out whole, optionally narrowed by the filters of /products/filter; offset and limit are ignored. JSON is an array of objects keyed by column; xlsx is a one-sheet workbook with a header row. The price and cost columns are left out for roles without pricing access, and users granted categories only get products of those. With tax=inclusive, prices include each product's tax rate; such files must not be imported back.
+// @Tags import
+// @Produce text/csv, application/json, application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param format query string false "csv (default), json or xlsx"
+// @Param tax query string false "Price output: exclusive (default, as stored) or inclusive of the product's tax rate"
+// @Param price_list query string false "Name of a price list whose prices replace the regular ones where set"
+// @Param columns query string false "Comma-separated columns, in output order (default: all)"
+// @Param name query string false "Filter by name"
+// @Param q query string false "Full-text search, as on /products/filter"
+// @Param low_stock query bool false "Only products below their threshold"
+// @Param brand query string false "Only products of this brand (case-insensitive)"
+// @Param updated_since query string false "Only products changed at or after this time (RFC3339)"
+// @Param sort query string false "Order: id (default), name, price, quantity or updated_at"
+// @Param order query string false "asc (default) or desc"
+// @Success 200 {file} file
+// @Failure 400 {string} string "Invalid format, filter, tax option or columns"
+// @Failure 500 {string} string "Internal error"
+// @Router /products/export [get]
+// @Security BearerAuth
+func ExportProductsHandler(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "csv"
+	}
+	if !slices.Contains(productExportFormats, format) {
+		http.Error(w, "format must be csv, json or xlsx", http.StatusBadRequest)
+		return
+	}
+	filter, err := parseProductFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	filter.Offset, filter.Limit, filter.SkipTotal = nil, nil, true
+	view, err := parsePriceView(r)
+	if err != nil {
+		writePriceViewError(w, err)
+		return
+	}
+	role, _ := GetRoleFromContext(r)
+	allowed := productCSVColumns
+	if !auth.HasPermission(role, auth.PermPricingRead) {
+		allowed = slices.DeleteFunc(slices.Clone(allowed), func(c string) bool { return c == "price" || c == "cost" })
+	}
+	columns, err := parseExportColumns(r.URL.Query().Get("columns"), allowed)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if filter.Scope, err = accessScope(r); err != nil {
+		http.Error(w, "could not load access grants", http.StatusInternalServerError)
+		return
+	}
+	each := func(fn func(models.Product) error) error {
+		return productRepo.Each(filter, productExportTimeout, fn)
+	}
+
+	// Rows are written as they are read. Until the first buffer goes out,
+	// a failed query can still be answered with an error status.
+	out := &startedWriter{ResponseWriter: w}
+	buf := bufio.NewWriter(out)
+	w.Header().Set("Content-Disposition", `attachment; filename="products.`+format+`"`)
+	switch format {
+	case "csv":
+		w.Header().Set("Content-Type", "text/csv")
+		err = writeProductsCSV(buf, each, columns, view)
+	case "json":
+		w.Header().Set("Content-Type", "application/json")
+		err = writeProductsJSON(buf, each, columns, view)
+	case "xlsx":
+		w.Header().Set("Content-Type", xlsx.ContentType)
+		err = writeProductsXLSX(buf, each, columns, view)
+	}
+	if err == nil {
+		err = buf.Flush()
+	}
+	if err != nil && !out.started {
+		w.Header().Del("Content-Disposition")
+		http.Error(w, "could not fetch products", http.StatusInternalServerError)
+		return
+	}
+	if err != nil {
+		log.Printf("failed to write products %s: %v", format, err)
+	}
+}
+
+// productExportTimeout bounds the query of a product export, which reads
+// the whole catalog rather than a page.
+const productExportTimeout = 5 * time.Minute
+
+// startedWriter records whether anything has been written to the response.
+type startedWriter struct {
+	http.ResponseWriter
+	started bool
+}
+
+func (w *startedWriter) Write(b []byte) (int, error) {
+	w.started = true
+	return w.ResponseWriter.Write(b)
+}
+
+var productExportFormats = []string{"csv", "json", "xlsx"}
+
+// productExportValues holds a product's export columns, numbers as numbers.
+// Missing measures are nil rather than zero.
+func productExportValues(p models.Product, view priceView) map[string]any {
+	values := map[string]any{
+		"name":         p.Name,
+		"price":        roundMoney(view.price(p)),
+		"cost":         p.Cost,
+		"quantity":     p.Quantity,
+		"threshold":    p.Threshold,
+		"category":     p.Category,
+		"sku":          p.SKU,
+		"barcode":      p.Barcode,
+		"supplier":     p.Supplier,
+		"max_quantity": p.MaxQuantity,
+		"status":       p.Status,
+		"tax_class":    "",
+		"description":  p.Description,
+		"brand":        p.Brand,
+		"manufacturer": p.Manufacturer,
+		"weight":       measureValue(p.Weight),
+		"length":       measureValue(p.Dimensions.Length),
+		"width":        measureValue(p.Dimensions.Width),
+		"height":       measureValue(p.Dimensions.Height),
+	}
+	if p.TaxClassID != nil {
+		values["tax_class"] = view.classes[*p.TaxClassID].Name
+	}
+	return values
+}
+
+func measureValue(v float64) any {
+	if v == 0 {
+		return nil
+	}
+	return v
+}
+
+// productIterator calls fn with each exported product, stopping at fn's
+// first error.
+type productIterator func(fn func(models.Product) error) error
+
+func writeProductsCSV(w io.Writer, each productIterator, columns []string, view priceView) error {
+	csvWriter := newSafeCSVWriter(w)
+	if err := csvWriter.Write(columns); err != nil {
+		return err
+	}
+	err := each(func(p models.Product) error {
+		values := productExportValues(p, view)
+		record := make([]string, len(columns))
+		for i, c := range columns {
+			switch v := values[c].(type) {
+			case float64:
+				if c == "price" || c == "cost" {
+					record[i] = strconv.FormatFloat(v, 'f', 2, 64)
+				} else {
+					record[i] = formatMeasure(v)
+				}
+			case int:
+				record[i] = strconv.Itoa(v)
+			case string:
+				record[i] = v
+			}
+		}
+		return csvWriter.Write(record)
+	})
+	if err != nil {
+		return err
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+// writeProductsJSON streams a JSON array of objects whose keys follow
+// columns.
+func writeProductsJSON(w io.Writer, each productIterator, columns []string, view priceView) error {
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+	first := true
+	err := each(func(p models.Product) error {
+		values := productExportValues(p, view)
+		var buf bytes.Buffer
+		if !first {
+			buf.WriteString(",")
+		}
+		first = false
+		buf.WriteString("{")
+		for j, c := range columns {
+			if j > 0 {
+				buf.WriteString(",")
+			}
+			if err := appendJSON(&buf, c); err != nil {
+				return err
+			}
+			buf.WriteString(":")
+			if err := appendJSON(&buf, values[c]); err != nil {
+				return err
+			}
+		}
+		buf.WriteString("}")
+		_, err := w.Write(buf.Bytes())
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "]\n")
+	return err
+}
+
+// appendJSON appends v to buf as JSON, leaving characters such as & as
+// they are.
+func appendJSON(buf *bytes.Buffer, v any) error {
+	var out bytes.Buffer
+	enc := json.NewEncoder(&out)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	buf.Write(bytes.TrimSuffix(out.Bytes(), []byte("\n")))
+	return nil
+}
+
+func writeProductsXLSX(w io.Writer, each productIterator, columns []string, view priceView) error {
+	sheet, err := xlsx.NewWriter(w, "Products")
+	if err != nil {
+		return err
+	}
+	header := make([]any, len(columns))
+	for i, c := range columns {
+		header[i] = c
+	}
+	if err := sheet.WriteRow(header); err != nil {
+		return err
+	}
+	err = each(func(p models.Product) error {
+		values := productExportValues(p, view)
+		row := make([]any, len(columns))
+		for i, c := range columns {
+			row[i] = values[c]
+		}
+		return sheet.WriteRow(row)
+	})
+	if err != nil {
+		return err
+	}
+	return sheet.Close()
+}
+
+// productCSVColumns is the full column set, in export order. Only name,
+// price and quantity are required on import; columns are matched by header,
+// so the original four-column files still import unchanged.
+var productCSVColumns = []string{"name", "price", "quantity", "threshold", "category", "sku", "barcode", "supplier", "max_quantity", "status", "cost", "tax_class",
+	"description", "brand", "manufacturer", "weight", "length", "width", "height"}
+
+var requiredCSVColumns = []string{"name", "price", "quantity"}
+
 type csvRow struct {
-	Name      string
-	Price     float64
-	Quantity  int
-	Threshold int
+	Name        string
+	Price       float64
+	Quantity    int
+	Threshold   int
+	Category    string
+	SKU         string
+	Barcode     string
+	Supplier    string
+	MaxQuantity int
+	Status      string
+	Cost        float64
+	TaxClass    string // name, matched regardless of case; empty for untaxed
+	taxClassID  *int
+
+	Description  string
+	Brand        string
+	Manufacturer string
+	Weight       float64
+	Dimensions   models.Dimensions
+
+	// columns holds the headers present in the file. Absent optional
+	// columns leave existing values untouched on update.
+	columns map[string]bool
+}
+
+// apply copies the row's values onto p.
+func (r csvRow) apply(p *models.Product) {
+	p.Price = r.Price
+	p.Quantity = r.Quantity
+	if r.columns["threshold"] {
+		p.Threshold = r.Threshold
+	}
+	if r.columns["category"] {
+		p.Category = r.Category
+	}
+	if r.columns["sku"] {
+		p.SKU = r.SKU
+	}
+	if r.columns["barcode"] {
+		p.Barcode = r.Barcode
+	}
+	if r.columns["supplier"] {
+		p.Supplier = r.Supplier
+	}
+	if r.columns["max_quantity"] {
+		p.MaxQuantity = r.MaxQuantity
+	}
+	if r.columns["cost"] {
+		p.Cost = r.Cost
+	}
+	if r.columns["tax_class"] {
+		p.TaxClassID = r.taxClassID
+	}
+	if r.columns["status"] && r.Status != "" {
+		p.Status = r.Status
+	}
+	if r.columns["description"] {
+		p.Description = r.Description
+	}
+	if r.columns["brand"] {
+		p.Brand = r.Brand
+	}
+	if r.columns["manufacturer"] {
+		p.Manufacturer = r.Manufacturer
+	}
+	if r.columns["weight"] {
+		p.Weight = r.Weight
+	}
+	if r.columns["length"] {
+		p.Dimensions.Length = r.Dimensions.Length
+	}
+	if r.columns["width"] {
+		p.Dimensions.Width = r.Dimensions.Width
+	}
+	if r.columns["height"] {
+		p.Dimensions.Height = r.Dimensions.Height
+	}
 }
 
 func parseCSV(file multipart.File) ([]csvRow, error) {
@@ -114,8 +623,16 @@ func parseCSV(file multipart.File) ([]csvRow, error) {
 	}
 
 	index := map[string]int{}
+	columns := map[string]bool{}
 	for i, h := range headers {
-		index[strings.ToLower(h)] = i
+		h = strings.ToLower(strings.TrimSpace(h))
+		index[h] = i
+		columns[h] = true
+	}
+	for _, c := range requiredCSVColumns {
+		if !columns[c] {
+			return nil, fmt.Errorf("missing required column %q", c)
+		}
 	}
 
 	var rows []csvRow
@@ -128,17 +645,56 @@ func parseCSV(file multipart.File) ([]csvRow, error) {
 			return nil, fmt.Errorf("CSV read error: %v", err)
 		}
 
+		field := func(name string) string {
+			if i, ok := index[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
 		row := csvRow{
-			Name:      record[index["name"]],
-			Price:     parseFloat(record[index["price"]]),
-			Quantity:  parseInt(record[index["quantity"]]),
-			Threshold: parseInt(record[index["threshold"]]),
+			Name:        record[index["name"]],
+			Price:       parseFloat(field("price")),
+			Quantity:    parseInt(field("quantity")),
+			Threshold:   parseInt(field("threshold")),
+			Category:    field("category"),
+			SKU:         field("sku"),
+			Barcode:     field("barcode"),
+			Supplier:    field("supplier"),
+			MaxQuantity: parseInt(field("max_quantity")),
+			Status:      strings.ToLower(field("status")),
+			Cost:        parseFloat(field("cost")),
+			TaxClass:    field("tax_class"),
+			columns:     columns,
+
+			Description:  field("description"),
+			Brand:        field("brand"),
+			Manufacturer: field("manufacturer"),
+			Weight:       parseFloat(field("weight")),
+			Dimensions: models.Dimensions{
+				Length: parseFloat(field("length")),
+				Width:  parseFloat(field("width")),
+				Height: parseFloat(field("height")),
+			},
 		}
 		rows = append(rows, row)
 	}
 	return rows, nil
 }
 
+// resolveTaxClass looks the row's tax class name up among classes.
+func (r *csvRow) resolveTaxClass(classes []models.TaxClass) error {
+	if r.TaxClass == "" {
+		return nil
+	}
+	for _, c := range classes {
+		if strings.EqualFold(c.Name, r.TaxClass) {
+			r.taxClassID = &c.ID
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown tax class %q", r.TaxClass)
+}
+
 func validateRow(r csvRow) error {
 	if strings.TrimSpace(r.Name) == "" {
 		return errors.New("missing name")
@@ -152,7 +708,38 @@ func validateRow(r csvRow) error {
 	if r.Threshold < 0 {
 		return errors.New("invalid threshold")
 	}
-	return nil
+	if r.MaxQuantity < 0 {
+		return errors.New("invalid max_quantity")
+	}
+	if r.Cost < 0 {
+		return errors.New("invalid cost")
+	}
+	if r.Status != "" && !models.ValidProductStatus(r.Status) {
+		return errors.New("invalid status")
+	}
+	if len(r.Description) > maxProductDescriptionLength {
+		return errors.New("description too long")
+	}
+	if len(r.Brand) > maxProductBrandLength || len(r.Manufacturer) > maxProductBrandLength {
+		return errors.New("brand or manufacturer too long")
+	}
+	if r.Weight < 0 {
+		return errors.New("invalid weight")
+	}
+	if d := r.Dimensions; d.Length < 0 || d.Width < 0 || d.Height < 0 {
+		return errors.New("invalid dimensions")
+	}
+	return rejectFormulas(
+		csvCell{"name", r.Name},
+		csvCell{"category", r.Category},
+		csvCell{"sku", r.SKU},
+		csvCell{"barcode", r.Barcode},
+		csvCell{"supplier", r.Supplier},
+		csvCell{"tax_class", r.TaxClass},
+		csvCell{"description", r.Description},
+		csvCell{"brand", r.Brand},
+		csvCell{"manufacturer", r.Manufacturer},
+	)
 }
 
 func parseFloat(s string) float64 {
@@ -160,11 +747,20 @@ func parseFloat(s string) float64 {
 	return v
 }
 
+// formatMeasure writes a weight or dimension without trailing zeros, and
+// leaves unknown (zero) ones empty.
+func formatMeasure(v float64) string {
+	if v == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
 func parseInt(s string) int {
 	v, _ := strconv.Atoi(s)
 	return v
 }
 
 func nowRFC3339() string {
-	return time.Now().Format(time.RFC3339)
+	return time.Now().UTC().Format(time.RFC3339)
 }
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/jobs"
+)
+
+const (
+	defaultIncidentLimit = 50
+	maxIncidentLimit     = 200
+)
+
+// ListJobsHandler godoc
+// @Summary Background job statuses
+// @Description Lists the background jobs with their schedule and the outcome of their latest run since the server started.
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} jobs.Status
+// @Router /admin/jobs [get]
+func ListJobsHandler(w http.ResponseWriter, r *http.Request) {
+	if err := writeJSON(w, http.StatusOK, jobs.List()); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// ListIncidentsHandler godoc
+// @Summary Failed background work
+// @Description Lists, newest first, the background work that failed on every retry, such as scheduled snapshots, digests, settlements and webhook deliveries. Each incident is also emailed and published as a job_incident security event when it happens.
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param job query string false "Only incidents of this job"
+// @Param limit query int false "Maximum incidents (default 50, max 200)"
+// @Success 200 {array} models.Incident
+// @Failure 400 {string} string "Invalid limit"
+// @Failure 500 {string} string "Internal error"
+// @Router /admin/incidents [get]
+func ListIncidentsHandler(w http.ResponseWriter, r *http.Request) {
+	limit := defaultIncidentLimit
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		limit = min(n, maxIncidentLimit)
+	}
+
+	incidents, err := incidentRepo.List(strings.TrimSpace(r.URL.Query().Get("job")), limit)
+	if err != nil {
+		http.Error(w, "could not fetch incidents", http.StatusInternalServerError)
+		return
+	}
+	if err := writeJSON(w, http.StatusOK, incidents); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
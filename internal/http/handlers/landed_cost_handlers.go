@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+)
+
+const maxLandedCostDescriptionLength = 200
+
+// allocateLandedCost splits total across the received lines of a in
+// proportion to their value at unit cost, or to their weight: the unit
+// weight given in weights, else the product's own. Shares are
+// rounded to cents; the rounding remainder goes to the largest share so they
+// add up to total.
+func allocateLandedCost(a models.ASN, total float64, method string, weights map[int]float64) ([]models.LandedCostAllocation, error) {
+	var allocations []models.LandedCostAllocation
+	var sum float64
+	for _, l := range a.Lines {
+		if l.Received <= 0 {
+			continue
+		}
+		alloc := models.LandedCostAllocation{ProductID: l.ProductID, Received: l.Received}
+		switch method {
+		case models.LandedCostByValue:
+			p, err := productRepo.GetByID(l.ProductID)
+			if err != nil {
+				return nil, err
+			}
+			alloc.Basis = roundMoney(p.Cost * float64(l.Received))
+		case models.LandedCostByWeight:
+			weight, ok := weights[l.ProductID]
+			if !ok {
+				p, err := productRepo.GetByID(l.ProductID)
+				if err != nil {
+					return nil, err
+				}
+				if weight = p.Weight; weight <= 0 {
+					return nil, fmt.Errorf("unit_weight of product %d is required: it has no weight", l.ProductID)
+				}
+			}
+			alloc.Basis = weight * float64(l.Received)
+		}
+		sum += alloc.Basis
+		allocations = append(allocations, alloc)
+	}
+	switch {
+	case len(allocations) == 0:
+		return nil, errors.New("nothing was received to allocate the cost to")
+	case sum <= 0 && method == models.LandedCostByValue:
+		return nil, errors.New("no received product has a cost; allocate by weight instead")
+	}
+
+	largest, allocated := 0, 0.0
+	for i := range allocations {
+		allocations[i].AllocatedCost = roundMoney(total * allocations[i].Basis / sum)
+		allocated += allocations[i].AllocatedCost
+		if allocations[i].Basis > allocations[largest].Basis {
+			largest = i
+		}
+	}
+	allocations[largest].AllocatedCost = roundMoney(allocations[largest].AllocatedCost + total - allocated)
+	return allocations, nil
+}
+
+// CreateLandedCostHandler godoc
+// @Summary Allocate a landed cost to a received shipment
+// @Description Attaches freight, duty or a similar charge to a received ASN and spreads it across the received products by value (received units at unit cost) or by weight (received units at the given unit weights, or at the products' own weights). Each product's share raises its unit cost, spread over the units on hand, so stock valuation includes it.
+// @Tags receiving
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ASN ID"
+// @Param cost body LandedCostRequest true "Charge and allocation method"
+// @Success 201 {object} models.LandedCost
+// @Failure 400 {object} ErrorResponse "Invalid input"
+// @Failure 403 {object} ErrorResponse "Admins only"
+// @Failure 404 {object} ErrorResponse "ASN not found"
+// @Failure 409 {object} ErrorResponse "ASN not received yet or period closed"
+// @Failure 500 {object} ErrorResponse "Internal error"
+// @Router /asns/{id}/landed-costs [post]
+func CreateLandedCostHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidInput, "invalid ASN ID")
+		return
+	}
+	var req LandedCostRequest
+	if err := readJSON(w, r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidInput, "invalid input")
+		return
+	}
+	lc := models.LandedCost{
+		ASNID:       id,
+		Description: strings.TrimSpace(req.Description),
+		TotalCost:   roundMoney(req.TotalCost),
+		Method:      req.Method,
+	}
+	switch {
+	case lc.Description == "":
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidInput, "description is required")
+		return
+	case len(lc.Description) > maxLandedCostDescriptionLength:
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidInput, fmt.Sprintf("description must be at most %d characters", maxLandedCostDescriptionLength))
+		return
+	case lc.TotalCost <= 0:
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidInput, "total_cost must be greater than zero")
+		return
+	case lc.Method != models.LandedCostByValue && lc.Method != models.LandedCostByWeight:
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidInput, "method must be value or weight")
+		return
+	}
+	weights := map[int]float64{}
+	for _, uw := range req.Weights {
+		if uw.UnitWeight <= 0 {
+			writeError(w, http.StatusBadRequest, ErrCodeInvalidInput, "unit weights must be positive")
+			return
+		}
+		weights[uw.ProductID] = uw.UnitWeight
+	}
+
+	a, err := asnRepo.GetByID(id)
+	if err != nil {
+		writeASNError(w, err)
+		return
+	}
+	if a.Status != models.ASNStatusReceived {
+		writeError(w, http.StatusConflict, ErrCodeConflict, "ASN has not been received yet")
+		return
+	}
+	if err := ensurePeriodOpen(time.Now().UTC()); err != nil {
+		if errors.Is(err, errPeriodClosed) {
+			writeError(w, http.StatusConflict, ErrCodeConflict, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "could not verify accounting period")
+		return
+	}
+
+	if lc.Allocations, err = allocateLandedCost(a, lc.TotalCost, lc.Method, weights); err != nil {
+		if errors.Is(err, repo.ErrProductNotFound) {
+			writeError(w, http.StatusNotFound, ErrCodeNotFound, err.Error())
+			return
+		}
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidInput, err.Error())
+		return
+	}
+	if lc.CreatedBy, err = GetUsernameFromContext(r); err != nil {
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "Internal error")
+		return
+	}
+	created, err := landedCostRepo.Create(lc)
+	if err != nil {
+		writeASNError(w, err)
+		return
+	}
+
+	recordAudit(r, "create", "landed_cost", created.ID, created)
+	if err := writeJSON(w, http.StatusCreated, created); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// ListLandedCostsHandler godoc
+// @Summary List the landed costs of a shipment
+// @Tags receiving
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ASN ID"
+// @Success 200 {array} models.LandedCost
+// @Failure 400 {object} ErrorResponse "Invalid ID"
+// @Failure 403 {string} string "Missing pricing:read permission"
+// @Failure 404 {object} ErrorResponse "ASN not found"
+// @Failure 500 {object} ErrorResponse "Internal error"
+// @Router /asns/{id}/landed-costs [get]
+func ListLandedCostsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidInput, "invalid ASN ID")
+		return
+	}
+	if _, err := asnRepo.GetByID(id); err != nil {
+		writeASNError(w, err)
+		return
+	}
+
+	costs, err := landedCostRepo.List(id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "could not fetch landed costs")
+		return
+	}
+	if err := writeJSON(w, http.StatusOK, costs); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
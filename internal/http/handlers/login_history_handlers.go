@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+const (
+	defaultLoginHistoryLimit = 50
+	maxLoginHistoryLimit     = 200
+)
+
+// recordLogin adds an attempt to log in as username to the login history.
+// Failures are logged rather than returned: they must not decide the login.
+func recordLogin(r *http.Request, username, outcome, reason string) {
+	err := loginEventRepo.Record(models.LoginEvent{
+		Username:  username,
+		IPAddress: clientIP(r),
+		UserAgent: r.UserAgent(),
+		Outcome:   outcome,
+		Reason:    reason,
+	})
+	if err != nil {
+		log.Printf("failed to record %s login of %q: %v", outcome, username, err)
+	}
+}
+
+// MeLoginsHandler godoc
+// @Summary Login history of the current user
+// @Description Lists every successful and failed attempt to log in to the caller's account, newest first, so unfamiliar ones stand out. Unlike the list of open sessions, it also covers failed attempts and sessions that have ended.
+// @Tags auth
+// @Security BearerAuth
+// @Produce json
+// @Param limit query int false "Maximum logins (default 50, max 200)"
+// @Param offset query int false "Logins to skip"
+// @Success 200 {object} LoginHistoryPage
+// @Failure 400 {string} string "Invalid limit or offset"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 500 {string} string "Internal error"
+// @Router /me/logins [get]
+func MeLoginsHandler(w http.ResponseWriter, r *http.Request) {
+	username, err := GetUsernameFromContext(r)
+	if err != nil || username == "" {
+		http.Error(w, "invalid token", http.StatusUnauthorized)
+		return
+	}
+	writeLoginHistory(w, r, username)
+}
+
+// UserLoginsHandler godoc
+// @Summary Login history of a user
+// @Description Lists every successful and failed attempt to log in as the user, newest first, whether or not the sessions they opened are still live. Failed attempts are kept under the username that was tried, so names without an account can be looked up too.
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param username path string true "Username"
+// @Param limit query int false "Maximum logins (default 50, max 200)"
+// @Param offset query int false "Logins to skip"
+// @Success 200 {object} LoginHistoryPage
+// @Failure 400 {string} string "Invalid limit or offset"
+// @Failure 500 {string} string "Internal error"
+// @Router /admin/users/{username}/logins [get]
+func UserLoginsHandler(w http.ResponseWriter, r *http.Request) {
+	writeLoginHistory(w, r, chi.URLParam(r, "username"))
+}
+
+func writeLoginHistory(w http.ResponseWriter, r *http.Request, username string) {
+	q := r.URL.Query()
+	limit := defaultLoginHistoryLimit
+	if raw := q.Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		limit = min(n, maxLoginHistoryLimit)
+	}
+	offset := 0
+	if raw := q.Get("offset"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			http.Error(w, "offset must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+		offset = n
+	}
+
+	// One extra login tells whether another page follows.
+	logins, err := loginEventRepo.List(username, limit+1, offset)
+	if err != nil {
+		http.Error(w, "could not retrieve login history", http.StatusInternalServerError)
+		return
+	}
+	page := LoginHistoryPage{Logins: logins, Limit: limit, Offset: offset}
+	if len(logins) > limit {
+		page.Logins, page.HasMore = logins[:limit], true
+	}
+	if err := writeJSON(w, http.StatusOK, page); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+)
+
+// CreateLotHandler godoc
+// @Summary Record a lot of a product with its expiry date
+// @Tags lots
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param lot body LotRequest true "Lot data"
+// @Success 201 {object} models.Lot
+// @Failure 400 {string} string "Invalid input"
+// @Failure 404 {string} string "Product not found"
+// @Failure 409 {string} string "Lot number already recorded for this product"
+// @Failure 500 {string} string "Internal error"
+// @Router /products/{id}/lots [post]
+func CreateLotHandler(w http.ResponseWriter, r *http.Request) {
+	productID, err := parseID(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid product ID", http.StatusBadRequest)
+		return
+	}
+	if _, err := productRepo.GetByID(productID); err != nil {
+		http.Error(w, "product not found", http.StatusNotFound)
+		return
+	}
+	if !productAccessible(w, r, productID) {
+		return
+	}
+
+	var req LotRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	req.LotNumber = strings.TrimSpace(req.LotNumber)
+	if req.LotNumber == "" {
+		http.Error(w, "lot_number is required", http.StatusBadRequest)
+		return
+	}
+	if req.Quantity < 0 {
+		http.Error(w, "quantity cannot be negative", http.StatusBadRequest)
+		return
+	}
+	expiresAt, err := parseDate(req.ExpiresAt)
+	if err != nil || req.ExpiresAt == "" {
+		http.Error(w, "expires_at must be a date (YYYY-MM-DD or RFC3339)", http.StatusBadRequest)
+		return
+	}
+
+	lot, err := lotRepo.Create(models.Lot{
+		ProductID: productID,
+		LotNumber: req.LotNumber,
+		Quantity:  req.Quantity,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		if errors.Is(err, repo.ErrDuplicatedValueUnique) {
+			http.Error(w, "lot number already recorded for this product", http.StatusConflict)
+			return
+		}
+		http.Error(w, "could not save lot", http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(r, "create", "lot", lot.ID, lot)
+	if err := writeJSON(w, http.StatusCreated, lot); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// ListLotsHandler godoc
+// @Summary List the lots of a product, soonest expiry first
+// @Tags lots
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Product ID"
+// @Success 200 {array} models.Lot
+// @Failure 400 {string} string "Invalid input"
+// @Failure 404 {string} string "Product not found"
+// @Failure 500 {string} string "Internal error"
+// @Router /products/{id}/lots [get]
+func ListLotsHandler(w http.ResponseWriter, r *http.Request) {
+	productID, err := parseID(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid product ID", http.StatusBadRequest)
+		return
+	}
+	if !productAccessible(w, r, productID) {
+		return
+	}
+
+	lots, err := lotRepo.GetByProductID(productID)
+	if err != nil {
+		http.Error(w, "could not fetch lots", http.StatusInternalServerError)
+		return
+	}
+	if err := writeJSON(w, http.StatusOK, lots); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// UpdateLotQuantityHandler godoc
+// @Summary Set the remaining quantity of a lot
+// @Tags lots
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param lotId path int true "Lot ID"
+// @Param body body LotQuantityRequest true "Remaining quantity"
+// @Success 200 {object} models.Lot
+// @Failure 400 {string} string "Invalid input"
+// @Failure 404 {string} string "Lot not found"
+// @Failure 500 {string} string "Internal error"
+// @Router /products/{id}/lots/{lotId} [put]
+func UpdateLotQuantityHandler(w http.ResponseWriter, r *http.Request) {
+	productID, err := parseID(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid product ID", http.StatusBadRequest)
+		return
+	}
+	lotID, err := parseID(chi.URLParam(r, "lotId"))
+	if err != nil {
+		http.Error(w, "invalid lot ID", http.StatusBadRequest)
+		return
+	}
+
+	var req LotQuantityRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Quantity < 0 {
+		http.Error(w, "quantity cannot be negative", http.StatusBadRequest)
+		return
+	}
+
+	lot, err := lotRepo.GetByID(lotID)
+	if err != nil || lot.ProductID != productID {
+		if err != nil && !errors.Is(err, repo.ErrLotNotFound) {
+			http.Error(w, "could not fetch lot", http.StatusInternalServerError)
+			return
+		}
+		http.Error(w, "lot not found", http.StatusNotFound)
+		return
+	}
+	if !productAccessible(w, r, productID) {
+		return
+	}
+
+	updated, err := lotRepo.UpdateQuantity(lotID, req.Quantity)
+	if err != nil {
+		http.Error(w, "could not update lot", http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(r, "update", "lot", lotID, map[string]any{"before": lot, "after": updated})
+	if err := writeJSON(w, http.StatusOK, updated); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// ExpiringLotsHandler godoc
+// @Summary Report lots with stock left that expired or expire soon
+// @Tags lots
+// @Security BearerAuth
+// @Produce json
+// @Param within query string false "Look-ahead window, e.g. 30d or 72h (default 30d)"
+// @Success 200 {array} ExpiringLot
+// @Failure 400 {string} string "Invalid input"
+// @Failure 500 {string} string "Internal error"
+// @Router /reports/expiring [get]
+func ExpiringLotsHandler(w http.ResponseWriter, r *http.Request) {
+	within, err := parseWithin(r.URL.Query().Get("within"), defaultExpiryWindow)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now().UTC()
+	lots, err := lotRepo.GetExpiringBefore(now.Add(within))
+	if err != nil {
+		http.Error(w, "could not fetch lots", http.StatusInternalServerError)
+		return
+	}
+
+	names := map[int]string{}
+	report := []ExpiringLot{}
+	for _, l := range lots {
+		if _, ok := names[l.ProductID]; !ok {
+			p, err := productRepo.GetByID(l.ProductID)
+			if err == nil {
+				names[l.ProductID] = p.Name
+			}
+		}
+		report = append(report, ExpiringLot{
+			Lot:         l,
+			ProductName: names[l.ProductID],
+			Expired:     l.ExpiresAt.Before(now),
+			DaysLeft:    int(l.ExpiresAt.Sub(now).Hours() / 24),
+		})
+	}
+
+	if err := writeJSON(w, http.StatusOK, report); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
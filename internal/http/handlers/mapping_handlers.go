@@ -0,0 +1,339 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+)
+
+const (
+	maxMappingSystemLength = 50
+	maxMappingCodeLength   = 255
+)
+
+// normalizeMapping trims the request and lowercases the system name, so that
+// "SAP" and "sap" name the same system. Codes are kept as sent: external
+// systems are free to treat them case-sensitively.
+func normalizeMapping(req MappingRequest) (models.ProductMapping, error) {
+	m := models.ProductMapping{
+		ProductID:    req.ProductID,
+		System:       strings.ToLower(strings.TrimSpace(req.System)),
+		ExternalCode: strings.TrimSpace(req.ExternalCode),
+	}
+	switch {
+	case m.ProductID <= 0:
+		return m, errors.New("product_id is required")
+	case m.System == "":
+		return m, errors.New("system is required")
+	case len(m.System) > maxMappingSystemLength:
+		return m, fmt.Errorf("system must be at most %d characters", maxMappingSystemLength)
+	case m.ExternalCode == "":
+		return m, errors.New("external_code is required")
+	case len(m.ExternalCode) > maxMappingCodeLength:
+		return m, fmt.Errorf("external_code must be at most %d characters", maxMappingCodeLength)
+	}
+	return m, nil
+}
+
+// CreateMappingHandler godoc
+// @Summary Link a product to its code in an external system
+// @Tags integrations
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param mapping body MappingRequest true "Mapping data"
+// @Success 201 {object} models.ProductMapping
+// @Failure 400 {string} string "Invalid input"
+// @Failure 404 {string} string "Product not found"
+// @Failure 409 {string} string "Code already mapped in this system"
+// @Failure 500 {string} string "Internal error"
+// @Router /integrations/mappings [post]
+func CreateMappingHandler(w http.ResponseWriter, r *http.Request) {
+	var req MappingRequest
+	if err := readJSON(w, r, &req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	m, err := normalizeMapping(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	created, err := mappingRepo.Create(m)
+	if err != nil {
+		writeMappingError(w, err)
+		return
+	}
+
+	recordAudit(r, "create", "mapping", created.ID, created)
+	if err := writeJSON(w, http.StatusCreated, created); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// ListMappingsHandler godoc
+// @Summary List external system mappings
+// @Tags integrations
+// @Security BearerAuth
+// @Produce json
+// @Param product_id query int false "Only mappings of this product"
+// @Param system query string false "Only mappings in this system"
+// @Success 200 {array} models.ProductMapping
+// @Failure 400 {string} string "Invalid input"
+// @Failure 500 {string} string "Internal error"
+// @Router /integrations/mappings [get]
+func ListMappingsHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	productID := 0
+	if v := q.Get("product_id"); v != "" {
+		id, err := parseID(v)
+		if err != nil {
+			http.Error(w, "invalid product_id", http.StatusBadRequest)
+			return
+		}
+		productID = id
+	}
+
+	mappings, err := mappingRepo.List(productID, strings.ToLower(strings.TrimSpace(q.Get("system"))))
+	if err != nil {
+		http.Error(w, "could not fetch mappings", http.StatusInternalServerError)
+		return
+	}
+	if err := writeJSON(w, http.StatusOK, mappings); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// GetMappingHandler godoc
+// @Summary Get an external system mapping
+// @Tags integrations
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Mapping ID"
+// @Success 200 {object} models.ProductMapping
+// @Failure 400 {string} string "Invalid ID"
+// @Failure 404 {string} string "Mapping not found"
+// @Failure 500 {string} string "Internal error"
+// @Router /integrations/mappings/{id} [get]
+func GetMappingHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid mapping ID", http.StatusBadRequest)
+		return
+	}
+
+	m, err := mappingRepo.GetByID(id)
+	if err != nil {
+		writeMappingError(w, err)
+		return
+	}
+	if err := writeJSON(w, http.StatusOK, m); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// UpdateMappingHandler godoc
+// @Summary Change an external system mapping
+// @Tags integrations
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Mapping ID"
+// @Param mapping body MappingRequest true "Mapping data"
+// @Success 200 {object} models.ProductMapping
+// @Failure 400 {string} string "Invalid input"
+// @Failure 404 {string} string "Mapping or product not found"
+// @Failure 409 {string} string "Code already mapped in this system"
+// @Failure 500 {string} string "Internal error"
+// @Router /integrations/mappings/{id} [put]
+func UpdateMappingHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid mapping ID", http.StatusBadRequest)
+		return
+	}
+
+	var req MappingRequest
+	if err := readJSON(w, r, &req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	m, err := normalizeMapping(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	before, err := mappingRepo.GetByID(id)
+	if err != nil {
+		writeMappingError(w, err)
+		return
+	}
+	m.ID = id
+	updated, err := mappingRepo.Update(m)
+	if err != nil {
+		writeMappingError(w, err)
+		return
+	}
+
+	recordAudit(r, "update", "mapping", id, map[string]any{"before": before, "after": updated})
+	if err := writeJSON(w, http.StatusOK, updated); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// DeleteMappingHandler godoc
+// @Summary Remove an external system mapping
+// @Tags integrations
+// @Security BearerAuth
+// @Param id path int true "Mapping ID"
+// @Success 204 "No Content"
+// @Failure 400 {string} string "Invalid ID"
+// @Failure 404 {string} string "Mapping not found"
+// @Failure 500 {string} string "Internal error"
+// @Router /integrations/mappings/{id} [delete]
+func DeleteMappingHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid mapping ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := mappingRepo.Delete(id); err != nil {
+		writeMappingError(w, err)
+		return
+	}
+
+	recordAudit(r, "delete", "mapping", id, nil)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// BulkUpsertMappingsHandler godoc
+// @Summary Upload many external system mappings at once
+// @Description Creates each mapping, or re-points it to the given product when the code is already mapped in that system. Nothing is written if any item is invalid.
+// @Tags integrations
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param mappings body []MappingRequest true "Mappings"
+// @Success 200 {object} BulkUpsertResult
+// @Failure 400 {string} string "Invalid input"
+// @Failure 404 {string} string "Product not found"
+// @Failure 500 {string} string "Internal error"
+// @Router /integrations/mappings/bulk [post]
+func BulkUpsertMappingsHandler(w http.ResponseWriter, r *http.Request) {
+	var reqs []MappingRequest
+	if err := readBulkJSON(w, r, &reqs); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	mappings := make([]models.ProductMapping, len(reqs))
+	for i, req := range reqs {
+		m, err := normalizeMapping(req)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("item %d: %v", i, err), http.StatusBadRequest)
+			return
+		}
+		mappings[i] = m
+	}
+
+	n, err := mappingRepo.Upsert(mappings)
+	if err != nil {
+		writeMappingError(w, err)
+		return
+	}
+
+	recordAudit(r, "bulk_upsert", "mapping", nil, map[string]any{"count": n})
+	if err := writeJSON(w, http.StatusOK, BulkUpsertResult{Upserted: n}); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// ResolveMappingHandler godoc
+// @Summary Resolve a product through an external system
+// @Description Given code, finds the product holding that code in the system. Given product_id, lists the product's codes in the system. Exactly one of the two must be set.
+// @Tags integrations
+// @Security BearerAuth
+// @Produce json
+// @Param system query string true "External system"
+// @Param code query string false "Code in the external system"
+// @Param product_id query int false "Internal product ID"
+// @Success 200 {object} MappingResolution
+// @Failure 400 {string} string "Invalid input"
+// @Failure 404 {string} string "Mapping or product not found"
+// @Failure 500 {string} string "Internal error"
+// @Router /integrations/mappings/resolve [get]
+func ResolveMappingHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	system := strings.ToLower(strings.TrimSpace(q.Get("system")))
+	code := strings.TrimSpace(q.Get("code"))
+	rawProductID := q.Get("product_id")
+	if system == "" {
+		http.Error(w, "system is required", http.StatusBadRequest)
+		return
+	}
+	if (code == "") == (rawProductID == "") {
+		http.Error(w, "exactly one of code or product_id is required", http.StatusBadRequest)
+		return
+	}
+
+	var (
+		productID int
+		mappings  []models.ProductMapping
+	)
+	if code != "" {
+		m, err := mappingRepo.Resolve(system, code)
+		if err != nil {
+			writeMappingError(w, err)
+			return
+		}
+		productID = m.ProductID
+		mappings = []models.ProductMapping{m}
+	} else {
+		id, err := strconv.Atoi(rawProductID)
+		if err != nil || id <= 0 {
+			http.Error(w, "invalid product_id", http.StatusBadRequest)
+			return
+		}
+		if mappings, err = mappingRepo.List(id, system); err != nil {
+			http.Error(w, "could not fetch mappings", http.StatusInternalServerError)
+			return
+		}
+		if len(mappings) == 0 {
+			http.Error(w, "mapping not found", http.StatusNotFound)
+			return
+		}
+		productID = id
+	}
+
+	product, err := productRepo.GetByID(productID)
+	if err != nil {
+		writeMappingError(w, err)
+		return
+	}
+	if err := writeJSON(w, http.StatusOK, MappingResolution{Product: newProductResponse(product), Mappings: mappings}); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+func writeMappingError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, repo.ErrMappingNotFound):
+		http.Error(w, "mapping not found", http.StatusNotFound)
+	case errors.Is(err, repo.ErrProductNotFound):
+		http.Error(w, "product not found", http.StatusNotFound)
+	case errors.Is(err, repo.ErrDuplicatedValueUnique):
+		http.Error(w, "code already mapped in this system", http.StatusConflict)
+	default:
+		http.Error(w, "could not process mapping", http.StatusInternalServerError)
+	}
+}
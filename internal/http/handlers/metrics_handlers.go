@@ -1,25 +1,363 @@
 package handlers
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/fxrate"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
 )
 
 // GetDashboardMetricsHandler godoc
 // @Summary Dashboard metrics for admin view
+// @Description average_price and total_stock_value are reported in currency, converted from the currency each product is priced in at the configured exchange rates; stock_by_currency keeps the unconverted figures.
+// @Description trends compares the units moved over the last seven days with the seven before, and the value of the latest daily snapshot with the one a week older; a trend without a week-old figure is left out.
+// @Description With widgets, only the listed widgets are computed and the response is a DashboardWidgets holding them. Each widget is cached on its own for a minute.
 // @Tags metrics
 // @Produce json
+// @Param currency query string false "ISO 4217 code to report money in (default the base currency)"
+// @Param widgets query string false "Comma-separated widgets: summary, low_stock, top_movers, valuation, stock_load, low_margin, expiring, trends"
 // @Success 200 {object} repo.Metrics
+// @Failure 400 {string} string "Invalid currency or widget"
+// @Failure 422 {string} string "No exchange rate for a currency"
 // @Failure 500 {string} string "Internal error"
+// @Failure 503 {string} string "Exchange rates unavailable"
 // @Router /metrics/dashboard [get]
 func GetDashboardMetricsHandler(w http.ResponseWriter, r *http.Request) {
+	currency := exchangeRates.Base()
+	if v := r.URL.Query().Get("currency"); v != "" {
+		if currency = strings.ToUpper(strings.TrimSpace(v)); !currencyPattern.MatchString(currency) {
+			http.Error(w, "currency must be a three-letter ISO 4217 code", http.StatusBadRequest)
+			return
+		}
+	}
+	if raw := r.URL.Query().Get("widgets"); raw != "" {
+		widgets, err := parseWidgets(raw)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writeDashboardWidgets(w, widgets, currency)
+		return
+	}
+
 	m, err := metricsRepo.GetDashboardMetrics()
 	if err != nil {
 		http.Error(w, "failed to fetch metrics", http.StatusInternalServerError)
 		return
 	}
+	if err := convertMetrics(&m, currency); err != nil {
+		writeExchangeRateError(w, err)
+		return
+	}
+	m.MovementLogFailures = movementLogFailures.Load()
+	if lots, err := lotRepo.GetExpiringBefore(time.Now().UTC().Add(defaultExpiryWindow)); err == nil {
+		m.ExpiringSoonCount = len(lots)
+	} else {
+		log.Printf("failed to count expiring lots: %v", err)
+	}
+	if m.Trends, err = weeklyTrends(time.Now().UTC()); err != nil {
+		log.Printf("failed to compute trends: %v", err)
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	if err := writeJSON(w, http.StatusOK, m); err != nil {
 		log.Printf("Failed to write JSON response: %v", err)
 	}
 }
+
+const (
+	// widgetExpiring and widgetTrends are computed by the API process
+	// rather than the metrics repository, like the fields they carry.
+	widgetExpiring = "expiring"
+	widgetTrends   = "trends"
+
+	// trendWindow is the period trends compare with the one before it.
+	trendWindow = 7 * 24 * time.Hour
+	// trendSlack lets a daily snapshot taken a little late still count as
+	// a week older than the latest one.
+	trendSlack = time.Hour
+
+	dashboardCacheTTL    = time.Minute
+	dashboardCachePrefix = "dashboard:"
+)
+
+// dashboardWidgets lists the widgets GET /metrics/dashboard?widgets= accepts.
+var dashboardWidgets = slices.Concat(repo.DashboardWidgets, []string{widgetExpiring, widgetTrends})
+
+// parseWidgets reads the comma-separated widgets parameter, dropping
+// repeats.
+func parseWidgets(raw string) ([]string, error) {
+	var widgets []string
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !slices.Contains(dashboardWidgets, name) {
+			return nil, fmt.Errorf("widgets accepts %s", strings.Join(dashboardWidgets, ", "))
+		}
+		if !slices.Contains(widgets, name) {
+			widgets = append(widgets, name)
+		}
+	}
+	return widgets, nil
+}
+
+// writeDashboardWidgets computes each of widgets on its own, money in
+// currency, and writes them.
+func writeDashboardWidgets(w http.ResponseWriter, widgets []string, currency string) {
+	var resp DashboardWidgets
+	for _, name := range widgets {
+		m, err := dashboardWidget(name)
+		if err != nil {
+			log.Printf("failed to compute dashboard widget %s: %v", name, err)
+			http.Error(w, "failed to fetch metrics", http.StatusInternalServerError)
+			return
+		}
+		switch name {
+		case repo.WidgetSummary:
+			resp.Summary = &SummaryWidget{
+				TotalProducts:       m.TotalProducts,
+				TotalMovements:      m.TotalMovements,
+				TotalQuantity:       m.TotalQuantity,
+				MovementLogFailures: movementLogFailures.Load(),
+			}
+		case repo.WidgetLowStock:
+			resp.LowStock = &LowStockWidget{LowStockCount: m.LowStockCount}
+		case repo.WidgetTopMovers:
+			resp.TopMovers = &TopMoversWidget{MostMovedProduct: m.MostMovedProduct, Top5Movers: m.Top5Movers}
+		case repo.WidgetValuation:
+			if err := convertMetrics(&m, currency); err != nil {
+				writeExchangeRateError(w, err)
+				return
+			}
+			resp.Valuation = &ValuationWidget{
+				Currency:        m.Currency,
+				AveragePrice:    m.AveragePrice,
+				TotalStockValue: m.TotalStockValue,
+				StockByCurrency: m.StockByCurrency,
+			}
+		case repo.WidgetStockLoad:
+			resp.StockLoad = &StockLoadWidget{TotalStockWeight: m.TotalStockWeight, TotalStockVolume: m.TotalStockVolume}
+		case repo.WidgetLowMargin:
+			resp.LowMargin = &LowMarginWidget{LowMarginMovers: m.LowMarginMovers}
+		case widgetExpiring:
+			resp.Expiring = &ExpiringWidget{ExpiringSoonCount: m.ExpiringSoonCount}
+		case widgetTrends:
+			resp.Trends = m.Trends
+		}
+	}
+
+	w.Header().Set("Cache-Control", fmt.Sprintf("private, max-age=%d", int(dashboardCacheTTL.Seconds())))
+	if err := writeJSON(w, http.StatusOK, resp); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// dashboardWidget returns the metrics of one widget, from the cache when it
+// was computed less than dashboardCacheTTL ago. Money is cached unconverted.
+func dashboardWidget(name string) (repo.Metrics, error) {
+	key := fmt.Sprintf("%s%s:%s", dashboardCachePrefix, tenant, name)
+	if cached, err := Rdb.Get(Ctx, key).Bytes(); err == nil {
+		var m repo.Metrics
+		if err := json.Unmarshal(cached, &m); err == nil {
+			return m, nil
+		}
+	}
+
+	var m repo.Metrics
+	var err error
+	switch name {
+	case widgetExpiring:
+		lots, err := lotRepo.GetExpiringBefore(time.Now().UTC().Add(defaultExpiryWindow))
+		if err != nil {
+			return m, err
+		}
+		m.ExpiringSoonCount = len(lots)
+	case widgetTrends:
+		if m.Trends, err = weeklyTrends(time.Now().UTC()); err != nil {
+			return m, err
+		}
+	default:
+		if m, err = metricsRepo.GetDashboardMetrics(name); err != nil {
+			return m, err
+		}
+	}
+	if raw, err := json.Marshal(m); err == nil {
+		_ = Rdb.Set(Ctx, key, raw, dashboardCacheTTL).Err()
+	}
+	return m, nil
+}
+
+// weeklyTrends compares the units moved over the week before now with the
+// week before that, and the stock value of the latest daily snapshot with
+// the one taken a week earlier.
+func weeklyTrends(now time.Time) (*repo.Trends, error) {
+	weekAgo := now.Add(-trendWindow)
+	var volume [2]int
+	for i, since := range []time.Time{weekAgo, weekAgo.Add(-trendWindow)} {
+		movements, err := movementRepo.GetBetween(since, since.Add(trendWindow))
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range movements {
+			volume[i] += max(m.Delta, -m.Delta)
+		}
+	}
+	trends := &repo.Trends{MovementVolume: repo.NewTrend(float64(volume[0]), float64(volume[1]))}
+
+	snapshots, err := snapshotRepo.ListScheduledSince(weekAgo.Add(-trendWindow), false)
+	if err != nil {
+		return nil, err
+	}
+	if len(snapshots) > 0 {
+		latest := snapshots[len(snapshots)-1]
+		cutoff := latest.CreatedAt.Add(-trendWindow + trendSlack)
+		for i := len(snapshots) - 2; i >= 0; i-- {
+			if !snapshots[i].CreatedAt.After(cutoff) {
+				trends.StockValue = repo.NewTrend(roundMoney(latest.TotalValue), roundMoney(snapshots[i].TotalValue))
+				break
+			}
+		}
+	}
+	return trends, nil
+}
+
+func writeExchangeRateError(w http.ResponseWriter, err error) {
+	if errors.Is(err, fxrate.ErrUnknownCurrency) {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	log.Printf("failed to fetch exchange rates: %v", err)
+	http.Error(w, "exchange rates unavailable", http.StatusServiceUnavailable)
+}
+
+// convertMetrics restates the money figures of m in currency, summing the
+// per-currency figures at the configured rates. When everything is already
+// in currency they are left exactly as the repository computed them.
+func convertMetrics(m *repo.Metrics, currency string) error {
+	var value, prices float64
+	products, converted := 0, false
+	for i, s := range m.StockByCurrency {
+		if s.Currency == "" {
+			s.Currency = exchangeRates.Base()
+			m.StockByCurrency[i].Currency = s.Currency
+		}
+		rate, err := fxrate.Rate(exchangeRates, s.Currency, currency)
+		if err != nil {
+			return err
+		}
+		converted = converted || rate != 1
+		value += s.StockValue * rate
+		prices += s.AveragePrice * float64(s.Products) * rate
+		products += s.Products
+	}
+	m.Currency = currency
+	if !converted {
+		return nil
+	}
+	m.TotalStockValue = roundMoney(value)
+	m.AveragePrice = 0
+	if products > 0 {
+		m.AveragePrice = roundMoney(prices / float64(products))
+	}
+	return nil
+}
+
+// GetMarginsHandler godoc
+// @Summary Margin and profitability per product and category
+// @Description Margins are a percentage of the sale price. Projected profit is what the current stock would earn if sold at today's prices. Products without a cost are counted but left out of the figures.
+// @Tags metrics
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} repo.MarginReport
+// @Failure 500 {string} string "Internal error"
+// @Router /metrics/margins [get]
+func GetMarginsHandler(w http.ResponseWriter, r *http.Request) {
+	report, err := metricsRepo.GetMarginReport()
+	if err != nil {
+		http.Error(w, "failed to fetch margins", http.StatusInternalServerError)
+		return
+	}
+	if err := writeJSON(w, http.StatusOK, report); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// GetStockLoadHandler godoc
+// @Summary Weight and volume of the stock on hand, per warehouse
+// @Description Sums unit weight (kg) and volume (m³, from the dimensions in cm) over every unit on hand, split by the warehouse whose bins hold it. Units in no bin are reported as unplaced. Products missing a weight or dimensions are counted so the totals can be read as lower bounds.
+// @Tags metrics
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} repo.StockLoadReport
+// @Failure 500 {string} string "Internal error"
+// @Router /metrics/stock-load [get]
+func GetStockLoadHandler(w http.ResponseWriter, r *http.Request) {
+	report, err := metricsRepo.GetStockLoadReport()
+	if err != nil {
+		http.Error(w, "failed to fetch stock load", http.StatusInternalServerError)
+		return
+	}
+	if err := writeJSON(w, http.StatusOK, report); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// ExportValuationHandler godoc
+// @Summary Export the valuation of owned stock
+// @Description One CSV row per product: units on hand, units still belonging to a consignment supplier, and the owned units valued at unit cost. With encryption=zip the file comes in a ZIP archive protected with the password in the X-Export-Password header; with encryption=pgp it is encrypted to the PGP key of the export recipient recipient_id and emailed to them.
+// @Tags metrics
+// @Security BearerAuth
+// @Produce text/csv, application/zip, application/json
+// @Param encryption query string false "zip or pgp"
+// @Param recipient_id query int false "Export recipient, for pgp"
+// @Param X-Export-Password header string false "Archive password, for zip (at least 12 characters; the archive is only as strong as the password, so use a long random one)"
+// @Success 200 {file} file
+// @Success 202 {object} ExportDelivery "Encrypted and being emailed"
+// @Failure 400 {string} string "Invalid input"
+// @Failure 404 {string} string "Export recipient not found"
+// @Failure 500 {string} string "Internal error"
+// @Router /metrics/valuation/export [get]
+func ExportValuationHandler(w http.ResponseWriter, r *http.Request) {
+	products, err := productRepo.GetAll()
+	if err != nil {
+		http.Error(w, "could not fetch products", http.StatusInternalServerError)
+		return
+	}
+	stock, err := consignmentRepo.List()
+	if err != nil {
+		http.Error(w, "could not fetch consignment stock", http.StatusInternalServerError)
+		return
+	}
+	consigned := map[int]int{}
+	for _, c := range stock {
+		consigned[c.ProductID] += c.Quantity
+	}
+
+	var buf bytes.Buffer
+	csvWriter := newSafeCSVWriter(&buf)
+	_ = csvWriter.Write([]string{"product_id", "name", "sku", "category", "quantity", "consigned", "owned", "unit_cost", "value"})
+	for _, p := range products {
+		owned := max(p.Quantity-consigned[p.ID], 0)
+		_ = csvWriter.Write([]string{
+			strconv.Itoa(p.ID),
+			p.Name,
+			p.SKU,
+			p.Category,
+			strconv.Itoa(p.Quantity),
+			strconv.Itoa(consigned[p.ID]),
+			strconv.Itoa(owned),
+			strconv.FormatFloat(p.Cost, 'f', 2, 64),
+			strconv.FormatFloat(roundMoney(float64(owned)*p.Cost), 'f', 2, 64),
+		})
+	}
+	csvWriter.Flush()
+	deliverExport(w, r, "valuation_"+time.Now().UTC().Format("2006-01-02")+".csv", "text/csv", buf.Bytes())
+}
@@ -1,19 +1,26 @@
 package handlers
 
 import (
-	"encoding/csv"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"net/url"
+	"slices"
 	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
 	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+	"github.com/rogerio-castellano/inventory-tracker/internal/webhook"
 )
 
 // AdjustQuantityHandler godoc
 // @Summary Adjust quantity of a product
+// @Description With a warehouse_id, the warehouse's stock of the product changes along with its total. A reduction cannot take units held by reservations. An adjustment larger than the product's adjustment limits is answered with warnings, or refused above the maximum unless the caller may override it.
 // @Tags inventory
 // @Accept json
 // @Produce json
@@ -21,7 +28,10 @@ import (
 // @Param adjustment body QuantityAdjustmentRequest true "Quantity change"
 // @Success 200 {object} ProductResponse
 // @Failure 400 {string} string "Invalid adjustment"
+// @Failure 403 {string} string "Warehouse outside the caller's grants"
 // @Failure 404 {string} string "Not found"
+// @Failure 409 {string} string "Quantity would become negative or drop below reserved stock, period closed or external ID taken"
+// @Failure 422 {string} string "Adjustment larger than the product's maximum"
 // @Failure 500 {string} string "Internal error"
 // @Router /products/{id}/adjust [post]
 // @Security BearerAuth
@@ -39,34 +49,305 @@ func AdjustQuantityHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	product, err := productRepo.AdjustQuantity(id, req.Delta)
+	if req.Reason, req.Note, err = movementReason(req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !adjustmentInScope(w, r, id, req.WarehouseID) {
+		return
+	}
+	warnings, err := checkProductAdjustment(r, id, req.Delta)
 	if err != nil {
-		if err == repo.ErrInvalidQuantityChange {
-			http.Error(w, "quantity cannot be negative", http.StatusConflict)
+		writeAdjustmentLimitError(w, err)
+		return
+	}
+
+	occurredAt := time.Now().UTC()
+	if req.OccurredAt != "" {
+		t, err := parseTime(req.OccurredAt)
+		if err != nil {
+			http.Error(w, "invalid occurred_at date format", http.StatusBadRequest)
+			return
+		}
+		if t.After(time.Now()) {
+			http.Error(w, "occurred_at cannot be in the future", http.StatusBadRequest)
+			return
+		}
+		occurredAt = t.UTC()
+	}
+
+	if req.ExternalID != "" {
+		var ok bool
+		if req.ExternalID, ok = normalizeUUID(req.ExternalID); !ok {
+			http.Error(w, "external_id must be a UUID", http.StatusBadRequest)
+			return
+		}
+		if _, err := movementRepo.GetByExternalID(req.ExternalID); err == nil {
+			http.Error(w, "a movement with this external_id already exists", http.StatusConflict)
+			return
+		} else if !errors.Is(err, repo.ErrMovementNotFound) {
+			http.Error(w, "could not verify external_id", http.StatusInternalServerError)
 			return
 		}
-		http.Error(w, "could not update quantity", http.StatusInternalServerError)
+	}
+
+	if err := ensurePeriodOpen(occurredAt); err != nil {
+		if errors.Is(err, errPeriodClosed) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		http.Error(w, "could not verify accounting period", http.StatusInternalServerError)
+		return
+	}
+
+	movement := models.Movement{ProductID: id, Delta: req.Delta, CreatedAt: occurredAt.Format(time.RFC3339), ExternalID: req.ExternalID, WarehouseID: req.WarehouseID, Reason: req.Reason, Note: req.Note}
+	var product models.Product
+	if req.WarehouseID != nil {
+		// The warehouse's stock must change with the total, so the movement
+		// is logged in the same transaction.
+		product, err = productRepo.AdjustWithMovements(id, []models.Movement{movement})
+	} else {
+		product, err = productRepo.AdjustQuantity(id, req.Delta)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, repo.ErrStockReserved):
+			http.Error(w, "the units are reserved for pending orders", http.StatusConflict)
+		case errors.Is(err, repo.ErrInvalidQuantityChange):
+			http.Error(w, "quantity cannot be negative", http.StatusConflict)
+		case errors.Is(err, repo.ErrWarehouseNotFound):
+			http.Error(w, "warehouse not found", http.StatusNotFound)
+		case errors.Is(err, repo.ErrDuplicatedExternalID):
+			http.Error(w, "a movement with this external_id already exists", http.StatusConflict)
+		default:
+			http.Error(w, "could not update quantity", http.StatusInternalServerError)
+		}
 		return
 	}
-	_ = movementRepo.Log(id, req.Delta)
+	if req.WarehouseID == nil {
+		var logged models.Movement
+		if logged, err = movementRepo.Log(movement); err == nil {
+			movement = logged
+		}
+		recordMovementLog(id, req.Delta, err)
+	}
+	webhook.Publish(webhook.EventMovementCreated, movement)
+	publishStockLevel(product, req.Delta)
+	details := map[string]any{"delta": req.Delta, "quantity": product.Quantity, "occurred_at": occurredAt.Format(time.RFC3339)}
+	if req.WarehouseID != nil {
+		details["warehouse_id"] = *req.WarehouseID
+	}
+	if req.Reason != "" {
+		details["reason"] = req.Reason
+	}
+	if len(warnings) > 0 {
+		details["warnings"] = warnings
+	}
+	recordAudit(r, "adjust", "product", id, details)
+	if len(warnings) > 0 {
+		flagProduct(r, id, models.FlagSourceAnomaly, strings.Join(warnings, "; "))
+	}
 
 	if product.Quantity < product.Threshold {
 		log.Printf("⚠️ ALERT: Product %d (%s) is below threshold! Qty=%d, Threshold=%d",
 			product.ID, product.Name, product.Quantity, product.Threshold)
 	}
 
-	resp := ProductResponse{
-		Id:        product.ID,
-		Name:      product.Name,
-		Price:     product.Price,
-		Quantity:  product.Quantity,
-		Threshold: product.Threshold,
-		LowStock:  product.Quantity < product.Threshold,
+	resp := newProductResponse(product)
+	if product.Quantity < product.Threshold {
+		resp.LowStock = true
+	}
+	resp.Warnings = warnings
+	resps := []ProductResponse{resp}
+	if err := withAvailability(resps); err != nil {
+		log.Printf("failed to read reservations of product %d: %v", id, err)
+	}
+	if err := writeJSON(w, http.StatusOK, resps[0]); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// adjustmentInScope checks that the caller may adjust the product's stock
+// in the warehouse, writing the error response itself if not.
+func adjustmentInScope(w http.ResponseWriter, r *http.Request, productID int, warehouseID *int) bool {
+	scope, err := accessScope(r)
+	if err != nil {
+		http.Error(w, "could not load access grants", http.StatusInternalServerError)
+		return false
+	}
+	if err := productInScope(scope, productID); err != nil {
+		if errors.Is(err, repo.ErrProductNotFound) {
+			http.Error(w, "product not found", http.StatusNotFound)
+			return false
+		}
+		http.Error(w, "could not fetch product", http.StatusInternalServerError)
+		return false
+	}
+	if !warehouseInScope(scope, warehouseID) {
+		http.Error(w, "warehouse outside your access grants: adjust stock in a granted warehouse", http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// maxAdjustmentBatch bounds a scanner upload; larger loads belong to /admin/bulk/movements.
+const maxAdjustmentBatch = 5000
+
+// AdjustQuantityBatchHandler godoc
+// @Summary Apply a batch of quantity changes to a product
+// @Description For scanners that buffer readings offline. Every adjustment is logged as its own movement, and the product quantity is updated once by their sum, all in one transaction. Only the final quantity must not be negative, nor below the reserved stock if the batch reduces it.
+// @Tags inventory
+// @Accept json
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param adjustments body []QuantityAdjustmentRequest true "Quantity changes, each optionally timestamped"
+// @Success 200 {object} BatchAdjustmentResult
+// @Failure 400 {string} string "Invalid adjustment"
+// @Failure 403 {string} string "Warehouse outside the caller's grants"
+// @Failure 404 {string} string "Product or warehouse not found"
+// @Failure 409 {string} string "Quantity would become negative, period closed or external ID taken"
+// @Failure 422 {string} string "An adjustment larger than the product's maximum"
+// @Failure 500 {string} string "Internal error"
+// @Router /products/{id}/adjust/batch [post]
+// @Security BearerAuth
+func AdjustQuantityBatchHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid product ID", http.StatusBadRequest)
+		return
+	}
+
+	var reqs []QuantityAdjustmentRequest
+	if err := readBulkJSON(w, r, &reqs); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(reqs) > maxAdjustmentBatch {
+		http.Error(w, fmt.Sprintf("at most %d adjustments per batch", maxAdjustmentBatch), http.StatusBadRequest)
+		return
+	}
+	scope, err := accessScope(r)
+	if err != nil {
+		http.Error(w, "could not load access grants", http.StatusInternalServerError)
+		return
+	}
+	if err := productInScope(scope, id); err != nil {
+		if errors.Is(err, repo.ErrProductNotFound) {
+			http.Error(w, "product not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "could not fetch product", http.StatusInternalServerError)
+		return
+	}
+
+	limit, err := adjustmentLimitOf(id)
+	if err != nil {
+		writeAdjustmentLimitError(w, err)
+		return
+	}
+
+	now := time.Now().UTC()
+	movements := make([]models.Movement, len(reqs))
+	periods := make(map[string]time.Time)
+	externalIDs := make(map[string]bool)
+	var warnings []string
+	net := 0
+	for i, req := range reqs {
+		if req.Delta == 0 {
+			http.Error(w, fmt.Sprintf("item %d: delta must not be zero", i), http.StatusBadRequest)
+			return
+		}
+		at := now
+		if req.OccurredAt != "" {
+			t, err := time.Parse(time.RFC3339, req.OccurredAt)
+			if err != nil || t.After(now) {
+				http.Error(w, fmt.Sprintf("item %d: occurred_at must be a past RFC3339 timestamp", i), http.StatusBadRequest)
+				return
+			}
+			at = t.UTC()
+		}
+		if req.Reason, req.Note, err = movementReason(req); err != nil {
+			http.Error(w, fmt.Sprintf("item %d: %v", i, err), http.StatusBadRequest)
+			return
+		}
+		if req.ExternalID != "" {
+			var ok bool
+			if req.ExternalID, ok = normalizeUUID(req.ExternalID); !ok {
+				http.Error(w, fmt.Sprintf("item %d: external_id must be a UUID", i), http.StatusBadRequest)
+				return
+			}
+			if externalIDs[req.ExternalID] {
+				http.Error(w, fmt.Sprintf("item %d: external_id is repeated in the batch", i), http.StatusBadRequest)
+				return
+			}
+			externalIDs[req.ExternalID] = true
+		}
+		if !warehouseInScope(scope, req.WarehouseID) {
+			http.Error(w, fmt.Sprintf("item %d: warehouse outside your access grants", i), http.StatusForbidden)
+			return
+		}
+		itemWarnings, err := checkAdjustmentLimit(r, limit, req.Delta)
+		if err != nil {
+			writeAdjustmentLimitError(w, fmt.Errorf("item %d: %w", i, err))
+			return
+		}
+		for _, warning := range itemWarnings {
+			warnings = append(warnings, fmt.Sprintf("item %d: %s", i, warning))
+		}
+		movements[i] = models.Movement{ProductID: id, Delta: req.Delta, CreatedAt: at.Format(time.RFC3339), ExternalID: req.ExternalID, WarehouseID: req.WarehouseID, Reason: req.Reason, Note: req.Note}
+		periods[repo.PeriodOf(at)] = at
+		net += req.Delta
+	}
+
+	for _, at := range periods {
+		if err := ensurePeriodOpen(at); err != nil {
+			if errors.Is(err, errPeriodClosed) {
+				http.Error(w, err.Error(), http.StatusConflict)
+				return
+			}
+			http.Error(w, "could not verify accounting period", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	product, err := productRepo.AdjustWithMovements(id, movements)
+	if err != nil {
+		switch {
+		case errors.Is(err, repo.ErrStockReserved):
+			http.Error(w, "the units are reserved for pending orders", http.StatusConflict)
+		case errors.Is(err, repo.ErrInvalidQuantityChange):
+			http.Error(w, "product not found or quantity would become negative", http.StatusConflict)
+		case errors.Is(err, repo.ErrWarehouseNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case errors.Is(err, repo.ErrDuplicatedExternalID):
+			http.Error(w, "a movement with one of these external_ids already exists", http.StatusConflict)
+		default:
+			http.Error(w, "could not apply adjustments", http.StatusInternalServerError)
+		}
+		return
+	}
+	details := map[string]any{"applied": len(movements), "net_delta": net, "quantity": product.Quantity}
+	if len(warnings) > 0 {
+		details["warnings"] = warnings
+	}
+	recordAudit(r, "adjust-batch", "product", id, details)
+	if len(warnings) > 0 {
+		flagProduct(r, id, models.FlagSourceAnomaly, strings.Join(warnings, "; "))
+	}
+	events := make([]any, len(movements))
+	for i, m := range movements {
+		events[i] = m
 	}
+	webhook.Publish(webhook.EventMovementCreated, events...)
+	publishStockLevel(product, net)
+
+	resp := newProductResponse(product)
 	if product.Quantity < product.Threshold {
+		log.Printf("⚠️ ALERT: Product %d (%s) is below threshold! Qty=%d, Threshold=%d",
+			product.ID, product.Name, product.Quantity, product.Threshold)
 		resp.LowStock = true
 	}
-	if err := writeJSON(w, http.StatusOK, resp); err != nil {
+	if err := writeJSON(w, http.StatusOK, BatchAdjustmentResult{Product: resp, Applied: len(movements), NetDelta: net, Warnings: warnings}); err != nil {
 		log.Printf("Failed to write JSON response: %v", err)
 	}
 }
@@ -78,6 +359,7 @@ func AdjustQuantityHandler(w http.ResponseWriter, r *http.Request) {
 // @Param id path int true "Product ID"
 // @Param since query string false "Filter movements from this timestamp (RFC3339)"
 // @Param until query string false "Filter movements until this timestamp (RFC3339)"
+// @Param reason query string false "Only movements with this reason (sale, return, damage, recount or transfer)"
 // @Param offset query int false "Offset for pagination"
 // @Param limit query int false "Limit for pagination"
 // @Success 200 {object} MovementsSearchResult
@@ -100,31 +382,17 @@ func GetMovementsHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "product not found", status)
 		return
 	}
-
-	q := r.URL.Query()
-	since, err := parseTime(q.Get("since"))
-	if err != nil {
-		http.Error(w, "invalid since date format", http.StatusBadRequest)
-		return
-	}
-	until, err := parseTime(q.Get("until"))
-	if err != nil {
-		http.Error(w, "invalid until date format", http.StatusBadRequest)
+	if !productAccessible(w, r, id) {
 		return
 	}
 
-	limit, err := parseNonNegativeInt(q.Get("limit"))
+	filter, err := parseMovementFilter(r.URL.Query())
 	if err != nil {
-		http.Error(w, "invalid limit format", http.StatusBadRequest)
-		return
-	}
-	offset, err := parseNonNegativeInt(q.Get("offset"))
-	if err != nil {
-		http.Error(w, "invalid offset format", http.StatusBadRequest)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	movements, total, err := movementRepo.GetByProductID(id, repo.MovementFilter{Since: since, Until: until, Offset: offset, Limit: limit})
+	movements, total, err := movementRepo.GetByProductID(id, filter)
 	if err != nil {
 		log.Printf("failed to retrieve movements for product %d: %v", id, err)
 		http.Error(w, "could not retrieve movements", http.StatusInternalServerError)
@@ -137,10 +405,15 @@ func GetMovementsHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	for i, m := range movements {
 		resp.Data[i] = MovementResponse{
-			ID:        m.ID,
-			ProductID: m.ProductID,
-			Delta:     m.Delta,
-			CreatedAt: m.CreatedAt,
+			ID:          m.ID,
+			ProductID:   m.ProductID,
+			Delta:       m.Delta,
+			CreatedAt:   m.CreatedAt,
+			WorkOrderID: m.WorkOrderID,
+			ExternalID:  m.ExternalID,
+			WarehouseID: m.WarehouseID,
+			Reason:      m.Reason,
+			Note:        m.Note,
 		}
 	}
 
@@ -151,6 +424,61 @@ func GetMovementsHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// GetMovementByExternalIDHandler godoc
+// @Summary Get movement by external ID
+// @Tags movements
+// @Produce json
+// @Param externalId path string true "External ID (UUID)"
+// @Success 200 {object} MovementResponse
+// @Failure 400 {string} string "Invalid ID"
+// @Failure 404 {string} string "Not found"
+// @Failure 500 {string} string "Internal error"
+// @Router /movements/by-external/{externalId} [get]
+func GetMovementByExternalIDHandler(w http.ResponseWriter, r *http.Request) {
+	externalID, ok := normalizeUUID(chi.URLParam(r, "externalId"))
+	if !ok {
+		http.Error(w, "external ID must be a UUID", http.StatusBadRequest)
+		return
+	}
+
+	scope, err := accessScope(r)
+	if err != nil {
+		http.Error(w, "could not load access grants", http.StatusInternalServerError)
+		return
+	}
+	m, err := movementRepo.GetByExternalID(externalID)
+	if err == nil {
+		// Movements of products outside the caller's grants do not exist
+		// for them either.
+		if err = productInScope(scope, m.ProductID); errors.Is(err, repo.ErrProductNotFound) {
+			err = repo.ErrMovementNotFound
+		}
+	}
+	if err != nil {
+		if errors.Is(err, repo.ErrMovementNotFound) {
+			http.Error(w, "movement not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "could not fetch movement", http.StatusInternalServerError)
+		return
+	}
+
+	resp := MovementResponse{
+		ID:          m.ID,
+		ProductID:   m.ProductID,
+		Delta:       m.Delta,
+		CreatedAt:   m.CreatedAt,
+		WorkOrderID: m.WorkOrderID,
+		ExternalID:  m.ExternalID,
+		WarehouseID: m.WarehouseID,
+		Reason:      m.Reason,
+		Note:        m.Note,
+	}
+	if err := writeJSON(w, http.StatusOK, resp); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
 // ExportMovementsHandler godoc
 // @Summary Export product movement logs
 // @Tags movements
@@ -159,6 +487,11 @@ func GetMovementsHandler(w http.ResponseWriter, r *http.Request) {
 // @Param format query string true "Export format (csv or json)"
 // @Param since query string false "Filter from timestamp (RFC3339)"
 // @Param until query string false "Filter until timestamp (RFC3339)"
+// @Param period query string false "Accounting period (YYYY-MM); closed periods are served from their frozen copy"
+// @Param reason query string false "Only movements with this reason (sale, return, damage, recount or transfer)"
+// @Param columns query string false "CSV only: comma-separated columns, in output order, from id, product_id, delta, created_at, work_order_id, external_id, reason and note (default: the first four)"
+// @Param tz query string false "IANA timezone of created_at, defaults to the caller's profile timezone, then UTC"
+// @Param date_format query string false "Layout of created_at: rfc3339 (default), datetime, date or a Go time layout"
 // @Success 200 {file} file
 // @Failure 400 {string} string "Invalid input"
 // @Failure 500 {string} string "Internal error"
@@ -176,6 +509,18 @@ func ExportMovementsHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "format must be 'csv' or 'json'", http.StatusBadRequest)
 		return
 	}
+	columns := defaultMovementCSVColumns
+	if raw := q.Get("columns"); raw != "" {
+		if columns, err = parseExportColumns(raw, movementCSVColumns); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	clock, err := parseExportClock(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	since, err := parseTime(q.Get("since"))
 	if err != nil {
@@ -187,18 +532,55 @@ func ExportMovementsHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "invalid until date format", http.StatusBadRequest)
 		return
 	}
-
-	movements, _, err := movementRepo.GetByProductID(id, repo.MovementFilter{Since: since, Until: until})
+	reason, err := parseMovementReason(q.Get("reason"))
 	if err != nil {
-		http.Error(w, "could not retrieve movements", http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
+	if !productAccessible(w, r, id) {
+		return
+	}
+
+	var movements []models.Movement
+	if period := q.Get("period"); period != "" {
+		if since != nil || until != nil {
+			http.Error(w, "period cannot be combined with since/until", http.StatusBadRequest)
+			return
+		}
+		var closed bool
+		movements, closed, err = periodMovements(id, period)
+		if err != nil {
+			if errors.Is(err, errInvalidPeriod) {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			http.Error(w, "could not retrieve movements", http.StatusInternalServerError)
+			return
+		}
+		if closed {
+			w.Header().Set("X-Period-Status", "closed")
+		}
+		if reason != "" {
+			movements = slices.DeleteFunc(movements, func(m models.Movement) bool { return m.Reason != reason })
+		}
+	} else {
+		movements, _, err = movementRepo.GetByProductID(id, repo.MovementFilter{Since: since, Until: until, Reason: reason})
+		if err != nil {
+			http.Error(w, "could not retrieve movements", http.StatusInternalServerError)
+			return
+		}
+	}
+
 	switch format {
 	case "json":
 		w.Header().Set("Content-Type", "application/json")
 		w.Header().Set("Content-Disposition", `attachment; filename="movements.json"`)
 
+		movements = slices.Clone(movements)
+		for i := range movements {
+			movements[i].CreatedAt = clock.format(movements[i].CreatedAt)
+		}
 		if err := writeJSON(w, http.StatusOK, movements); err != nil {
 			log.Printf("Failed to write JSON response: %v", err)
 		}
@@ -206,20 +588,65 @@ func ExportMovementsHandler(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "text/csv")
 		w.Header().Set("Content-Disposition", `attachment; filename="movements.csv"`)
 
-		csvWriter := csv.NewWriter(w)
-		_ = csvWriter.Write([]string{"id", "product_id", "delta", "c"})
+		csvWriter := newSafeCSVWriter(w)
+		_ = csvWriter.Write(columns)
 		for _, m := range movements {
-			_ = csvWriter.Write([]string{
-				strconv.Itoa(m.ID),
-				strconv.Itoa(m.ProductID),
-				strconv.Itoa(m.Delta),
-				m.CreatedAt,
-			})
+			values := map[string]string{
+				"id":          strconv.Itoa(m.ID),
+				"product_id":  strconv.Itoa(m.ProductID),
+				"delta":       strconv.Itoa(m.Delta),
+				"created_at":  clock.format(m.CreatedAt),
+				"external_id": m.ExternalID,
+				"reason":      m.Reason,
+				"note":        m.Note,
+			}
+			if m.WorkOrderID != nil {
+				values["work_order_id"] = strconv.Itoa(*m.WorkOrderID)
+			}
+			record := make([]string, len(columns))
+			for i, c := range columns {
+				record[i] = values[c]
+			}
+			_ = csvWriter.Write(record)
 		}
 		csvWriter.Flush()
 	}
 }
 
+// movementCSVColumns are the columns a movement export may select.
+var movementCSVColumns = []string{"id", "product_id", "delta", "created_at", "work_order_id", "external_id", "reason", "note"}
+
+// defaultMovementCSVColumns are exported when no columns are asked for.
+var defaultMovementCSVColumns = []string{"id", "product_id", "delta", "created_at"}
+
+// movementLogAlertThreshold is the number of consecutive movement log failures
+// after which an alert is raised.
+const movementLogAlertThreshold = 3
+
+var (
+	movementLogFailures            atomic.Int64
+	consecutiveMovementLogFailures atomic.Int64
+)
+
+// recordMovementLog tracks the outcome of persisting a movement. The product
+// quantity has already been changed at this point, so a failure is not
+// reported to the client; it is counted, surfaced in the dashboard metrics and
+// alerted on when it persists. GET /admin/reconciliation finds the affected products.
+func recordMovementLog(productID, delta int, err error) {
+	if err == nil {
+		consecutiveMovementLogFailures.Store(0)
+		return
+	}
+
+	movementLogFailures.Add(1)
+	consecutive := consecutiveMovementLogFailures.Add(1)
+	log.Printf("failed to log movement for product %d (delta %d): %v", productID, delta, err)
+
+	if consecutive >= movementLogAlertThreshold {
+		log.Printf("⚠️ ALERT: %d consecutive movement log failures, ledger is drifting from product quantities", consecutive)
+	}
+}
+
 func parseID(idStr string) (int, error) {
 	return strconv.Atoi(idStr)
 }
@@ -254,3 +681,52 @@ func parseNonNegativeInt(s string) (*int, error) {
 	}
 	return &v, nil
 }
+
+const maxMovementNoteLength = 500
+
+// movementReason validates the reason and note of an adjustment and returns
+// them trimmed. Both are optional.
+func movementReason(req QuantityAdjustmentRequest) (string, string, error) {
+	reason, err := parseMovementReason(req.Reason)
+	if err != nil {
+		return "", "", err
+	}
+	note := strings.TrimSpace(req.Note)
+	if len(note) > maxMovementNoteLength {
+		return "", "", fmt.Errorf("note must be at most %d characters", maxMovementNoteLength)
+	}
+	return reason, note, nil
+}
+
+func parseMovementReason(raw string) (string, error) {
+	reason := strings.ToLower(strings.TrimSpace(raw))
+	if reason != "" && !models.ValidMovementReason(reason) {
+		return "", fmt.Errorf("reason must be one of %s", strings.Join(models.MovementReasons, ", "))
+	}
+	return reason, nil
+}
+
+// parseMovementFilter reads the since, until, reason, limit and offset query parameters.
+func parseMovementFilter(q url.Values) (repo.MovementFilter, error) {
+	since, err := parseTime(q.Get("since"))
+	if err != nil {
+		return repo.MovementFilter{}, errors.New("invalid since date format")
+	}
+	until, err := parseTime(q.Get("until"))
+	if err != nil {
+		return repo.MovementFilter{}, errors.New("invalid until date format")
+	}
+	limit, err := parseNonNegativeInt(q.Get("limit"))
+	if err != nil {
+		return repo.MovementFilter{}, errors.New("invalid limit format")
+	}
+	offset, err := parseNonNegativeInt(q.Get("offset"))
+	if err != nil {
+		return repo.MovementFilter{}, errors.New("invalid offset format")
+	}
+	reason, err := parseMovementReason(q.Get("reason"))
+	if err != nil {
+		return repo.MovementFilter{}, err
+	}
+	return repo.MovementFilter{Since: since, Until: until, Reason: reason, Offset: offset, Limit: limit}, nil
+}
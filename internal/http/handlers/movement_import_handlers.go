@@ -0,0 +1,269 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+	"github.com/rogerio-castellano/inventory-tracker/internal/webhook"
+)
+
+// placeholderPrice is the price of a placeholder product whose row gives
+// none: products must have one, and a review is expected to replace it.
+const placeholderPrice = 0.01
+
+// movementRow is one line of a movement import.
+type movementRow struct {
+	num        int
+	SKU        string
+	Delta      int
+	OccurredAt string
+	Reason     string
+	Note       string
+	// Name and Price describe the placeholder created for an unknown SKU.
+	Name  string
+	Price float64
+}
+
+// ImportMovementsHandler godoc
+// @Summary Import historical movements via CSV
+// @Description Columns: sku and delta, required; occurred_at (RFC3339 or YYYY-MM-DD), reason and note, optional. Each row is applied to the product with that SKU as a backdated movement; rows that fail are reported and skipped. With create_missing=true, an unknown SKU gets an inactive placeholder product flagged needs_review instead of failing, named and priced from the optional name and price columns (default "Placeholder <sku>" at 0.01). Placeholders are found with GET /products/filter?needs_review=true; updating one clears the flag.
+// @Tags import
+// @Security BearerAuth
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "CSV file"
+// @Param create_missing query bool false "Create placeholder products for unknown SKUs"
+// @Success 200 {object} ImportMovementsResult
+// @Failure 400 {object} ErrorResponse "Invalid file"
+// @Failure 422 {object} ErrorResponse "Infected file"
+// @Failure 500 {object} ErrorResponse "Internal error"
+// @Failure 503 {object} ErrorResponse "File could not be scanned"
+// @Router /movements/import [post]
+func ImportMovementsHandler(w http.ResponseWriter, r *http.Request) {
+	createMissing := false
+	if v := r.URL.Query().Get("create_missing"); v != "" {
+		var err error
+		if createMissing, err = strconv.ParseBool(v); err != nil {
+			writeError(w, http.StatusBadRequest, ErrCodeInvalidInput, "create_missing must be true or false")
+			return
+		}
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidInput, "missing file")
+		return
+	}
+	defer file.Close()
+	if !scanUpload(w, r, file, header.Filename) {
+		return
+	}
+
+	rows, err := parseMovementCSV(file)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidInput, err.Error())
+		return
+	}
+	scope, err := accessScope(r)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "could not load access grants")
+		return
+	}
+
+	result := ImportMovementsResult{Errors: []ProductValidationError{}}
+	fail := func(row movementRow, code string, err error) {
+		result.Errors = append(result.Errors, ProductValidationError{Code: code, Description: fmt.Sprintf("row %d: %v", row.num, err)})
+	}
+	// Products are looked up once per SKU, placeholders included.
+	products := map[string]models.Product{}
+	for _, row := range rows {
+		m, err := row.movement()
+		if err != nil {
+			fail(row, ErrCodeInvalidRow, err)
+			continue
+		}
+
+		p, ok := products[row.SKU]
+		if !ok {
+			p, err = productRepo.GetBySKU(row.SKU)
+			switch {
+			case errors.Is(err, repo.ErrProductNotFound) && createMissing:
+				if p, err = createPlaceholder(r, row); err != nil {
+					fail(row, ErrCodeInvalidRow, err)
+					continue
+				}
+				result.Placeholders = append(result.Placeholders, p.ID)
+			case errors.Is(err, repo.ErrProductNotFound):
+				fail(row, ErrCodeNotFound, fmt.Errorf("unknown SKU %q", row.SKU))
+				continue
+			case errors.Is(err, repo.ErrAmbiguousSKU):
+				fail(row, ErrCodeConflict, fmt.Errorf("SKU %q matches more than one product", row.SKU))
+				continue
+			case err != nil:
+				fail(row, ErrCodeInternal, errors.New("could not look up SKU"))
+				continue
+			}
+			products[row.SKU] = p
+		}
+		if !scope.AllowsCategory(p.Category) {
+			fail(row, ErrCodeOutOfScope, errors.New("category outside your access grants"))
+			continue
+		}
+
+		at, _ := time.Parse(time.RFC3339, m.CreatedAt)
+		if err := ensurePeriodOpen(at); err != nil {
+			if !errors.Is(err, errPeriodClosed) {
+				err = errors.New("could not verify accounting period")
+			}
+			fail(row, ErrCodeConflict, err)
+			continue
+		}
+		m.ProductID = p.ID
+		product, err := productRepo.AdjustWithMovements(p.ID, []models.Movement{m})
+		if err != nil {
+			switch {
+			case errors.Is(err, repo.ErrStockReserved):
+				fail(row, ErrCodeConflict, errors.New("the units are reserved for pending orders"))
+			case errors.Is(err, repo.ErrInvalidQuantityChange):
+				fail(row, ErrCodeConflict, errors.New("quantity cannot be negative"))
+			default:
+				fail(row, ErrCodeInternal, errors.New("could not apply movement"))
+			}
+			continue
+		}
+		products[row.SKU] = product
+		webhook.Publish(webhook.EventMovementCreated, m)
+		publishStockLevel(product, m.Delta)
+		result.Imported++
+	}
+
+	recordAudit(r, "import", "movement", "", map[string]any{"imported": result.Imported, "failed": len(result.Errors), "placeholders": result.Placeholders})
+	if err := writeJSON(w, http.StatusOK, result); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// movement validates the row and turns it into a movement, without a
+// product yet.
+func (row movementRow) movement() (models.Movement, error) {
+	if row.Delta == 0 {
+		return models.Movement{}, errors.New("delta must be a non-zero integer")
+	}
+	if err := rejectFormulas(csvCell{"sku", row.SKU}, csvCell{"note", row.Note}, csvCell{"name", row.Name}); err != nil {
+		return models.Movement{}, err
+	}
+	at := time.Now().UTC()
+	if row.OccurredAt != "" {
+		t, err := parseDate(row.OccurredAt)
+		if err != nil || t.After(at) {
+			return models.Movement{}, errors.New("occurred_at must be a past date (YYYY-MM-DD or RFC3339)")
+		}
+		at = t.UTC()
+	}
+	reason, note, err := movementReason(QuantityAdjustmentRequest{Reason: row.Reason, Note: row.Note})
+	if err != nil {
+		return models.Movement{}, err
+	}
+	return models.Movement{Delta: row.Delta, CreatedAt: at.Format(time.RFC3339), Reason: reason, Note: note}, nil
+}
+
+// createPlaceholder creates the inactive product flagged needs_review that
+// stands in for the unknown SKU of row. Required-field and price rules are
+// left to the review that completes it.
+func createPlaceholder(r *http.Request, row movementRow) (models.Product, error) {
+	name := strings.TrimSpace(row.Name)
+	if name == "" {
+		name = "Placeholder " + row.SKU
+	}
+	price := roundMoney(row.Price)
+	switch {
+	case price < 0:
+		return models.Product{}, errors.New("invalid price")
+	case price == 0:
+		price = placeholderPrice
+	}
+	if err := checkProductQuota(1); err != nil {
+		if errors.Is(err, errQuotaExceeded) {
+			return models.Product{}, err
+		}
+		return models.Product{}, errors.New("could not check product quota")
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	created, err := productRepo.Create(models.Product{
+		Name:        name,
+		Price:       price,
+		SKU:         row.SKU,
+		Status:      models.ProductStatusInactive,
+		NeedsReview: true,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	})
+	if err != nil {
+		if errors.Is(err, repo.ErrDuplicatedValueUnique) {
+			return models.Product{}, fmt.Errorf("placeholder for SKU %q: product %q already exists", row.SKU, name)
+		}
+		return models.Product{}, errors.New("could not create placeholder product")
+	}
+	recordAudit(r, "create_placeholder", "product", created.ID, created)
+	flagProduct(r, created.ID, models.FlagSourceImport, fmt.Sprintf("placeholder for unknown SKU %s created by a movement import", row.SKU))
+	webhook.Publish(webhook.EventProductCreated, newProductResponse(created))
+	return created, nil
+}
+
+func parseMovementCSV(file multipart.File) ([]movementRow, error) {
+	reader := csv.NewReader(file)
+	headers, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV header")
+	}
+
+	index := map[string]int{}
+	for i, h := range headers {
+		index[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	for _, c := range []string{"sku", "delta"} {
+		if _, ok := index[c]; !ok {
+			return nil, fmt.Errorf("missing required column %q", c)
+		}
+	}
+
+	var rows []movementRow
+	for num := 2; ; num++ { // header is row 1
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("CSV read error: %v", err)
+		}
+
+		field := func(name string) string {
+			if i, ok := index[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		rows = append(rows, movementRow{
+			num:        num,
+			SKU:        field("sku"),
+			Delta:      parseInt(field("delta")),
+			OccurredAt: field("occurred_at"),
+			Reason:     field("reason"),
+			Note:       field("note"),
+			Name:       field("name"),
+			Price:      parseFloat(field("price")),
+		})
+	}
+	return rows, nil
+}
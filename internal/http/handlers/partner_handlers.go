@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/redis/go-redis/v9"
+	"github.com/rogerio-castellano/inventory-tracker/internal/auth"
+)
+
+// CreatePartnerHandler godoc
+// @Summary Register a partner that signs its requests with HMAC
+// @Description The returned secret is shown only once. Partners sign each request as described in the README and may only call endpoints covered by their scopes.
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body PartnerRequest true "Partner name and scopes"
+// @Success 201 {object} PartnerSecretResponse
+// @Failure 400 {string} string "Invalid input"
+// @Failure 500 {string} string "Internal error"
+// @Router /admin/partners [post]
+func CreatePartnerHandler(w http.ResponseWriter, r *http.Request) {
+	var req PartnerRequest
+	if err := readJSON(w, r, &req); err != nil {
+		http.Error(w, "invalid input", http.StatusBadRequest)
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		http.Error(w, "name is required", http.StatusBadRequest)
+		return
+	}
+	if len(req.Scopes) == 0 {
+		http.Error(w, "at least one scope is required", http.StatusBadRequest)
+		return
+	}
+	for _, s := range req.Scopes {
+		if !auth.ValidScope(s) {
+			http.Error(w, fmt.Sprintf("invalid scope %q: expected a path such as \"POST /products/*/adjust\"", s), http.StatusBadRequest)
+			return
+		}
+	}
+
+	id, secret := generateRandomToken(), generateRandomToken()
+	if id == "" || secret == "" {
+		http.Error(w, "could not generate secret", http.StatusInternalServerError)
+		return
+	}
+	partner := auth.Partner{ID: id[:16], Name: req.Name, Secret: secret, Scopes: req.Scopes, CreatedAt: time.Now().UTC()}
+	if err := savePartner(partner); err != nil {
+		http.Error(w, "could not store partner", http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(r, "create", "partner", partner.ID, partnerInfo(partner))
+	if err := writeJSON(w, http.StatusCreated, PartnerSecretResponse{PartnerInfo: partnerInfo(partner), Secret: partner.Secret}); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// ListPartnersHandler godoc
+// @Summary List partners allowed to sign requests
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} PartnerInfo
+// @Failure 500 {string} string "Internal error"
+// @Router /admin/partners [get]
+func ListPartnersHandler(w http.ResponseWriter, r *http.Request) {
+	entries, err := Rdb.HGetAll(Ctx, auth.PartnersKey).Result()
+	if err != nil {
+		http.Error(w, "could not read partners", http.StatusInternalServerError)
+		return
+	}
+
+	partners := make([]PartnerInfo, 0, len(entries))
+	for id, raw := range entries {
+		var p auth.Partner
+		if err := json.Unmarshal([]byte(raw), &p); err != nil {
+			log.Printf("Skipping unreadable partner %s: %v", id, err)
+			continue
+		}
+		partners = append(partners, partnerInfo(p))
+	}
+	sort.Slice(partners, func(i, j int) bool { return partners[i].CreatedAt.Before(partners[j].CreatedAt) })
+
+	if err := writeJSON(w, http.StatusOK, partners); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// RotatePartnerSecretHandler godoc
+// @Summary Replace a partner's signing secret
+// @Description The old secret stops working immediately.
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Partner ID"
+// @Success 200 {object} PartnerSecretResponse
+// @Failure 404 {string} string "Partner not found"
+// @Failure 500 {string} string "Internal error"
+// @Router /admin/partners/{id}/secret [post]
+func RotatePartnerSecretHandler(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	raw, err := Rdb.HGet(Ctx, auth.PartnersKey, id).Result()
+	if err == redis.Nil {
+		http.Error(w, "partner not found", http.StatusNotFound)
+		return
+	}
+	var partner auth.Partner
+	if err != nil || json.Unmarshal([]byte(raw), &partner) != nil {
+		http.Error(w, "could not read partner", http.StatusInternalServerError)
+		return
+	}
+
+	if partner.Secret = generateRandomToken(); partner.Secret == "" {
+		http.Error(w, "could not generate secret", http.StatusInternalServerError)
+		return
+	}
+	if err := savePartner(partner); err != nil {
+		http.Error(w, "could not store partner", http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(r, "rotate_secret", "partner", id, nil)
+	if err := writeJSON(w, http.StatusOK, PartnerSecretResponse{PartnerInfo: partnerInfo(partner), Secret: partner.Secret}); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// DeletePartnerHandler godoc
+// @Summary Remove a partner; its signed requests are rejected from then on
+// @Tags admin
+// @Security BearerAuth
+// @Param id path string true "Partner ID"
+// @Success 204 "Partner removed"
+// @Failure 404 {string} string "Partner not found"
+// @Failure 500 {string} string "Internal error"
+// @Router /admin/partners/{id} [delete]
+func DeletePartnerHandler(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	n, err := Rdb.HDel(Ctx, auth.PartnersKey, id).Result()
+	if err != nil {
+		http.Error(w, "could not remove partner", http.StatusInternalServerError)
+		return
+	}
+	if n == 0 {
+		http.Error(w, "partner not found", http.StatusNotFound)
+		return
+	}
+
+	recordAudit(r, "delete", "partner", id, nil)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func savePartner(p auth.Partner) error {
+	raw, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	return Rdb.HSet(Ctx, auth.PartnersKey, p.ID, raw).Err()
+}
+
+func partnerInfo(p auth.Partner) PartnerInfo {
+	return PartnerInfo{ID: p.ID, Name: p.Name, Scopes: p.Scopes, CreatedAt: p.CreatedAt}
+}
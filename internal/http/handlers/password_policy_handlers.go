@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/auth"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+)
+
+const maxPasswordRotationUsers = 500
+
+// passwordExpired reports whether user must change the password before
+// logging in: an admin forced it, the password is temporary, or it is older
+// than the maximum age.
+func passwordExpired(user models.User) bool {
+	if user.MustChangePassword {
+		return true
+	}
+	return passwordMaxAge > 0 && time.Since(user.PasswordChangedAt) > passwordMaxAge
+}
+
+// ExpirePasswordsHandler godoc
+// @Summary Force users to change their password
+// @Description Expires the passwords of the listed users now, whatever their age, and revokes their refresh tokens. Their next login answers 403 with code PASSWORD_EXPIRED until they set a new password with /password/change; access tokens already issued stay valid until they expire.
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body PasswordRotationRequest true "Users to rotate"
+// @Success 200 {object} PasswordRotationResult
+// @Failure 400 {string} string "Invalid input"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 500 {string} string "Internal error"
+// @Router /admin/users/password-rotation [post]
+func ExpirePasswordsHandler(w http.ResponseWriter, r *http.Request) {
+	var req PasswordRotationRequest
+	if err := readJSON(w, r, &req); err != nil {
+		http.Error(w, "invalid input", http.StatusBadRequest)
+		return
+	}
+	switch {
+	case len(req.Usernames) == 0:
+		http.Error(w, "at least one username is required", http.StatusBadRequest)
+		return
+	case len(req.Usernames) > maxPasswordRotationUsers:
+		http.Error(w, "too many usernames", http.StatusBadRequest)
+		return
+	}
+
+	result := PasswordRotationResult{Expired: []string{}, NotFound: []string{}}
+	seen := map[string]bool{}
+	for _, username := range req.Usernames {
+		username = strings.TrimSpace(username)
+		if username == "" || seen[username] {
+			continue
+		}
+		seen[username] = true
+
+		if err := userRepo.RequirePasswordChange(username); err != nil {
+			if errors.Is(err, repo.ErrUserNotFound) {
+				result.NotFound = append(result.NotFound, username)
+				continue
+			}
+			log.Printf("failed to expire password of %s: %v", username, err)
+			http.Error(w, "could not expire passwords", http.StatusInternalServerError)
+			return
+		}
+		if err := auth.RemoveUserRefreshTokens(username); err != nil {
+			log.Printf("failed to revoke refresh tokens of %s: %v", username, err)
+		}
+		result.Expired = append(result.Expired, username)
+		recordAudit(r, "expire_password", "user", username, nil)
+	}
+
+	if err := writeJSON(w, http.StatusOK, result); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+)
+
+var (
+	errPeriodClosed  = errors.New("accounting period is closed")
+	errInvalidPeriod = errors.New("period must be formatted as YYYY-MM")
+)
+
+// ClosePeriodHandler godoc
+// @Summary Close an accounting period
+// @Description Rejects any further movement dated inside the month and freezes its movements for export.
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param period path string true "Accounting period (YYYY-MM)"
+// @Success 201 {object} models.Period
+// @Failure 400 {string} string "Invalid period"
+// @Failure 409 {string} string "Period already closed"
+// @Failure 500 {string} string "Internal error"
+// @Router /admin/periods/{period}/close [post]
+func ClosePeriodHandler(w http.ResponseWriter, r *http.Request) {
+	period := chi.URLParam(r, "period")
+	start, end, err := repo.PeriodBounds(period)
+	if err != nil {
+		http.Error(w, errInvalidPeriod.Error(), http.StatusBadRequest)
+		return
+	}
+	if end.After(time.Now()) {
+		http.Error(w, "only past periods can be closed", http.StatusBadRequest)
+		return
+	}
+
+	username, err := GetUsernameFromContext(r)
+	if err != nil {
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	movements, err := movementRepo.GetBetween(start, end)
+	if err != nil {
+		http.Error(w, "could not retrieve movements", http.StatusInternalServerError)
+		return
+	}
+
+	closed, err := periodRepo.Close(models.Period{
+		Period:    period,
+		ClosedBy:  username,
+		ClosedAt:  time.Now().UTC(),
+		Movements: movements,
+	})
+	if err != nil {
+		if errors.Is(err, repo.ErrPeriodAlreadyClosed) {
+			http.Error(w, "period already closed", http.StatusConflict)
+			return
+		}
+		http.Error(w, "could not close period", http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(r, "close", "period", period, map[string]int{"frozen_movements": len(movements)})
+
+	if err := writeJSON(w, http.StatusCreated, closed); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// ListPeriodsHandler godoc
+// @Summary List closed accounting periods
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.Period
+// @Failure 500 {string} string "Internal error"
+// @Router /admin/periods [get]
+func ListPeriodsHandler(w http.ResponseWriter, r *http.Request) {
+	periods, err := periodRepo.GetAll()
+	if err != nil {
+		http.Error(w, "could not fetch periods", http.StatusInternalServerError)
+		return
+	}
+
+	if err := writeJSON(w, http.StatusOK, periods); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// ensurePeriodOpen returns errPeriodClosed when t falls inside a closed accounting period.
+func ensurePeriodOpen(t time.Time) error {
+	period := repo.PeriodOf(t)
+	_, err := periodRepo.GetByPeriod(period)
+	if errors.Is(err, repo.ErrPeriodNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("%w: %s", errPeriodClosed, period)
+}
+
+// periodMovements returns a product's movements for an accounting period,
+// reading the frozen copy when the period is closed.
+func periodMovements(productID int, period string) ([]models.Movement, bool, error) {
+	start, end, err := repo.PeriodBounds(period)
+	if err != nil {
+		return nil, false, errInvalidPeriod
+	}
+
+	closed, err := periodRepo.GetByPeriod(period)
+	if err == nil {
+		movements := []models.Movement{}
+		for _, m := range closed.Movements {
+			if m.ProductID == productID {
+				movements = append(movements, m)
+			}
+		}
+		return movements, true, nil
+	}
+	if !errors.Is(err, repo.ErrPeriodNotFound) {
+		return nil, false, err
+	}
+
+	until := end.Add(-time.Nanosecond)
+	movements, _, err := movementRepo.GetByProductID(productID, repo.MovementFilter{Since: &start, Until: &until})
+	return movements, false, err
+}
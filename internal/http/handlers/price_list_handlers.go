@@ -0,0 +1,308 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+)
+
+const (
+	maxPriceListNameLength        = 50
+	maxPriceListDescriptionLength = 255
+)
+
+func normalizePriceList(req PriceListRequest) (models.PriceList, error) {
+	l := models.PriceList{Name: strings.TrimSpace(req.Name), Description: strings.TrimSpace(req.Description)}
+	switch {
+	case l.Name == "":
+		return l, errors.New("name is required")
+	case len(l.Name) > maxPriceListNameLength:
+		return l, fmt.Errorf("name must be at most %d characters", maxPriceListNameLength)
+	case len(l.Description) > maxPriceListDescriptionLength:
+		return l, fmt.Errorf("description must be at most %d characters", maxPriceListDescriptionLength)
+	}
+	return l, nil
+}
+
+// CreatePriceListHandler godoc
+// @Summary Create a price list
+// @Description A named set of per-product prices, e.g. for wholesale customers. Product reads with ?price_list=<name> show the list's price where it has one.
+// @Tags pricing
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param priceList body PriceListRequest true "Price list"
+// @Success 201 {object} models.PriceList
+// @Failure 400 {string} string "Invalid input"
+// @Failure 409 {string} string "Name already in use"
+// @Failure 500 {string} string "Internal error"
+// @Router /admin/price-lists [post]
+func CreatePriceListHandler(w http.ResponseWriter, r *http.Request) {
+	var req PriceListRequest
+	if err := readJSON(w, r, &req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	l, err := normalizePriceList(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	created, err := priceListRepo.Create(l)
+	if err != nil {
+		writePriceListError(w, err)
+		return
+	}
+
+	recordAudit(r, "create", "price_list", created.ID, created)
+	if err := writeJSON(w, http.StatusCreated, created); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// ListPriceListsHandler godoc
+// @Summary List price lists
+// @Tags pricing
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.PriceList
+// @Failure 500 {string} string "Internal error"
+// @Router /admin/price-lists [get]
+func ListPriceListsHandler(w http.ResponseWriter, r *http.Request) {
+	lists, err := priceListRepo.List()
+	if err != nil {
+		http.Error(w, "could not fetch price lists", http.StatusInternalServerError)
+		return
+	}
+	if err := writeJSON(w, http.StatusOK, lists); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// GetPriceListHandler godoc
+// @Summary Get a price list with its prices
+// @Tags pricing
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Price list ID"
+// @Success 200 {object} PriceListDetail
+// @Failure 400 {string} string "Invalid ID"
+// @Failure 404 {string} string "Price list not found"
+// @Failure 500 {string} string "Internal error"
+// @Router /admin/price-lists/{id} [get]
+func GetPriceListHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid price list ID", http.StatusBadRequest)
+		return
+	}
+
+	l, err := priceListRepo.GetByID(id)
+	if err != nil {
+		writePriceListError(w, err)
+		return
+	}
+	prices, err := priceListRepo.Prices(id)
+	if err != nil {
+		http.Error(w, "could not fetch prices", http.StatusInternalServerError)
+		return
+	}
+	if err := writeJSON(w, http.StatusOK, PriceListDetail{PriceList: l, Prices: prices}); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// UpdatePriceListHandler godoc
+// @Summary Rename a price list or change its description
+// @Tags pricing
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Price list ID"
+// @Param priceList body PriceListRequest true "Price list"
+// @Success 200 {object} models.PriceList
+// @Failure 400 {string} string "Invalid input"
+// @Failure 404 {string} string "Price list not found"
+// @Failure 409 {string} string "Name already in use"
+// @Failure 500 {string} string "Internal error"
+// @Router /admin/price-lists/{id} [put]
+func UpdatePriceListHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid price list ID", http.StatusBadRequest)
+		return
+	}
+	var req PriceListRequest
+	if err := readJSON(w, r, &req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	l, err := normalizePriceList(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	before, err := priceListRepo.GetByID(id)
+	if err != nil {
+		writePriceListError(w, err)
+		return
+	}
+	l.ID = id
+	updated, err := priceListRepo.Update(l)
+	if err != nil {
+		writePriceListError(w, err)
+		return
+	}
+
+	recordAudit(r, "update", "price_list", id, map[string]any{"before": before, "after": updated})
+	if err := writeJSON(w, http.StatusOK, updated); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// DeletePriceListHandler godoc
+// @Summary Delete a price list and all its prices
+// @Tags pricing
+// @Security BearerAuth
+// @Param id path int true "Price list ID"
+// @Success 204 "No Content"
+// @Failure 400 {string} string "Invalid ID"
+// @Failure 404 {string} string "Price list not found"
+// @Failure 500 {string} string "Internal error"
+// @Router /admin/price-lists/{id} [delete]
+func DeletePriceListHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid price list ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := priceListRepo.Delete(id); err != nil {
+		writePriceListError(w, err)
+		return
+	}
+
+	recordAudit(r, "delete", "price_list", id, nil)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SetListPricesHandler godoc
+// @Summary Set prices in a price list
+// @Description Creates or replaces the price of each product given; prices of products left out are kept. Nothing is saved if any row is invalid or names a missing product.
+// @Tags pricing
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Price list ID"
+// @Param prices body []models.PriceListItem true "Product prices"
+// @Success 200 {object} PriceListDetail
+// @Failure 400 {string} string "Invalid input"
+// @Failure 404 {string} string "Price list or product not found"
+// @Failure 500 {string} string "Internal error"
+// @Router /admin/price-lists/{id}/prices [put]
+func SetListPricesHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid price list ID", http.StatusBadRequest)
+		return
+	}
+	var items []models.PriceListItem
+	if err := readBulkJSON(w, r, &items); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	seen := make(map[int]bool, len(items))
+	for i, item := range items {
+		switch {
+		case item.ProductID <= 0:
+			http.Error(w, fmt.Sprintf("row %d: product_id must be positive", i+1), http.StatusBadRequest)
+			return
+		case item.Price <= 0:
+			http.Error(w, fmt.Sprintf("row %d: price must be greater than zero", i+1), http.StatusBadRequest)
+			return
+		case seen[item.ProductID]:
+			http.Error(w, fmt.Sprintf("row %d: product %d is listed twice", i+1, item.ProductID), http.StatusBadRequest)
+			return
+		}
+		seen[item.ProductID] = true
+		items[i].Price = roundMoney(item.Price)
+	}
+
+	l, err := priceListRepo.GetByID(id)
+	if err != nil {
+		writePriceListError(w, err)
+		return
+	}
+	written, err := priceListRepo.SetPrices(id, items)
+	if err != nil {
+		writePriceListError(w, err)
+		return
+	}
+	prices, err := priceListRepo.Prices(id)
+	if err != nil {
+		http.Error(w, "could not fetch prices", http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(r, "set_prices", "price_list", id, map[string]any{"rows": written})
+	if err := writeJSON(w, http.StatusOK, PriceListDetail{PriceList: l, Prices: prices}); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// DeleteListPriceHandler godoc
+// @Summary Remove a product's price from a price list
+// @Description The product falls back to its regular price for this list.
+// @Tags pricing
+// @Security BearerAuth
+// @Param id path int true "Price list ID"
+// @Param productId path int true "Product ID"
+// @Success 204 "No Content"
+// @Failure 400 {string} string "Invalid ID"
+// @Failure 404 {string} string "Price list or list price not found"
+// @Failure 500 {string} string "Internal error"
+// @Router /admin/price-lists/{id}/prices/{productId} [delete]
+func DeleteListPriceHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid price list ID", http.StatusBadRequest)
+		return
+	}
+	productID, err := parseID(chi.URLParam(r, "productId"))
+	if err != nil {
+		http.Error(w, "invalid product ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := priceListRepo.DeletePrice(id, productID); err != nil {
+		writePriceListError(w, err)
+		return
+	}
+
+	recordAudit(r, "delete_price", "price_list", id, map[string]any{"product_id": productID})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writePriceListError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, repo.ErrPriceListNotFound):
+		http.Error(w, "price list not found", http.StatusNotFound)
+	case errors.Is(err, repo.ErrListPriceNotFound):
+		http.Error(w, "product has no price in this list", http.StatusNotFound)
+	case errors.Is(err, repo.ErrProductNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, repo.ErrDuplicatedValueUnique):
+		http.Error(w, "price list name already in use", http.StatusConflict)
+	default:
+		log.Printf("price list: %v", err)
+		http.Error(w, "could not process price list", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"slices"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/auth"
+)
+
+// pricingHiddenWriter marks the response of a caller lacking
+// auth.PermPricingRead. writeJSON leaves auth.PricingFields out of the data
+// it writes; handlers streaming other formats drop their price columns
+// themselves.
+type pricingHiddenWriter struct {
+	http.ResponseWriter
+}
+
+// HidePricing returns w marked so that the JSON written to it carries no
+// auth.PricingFields.
+func HidePricing(w http.ResponseWriter) http.ResponseWriter {
+	if pricingHidden(w) {
+		return w
+	}
+	return pricingHiddenWriter{w}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w pricingHiddenWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func pricingHidden(w http.ResponseWriter) bool {
+	_, ok := w.(pricingHiddenWriter)
+	return ok
+}
+
+// stripPricing removes auth.PricingFields from a JSON document, at any
+// depth. Documents that do not decode are returned untouched.
+func stripPricing(body []byte) []byte {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+
+	var data any
+	if err := dec.Decode(&data); err != nil {
+		return body
+	}
+
+	out, err := json.Marshal(stripKeys(data, auth.PricingFields))
+	if err != nil {
+		return body
+	}
+	return out
+}
+
+func stripKeys(v any, fields []string) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for key, value := range t {
+			if slices.Contains(fields, key) {
+				delete(t, key)
+				continue
+			}
+			t[key] = stripKeys(value, fields)
+		}
+	case []any:
+		for i, value := range t {
+			t[i] = stripKeys(value, fields)
+		}
+	}
+	return v
+}
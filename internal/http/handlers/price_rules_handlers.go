@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/auth"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+// PriceRulesOverrideParam is the query parameter with which callers holding
+// the pricing:override permission write prices that break the price rules.
+const PriceRulesOverrideParam = "override_price_rules"
+
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// GetPriceRulesHandler godoc
+// @Summary Get the price sanity rules
+// @Description Lists the checks applied to product prices written through the API, bulk insert and CSV import.
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} models.PriceRules
+// @Failure 500 {string} string "Internal error"
+// @Router /admin/price-rules [get]
+func GetPriceRulesHandler(w http.ResponseWriter, r *http.Request) {
+	rules, err := priceRulesRepo.Get(tenant)
+	if err != nil {
+		http.Error(w, "could not fetch price rules", http.StatusInternalServerError)
+		return
+	}
+	if err := writeJSON(w, http.StatusOK, rules); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// UpdatePriceRulesHandler godoc
+// @Summary Replace the price sanity rules
+// @Description Sets the highest price a product may have, how far in percent one update may move a price and the currency whose decimals prices must fit. In reject mode, breaking prices are refused unless the caller holds the pricing:override permission and passes override_price_rules=true; in warn mode they are accepted with warnings. Either way they are recorded in the audit log. Zero limits and an empty currency turn their check off.
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param rules body PriceRulesRequest true "Price rules"
+// @Success 200 {object} models.PriceRules
+// @Failure 400 {string} string "Invalid input"
+// @Failure 500 {string} string "Internal error"
+// @Router /admin/price-rules [put]
+func UpdatePriceRulesHandler(w http.ResponseWriter, r *http.Request) {
+	var req PriceRulesRequest
+	if err := readJSON(w, r, &req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	rules := models.PriceRules{
+		Tenant:           tenant,
+		MaxPrice:         roundMoney(req.MaxPrice),
+		MaxChangePercent: req.MaxChangePercent,
+		Currency:         strings.ToUpper(strings.TrimSpace(req.Currency)),
+		Mode:             req.Mode,
+	}
+	if rules.Mode == "" {
+		rules.Mode = models.PriceRuleModeReject
+	}
+	switch {
+	case rules.MaxPrice < 0 || rules.MaxChangePercent < 0:
+		http.Error(w, "max_price and max_change_percent cannot be negative", http.StatusBadRequest)
+		return
+	case rules.Currency != "" && !currencyPattern.MatchString(rules.Currency):
+		http.Error(w, "currency must be an ISO 4217 code such as EUR", http.StatusBadRequest)
+		return
+	case rules.Mode != models.PriceRuleModeReject && rules.Mode != models.PriceRuleModeWarn:
+		http.Error(w, "mode must be reject or warn", http.StatusBadRequest)
+		return
+	}
+
+	before, err := priceRulesRepo.Get(tenant)
+	if err != nil {
+		http.Error(w, "could not fetch price rules", http.StatusInternalServerError)
+		return
+	}
+	saved, err := priceRulesRepo.Put(rules)
+	if err != nil {
+		http.Error(w, "could not save price rules", http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(r, "update", "price_rules", tenant, map[string]any{"before": before, "after": saved})
+	if err := writeJSON(w, http.StatusOK, saved); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// priceViolations lists the rules price breaks. before is the current price
+// when an existing product is updated.
+func priceViolations(rules models.PriceRules, price float64, before *float64) []string {
+	var violations []string
+	if rules.MaxPrice > 0 && price > rules.MaxPrice {
+		violations = append(violations, fmt.Sprintf("price %.2f is above the maximum of %.2f", price, rules.MaxPrice))
+	}
+	if before != nil && *before > 0 && rules.MaxChangePercent > 0 && price != *before {
+		change := math.Abs(price-*before) / *before * 100
+		if change > rules.MaxChangePercent {
+			violations = append(violations, fmt.Sprintf("price changes by %.1f%% from %.2f, more than the maximum of %g%%", change, *before, rules.MaxChangePercent))
+		}
+	}
+	if rules.Currency != "" {
+		decimals := models.CurrencyDecimals(rules.Currency)
+		scaled := price * math.Pow10(decimals)
+		if math.Abs(scaled-math.Round(scaled)) > 1e-6 {
+			violations = append(violations, fmt.Sprintf("price %g has more than %d decimals for %s", price, decimals, rules.Currency))
+		}
+	}
+	return violations
+}
+
+// priceCheck is the outcome of screening a price against the price rules.
+type priceCheck struct {
+	Violations []string
+	Overridden bool // accepted in reject mode by a caller allowed to override
+	Rejected   bool
+}
+
+// screenPrice applies the price rules to a price written by r.
+func screenPrice(r *http.Request, rules models.PriceRules, price float64, before *float64) priceCheck {
+	check := priceCheck{Violations: priceViolations(rules, price, before)}
+	if len(check.Violations) == 0 || rules.Mode == models.PriceRuleModeWarn {
+		return check
+	}
+	if r.URL.Query().Get(PriceRulesOverrideParam) == "true" {
+		if role, err := GetRoleFromContext(r); err == nil && auth.HasPermission(role, auth.PermPriceOverride) {
+			check.Overridden = true
+			return check
+		}
+	}
+	check.Rejected = true
+	return check
+}
+
+// message explains a rejected price.
+func (c priceCheck) message() string {
+	return fmt.Sprintf("suspicious price: %s; pass %s=true with the pricing:override permission to accept it", strings.Join(c.Violations, "; "), PriceRulesOverrideParam)
+}
+
+// recordPriceCheck audits a price that broke the rules but was accepted and,
+// unless the rules were overridden on purpose, flags it for review.
+func recordPriceCheck(r *http.Request, productID int, price float64, c priceCheck) {
+	if len(c.Violations) == 0 || c.Rejected {
+		return
+	}
+	action := "flag_price"
+	if c.Overridden {
+		action = "override_price_rules"
+	}
+	recordAudit(r, action, "product", productID, map[string]any{"price": price, "violations": c.Violations})
+	if !c.Overridden {
+		flagProduct(r, productID, models.FlagSourceValidation, strings.Join(c.Violations, "; "))
+	}
+}
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+)
+
+var (
+	errInvalidTaxOption = errors.New("tax must be inclusive or exclusive")
+	errUnknownPriceList = errors.New("unknown price list")
+)
+
+// priceView renders product prices for a read: the price from the list
+// named by ?price_list= when the product has one there, else the stored
+// price, lowered by an active promotion when one applies, net of tax or,
+// with ?tax=inclusive, gross of the product's tax rate.
+type priceView struct {
+	inclusive  bool
+	classes    map[int]models.TaxClass
+	list       string
+	listPrices map[int]float64
+	promotions []models.Promotion
+}
+
+func parsePriceView(r *http.Request) (priceView, error) {
+	v := priceView{}
+	switch r.URL.Query().Get("tax") {
+	case "", "exclusive":
+	case "inclusive":
+		v.inclusive = true
+	default:
+		return v, errInvalidTaxOption
+	}
+
+	classes, err := taxClassRepo.List()
+	if err != nil {
+		return v, fmt.Errorf("could not fetch tax classes: %w", err)
+	}
+	v.classes = make(map[int]models.TaxClass, len(classes))
+	for _, c := range classes {
+		v.classes[c.ID] = c
+	}
+
+	if name := r.URL.Query().Get("price_list"); name != "" {
+		list, err := priceListRepo.GetByName(name)
+		if errors.Is(err, repo.ErrPriceListNotFound) {
+			return v, fmt.Errorf("%w %q", errUnknownPriceList, name)
+		}
+		if err != nil {
+			return v, fmt.Errorf("could not fetch price list: %w", err)
+		}
+		items, err := priceListRepo.Prices(list.ID)
+		if err != nil {
+			return v, fmt.Errorf("could not fetch prices of list %d: %w", list.ID, err)
+		}
+		v.list = list.Name
+		v.listPrices = make(map[int]float64, len(items))
+		for _, item := range items {
+			v.listPrices[item.ProductID] = item.Price
+		}
+	}
+
+	if v.promotions, err = promotionRepo.List(true); err != nil {
+		return v, fmt.Errorf("could not fetch promotions: %w", err)
+	}
+	return v, nil
+}
+
+// rate returns the product's tax rate, zero for untaxed products.
+func (v priceView) rate(p models.Product) float64 {
+	if p.TaxClassID == nil {
+		return 0
+	}
+	return v.classes[*p.TaxClassID].Rate
+}
+
+// regular returns the price of p before promotions and tax.
+func (v priceView) regular(p models.Product) float64 {
+	if listPrice, ok := v.listPrices[p.ID]; ok {
+		return listPrice
+	}
+	return p.Price
+}
+
+// resolve returns the price p sells at before tax, and the promotion that
+// set it, if any. When several promotions apply, the lowest price wins; a
+// promotion never raises the price.
+func (v priceView) resolve(p models.Product) (float64, *models.Promotion) {
+	regular := v.regular(p)
+	price := regular
+	var applied *models.Promotion
+	for i, promo := range v.promotions {
+		if !promotionApplies(promo, p) {
+			continue
+		}
+		promoPrice := promo.Price
+		if promo.PercentOff > 0 {
+			promoPrice = roundMoney(regular * (1 - promo.PercentOff/100))
+		}
+		if promoPrice < price {
+			price, applied = promoPrice, &v.promotions[i]
+		}
+	}
+	return price, applied
+}
+
+func promotionApplies(promo models.Promotion, p models.Product) bool {
+	if promo.ProductID != nil {
+		return *promo.ProductID == p.ID
+	}
+	return promo.Category != "" && strings.EqualFold(promo.Category, p.Category)
+}
+
+func (v priceView) withTax(p models.Product, price float64) float64 {
+	if !v.inclusive {
+		return price
+	}
+	return roundMoney(price * (1 + v.rate(p)/100))
+}
+
+func (v priceView) price(p models.Product) float64 {
+	price, _ := v.resolve(p)
+	return v.withTax(p, price)
+}
+
+func (v priceView) product(p models.Product) ProductResponse {
+	resp := newProductResponse(p)
+	if p.TaxClassID != nil {
+		rate := v.rate(p)
+		resp.TaxRate = &rate
+	}
+	price, promo := v.resolve(p)
+	resp.Price = v.withTax(p, price)
+	resp.PriceIncludesTax = v.inclusive
+	if _, ok := v.listPrices[p.ID]; ok {
+		resp.PriceList = v.list
+	}
+	if promo != nil {
+		resp.Promotion = &AppliedPromotion{ID: promo.ID, Name: promo.Name, RegularPrice: v.withTax(p, v.regular(p)), EndsAt: promo.EndsAt}
+	}
+	return resp
+}
+
+// writePriceViewError answers a parsePriceView failure.
+func writePriceViewError(w http.ResponseWriter, err error) {
+	if errors.Is(err, errInvalidTaxOption) || errors.Is(err, errUnknownPriceList) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	log.Printf("%v", err)
+	http.Error(w, "could not fetch prices", http.StatusInternalServerError)
+}
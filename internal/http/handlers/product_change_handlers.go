@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+)
+
+const (
+	defaultProductChangeLimit = 100
+	maxProductChangeLimit     = 1000
+)
+
+// GetProductChangesHandler godoc
+// @Summary Changes to a product since a sequence number
+// @Description Every write to a product increments its seq, which product responses include. A cache holding seq 42 calls this with since_seq=42 to get the fields changed since, oldest first, instead of refetching the product. Each change lists only the fields it changed, keyed by column name; the creation lists them all.
+// @Tags products
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param since_seq query int false "Return changes after this seq (default 0, since creation)"
+// @Param limit query int false "Maximum changes (default 100, max 1000)"
+// @Success 200 {object} ProductChangesPage
+// @Failure 400 {string} string "Invalid ID, since_seq or limit"
+// @Failure 404 {string} string "Product not found"
+// @Failure 500 {string} string "Internal error"
+// @Router /products/{id}/changes [get]
+func GetProductChangesHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid product ID", http.StatusBadRequest)
+		return
+	}
+	sinceSeq := 0
+	if raw := r.URL.Query().Get("since_seq"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			http.Error(w, "since_seq must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+		sinceSeq = n
+	}
+	limit := defaultProductChangeLimit
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		limit = min(n, maxProductChangeLimit)
+	}
+
+	p, err := productRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, repo.ErrProductNotFound) {
+			http.Error(w, "product not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "could not fetch product", http.StatusInternalServerError)
+		return
+	}
+	changes, err := productChangeRepo.Since(id, sinceSeq, limit)
+	if err != nil {
+		http.Error(w, "could not fetch changes", http.StatusInternalServerError)
+		return
+	}
+
+	if err := writeJSON(w, http.StatusOK, ProductChangesPage{ProductID: id, Seq: p.Seq, Changes: changes}); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
@@ -0,0 +1,250 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+)
+
+const maxFlagNoteLength = 500
+
+// flagProduct puts productID in the review queue. A flag that cannot be
+// saved is logged: it must not fail the write that raised it.
+func flagProduct(r *http.Request, productID int, source, reason string) {
+	flag, err := productFlagRepo.Create(models.ProductFlag{ProductID: productID, Source: source, Reason: reason})
+	if err != nil {
+		log.Printf("failed to flag product %d: %v", productID, err)
+		return
+	}
+	recordAudit(r, "flag", "product", productID, flag)
+}
+
+// ReviewQueueHandler godoc
+// @Summary List flagged products awaiting review
+// @Description Flags come from imports (placeholder products created for unknown SKUs), anomaly detection (adjustments beyond a product's warning limit) and validation (prices saved with price rule warnings). Users granted categories only see flags on products of those.
+// @Tags products
+// @Security BearerAuth
+// @Produce json
+// @Param source query string false "import, anomaly or validation"
+// @Param status query string false "open (default), resolved, dismissed or all"
+// @Param offset query int false "Offset for pagination"
+// @Param limit query int false "Limit for pagination"
+// @Success 200 {object} ReviewQueueResult
+// @Failure 400 {string} string "Invalid query"
+// @Failure 500 {string} string "Internal error"
+// @Router /products/review-queue [get]
+func ReviewQueueHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter := repo.ProductFlagFilter{Source: q.Get("source"), Status: q.Get("status")}
+	if filter.Source != "" && !models.ValidFlagSource(filter.Source) {
+		http.Error(w, "source must be import, anomaly or validation", http.StatusBadRequest)
+		return
+	}
+	switch filter.Status {
+	case "":
+		filter.Status = models.FlagStatusOpen
+	case "all":
+		filter.Status = ""
+	case models.FlagStatusOpen, models.FlagStatusResolved, models.FlagStatusDismissed:
+	default:
+		http.Error(w, "status must be open, resolved, dismissed or all", http.StatusBadRequest)
+		return
+	}
+	offset, limit := parseIntPtr(q.Get("offset")), parseIntPtr(q.Get("limit"))
+	if limit != nil && *limit <= 0 {
+		http.Error(w, "limit must be greater than zero", http.StatusBadRequest)
+		return
+	}
+	if offset != nil && *offset < 0 {
+		http.Error(w, "offset must be zero or positive", http.StatusBadRequest)
+		return
+	}
+	scope, err := accessScope(r)
+	if err != nil {
+		http.Error(w, "could not load access grants", http.StatusInternalServerError)
+		return
+	}
+
+	flags, err := productFlagRepo.List(filter)
+	if err != nil {
+		http.Error(w, "could not fetch flags", http.StatusInternalServerError)
+		return
+	}
+	entries := []ReviewQueueEntry{}
+	products := map[int]models.Product{}
+	for _, f := range flags {
+		p, ok := products[f.ProductID]
+		if !ok {
+			if p, err = productRepo.GetByID(f.ProductID); err != nil {
+				if errors.Is(err, repo.ErrProductNotFound) {
+					continue
+				}
+				http.Error(w, "could not fetch products", http.StatusInternalServerError)
+				return
+			}
+			products[f.ProductID] = p
+		}
+		if scope.AllowsCategory(p.Category) {
+			entries = append(entries, ReviewQueueEntry{ProductFlag: f, ProductName: p.Name, SKU: p.SKU})
+		}
+	}
+
+	resp := ReviewQueueResult{Meta: Meta{TotalCount: len(entries)}}
+	if offset != nil {
+		entries = entries[min(*offset, len(entries)):]
+	}
+	if limit != nil {
+		entries = entries[:min(*limit, len(entries))]
+	}
+	resp.Data = entries
+	if err := writeJSON(w, http.StatusOK, resp); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// ResolveFlagHandler godoc
+// @Summary Resolve a product flag
+// @Description Marks the issue as fixed. Once a placeholder product has no open flags left, it no longer needs review.
+// @Tags products
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param flagId path int true "Flag ID"
+// @Param decision body CloseFlagRequest false "What was done"
+// @Success 200 {object} models.ProductFlag
+// @Failure 400 {string} string "Invalid input"
+// @Failure 404 {string} string "Flag not found"
+// @Failure 409 {string} string "Already closed"
+// @Failure 500 {string} string "Internal error"
+// @Router /products/review-queue/{flagId}/resolve [post]
+func ResolveFlagHandler(w http.ResponseWriter, r *http.Request) {
+	closeFlag(w, r, models.FlagStatusResolved)
+}
+
+// DismissFlagHandler godoc
+// @Summary Dismiss a product flag
+// @Description Closes a flag that needs no change, such as a price that is right despite the warning.
+// @Tags products
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param flagId path int true "Flag ID"
+// @Param decision body CloseFlagRequest false "Why it needs no change"
+// @Success 200 {object} models.ProductFlag
+// @Failure 400 {string} string "Invalid input"
+// @Failure 404 {string} string "Flag not found"
+// @Failure 409 {string} string "Already closed"
+// @Failure 500 {string} string "Internal error"
+// @Router /products/review-queue/{flagId}/dismiss [post]
+func DismissFlagHandler(w http.ResponseWriter, r *http.Request) {
+	closeFlag(w, r, models.FlagStatusDismissed)
+}
+
+func closeFlag(w http.ResponseWriter, r *http.Request, status string) {
+	id, err := parseID(chi.URLParam(r, "flagId"))
+	if err != nil {
+		http.Error(w, "invalid flag ID", http.StatusBadRequest)
+		return
+	}
+	var req CloseFlagRequest
+	if r.ContentLength != 0 {
+		if err := readJSON(w, r, &req); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+	if req.Note = strings.TrimSpace(req.Note); len(req.Note) > maxFlagNoteLength {
+		http.Error(w, fmt.Sprintf("note must be at most %d characters", maxFlagNoteLength), http.StatusBadRequest)
+		return
+	}
+	actor, err := GetUsernameFromContext(r)
+	if err != nil {
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	scope, err := accessScope(r)
+	if err != nil {
+		http.Error(w, "could not load access grants", http.StatusInternalServerError)
+		return
+	}
+	flag, err := productFlagRepo.GetByID(id)
+	if err != nil {
+		writeFlagError(w, err)
+		return
+	}
+	// A flag on a product outside the caller's grants is not found, as the
+	// product is not.
+	if err := productInScope(scope, flag.ProductID); err != nil {
+		if errors.Is(err, repo.ErrProductNotFound) {
+			err = repo.ErrProductFlagNotFound
+		}
+		writeFlagError(w, err)
+		return
+	}
+
+	closed, err := productFlagRepo.Close(id, status, actor, req.Note)
+	if err != nil {
+		writeFlagError(w, err)
+		return
+	}
+	recordAudit(r, status, "product_flag", id, closed)
+	if err := clearNeedsReview(closed.ProductID); err != nil {
+		log.Printf("failed to clear needs_review of product %d: %v", closed.ProductID, err)
+	}
+	if err := writeJSON(w, http.StatusOK, closed); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// clearNeedsReview takes a placeholder product out of review once none of
+// its flags are open.
+func clearNeedsReview(productID int) error {
+	open, err := productFlagRepo.List(repo.ProductFlagFilter{ProductID: productID, Status: models.FlagStatusOpen})
+	if err != nil || len(open) > 0 {
+		return err
+	}
+	p, err := productRepo.GetByID(productID)
+	if err != nil || !p.NeedsReview {
+		return err
+	}
+	p.NeedsReview = false
+	_, err = productRepo.Update(p)
+	return err
+}
+
+// resolveFlags closes the open flags of productID from source once an edit
+// has dealt with them.
+func resolveFlags(r *http.Request, productID int, source, note string) {
+	flags, err := productFlagRepo.List(repo.ProductFlagFilter{ProductID: productID, Source: source, Status: models.FlagStatusOpen})
+	if err != nil {
+		log.Printf("failed to fetch flags of product %d: %v", productID, err)
+		return
+	}
+	actor, _ := GetUsernameFromContext(r)
+	for _, f := range flags {
+		closed, err := productFlagRepo.Close(f.ID, models.FlagStatusResolved, actor, note)
+		if err != nil {
+			log.Printf("failed to resolve flag %d: %v", f.ID, err)
+			continue
+		}
+		recordAudit(r, models.FlagStatusResolved, "product_flag", f.ID, closed)
+	}
+}
+
+func writeFlagError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, repo.ErrProductFlagNotFound):
+		http.Error(w, "flag not found", http.StatusNotFound)
+	case errors.Is(err, repo.ErrProductFlagClosed):
+		http.Error(w, "flag already closed", http.StatusConflict)
+	default:
+		http.Error(w, "could not process flag", http.StatusInternalServerError)
+	}
+}
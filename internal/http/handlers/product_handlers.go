@@ -1,15 +1,20 @@
 package handlers
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/rogerio-castellano/inventory-tracker/internal/models"
 	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+	"github.com/rogerio-castellano/inventory-tracker/internal/webhook"
 )
 
 // CreateProductHandler godoc
@@ -20,17 +25,21 @@ import (
 // @Produce json
 // @Security BearerAuth
 // @Param product body ProductRequest true "Product to add"
+// @Param override_price_rules query bool false "Accept a price that breaks the price rules (needs pricing:override)"
 // @Success 201 {object} ProductResponse
 // @Failure 400 {object} map[string]string
+// @Failure 403 {object} ErrorResponse "Product quota exceeded or category outside the caller's grants"
+// @Failure 409 {string} string "Product name already exists"
+// @Failure 422 {object} ErrorResponse "Price breaks the price rules"
 // @Router /products [post]
 func CreateProductHandler(w http.ResponseWriter, r *http.Request) {
 	var req ProductRequest
 	if err := readJSON(w, r, &req); err != nil {
-		http.Error(w, "invalid input", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidInput, "invalid input")
 		return
 	}
 
-	validationErrors := validateProduct(req)
+	validationErrors := append(validateProduct(req), validateTaxClass(req.TaxClassID)...)
 	if len(validationErrors) > 0 {
 		w.WriteHeader(http.StatusBadRequest)
 		if err := writeJSON(w, http.StatusOK, validationErrors); err != nil {
@@ -40,31 +49,82 @@ func CreateProductHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	product := models.Product{
-		Name:      req.Name,
-		Price:     req.Price,
-		Quantity:  req.Quantity,
-		Threshold: req.Threshold,
-		CreatedAt: time.Now().Format(time.RFC3339),
-		UpdatedAt: time.Now().Format(time.RFC3339),
+		Name:        req.Name,
+		Price:       req.Price,
+		Cost:        req.Cost,
+		Quantity:    req.Quantity,
+		Threshold:   req.Threshold,
+		Category:    req.Category,
+		SKU:         req.SKU,
+		Barcode:     req.Barcode,
+		Supplier:    req.Supplier,
+		MaxQuantity: req.MaxQuantity,
+		Status:      req.Status,
+		ExternalID:  strings.ToLower(strings.TrimSpace(req.ExternalID)),
+		TaxClassID:  req.TaxClassID,
+		Currency:    strings.ToUpper(strings.TrimSpace(req.Currency)),
+		CreatedAt:   time.Now().UTC().Format(time.RFC3339),
+		UpdatedAt:   time.Now().UTC().Format(time.RFC3339),
+
+		Description:  strings.TrimSpace(req.Description),
+		Brand:        strings.TrimSpace(req.Brand),
+		Manufacturer: strings.TrimSpace(req.Manufacturer),
+		Weight:       req.Weight,
+		Dimensions:   req.Dimensions,
+	}
+	required, err := requiredFields()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "could not load validation policy")
+		return
+	}
+	if errs := validateRequiredFields(product, required); len(errs) > 0 {
+		if err := writeJSON(w, http.StatusBadRequest, errs); err != nil {
+			log.Printf("Failed to write JSON response: %v", err)
+		}
+		return
+	}
+	rules, err := priceRulesRepo.Get(tenant)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "could not load price rules")
+		return
+	}
+	priceCheck := screenPrice(r, rules, product.Price, nil)
+	if priceCheck.Rejected {
+		writeError(w, http.StatusUnprocessableEntity, ErrCodeSuspiciousPrice, priceCheck.message())
+		return
+	}
+	scope, err := accessScope(r)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "could not load access grants")
+		return
+	}
+	if !scope.AllowsCategory(product.Category) {
+		writeError(w, http.StatusForbidden, ErrCodeOutOfScope, "category outside your access grants")
+		return
+	}
+	if err := checkProductQuota(1); err != nil {
+		writeQuotaError(w, err)
+		return
 	}
 	created, err := productRepo.Create(product)
 	if err != nil {
+		if errors.Is(err, repo.ErrDuplicatedExternalID) {
+			writeError(w, http.StatusConflict, ErrCodeDuplicateExternalID, "could not create product: external ID already in use")
+			return
+		}
 		if errors.Is(err, repo.ErrDuplicatedValueUnique) {
-			http.Error(w, "could not create product: product name duplicated", http.StatusInternalServerError)
+			writeError(w, http.StatusConflict, ErrCodeDuplicateName, "could not create product: product name duplicated")
 			return
 		}
-		http.Error(w, "could not create product", http.StatusInternalServerError)
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "could not create product")
 		return
 	}
 
-	resp := ProductResponse{
-		Id:        created.ID,
-		Name:      created.Name,
-		Price:     created.Price,
-		Quantity:  created.Quantity,
-		Threshold: created.Threshold,
-		LowStock:  created.Quantity < created.Threshold,
-	}
+	recordAudit(r, "create", "product", created.ID, created)
+	recordPriceCheck(r, created.ID, created.Price, priceCheck)
+	resp := newProductResponse(created)
+	resp.Warnings = priceCheck.Violations
+	webhook.Publish(webhook.EventProductCreated, resp)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
@@ -77,26 +137,52 @@ func CreateProductHandler(w http.ResponseWriter, r *http.Request) {
 // @Summary List all products
 // @Tags products
 // @Produce json
+// @Param tax query string false "Price output: exclusive (default, as stored) or inclusive of the product's tax rate"
+// @Param price_list query string false "Name of a price list whose prices replace the regular ones where set"
+// @Param include query string false "Related records to embed: suppliers, health (the health score and its components)"
+// @Param sort query string false "Order: id (default), name, price, quantity, updated_at, -updated_at (most recently changed first) or health (lowest score first)"
+// @Param order query string false "asc (default) or desc"
 // @Success 200 {array} ProductResponse
+// @Failure 400 {string} string "Invalid tax, include, sort or order option"
 // @Failure 500 {string} string "Internal error"
 // @Router /products [get]
 func GetProductsHandler(w http.ResponseWriter, r *http.Request) {
-	products, err := productRepo.GetAll()
+	view, err := parsePriceView(r)
+	if err != nil {
+		writePriceViewError(w, err)
+		return
+	}
+	include, err := parseIncludes(r, productIncludes)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	filter := repo.ProductFilter{SkipTotal: true}
+	if filter.Sort, filter.Descending, err = parseProductSort(r); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if filter.Scope, err = accessScope(r); err != nil {
+		http.Error(w, "could not load access grants", http.StatusInternalServerError)
+		return
+	}
+	products, _, err := productRepo.Filter(filter)
 	if err != nil {
 		http.Error(w, "could not fetch products", http.StatusInternalServerError)
 		return
 	}
 	response := make([]ProductResponse, len(products))
+	now := time.Now().UTC()
 	for i, p := range products {
-		response[i] = ProductResponse{
-			Id:        p.ID,
-			Name:      p.Name,
-			Price:     p.Price,
-			Quantity:  p.Quantity,
-			Threshold: p.Threshold,
-			LowStock:  p.Quantity < p.Threshold,
+		response[i] = view.product(p)
+		if include["health"] {
+			response[i].Health = productHealth(p, now)
 		}
 	}
+	if err := embedIncludes(response, include); err != nil {
+		http.Error(w, "could not fetch suppliers", http.StatusInternalServerError)
+		return
+	}
 	w.Header().Set("Content-Type", "application/json")
 	if err := writeJSON(w, http.StatusOK, response); err != nil {
 		log.Printf("Failed to write JSON response: %v", err)
@@ -105,11 +191,15 @@ func GetProductsHandler(w http.ResponseWriter, r *http.Request) {
 
 // GetProductByIDHandler godoc
 // @Summary Get product by ID
+// @Description Quantity is the product's total; warehouses splits it by location.
 // @Tags products
 // @Produce json
 // @Param id path int true "Product ID"
+// @Param tax query string false "Price output: exclusive (default, as stored) or inclusive of the product's tax rate"
+// @Param price_list query string false "Name of a price list whose prices replace the regular ones where set"
+// @Param include query string false "Related records to embed: suppliers, health (the health score and its components), stats (movement counts, last movement, 30-day velocity and reservations)"
 // @Success 200 {object} ProductResponse
-// @Failure 400 {string} string "Invalid ID"
+// @Failure 400 {string} string "Invalid ID, tax or include option"
 // @Failure 404 {string} string "Not found"
 // @Failure 500 {string} string "Internal error"
 // @Router /products/{id} [get]
@@ -120,8 +210,26 @@ func GetProductByIDHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "invalid product ID", http.StatusBadRequest)
 		return
 	}
+	view, err := parsePriceView(r)
+	if err != nil {
+		writePriceViewError(w, err)
+		return
+	}
+	include, err := parseIncludes(r, productDetailIncludes)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
+	scope, err := accessScope(r)
+	if err != nil {
+		http.Error(w, "could not load access grants", http.StatusInternalServerError)
+		return
+	}
 	product, err := productRepo.GetByID(id)
+	if err == nil && !scope.AllowsCategory(product.Category) {
+		err = repo.ErrProductNotFound
+	}
 	if err != nil {
 		if err == repo.ErrProductNotFound {
 			http.Error(w, "product not found", http.StatusNotFound)
@@ -130,27 +238,113 @@ func GetProductByIDHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "could not fetch product", http.StatusInternalServerError)
 		return
 	}
-	resp := ProductResponse{
-		Id:        product.ID,
-		Name:      product.Name,
-		Price:     product.Price,
-		Quantity:  product.Quantity,
-		Threshold: product.Threshold,
-		LowStock:  product.Quantity < product.Threshold,
+	resp := view.product(product)
+	if include["health"] {
+		resp.Health = productHealth(product, time.Now().UTC())
+	}
+	if resp.Warehouses, err = warehouseRepo.Stock(id); err != nil {
+		http.Error(w, "could not fetch warehouse stock", http.StatusInternalServerError)
+		return
+	}
+	resp.Warehouses = slices.DeleteFunc(resp.Warehouses, func(s models.WarehouseStock) bool { return !scope.AllowsWarehouse(s.WarehouseID) })
+	resps := []ProductResponse{resp}
+	if err := withAvailability(resps); err != nil {
+		http.Error(w, "could not fetch reservations", http.StatusInternalServerError)
+		return
+	}
+	if err := embedIncludes(resps, include); err != nil {
+		http.Error(w, "could not fetch suppliers", http.StatusInternalServerError)
+		return
+	}
+	if include["stats"] {
+		if resps[0].Stats, err = productStats(id, time.Now().UTC()); err != nil {
+			http.Error(w, "could not fetch product stats", http.StatusInternalServerError)
+			return
+		}
 	}
 	w.Header().Set("Content-Type", "application/json")
-	if err := writeJSON(w, http.StatusOK, resp); err != nil {
+	if err := writeJSON(w, http.StatusOK, resps[0]); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// velocityWindow is the period ProductStats averages outbound units over.
+const velocityWindow = 30
+
+// productStats summarizes the movements and reservations of a product as of
+// now.
+func productStats(id int, now time.Time) (*ProductStats, error) {
+	movements, err := movementRepo.Stats(id, now.AddDate(0, 0, -velocityWindow))
+	if err != nil {
+		return nil, err
+	}
+	stats := &ProductStats{
+		MovementStats: movements,
+		UnitsOut30d:   movements.UnitsOutSince,
+		Velocity30d:   roundMoney(float64(movements.UnitsOutSince) / velocityWindow),
+	}
+	if stats.Reservations, stats.ReservedUnits, err = reservationRepo.Count(id, now); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// GetProductByExternalIDHandler godoc
+// @Summary Get product by external ID
+// @Tags products
+// @Produce json
+// @Param externalId path string true "External ID (UUID)"
+// @Param tax query string false "Price output: exclusive (default, as stored) or inclusive of the product's tax rate"
+// @Param price_list query string false "Name of a price list whose prices replace the regular ones where set"
+// @Success 200 {object} ProductResponse
+// @Failure 400 {string} string "Invalid ID or tax option"
+// @Failure 404 {string} string "Not found"
+// @Failure 500 {string} string "Internal error"
+// @Router /products/by-external/{externalId} [get]
+func GetProductByExternalIDHandler(w http.ResponseWriter, r *http.Request) {
+	externalID, ok := normalizeUUID(chi.URLParam(r, "externalId"))
+	if !ok {
+		http.Error(w, "external ID must be a UUID", http.StatusBadRequest)
+		return
+	}
+	view, err := parsePriceView(r)
+	if err != nil {
+		writePriceViewError(w, err)
+		return
+	}
+
+	scope, err := accessScope(r)
+	if err != nil {
+		http.Error(w, "could not load access grants", http.StatusInternalServerError)
+		return
+	}
+	product, err := productRepo.GetByExternalID(externalID)
+	if err == nil && !scope.AllowsCategory(product.Category) {
+		err = repo.ErrProductNotFound
+	}
+	if err != nil {
+		if errors.Is(err, repo.ErrProductNotFound) {
+			http.Error(w, "product not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "could not fetch product", http.StatusInternalServerError)
+		return
+	}
+	if err := writeJSON(w, http.StatusOK, view.product(product)); err != nil {
 		log.Printf("Failed to write JSON response: %v", err)
 	}
 }
 
 // DeleteProductHandler godoc
 // @Summary Delete a product
+// @Description With PRODUCT_DELETE_APPROVAL on, a product that still has stock is not deleted: a pending request is created instead, which another admin approves via /approvals.
 // @Tags products
 // @Param id path int true "Product ID"
+// @Success 202 {object} models.Approval "Deletion awaits approval"
 // @Success 204 "Deleted successfully"
 // @Failure 400 {string} string "Invalid ID"
 // @Failure 404 {string} string "Not found"
+// @Failure 409 {string} string "Deletion already awaiting approval"
 // @Failure 500 {string} string "Internal error"
 // @Router /products/{id} [delete]
 // @Security BearerAuth
@@ -165,6 +359,34 @@ func DeleteProductHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "invalid product ID", http.StatusBadRequest)
 		return
 	}
+	scope, err := accessScope(r)
+	if err != nil {
+		http.Error(w, "could not load access grants", http.StatusInternalServerError)
+		return
+	}
+	if err := productInScope(scope, id); err != nil {
+		if err == repo.ErrProductNotFound {
+			http.Error(w, "product not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "could not delete product", http.StatusInternalServerError)
+		return
+	}
+	if deleteApprovalRequired {
+		p, err := productRepo.GetByID(id)
+		if err != nil {
+			if err == repo.ErrProductNotFound {
+				http.Error(w, "product not found", http.StatusNotFound)
+				return
+			}
+			http.Error(w, "could not delete product", http.StatusInternalServerError)
+			return
+		}
+		if p.Quantity > 0 {
+			requestProductDeletion(w, r, p)
+			return
+		}
+	}
 	if err := productRepo.Delete(id); err != nil {
 		if err == repo.ErrProductNotFound {
 			http.Error(w, "product not found", http.StatusNotFound)
@@ -173,19 +395,67 @@ func DeleteProductHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "could not delete product", http.StatusInternalServerError)
 		return
 	}
+	recordAudit(r, "delete", "product", id, nil)
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// MergeProductsHandler godoc
+// @Summary Merge a duplicate product into another
+// @Description Moves the source product's stock and movement history to the target, then deletes the source.
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param merge body MergeProductsRequest true "Source and target products"
+// @Success 200 {object} ProductResponse
+// @Failure 400 {string} string "Invalid input"
+// @Failure 404 {string} string "Not found"
+// @Failure 500 {string} string "Internal error"
+// @Router /admin/products/merge [post]
+func MergeProductsHandler(w http.ResponseWriter, r *http.Request) {
+	var req MergeProductsRequest
+	if err := readJSON(w, r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidInput, "invalid input")
+		return
+	}
+
+	// Closed periods keep their frozen movements under the source ID.
+	merged, err := productRepo.Merge(req.SourceID, req.TargetID)
+	if err != nil {
+		switch {
+		case errors.Is(err, repo.ErrInvalidMerge):
+			writeError(w, http.StatusBadRequest, ErrCodeInvalidInput, err.Error())
+		case errors.Is(err, repo.ErrProductNotFound):
+			writeError(w, http.StatusNotFound, ErrCodeNotFound, "product not found")
+		default:
+			writeError(w, http.StatusInternalServerError, ErrCodeInternal, "could not merge products")
+		}
+		return
+	}
+
+	recordAudit(r, "merge", "product", merged.ID, req)
+
+	resp := newProductResponse(merged)
+	if err := writeJSON(w, http.StatusOK, resp); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
 // UpdateProductHandler godoc
 // @Summary Update a product
+// @Description Saving a placeholder product flagged needs_review marks it reviewed, resolving its import flags in the review queue; saving a price within the price rules resolves its validation flags.
 // @Tags products
 // @Accept json
 // @Produce json
 // @Param id path int true "Product ID"
 // @Param product body ProductRequest true "Updated product"
+// @Param override_price_rules query bool false "Accept a price that breaks the price rules (needs pricing:override)"
 // @Success 200 {object} ProductResponse
 // @Failure 400 {object} map[string]any
+// @Failure 403 {object} ErrorResponse "New category outside the caller's grants"
 // @Failure 404 {string} string "Not found"
+// @Failure 409 {string} string "Product name already exists"
+// @Failure 422 {object} ErrorResponse "Price breaks the price rules"
 // @Failure 500 {string} string "Internal error"
 // @Router /products/{id} [put]
 // @Security BearerAuth
@@ -193,17 +463,17 @@ func UpdateProductHandler(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		http.Error(w, "invalid product ID", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidInput, "invalid product ID")
 		return
 	}
 
 	var req ProductRequest
 	if err := readJSON(w, r, &req); err != nil {
-		http.Error(w, "invalid input", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidInput, "invalid input")
 		return
 	}
 
-	validationErrors := validateProduct(req)
+	validationErrors := append(validateProduct(req), validateTaxClass(req.TaxClassID)...)
 	if len(validationErrors) > 0 {
 		w.WriteHeader(http.StatusBadRequest)
 		if err := writeJSON(w, http.StatusOK, validationErrors); err != nil {
@@ -213,31 +483,89 @@ func UpdateProductHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	product := models.Product{
-		ID:        id,
-		Name:      req.Name,
-		Price:     req.Price,
-		Quantity:  req.Quantity,
-		Threshold: req.Threshold,
-		UpdatedAt: time.Now().Format(time.RFC3339),
+		ID:          id,
+		Name:        req.Name,
+		Price:       req.Price,
+		Cost:        req.Cost,
+		Quantity:    req.Quantity,
+		Threshold:   req.Threshold,
+		Category:    req.Category,
+		SKU:         req.SKU,
+		Barcode:     req.Barcode,
+		Supplier:    req.Supplier,
+		MaxQuantity: req.MaxQuantity,
+		Status:      req.Status,
+		ExternalID:  strings.ToLower(strings.TrimSpace(req.ExternalID)),
+		TaxClassID:  req.TaxClassID,
+		Currency:    strings.ToUpper(strings.TrimSpace(req.Currency)),
+		UpdatedAt:   time.Now().UTC().Format(time.RFC3339),
+
+		Description:  strings.TrimSpace(req.Description),
+		Brand:        strings.TrimSpace(req.Brand),
+		Manufacturer: strings.TrimSpace(req.Manufacturer),
+		Weight:       req.Weight,
+		Dimensions:   req.Dimensions,
+	}
+	before, err := productRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, repo.ErrProductNotFound) {
+			writeError(w, http.StatusNotFound, ErrCodeNotFound, "product not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "could not update product")
+		return
+	}
+	scope, err := accessScope(r)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "could not load access grants")
+		return
+	}
+	if !scope.AllowsCategory(before.Category) {
+		writeError(w, http.StatusNotFound, ErrCodeNotFound, "product not found")
+		return
+	}
+	if !scope.AllowsCategory(product.Category) {
+		writeError(w, http.StatusForbidden, ErrCodeOutOfScope, "category outside your access grants")
+		return
+	}
+	rules, err := priceRulesRepo.Get(tenant)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "could not load price rules")
+		return
+	}
+	priceCheck := screenPrice(r, rules, product.Price, &before.Price)
+	if priceCheck.Rejected {
+		writeError(w, http.StatusUnprocessableEntity, ErrCodeSuspiciousPrice, priceCheck.message())
+		return
 	}
 	updated, err := productRepo.Update(product)
 	if err != nil {
 		if err == repo.ErrProductNotFound {
-			http.Error(w, "product not found", http.StatusNotFound)
+			writeError(w, http.StatusNotFound, ErrCodeNotFound, "product not found")
+			return
+		}
+		if errors.Is(err, repo.ErrDuplicatedExternalID) {
+			writeError(w, http.StatusConflict, ErrCodeDuplicateExternalID, "could not update product: external ID already in use")
 			return
 		}
-		http.Error(w, "could not update product", http.StatusInternalServerError)
+		if errors.Is(err, repo.ErrDuplicatedValueUnique) {
+			writeError(w, http.StatusConflict, ErrCodeDuplicateName, "could not update product: product name duplicated")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "could not update product")
 		return
 	}
 
-	resp := ProductResponse{
-		Id:        updated.ID,
-		Name:      updated.Name,
-		Price:     updated.Price,
-		Quantity:  updated.Quantity,
-		Threshold: updated.Threshold,
-		LowStock:  updated.Quantity < updated.Threshold,
+	recordAudit(r, "update", "product", id, map[string]any{"before": before, "after": updated})
+	recordPriceCheck(r, id, updated.Price, priceCheck)
+	if before.NeedsReview {
+		resolveFlags(r, id, models.FlagSourceImport, "product data completed")
+	}
+	if len(priceCheck.Violations) == 0 {
+		resolveFlags(r, id, models.FlagSourceValidation, "price corrected")
 	}
+	resp := newProductResponse(updated)
+	resp.Warnings = priceCheck.Violations
 	w.Header().Set("Content-Type", "application/json")
 	if err := writeJSON(w, http.StatusOK, resp); err != nil {
 		log.Printf("Failed to write JSON response: %v", err)
@@ -245,39 +573,53 @@ func UpdateProductHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 // FilterProductsHandler godoc
-// @Summary Filter and paginate products
+// @Summary Filter, search and paginate products
+// @Description With q, products are searched by full text over name, SKU and description, tolerating typos in the name, best match first; each result then carries highlights: its matching fields, HTML-escaped, with the matched words in <mark>. Served at /products/filter and /products/search. With a token, users granted categories find only products of those. The health score rates each product from 0 to 100, 25 points each for stock against its threshold, a change within 30 days (none after 180), a margin of 30% or more and filled-in catalog data; sort=health lists what to fix first.
 // @Tags products
 // @Produce json
+// @Param q query string false "Search text (at most 200 characters); supports quoted phrases, or and -word"
 // @Param name query string false "Filter by name"
 // @Param minPrice query number false "Minimum price"
 // @Param maxPrice query number false "Maximum price"
 // @Param minQty query int false "Minimum quantity"
 // @Param maxQty query int false "Maximum quantity"
+// @Param low_stock query bool false "Only products below their threshold"
+// @Param needs_review query bool false "Only placeholder products awaiting review, as created by movement imports"
+// @Param brand query string false "Only products of this brand (case-insensitive)"
+// @Param updated_since query string false "Only products changed at or after this time (RFC3339)"
+// @Param min_health query int false "Only products with a health score of at least this (0-100)"
+// @Param max_health query int false "Only products with a health score of at most this (0-100)"
+// @Param sort query string false "Order: id (default), relevance (default with q), name, price, quantity, updated_at, -updated_at (most recently changed first) or health (lowest score first)"
+// @Param order query string false "asc (default) or desc; does not reverse relevance"
 // @Param offset query int false "Offset for pagination"
 // @Param limit query int false "Limit for pagination"
+// @Param include_total query bool false "Count all matches (default true); when false, total_count is -1 and has_more is set"
+// @Param tax query string false "Price output: exclusive (default, as stored) or inclusive of the product's tax rate"
+// @Param price_list query string false "Name of a price list whose prices replace the regular ones where set"
+// @Param include query string false "Related records to embed: suppliers, health (the health score and its components)"
 // @Success 200 {object} ProductsSearchResult
 // @Failure 400 {string} string "Invalid query"
 // @Failure 500 {string} string "Internal error"
+// @Router /products/filter [get]
 // @Router /products/search [get]
 func FilterProductsHandler(w http.ResponseWriter, r *http.Request) {
-	q := r.URL.Query()
-
-	filter := repo.ProductFilter{
-		Name:     q.Get("name"),
-		MinPrice: parseFloatPtr(q.Get("minPrice")),
-		MaxPrice: parseFloatPtr(q.Get("maxPrice")),
-		MinQty:   parseIntPtr(q.Get("minQty")),
-		MaxQty:   parseIntPtr(q.Get("maxQty")),
-		Offset:   parseIntPtr(q.Get("offset")),
-		Limit:    parseIntPtr(q.Get("limit")),
+	filter, err := parseProductFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
-
-	if filter.Limit != nil && *filter.Limit <= 0 {
-		http.Error(w, "limit must be greater than zero", http.StatusBadRequest)
+	view, err := parsePriceView(r)
+	if err != nil {
+		writePriceViewError(w, err)
 		return
 	}
-	if filter.Offset != nil && *filter.Offset < 0 {
-		http.Error(w, "offset must be zero or positive", http.StatusBadRequest)
+	include, err := parseIncludes(r, productIncludes)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if filter.Scope, err = accessScope(r); err != nil {
+		http.Error(w, "could not load access grants", http.StatusInternalServerError)
 		return
 	}
 
@@ -291,16 +633,33 @@ func FilterProductsHandler(w http.ResponseWriter, r *http.Request) {
 		Data: make([]ProductResponse, len(products)),
 		Meta: Meta{TotalCount: total},
 	}
+	if filter.SkipTotal {
+		offset := 0
+		if filter.Offset != nil {
+			offset = *filter.Offset
+		}
+		hasMore := total > offset+len(products)
+		resp.Meta = Meta{TotalCount: -1, HasMore: &hasMore}
+	}
+	terms := repo.SearchTerms(filter.Query)
+	now := time.Now().UTC()
 	for i, p := range products {
-		resp.Data[i] = ProductResponse{
-			Id:        p.ID,
-			Name:      p.Name,
-			Price:     p.Price,
-			Quantity:  p.Quantity,
-			Threshold: p.Threshold,
-			LowStock:  p.Quantity < p.Threshold,
+		resp.Data[i] = view.product(p)
+		if len(terms) > 0 {
+			resp.Data[i].Highlights = searchHighlights(p, terms)
+		}
+		if include["health"] {
+			resp.Data[i].Health = productHealth(p, now)
 		}
 	}
+	if err := withAvailability(resp.Data); err != nil {
+		http.Error(w, "could not fetch reservations", http.StatusInternalServerError)
+		return
+	}
+	if err := embedIncludes(resp.Data, include); err != nil {
+		http.Error(w, "could not fetch suppliers", http.StatusInternalServerError)
+		return
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := writeJSON(w, http.StatusOK, resp); err != nil {
@@ -309,6 +668,175 @@ func FilterProductsHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+const (
+	defaultSuggestLimit = 10
+	maxSuggestLimit     = 25
+	maxSuggestQuery     = 100
+
+	// suggestCacheTTL is short: a product created a moment ago may be
+	// missing from suggestions for this long.
+	suggestCacheTTL    = 30 * time.Second
+	suggestCachePrefix = "suggest:"
+)
+
+// SuggestProductsHandler godoc
+// @Summary Product name suggestions
+// @Description Lightweight typeahead for autocomplete boxes: products whose name, SKU or brand contains q, names starting with q first, then by similarity. Discontinued products are left out. Answers are cached for 30 seconds.
+// @Tags products
+// @Produce json
+// @Param q query string true "Text typed so far"
+// @Param limit query int false "Maximum suggestions (default 10, max 25)"
+// @Success 200 {array} repo.ProductSuggestion
+// @Failure 400 {string} string "Missing q or invalid limit"
+// @Failure 500 {string} string "Internal error"
+// @Router /products/suggest [get]
+func SuggestProductsHandler(w http.ResponseWriter, r *http.Request) {
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	if q == "" || len(q) > maxSuggestQuery {
+		http.Error(w, fmt.Sprintf("q must have 1 to %d characters", maxSuggestQuery), http.StatusBadRequest)
+		return
+	}
+	limit := defaultSuggestLimit
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		limit = min(n, maxSuggestLimit)
+	}
+
+	scope, err := accessScope(r)
+	if err != nil {
+		http.Error(w, "could not load access grants", http.StatusInternalServerError)
+		return
+	}
+
+	// Only unrestricted answers are shared through the cache.
+	cacheable := scope.Categories == nil
+	w.Header().Set("Cache-Control", fmt.Sprintf("private, max-age=%d", int(suggestCacheTTL.Seconds())))
+	key := fmt.Sprintf("%s%s:%d:%s", suggestCachePrefix, tenant, limit, strings.ToLower(q))
+	if cacheable {
+		if cached, err := Rdb.Get(Ctx, key).Bytes(); err == nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write(cached)
+			return
+		}
+	}
+
+	suggestions, err := productRepo.Suggest(q, limit, scope)
+	if err != nil {
+		http.Error(w, "could not fetch suggestions", http.StatusInternalServerError)
+		return
+	}
+	if raw, err := json.Marshal(suggestions); err == nil && cacheable {
+		_ = Rdb.Set(Ctx, key, raw, suggestCacheTTL).Err()
+	}
+	if err := writeJSON(w, http.StatusOK, suggestions); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// productHealth scores p for include=health. It works on the stored
+// product, so tax and price list views do not change the margin.
+func productHealth(p models.Product, now time.Time) *repo.ProductHealth {
+	health := repo.HealthOf(p, now)
+	return &health
+}
+
+// parseProductFilter reads the filter, sort and pagination parameters of
+// product listings. The caller's access scope is left for the caller.
+func parseProductFilter(r *http.Request) (repo.ProductFilter, error) {
+	q := r.URL.Query()
+
+	filter := repo.ProductFilter{
+		Name:     q.Get("name"),
+		Query:    strings.TrimSpace(q.Get("q")),
+		MinPrice: parseFloatPtr(q.Get("minPrice")),
+		MaxPrice: parseFloatPtr(q.Get("maxPrice")),
+		MinQty:   parseIntPtr(q.Get("minQty")),
+		MaxQty:   parseIntPtr(q.Get("maxQty")),
+		Brand:    strings.TrimSpace(q.Get("brand")),
+		Offset:   parseIntPtr(q.Get("offset")),
+		Limit:    parseIntPtr(q.Get("limit")),
+	}
+	if len(filter.Query) > maxSearchQueryLength {
+		return filter, fmt.Errorf("q must be at most %d characters", maxSearchQueryLength)
+	}
+	if v := q.Get("include_total"); v != "" {
+		include, err := strconv.ParseBool(v)
+		if err != nil {
+			return filter, errors.New("include_total must be true or false")
+		}
+		filter.SkipTotal = !include
+	}
+	if v := q.Get("low_stock"); v != "" {
+		lowStock, err := strconv.ParseBool(v)
+		if err != nil {
+			return filter, errors.New("low_stock must be true or false")
+		}
+		filter.LowStock = lowStock
+	}
+	if v := q.Get("needs_review"); v != "" {
+		needsReview, err := strconv.ParseBool(v)
+		if err != nil {
+			return filter, errors.New("needs_review must be true or false")
+		}
+		filter.NeedsReview = needsReview
+	}
+	updatedSince, err := parseTime(q.Get("updated_since"))
+	if err != nil {
+		return filter, errors.New("updated_since must be an RFC3339 timestamp")
+	}
+	filter.UpdatedSince = updatedSince
+	if filter.MinHealth, err = parseHealth(q.Get("min_health"), "min_health"); err != nil {
+		return filter, err
+	}
+	if filter.MaxHealth, err = parseHealth(q.Get("max_health"), "max_health"); err != nil {
+		return filter, err
+	}
+	if filter.Sort, filter.Descending, err = parseProductSort(r); err != nil {
+		return filter, err
+	}
+	if filter.Limit != nil && *filter.Limit <= 0 {
+		return filter, errors.New("limit must be greater than zero")
+	}
+	if filter.Offset != nil && *filter.Offset < 0 {
+		return filter, errors.New("offset must be zero or positive")
+	}
+	return filter, nil
+}
+
+// parseHealth reads the health score bound named param, if given.
+func parseHealth(raw, param string) (*int, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	score, err := strconv.Atoi(raw)
+	if err != nil || score < 0 || score > 100 {
+		return nil, fmt.Errorf("%s must be an integer from 0 to 100", param)
+	}
+	return &score, nil
+}
+
+// parseProductSort reads the sort and order parameters of product
+// listings, accepting only the repo.ProductSorts orders.
+func parseProductSort(r *http.Request) (string, bool, error) {
+	q := r.URL.Query()
+	sort := strings.ToLower(strings.TrimSpace(q.Get("sort")))
+	if sort != "" && !slices.Contains(repo.ProductSorts, sort) {
+		return "", false, fmt.Errorf("sort must be one of %s", strings.Join(repo.ProductSorts, ", "))
+	}
+	switch order := strings.ToLower(strings.TrimSpace(q.Get("order"))); order {
+	case "", "asc":
+		return sort, false, nil
+	case "desc":
+		return sort, true, nil
+	}
+	return "", false, errors.New("order must be asc or desc")
+}
+
 func parseFloatPtr(s string) *float64 {
 	if s == "" {
 		return nil
@@ -330,3 +858,36 @@ func parseIntPtr(s string) *int {
 	}
 	return &v
 }
+
+var errQuotaExceeded = errors.New("product quota exceeded")
+
+// countProducts returns how many products exist, whatever their status.
+func countProducts() (int, error) {
+	limit := 1
+	_, total, err := productRepo.Filter(repo.ProductFilter{Limit: &limit})
+	return total, err
+}
+
+// checkProductQuota fails with errQuotaExceeded when adding more products
+// would go over the configured quota.
+func checkProductQuota(adding int) error {
+	if productQuota <= 0 {
+		return nil
+	}
+	used, err := countProducts()
+	if err != nil {
+		return err
+	}
+	if used+adding > productQuota {
+		return fmt.Errorf("%w: %d of %d products used", errQuotaExceeded, used, productQuota)
+	}
+	return nil
+}
+
+func writeQuotaError(w http.ResponseWriter, err error) {
+	if errors.Is(err, errQuotaExceeded) {
+		writeError(w, http.StatusForbidden, ErrCodeQuotaExceeded, err.Error())
+		return
+	}
+	writeError(w, http.StatusInternalServerError, ErrCodeInternal, "could not check product quota")
+}
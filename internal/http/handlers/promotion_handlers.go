@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+)
+
+const maxPromotionNameLength = 100
+
+func normalizePromotion(req PromotionRequest, now time.Time) (models.Promotion, error) {
+	p := models.Promotion{
+		Name:       strings.TrimSpace(req.Name),
+		ProductID:  req.ProductID,
+		Category:   strings.TrimSpace(req.Category),
+		Price:      roundMoney(req.Price),
+		PercentOff: req.PercentOff,
+	}
+	switch {
+	case p.Name == "":
+		return p, errors.New("name is required")
+	case len(p.Name) > maxPromotionNameLength:
+		return p, fmt.Errorf("name must be at most %d characters", maxPromotionNameLength)
+	case (p.ProductID == nil) == (p.Category == ""):
+		return p, errors.New("set exactly one of product_id and category")
+	case p.ProductID != nil && *p.ProductID <= 0:
+		return p, errors.New("product_id must be positive")
+	case (p.Price == 0) == (p.PercentOff == 0):
+		return p, errors.New("set exactly one of price and percent_off")
+	case p.Price < 0:
+		return p, errors.New("price must be greater than zero")
+	case p.PercentOff < 0 || p.PercentOff >= 100:
+		return p, errors.New("percent_off must be greater than 0 and less than 100")
+	}
+
+	startsAt, err := parseTime(req.StartsAt)
+	if err != nil || startsAt == nil {
+		return p, errors.New("starts_at must be an RFC 3339 time")
+	}
+	endsAt, err := parseTime(req.EndsAt)
+	if err != nil || endsAt == nil {
+		return p, errors.New("ends_at must be an RFC 3339 time")
+	}
+	p.StartsAt, p.EndsAt = startsAt.UTC(), endsAt.UTC()
+	switch {
+	case !p.EndsAt.After(p.StartsAt):
+		return p, errors.New("ends_at must be after starts_at")
+	case !p.EndsAt.After(now):
+		return p, errors.New("ends_at must be in the future")
+	}
+	p.Active = p.Running(now)
+	return p, nil
+}
+
+// CreatePromotionHandler godoc
+// @Summary Create a promotion
+// @Description Overrides the sale price of a product, or of every product in a category, between starts_at and ends_at. Product reads show the promotional price and flag it while the promotion is active; promotions start and end on their own within a minute of their window. A promotion never raises a price, and when several apply the lowest price wins.
+// @Tags pricing
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param promotion body PromotionRequest true "Promotion"
+// @Success 201 {object} models.Promotion
+// @Failure 400 {string} string "Invalid input"
+// @Failure 403 {string} string "Admins only"
+// @Failure 404 {string} string "Product not found"
+// @Failure 500 {string} string "Internal error"
+// @Router /promotions [post]
+func CreatePromotionHandler(w http.ResponseWriter, r *http.Request) {
+	var req PromotionRequest
+	if err := readJSON(w, r, &req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	p, err := normalizePromotion(req, time.Now().UTC())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	created, err := promotionRepo.Create(p)
+	if err != nil {
+		writePromotionError(w, err)
+		return
+	}
+
+	recordAudit(r, "create", "promotion", created.ID, created)
+	if err := writeJSON(w, http.StatusCreated, created); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// ListPromotionsHandler godoc
+// @Summary List promotions
+// @Tags pricing
+// @Security BearerAuth
+// @Produce json
+// @Param active query bool false "Only promotions currently changing prices"
+// @Success 200 {array} models.Promotion
+// @Failure 400 {string} string "Invalid active filter"
+// @Failure 500 {string} string "Internal error"
+// @Router /promotions [get]
+func ListPromotionsHandler(w http.ResponseWriter, r *http.Request) {
+	activeOnly := false
+	switch r.URL.Query().Get("active") {
+	case "", "false":
+	case "true":
+		activeOnly = true
+	default:
+		http.Error(w, "active must be true or false", http.StatusBadRequest)
+		return
+	}
+
+	promotions, err := promotionRepo.List(activeOnly)
+	if err != nil {
+		http.Error(w, "could not fetch promotions", http.StatusInternalServerError)
+		return
+	}
+	if err := writeJSON(w, http.StatusOK, promotions); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// GetPromotionHandler godoc
+// @Summary Get a promotion
+// @Tags pricing
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Promotion ID"
+// @Success 200 {object} models.Promotion
+// @Failure 400 {string} string "Invalid ID"
+// @Failure 404 {string} string "Promotion not found"
+// @Failure 500 {string} string "Internal error"
+// @Router /promotions/{id} [get]
+func GetPromotionHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid promotion ID", http.StatusBadRequest)
+		return
+	}
+
+	p, err := promotionRepo.GetByID(id)
+	if err != nil {
+		writePromotionError(w, err)
+		return
+	}
+	if err := writeJSON(w, http.StatusOK, p); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// DeletePromotionHandler godoc
+// @Summary Delete a promotion
+// @Description Prices return to normal at once, even mid-window.
+// @Tags pricing
+// @Security BearerAuth
+// @Param id path int true "Promotion ID"
+// @Success 204 "No Content"
+// @Failure 400 {string} string "Invalid ID"
+// @Failure 403 {string} string "Admins only"
+// @Failure 404 {string} string "Promotion not found"
+// @Failure 500 {string} string "Internal error"
+// @Router /promotions/{id} [delete]
+func DeletePromotionHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid promotion ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := promotionRepo.Delete(id); err != nil {
+		writePromotionError(w, err)
+		return
+	}
+
+	recordAudit(r, "delete", "promotion", id, nil)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writePromotionError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, repo.ErrPromotionNotFound):
+		http.Error(w, "promotion not found", http.StatusNotFound)
+	case errors.Is(err, repo.ErrProductNotFound):
+		http.Error(w, "product not found", http.StatusNotFound)
+	default:
+		log.Printf("promotion: %v", err)
+		http.Error(w, "could not process promotion", http.StatusInternalServerError)
+	}
+}
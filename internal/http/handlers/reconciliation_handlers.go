@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+// GetReconciliationHandler godoc
+// @Summary Report products whose quantity does not match their movement history
+// @Description Expected quantity is the product baseline (initial or imported quantity) plus the sum of its movements.
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} ReconciliationReport
+// @Failure 500 {string} string "Internal error"
+// @Router /admin/reconciliation [get]
+func GetReconciliationHandler(w http.ResponseWriter, r *http.Request) {
+	report, err := buildReconciliationReport()
+	if err != nil {
+		log.Printf("failed to build reconciliation report: %v", err)
+		http.Error(w, "could not build reconciliation report", http.StatusInternalServerError)
+		return
+	}
+
+	if err := writeJSON(w, http.StatusOK, report); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// CorrectReconciliationHandler godoc
+// @Summary Create corrective movements for every reconciliation discrepancy
+// @Description Product quantities are left untouched; a movement equal to each difference is logged so the ledger matches stock again.
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} ReconciliationReport
+// @Failure 500 {string} string "Internal error"
+// @Router /admin/reconciliation/corrections [post]
+func CorrectReconciliationHandler(w http.ResponseWriter, r *http.Request) {
+	report, err := buildReconciliationReport()
+	if err != nil {
+		log.Printf("failed to build reconciliation report: %v", err)
+		http.Error(w, "could not build reconciliation report", http.StatusInternalServerError)
+		return
+	}
+
+	if err := ensurePeriodOpen(time.Now()); err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	for i, d := range report.Discrepancies {
+		if _, err := movementRepo.Log(models.Movement{ProductID: d.ProductID, Delta: d.Difference}); err != nil {
+			log.Printf("failed to log corrective movement for product %d: %v", d.ProductID, err)
+			continue
+		}
+		report.Discrepancies[i].Corrected = true
+		recordAudit(r, "correct", "product", d.ProductID, d)
+	}
+
+	if err := writeJSON(w, http.StatusOK, report); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+func buildReconciliationReport() (ReconciliationReport, error) {
+	products, err := productRepo.GetAll()
+	if err != nil {
+		return ReconciliationReport{}, err
+	}
+
+	sums, err := movementRepo.SumDeltasByProduct()
+	if err != nil {
+		return ReconciliationReport{}, err
+	}
+
+	report := ReconciliationReport{
+		CheckedProducts: len(products),
+		Discrepancies:   []ReconciliationEntry{},
+	}
+	for _, p := range products {
+		expected := p.BaselineQuantity + sums[p.ID]
+		if p.Quantity == expected {
+			continue
+		}
+		report.Discrepancies = append(report.Discrepancies, ReconciliationEntry{
+			ProductID:        p.ID,
+			ProductName:      p.Name,
+			Quantity:         p.Quantity,
+			BaselineQuantity: p.BaselineQuantity,
+			MovementSum:      sums[p.ID],
+			ExpectedQuantity: expected,
+			Difference:       p.Quantity - expected,
+		})
+	}
+
+	return report, nil
+}
@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+)
+
+const (
+	repricingPreviewPrefix = "repricing_preview:"
+	repricingPreviewTTL    = 15 * time.Minute
+)
+
+// RepricingHandler godoc
+// @Summary Re-price many products with a preview step
+// @Description Without preview_id, computes the new prices of the filtered products and stores them as a preview for 15 minutes; nothing is changed. With preview_id, applies that preview once, refusing it if any price moved since. Every changed product gets its own audit entry.
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param repricing body RepricingRequest true "Filter and rule, or a preview to apply"
+// @Success 200 {object} RepricingPreview "Preview"
+// @Success 201 {object} RepricingResult "Applied"
+// @Failure 400 {object} ErrorResponse "Invalid input"
+// @Failure 404 {object} ErrorResponse "Preview not found or expired"
+// @Failure 409 {object} ErrorResponse "Prices changed since the preview"
+// @Failure 500 {object} ErrorResponse "Internal error"
+// @Router /admin/repricing [post]
+func RepricingHandler(w http.ResponseWriter, r *http.Request) {
+	var req RepricingRequest
+	if err := readJSON(w, r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidInput, "invalid request body")
+		return
+	}
+	if req.PreviewID != "" {
+		applyRepricing(w, r, req.PreviewID)
+		return
+	}
+	if err := validatePricingRule(req.Rule); err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidInput, err.Error())
+		return
+	}
+
+	products, err := productRepo.GetAll()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "could not fetch products")
+		return
+	}
+
+	preview := RepricingPreview{Changes: []RepricingChange{}, Skipped: []RepricingSkip{}}
+	var changes []repo.PriceChange
+	for _, p := range products {
+		if !req.Filter.matches(p) {
+			continue
+		}
+		preview.Matched++
+		price, reason := req.Rule.apply(p)
+		switch {
+		case reason != "":
+			preview.Skipped = append(preview.Skipped, RepricingSkip{ProductID: p.ID, Name: p.Name, Reason: reason})
+		case price == p.Price:
+			preview.Unchanged++
+		default:
+			preview.Changes = append(preview.Changes, RepricingChange{ProductID: p.ID, Name: p.Name, Cost: p.Cost, PriceBefore: p.Price, PriceAfter: price})
+			changes = append(changes, repo.PriceChange{ProductID: p.ID, From: p.Price, To: price})
+		}
+	}
+
+	if len(changes) > 0 {
+		raw, err := json.Marshal(changes)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, ErrCodeInternal, "could not store preview")
+			return
+		}
+		id := generateRandomToken()
+		if id == "" || Rdb.Set(Ctx, repricingPreviewPrefix+id, raw, repricingPreviewTTL).Err() != nil {
+			writeError(w, http.StatusInternalServerError, ErrCodeInternal, "could not store preview")
+			return
+		}
+		expiresAt := time.Now().UTC().Add(repricingPreviewTTL)
+		preview.PreviewID, preview.ExpiresAt = id, &expiresAt
+	}
+
+	if err := writeJSON(w, http.StatusOK, preview); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// applyRepricing consumes the preview, so it can be applied only once.
+func applyRepricing(w http.ResponseWriter, r *http.Request, previewID string) {
+	raw, err := Rdb.GetDel(Ctx, repricingPreviewPrefix+previewID).Bytes()
+	if errors.Is(err, redis.Nil) {
+		writeError(w, http.StatusNotFound, ErrCodeNotFound, "preview not found or expired")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "could not load preview")
+		return
+	}
+	var changes []repo.PriceChange
+	if err := json.Unmarshal(raw, &changes); err != nil {
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "could not load preview")
+		return
+	}
+
+	if err := productRepo.Reprice(changes); err != nil {
+		if errors.Is(err, repo.ErrPriceChanged) {
+			writeError(w, http.StatusConflict, ErrCodeConflict, "prices changed since the preview; preview again")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "could not update prices")
+		return
+	}
+
+	for _, c := range changes {
+		recordAudit(r, "reprice", "product", c.ProductID, map[string]any{
+			"preview_id":   previewID,
+			"price_before": c.From,
+			"price_after":  c.To,
+		})
+	}
+	if err := writeJSON(w, http.StatusCreated, RepricingResult{PreviewID: previewID, Updated: len(changes)}); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+func (f RepricingFilter) matches(p models.Product) bool {
+	switch {
+	case len(f.ProductIDs) > 0 && !slices.Contains(f.ProductIDs, p.ID):
+		return false
+	case f.Name != "" && !strings.Contains(strings.ToLower(p.Name), strings.ToLower(f.Name)):
+		return false
+	case f.Category != "" && !strings.EqualFold(p.Category, f.Category):
+		return false
+	case f.Supplier != "" && !strings.EqualFold(p.Supplier, f.Supplier):
+		return false
+	case f.MinPrice != nil && p.Price < *f.MinPrice:
+		return false
+	case f.MaxPrice != nil && p.Price > *f.MaxPrice:
+		return false
+	}
+	return true
+}
+
+func validatePricingRule(rule PricingRule) error {
+	switch rule.Type {
+	case "percentage":
+		if rule.Percent <= -100 {
+			return errors.New("percent must be greater than -100")
+		}
+	case "margin":
+		if rule.Percent < 0 || rule.Percent >= 100 {
+			return errors.New("margin percent must be at least 0 and below 100")
+		}
+	default:
+		return errors.New("rule type must be percentage or margin")
+	}
+	if rd := rule.Rounding; rd != nil {
+		if rd.Step < 0 {
+			return errors.New("rounding step cannot be negative")
+		}
+		if rd.Ending != nil && (*rd.Ending < 0 || *rd.Ending >= 1) {
+			return errors.New("rounding ending must be at least 0 and below 1")
+		}
+		if rd.Direction != "" && rd.Direction != "nearest" && rd.Direction != "up" && rd.Direction != "down" {
+			return errors.New("rounding direction must be nearest, up or down")
+		}
+	}
+	return nil
+}
+
+// apply returns p's new price, or why the rule cannot price it.
+func (rule PricingRule) apply(p models.Product) (float64, string) {
+	var price float64
+	if rule.Type == "margin" {
+		if p.Cost <= 0 {
+			return 0, "no_cost"
+		}
+		price = p.Cost / (1 - rule.Percent/100)
+	} else {
+		price = p.Price * (1 + rule.Percent/100)
+	}
+	price = roundPrice(price, rule.Rounding)
+	if price <= 0 {
+		return 0, "not_positive"
+	}
+	return price, ""
+}
+
+// roundPrice works in cents, so that float noise such as 19.949999 does not
+// push a price across a rounding boundary.
+func roundPrice(price float64, rd *PriceRounding) float64 {
+	cents := math.Round(price*100*1e6) / 1e6
+	if rd == nil {
+		return math.Round(cents) / 100
+	}
+
+	round := math.Round
+	switch rd.Direction {
+	case "up":
+		round = math.Ceil
+	case "down":
+		round = math.Floor
+	}
+	if rd.Ending != nil {
+		ending := math.Round(*rd.Ending * 100)
+		return (round((cents-ending)/100)*100 + ending) / 100
+	}
+	step := math.Round(rd.Step * 100)
+	if step < 1 {
+		step = 1
+	}
+	return round(cents/step) * step / 100
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+)
+
+const (
+	defaultReservationTTL         = 15 * time.Minute
+	maxReservationTTL             = 7 * 24 * time.Hour
+	maxReservationReferenceLength = 100
+)
+
+// CreateReservationHandler godoc
+// @Summary Reserve stock for a pending order
+// @Description Holds units of the product until the reservation expires or is released, so they cannot be sold twice. Reserved units stay in the product's quantity, but adjustments that would take them fail. Expired reservations stop holding stock at once and are removed by a background worker.
+// @Tags inventory
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param reservation body ReservationRequest true "Units and time to live"
+// @Success 201 {object} models.StockReservation
+// @Failure 400 {string} string "Invalid input"
+// @Failure 404 {string} string "Product not found"
+// @Failure 409 {string} string "Not enough unreserved stock"
+// @Failure 500 {string} string "Internal error"
+// @Router /products/{id}/reservations [post]
+func CreateReservationHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid product ID", http.StatusBadRequest)
+		return
+	}
+	var req ReservationRequest
+	if err := readJSON(w, r, &req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	ttl := defaultReservationTTL
+	if req.TTLSeconds != 0 {
+		ttl = time.Duration(req.TTLSeconds) * time.Second
+	}
+	res := models.StockReservation{ProductID: id, Quantity: req.Quantity, Reference: strings.TrimSpace(req.Reference)}
+	switch {
+	case res.Quantity <= 0:
+		http.Error(w, "quantity must be positive", http.StatusBadRequest)
+		return
+	case len(res.Reference) > maxReservationReferenceLength:
+		http.Error(w, fmt.Sprintf("reference must be at most %d characters", maxReservationReferenceLength), http.StatusBadRequest)
+		return
+	case ttl <= 0 || ttl > maxReservationTTL:
+		http.Error(w, fmt.Sprintf("ttl_seconds must be between 1 and %d", int(maxReservationTTL.Seconds())), http.StatusBadRequest)
+		return
+	}
+	if res.CreatedBy, err = GetUsernameFromContext(r); err != nil {
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	res.ExpiresAt = time.Now().UTC().Add(ttl)
+	if !productAccessible(w, r, id) {
+		return
+	}
+
+	created, err := reservationRepo.Create(res)
+	if err != nil {
+		writeReservationError(w, err)
+		return
+	}
+
+	recordAudit(r, "reserve", "product", id, created)
+	if err := writeJSON(w, http.StatusCreated, created); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// DeleteReservationHandler godoc
+// @Summary Release a stock reservation
+// @Description Frees the units held, for instance when the order is cancelled or about to be fulfilled.
+// @Tags inventory
+// @Security BearerAuth
+// @Param id path int true "Reservation ID"
+// @Success 204 "Released"
+// @Failure 400 {string} string "Invalid ID"
+// @Failure 404 {string} string "Reservation not found"
+// @Failure 500 {string} string "Internal error"
+// @Router /reservations/{id} [delete]
+func DeleteReservationHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid reservation ID", http.StatusBadRequest)
+		return
+	}
+	scope, err := accessScope(r)
+	if err != nil {
+		http.Error(w, "could not load access grants", http.StatusInternalServerError)
+		return
+	}
+	res, err := reservationRepo.GetByID(id)
+	if err == nil {
+		if err = productInScope(scope, res.ProductID); errors.Is(err, repo.ErrProductNotFound) {
+			err = repo.ErrReservationNotFound
+		}
+	}
+	if err != nil {
+		writeReservationError(w, err)
+		return
+	}
+	if err := reservationRepo.Delete(id); err != nil {
+		writeReservationError(w, err)
+		return
+	}
+
+	recordAudit(r, "release", "reservation", id, res)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// withAvailability sets how much of each product's quantity is reserved and
+// how much is available.
+func withAvailability(products []ProductResponse) error {
+	ids := make([]int, len(products))
+	for i, p := range products {
+		ids[i] = p.Id
+	}
+	reserved, err := reservationRepo.Reserved(ids, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	for i := range products {
+		units := reserved[products[i].Id]
+		available := max(products[i].Quantity-units, 0)
+		products[i].Reserved, products[i].Available = &units, &available
+	}
+	return nil
+}
+
+func writeReservationError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, repo.ErrProductNotFound):
+		http.Error(w, "product not found", http.StatusNotFound)
+	case errors.Is(err, repo.ErrReservationNotFound):
+		http.Error(w, "reservation not found", http.StatusNotFound)
+	case errors.Is(err, repo.ErrStockReserved):
+		http.Error(w, "not enough unreserved stock", http.StatusConflict)
+	default:
+		log.Printf("reservation: %v", err)
+		http.Error(w, "could not process reservation", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,245 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+)
+
+// CreateReturnHandler godoc
+// @Summary Register a customer return
+// @Description The return references the outbound movement that shipped the goods. Stock is not touched until inspection restocks the items.
+// @Tags returns
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param return body ReturnRequest true "Return"
+// @Success 201 {object} models.Return
+// @Failure 400 {string} string "Invalid input"
+// @Failure 404 {string} string "Movement not found"
+// @Failure 409 {string} string "More returned than shipped"
+// @Failure 500 {string} string "Internal error"
+// @Router /returns [post]
+func CreateReturnHandler(w http.ResponseWriter, r *http.Request) {
+	var req ReturnRequest
+	if err := readJSON(w, r, &req); err != nil {
+		http.Error(w, "invalid input", http.StatusBadRequest)
+		return
+	}
+	if req.Quantity <= 0 {
+		http.Error(w, "quantity must be positive", http.StatusBadRequest)
+		return
+	}
+
+	movement, err := movementRepo.GetByID(req.MovementID)
+	if err != nil {
+		if errors.Is(err, repo.ErrMovementNotFound) {
+			http.Error(w, "movement not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "could not fetch movement", http.StatusInternalServerError)
+		return
+	}
+	if movement.Delta >= 0 {
+		http.Error(w, "returns must reference an outbound movement", http.StatusBadRequest)
+		return
+	}
+	if !productAccessible(w, r, movement.ProductID) {
+		return
+	}
+
+	returned, err := returnRepo.ReturnedQuantity(movement.ID)
+	if err != nil {
+		http.Error(w, "could not fetch previous returns", http.StatusInternalServerError)
+		return
+	}
+	if returned+req.Quantity > -movement.Delta {
+		http.Error(w, "returned quantity exceeds the quantity shipped", http.StatusConflict)
+		return
+	}
+
+	username, err := GetUsernameFromContext(r)
+	if err != nil {
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	ret, err := returnRepo.Create(models.Return{
+		ProductID:  movement.ProductID,
+		MovementID: movement.ID,
+		OrderRef:   strings.TrimSpace(req.OrderRef),
+		Quantity:   req.Quantity,
+		Reason:     strings.TrimSpace(req.Reason),
+		CreatedBy:  username,
+	})
+	if err != nil {
+		http.Error(w, "could not save return", http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(r, "create", "return", ret.ID, ret)
+	if err := writeJSON(w, http.StatusCreated, ret); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// ListReturnsHandler godoc
+// @Summary List customer returns, newest first
+// @Tags returns
+// @Security BearerAuth
+// @Produce json
+// @Param status query string false "pending, restock, refurbish or scrap"
+// @Success 200 {array} models.Return
+// @Failure 500 {string} string "Internal error"
+// @Router /returns [get]
+func ListReturnsHandler(w http.ResponseWriter, r *http.Request) {
+	returns, err := returnRepo.List(r.URL.Query().Get("status"))
+	if err != nil {
+		http.Error(w, "could not fetch returns", http.StatusInternalServerError)
+		return
+	}
+	if err := writeJSON(w, http.StatusOK, returns); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// GetReturnHandler godoc
+// @Summary Get a customer return
+// @Tags returns
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Return ID"
+// @Success 200 {object} models.Return
+// @Failure 400 {string} string "Invalid input"
+// @Failure 404 {string} string "Return not found"
+// @Failure 500 {string} string "Internal error"
+// @Router /returns/{id} [get]
+func GetReturnHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid return ID", http.StatusBadRequest)
+		return
+	}
+
+	ret, err := returnRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, repo.ErrReturnNotFound) {
+			http.Error(w, "return not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "could not fetch return", http.StatusInternalServerError)
+		return
+	}
+	if err := writeJSON(w, http.StatusOK, ret); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// InspectReturnHandler godoc
+// @Summary Record the inspection outcome of a return
+// @Description restock puts the items back in stock with an inbound movement; refurbish keeps them aside until a later restock or scrap; scrap closes the return without touching stock.
+// @Tags returns
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Return ID"
+// @Param inspection body InspectReturnRequest true "Inspection outcome"
+// @Success 200 {object} models.Return
+// @Failure 400 {string} string "Invalid input"
+// @Failure 403 {string} string "Restock by a caller restricted to warehouses"
+// @Failure 404 {string} string "Return not found"
+// @Failure 409 {string} string "Transition not allowed"
+// @Failure 500 {string} string "Internal error"
+// @Router /returns/{id}/inspect [post]
+func InspectReturnHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid return ID", http.StatusBadRequest)
+		return
+	}
+
+	var req InspectReturnRequest
+	if err := readJSON(w, r, &req); err != nil {
+		http.Error(w, "invalid input", http.StatusBadRequest)
+		return
+	}
+	req.Status = strings.ToLower(strings.TrimSpace(req.Status))
+	switch req.Status {
+	case models.ReturnStatusRestock, models.ReturnStatusRefurbish, models.ReturnStatusScrap:
+	default:
+		http.Error(w, "status must be restock, refurbish or scrap", http.StatusBadRequest)
+		return
+	}
+
+	ret, err := returnRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, repo.ErrReturnNotFound) {
+			http.Error(w, "return not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "could not fetch return", http.StatusInternalServerError)
+		return
+	}
+	if !models.ValidReturnTransition(ret.Status, req.Status) {
+		http.Error(w, "a "+ret.Status+" return cannot move to "+req.Status, http.StatusConflict)
+		return
+	}
+	// Restocking changes stock, so it needs the same grants as an adjustment.
+	if req.Status == models.ReturnStatusRestock {
+		if !adjustmentInScope(w, r, ret.ProductID, nil) {
+			return
+		}
+	} else if !productAccessible(w, r, ret.ProductID) {
+		return
+	}
+
+	username, err := GetUsernameFromContext(r)
+	if err != nil {
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	// Claim the transition before touching stock so a concurrent inspection
+	// cannot restock the same items twice.
+	updated, err := returnRepo.Inspect(id, ret.Status, models.Return{
+		Status:         req.Status,
+		InspectionNote: strings.TrimSpace(req.Note),
+		InspectedBy:    username,
+	})
+	if err != nil {
+		if errors.Is(err, repo.ErrReturnStatusChanged) {
+			http.Error(w, "return was inspected concurrently", http.StatusConflict)
+			return
+		}
+		http.Error(w, "could not update return", http.StatusInternalServerError)
+		return
+	}
+
+	if updated.Status == models.ReturnStatusRestock {
+		if _, err := productRepo.AdjustQuantity(updated.ProductID, updated.Quantity); err != nil {
+			log.Printf("return %d marked restocked but stock of product %d was not updated: %v", id, updated.ProductID, err)
+			http.Error(w, "could not restock items", http.StatusInternalServerError)
+			return
+		}
+		movement, err := movementRepo.Log(models.Movement{ProductID: updated.ProductID, Delta: updated.Quantity, CreatedAt: time.Now().UTC().Format(time.RFC3339), Reason: models.MovementReasonReturn})
+		recordMovementLog(updated.ProductID, updated.Quantity, err)
+		if err == nil {
+			if err := returnRepo.SetRestockMovement(id, movement.ID); err != nil {
+				log.Printf("failed to link return %d to restock movement %d: %v", id, movement.ID, err)
+			} else {
+				updated.RestockMovementID = &movement.ID
+			}
+		}
+	}
+
+	recordAudit(r, "inspect", "return", id, map[string]any{"before": ret, "after": updated})
+	if err := writeJSON(w, http.StatusOK, updated); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
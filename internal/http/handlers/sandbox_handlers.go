@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+	"github.com/rogerio-castellano/inventory-tracker/internal/security"
+)
+
+const (
+	SimulateStockChanges  = "stock_changes"
+	SimulateRateLimit     = "rate_limit"
+	SimulateWebhookEvents = "webhook_events"
+
+	maxSimulatedItems = 100
+	// sandboxRetryAfter is the wait advertised by a simulated rate limit.
+	sandboxRetryAfter = 30
+)
+
+var sandboxSeverities = []string{security.SeverityInfo, security.SeverityWarning, security.SeverityCritical}
+
+// SandboxSimulateHandler godoc
+// @Summary Simulate stock changes, rate limiting or webhook events
+// @Description Lets integration partners exercise their error handling on demand. stock_changes applies count random adjustments, logged as movements, to the given product or to products picked among all of them. rate_limit answers 429 with the headers of a real rate-limited response. webhook_events publishes count sandbox events to the configured security sinks. The same seed always produces the same changes and events. Only available when SANDBOX_MODE is on, which must never be the case on real stock.
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param simulation body SandboxSimulateRequest true "What to simulate"
+// @Success 200 {object} SandboxSimulation
+// @Failure 400 {string} string "Invalid input"
+// @Failure 404 {string} string "Sandbox mode is off, or product not found"
+// @Failure 409 {string} string "Period closed"
+// @Failure 429 {string} string "Simulated rate limit"
+// @Failure 500 {string} string "Internal error"
+// @Router /admin/sandbox/simulate [post]
+func SandboxSimulateHandler(w http.ResponseWriter, r *http.Request) {
+	if !sandboxEnabled {
+		http.NotFound(w, r)
+		return
+	}
+
+	var req SandboxSimulateRequest
+	if err := readJSON(w, r, &req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Count == 0 {
+		req.Count = 1
+	}
+	if req.Seed == 0 {
+		req.Seed = 1
+	}
+	if req.Count < 0 || req.Count > maxSimulatedItems {
+		http.Error(w, fmt.Sprintf("count must be between 1 and %d", maxSimulatedItems), http.StatusBadRequest)
+		return
+	}
+	rng := rand.New(rand.NewSource(req.Seed))
+	sim := SandboxSimulation{Kind: req.Kind, Seed: req.Seed}
+
+	switch req.Kind {
+	case SimulateRateLimit:
+		w.Header().Set("X-RateLimit-Limit", "10")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.Itoa(sandboxRetryAfter))
+		w.Header().Set("Retry-After", strconv.Itoa(sandboxRetryAfter))
+		http.Error(w, "Too many requests", http.StatusTooManyRequests)
+		return
+	case SimulateStockChanges:
+		if err := ensurePeriodOpen(time.Now().UTC()); err != nil {
+			if errors.Is(err, errPeriodClosed) {
+				http.Error(w, err.Error(), http.StatusConflict)
+				return
+			}
+			http.Error(w, "could not verify accounting period", http.StatusInternalServerError)
+			return
+		}
+		changes, err := simulateStockChanges(rng, req.ProductID, req.Count)
+		if err != nil {
+			if errors.Is(err, repo.ErrProductNotFound) {
+				http.Error(w, "product not found", http.StatusNotFound)
+				return
+			}
+			log.Printf("sandbox stock changes stopped: %v", err)
+			http.Error(w, "could not simulate stock changes", http.StatusInternalServerError)
+			return
+		}
+		sim.StockChanges = changes
+	case SimulateWebhookEvents:
+		username, _ := GetUsernameFromContext(r)
+		for i := 1; i <= req.Count; i++ {
+			e := security.Event{
+				Type:     security.EventSandbox,
+				Severity: sandboxSeverities[rng.Intn(len(sandboxSeverities))],
+				Actor:    username,
+				Target:   fmt.Sprintf("sandbox-%d", rng.Intn(1000)),
+				Details:  map[string]any{"seed": req.Seed, "sequence": i, "simulated": true},
+			}
+			security.Publish(e)
+			sim.Events = append(sim.Events, e)
+		}
+	default:
+		http.Error(w, "kind must be stock_changes, rate_limit or webhook_events", http.StatusBadRequest)
+		return
+	}
+
+	recordAudit(r, "simulate", "sandbox", req.Seed, sim)
+	if err := writeJSON(w, http.StatusOK, sim); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// simulateStockChanges adjusts productID, or products picked by rng when it
+// is zero, count times by up to ten units either way, never below zero.
+func simulateStockChanges(rng *rand.Rand, productID, count int) ([]SimulatedStockChange, error) {
+	var products []models.Product
+	if productID != 0 {
+		p, err := productRepo.GetByID(productID)
+		if err != nil {
+			return nil, err
+		}
+		products = []models.Product{p}
+	} else {
+		all, err := productRepo.GetAll()
+		if err != nil {
+			return nil, err
+		}
+		if len(all) == 0 {
+			return nil, repo.ErrProductNotFound
+		}
+		// The listing order is not guaranteed; the seed must pick the same products.
+		sort.Slice(all, func(i, j int) bool { return all[i].ID < all[j].ID })
+		products = all
+	}
+
+	quantities := map[int]int{}
+	for _, p := range products {
+		quantities[p.ID] = p.Quantity
+	}
+	changes := make([]SimulatedStockChange, 0, count)
+	for range count {
+		id := products[rng.Intn(len(products))].ID
+		delta := rng.Intn(10) + 1
+		if rng.Intn(2) == 0 && quantities[id] >= delta {
+			delta = -delta
+		}
+		movement := models.Movement{ProductID: id, Delta: delta, CreatedAt: time.Now().UTC().Format(time.RFC3339)}
+		p, err := productRepo.AdjustWithMovements(id, []models.Movement{movement})
+		if err != nil {
+			return changes, err
+		}
+		quantities[id] = p.Quantity
+		changes = append(changes, SimulatedStockChange{ProductID: id, Delta: delta, Quantity: p.Quantity})
+	}
+	return changes, nil
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+)
+
+// ScanHandler godoc
+// @Summary Adjust stock by barcode in one call
+// @Description Meant for handheld scanners: resolves the product by barcode and applies the adjustment, answering with a minimal body that includes the product's bins in the scanned warehouse. The warehouse is recorded in the audit log.
+// @Tags inventory
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param scan body ScanRequest true "Barcode and quantity change"
+// @Success 200 {object} ScanResponse
+// @Failure 400 {string} string "Invalid input"
+// @Failure 403 {string} string "Caller restricted to warehouses"
+// @Failure 404 {string} string "Unknown barcode"
+// @Failure 409 {string} string "Ambiguous barcode, insufficient stock or period closed"
+// @Failure 422 {string} string "Adjustment larger than the product's maximum"
+// @Failure 500 {string} string "Internal error"
+// @Router /scan [post]
+func ScanHandler(w http.ResponseWriter, r *http.Request) {
+	var req ScanRequest
+	if err := readJSON(w, r, &req); err != nil {
+		http.Error(w, "invalid input", http.StatusBadRequest)
+		return
+	}
+	req.Barcode = strings.TrimSpace(req.Barcode)
+	if req.Barcode == "" || req.Delta == 0 {
+		http.Error(w, "barcode and a non-zero delta are required", http.StatusBadRequest)
+		return
+	}
+
+	product, err := productRepo.GetByBarcode(req.Barcode)
+	if err != nil {
+		switch {
+		case errors.Is(err, repo.ErrProductNotFound):
+			http.Error(w, "unknown barcode", http.StatusNotFound)
+		case errors.Is(err, repo.ErrAmbiguousBarcode):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			http.Error(w, "could not look up barcode", http.StatusInternalServerError)
+		}
+		return
+	}
+	if !adjustmentInScope(w, r, product.ID, nil) {
+		return
+	}
+
+	warnings, err := checkProductAdjustment(r, product.ID, req.Delta)
+	if err != nil {
+		writeAdjustmentLimitError(w, err)
+		return
+	}
+
+	now := time.Now().UTC()
+	if err := ensurePeriodOpen(now); err != nil {
+		if errors.Is(err, errPeriodClosed) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		http.Error(w, "could not verify accounting period", http.StatusInternalServerError)
+		return
+	}
+
+	product, err = productRepo.AdjustQuantity(product.ID, req.Delta)
+	if err != nil {
+		if errors.Is(err, repo.ErrInvalidQuantityChange) {
+			http.Error(w, "quantity cannot be negative", http.StatusConflict)
+			return
+		}
+		http.Error(w, "could not update quantity", http.StatusInternalServerError)
+		return
+	}
+	_, err = movementRepo.Log(models.Movement{ProductID: product.ID, Delta: req.Delta, CreatedAt: now.Format(time.RFC3339)})
+	recordMovementLog(product.ID, req.Delta, err)
+
+	details := map[string]any{"delta": req.Delta, "warehouse": req.Warehouse}
+	if len(warnings) > 0 {
+		details["warnings"] = warnings
+	}
+	recordAudit(r, "scan", "product", product.ID, details)
+	if len(warnings) > 0 {
+		flagProduct(r, product.ID, models.FlagSourceAnomaly, strings.Join(warnings, "; "))
+	}
+
+	resp := ScanResponse{
+		ID:       product.ID,
+		Name:     product.Name,
+		Quantity: product.Quantity,
+		LowStock: product.Quantity < product.Threshold,
+		Bins:     scanBins(product.ID, req.Warehouse),
+		Warnings: warnings,
+	}
+	if err := writeJSON(w, http.StatusOK, resp); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+)
+
+const (
+	maxSearchQueryLength = 200
+	// highlightContext is how many words around the first match a
+	// description fragment keeps on each side.
+	highlightContext = 8
+)
+
+var searchWord = regexp.MustCompile(`[\p{L}\p{N}]+`)
+
+// searchHighlights returns, for each of p's name, SKU and description that
+// matches a term, the field HTML-escaped with the matching words wrapped in
+// <mark>. Descriptions are cut to the words around the first match. Matches
+// found only through typo tolerance have nothing to highlight.
+func searchHighlights(p models.Product, terms []string) map[string]string {
+	highlights := map[string]string{}
+	for _, f := range []struct {
+		name, text string
+		fragment   bool
+	}{{"name", p.Name, false}, {"sku", p.SKU, false}, {"description", p.Description, true}} {
+		if h, ok := highlight(f.text, terms, f.fragment); ok {
+			highlights[f.name] = h
+		}
+	}
+	if len(highlights) == 0 {
+		return nil
+	}
+	return highlights
+}
+
+// highlight marks the words of text matching terms. With fragment, only the
+// words around the first match are kept, with an ellipsis where text was cut.
+func highlight(text string, terms []string, fragment bool) (string, bool) {
+	words := searchWord.FindAllStringIndex(text, -1)
+	first := -1
+	marked := make([]bool, len(words))
+	for i, w := range words {
+		for _, term := range terms {
+			if repo.MatchesTerm(text[w[0]:w[1]], term) {
+				marked[i] = true
+				break
+			}
+		}
+		if marked[i] && first < 0 {
+			first = i
+		}
+	}
+	if first < 0 {
+		return "", false
+	}
+
+	start, end := 0, len(text)
+	if fragment {
+		if from := first - highlightContext; from > 0 {
+			start = words[from][0]
+		}
+		if to := first + highlightContext; to < len(words)-1 {
+			end = words[to][1]
+		}
+	}
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("… ")
+	}
+	pos := start
+	for i, w := range words {
+		if w[0] < start || w[1] > end || !marked[i] {
+			continue
+		}
+		b.WriteString(html.EscapeString(text[pos:w[0]]))
+		b.WriteString("<mark>" + html.EscapeString(text[w[0]:w[1]]) + "</mark>")
+		pos = w[1]
+	}
+	b.WriteString(html.EscapeString(text[pos:end]))
+	if end < len(text) {
+		b.WriteString(" …")
+	}
+	return b.String(), true
+}
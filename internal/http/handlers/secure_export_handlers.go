@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"mime"
+	"net/http"
+	"net/mail"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rogerio-castellano/inventory-tracker/internal/mailer"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+	"github.com/rogerio-castellano/inventory-tracker/internal/secureexport"
+)
+
+// ExportPasswordHeader carries the password of a ZIP-encrypted export, kept
+// out of the URL so it does not end up in access logs.
+const ExportPasswordHeader = "X-Export-Password"
+
+const maxExportRecipientNameLength = 100
+
+// deliverExport sends an export file the way the request's encryption
+// parameter asks: in the clear, as a password-protected ZIP archive or
+// encrypted to a registered recipient's PGP key and emailed to them.
+func deliverExport(w http.ResponseWriter, r *http.Request, filename, contentType string, content []byte) {
+	q := r.URL.Query()
+	switch q.Get("encryption") {
+	case "":
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+		if _, err := w.Write(content); err != nil {
+			log.Printf("failed to send export %s: %v", filename, err)
+		}
+
+	case "zip":
+		password := r.Header.Get(ExportPasswordHeader)
+		if len(password) < secureexport.MinPasswordLength {
+			http.Error(w, fmt.Sprintf("%s must hold a password of at least %d characters", ExportPasswordHeader, secureexport.MinPasswordLength), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename + ".zip"}))
+		if err := secureexport.WriteZip(w, filename, content, password); err != nil {
+			log.Printf("failed to send encrypted export %s: %v", filename, err)
+		}
+
+	case "pgp":
+		id, err := strconv.Atoi(q.Get("recipient_id"))
+		if err != nil || id <= 0 {
+			http.Error(w, "recipient_id is required for pgp encryption", http.StatusBadRequest)
+			return
+		}
+		rcpt, err := exportRecipientRepo.GetByID(id)
+		if err != nil {
+			writeExportRecipientError(w, err)
+			return
+		}
+		encrypted, err := secureexport.EncryptPGP(rcpt.PublicKey, filename, content)
+		if err != nil {
+			log.Printf("failed to encrypt export %s for recipient %d: %v", filename, rcpt.ID, err)
+			http.Error(w, "could not encrypt export", http.StatusInternalServerError)
+			return
+		}
+
+		requestedBy, _ := GetUsernameFromContext(r)
+		go func() {
+			body := fmt.Sprintf("<p>%s, <strong>%s</strong> sent you %s, encrypted to your PGP key %s.</p>",
+				html.EscapeString(rcpt.Name), html.EscapeString(requestedBy), html.EscapeString(filename), rcpt.Fingerprint)
+			attachment := mailer.Attachment{Filename: filename + ".asc", ContentType: "application/pgp-encrypted", Content: encrypted}
+			if err := mailer.SendHTMLWithAttachments(rcpt.Email, "Encrypted export: "+filename, body, attachment); err != nil {
+				log.Printf("failed to email export %s to recipient %d: %v", filename, rcpt.ID, err)
+			}
+		}()
+
+		recordAudit(r, "send_export", "export_recipient", rcpt.ID, map[string]string{"filename": filename})
+		delivery := ExportDelivery{Filename: filename + ".asc", RecipientID: rcpt.ID, Email: rcpt.Email, Fingerprint: rcpt.Fingerprint}
+		if err := writeJSON(w, http.StatusAccepted, delivery); err != nil {
+			log.Printf("Failed to write JSON response: %v", err)
+		}
+
+	default:
+		http.Error(w, "encryption must be 'zip' or 'pgp'", http.StatusBadRequest)
+	}
+}
+
+// CreateExportRecipientHandler godoc
+// @Summary Register a recipient of encrypted exports
+// @Description Stores a recipient's PGP public key so valuation and audit exports can be emailed to them encrypted, with encryption=pgp and their ID as recipient_id. The key must be able to encrypt.
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param recipient body ExportRecipientRequest true "Name, email and armored public key"
+// @Success 201 {object} models.ExportRecipient
+// @Failure 400 {string} string "Invalid input"
+// @Failure 409 {string} string "Key already registered"
+// @Failure 500 {string} string "Internal error"
+// @Router /admin/export-recipients [post]
+func CreateExportRecipientHandler(w http.ResponseWriter, r *http.Request) {
+	var req ExportRecipientRequest
+	if err := readJSON(w, r, &req); err != nil {
+		http.Error(w, "invalid input", http.StatusBadRequest)
+		return
+	}
+	rcpt := models.ExportRecipient{Name: strings.TrimSpace(req.Name), Email: strings.TrimSpace(req.Email), PublicKey: strings.TrimSpace(req.PublicKey)}
+	switch {
+	case rcpt.Name == "":
+		http.Error(w, "name is required", http.StatusBadRequest)
+		return
+	case len(rcpt.Name) > maxExportRecipientNameLength:
+		http.Error(w, fmt.Sprintf("name must be at most %d characters", maxExportRecipientNameLength), http.StatusBadRequest)
+		return
+	}
+	if addr, err := mail.ParseAddress(rcpt.Email); err != nil || addr.Address != rcpt.Email {
+		http.Error(w, "a valid email is required", http.StatusBadRequest)
+		return
+	}
+	fingerprint, err := secureexport.ParsePublicKey(rcpt.PublicKey)
+	if err != nil {
+		http.Error(w, "public_key: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	rcpt.Fingerprint = fingerprint
+	rcpt.CreatedBy, _ = GetUsernameFromContext(r)
+
+	created, err := exportRecipientRepo.Create(rcpt)
+	if err != nil {
+		writeExportRecipientError(w, err)
+		return
+	}
+
+	recordAudit(r, "create", "export_recipient", created.ID, map[string]string{"name": created.Name, "email": created.Email, "fingerprint": created.Fingerprint})
+	if err := writeJSON(w, http.StatusCreated, created); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// ListExportRecipientsHandler godoc
+// @Summary List the recipients of encrypted exports
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.ExportRecipient
+// @Failure 500 {string} string "Internal error"
+// @Router /admin/export-recipients [get]
+func ListExportRecipientsHandler(w http.ResponseWriter, r *http.Request) {
+	recipients, err := exportRecipientRepo.List()
+	if err != nil {
+		http.Error(w, "could not fetch export recipients", http.StatusInternalServerError)
+		return
+	}
+	if err := writeJSON(w, http.StatusOK, recipients); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// DeleteExportRecipientHandler godoc
+// @Summary Remove a recipient of encrypted exports
+// @Tags admin
+// @Security BearerAuth
+// @Param id path int true "Recipient ID"
+// @Success 204 "Removed"
+// @Failure 400 {string} string "Invalid ID"
+// @Failure 404 {string} string "Recipient not found"
+// @Failure 500 {string} string "Internal error"
+// @Router /admin/export-recipients/{id} [delete]
+func DeleteExportRecipientHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid recipient ID", http.StatusBadRequest)
+		return
+	}
+	rcpt, err := exportRecipientRepo.GetByID(id)
+	if err != nil {
+		writeExportRecipientError(w, err)
+		return
+	}
+	if err := exportRecipientRepo.Delete(id); err != nil {
+		writeExportRecipientError(w, err)
+		return
+	}
+
+	recordAudit(r, "delete", "export_recipient", id, map[string]string{"name": rcpt.Name, "fingerprint": rcpt.Fingerprint})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeExportRecipientError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, repo.ErrExportRecipientNotFound):
+		http.Error(w, "export recipient not found", http.StatusNotFound)
+	case errors.Is(err, repo.ErrDuplicatedValueUnique):
+		http.Error(w, "a recipient with this key is already registered", http.StatusConflict)
+	default:
+		log.Printf("export recipients: %v", err)
+		http.Error(w, "could not process export recipient", http.StatusInternalServerError)
+	}
+}
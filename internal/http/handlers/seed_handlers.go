@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/seed"
+)
+
+// SeedHandler godoc
+// @Summary Generate demo data
+// @Description Creates fake products, users and months of movement history. Only enabled when APP_ENV is development. The generated users share a random password, returned once in the result.
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param options body seed.Options false "Volume of data to generate"
+// @Success 201 {object} seed.Result
+// @Failure 400 {string} string "Invalid input"
+// @Failure 404 {string} string "Not found"
+// @Failure 500 {string} string "Internal error"
+// @Router /admin/seed [post]
+func SeedHandler(w http.ResponseWriter, r *http.Request) {
+	if !seedingEnabled {
+		http.NotFound(w, r)
+		return
+	}
+
+	var opts seed.Options
+	if err := readJSON(w, r, &opts); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "invalid input", http.StatusBadRequest)
+		return
+	}
+	if err := opts.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	gen := seed.Generator{Products: productRepo, Movements: movementRepo, Users: userRepo}
+	res, err := gen.Run(opts)
+	if err != nil {
+		log.Printf("seeding stopped after %d products, %d movements: %v", res.Products, res.Movements, err)
+		http.Error(w, "could not generate data", http.StatusInternalServerError)
+		return
+	}
+
+	audited := res
+	audited.Password = ""
+	recordAudit(r, "seed", "dataset", opts.RandomSeed, audited)
+
+	if err := writeJSON(w, http.StatusCreated, res); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
@@ -2,19 +2,76 @@ package handlers
 
 import (
 	"context"
+	"strings"
+	"time"
 
 	"github.com/redis/go-redis/v9"
+	"github.com/rogerio-castellano/inventory-tracker/internal/avscan"
+	"github.com/rogerio-castellano/inventory-tracker/internal/fxrate"
 	"github.com/rogerio-castellano/inventory-tracker/internal/redissvc"
 	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+	"github.com/rogerio-castellano/inventory-tracker/internal/storage"
 )
 
 var (
-	productRepo  repo.ProductRepository
-	movementRepo repo.MovementRepository
-	metricsRepo  repo.MetricsRepository
-	userRepo     repo.UserRepository
+	productRepo   repo.ProductRepository
+	movementRepo  repo.MovementRepository
+	metricsRepo   repo.MetricsRepository
+	userRepo      repo.UserRepository
+	periodRepo    repo.PeriodRepository
+	usageRepo     repo.UsageRepository
+	auditRepo     repo.AuditRepository
+	documentRepo  repo.DocumentRepository
+	lotRepo       repo.LotRepository
+	writeOffRepo  repo.WriteOffRepository
+	returnRepo    repo.ReturnRepository
+	workOrderRepo repo.WorkOrderRepository
+	syncRepo      repo.SyncRepository
+	mappingRepo   repo.MappingRepository
+	snapshotRepo  repo.SnapshotRepository
+	taxClassRepo  repo.TaxClassRepository
 
-	Rdb *redis.Client
+	validationPolicyRepo repo.ValidationPolicyRepository
+	runtimeConfigRepo    repo.RuntimeConfigRepository
+	substituteRepo       repo.SubstituteRepository
+	priceListRepo        repo.PriceListRepository
+	promotionRepo        repo.PromotionRepository
+	binRepo              repo.BinRepository
+	capacityRepo         repo.CapacityRepository
+	asnRepo              repo.ASNRepository
+	landedCostRepo       repo.LandedCostRepository
+	consignmentRepo      repo.ConsignmentRepository
+	attachmentRepo       repo.MovementAttachmentRepository
+	incidentRepo         repo.IncidentRepository
+	eventLogRepo         repo.EventLogRepository
+	productChangeRepo    repo.ProductChangeRepository
+	warehouseRepo        repo.WarehouseRepository
+	reservationRepo      repo.ReservationRepository
+	supplierRepo         repo.SupplierRepository
+	webhookRepo          repo.WebhookRepository
+	loginEventRepo       repo.LoginEventRepository
+	accessGrantRepo      repo.AccessGrantRepository
+	approvalRepo         repo.ApprovalRepository
+	exportRecipientRepo  repo.ExportRecipientRepository
+	adjustmentLimitRepo  repo.AdjustmentLimitRepository
+	priceRulesRepo       repo.PriceRulesRepository
+	productFlagRepo      repo.ProductFlagRepository
+
+	documentStore storage.Store
+	fileScanner   avscan.Scanner  = avscan.Nop{}
+	exchangeRates fxrate.Provider = fxrate.NewStatic("USD", nil)
+
+	seedingEnabled          bool
+	sandboxEnabled          bool
+	queryDiagnosticsEnabled bool
+	productQuota            int
+	deleteApprovalRequired  bool
+	passwordMaxAge          time.Duration
+	tenant                  = "default"
+	slackSigningSecret      string
+	slackDashboardURL       string
+
+	Rdb redis.UniversalClient
 	Ctx context.Context
 )
 
@@ -34,6 +91,217 @@ func SetUserRepo(r repo.UserRepository) {
 	userRepo = r
 }
 
+func SetPeriodRepo(r repo.PeriodRepository) {
+	periodRepo = r
+}
+
+func SetUsageRepo(r repo.UsageRepository) {
+	usageRepo = r
+}
+
+func SetAuditRepo(r repo.AuditRepository) {
+	auditRepo = r
+}
+
+// SetSeedingEnabled turns the demo data generator on; it is only on when
+// APP_ENV is development, so a missing or mistyped APP_ENV keeps it off.
+func SetSeedingEnabled(enabled bool) {
+	seedingEnabled = enabled
+}
+
+// SetSandboxEnabled turns on the simulation endpoint for integration partners.
+// It changes stock for real, so it must only be on for sandbox data.
+func SetSandboxEnabled(enabled bool) {
+	sandboxEnabled = enabled
+}
+
+// SetQueryDiagnosticsEnabled exposes query plans to admins. EXPLAIN ANALYZE
+// executes the query, so this is meant to be switched on only while investigating.
+func SetQueryDiagnosticsEnabled(enabled bool) {
+	queryDiagnosticsEnabled = enabled
+}
+
+// SetProductQuota caps how many products may exist; 0 means no cap.
+func SetProductQuota(n int) {
+	productQuota = n
+}
+
+// SetDeleteApprovalRequired turns on the two-person rule for deleting
+// products that still have stock.
+func SetDeleteApprovalRequired(required bool) {
+	deleteApprovalRequired = required
+}
+
+// SetPasswordMaxAge makes passwords older than maxAge expire; 0 means they
+// never do.
+func SetPasswordMaxAge(maxAge time.Duration) {
+	passwordMaxAge = maxAge
+}
+
+// SetTenant names the organisation this deployment serves.
+func SetTenant(name string) {
+	tenant = name
+}
+
+// SetSlackCommands enables the Slack slash command endpoint for requests
+// signed with signingSecret. Replies link to dashboardURL when it is set.
+func SetSlackCommands(signingSecret, dashboardURL string) {
+	slackSigningSecret = signingSecret
+	slackDashboardURL = strings.TrimRight(dashboardURL, "/")
+}
+
+func SetDocumentRepo(r repo.DocumentRepository) {
+	documentRepo = r
+}
+
+func SetDocumentStore(s storage.Store) {
+	documentStore = s
+}
+
+// SetFileScanner makes uploaded documents, attachments and import files go
+// through s before anything else is done with them.
+func SetFileScanner(s avscan.Scanner) {
+	fileScanner = s
+}
+
+// SetExchangeRates sets the rates money figures in several currencies are
+// converted with; products without a currency are in p's base currency.
+func SetExchangeRates(p fxrate.Provider) {
+	exchangeRates = p
+}
+
+func SetLotRepo(r repo.LotRepository) {
+	lotRepo = r
+}
+
+func SetWriteOffRepo(r repo.WriteOffRepository) {
+	writeOffRepo = r
+}
+
+func SetReturnRepo(r repo.ReturnRepository) {
+	returnRepo = r
+}
+
+func SetWorkOrderRepo(r repo.WorkOrderRepository) {
+	workOrderRepo = r
+}
+
+func SetSyncRepo(r repo.SyncRepository) {
+	syncRepo = r
+}
+
+func SetMappingRepo(r repo.MappingRepository) {
+	mappingRepo = r
+}
+
+func SetSnapshotRepo(r repo.SnapshotRepository) {
+	snapshotRepo = r
+}
+
+func SetTaxClassRepo(r repo.TaxClassRepository) {
+	taxClassRepo = r
+}
+
+func SetValidationPolicyRepo(r repo.ValidationPolicyRepository) {
+	validationPolicyRepo = r
+}
+
+func SetPriceRulesRepo(r repo.PriceRulesRepository) {
+	priceRulesRepo = r
+}
+
+func SetProductFlagRepo(r repo.ProductFlagRepository) {
+	productFlagRepo = r
+}
+
+func SetRuntimeConfigRepo(r repo.RuntimeConfigRepository) {
+	runtimeConfigRepo = r
+}
+
+func SetSubstituteRepo(r repo.SubstituteRepository) {
+	substituteRepo = r
+}
+
+func SetPriceListRepo(r repo.PriceListRepository) {
+	priceListRepo = r
+}
+
+func SetPromotionRepo(r repo.PromotionRepository) {
+	promotionRepo = r
+}
+
+func SetBinRepo(r repo.BinRepository) {
+	binRepo = r
+}
+
+func SetCapacityRepo(r repo.CapacityRepository) {
+	capacityRepo = r
+}
+
+func SetAdjustmentLimitRepo(r repo.AdjustmentLimitRepository) {
+	adjustmentLimitRepo = r
+}
+
+func SetASNRepo(r repo.ASNRepository) {
+	asnRepo = r
+}
+
+func SetLandedCostRepo(r repo.LandedCostRepository) {
+	landedCostRepo = r
+}
+
+func SetConsignmentRepo(r repo.ConsignmentRepository) {
+	consignmentRepo = r
+}
+
+func SetMovementAttachmentRepo(r repo.MovementAttachmentRepository) {
+	attachmentRepo = r
+}
+
+func SetIncidentRepo(r repo.IncidentRepository) {
+	incidentRepo = r
+}
+
+func SetEventLogRepo(r repo.EventLogRepository) {
+	eventLogRepo = r
+}
+
+func SetProductChangeRepo(r repo.ProductChangeRepository) {
+	productChangeRepo = r
+}
+
+func SetWarehouseRepo(r repo.WarehouseRepository) {
+	warehouseRepo = r
+}
+
+func SetReservationRepo(r repo.ReservationRepository) {
+	reservationRepo = r
+}
+
+func SetSupplierRepo(r repo.SupplierRepository) {
+	supplierRepo = r
+}
+
+func SetWebhookRepo(r repo.WebhookRepository) {
+	webhookRepo = r
+}
+
+func SetLoginEventRepo(r repo.LoginEventRepository) {
+	loginEventRepo = r
+}
+
+func SetAccessGrantRepo(r repo.AccessGrantRepository) {
+	accessGrantRepo = r
+}
+
+func SetApprovalRepo(r repo.ApprovalRepository) {
+	approvalRepo = r
+}
+
+func SetExportRecipientRepo(r repo.ExportRecipientRepository) {
+	exportRecipientRepo = r
+}
+
 func SetRedisService(rs *redissvc.RedisService) {
 	Rdb = rs.Rdb()
 	Ctx = rs.Ctx()
@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rogerio-castellano/inventory-tracker/internal/auth"
+)
+
+// IssueServiceTokenHandler godoc
+// @Summary Issue a long-lived token limited to some endpoints
+// @Description For kiosks and devices that cannot use the refresh flow. The token carries the service role and only works on endpoints covered by its scopes.
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body ServiceTokenRequest true "Token name, scopes and optional lifetime"
+// @Success 201 {object} ServiceTokenResponse
+// @Failure 400 {string} string "Invalid input"
+// @Failure 500 {string} string "Internal error"
+// @Router /admin/service-tokens [post]
+func IssueServiceTokenHandler(w http.ResponseWriter, r *http.Request) {
+	var req ServiceTokenRequest
+	if err := readJSON(w, r, &req); err != nil {
+		http.Error(w, "invalid input", http.StatusBadRequest)
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		http.Error(w, "name is required", http.StatusBadRequest)
+		return
+	}
+	if len(req.Scopes) == 0 {
+		http.Error(w, "at least one scope is required", http.StatusBadRequest)
+		return
+	}
+	for _, s := range req.Scopes {
+		if !auth.ValidScope(s) {
+			http.Error(w, fmt.Sprintf("invalid scope %q: expected a path such as \"POST /scan\" or \"GET /products/*\"", s), http.StatusBadRequest)
+			return
+		}
+	}
+	var ttl time.Duration
+	if req.ExpiresIn != "" {
+		var err error
+		if ttl, err = parseWithin(req.ExpiresIn, 0); err != nil {
+			http.Error(w, "expires_in must be a positive duration such as 365d or 720h", http.StatusBadRequest)
+			return
+		}
+	}
+
+	id := generateRandomToken()
+	if id == "" {
+		http.Error(w, "could not generate token", http.StatusInternalServerError)
+		return
+	}
+	createdBy, _ := GetUsernameFromContext(r)
+	info := ServiceTokenInfo{
+		ID:        id,
+		Name:      req.Name,
+		Scopes:    req.Scopes,
+		CreatedBy: createdBy,
+		CreatedAt: time.Now().UTC(),
+	}
+	if ttl > 0 {
+		expires := info.CreatedAt.Add(ttl)
+		info.ExpiresAt = &expires
+	}
+
+	token, err := auth.GenerateServiceToken(info.ID, info.Name, info.Scopes, ttl)
+	if err != nil {
+		http.Error(w, "could not generate token", http.StatusInternalServerError)
+		return
+	}
+	raw, err := json.Marshal(info)
+	if err != nil {
+		http.Error(w, "could not store token", http.StatusInternalServerError)
+		return
+	}
+	if err := Rdb.HSet(Ctx, auth.ServiceTokensKey, info.ID, raw).Err(); err != nil {
+		http.Error(w, "could not store token", http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(r, "issue", "service_token", info.ID, info)
+	if err := writeJSON(w, http.StatusCreated, ServiceTokenResponse{ServiceTokenInfo: info, Token: token}); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// ListServiceTokensHandler godoc
+// @Summary List service tokens that have not been revoked
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} ServiceTokenInfo
+// @Failure 500 {string} string "Internal error"
+// @Router /admin/service-tokens [get]
+func ListServiceTokensHandler(w http.ResponseWriter, r *http.Request) {
+	entries, err := Rdb.HGetAll(Ctx, auth.ServiceTokensKey).Result()
+	if err != nil {
+		http.Error(w, "could not read service tokens", http.StatusInternalServerError)
+		return
+	}
+
+	tokens := make([]ServiceTokenInfo, 0, len(entries))
+	for id, raw := range entries {
+		var info ServiceTokenInfo
+		if err := json.Unmarshal([]byte(raw), &info); err != nil {
+			log.Printf("Skipping unreadable service token %s: %v", id, err)
+			continue
+		}
+		tokens = append(tokens, info)
+	}
+	sort.Slice(tokens, func(i, j int) bool { return tokens[i].CreatedAt.Before(tokens[j].CreatedAt) })
+
+	if err := writeJSON(w, http.StatusOK, tokens); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// RevokeServiceTokenHandler godoc
+// @Summary Revoke a service token
+// @Tags admin
+// @Security BearerAuth
+// @Param id path string true "Service token ID"
+// @Success 204 "Token revoked"
+// @Failure 404 {string} string "Token not found"
+// @Failure 500 {string} string "Internal error"
+// @Router /admin/service-tokens/{id} [delete]
+func RevokeServiceTokenHandler(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	n, err := Rdb.HDel(Ctx, auth.ServiceTokensKey, id).Result()
+	if err != nil {
+		http.Error(w, "could not revoke token", http.StatusInternalServerError)
+		return
+	}
+	if n == 0 {
+		http.Error(w, "token not found", http.StatusNotFound)
+		return
+	}
+
+	recordAudit(r, "revoke", "service_token", id, nil)
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rogerio-castellano/inventory-tracker/internal/auth"
+	"github.com/rogerio-castellano/inventory-tracker/internal/usage"
+)
+
+const defaultActiveWindow = 15 * time.Minute
+
+// ListActiveSessionsHandler godoc
+// @Summary Users active right now
+// @Description Lists users who made a request within the window, with where they connected from, what they last did and their open refresh-token sessions.
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param within query string false "Activity window, e.g. 15m or 1h (default 15m)"
+// @Success 200 {array} ActiveSession
+// @Failure 400 {string} string "Invalid input"
+// @Failure 500 {string} string "Internal error"
+// @Router /admin/sessions/active [get]
+func ListActiveSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	within, err := parseWithin(r.URL.Query().Get("within"), defaultActiveWindow)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	activities, err := usage.ActiveSince(time.Now().Add(-within))
+	if err != nil {
+		http.Error(w, "could not read recent activity", http.StatusInternalServerError)
+		return
+	}
+	tokens, err := auth.GetRefreshTokens()
+	if err != nil {
+		http.Error(w, "could not read sessions", http.StatusInternalServerError)
+		return
+	}
+
+	today := usage.Today()
+	active := make([]ActiveSession, 0, len(activities))
+	for _, a := range activities {
+		s := ActiveSession{
+			Username:    a.Username,
+			LastSeenAt:  a.At,
+			LastRequest: a.Request,
+			IPAddress:   a.IPAddress,
+			UserAgent:   a.UserAgent,
+			Sessions:    []RefreshTokenInfo{},
+		}
+		if counters, err := usage.Live(a.Username, today); err == nil {
+			s.RequestsToday = counters.Requests
+		}
+		for key, entry := range tokens[a.Username] {
+			s.Sessions = append(s.Sessions, sessionInfo(a.Username, key, entry))
+		}
+		sort.Slice(s.Sessions, func(i, j int) bool { return s.Sessions[i].IssuedAt.After(s.Sessions[j].IssuedAt) })
+		active = append(active, s)
+	}
+
+	if err := writeJSON(w, http.StatusOK, active); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// sessionInfo describes the refresh-token session key of username.
+func sessionInfo(username, key string, entry auth.RefreshTokenEntry) RefreshTokenInfo {
+	return RefreshTokenInfo{
+		SessionKey: key,
+		Username:   username,
+		IssuedAt:   entry.CreatedAt,
+		ExpiresAt:  entry.CreatedAt.Add(auth.RefreshTokenMaxAge),
+		IPAddress:  entry.IPAddress,
+		UserAgent:  entry.UserAgent,
+		DeviceName: entry.DeviceName,
+	}
+}
+
+// MeSessionsHandler godoc
+// @Summary Open sessions of the current user
+// @Description Lists the caller's refresh-token sessions, newest first, with the device name each was given at login. The session making the request is flagged as current.
+// @Tags auth
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} RefreshTokenInfo
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 500 {string} string "Internal error"
+// @Router /me/sessions [get]
+func MeSessionsHandler(w http.ResponseWriter, r *http.Request) {
+	username, err := GetUsernameFromContext(r)
+	if err != nil || username == "" {
+		http.Error(w, "invalid token", http.StatusUnauthorized)
+		return
+	}
+	userSessions, _, err := auth.GetRefreshToken(username)
+	if err != nil {
+		http.Error(w, "could not read sessions", http.StatusInternalServerError)
+		return
+	}
+
+	current := auth.SessionKey(clientIP(r), r.UserAgent())
+	sessions := []RefreshTokenInfo{}
+	for key, entry := range userSessions {
+		s := sessionInfo(username, key, entry)
+		s.Current = key == current
+		sessions = append(sessions, s)
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].IssuedAt.After(sessions[j].IssuedAt) })
+	if err := writeJSON(w, http.StatusOK, sessions); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// RevokeMySessionHandler godoc
+// @Summary Log out one of the current user's devices
+// @Description Revokes the refresh token of the session, so the device must log in again once its access token expires.
+// @Tags auth
+// @Security BearerAuth
+// @Param sessionKey path string true "Session key from GET /me/sessions"
+// @Success 204 "Session revoked"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 404 {string} string "Session not found"
+// @Failure 500 {string} string "Internal error"
+// @Router /me/sessions/{sessionKey} [delete]
+func RevokeMySessionHandler(w http.ResponseWriter, r *http.Request) {
+	username, err := GetUsernameFromContext(r)
+	if err != nil || username == "" {
+		http.Error(w, "invalid token", http.StatusUnauthorized)
+		return
+	}
+	sessionKey := chi.URLParam(r, "sessionKey")
+
+	userSessions, _, err := auth.GetRefreshToken(username)
+	if err != nil {
+		http.Error(w, "could not read sessions", http.StatusInternalServerError)
+		return
+	}
+	entry, ok := userSessions[sessionKey]
+	if !ok {
+		http.Error(w, "session not found", http.StatusNotFound)
+		return
+	}
+	if err := auth.RemoveRefreshToken(username, sessionKey); err != nil {
+		http.Error(w, "Failed to handle refresh token", http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(r, "revoke_session", "user", username, map[string]string{"device_name": entry.DeviceName})
+	w.WriteHeader(http.StatusNoContent)
+}
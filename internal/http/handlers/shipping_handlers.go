@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"slices"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+)
+
+const (
+	maxShippingItems         = 500
+	defaultVolumetricDivisor = 5000 // cm³ per kg, the usual courier rate
+)
+
+// ShippingEstimateHandler godoc
+// @Summary Estimate the weight and volume of a shipment
+// @Description Sums the products' unit weight (kg) and volume (m³, from their dimensions in cm) over the given quantities, and derives the volumetric and chargeable weight carriers bill by. Products missing a weight or dimensions are listed so the estimate can be read as a lower bound.
+// @Tags inventory
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param shipment body ShippingEstimateRequest true "Products and quantities"
+// @Success 200 {object} ShippingEstimate
+// @Failure 400 {string} string "Invalid input"
+// @Failure 404 {string} string "Product not found"
+// @Failure 500 {string} string "Internal error"
+// @Router /shipping/estimate [post]
+func ShippingEstimateHandler(w http.ResponseWriter, r *http.Request) {
+	var req ShippingEstimateRequest
+	if err := readJSON(w, r, &req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	switch {
+	case len(req.Items) == 0:
+		http.Error(w, "items are required", http.StatusBadRequest)
+		return
+	case len(req.Items) > maxShippingItems:
+		http.Error(w, fmt.Sprintf("at most %d items per estimate", maxShippingItems), http.StatusBadRequest)
+		return
+	case req.VolumetricDivisor < 0:
+		http.Error(w, "volumetric_divisor cannot be negative", http.StatusBadRequest)
+		return
+	}
+	divisor := req.VolumetricDivisor
+	if divisor == 0 {
+		divisor = defaultVolumetricDivisor
+	}
+
+	estimate := ShippingEstimate{Items: make([]ShippingEstimateLine, 0, len(req.Items))}
+	for _, item := range req.Items {
+		if item.Quantity <= 0 {
+			http.Error(w, "quantities must be positive", http.StatusBadRequest)
+			return
+		}
+		p, err := productRepo.GetByID(item.ProductID)
+		if err != nil {
+			if errors.Is(err, repo.ErrProductNotFound) {
+				http.Error(w, fmt.Sprintf("product %d not found", item.ProductID), http.StatusNotFound)
+				return
+			}
+			http.Error(w, "could not fetch product", http.StatusInternalServerError)
+			return
+		}
+		if p.Weight <= 0 && !slices.Contains(estimate.Unweighed, p.ID) {
+			estimate.Unweighed = append(estimate.Unweighed, p.ID)
+		}
+		if p.Dimensions.Volume() == 0 && !slices.Contains(estimate.Unmeasured, p.ID) {
+			estimate.Unmeasured = append(estimate.Unmeasured, p.ID)
+		}
+		line := ShippingEstimateLine{
+			ProductID: p.ID,
+			Name:      p.Name,
+			Quantity:  item.Quantity,
+			Weight:    max(p.Weight, 0) * float64(item.Quantity),
+			Volume:    p.Dimensions.Volume() * float64(item.Quantity),
+		}
+		estimate.Weight += line.Weight
+		estimate.Volume += line.Volume
+		line.Weight, line.Volume = roundMeasure(line.Weight), roundMeasure(line.Volume)
+		estimate.Items = append(estimate.Items, line)
+	}
+	estimate.VolumetricWeight = roundMeasure(estimate.Volume * 1e6 / divisor)
+	estimate.Weight, estimate.Volume = roundMeasure(estimate.Weight), roundMeasure(estimate.Volume)
+	estimate.ChargeableWeight = max(estimate.Weight, estimate.VolumetricWeight)
+
+	if err := writeJSON(w, http.StatusOK, estimate); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// roundMeasure rounds kilograms and cubic metres to three decimals: grams
+// and litres.
+func roundMeasure(v float64) float64 {
+	return math.Round(v*1000) / 1000
+}
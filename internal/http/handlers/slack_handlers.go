@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+)
+
+const (
+	// slackMaxSkew is how old a signed request may be before it is treated
+	// as a replay, as Slack recommends.
+	slackMaxSkew    = 5 * time.Minute
+	slackMaxBody    = 64 << 10
+	slackMaxMatches = 5
+	slackEphemeral  = "ephemeral"
+)
+
+// SlackCommandHandler godoc
+// @Summary Slack slash command for stock queries
+// @Description Answers a Slack slash command such as "/stock mouse" with the matching products' quantity and low-stock status, suggesting an in-stock substitute for products that ran out. Requests must carry a valid Slack signature (X-Slack-Signature and X-Slack-Request-Timestamp).
+// @Tags integrations
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param text formData string false "Product name, or part of it"
+// @Success 200 {object} SlackCommandResponse
+// @Failure 401 {string} string "Invalid signature"
+// @Failure 404 {string} string "Slack integration not configured"
+// @Router /integrations/slack/commands [post]
+func SlackCommandHandler(w http.ResponseWriter, r *http.Request) {
+	if slackSigningSecret == "" {
+		http.Error(w, "slack integration not configured", http.StatusNotFound)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, slackMaxBody))
+	if err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := verifySlackSignature(r.Header, body, time.Now()); err != nil {
+		log.Printf("rejected slack command: %v", err)
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Slack shows any non-200 answer as a bare failure, so problems past this
+	// point are reported in the reply text.
+	text, err := slackStockReply(strings.TrimSpace(form.Get("text")), form.Get("command"))
+	if err != nil {
+		log.Printf("slack command failed: %v", err)
+		text = "Sorry, stock could not be looked up right now."
+	}
+	if err := writeJSON(w, http.StatusOK, SlackCommandResponse{ResponseType: slackEphemeral, Text: text}); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// verifySlackSignature checks Slack's v0 request signature: an HMAC-SHA256
+// of "v0:<timestamp>:<body>" keyed with the app's signing secret.
+func verifySlackSignature(h http.Header, body []byte, now time.Time) error {
+	ts := h.Get("X-Slack-Request-Timestamp")
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return errors.New("missing or invalid timestamp")
+	}
+	if skew := now.Sub(time.Unix(sec, 0)); skew > slackMaxSkew || skew < -slackMaxSkew {
+		return errors.New("stale timestamp")
+	}
+
+	mac := hmac.New(sha256.New, []byte(slackSigningSecret))
+	fmt.Fprintf(mac, "v0:%s:", ts)
+	mac.Write(body)
+	expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(expected), []byte(h.Get("X-Slack-Signature"))) {
+		return errors.New("signature mismatch")
+	}
+	return nil
+}
+
+// slackStockReply finds the product named by query, or those whose name
+// contains it, and describes their stock in Slack's mrkdwn.
+func slackStockReply(query, command string) (string, error) {
+	if query == "" {
+		if command == "" {
+			command = "/stock"
+		}
+		return fmt.Sprintf("Usage: `%s <product name>`", command), nil
+	}
+
+	var matches []models.Product
+	if p, err := productRepo.GetByName(query); err == nil {
+		matches = []models.Product{p}
+	} else if !errors.Is(err, repo.ErrProductNotFound) {
+		return "", err
+	} else {
+		limit := slackMaxMatches + 1
+		if matches, _, err = productRepo.Filter(repo.ProductFilter{Name: query, Limit: &limit, SkipTotal: true}); err != nil {
+			return "", err
+		}
+	}
+
+	if len(matches) == 0 {
+		return fmt.Sprintf("No product matches %q.", query), nil
+	}
+	lines := make([]string, 0, len(matches))
+	for i, p := range matches {
+		if i == slackMaxMatches {
+			lines = append(lines, "_More products match; try a longer name._")
+			break
+		}
+		var substitute *SuggestedSubstitute
+		if p.Quantity <= 0 {
+			var err error
+			if substitute, err = inStockSubstitute(p.ID, 1); err != nil {
+				return "", err
+			}
+		}
+		lines = append(lines, slackStockLine(p, substitute))
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// slackStockLine describes one product; substitute, when not nil, is offered
+// in place of an out-of-stock product.
+func slackStockLine(p models.Product, substitute *SuggestedSubstitute) string {
+	line := fmt.Sprintf("*%s*: %d in stock", slackProductLink(p.ID, p.Name), p.Quantity)
+	if p.Quantity < p.Threshold {
+		line += fmt.Sprintf(" :warning: low stock (threshold %d)", p.Threshold)
+	}
+	if substitute != nil {
+		line += fmt.Sprintf("\n    :arrows_counterclockwise: substitute: *%s*, %d in stock", slackProductLink(substitute.ProductID, substitute.Name), substitute.Quantity)
+	}
+	return line
+}
+
+func slackProductLink(id int, name string) string {
+	name = slackEscape(name)
+	if slackDashboardURL != "" {
+		return fmt.Sprintf("<%s/products/%d|%s>", slackDashboardURL, id, name)
+	}
+	return name
+}
+
+// slackEscape escapes the characters Slack treats as control sequences.
+func slackEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
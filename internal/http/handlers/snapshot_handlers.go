@@ -0,0 +1,261 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+)
+
+const (
+	maxSnapshotLabelLength = 100
+	defaultHistoryWindow   = 90 * 24 * time.Hour
+)
+
+// CreateSnapshotHandler godoc
+// @Summary Capture the current stock of every product
+// @Description Records the quantity and price of all products at this moment, e.g. before a large import or a stocktake.
+// @Tags snapshots
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param snapshot body SnapshotRequest false "Optional label"
+// @Success 201 {object} models.Snapshot
+// @Failure 400 {string} string "Invalid input"
+// @Failure 500 {string} string "Internal error"
+// @Router /snapshots [post]
+func CreateSnapshotHandler(w http.ResponseWriter, r *http.Request) {
+	var req SnapshotRequest
+	if r.ContentLength != 0 {
+		if err := readJSON(w, r, &req); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+	req.Label = strings.TrimSpace(req.Label)
+	if len(req.Label) > maxSnapshotLabelLength {
+		http.Error(w, "label is too long", http.StatusBadRequest)
+		return
+	}
+
+	username, _ := GetUsernameFromContext(r)
+	s, err := snapshotRepo.Capture(req.Label, username, false)
+	if err != nil {
+		http.Error(w, "could not capture snapshot", http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(r, "create", "snapshot", s.ID, map[string]any{"label": s.Label, "product_count": s.ProductCount})
+	s.Items = nil
+	if err := writeJSON(w, http.StatusCreated, s); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// ListSnapshotsHandler godoc
+// @Summary List inventory snapshots
+// @Description Newest first, with totals only.
+// @Tags snapshots
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.Snapshot
+// @Failure 500 {string} string "Internal error"
+// @Router /snapshots [get]
+func ListSnapshotsHandler(w http.ResponseWriter, r *http.Request) {
+	snapshots, err := snapshotRepo.List()
+	if err != nil {
+		http.Error(w, "could not fetch snapshots", http.StatusInternalServerError)
+		return
+	}
+	if err := writeJSON(w, http.StatusOK, snapshots); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// GetSnapshotHandler godoc
+// @Summary Get an inventory snapshot with every product it captured
+// @Tags snapshots
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Snapshot ID"
+// @Success 200 {object} models.Snapshot
+// @Failure 400 {string} string "Invalid ID"
+// @Failure 404 {string} string "Snapshot not found"
+// @Failure 500 {string} string "Internal error"
+// @Router /snapshots/{id} [get]
+func GetSnapshotHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid snapshot ID", http.StatusBadRequest)
+		return
+	}
+
+	s, err := snapshotRepo.GetByID(id)
+	if err != nil {
+		writeSnapshotError(w, err)
+		return
+	}
+	if err := writeJSON(w, http.StatusOK, s); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// DiffSnapshotsHandler godoc
+// @Summary Compare two inventory snapshots
+// @Description Lists the products added, removed or changed between snapshot id and snapshot otherId, with quantity and value deltas.
+// @Tags snapshots
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Earlier snapshot ID"
+// @Param otherId path int true "Later snapshot ID"
+// @Success 200 {object} SnapshotDiff
+// @Failure 400 {string} string "Invalid ID"
+// @Failure 404 {string} string "Snapshot not found"
+// @Failure 500 {string} string "Internal error"
+// @Router /snapshots/{id}/diff/{otherId} [get]
+func DiffSnapshotsHandler(w http.ResponseWriter, r *http.Request) {
+	fromID, err := parseID(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid snapshot ID", http.StatusBadRequest)
+		return
+	}
+	toID, err := parseID(chi.URLParam(r, "otherId"))
+	if err != nil {
+		http.Error(w, "invalid snapshot ID", http.StatusBadRequest)
+		return
+	}
+
+	from, err := snapshotRepo.GetByID(fromID)
+	if err != nil {
+		writeSnapshotError(w, err)
+		return
+	}
+	to, err := snapshotRepo.GetByID(toID)
+	if err != nil {
+		writeSnapshotError(w, err)
+		return
+	}
+
+	if err := writeJSON(w, http.StatusOK, diffSnapshots(from, to)); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// StockHistoryHandler godoc
+// @Summary Chart stock levels over time
+// @Description Reads the daily snapshots, so long ranges cost one row per day instead of replaying every movement. Days before the product existed are left out.
+// @Tags snapshots
+// @Security BearerAuth
+// @Produce json
+// @Param within query string false "How far back to go, e.g. 30d or 720h (default 90d)"
+// @Param product_id query int false "Chart a single product instead of the whole inventory"
+// @Success 200 {object} StockHistory
+// @Failure 400 {string} string "Invalid input"
+// @Failure 500 {string} string "Internal error"
+// @Router /reports/stock-history [get]
+func StockHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	within, err := parseWithin(q.Get("within"), defaultHistoryWindow)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var productID *int
+	if v := q.Get("product_id"); v != "" {
+		id, err := parseID(v)
+		if err != nil {
+			http.Error(w, "invalid product_id", http.StatusBadRequest)
+			return
+		}
+		productID = &id
+	}
+
+	snapshots, err := snapshotRepo.ListScheduledSince(time.Now().UTC().Add(-within), productID != nil)
+	if err != nil {
+		http.Error(w, "could not fetch stock history", http.StatusInternalServerError)
+		return
+	}
+
+	history := StockHistory{ProductID: productID, Points: []StockHistoryPoint{}}
+	for _, s := range snapshots {
+		point := StockHistoryPoint{At: s.CreatedAt, Quantity: s.TotalQuantity, TotalValue: s.TotalValue}
+		if productID != nil {
+			i, found := slices.BinarySearchFunc(s.Items, *productID, func(item models.SnapshotItem, id int) int { return item.ProductID - id })
+			if !found {
+				continue
+			}
+			point.Quantity, point.TotalValue = s.Items[i].Quantity, itemValue(s.Items[i])
+		}
+		history.Points = append(history.Points, point)
+	}
+
+	if err := writeJSON(w, http.StatusOK, history); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// diffSnapshots walks both item lists, which are ordered by product ID, in a
+// single merge pass.
+func diffSnapshots(from, to models.Snapshot) SnapshotDiff {
+	diff := SnapshotDiff{
+		QuantityDelta:   to.TotalQuantity - from.TotalQuantity,
+		TotalValueDelta: roundMoney(to.TotalValue - from.TotalValue),
+		Changes:         []SnapshotChange{},
+	}
+
+	before, after := from.Items, to.Items
+	for len(before) > 0 || len(after) > 0 {
+		var c SnapshotChange
+		switch {
+		case len(after) == 0 || (len(before) > 0 && before[0].ProductID < after[0].ProductID):
+			c = SnapshotChange{ProductID: before[0].ProductID, Name: before[0].Name, Status: "removed",
+				QuantityBefore: before[0].Quantity, ValueBefore: itemValue(before[0])}
+			before = before[1:]
+			diff.Removed++
+		case len(before) == 0 || after[0].ProductID < before[0].ProductID:
+			c = SnapshotChange{ProductID: after[0].ProductID, Name: after[0].Name, Status: "added",
+				QuantityAfter: after[0].Quantity, ValueAfter: itemValue(after[0])}
+			after = after[1:]
+			diff.Added++
+		default:
+			b, a := before[0], after[0]
+			before, after = before[1:], after[1:]
+			if b.Quantity == a.Quantity && b.Price == a.Price {
+				continue
+			}
+			c = SnapshotChange{ProductID: a.ProductID, Name: a.Name, Status: "changed",
+				QuantityBefore: b.Quantity, QuantityAfter: a.Quantity, ValueBefore: itemValue(b), ValueAfter: itemValue(a)}
+			diff.Changed++
+		}
+		c.QuantityDelta = c.QuantityAfter - c.QuantityBefore
+		c.ValueDelta = roundMoney(c.ValueAfter - c.ValueBefore)
+		diff.Changes = append(diff.Changes, c)
+	}
+
+	from.Items, to.Items = nil, nil
+	diff.From, diff.To = from, to
+	return diff
+}
+
+func itemValue(item models.SnapshotItem) float64 {
+	return roundMoney(float64(item.Quantity) * item.Price)
+}
+
+func roundMoney(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+func writeSnapshotError(w http.ResponseWriter, err error) {
+	if errors.Is(err, repo.ErrSnapshotNotFound) {
+		http.Error(w, "snapshot not found", http.StatusNotFound)
+		return
+	}
+	http.Error(w, "could not fetch snapshot", http.StatusInternalServerError)
+}
@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+)
+
+const maxSubstitutes = 20
+
+// GetSubstitutesHandler godoc
+// @Summary List a product's substitutes
+// @Description Products that can stand in for this one when it runs out, in order of preference.
+// @Tags products
+// @Produce json
+// @Param id path int true "Product ID"
+// @Success 200 {array} ProductResponse
+// @Failure 400 {string} string "Invalid ID"
+// @Failure 404 {string} string "Product not found"
+// @Failure 500 {string} string "Internal error"
+// @Router /products/{id}/substitutes [get]
+func GetSubstitutesHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid product ID", http.StatusBadRequest)
+		return
+	}
+	scope, err := accessScope(r)
+	if err != nil {
+		http.Error(w, "could not load access grants", http.StatusInternalServerError)
+		return
+	}
+	if err := productInScope(scope, id); err != nil {
+		writeSubstituteLookupError(w, err)
+		return
+	}
+	if _, err := productRepo.GetByID(id); err != nil {
+		writeSubstituteLookupError(w, err)
+		return
+	}
+
+	substitutes, err := substituteRepo.List(id)
+	if err != nil {
+		http.Error(w, "could not fetch substitutes", http.StatusInternalServerError)
+		return
+	}
+	substitutes = scopedProducts(scope, substitutes)
+	resp := make([]ProductResponse, len(substitutes))
+	for i, p := range substitutes {
+		resp[i] = newProductResponse(p)
+	}
+	if err := writeJSON(w, http.StatusOK, resp); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// SetSubstitutesHandler godoc
+// @Summary Replace a product's substitutes
+// @Description Sets, in order of preference, the products that can stand in for this one. Links are one-way: list this product on the other side too if they are interchangeable. Work order shortages suggest the first substitute with enough stock. An empty list removes every link.
+// @Tags products
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param substitutes body SubstitutesRequest true "Substitute product IDs, preferred first"
+// @Success 200 {array} ProductResponse
+// @Failure 400 {string} string "Invalid input"
+// @Failure 404 {string} string "Product or substitute not found"
+// @Failure 500 {string} string "Internal error"
+// @Router /products/{id}/substitutes [put]
+func SetSubstitutesHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid product ID", http.StatusBadRequest)
+		return
+	}
+	var req SubstitutesRequest
+	if err := readJSON(w, r, &req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if len(req.ProductIDs) > maxSubstitutes {
+		http.Error(w, fmt.Sprintf("a product can have at most %d substitutes", maxSubstitutes), http.StatusBadRequest)
+		return
+	}
+	for i, sub := range req.ProductIDs {
+		switch {
+		case sub <= 0:
+			http.Error(w, "product_ids must be positive", http.StatusBadRequest)
+			return
+		case sub == id:
+			http.Error(w, "a product cannot substitute itself", http.StatusBadRequest)
+			return
+		case slices.Contains(req.ProductIDs[:i], sub):
+			http.Error(w, fmt.Sprintf("product %d is listed twice", sub), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if _, err := productRepo.GetByID(id); err != nil {
+		writeSubstituteLookupError(w, err)
+		return
+	}
+	scope, err := accessScope(r)
+	if err != nil {
+		http.Error(w, "could not load access grants", http.StatusInternalServerError)
+		return
+	}
+	if err := productInScope(scope, id); err != nil {
+		writeSubstituteLookupError(w, err)
+		return
+	}
+	for _, sub := range req.ProductIDs {
+		if err := productInScope(scope, sub); err != nil {
+			if errors.Is(err, repo.ErrProductNotFound) {
+				http.Error(w, "substitute product not found", http.StatusNotFound)
+				return
+			}
+			http.Error(w, "could not fetch substitutes", http.StatusInternalServerError)
+			return
+		}
+	}
+	before, err := substituteRepo.List(id)
+	if err != nil {
+		http.Error(w, "could not fetch substitutes", http.StatusInternalServerError)
+		return
+	}
+	if err := substituteRepo.Set(id, req.ProductIDs); err != nil {
+		if errors.Is(err, repo.ErrProductNotFound) {
+			http.Error(w, "substitute product not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "could not save substitutes", http.StatusInternalServerError)
+		return
+	}
+	substitutes, err := substituteRepo.List(id)
+	if err != nil {
+		http.Error(w, "could not fetch substitutes", http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(r, "substitutes", "product", id, map[string]any{"before": productIDs(before), "after": req.ProductIDs})
+	resp := make([]ProductResponse, len(substitutes))
+	for i, p := range substitutes {
+		resp[i] = newProductResponse(p)
+	}
+	if err := writeJSON(w, http.StatusOK, resp); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// inStockSubstitute returns the first substitute of productID, in order of
+// preference, that is not discontinued and has at least need in stock, or
+// nil when there is none.
+func inStockSubstitute(productID, need int) (*SuggestedSubstitute, error) {
+	substitutes, err := substituteRepo.List(productID)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range substitutes {
+		if p.Status != models.ProductStatusDiscontinued && p.Quantity >= max(need, 1) {
+			return &SuggestedSubstitute{ProductID: p.ID, Name: p.Name, Quantity: p.Quantity}, nil
+		}
+	}
+	return nil, nil
+}
+
+func productIDs(products []models.Product) []int {
+	ids := make([]int, len(products))
+	for i, p := range products {
+		ids[i] = p.ID
+	}
+	return ids
+}
+
+func writeSubstituteLookupError(w http.ResponseWriter, err error) {
+	if errors.Is(err, repo.ErrProductNotFound) {
+		http.Error(w, "product not found", http.StatusNotFound)
+		return
+	}
+	http.Error(w, "could not fetch product", http.StatusInternalServerError)
+}
@@ -0,0 +1,390 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/mail"
+	"slices"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+)
+
+const (
+	maxSupplierNameLength  = 100
+	maxSupplierPhoneLength = 30
+	maxSupplierSKULength   = 64
+)
+
+func normalizeSupplier(req SupplierRequest) (models.Supplier, error) {
+	s := models.Supplier{Name: strings.TrimSpace(req.Name), Email: strings.TrimSpace(req.Email), Phone: strings.TrimSpace(req.Phone)}
+	switch {
+	case s.Name == "":
+		return s, errors.New("name is required")
+	case len(s.Name) > maxSupplierNameLength:
+		return s, fmt.Errorf("name must be at most %d characters", maxSupplierNameLength)
+	case len(s.Phone) > maxSupplierPhoneLength:
+		return s, fmt.Errorf("phone must be at most %d characters", maxSupplierPhoneLength)
+	}
+	if s.Email != "" {
+		if addr, err := mail.ParseAddress(s.Email); err != nil || addr.Address != s.Email {
+			return s, errors.New("email is not a valid address")
+		}
+	}
+	return s, nil
+}
+
+// CreateSupplierHandler godoc
+// @Summary Create a supplier
+// @Tags suppliers
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param supplier body SupplierRequest true "Supplier"
+// @Success 201 {object} models.Supplier
+// @Failure 400 {string} string "Invalid input"
+// @Failure 403 {string} string "Admins only"
+// @Failure 409 {string} string "Name already in use"
+// @Failure 500 {string} string "Internal error"
+// @Router /suppliers [post]
+func CreateSupplierHandler(w http.ResponseWriter, r *http.Request) {
+	var req SupplierRequest
+	if err := readJSON(w, r, &req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	s, err := normalizeSupplier(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	created, err := supplierRepo.Create(s)
+	if err != nil {
+		writeSupplierError(w, err)
+		return
+	}
+
+	recordAudit(r, "create", "supplier", created.ID, created)
+	if err := writeJSON(w, http.StatusCreated, created); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// ListSuppliersHandler godoc
+// @Summary List suppliers
+// @Tags suppliers
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.Supplier
+// @Failure 500 {string} string "Internal error"
+// @Router /suppliers [get]
+func ListSuppliersHandler(w http.ResponseWriter, r *http.Request) {
+	suppliers, err := supplierRepo.List()
+	if err != nil {
+		http.Error(w, "could not fetch suppliers", http.StatusInternalServerError)
+		return
+	}
+	if err := writeJSON(w, http.StatusOK, suppliers); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// GetSupplierHandler godoc
+// @Summary Get a supplier
+// @Tags suppliers
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Supplier ID"
+// @Success 200 {object} models.Supplier
+// @Failure 400 {string} string "Invalid ID"
+// @Failure 404 {string} string "Supplier not found"
+// @Failure 500 {string} string "Internal error"
+// @Router /suppliers/{id} [get]
+func GetSupplierHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid supplier ID", http.StatusBadRequest)
+		return
+	}
+
+	s, err := supplierRepo.GetByID(id)
+	if err != nil {
+		writeSupplierError(w, err)
+		return
+	}
+	if err := writeJSON(w, http.StatusOK, s); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// UpdateSupplierHandler godoc
+// @Summary Update a supplier
+// @Tags suppliers
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Supplier ID"
+// @Param supplier body SupplierRequest true "Supplier"
+// @Success 200 {object} models.Supplier
+// @Failure 400 {string} string "Invalid input"
+// @Failure 403 {string} string "Admins only"
+// @Failure 404 {string} string "Supplier not found"
+// @Failure 409 {string} string "Name already in use"
+// @Failure 500 {string} string "Internal error"
+// @Router /suppliers/{id} [put]
+func UpdateSupplierHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid supplier ID", http.StatusBadRequest)
+		return
+	}
+	var req SupplierRequest
+	if err := readJSON(w, r, &req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	s, err := normalizeSupplier(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	before, err := supplierRepo.GetByID(id)
+	if err != nil {
+		writeSupplierError(w, err)
+		return
+	}
+	s.ID = id
+	updated, err := supplierRepo.Update(s)
+	if err != nil {
+		writeSupplierError(w, err)
+		return
+	}
+
+	recordAudit(r, "update", "supplier", id, map[string]any{"before": before, "after": updated})
+	if err := writeJSON(w, http.StatusOK, updated); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// DeleteSupplierHandler godoc
+// @Summary Delete a supplier
+// @Description Also removes the supplier's terms from every product.
+// @Tags suppliers
+// @Security BearerAuth
+// @Param id path int true "Supplier ID"
+// @Success 204 "No Content"
+// @Failure 400 {string} string "Invalid ID"
+// @Failure 403 {string} string "Admins only"
+// @Failure 404 {string} string "Supplier not found"
+// @Failure 500 {string} string "Internal error"
+// @Router /suppliers/{id} [delete]
+func DeleteSupplierHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid supplier ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := supplierRepo.Delete(id); err != nil {
+		writeSupplierError(w, err)
+		return
+	}
+
+	recordAudit(r, "delete", "supplier", id, nil)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetProductSuppliersHandler godoc
+// @Summary List the suppliers of a product
+// @Tags suppliers
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Product ID"
+// @Success 200 {array} models.ProductSupplier
+// @Failure 400 {string} string "Invalid ID"
+// @Failure 500 {string} string "Internal error"
+// @Router /products/{id}/suppliers [get]
+func GetProductSuppliersHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid product ID", http.StatusBadRequest)
+		return
+	}
+
+	linked, err := supplierRepo.ForProducts([]int{id})
+	if err != nil {
+		http.Error(w, "could not fetch suppliers", http.StatusInternalServerError)
+		return
+	}
+	suppliers := linked[id]
+	if suppliers == nil {
+		suppliers = []models.ProductSupplier{}
+	}
+	if err := writeJSON(w, http.StatusOK, suppliers); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// LinkProductSupplierHandler godoc
+// @Summary Set the terms a supplier sells a product on
+// @Description Links the supplier to the product, or replaces the terms of an existing link.
+// @Tags suppliers
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param supplierId path int true "Supplier ID"
+// @Param terms body ProductSupplierRequest true "Supplier SKU, cost price and lead time"
+// @Success 200 {object} models.ProductSupplier
+// @Failure 400 {string} string "Invalid input"
+// @Failure 404 {string} string "Product or supplier not found"
+// @Failure 500 {string} string "Internal error"
+// @Router /products/{id}/suppliers/{supplierId} [put]
+func LinkProductSupplierHandler(w http.ResponseWriter, r *http.Request) {
+	productID, supplierID, ok := productSupplierIDs(w, r)
+	if !ok {
+		return
+	}
+	var req ProductSupplierRequest
+	if err := readJSON(w, r, &req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	ps := models.ProductSupplier{
+		ProductID:    productID,
+		SupplierID:   supplierID,
+		SupplierSKU:  strings.TrimSpace(req.SupplierSKU),
+		CostPrice:    roundMoney(req.CostPrice),
+		LeadTimeDays: req.LeadTimeDays,
+	}
+	switch {
+	case ps.CostPrice < 0:
+		http.Error(w, "cost_price cannot be negative", http.StatusBadRequest)
+		return
+	case ps.LeadTimeDays < 0:
+		http.Error(w, "lead_time_days cannot be negative", http.StatusBadRequest)
+		return
+	case len(ps.SupplierSKU) > maxSupplierSKULength:
+		http.Error(w, fmt.Sprintf("supplier_sku must be at most %d characters", maxSupplierSKULength), http.StatusBadRequest)
+		return
+	}
+
+	linked, err := supplierRepo.Link(ps)
+	if err != nil {
+		writeSupplierError(w, err)
+		return
+	}
+
+	recordAudit(r, "link_supplier", "product", productID, linked)
+	if err := writeJSON(w, http.StatusOK, linked); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// UnlinkProductSupplierHandler godoc
+// @Summary Stop buying a product from a supplier
+// @Tags suppliers
+// @Security BearerAuth
+// @Param id path int true "Product ID"
+// @Param supplierId path int true "Supplier ID"
+// @Success 204 "No Content"
+// @Failure 400 {string} string "Invalid ID"
+// @Failure 404 {string} string "Supplier not linked to the product"
+// @Failure 500 {string} string "Internal error"
+// @Router /products/{id}/suppliers/{supplierId} [delete]
+func UnlinkProductSupplierHandler(w http.ResponseWriter, r *http.Request) {
+	productID, supplierID, ok := productSupplierIDs(w, r)
+	if !ok {
+		return
+	}
+
+	if err := supplierRepo.Unlink(productID, supplierID); err != nil {
+		writeSupplierError(w, err)
+		return
+	}
+
+	recordAudit(r, "unlink_supplier", "product", productID, map[string]int{"supplier_id": supplierID})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// productSupplierIDs reads the product and supplier IDs from the URL. It
+// writes the error response itself.
+func productSupplierIDs(w http.ResponseWriter, r *http.Request) (productID, supplierID int, ok bool) {
+	productID, err := parseID(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid product ID", http.StatusBadRequest)
+		return 0, 0, false
+	}
+	supplierID, err = parseID(chi.URLParam(r, "supplierId"))
+	if err != nil {
+		http.Error(w, "invalid supplier ID", http.StatusBadRequest)
+		return 0, 0, false
+	}
+	return productID, supplierID, true
+}
+
+// productIncludes are the related records include= can embed in product
+// responses. GET /products/{id} can also embed productDetailIncludes.
+var (
+	productIncludes       = []string{"suppliers", "health"}
+	productDetailIncludes = []string{"suppliers", "health", "stats"}
+)
+
+// parseIncludes reads the comma-separated include parameter, accepting the
+// names in allowed.
+func parseIncludes(r *http.Request, allowed []string) (map[string]bool, error) {
+	include := map[string]bool{}
+	raw := r.URL.Query().Get("include")
+	if raw == "" {
+		return include, nil
+	}
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !slices.Contains(allowed, name) {
+			return nil, fmt.Errorf("include accepts %s", strings.Join(allowed, ", "))
+		}
+		include[name] = true
+	}
+	return include, nil
+}
+
+// embedIncludes fills in the related records include asks for.
+func embedIncludes(products []ProductResponse, include map[string]bool) error {
+	if !include["suppliers"] || len(products) == 0 {
+		return nil
+	}
+	ids := make([]int, len(products))
+	for i, p := range products {
+		ids[i] = p.Id
+	}
+	linked, err := supplierRepo.ForProducts(ids)
+	if err != nil {
+		return err
+	}
+	for i := range products {
+		products[i].Suppliers = linked[products[i].Id]
+	}
+	return nil
+}
+
+func writeSupplierError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, repo.ErrSupplierNotFound):
+		http.Error(w, "supplier not found", http.StatusNotFound)
+	case errors.Is(err, repo.ErrProductNotFound):
+		http.Error(w, "product not found", http.StatusNotFound)
+	case errors.Is(err, repo.ErrSupplierNotLinked):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, repo.ErrDuplicatedValueUnique):
+		http.Error(w, "supplier name already in use", http.StatusConflict)
+	default:
+		log.Printf("supplier: %v", err)
+		http.Error(w, "could not process supplier", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,416 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+)
+
+const (
+	defaultSyncLimit = 500
+	maxSyncLimit     = 1000
+	maxSyncBatch     = 500
+)
+
+// Reasons a sync operation is rejected.
+const (
+	syncReasonInsufficientStock = "insufficient_stock"
+	syncReasonProductNotFound   = "product_not_found"
+	syncReasonPeriodClosed      = "period_closed"
+	syncReasonOutsideGrants     = "outside_access_grants"
+	syncReasonInternal          = "internal_error"
+)
+
+// GetSyncChangesHandler godoc
+// @Summary Changes since a sync cursor
+// @Description Returns products changed, movements recorded and products deleted after the cursor. Omit the cursor for a full sync and keep calling with the returned cursor while has_more is true.
+// @Tags sync
+// @Security BearerAuth
+// @Produce json
+// @Param since query string false "Cursor returned by the previous call"
+// @Param limit query int false "Maximum rows per change type (default 500, max 1000)"
+// @Success 200 {object} SyncChangesResponse
+// @Failure 400 {string} string "Invalid cursor"
+// @Failure 500 {string} string "Internal error"
+// @Router /sync/changes [get]
+func GetSyncChangesHandler(w http.ResponseWriter, r *http.Request) {
+	cursor, err := decodeSyncCursor(r.URL.Query().Get("since"))
+	if err != nil {
+		http.Error(w, "invalid cursor", http.StatusBadRequest)
+		return
+	}
+	limit := defaultSyncLimit
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		limit = min(n, maxSyncLimit)
+	}
+
+	scope, err := accessScope(r)
+	if err != nil {
+		http.Error(w, "could not load access grants", http.StatusInternalServerError)
+		return
+	}
+
+	changes, err := syncRepo.Changes(cursor, limit)
+	if err != nil {
+		log.Printf("failed to read sync changes: %v", err)
+		http.Error(w, "could not read changes", http.StatusInternalServerError)
+		return
+	}
+	changes.Products = scopedProducts(scope, changes.Products)
+	if changes.Movements, err = scopedMovements(scope, changes.Movements); err != nil {
+		http.Error(w, "could not fetch products", http.StatusInternalServerError)
+		return
+	}
+	next, err := encodeSyncCursor(changes.Next)
+	if err != nil {
+		http.Error(w, "could not encode cursor", http.StatusInternalServerError)
+		return
+	}
+
+	resp := SyncChangesResponse{
+		Products:          make([]ProductResponse, len(changes.Products)),
+		Movements:         make([]MovementResponse, len(changes.Movements)),
+		DeletedProductIDs: changes.DeletedProductIDs,
+		Cursor:            next,
+		HasMore:           changes.HasMore,
+	}
+	for i, p := range changes.Products {
+		resp.Products[i] = newProductResponse(p)
+	}
+	for i, m := range changes.Movements {
+		resp.Movements[i] = MovementResponse{
+			ID:          m.ID,
+			ProductID:   m.ProductID,
+			Delta:       m.Delta,
+			CreatedAt:   m.CreatedAt,
+			WorkOrderID: m.WorkOrderID,
+			ExternalID:  m.ExternalID,
+			WarehouseID: m.WarehouseID,
+		}
+	}
+
+	if err := writeJSON(w, http.StatusOK, resp); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// Actions of the product change feed.
+const (
+	productChangeCreated = "created"
+	productChangeUpdated = "updated"
+	productChangeDeleted = "deleted"
+)
+
+// GetChangedProductsHandler godoc
+// @Summary Products created, updated or deleted since a point in time
+// @Description Incremental pull for external caches and storefront sync: one stream of product changes ordered by time, each product at its current state and deletions as tombstones. Start from a timestamp (or omit since for everything), then keep calling with the returned cursor while has_more is true. A product written several times appears once, at its latest write.
+// @Tags sync
+// @Security BearerAuth
+// @Produce json
+// @Param since query string false "RFC3339 timestamp, or the cursor returned by the previous call"
+// @Param limit query int false "Maximum changes (default 500, max 1000)"
+// @Success 200 {object} ProductChangesResponse
+// @Failure 400 {string} string "Invalid input"
+// @Failure 500 {string} string "Internal error"
+// @Router /products/changed [get]
+func GetChangedProductsHandler(w http.ResponseWriter, r *http.Request) {
+	var after repo.SyncPosition
+	if raw := r.URL.Query().Get("since"); raw != "" {
+		pos, err := decodeProductChangeCursor(raw)
+		if err != nil {
+			t, err := parseTime(raw)
+			if err != nil {
+				http.Error(w, "since must be an RFC3339 timestamp or a cursor", http.StatusBadRequest)
+				return
+			}
+			pos = repo.SyncPosition{At: t.UTC()}
+		}
+		after = pos
+	}
+	limit := defaultSyncLimit
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		limit = min(n, maxSyncLimit)
+	}
+
+	scope, err := accessScope(r)
+	if err != nil {
+		http.Error(w, "could not load access grants", http.StatusInternalServerError)
+		return
+	}
+
+	changes, hasMore, err := syncRepo.ProductChanges(after, limit)
+	if err != nil {
+		log.Printf("failed to read product changes: %v", err)
+		http.Error(w, "could not read changes", http.StatusInternalServerError)
+		return
+	}
+
+	resp := ProductChangesResponse{Changes: make([]ProductChangeResponse, 0, len(changes)), HasMore: hasMore}
+	for _, c := range changes {
+		// Products outside the caller's grants are skipped, but the cursor
+		// still moves past them.
+		after = c.Position()
+		if c.Product != nil && !scope.AllowsCategory(c.Product.Category) {
+			continue
+		}
+		change := ProductChangeResponse{ProductID: c.ProductID, At: c.At.UTC().Format(time.RFC3339Nano)}
+		switch {
+		case c.Deleted:
+			change.Action = productChangeDeleted
+		case c.Product.Seq <= 1:
+			change.Action = productChangeCreated
+		default:
+			change.Action = productChangeUpdated
+		}
+		if c.Product != nil {
+			p := newProductResponse(*c.Product)
+			change.Product = &p
+		}
+		resp.Changes = append(resp.Changes, change)
+	}
+	if resp.Cursor, err = encodeProductChangeCursor(after); err != nil {
+		http.Error(w, "could not encode cursor", http.StatusInternalServerError)
+		return
+	}
+
+	if err := writeJSON(w, http.StatusOK, resp); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// SyncBatchHandler godoc
+// @Summary Replay adjustments queued while offline
+// @Description Each operation is applied at most once, keyed by its client_id. Deltas are applied to the current server stock in order; an operation that would take stock below zero is rejected and the client gets the server quantity to reconcile with.
+// @Tags sync
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param batch body SyncBatchRequest true "Queued operations, oldest first"
+// @Success 200 {object} SyncBatchResponse
+// @Failure 400 {string} string "Invalid input"
+// @Failure 500 {string} string "Internal error"
+// @Router /sync/batch [post]
+func SyncBatchHandler(w http.ResponseWriter, r *http.Request) {
+	var req SyncBatchRequest
+	if err := readJSON(w, r, &req); err != nil {
+		http.Error(w, "invalid input", http.StatusBadRequest)
+		return
+	}
+	if len(req.Operations) == 0 || len(req.Operations) > maxSyncBatch {
+		http.Error(w, "a batch must hold between 1 and 500 operations", http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now().UTC()
+	occurred := make([]time.Time, len(req.Operations))
+	for i := range req.Operations {
+		op := &req.Operations[i]
+		var ok bool
+		if op.ClientID, ok = normalizeUUID(op.ClientID); !ok {
+			http.Error(w, "client_id must be a UUID: "+op.ClientID, http.StatusBadRequest)
+			return
+		}
+		if op.Delta == 0 {
+			http.Error(w, "delta must not be zero for "+op.ClientID, http.StatusBadRequest)
+			return
+		}
+		occurred[i] = now
+		if op.OccurredAt != "" {
+			t, err := parseTime(op.OccurredAt)
+			if err != nil {
+				http.Error(w, "invalid occurred_at for "+op.ClientID, http.StatusBadRequest)
+				return
+			}
+			// Device clocks drift; an adjustment cannot have happened after it reached us.
+			if t.Before(now) {
+				occurred[i] = t.UTC()
+			}
+		}
+	}
+
+	username, err := GetUsernameFromContext(r)
+	if err != nil {
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+	scope, err := accessScope(r)
+	if err != nil {
+		http.Error(w, "could not load access grants", http.StatusInternalServerError)
+		return
+	}
+
+	resp := SyncBatchResponse{Results: make([]SyncResult, len(req.Operations))}
+	for i, op := range req.Operations {
+		if reason := syncScopeRejection(scope, op.ProductID); reason != "" {
+			resp.Results[i] = SyncResult{ClientID: op.ClientID, Status: models.SyncStatusRejected, Reason: reason}
+			continue
+		}
+		resp.Results[i] = applySyncOperation(r, models.SyncOperation{
+			ClientID:  op.ClientID,
+			ProductID: op.ProductID,
+			Delta:     op.Delta,
+			CreatedBy: username,
+		}, occurred[i])
+	}
+
+	if err := writeJSON(w, http.StatusOK, resp); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// syncScopeRejection returns why an operation on the product is outside
+// scope, or "" if it is not. Sync operations name no warehouse, so users
+// granted warehouses cannot replay them. Such operations are not recorded,
+// so that they apply once the grants allow it.
+func syncScopeRejection(scope repo.AccessScope, productID int) string {
+	if !warehouseInScope(scope, nil) {
+		return syncReasonOutsideGrants
+	}
+	err := productInScope(scope, productID)
+	switch {
+	case errors.Is(err, repo.ErrProductNotFound):
+		return syncReasonProductNotFound
+	case err != nil:
+		return syncReasonInternal
+	}
+	return ""
+}
+
+func applySyncOperation(r *http.Request, op models.SyncOperation, occurredAt time.Time) SyncResult {
+	result := SyncResult{ClientID: op.ClientID}
+
+	existing, claimed, err := syncRepo.Claim(op)
+	if err != nil {
+		log.Printf("failed to claim sync operation %s: %v", op.ClientID, err)
+		result.Status, result.Reason = models.SyncStatusRejected, syncReasonInternal
+		return result
+	}
+	if !claimed {
+		result.Status, result.Reason, result.Duplicate = existing.Status, existing.Reason, true
+		result.Quantity = currentQuantity(existing.ProductID)
+		return result
+	}
+
+	op.Status = models.SyncStatusApplied
+	if err := ensurePeriodOpen(occurredAt); err != nil {
+		op.Status, op.Reason = models.SyncStatusRejected, syncReasonPeriodClosed
+		if !errors.Is(err, errPeriodClosed) {
+			op.Reason = syncReasonInternal
+		}
+	} else if product, err := productRepo.AdjustQuantity(op.ProductID, op.Delta); err != nil {
+		op.Status, op.Reason = models.SyncStatusRejected, syncReasonInternal
+		if errors.Is(err, repo.ErrInvalidQuantityChange) {
+			op.Reason = syncReasonInsufficientStock
+			if _, err := productRepo.GetByID(op.ProductID); errors.Is(err, repo.ErrProductNotFound) {
+				op.Reason = syncReasonProductNotFound
+			}
+		}
+	} else {
+		result.Quantity = &product.Quantity
+		movement, err := movementRepo.Log(models.Movement{ProductID: op.ProductID, Delta: op.Delta, CreatedAt: occurredAt.Format(time.RFC3339)})
+		recordMovementLog(op.ProductID, op.Delta, err)
+		if err == nil {
+			op.MovementID = &movement.ID
+		}
+		recordAudit(r, "sync-adjust", "product", op.ProductID, op)
+	}
+
+	if err := syncRepo.Finish(op); err != nil {
+		log.Printf("failed to record outcome of sync operation %s: %v", op.ClientID, err)
+	}
+	if result.Quantity == nil {
+		result.Quantity = currentQuantity(op.ProductID)
+	}
+	result.Status, result.Reason = op.Status, op.Reason
+	return result
+}
+
+// scopedMovements drops the movements of products outside scope, including
+// those of products deleted since.
+func scopedMovements(scope repo.AccessScope, movements []models.Movement) ([]models.Movement, error) {
+	if scope.Categories == nil {
+		return movements, nil
+	}
+	allowed := make(map[int]bool)
+	kept := movements[:0]
+	for _, m := range movements {
+		ok, seen := allowed[m.ProductID]
+		if !seen {
+			err := productInScope(scope, m.ProductID)
+			if err != nil && !errors.Is(err, repo.ErrProductNotFound) {
+				return nil, err
+			}
+			ok = err == nil
+			allowed[m.ProductID] = ok
+		}
+		if ok {
+			kept = append(kept, m)
+		}
+	}
+	return kept, nil
+}
+
+// currentQuantity returns the product's stock, or nil if it cannot be read.
+func currentQuantity(productID int) *int {
+	p, err := productRepo.GetByID(productID)
+	if err != nil {
+		return nil
+	}
+	return &p.Quantity
+}
+
+func decodeSyncCursor(raw string) (repo.SyncCursor, error) {
+	var cursor repo.SyncCursor
+	if raw == "" {
+		return cursor, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return cursor, err
+	}
+	err = json.Unmarshal(data, &cursor)
+	return cursor, err
+}
+
+func encodeSyncCursor(cursor repo.SyncCursor) (string, error) {
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeProductChangeCursor(raw string) (repo.SyncPosition, error) {
+	var pos repo.SyncPosition
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return pos, err
+	}
+	err = json.Unmarshal(data, &pos)
+	return pos, err
+}
+
+func encodeProductChangeCursor(pos repo.SyncPosition) (string, error) {
+	data, err := json.Marshal(pos)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
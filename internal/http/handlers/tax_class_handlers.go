@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+)
+
+const (
+	maxTaxClassNameLength = 50
+	maxTaxRate            = 100
+)
+
+func normalizeTaxClass(req TaxClassRequest) (models.TaxClass, error) {
+	c := models.TaxClass{Name: strings.TrimSpace(req.Name), Rate: req.Rate}
+	switch {
+	case c.Name == "":
+		return c, errors.New("name is required")
+	case len(c.Name) > maxTaxClassNameLength:
+		return c, fmt.Errorf("name must be at most %d characters", maxTaxClassNameLength)
+	case c.Rate < 0 || c.Rate > maxTaxRate:
+		return c, fmt.Errorf("rate must be between 0 and %d", maxTaxRate)
+	}
+	return c, nil
+}
+
+// CreateTaxClassHandler godoc
+// @Summary Create a tax class
+// @Tags tax
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param taxClass body TaxClassRequest true "Tax class"
+// @Success 201 {object} models.TaxClass
+// @Failure 400 {string} string "Invalid input"
+// @Failure 409 {string} string "Name already in use"
+// @Failure 500 {string} string "Internal error"
+// @Router /admin/tax-classes [post]
+func CreateTaxClassHandler(w http.ResponseWriter, r *http.Request) {
+	var req TaxClassRequest
+	if err := readJSON(w, r, &req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	c, err := normalizeTaxClass(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	created, err := taxClassRepo.Create(c)
+	if err != nil {
+		writeTaxClassError(w, err)
+		return
+	}
+
+	recordAudit(r, "create", "tax_class", created.ID, created)
+	if err := writeJSON(w, http.StatusCreated, created); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// ListTaxClassesHandler godoc
+// @Summary List tax classes
+// @Tags tax
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.TaxClass
+// @Failure 500 {string} string "Internal error"
+// @Router /admin/tax-classes [get]
+func ListTaxClassesHandler(w http.ResponseWriter, r *http.Request) {
+	classes, err := taxClassRepo.List()
+	if err != nil {
+		http.Error(w, "could not fetch tax classes", http.StatusInternalServerError)
+		return
+	}
+	if err := writeJSON(w, http.StatusOK, classes); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// UpdateTaxClassHandler godoc
+// @Summary Rename a tax class or change its rate
+// @Description A new rate applies at once to every product in the class.
+// @Tags tax
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Tax class ID"
+// @Param taxClass body TaxClassRequest true "Tax class"
+// @Success 200 {object} models.TaxClass
+// @Failure 400 {string} string "Invalid input"
+// @Failure 404 {string} string "Tax class not found"
+// @Failure 409 {string} string "Name already in use"
+// @Failure 500 {string} string "Internal error"
+// @Router /admin/tax-classes/{id} [put]
+func UpdateTaxClassHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid tax class ID", http.StatusBadRequest)
+		return
+	}
+	var req TaxClassRequest
+	if err := readJSON(w, r, &req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	c, err := normalizeTaxClass(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	before, err := taxClassRepo.GetByID(id)
+	if err != nil {
+		writeTaxClassError(w, err)
+		return
+	}
+	c.ID = id
+	updated, err := taxClassRepo.Update(c)
+	if err != nil {
+		writeTaxClassError(w, err)
+		return
+	}
+
+	recordAudit(r, "update", "tax_class", id, map[string]any{"before": before, "after": updated})
+	if err := writeJSON(w, http.StatusOK, updated); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// DeleteTaxClassHandler godoc
+// @Summary Delete a tax class
+// @Tags tax
+// @Security BearerAuth
+// @Param id path int true "Tax class ID"
+// @Success 204 "No Content"
+// @Failure 400 {string} string "Invalid ID"
+// @Failure 404 {string} string "Tax class not found"
+// @Failure 409 {string} string "Tax class is assigned to products"
+// @Failure 500 {string} string "Internal error"
+// @Router /admin/tax-classes/{id} [delete]
+func DeleteTaxClassHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid tax class ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := taxClassRepo.Delete(id); err != nil {
+		writeTaxClassError(w, err)
+		return
+	}
+
+	recordAudit(r, "delete", "tax_class", id, nil)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeTaxClassError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, repo.ErrTaxClassNotFound):
+		http.Error(w, "tax class not found", http.StatusNotFound)
+	case errors.Is(err, repo.ErrDuplicatedValueUnique):
+		http.Error(w, "tax class name already in use", http.StatusConflict)
+	case errors.Is(err, repo.ErrTaxClassInUse):
+		http.Error(w, "tax class is assigned to products", http.StatusConflict)
+	default:
+		http.Error(w, "could not process tax class", http.StatusInternalServerError)
+	}
+}
+
+func validateTaxClass(id *int) []ProductValidationError {
+	if id == nil {
+		return nil
+	}
+	if _, err := taxClassRepo.GetByID(*id); err != nil {
+		desc := "Tax class does not exist"
+		if !errors.Is(err, repo.ErrTaxClassNotFound) {
+			log.Printf("failed to look up tax class %d: %v", *id, err)
+			desc = "Tax class could not be verified"
+		}
+		return []ProductValidationError{{Field: "TaxClassID", Description: desc}}
+	}
+	return nil
+}
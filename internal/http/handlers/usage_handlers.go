@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+	"github.com/rogerio-castellano/inventory-tracker/internal/usage"
+)
+
+// GetUsageHandler godoc
+// @Summary API usage per user for a month
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param user query string false "Restrict to a single username"
+// @Param month query string false "Month (YYYY-MM), defaults to the current month"
+// @Success 200 {object} UsageReport
+// @Failure 400 {string} string "Invalid month"
+// @Failure 500 {string} string "Internal error"
+// @Router /admin/usage [get]
+func GetUsageHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	writeUsageReport(w, q.Get("user"), q.Get("month"))
+}
+
+// MeUsageHandler godoc
+// @Summary API usage of the current user for a month
+// @Tags auth
+// @Security BearerAuth
+// @Produce json
+// @Param month query string false "Month (YYYY-MM), defaults to the current month"
+// @Success 200 {object} UsageReport
+// @Failure 400 {string} string "Invalid month"
+// @Failure 500 {string} string "Internal error"
+// @Router /me/usage [get]
+func MeUsageHandler(w http.ResponseWriter, r *http.Request) {
+	username, err := GetUsernameFromContext(r)
+	if err != nil || username == "" {
+		http.Error(w, "invalid token", http.StatusUnauthorized)
+		return
+	}
+	writeUsageReport(w, username, r.URL.Query().Get("month"))
+}
+
+func writeUsageReport(w http.ResponseWriter, username, month string) {
+	if month == "" {
+		month = repo.PeriodOf(time.Now())
+	}
+	if _, _, err := repo.PeriodBounds(month); err != nil {
+		http.Error(w, "month must be formatted as YYYY-MM", http.StatusBadRequest)
+		return
+	}
+
+	records, err := usageRepo.GetMonthly(username, month)
+	if err != nil {
+		http.Error(w, "could not fetch usage", http.StatusInternalServerError)
+		return
+	}
+
+	// Today's counters are still in Redis until the aggregator runs.
+	today := usage.Today()
+	if strings.HasPrefix(today, month+"-") {
+		live, err := liveUsage(username, today)
+		if err != nil {
+			log.Printf("failed to read live usage: %v", err)
+		}
+		records = append(records, live...)
+	}
+
+	if err := writeJSON(w, http.StatusOK, buildUsageReport(username, month, records)); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+func liveUsage(username, day string) ([]models.UsageRecord, error) {
+	if username == "" {
+		return usage.LiveAll(day)
+	}
+	live, err := usage.Live(username, day)
+	if err != nil || live.Requests == 0 {
+		return nil, err
+	}
+	return []models.UsageRecord{live}, nil
+}
+
+func buildUsageReport(username, month string, records []models.UsageRecord) UsageReport {
+	report := UsageReport{
+		Month:  month,
+		User:   username,
+		Days:   records,
+		ByUser: []models.UsageRecord{},
+	}
+
+	byUser := map[string]*models.UsageRecord{}
+	for _, rec := range records {
+		report.Totals.Requests += rec.Requests
+		report.Totals.BytesIn += rec.BytesIn
+		report.Totals.BytesOut += rec.BytesOut
+
+		total, ok := byUser[rec.Username]
+		if !ok {
+			total = &models.UsageRecord{Username: rec.Username}
+			byUser[rec.Username] = total
+		}
+		total.Requests += rec.Requests
+		total.BytesIn += rec.BytesIn
+		total.BytesOut += rec.BytesOut
+	}
+
+	for _, total := range byUser {
+		report.ByUser = append(report.ByUser, *total)
+	}
+	sort.Slice(report.ByUser, func(i, j int) bool {
+		return report.ByUser[i].Requests > report.ByUser[j].Requests
+	})
+
+	return report
+}
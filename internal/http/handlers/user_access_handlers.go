@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rogerio-castellano/inventory-tracker/internal/auth"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+	"github.com/rogerio-castellano/inventory-tracker/internal/security"
+)
+
+// revokeUserAccess ends every session of username: refresh tokens are
+// removed and access tokens issued until now are rejected, so a change to
+// the account applies on the next request rather than when tokens expire.
+func revokeUserAccess(username string) error {
+	if err := auth.RemoveUserRefreshTokens(username); err != nil {
+		return err
+	}
+	return Rdb.Set(Ctx, auth.RevokedBeforeKey(username), time.Now().UnixMilli(), auth.AccessTokenTTL).Err()
+}
+
+// SetUserRoleHandler godoc
+// @Summary Change a user's role
+// @Description Gives the user another role and revokes their sessions: refresh tokens are removed and access tokens already issued are rejected, so the user logs in again with the new role's permissions.
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param username path string true "Username"
+// @Param request body RoleChangeRequest true "New role"
+// @Success 200 {object} UserAccessResponse
+// @Failure 400 {string} string "Invalid input"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 404 {string} string "User not found"
+// @Failure 409 {string} string "Admins cannot change their own role"
+// @Failure 500 {string} string "Internal error"
+// @Router /admin/users/{username}/role [put]
+func SetUserRoleHandler(w http.ResponseWriter, r *http.Request) {
+	username := chi.URLParam(r, "username")
+	var req RoleChangeRequest
+	if err := readJSON(w, r, &req); err != nil {
+		http.Error(w, "invalid input", http.StatusBadRequest)
+		return
+	}
+	role := strings.ToLower(strings.TrimSpace(req.Role))
+	if !knownRole(role) {
+		http.Error(w, "unknown role", http.StatusBadRequest)
+		return
+	}
+	actor, _ := GetUsernameFromContext(r)
+	if actor == username {
+		http.Error(w, "admins cannot change their own role", http.StatusConflict)
+		return
+	}
+
+	user, err := userRepo.GetByUsername(username)
+	if err != nil {
+		http.Error(w, "user not found", http.StatusNotFound)
+		return
+	}
+	if user.Role != role {
+		if err := userRepo.SetRole(username, role); err != nil {
+			writeUserAccessError(w, err)
+			return
+		}
+		if err := revokeUserAccess(username); err != nil {
+			log.Printf("failed to revoke sessions of %s: %v", username, err)
+			http.Error(w, "role changed but sessions could not be revoked", http.StatusInternalServerError)
+			return
+		}
+		recordAudit(r, "set_role", "user", username, map[string]string{"role": role, "previous_role": user.Role})
+		security.Publish(security.Event{
+			Type:     security.EventRoleChange,
+			Severity: roleChangeSeverity(role),
+			Actor:    actor,
+			Target:   username,
+			SourceIP: clientIP(r),
+			Details:  map[string]any{"role": role, "previous_role": user.Role},
+		})
+	}
+
+	if err := writeJSON(w, http.StatusOK, UserAccessResponse{Username: username, Role: role, Disabled: user.Disabled}); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// DisableUserHandler godoc
+// @Summary Disable a user account
+// @Description Blocks the user from logging in and refreshing, and revokes their sessions: refresh tokens are removed and access tokens already issued are rejected.
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param username path string true "Username"
+// @Success 200 {object} UserAccessResponse
+// @Failure 403 {string} string "Forbidden"
+// @Failure 404 {string} string "User not found"
+// @Failure 409 {string} string "Admins cannot disable their own account"
+// @Failure 500 {string} string "Internal error"
+// @Router /admin/users/{username}/disable [post]
+func DisableUserHandler(w http.ResponseWriter, r *http.Request) {
+	setUserDisabled(w, r, true)
+}
+
+// EnableUserHandler godoc
+// @Summary Enable a disabled user account
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param username path string true "Username"
+// @Success 200 {object} UserAccessResponse
+// @Failure 403 {string} string "Forbidden"
+// @Failure 404 {string} string "User not found"
+// @Failure 500 {string} string "Internal error"
+// @Router /admin/users/{username}/enable [post]
+func EnableUserHandler(w http.ResponseWriter, r *http.Request) {
+	setUserDisabled(w, r, false)
+}
+
+func setUserDisabled(w http.ResponseWriter, r *http.Request, disabled bool) {
+	username := chi.URLParam(r, "username")
+	if actor, _ := GetUsernameFromContext(r); disabled && actor == username {
+		http.Error(w, "admins cannot disable their own account", http.StatusConflict)
+		return
+	}
+
+	user, err := userRepo.GetByUsername(username)
+	if err != nil {
+		http.Error(w, "user not found", http.StatusNotFound)
+		return
+	}
+	if user.Disabled != disabled {
+		if err := userRepo.SetDisabled(username, disabled); err != nil {
+			writeUserAccessError(w, err)
+			return
+		}
+		action := "enable"
+		if disabled {
+			action = "disable"
+			if err := revokeUserAccess(username); err != nil {
+				log.Printf("failed to revoke sessions of %s: %v", username, err)
+				http.Error(w, "account disabled but sessions could not be revoked", http.StatusInternalServerError)
+				return
+			}
+		}
+		recordAudit(r, action, "user", username, nil)
+	}
+
+	if err := writeJSON(w, http.StatusOK, UserAccessResponse{Username: username, Role: user.Role, Disabled: disabled}); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+func writeUserAccessError(w http.ResponseWriter, err error) {
+	if errors.Is(err, repo.ErrUserNotFound) {
+		http.Error(w, "user not found", http.StatusNotFound)
+		return
+	}
+	http.Error(w, "could not update user", http.StatusInternalServerError)
+}
@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/mail"
+	"strings"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/auth"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+	"github.com/rogerio-castellano/inventory-tracker/internal/security"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	maxUserImportRows  = 1000
+	maxUserImportBytes = 1 << 20
+)
+
+// userImportRow is one line of a user import file.
+type userImportRow struct {
+	Row      int
+	Username string
+	Email    string
+	Role     string
+}
+
+// ImportUsersHandler godoc
+// @Summary Import users from CSV
+// @Description Creates an account for every valid row of a CSV with username, email and role columns, to onboard a whole team at once. Each account gets a temporary password, returned only in this response, and must change it (POST /password/change) before it can log in. Invalid rows and taken usernames are reported and skipped.
+// @Tags admin
+// @Security BearerAuth
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "CSV file with username, email and role columns"
+// @Success 200 {object} UserImportResult
+// @Failure 400 {string} string "Invalid file"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 422 {object} ErrorResponse "Infected file"
+// @Failure 500 {string} string "Internal error"
+// @Failure 503 {object} ErrorResponse "File could not be scanned"
+// @Router /admin/users/import [post]
+func ImportUsersHandler(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxUserImportBytes+1<<10)
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "missing file", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+	if !scanUpload(w, r, file, header.Filename) {
+		return
+	}
+
+	rows, err := parseUserCSV(file)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(rows) > maxUserImportRows {
+		http.Error(w, fmt.Sprintf("at most %d users per import", maxUserImportRows), http.StatusBadRequest)
+		return
+	}
+
+	actor, _ := GetUsernameFromContext(r)
+	result := UserImportResult{Created: []ImportedUser{}, Errors: []UserImportError{}}
+	seen := map[string]int{}
+	for _, row := range rows {
+		if err := validateUserRow(row); err != nil {
+			result.Errors = append(result.Errors, UserImportError{Row: row.Row, Username: row.Username, Description: err.Error()})
+			continue
+		}
+		if first, ok := seen[strings.ToLower(row.Username)]; ok {
+			result.Errors = append(result.Errors, UserImportError{Row: row.Row, Username: row.Username, Description: fmt.Sprintf("username repeats row %d", first)})
+			continue
+		}
+		seen[strings.ToLower(row.Username)] = row.Row
+
+		password := rand.Text()
+		hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		if err != nil {
+			http.Error(w, "Error hashing password", http.StatusInternalServerError)
+			return
+		}
+		user := models.User{Username: row.Username, Email: row.Email, Role: row.Role, PasswordHash: string(hashed), MustChangePassword: true}
+		if _, err := userRepo.CreateUser(user); err != nil {
+			description := "could not create user"
+			if errors.Is(err, repo.ErrDuplicatedValueUnique) {
+				description = "username already exists"
+			} else {
+				log.Printf("user import: row %d: %v", row.Row, err)
+			}
+			result.Errors = append(result.Errors, UserImportError{Row: row.Row, Username: row.Username, Description: description})
+			continue
+		}
+
+		recordAudit(r, "import", "user", row.Username, map[string]any{"row": row.Row, "role": row.Role})
+		security.Publish(security.Event{
+			Type:     security.EventRoleChange,
+			Severity: roleChangeSeverity(row.Role),
+			Actor:    actor,
+			Target:   row.Username,
+			SourceIP: clientIP(r),
+			Details:  map[string]any{"role": row.Role, "previous_role": nil},
+		})
+		result.Created = append(result.Created, ImportedUser{Row: row.Row, Username: row.Username, Email: row.Email, Role: row.Role, TemporaryPassword: password})
+	}
+
+	if err := writeJSON(w, http.StatusOK, result); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+func parseUserCSV(file io.Reader) ([]userImportRow, error) {
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	headers, err := reader.Read()
+	if err != nil {
+		return nil, errors.New("invalid CSV header")
+	}
+	index := map[string]int{}
+	for i, h := range headers {
+		index[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	for _, c := range []string{"username", "email", "role"} {
+		if _, ok := index[c]; !ok {
+			return nil, fmt.Errorf("missing required column %q", c)
+		}
+	}
+
+	var rows []userImportRow
+	for rowNum := 2; ; rowNum++ { // header is row 1
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("CSV read error: %v", err)
+		}
+		field := func(name string) string {
+			if i := index[name]; i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		rows = append(rows, userImportRow{Row: rowNum, Username: field("username"), Email: field("email"), Role: strings.ToLower(field("role"))})
+	}
+	return rows, nil
+}
+
+func validateUserRow(row userImportRow) error {
+	switch {
+	case len(row.Username) < 3:
+		return errors.New("username must have at least 3 characters")
+	case row.Role == "":
+		return errors.New("missing role")
+	case !knownRole(row.Role):
+		return fmt.Errorf("unknown role %q", row.Role)
+	}
+	if row.Email != "" {
+		if addr, err := mail.ParseAddress(row.Email); err != nil || addr.Address != row.Email {
+			return errors.New("email is not a valid address")
+		}
+	}
+	return rejectFormulas(csvCell{"username", row.Username}, csvCell{"email", row.Email})
+}
+
+// knownRole reports whether role is admin or one of the configured roles.
+func knownRole(role string) bool {
+	if role == "admin" {
+		return true
+	}
+	_, ok := auth.RolePermissions()[role]
+	return ok
+}
@@ -1,12 +1,30 @@
 package handlers
 
 import (
+	"fmt"
+	"regexp"
 	"strings"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+const (
+	maxProductDescriptionLength = 2000
+	maxProductBrandLength       = 100
 )
 
+var uuidPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+
+// normalizeUUID lowercases a UUID and reports whether it is well formed.
+func normalizeUUID(s string) (string, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	return s, uuidPattern.MatchString(s)
+}
+
 type ProductValidationError struct {
 	Field       string `json:"field"`
 	Description string `json:"description"`
+	Code        string `json:"code,omitempty"`
 }
 
 func validateProduct(p ProductRequest) []ProductValidationError {
@@ -17,8 +35,97 @@ func validateProduct(p ProductRequest) []ProductValidationError {
 	if p.Price <= 0 {
 		errs = append(errs, ProductValidationError{Field: "Price", Description: "Price must be greater than zero"})
 	}
+	if p.Cost < 0 {
+		errs = append(errs, ProductValidationError{Field: "Cost", Description: "Cost cannot be negative"})
+	}
 	if p.Quantity < 0 {
 		errs = append(errs, ProductValidationError{Field: "Quantity", Description: "Quantity cannot be negative"})
 	}
+	if p.MaxQuantity < 0 {
+		errs = append(errs, ProductValidationError{Field: "MaxQuantity", Description: "Max quantity cannot be negative"})
+	}
+	if p.Status != "" && !models.ValidProductStatus(p.Status) {
+		errs = append(errs, ProductValidationError{Field: "Status", Description: "Status must be active, inactive or discontinued"})
+	}
+	if c := strings.ToUpper(strings.TrimSpace(p.Currency)); c != "" && !currencyPattern.MatchString(c) {
+		errs = append(errs, ProductValidationError{Field: "Currency", Description: "Currency must be a three-letter ISO 4217 code"})
+	}
+	if _, ok := normalizeUUID(p.ExternalID); p.ExternalID != "" && !ok {
+		errs = append(errs, ProductValidationError{Field: "ExternalID", Description: "External ID must be a UUID"})
+	}
+	if len(strings.TrimSpace(p.Description)) > maxProductDescriptionLength {
+		errs = append(errs, ProductValidationError{Field: "Description", Description: fmt.Sprintf("Description must be at most %d characters", maxProductDescriptionLength)})
+	}
+	if len(strings.TrimSpace(p.Brand)) > maxProductBrandLength {
+		errs = append(errs, ProductValidationError{Field: "Brand", Description: fmt.Sprintf("Brand must be at most %d characters", maxProductBrandLength)})
+	}
+	if len(strings.TrimSpace(p.Manufacturer)) > maxProductBrandLength {
+		errs = append(errs, ProductValidationError{Field: "Manufacturer", Description: fmt.Sprintf("Manufacturer must be at most %d characters", maxProductBrandLength)})
+	}
+	if p.Weight < 0 {
+		errs = append(errs, ProductValidationError{Field: "Weight", Description: "Weight cannot be negative"})
+	}
+	if d := p.Dimensions; d.Length < 0 || d.Width < 0 || d.Height < 0 {
+		errs = append(errs, ProductValidationError{Field: "Dimensions", Description: "Dimensions cannot be negative"})
+	}
+	return errs
+}
+
+// requiredFieldLabels names the requirable fields in validation errors, the
+// same way validateProduct does.
+var requiredFieldLabels = map[string]string{
+	"sku": "SKU", "barcode": "Barcode", "category": "Category", "supplier": "Supplier", "threshold": "Threshold",
+	"max_quantity": "MaxQuantity", "cost": "Cost", "external_id": "ExternalID", "tax_class_id": "TaxClassID",
+	"description": "Description", "brand": "Brand", "manufacturer": "Manufacturer", "weight": "Weight",
+}
+
+// requiredFields returns the fields this tenant's policy makes mandatory at creation.
+func requiredFields() ([]string, error) {
+	policy, err := validationPolicyRepo.Get(tenant)
+	if err != nil {
+		return nil, err
+	}
+	return policy.RequiredFields, nil
+}
+
+// validateRequiredFields reports the required fields p leaves empty. Numeric
+// fields count as empty when zero.
+func validateRequiredFields(p models.Product, required []string) []ProductValidationError {
+	errs := []ProductValidationError{}
+	for _, field := range required {
+		var missing bool
+		switch field {
+		case "sku":
+			missing = strings.TrimSpace(p.SKU) == ""
+		case "barcode":
+			missing = strings.TrimSpace(p.Barcode) == ""
+		case "category":
+			missing = strings.TrimSpace(p.Category) == ""
+		case "supplier":
+			missing = strings.TrimSpace(p.Supplier) == ""
+		case "threshold":
+			missing = p.Threshold == 0
+		case "max_quantity":
+			missing = p.MaxQuantity == 0
+		case "cost":
+			missing = p.Cost == 0
+		case "external_id":
+			missing = p.ExternalID == ""
+		case "tax_class_id":
+			missing = p.TaxClassID == nil
+		case "description":
+			missing = strings.TrimSpace(p.Description) == ""
+		case "brand":
+			missing = strings.TrimSpace(p.Brand) == ""
+		case "manufacturer":
+			missing = strings.TrimSpace(p.Manufacturer) == ""
+		case "weight":
+			missing = p.Weight == 0
+		}
+		if missing {
+			label := requiredFieldLabels[field]
+			errs = append(errs, ProductValidationError{Field: label, Description: label + " is required"})
+		}
+	}
 	return errs
 }
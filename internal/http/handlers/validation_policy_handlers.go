@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+// GetValidationPolicyHandler godoc
+// @Summary Get the product validation policy
+// @Description Lists the product fields this tenant requires when products are created, on top of the built-in rules.
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} models.ValidationPolicy
+// @Failure 500 {string} string "Internal error"
+// @Router /admin/validation-policy [get]
+func GetValidationPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	policy, err := validationPolicyRepo.Get(tenant)
+	if err != nil {
+		http.Error(w, "could not fetch validation policy", http.StatusInternalServerError)
+		return
+	}
+	if err := writeJSON(w, http.StatusOK, policy); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// UpdateValidationPolicyHandler godoc
+// @Summary Replace the product validation policy
+// @Description Sets the fields required when products are created through the API, bulk insert or CSV import. Existing products are not checked. Fields: sku, barcode, category, supplier, threshold, max_quantity, cost, external_id, tax_class_id, description, brand, manufacturer, weight.
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param policy body ValidationPolicyRequest true "Required fields"
+// @Success 200 {object} models.ValidationPolicy
+// @Failure 400 {string} string "Invalid input"
+// @Failure 500 {string} string "Internal error"
+// @Router /admin/validation-policy [put]
+func UpdateValidationPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	var req ValidationPolicyRequest
+	if err := readJSON(w, r, &req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	fields, err := normalizeRequiredFields(req.RequiredFields)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	before, err := validationPolicyRepo.Get(tenant)
+	if err != nil {
+		http.Error(w, "could not fetch validation policy", http.StatusInternalServerError)
+		return
+	}
+	policy, err := validationPolicyRepo.Put(models.ValidationPolicy{Tenant: tenant, RequiredFields: fields})
+	if err != nil {
+		http.Error(w, "could not save validation policy", http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(r, "update", "validation_policy", tenant, map[string]any{"before": before.RequiredFields, "after": policy.RequiredFields})
+	if err := writeJSON(w, http.StatusOK, policy); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// normalizeRequiredFields lowercases and dedupes a requested field list,
+// rejecting fields a policy cannot require.
+func normalizeRequiredFields(requested []string) ([]string, error) {
+	fields := []string{}
+	for _, f := range requested {
+		f = strings.ToLower(strings.TrimSpace(f))
+		if !slices.Contains(models.RequirableProductFields, f) {
+			return nil, fmt.Errorf("unknown field %q; allowed: %s", f, strings.Join(models.RequirableProductFields, ", "))
+		}
+		if !slices.Contains(fields, f) {
+			fields = append(fields, f)
+		}
+	}
+	return fields, nil
+}
@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+)
+
+const (
+	maxWarehouseNameLength    = 100
+	maxWarehouseAddressLength = 200
+)
+
+func normalizeWarehouse(req WarehouseRequest) (models.Warehouse, error) {
+	wh := models.Warehouse{Name: strings.TrimSpace(req.Name), Address: strings.TrimSpace(req.Address)}
+	switch {
+	case wh.Name == "":
+		return wh, errors.New("name is required")
+	case len(wh.Name) > maxWarehouseNameLength:
+		return wh, fmt.Errorf("name must be at most %d characters", maxWarehouseNameLength)
+	case len(wh.Address) > maxWarehouseAddressLength:
+		return wh, fmt.Errorf("address must be at most %d characters", maxWarehouseAddressLength)
+	}
+	return wh, nil
+}
+
+// CreateWarehouseHandler godoc
+// @Summary Create a warehouse
+// @Description Adjustments given its warehouse_id then track stock there; see POST /products/{id}/adjust.
+// @Tags warehouses
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param warehouse body WarehouseRequest true "Warehouse"
+// @Success 201 {object} models.Warehouse
+// @Failure 400 {string} string "Invalid input"
+// @Failure 403 {string} string "Admins only"
+// @Failure 409 {string} string "Name already in use"
+// @Failure 500 {string} string "Internal error"
+// @Router /warehouses [post]
+func CreateWarehouseHandler(w http.ResponseWriter, r *http.Request) {
+	var req WarehouseRequest
+	if err := readJSON(w, r, &req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	wh, err := normalizeWarehouse(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	created, err := warehouseRepo.Create(wh)
+	if err != nil {
+		writeWarehouseError(w, err)
+		return
+	}
+
+	recordAudit(r, "create", "warehouse", created.ID, created)
+	if err := writeJSON(w, http.StatusCreated, created); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// ListWarehousesHandler godoc
+// @Summary List warehouses
+// @Tags warehouses
+// @Security BearerAuth
+// @Produce json
+// @Description Users granted warehouses see only those.
+// @Success 200 {array} models.Warehouse
+// @Failure 500 {string} string "Internal error"
+// @Router /warehouses [get]
+func ListWarehousesHandler(w http.ResponseWriter, r *http.Request) {
+	scope, err := accessScope(r)
+	if err != nil {
+		http.Error(w, "could not load access grants", http.StatusInternalServerError)
+		return
+	}
+	warehouses, err := warehouseRepo.List(scope)
+	if err != nil {
+		http.Error(w, "could not fetch warehouses", http.StatusInternalServerError)
+		return
+	}
+	if err := writeJSON(w, http.StatusOK, warehouses); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// GetWarehouseHandler godoc
+// @Summary Get a warehouse
+// @Tags warehouses
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Warehouse ID"
+// @Success 200 {object} models.Warehouse
+// @Failure 400 {string} string "Invalid ID"
+// @Failure 404 {string} string "Warehouse not found"
+// @Failure 500 {string} string "Internal error"
+// @Router /warehouses/{id} [get]
+func GetWarehouseHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid warehouse ID", http.StatusBadRequest)
+		return
+	}
+	scope, err := accessScope(r)
+	if err != nil {
+		http.Error(w, "could not load access grants", http.StatusInternalServerError)
+		return
+	}
+	if !scope.AllowsWarehouse(id) {
+		writeWarehouseError(w, repo.ErrWarehouseNotFound)
+		return
+	}
+
+	wh, err := warehouseRepo.GetByID(id)
+	if err != nil {
+		writeWarehouseError(w, err)
+		return
+	}
+	if err := writeJSON(w, http.StatusOK, wh); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// UpdateWarehouseHandler godoc
+// @Summary Rename a warehouse or change its address
+// @Tags warehouses
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Warehouse ID"
+// @Param warehouse body WarehouseRequest true "Warehouse"
+// @Success 200 {object} models.Warehouse
+// @Failure 400 {string} string "Invalid input"
+// @Failure 403 {string} string "Admins only"
+// @Failure 404 {string} string "Warehouse not found"
+// @Failure 409 {string} string "Name already in use"
+// @Failure 500 {string} string "Internal error"
+// @Router /warehouses/{id} [put]
+func UpdateWarehouseHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid warehouse ID", http.StatusBadRequest)
+		return
+	}
+	var req WarehouseRequest
+	if err := readJSON(w, r, &req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	wh, err := normalizeWarehouse(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	before, err := warehouseRepo.GetByID(id)
+	if err != nil {
+		writeWarehouseError(w, err)
+		return
+	}
+	wh.ID = id
+	updated, err := warehouseRepo.Update(wh)
+	if err != nil {
+		writeWarehouseError(w, err)
+		return
+	}
+
+	recordAudit(r, "update", "warehouse", id, map[string]any{"before": before, "after": updated})
+	if err := writeJSON(w, http.StatusOK, updated); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// DeleteWarehouseHandler godoc
+// @Summary Delete a warehouse
+// @Description Only an empty warehouse can be deleted; its movements keep their history without it.
+// @Tags warehouses
+// @Security BearerAuth
+// @Param id path int true "Warehouse ID"
+// @Success 204 "No Content"
+// @Failure 400 {string} string "Invalid ID"
+// @Failure 403 {string} string "Admins only"
+// @Failure 404 {string} string "Warehouse not found"
+// @Failure 409 {string} string "Warehouse still holds stock"
+// @Failure 500 {string} string "Internal error"
+// @Router /warehouses/{id} [delete]
+func DeleteWarehouseHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid warehouse ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := warehouseRepo.Delete(id); err != nil {
+		writeWarehouseError(w, err)
+		return
+	}
+
+	recordAudit(r, "delete", "warehouse", id, nil)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeWarehouseError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, repo.ErrWarehouseNotFound):
+		http.Error(w, "warehouse not found", http.StatusNotFound)
+	case errors.Is(err, repo.ErrDuplicatedValueUnique):
+		http.Error(w, "warehouse name already in use", http.StatusConflict)
+	case errors.Is(err, repo.ErrWarehouseNotEmpty):
+		http.Error(w, "warehouse still holds stock", http.StatusConflict)
+	default:
+		http.Error(w, "could not process warehouse", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,312 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+	"github.com/rogerio-castellano/inventory-tracker/internal/webhook"
+)
+
+const (
+	maxWebhookURLLength     = 2048
+	defaultDeliveriesLimit  = 50
+	maxWebhookDeliveryLimit = 200
+)
+
+// CreateWebhookHandler godoc
+// @Summary Subscribe a URL to inventory events
+// @Description Events of the chosen types (product.created, stock.low, movement.created) are POSTed to the URL as JSON with a delivery ID in X-Webhook-Delivery and an HMAC-SHA256 of the body, keyed with the webhook's secret, in X-Signature-256. Failed deliveries are retried with growing delays. The secret is only returned here.
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param webhook body WebhookRequest true "URL and event types"
+// @Success 201 {object} models.Webhook
+// @Failure 400 {string} string "Invalid input"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 500 {string} string "Internal error"
+// @Router /admin/webhooks [post]
+func CreateWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	var req WebhookRequest
+	if err := readJSON(w, r, &req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	hook, err := newWebhook(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	hook.Secret = rand.Text()
+	hook.CreatedBy, _ = GetUsernameFromContext(r)
+	created, err := webhookRepo.Create(hook)
+	if err != nil {
+		http.Error(w, "could not create webhook", http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(r, "create", "webhook", created.ID, map[string]any{"url": created.URL, "events": created.Events})
+	if err := writeJSON(w, http.StatusCreated, created); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// newWebhook validates a subscription request; events are deduplicated in
+// the order given.
+func newWebhook(req WebhookRequest) (models.Webhook, error) {
+	hook := models.Webhook{URL: strings.TrimSpace(req.URL)}
+	if err := validateWebhookURL(hook.URL); err != nil {
+		return models.Webhook{}, err
+	}
+	if len(req.Events) == 0 {
+		return models.Webhook{}, errors.New("at least one event is required")
+	}
+	for _, event := range req.Events {
+		event = strings.TrimSpace(event)
+		if !slices.Contains(webhook.EventTypes, event) {
+			return models.Webhook{}, fmt.Errorf("unknown event %q; events are %s", event, strings.Join(webhook.EventTypes, ", "))
+		}
+		if !slices.Contains(hook.Events, event) {
+			hook.Events = append(hook.Events, event)
+		}
+	}
+	return hook, nil
+}
+
+func validateWebhookURL(raw string) error {
+	if raw == "" {
+		return errors.New("url is required")
+	}
+	if len(raw) > maxWebhookURLLength {
+		return fmt.Errorf("url must be at most %d characters", maxWebhookURLLength)
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("url must be an absolute http or https URL")
+	}
+	return nil
+}
+
+// ListWebhooksHandler godoc
+// @Summary List webhooks
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.Webhook
+// @Failure 403 {string} string "Forbidden"
+// @Failure 500 {string} string "Internal error"
+// @Router /admin/webhooks [get]
+func ListWebhooksHandler(w http.ResponseWriter, r *http.Request) {
+	webhooks, err := webhookRepo.List()
+	if err != nil {
+		http.Error(w, "could not fetch webhooks", http.StatusInternalServerError)
+		return
+	}
+	for i := range webhooks {
+		webhooks[i].Secret = ""
+	}
+	if err := writeJSON(w, http.StatusOK, webhooks); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// DeleteWebhookHandler godoc
+// @Summary Delete a webhook
+// @Description Pending deliveries to it are dropped.
+// @Tags admin
+// @Security BearerAuth
+// @Param id path int true "Webhook ID"
+// @Success 204 "No Content"
+// @Failure 400 {string} string "Invalid ID"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 404 {string} string "Webhook not found"
+// @Failure 500 {string} string "Internal error"
+// @Router /admin/webhooks/{id} [delete]
+func DeleteWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid webhook ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := webhookRepo.Delete(id); err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+
+	recordAudit(r, "delete", "webhook", id, nil)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListWebhookDeliveriesHandler godoc
+// @Summary Delivery status of a webhook
+// @Description Lists the latest deliveries to the webhook, newest first: pending ones with their attempts so far and next attempt, and delivered or failed ones with the last response.
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Param status query string false "pending, delivered or failed"
+// @Param limit query int false "Maximum deliveries (default 50, max 200)"
+// @Success 200 {array} models.WebhookDelivery
+// @Failure 400 {string} string "Invalid input"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 404 {string} string "Webhook not found"
+// @Failure 500 {string} string "Internal error"
+// @Router /admin/webhooks/{id}/deliveries [get]
+func ListWebhookDeliveriesHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid webhook ID", http.StatusBadRequest)
+		return
+	}
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", models.WebhookDeliveryPending, models.WebhookDeliveryDelivered, models.WebhookDeliveryFailed:
+	default:
+		http.Error(w, "status must be pending, delivered or failed", http.StatusBadRequest)
+		return
+	}
+	limit := defaultDeliveriesLimit
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		limit = min(n, maxWebhookDeliveryLimit)
+	}
+
+	if _, err := webhookRepo.GetByID(id); err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+	deliveries, err := webhookRepo.Deliveries(id, status, limit)
+	if err != nil {
+		http.Error(w, "could not fetch deliveries", http.StatusInternalServerError)
+		return
+	}
+	if err := writeJSON(w, http.StatusOK, deliveries); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// TestWebhookHandler godoc
+// @Summary Send a test event to a webhook
+// @Description POSTs a webhook.test event, signed like any other, and returns the delivery with the receiver's answer. A failed test stays pending and is retried like other deliveries.
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Success 200 {object} models.WebhookDelivery
+// @Failure 400 {string} string "Invalid ID"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 404 {string} string "Webhook not found"
+// @Failure 500 {string} string "Internal error"
+// @Router /admin/webhooks/{id}/test [post]
+func TestWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid webhook ID", http.StatusBadRequest)
+		return
+	}
+
+	hook, err := webhookRepo.GetByID(id)
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+	delivery, err := webhook.Test(webhookRepo, hook)
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+
+	recordAudit(r, "test", "webhook", id, map[string]any{"delivery": delivery.ID, "status": delivery.Status})
+	if err := writeJSON(w, http.StatusOK, delivery); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// RedeliverWebhookHandler godoc
+// @Summary Redeliver a recorded delivery
+// @Description Sends the event of a delivered or failed delivery again, as a new delivery with the same event ID in X-Webhook-Delivery, and returns it with the receiver's answer.
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Webhook ID"
+// @Param deliveryId path int true "Delivery ID"
+// @Success 200 {object} models.WebhookDelivery
+// @Failure 400 {string} string "Invalid ID"
+// @Failure 403 {string} string "Forbidden"
+// @Failure 404 {string} string "Webhook or delivery not found"
+// @Failure 409 {string} string "Delivery still pending"
+// @Failure 500 {string} string "Internal error"
+// @Router /admin/webhooks/{id}/deliveries/{deliveryId}/redeliver [post]
+func RedeliverWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid webhook ID", http.StatusBadRequest)
+		return
+	}
+	deliveryID, err := parseID(chi.URLParam(r, "deliveryId"))
+	if err != nil {
+		http.Error(w, "invalid delivery ID", http.StatusBadRequest)
+		return
+	}
+
+	hook, err := webhookRepo.GetByID(id)
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+	original, err := webhookRepo.Delivery(id, deliveryID)
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+	if original.Status == models.WebhookDeliveryPending {
+		http.Error(w, "delivery is still pending; it will be retried", http.StatusConflict)
+		return
+	}
+	delivery, err := webhook.Redeliver(webhookRepo, hook, original)
+	if err != nil {
+		writeWebhookError(w, err)
+		return
+	}
+
+	recordAudit(r, "redeliver", "webhook", id, map[string]any{"original": original.ID, "delivery": delivery.ID, "status": delivery.Status})
+	if err := writeJSON(w, http.StatusOK, delivery); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// publishStockLevel raises stock.low when adding delta took the product
+// below its threshold, so a product that stays low is reported once.
+func publishStockLevel(p models.Product, delta int) {
+	if p.Quantity < p.Threshold && p.Quantity-delta >= p.Threshold {
+		webhook.Publish(webhook.EventStockLow, StockLowEvent{ProductID: p.ID, Name: p.Name, Quantity: p.Quantity, Threshold: p.Threshold})
+	}
+}
+
+func writeWebhookError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, repo.ErrWebhookNotFound):
+		http.Error(w, "webhook not found", http.StatusNotFound)
+	case errors.Is(err, repo.ErrWebhookDeliveryNotFound):
+		http.Error(w, "delivery not found", http.StatusNotFound)
+	default:
+		log.Printf("webhook: %v", err)
+		http.Error(w, "could not process webhook", http.StatusInternalServerError)
+	}
+}
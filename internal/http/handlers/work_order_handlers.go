@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+)
+
+// CreateWorkOrderHandler godoc
+// @Summary Create a kitting/assembly work order
+// @Description Stock is not touched until the work order is completed.
+// @Tags work-orders
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param workOrder body WorkOrderRequest true "Finished good, quantity and components per unit"
+// @Success 201 {object} models.WorkOrder
+// @Failure 400 {object} ErrorResponse "Invalid input"
+// @Failure 404 {object} ErrorResponse "Product not found"
+// @Failure 500 {object} ErrorResponse "Internal error"
+// @Router /work-orders [post]
+func CreateWorkOrderHandler(w http.ResponseWriter, r *http.Request) {
+	var req WorkOrderRequest
+	if err := readJSON(w, r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidInput, "invalid input")
+		return
+	}
+	if req.Quantity <= 0 {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidInput, "quantity must be positive")
+		return
+	}
+	if len(req.Components) == 0 {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidInput, "at least one component is required")
+		return
+	}
+
+	seen := map[int]bool{req.ProductID: true}
+	for _, c := range req.Components {
+		if c.QuantityPerUnit <= 0 {
+			writeError(w, http.StatusBadRequest, ErrCodeInvalidInput, "quantity_per_unit must be positive")
+			return
+		}
+		if seen[c.ProductID] {
+			writeError(w, http.StatusBadRequest, ErrCodeInvalidInput, fmt.Sprintf("product %d is listed more than once", c.ProductID))
+			return
+		}
+		seen[c.ProductID] = true
+	}
+	scope, err := accessScope(r)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "could not load access grants")
+		return
+	}
+	for id := range seen {
+		p, err := productRepo.GetByID(id)
+		if err == nil && !scope.AllowsCategory(p.Category) {
+			err = repo.ErrProductNotFound
+		}
+		if err != nil {
+			if errors.Is(err, repo.ErrProductNotFound) {
+				writeError(w, http.StatusNotFound, ErrCodeNotFound, fmt.Sprintf("product %d not found", id))
+				return
+			}
+			writeError(w, http.StatusInternalServerError, ErrCodeInternal, "could not fetch products")
+			return
+		}
+	}
+
+	username, err := GetUsernameFromContext(r)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "Internal error")
+		return
+	}
+
+	wo, err := workOrderRepo.Create(models.WorkOrder{
+		ProductID:  req.ProductID,
+		Quantity:   req.Quantity,
+		Components: req.Components,
+		Note:       strings.TrimSpace(req.Note),
+		CreatedBy:  username,
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "could not save work order")
+		return
+	}
+
+	recordAudit(r, "create", "work_order", wo.ID, wo)
+	if err := writeJSON(w, http.StatusCreated, wo); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// GetWorkOrderHandler godoc
+// @Summary Get a work order
+// @Tags work-orders
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Work order ID"
+// @Success 200 {object} models.WorkOrder
+// @Failure 400 {object} ErrorResponse "Invalid input"
+// @Failure 404 {object} ErrorResponse "Work order not found"
+// @Failure 500 {object} ErrorResponse "Internal error"
+// @Router /work-orders/{id} [get]
+func GetWorkOrderHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidInput, "invalid work order ID")
+		return
+	}
+
+	wo, err := workOrderRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, repo.ErrWorkOrderNotFound) {
+			writeError(w, http.StatusNotFound, ErrCodeNotFound, "work order not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "could not fetch work order")
+		return
+	}
+	if !workOrderAccessible(w, r, wo, false) {
+		return
+	}
+	if err := writeJSON(w, http.StatusOK, wo); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// CompleteWorkOrderHandler godoc
+// @Summary Complete a work order
+// @Description Consumes the components and produces the finished good in one transaction. Every movement posted is tagged with the work order ID.
+// @Tags work-orders
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "Work order ID"
+// @Success 200 {object} models.WorkOrder
+// @Failure 400 {object} ErrorResponse "Invalid input"
+// @Failure 403 {object} ErrorResponse "Caller restricted to warehouses"
+// @Failure 404 {object} ErrorResponse "Work order not found"
+// @Failure 409 {object} ShortageResponse "Component shortage, work order not open or period closed"
+// @Failure 500 {object} ErrorResponse "Internal error"
+// @Router /work-orders/{id}/complete [post]
+func CompleteWorkOrderHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidInput, "invalid work order ID")
+		return
+	}
+
+	pending, err := workOrderRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, repo.ErrWorkOrderNotFound) {
+			writeError(w, http.StatusNotFound, ErrCodeNotFound, "work order not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "could not fetch work order")
+		return
+	}
+	if !workOrderAccessible(w, r, pending, true) {
+		return
+	}
+
+	if err := ensurePeriodOpen(time.Now().UTC()); err != nil {
+		if errors.Is(err, errPeriodClosed) {
+			writeError(w, http.StatusConflict, ErrCodeConflict, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "could not verify accounting period")
+		return
+	}
+
+	wo, err := workOrderRepo.Complete(id)
+	if err != nil {
+		var shortage *repo.ShortageError
+		switch {
+		case errors.As(err, &shortage):
+			resp := ShortageResponse{
+				ErrorResponse: ErrorResponse{Code: ErrCodeShortage, Message: "not enough component stock to complete the work order"},
+				Shortages:     make([]ShortageItem, len(shortage.Shortages)),
+			}
+			for i, s := range shortage.Shortages {
+				resp.Shortages[i].Shortage = s
+				if resp.Shortages[i].Substitute, err = inStockSubstitute(s.ProductID, s.Required); err != nil {
+					log.Printf("failed to look up substitutes of product %d: %v", s.ProductID, err)
+				}
+			}
+			if err := writeJSON(w, http.StatusConflict, resp); err != nil {
+				log.Printf("Failed to write JSON response: %v", err)
+			}
+		case errors.Is(err, repo.ErrWorkOrderNotFound):
+			writeError(w, http.StatusNotFound, ErrCodeNotFound, "work order not found")
+		case errors.Is(err, repo.ErrWorkOrderNotOpen):
+			writeError(w, http.StatusConflict, ErrCodeConflict, "work order is already completed")
+		default:
+			log.Printf("failed to complete work order %d: %v", id, err)
+			writeError(w, http.StatusInternalServerError, ErrCodeInternal, "could not complete work order")
+		}
+		return
+	}
+
+	recordAudit(r, "complete", "work_order", wo.ID, wo)
+	if err := writeJSON(w, http.StatusOK, wo); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// workOrderAccessible checks that every product of the work order is within
+// the caller's grants, answering 404 if not, as for a missing work order.
+// Completing moves stock, so it also refuses callers restricted to
+// warehouses: a work order is not tied to one.
+func workOrderAccessible(w http.ResponseWriter, r *http.Request, wo models.WorkOrder, completing bool) bool {
+	scope, err := accessScope(r)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "could not load access grants")
+		return false
+	}
+	ids := []int{wo.ProductID}
+	for _, c := range wo.Components {
+		ids = append(ids, c.ProductID)
+	}
+	for _, id := range ids {
+		if err := productInScope(scope, id); err != nil {
+			if errors.Is(err, repo.ErrProductNotFound) {
+				writeError(w, http.StatusNotFound, ErrCodeNotFound, "work order not found")
+				return false
+			}
+			writeError(w, http.StatusInternalServerError, ErrCodeInternal, "could not fetch products")
+			return false
+		}
+	}
+	if completing && !warehouseInScope(scope, nil) {
+		writeError(w, http.StatusForbidden, ErrCodeOutOfScope, "warehouse outside your access grants: work orders change stock in every warehouse")
+		return false
+	}
+	return true
+}
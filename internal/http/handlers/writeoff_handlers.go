@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+)
+
+// defaultWasteWindow is how far back the waste report looks without ?since.
+const defaultWasteWindow = 30 * 24 * time.Hour
+
+// WriteOffHandler godoc
+// @Summary Write off damaged, expired or lost stock
+// @Description Removes the quantity from stock like a negative adjustment and records the reason and cost as waste.
+// @Tags inventory
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "Product ID"
+// @Param writeOff body WriteOffRequest true "Write-off"
+// @Success 201 {object} models.WriteOff
+// @Failure 400 {string} string "Invalid input"
+// @Failure 403 {string} string "Caller restricted to warehouses"
+// @Failure 404 {string} string "Product not found"
+// @Failure 409 {string} string "Not enough stock or period closed"
+// @Failure 500 {string} string "Internal error"
+// @Router /products/{id}/write-off [post]
+func WriteOffHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := parseID(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "invalid product ID", http.StatusBadRequest)
+		return
+	}
+
+	var req WriteOffRequest
+	if err := readJSON(w, r, &req); err != nil {
+		http.Error(w, "invalid input", http.StatusBadRequest)
+		return
+	}
+	req.Reason = strings.ToLower(strings.TrimSpace(req.Reason))
+	if req.Quantity <= 0 {
+		http.Error(w, "quantity must be positive", http.StatusBadRequest)
+		return
+	}
+	if !models.ValidWriteOffReason(req.Reason) {
+		http.Error(w, "reason must be one of expired, damaged, spoiled, lost, theft or other", http.StatusBadRequest)
+		return
+	}
+	if req.UnitCost != nil && *req.UnitCost < 0 {
+		http.Error(w, "unit_cost cannot be negative", http.StatusBadRequest)
+		return
+	}
+
+	occurredAt := time.Now().UTC()
+	if req.OccurredAt != "" {
+		t, err := parseTime(req.OccurredAt)
+		if err != nil {
+			http.Error(w, "invalid occurred_at date format", http.StatusBadRequest)
+			return
+		}
+		if t.After(time.Now()) {
+			http.Error(w, "occurred_at cannot be in the future", http.StatusBadRequest)
+			return
+		}
+		occurredAt = t.UTC()
+	}
+
+	if !adjustmentInScope(w, r, id, nil) {
+		return
+	}
+	product, err := productRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, repo.ErrProductNotFound) {
+			http.Error(w, "product not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "could not fetch product", http.StatusInternalServerError)
+		return
+	}
+
+	if err := ensurePeriodOpen(occurredAt); err != nil {
+		if errors.Is(err, errPeriodClosed) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		http.Error(w, "could not verify accounting period", http.StatusInternalServerError)
+		return
+	}
+
+	username, err := GetUsernameFromContext(r)
+	if err != nil {
+		http.Error(w, "Internal error", http.StatusInternalServerError)
+		return
+	}
+
+	if _, err := productRepo.AdjustQuantity(id, -req.Quantity); err != nil {
+		if errors.Is(err, repo.ErrInvalidQuantityChange) {
+			http.Error(w, "cannot write off more than the quantity in stock", http.StatusConflict)
+			return
+		}
+		http.Error(w, "could not update quantity", http.StatusInternalServerError)
+		return
+	}
+	movement, err := movementRepo.Log(models.Movement{ProductID: id, Delta: -req.Quantity, CreatedAt: occurredAt.Format(time.RFC3339)})
+	recordMovementLog(id, -req.Quantity, err)
+	var movementID *int
+	if err == nil {
+		movementID = &movement.ID
+	}
+
+	unitCost := product.Price
+	if req.UnitCost != nil {
+		unitCost = *req.UnitCost
+	}
+	writeOff, err := writeOffRepo.Create(models.WriteOff{
+		ProductID:  id,
+		Category:   product.Category,
+		Quantity:   req.Quantity,
+		Reason:     req.Reason,
+		UnitCost:   unitCost,
+		TotalCost:  math.Round(unitCost*float64(req.Quantity)*100) / 100,
+		Note:       req.Note,
+		CreatedBy:  username,
+		CreatedAt:  occurredAt,
+		MovementID: movementID,
+	})
+	if err != nil {
+		// The stock is already gone; losing the waste record only skews reports.
+		log.Printf("failed to record write-off of %d units of product %d: %v", req.Quantity, id, err)
+		http.Error(w, "stock removed but the write-off could not be recorded", http.StatusInternalServerError)
+		return
+	}
+
+	recordAudit(r, "write-off", "product", id, writeOff)
+	if err := writeJSON(w, http.StatusCreated, writeOff); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+// WasteReportHandler godoc
+// @Summary Written-off stock aggregated by reason and category
+// @Tags reports
+// @Security BearerAuth
+// @Produce json
+// @Param since query string false "Start date (YYYY-MM-DD or RFC3339, default 30 days ago)"
+// @Success 200 {object} WasteReport
+// @Failure 400 {string} string "Invalid input"
+// @Failure 500 {string} string "Internal error"
+// @Router /reports/waste [get]
+func WasteReportHandler(w http.ResponseWriter, r *http.Request) {
+	since := time.Now().UTC().Add(-defaultWasteWindow)
+	if raw := r.URL.Query().Get("since"); raw != "" {
+		t, err := parseDate(raw)
+		if err != nil {
+			http.Error(w, "since must be a date (YYYY-MM-DD or RFC3339)", http.StatusBadRequest)
+			return
+		}
+		since = t
+	}
+
+	totals, err := writeOffRepo.TotalsSince(since)
+	if err != nil {
+		http.Error(w, "could not fetch write-offs", http.StatusInternalServerError)
+		return
+	}
+
+	report := WasteReport{Since: since.Format(time.RFC3339)}
+	byReason := map[string]*WasteGroup{}
+	byCategory := map[string]*WasteGroup{}
+	for _, t := range totals {
+		report.Quantity += t.Quantity
+		report.WrittenOffValue += t.Value
+		addWaste(byReason, t.Reason, t)
+		addWaste(byCategory, t.Category, t)
+	}
+	report.WrittenOffValue = math.Round(report.WrittenOffValue*100) / 100
+	report.ByReason = sortedWaste(byReason)
+	report.ByCategory = sortedWaste(byCategory)
+
+	if err := writeJSON(w, http.StatusOK, report); err != nil {
+		log.Printf("Failed to write JSON response: %v", err)
+	}
+}
+
+func addWaste(groups map[string]*WasteGroup, name string, t repo.WasteTotal) {
+	g, ok := groups[name]
+	if !ok {
+		g = &WasteGroup{Name: name}
+		groups[name] = g
+	}
+	g.Quantity += t.Quantity
+	g.WrittenOffValue += t.Value
+}
+
+// sortedWaste lists the groups by written-off value, largest first.
+func sortedWaste(groups map[string]*WasteGroup) []WasteGroup {
+	out := make([]WasteGroup, 0, len(groups))
+	for _, g := range groups {
+		g.WrittenOffValue = math.Round(g.WrittenOffValue*100) / 100
+		out = append(out, *g)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].WrittenOffValue != out[j].WrittenOffValue {
+			return out[i].WrittenOffValue > out[j].WrittenOffValue
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
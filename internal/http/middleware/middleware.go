@@ -1,25 +1,35 @@
 package middleware
 
 import (
+	"bytes"
 	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net"
 	"net/http"
 	"slices"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/redis/go-redis/v9"
 	"github.com/rogerio-castellano/inventory-tracker/internal/auth"
 	"github.com/rogerio-castellano/inventory-tracker/internal/http/ban"
 	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
 	rl "github.com/rogerio-castellano/inventory-tracker/internal/http/rate_limiter"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
 	"github.com/rogerio-castellano/inventory-tracker/internal/redissvc"
+	"github.com/rogerio-castellano/inventory-tracker/internal/security"
+	"github.com/rogerio-castellano/inventory-tracker/internal/usage"
 )
 
 var (
-	rdb *redis.Client
+	rdb redis.UniversalClient
 	ctx context.Context
 )
 
@@ -32,6 +42,35 @@ type contextKey string
 
 const userIDKey = contextKey("user_id")
 
+// RequestIDHeader carries the request ID in both directions.
+const RequestIDHeader = "X-Request-ID"
+
+// RequestID tags every request with an ID, echoed in the response and recorded
+// with the audit entries the request writes. A well-formed ID sent by the
+// client or a proxy in front of the API is kept so logs can be correlated.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = rand.Text()
+		}
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(w, handlers.WithRequestID(r, id))
+	})
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 64 {
+		return false
+	}
+	for _, c := range id {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == '.') {
+			return false
+		}
+	}
+	return true
+}
+
 func AuthMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		authorization := r.Header.Get("Authorization")
@@ -40,16 +79,25 @@ func AuthMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
-		token, claims, err := auth.TokenClaims(authorization)
-		if err != nil || !token.Valid {
+		claims, err := auth.VerifyToken(ctx, rdb, authorization)
+		if err != nil {
+			if errors.Is(err, auth.ErrTokenRevoked) {
+				http.Error(w, "token revoked", http.StatusUnauthorized)
+				return
+			}
 			http.Error(w, "invalid token", http.StatusUnauthorized)
 			return
 		}
 
+		if scopes, ok := auth.TokenScopes(claims); ok && !auth.ScopeAllows(scopes, r.Method, r.URL.Path) {
+			http.Error(w, "Forbidden: token scope does not cover this endpoint", http.StatusForbidden)
+			return
+		}
+
 		userID := int(claims["sub"].(float64))
 
 		ctx := context.WithValue(r.Context(), userIDKey, userID)
-		next.ServeHTTP(w, r.WithContext(ctx))
+		next.ServeHTTP(w, handlers.WithVerifiedClaims(r.WithContext(ctx), claims))
 	})
 }
 
@@ -86,6 +134,9 @@ func RequireRoles(allowedRoles ...string) func(http.Handler) http.Handler {
 	}
 }
 
+// registrationLimiter allows 1 request/sec with a burst of 3 per IP.
+var registrationLimiter = rl.NewBucketLimiter(1, 3)
+
 func RateLimitMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		host, _, err := net.SplitHostPort(r.RemoteAddr)
@@ -94,8 +145,9 @@ func RateLimitMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
-		limiter := rl.GetVisitor(host)
-		if !limiter.Allow() {
+		d, _ := registrationLimiter.Allow(host)
+		writeRateLimitHeaders(w, d)
+		if !d.Allowed {
 			http.Error(w, "Too many requests", http.StatusTooManyRequests)
 			return
 		}
@@ -103,6 +155,39 @@ func RateLimitMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// writeRateLimitHeaders sets the headers every rate-limited route answers
+// with, whether the request is allowed or not:
+//
+//	X-RateLimit-Limit      requests allowed per window
+//	X-RateLimit-Remaining  requests left in the current window
+//	X-RateLimit-Reset      seconds until the window resets
+//	Retry-After            seconds to wait, on 429 responses only
+func writeRateLimitHeaders(w http.ResponseWriter, d rl.Decision) {
+	w.Header().Set("X-RateLimit-Limit", strconv.Itoa(d.Limit))
+	w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(d.Remaining))
+	w.Header().Set("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(d.Reset)))
+	if !d.Allowed {
+		w.Header().Set("Retry-After", strconv.Itoa(max(ceilSeconds(d.RetryAfter), 1)))
+	}
+}
+
+// ceilSeconds rounds up, so that a client waiting the advertised time is
+// never early.
+func ceilSeconds(d time.Duration) int {
+	return int((d + time.Second - 1) / time.Second)
+}
+
+// limiterUnavailable serves a request whose limiter cannot reach Redis:
+// unlimited when failing open, refused when failing closed.
+func limiterUnavailable(w http.ResponseWriter, r *http.Request, next http.Handler) {
+	if rl.FailMode() == rl.FailOpen {
+		next.ServeHTTP(w, r)
+		return
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(rl.BreakerCooldown)))
+	http.Error(w, "Rate limiter unavailable", http.StatusServiceUnavailable)
+}
+
 func RedisRateLimitMiddleware(route string, maxRequests int, window time.Duration) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -112,38 +197,18 @@ func RedisRateLimitMiddleware(route string, maxRequests int, window time.Duratio
 				return
 			}
 
-			pipe := rdb.TxPipeline()
-			countCmd := pipe.Incr(ctx, key)
-			ttlCmd := pipe.TTL(ctx, key)
-			_, err = pipe.Exec(ctx)
+			d, err := rl.WithBreaker(route, rl.NewWindowLimiter(rdb, ctx, maxRequests, window)).Allow(key)
 			if err != nil {
-				http.Error(w, "Rate limit error", http.StatusInternalServerError)
+				limiterUnavailable(w, r, next)
 				return
 			}
+			writeRateLimitHeaders(w, d)
 
-			count := countCmd.Val()
-			ttl := ttlCmd.Val()
-
-			// Set expiration if it's a new key
-			if count == 1 || ttl < 0 {
-				rdb.Expire(ctx, key, window)
-				ttl = window
-			}
-
-			remaining := max(maxRequests-int(count), 0)
-
-			// Set rate limit headers
-			w.Header().Set("X-RateLimit-Limit", fmt.Sprintf("%d", maxRequests))
-			w.Header().Set("X-RateLimit-Remaining", fmt.Sprintf("%d", remaining))
-			w.Header().Set("X-RateLimit-Reset", fmt.Sprintf("%d", int(ttl.Seconds())))
-
-			// If over limit
-			if count > int64(maxRequests) {
+			if !d.Allowed {
 				if err := recordRateLimitStrike(key, route, r); err != nil {
 					http.Error(w, "Rate limit error", http.StatusInternalServerError)
 					return
 				}
-				w.Header().Set("Retry-After", fmt.Sprintf("%d", int(ttl.Seconds())))
 				http.Error(w, "Too many requests", http.StatusTooManyRequests)
 				return
 			}
@@ -163,19 +228,17 @@ func RedisRateLimitPerRole(route string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			authorization := r.Header.Get("Authorization")
+			// Revoked or bogus tokens are limited as guests.
 			role := "guest"
 			if authorization != "" {
-				_, claims, err := auth.TokenClaims(authorization)
-				if err != nil {
-					http.Error(w, "Rate limit error", http.StatusInternalServerError)
-					return
-				}
-				if rRole, ok := claims["role"].(string); ok {
-					role = rRole
+				if claims, err := auth.VerifyToken(ctx, rdb, authorization); err == nil {
+					if rRole, ok := claims["role"].(string); ok {
+						role = rRole
+					}
 				}
 			}
 
-			cfg := getRateLimitConfigForRole(role)
+			cfg := rl.LimitForRole(role)
 
 			key, err := getClientIdentifier(r)
 			if err != nil {
@@ -188,31 +251,23 @@ func RedisRateLimitPerRole(route string) func(http.Handler) http.Handler {
 			banTTL, err := rdb.TTL(ctx, banKey).Result()
 
 			if err == nil && banTTL > 0 {
-				w.Header().Set("Retry-After", fmt.Sprintf("%d", int(banTTL.Seconds())))
+				writeRateLimitHeaders(w, rl.Decision{Limit: cfg.MaxRequests, Reset: banTTL, RetryAfter: banTTL})
 				http.Error(w, "Too many requests — temporarily banned", http.StatusTooManyRequests)
 				return
 			}
 
-			count, ttl, err := incrementWithTTL(redisKey, cfg.Window)
+			d, err := rl.WithBreaker(route, rl.NewWindowLimiter(rdb, ctx, cfg.MaxRequests, cfg.Window)).Allow(redisKey)
 			if err != nil {
-				http.Error(w, "Rate limit error", http.StatusInternalServerError)
+				limiterUnavailable(w, r, next)
 				return
 			}
+			writeRateLimitHeaders(w, d)
 
-			remaining := max(cfg.MaxRequests-int(count), 0)
-
-			// Headers
-			w.Header().Set("X-RateLimit-Limit", fmt.Sprintf("%d", cfg.MaxRequests))
-			w.Header().Set("X-RateLimit-Remaining", fmt.Sprintf("%d", remaining))
-			w.Header().Set("X-RateLimit-Reset", fmt.Sprintf("%d", int(ttl.Seconds())))
-
-			if count > int64(cfg.MaxRequests) {
+			if !d.Allowed {
 				if err := recordRateLimitStrike(redisKey, route, r); err != nil {
 					http.Error(w, "Rate limit error", http.StatusInternalServerError)
 					return
 				}
-
-				w.Header().Set("Retry-After", fmt.Sprintf("%d", int(ttl.Seconds())))
 				http.Error(w, "Too many requests", http.StatusTooManyRequests)
 				return
 			}
@@ -237,6 +292,14 @@ func recordRateLimitStrike(key, route string, r *http.Request) error {
 			banKey := fmt.Sprintf("ratelimit:ban:%s", key)
 			_ = rdb.Set(ctx, banKey, "1", banDuration).Err()
 			log.Printf("🚫 BANNED: %s for %v due to %d+ strikes", banKey, banDuration, strikes)
+			host, _, _ := net.SplitHostPort(r.RemoteAddr)
+			security.Publish(security.Event{
+				Type:     security.EventBan,
+				Severity: security.SeverityCritical,
+				Target:   key,
+				SourceIP: host,
+				Details:  map[string]any{"route": route, "strikes": strikes, "duration_seconds": int(banDuration.Seconds())},
+			})
 			if err := ban.SendBanAlertEmail(key, route, int(strikes), r); err != nil { // 📨 trigger alert
 				return fmt.Errorf("failed to send ban alert email: %w", err)
 			}
@@ -267,33 +330,16 @@ func getRateLimitKey(r *http.Request, route string) (string, error) {
 	return fmt.Sprintf("ratelimit:%s:%s", route, host), nil
 }
 
-type RateLimitConfig struct {
-	MaxRequests int
-	Window      time.Duration
-}
-
-func getRateLimitConfigForRole(role string) RateLimitConfig {
-	switch role {
-	case "admin":
-		return RateLimitConfig{MaxRequests: 20, Window: time.Minute}
-	case "user":
-		return RateLimitConfig{MaxRequests: 10, Window: time.Minute}
-	default:
-		return RateLimitConfig{MaxRequests: 3, Window: time.Minute} // guests or unknown
-	}
-}
-
 func getClientIdentifier(r *http.Request) (string, error) {
 	authorization := r.Header.Get("Authorization")
 
+	// Tokens that do not verify are counted against the address they
+	// come from.
 	if strings.HasPrefix(authorization, "Bearer ") {
-		_, claims, err := auth.TokenClaims(authorization)
-		if err != nil {
-			return "", err
-		}
-
-		if username, ok := claims["username"].(string); ok {
-			return username, nil
+		if claims, err := auth.VerifyToken(ctx, rdb, authorization); err == nil {
+			if username, ok := claims["username"].(string); ok {
+				return username, nil
+			}
 		}
 	}
 	host, _, err := net.SplitHostPort(r.RemoteAddr)
@@ -304,20 +350,124 @@ func getClientIdentifier(r *http.Request) (string, error) {
 	return host, nil
 }
 
-func incrementWithTTL(key string, window time.Duration) (int64, time.Duration, error) {
-	pipe := rdb.TxPipeline()
-	countCmd := pipe.Incr(ctx, key)
-	ttlCmd := pipe.TTL(ctx, key)
-	_, err := pipe.Exec(ctx)
-	if err != nil {
-		return 0, 0, err
-	}
+// UsageTracking counts requests and payload sizes per authenticated user.
+// Anonymous traffic is not tracked; rate limiting already covers it.
+func UsageTracking(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username := ""
+		if strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+			username, _ = handlers.GetUsernameFromContext(r)
+		}
+		if username == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
 
-	ttl := ttlCmd.Val()
-	if countCmd.Val() == 1 || ttl < 0 {
-		rdb.Expire(ctx, key, window)
-		ttl = window
-	}
+		cw := &countingResponseWriter{ResponseWriter: w}
+		next.ServeHTTP(cw, r)
+
+		bytesIn := max(r.ContentLength, 0)
+		if err := usage.Record(username, bytesIn, cw.written); err != nil {
+			log.Printf("failed to record usage for %s: %v", username, err)
+		}
+
+		route := r.URL.Path
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+			route = rctx.RoutePattern()
+		}
+		host, _, _ := net.SplitHostPort(r.RemoteAddr)
+		activity := models.Activity{Username: username, At: time.Now(), Request: r.Method + " " + route, IPAddress: host, UserAgent: r.UserAgent()}
+		if err := usage.Touch(activity); err != nil {
+			log.Printf("failed to record activity for %s: %v", username, err)
+		}
+		if idle := auth.SessionIdleTimeout(); idle > 0 {
+			if err := usage.TouchSession(username, auth.SessionKey(host, r.UserAgent()), idle); err != nil {
+				log.Printf("failed to record session activity for %s: %v", username, err)
+			}
+		}
+	})
+}
 
-	return countCmd.Val(), ttl, nil
+type countingResponseWriter struct {
+	http.ResponseWriter
+	written int64
+}
+
+func (c *countingResponseWriter) Write(p []byte) (int, error) {
+	n, err := c.ResponseWriter.Write(p)
+	c.written += int64(n)
+	return n, err
+}
+
+// maxSignedBodyBytes bounds how much of a signed request is buffered to check its signature.
+const maxSignedBodyBytes = 32 << 20
+
+// SignedRequests lets partners authenticate with an HMAC signature instead of
+// a JWT. Requests carrying X-Signature must also send X-Partner-Id and
+// X-Timestamp (Unix seconds); once verified against the partner's secret,
+// scopes and the replay window, they continue with a short-lived partner
+// token so the rest of the chain treats them like any authenticated call.
+// Requests without X-Signature pass through untouched.
+func SignedRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signature := r.Header.Get("X-Signature")
+		if signature == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		timestamp, err := strconv.ParseInt(r.Header.Get("X-Timestamp"), 10, 64)
+		if err != nil {
+			http.Error(w, "invalid X-Timestamp", http.StatusUnauthorized)
+			return
+		}
+		if skew := time.Since(time.Unix(timestamp, 0)); skew > auth.SignatureWindow || skew < -auth.SignatureWindow {
+			http.Error(w, "request timestamp outside the allowed window", http.StatusUnauthorized)
+			return
+		}
+
+		raw, err := rdb.HGet(ctx, auth.PartnersKey, r.Header.Get("X-Partner-Id")).Result()
+		if err != nil {
+			http.Error(w, "invalid signature", http.StatusUnauthorized)
+			return
+		}
+		var partner auth.Partner
+		if err := json.Unmarshal([]byte(raw), &partner); err != nil {
+			http.Error(w, "invalid signature", http.StatusUnauthorized)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxSignedBodyBytes))
+		if err != nil {
+			http.Error(w, "could not read request body", http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		if !auth.VerifySignature(partner.Secret, signature, timestamp, r.Method, r.URL.RequestURI(), body) {
+			http.Error(w, "invalid signature", http.StatusUnauthorized)
+			return
+		}
+		if !auth.ScopeAllows(partner.Scopes, r.Method, r.URL.Path) {
+			http.Error(w, "Forbidden: partner scope does not cover this endpoint", http.StatusForbidden)
+			return
+		}
+
+		fresh, err := rdb.SetNX(ctx, "hmac_seen:"+signature, "1", 2*auth.SignatureWindow).Result()
+		if err != nil {
+			http.Error(w, "could not verify signature", http.StatusInternalServerError)
+			return
+		}
+		if !fresh {
+			http.Error(w, "request already processed", http.StatusUnauthorized)
+			return
+		}
+
+		token, err := auth.GeneratePartnerToken(partner)
+		if err != nil {
+			http.Error(w, "could not authenticate partner", http.StatusInternalServerError)
+			return
+		}
+		r.Header.Set("Authorization", "Bearer "+token)
+		next.ServeHTTP(w, r)
+	})
 }
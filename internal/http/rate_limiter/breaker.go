@@ -0,0 +1,139 @@
+package rate_limiter
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	// breakerThreshold consecutive Redis failures open a route's breaker.
+	breakerThreshold = 5
+	// BreakerCooldown is how long an open breaker skips Redis before letting
+	// one request through to probe it again.
+	BreakerCooldown = 30 * time.Second
+)
+
+const (
+	FailOpen   = "open"   // let requests through unlimited while Redis is down
+	FailClosed = "closed" // reject them with 503
+)
+
+// ErrLimiterUnavailable is returned while a route's breaker is open.
+var ErrLimiterUnavailable = errors.New("rate limiter unavailable")
+
+var (
+	failMode = FailOpen
+
+	breakersMu sync.Mutex
+	breakers   = map[string]*breaker{}
+)
+
+// SetFailMode chooses what rate-limited routes do when their limiter cannot
+// reach Redis: FailOpen or FailClosed.
+func SetFailMode(mode string) error {
+	if mode != FailOpen && mode != FailClosed {
+		return fmt.Errorf("rate limit fail mode must be %q or %q, got %q", FailOpen, FailClosed, mode)
+	}
+	failMode = mode
+	return nil
+}
+
+func FailMode() string { return failMode }
+
+// breaker counts consecutive failures of one route's limiter.
+type breaker struct {
+	route     string
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+}
+
+// WithBreaker guards l with the circuit breaker of route, so that a Redis
+// outage costs each route a few failed calls rather than one per request.
+func WithBreaker(route string, l Limiter) Limiter {
+	breakersMu.Lock()
+	defer breakersMu.Unlock()
+	b, ok := breakers[route]
+	if !ok {
+		b = &breaker{route: route}
+		breakers[route] = b
+	}
+	return breakerLimiter{b: b, next: l}
+}
+
+type breakerLimiter struct {
+	b    *breaker
+	next Limiter
+}
+
+func (l breakerLimiter) Allow(key string) (Decision, error) {
+	if !l.b.ready() {
+		return Decision{}, ErrLimiterUnavailable
+	}
+	d, err := l.next.Allow(key)
+	l.b.record(err)
+	return d, err
+}
+
+// ready reports whether a call may be attempted. Once the cooldown of an
+// open breaker is over, a single probe is let through per cooldown.
+func (b *breaker) ready() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures < breakerThreshold {
+		return true
+	}
+	if now := time.Now(); now.After(b.openUntil) {
+		b.openUntil = now.Add(BreakerCooldown)
+		return true
+	}
+	return false
+}
+
+func (b *breaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err == nil {
+		if b.failures >= breakerThreshold {
+			log.Printf("✅ rate limiter for %s recovered, Redis is reachable again", b.route)
+		}
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.failures == breakerThreshold {
+		b.openUntil = time.Now().Add(BreakerCooldown)
+		log.Printf("⚠️ rate limiter for %s degraded after %d Redis failures (failing %s): %v", b.route, b.failures, failMode, err)
+	}
+}
+
+func (b *breaker) open() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.failures >= breakerThreshold
+}
+
+// OpenBreakers lists the routes whose limiter is currently degraded.
+func OpenBreakers() []string {
+	breakersMu.Lock()
+	defer breakersMu.Unlock()
+	routes := []string{}
+	for route, b := range breakers {
+		if b.open() {
+			routes = append(routes, route)
+		}
+	}
+	slices.Sort(routes)
+	return routes
+}
+
+// ResetBreakers closes every breaker.
+func ResetBreakers() {
+	breakersMu.Lock()
+	defer breakersMu.Unlock()
+	breakers = map[string]*breaker{}
+}
@@ -1,12 +1,114 @@
 package rate_limiter
 
 import (
+	"context"
+	"maps"
+	"math"
 	"sync"
 	"time"
 
+	"github.com/redis/go-redis/v9"
 	"golang.org/x/time/rate"
 )
 
+// Decision is the outcome of counting one request against a limit. Both
+// limiters report it the same way, so clients see the same headers whichever
+// one guards a route.
+type Decision struct {
+	Allowed   bool
+	Limit     int           // requests allowed per window (or burst size)
+	Remaining int           // requests left right now
+	Reset     time.Duration // until Remaining is back to Limit
+	// RetryAfter is how long a rejected client must wait; zero when allowed.
+	RetryAfter time.Duration
+}
+
+// Limiter counts requests per client key.
+type Limiter interface {
+	Allow(key string) (Decision, error)
+}
+
+// BucketLimiter is an in-process token bucket per key. It suits routes that
+// must keep working without Redis, such as registration.
+type BucketLimiter struct {
+	rate  rate.Limit
+	burst int
+}
+
+var _ Limiter = BucketLimiter{}
+
+// NewBucketLimiter allows perSecond requests per second with bursts of burst.
+func NewBucketLimiter(perSecond float64, burst int) BucketLimiter {
+	return BucketLimiter{rate: rate.Limit(perSecond), burst: burst}
+}
+
+func (b BucketLimiter) Allow(key string) (Decision, error) {
+	limiter := getVisitor(key, b.rate, b.burst)
+	now := time.Now()
+	allowed := limiter.AllowN(now, 1)
+	tokens := limiter.TokensAt(now)
+
+	d := Decision{
+		Allowed:   allowed,
+		Limit:     b.burst,
+		Remaining: max(int(math.Floor(tokens)), 0),
+		Reset:     b.refill(float64(b.burst) - tokens),
+	}
+	if !allowed {
+		d.RetryAfter = b.refill(1 - tokens)
+	}
+	return d, nil
+}
+
+// refill is how long the bucket takes to gain n tokens.
+func (b BucketLimiter) refill(n float64) time.Duration {
+	if n <= 0 {
+		return 0
+	}
+	return time.Duration(n / float64(b.rate) * float64(time.Second))
+}
+
+// WindowLimiter is a fixed-window counter in Redis, shared by all instances.
+type WindowLimiter struct {
+	rdb         redis.UniversalClient
+	ctx         context.Context
+	maxRequests int
+	window      time.Duration
+}
+
+var _ Limiter = WindowLimiter{}
+
+func NewWindowLimiter(rdb redis.UniversalClient, ctx context.Context, maxRequests int, window time.Duration) WindowLimiter {
+	return WindowLimiter{rdb: rdb, ctx: ctx, maxRequests: maxRequests, window: window}
+}
+
+func (l WindowLimiter) Allow(key string) (Decision, error) {
+	pipe := l.rdb.TxPipeline()
+	countCmd := pipe.Incr(l.ctx, key)
+	ttlCmd := pipe.TTL(l.ctx, key)
+	if _, err := pipe.Exec(l.ctx); err != nil {
+		return Decision{}, err
+	}
+
+	count, ttl := countCmd.Val(), ttlCmd.Val()
+	// Set expiration if it's a new key
+	if count == 1 || ttl < 0 {
+		l.rdb.Expire(l.ctx, key, l.window)
+		ttl = l.window
+	}
+
+	d := Decision{
+		Allowed:   count <= int64(l.maxRequests),
+		Limit:     l.maxRequests,
+		Remaining: max(l.maxRequests-int(count), 0),
+		Reset:     ttl,
+	}
+	if !d.Allowed {
+		d.RetryAfter = ttl
+	}
+	return d, nil
+}
+
 type clientLimiter struct {
 	limiter  *rate.Limiter
 	lastSeen time.Time
@@ -17,14 +119,14 @@ var (
 	mu       sync.Mutex
 )
 
-func GetVisitor(ip string) *rate.Limiter {
+func getVisitor(key string, r rate.Limit, burst int) *rate.Limiter {
 	mu.Lock()
 	defer mu.Unlock()
 
-	v, exists := visitors[ip]
+	v, exists := visitors[key]
 	if !exists {
-		limiter := rate.NewLimiter(1, 3) // 1 request/sec, burst of 3
-		visitors[ip] = &clientLimiter{limiter, time.Now()}
+		limiter := rate.NewLimiter(r, burst)
+		visitors[key] = &clientLimiter{limiter, time.Now()}
 		return limiter
 	}
 
@@ -46,5 +148,60 @@ func StartVisitorCleanupLoop() {
 }
 
 func CleanupAllVisitors() {
+	mu.Lock()
+	defer mu.Unlock()
 	visitors = make(map[string]*clientLimiter)
 }
+
+// RoleLimit is the Redis-backed request budget of a role on rate-limited routes.
+type RoleLimit struct {
+	Tier        string
+	MaxRequests int
+	Window      time.Duration
+}
+
+// defaultRoleLimits are the role budgets until an administrator applies a
+// configuration. The guest entry also covers unknown roles.
+var defaultRoleLimits = map[string]RoleLimit{
+	"admin": {Tier: "elevated", MaxRequests: 20, Window: time.Minute},
+	"user":  {Tier: "standard", MaxRequests: 10, Window: time.Minute},
+	"guest": {Tier: "basic", MaxRequests: 3, Window: time.Minute},
+}
+
+var (
+	limitsMu   sync.RWMutex
+	roleLimits = defaultRoleLimits
+)
+
+// LimitForRole returns the budget of role; unknown roles get the guest tier.
+func LimitForRole(role string) RoleLimit {
+	limitsMu.RLock()
+	defer limitsMu.RUnlock()
+	if limit, ok := roleLimits[role]; ok {
+		return limit
+	}
+	if limit, ok := roleLimits["guest"]; ok {
+		return limit
+	}
+	return defaultRoleLimits["guest"]
+}
+
+// RoleLimits returns a copy of the configured role budgets.
+func RoleLimits() map[string]RoleLimit {
+	limitsMu.RLock()
+	defer limitsMu.RUnlock()
+	return maps.Clone(roleLimits)
+}
+
+// SetRoleLimits replaces the role budgets. Roles left out fall back to the
+// guest budget, or the built-in basic tier when guest is left out too. A nil
+// map restores the built-in defaults.
+func SetRoleLimits(limits map[string]RoleLimit) {
+	limitsMu.Lock()
+	defer limitsMu.Unlock()
+	if limits == nil {
+		roleLimits = defaultRoleLimits
+		return
+	}
+	roleLimits = maps.Clone(limits)
+}
@@ -5,6 +5,8 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	_ "github.com/rogerio-castellano/inventory-tracker/api/docs" // generated by swag
+	"github.com/rogerio-castellano/inventory-tracker/internal/auth"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/adminui"
 	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
 	mw "github.com/rogerio-castellano/inventory-tracker/internal/http/middleware"
 	httpSwagger "github.com/swaggo/http-swagger/v2"
@@ -12,52 +14,246 @@ import (
 
 func NewRouter() http.Handler {
 	r := chi.NewRouter()
-	r.Get("/products", handlers.GetProductsHandler)
+	r.Use(mw.RequestID)
+	r.Use(mw.UsageTracking)
 
-	r.Get("/products/{id}", handlers.GetProductByIDHandler)
-	r.Get("/products/filter", handlers.FilterProductsHandler)
+	r.Get("/readyz", handlers.ReadyzHandler)
 
-	r.Get("/products/{id}/movements", handlers.GetMovementsHandler)
-	r.Get("/products/{id}/movements/export", handlers.ExportMovementsHandler)
+	r.Group(func(r chi.Router) {
+		r.Use(mw.MaskPricing)
+
+		r.Get("/products", handlers.GetProductsHandler)
+
+		r.Get("/products/{id}", handlers.GetProductByIDHandler)
+		r.Get("/products/filter", handlers.FilterProductsHandler)
+		r.Get("/products/search", handlers.FilterProductsHandler)
+		r.Get("/products/suggest", handlers.SuggestProductsHandler)
+		r.Get("/products/by-external/{externalId}", handlers.GetProductByExternalIDHandler)
+
+		r.Get("/products/{id}/movements", handlers.GetMovementsHandler)
+		r.Get("/products/{id}/substitutes", handlers.GetSubstitutesHandler)
+		r.Get("/products/{id}/movements/export", handlers.ExportMovementsHandler)
+		r.Get("/movements/by-external/{externalId}", handlers.GetMovementByExternalIDHandler)
+	})
+
+	// Slack signs these requests itself; they carry no bearer token.
+	r.Post("/integrations/slack/commands", handlers.SlackCommandHandler)
 
 	r.With(mw.RedisRateLimitPerRole("login")).Post("/login", handlers.LoginHandler)
+	r.With(mw.RedisRateLimitPerRole("login")).Post("/password/change", handlers.ChangePasswordHandler)
 	r.With(mw.RateLimitMiddleware).Post("/register", handlers.RegisterHandler)
 
 	r.Route("/metrics", func(r chi.Router) {
 		r.Use(mw.AuthMiddleware, mw.RequireRole("admin"))
 		r.Get("/dashboard", handlers.GetDashboardMetricsHandler)
+		r.With(mw.RequirePermission(auth.PermPricingRead)).Get("/margins", handlers.GetMarginsHandler)
+		r.Get("/stock-load", handlers.GetStockLoadHandler)
+		r.With(mw.RequirePermission(auth.PermPricingRead)).Get("/valuation/export", handlers.ExportValuationHandler)
 	})
 
 	r.With(mw.RedisRateLimitPerRole("refresh")).Post("/refresh", handlers.RefreshHandler)
 
+	r.Route("/approvals", func(r chi.Router) {
+		r.Use(mw.AuthMiddleware, mw.RequireRole("admin"))
+		r.Get("/", handlers.ListApprovalsHandler)
+		r.Post("/{id}/approve", handlers.ApproveHandler)
+		r.Post("/{id}/reject", handlers.RejectHandler)
+	})
+
 	r.Group(func(r chi.Router) {
-		r.Use(mw.AuthMiddleware)
+		r.Use(mw.SignedRequests, mw.AuthMiddleware, mw.MaskPricing)
 
 		r.Post("/products", handlers.CreateProductHandler)
 		r.Put("/products/{id}", handlers.UpdateProductHandler)
 		r.Delete("/products/{id}", handlers.DeleteProductHandler)
 		r.Post("/products/{id}/adjust", handlers.AdjustQuantityHandler)
+		r.Post("/products/{id}/adjust/batch", handlers.AdjustQuantityBatchHandler)
+		r.Get("/products/{id}/activity", handlers.GetProductActivityHandler)
+		r.Get("/products/{id}/changes", handlers.GetProductChangesHandler)
+		r.Put("/products/{id}/substitutes", handlers.SetSubstitutesHandler)
+		r.Get("/products/{id}/bins", handlers.GetBinsHandler)
+		r.Put("/products/{id}/bins", handlers.SetBinHandler)
+		r.Post("/products/{id}/bins/move", handlers.MoveBinStockHandler)
+		r.Post("/products/{id}/reservations", handlers.CreateReservationHandler)
+		r.Get("/products/{id}/suppliers", handlers.GetProductSuppliersHandler)
+		r.Put("/products/{id}/suppliers/{supplierId}", handlers.LinkProductSupplierHandler)
+		r.Delete("/products/{id}/suppliers/{supplierId}", handlers.UnlinkProductSupplierHandler)
+		r.Delete("/reservations/{id}", handlers.DeleteReservationHandler)
+		r.Put("/products/{id}/consignment", handlers.SetConsignmentHandler)
+		r.Post("/products/{id}/consignment/consume", handlers.ConsumeConsignmentHandler)
+		r.Get("/consignments", handlers.ListConsignmentsHandler)
+		r.With(mw.RequirePermission(auth.PermPricingRead)).Get("/reports/consignment-settlement", handlers.ConsignmentSettlementHandler)
 		r.Post("/products/import", handlers.ImportProductsHandler)
+		r.Post("/movements/import", handlers.ImportMovementsHandler)
+		r.Get("/products/review-queue", handlers.ReviewQueueHandler)
+		r.Post("/products/review-queue/{flagId}/resolve", handlers.ResolveFlagHandler)
+		r.Post("/products/review-queue/{flagId}/dismiss", handlers.DismissFlagHandler)
+		r.Get("/products/export", handlers.ExportProductsHandler)
+		r.Get("/products/duplicates", handlers.FindDuplicatesHandler)
+		r.Post("/products/{id}/documents", handlers.UploadDocumentHandler)
+		r.Get("/products/{id}/documents", handlers.ListDocumentsHandler)
+		r.Get("/products/{id}/documents/{docId}", handlers.DownloadDocumentHandler)
+		r.Get("/products/{id}/documents/{docId}/url", handlers.DocumentDownloadURLHandler)
+		r.Delete("/products/{id}/documents/{docId}", handlers.DeleteDocumentHandler)
+		r.Post("/movements/{id}/attachments", handlers.UploadAttachmentHandler)
+		r.Get("/movements/{id}/attachments", handlers.ListAttachmentsHandler)
+		r.Get("/movements/{id}/attachments/{attachmentId}", handlers.DownloadAttachmentHandler)
+		r.Get("/movements/{id}/attachments/{attachmentId}/url", handlers.AttachmentDownloadURLHandler)
+		r.Delete("/movements/{id}/attachments/{attachmentId}", handlers.DeleteAttachmentHandler)
+		r.Get("/reports/expiring-certificates", handlers.ExpiringCertificatesHandler)
+		r.Post("/products/{id}/lots", handlers.CreateLotHandler)
+		r.Get("/products/{id}/lots", handlers.ListLotsHandler)
+		r.Put("/products/{id}/lots/{lotId}", handlers.UpdateLotQuantityHandler)
+		r.Get("/reports/expiring", handlers.ExpiringLotsHandler)
+		r.Post("/products/{id}/write-off", handlers.WriteOffHandler)
+		r.Get("/reports/waste", handlers.WasteReportHandler)
+		r.Post("/returns", handlers.CreateReturnHandler)
+		r.Get("/returns", handlers.ListReturnsHandler)
+		r.Get("/returns/{id}", handlers.GetReturnHandler)
+		r.Post("/returns/{id}/inspect", handlers.InspectReturnHandler)
+		r.Post("/work-orders", handlers.CreateWorkOrderHandler)
+		r.Get("/work-orders/{id}", handlers.GetWorkOrderHandler)
+		r.Post("/work-orders/{id}/complete", handlers.CompleteWorkOrderHandler)
+		r.Post("/asns", handlers.CreateASNHandler)
+		r.Get("/asns", handlers.ListASNsHandler)
+		r.Get("/asns/{id}", handlers.GetASNHandler)
+		r.Post("/asns/{id}/receive", handlers.ReceiveASNHandler)
+		r.Get("/asns/{id}/discrepancies", handlers.ASNDiscrepanciesHandler)
+		r.With(mw.RequirePermission(auth.PermPricingRead)).Get("/asns/{id}/landed-costs", handlers.ListLandedCostsHandler)
+		r.With(mw.RequireRole("admin")).Post("/asns/{id}/landed-costs", handlers.CreateLandedCostHandler)
+		r.Post("/shipping/estimate", handlers.ShippingEstimateHandler)
+		r.Post("/scan", handlers.ScanHandler)
+		r.Get("/sync/changes", handlers.GetSyncChangesHandler)
+		r.Get("/products/changed", handlers.GetChangedProductsHandler)
+		r.Post("/sync/batch", handlers.SyncBatchHandler)
+		r.With(mw.RequireRole("admin")).Get("/events", handlers.ListEventLogHandler)
+		r.Get("/integrations/mappings", handlers.ListMappingsHandler)
+		r.Post("/integrations/mappings", handlers.CreateMappingHandler)
+		r.Post("/integrations/mappings/bulk", handlers.BulkUpsertMappingsHandler)
+		r.Get("/integrations/mappings/resolve", handlers.ResolveMappingHandler)
+		r.Get("/integrations/mappings/{id}", handlers.GetMappingHandler)
+		r.Put("/integrations/mappings/{id}", handlers.UpdateMappingHandler)
+		r.Delete("/integrations/mappings/{id}", handlers.DeleteMappingHandler)
+		r.Post("/snapshots", handlers.CreateSnapshotHandler)
+		r.Get("/snapshots", handlers.ListSnapshotsHandler)
+		r.Get("/snapshots/{id}", handlers.GetSnapshotHandler)
+		r.Get("/snapshots/{id}/diff/{otherId}", handlers.DiffSnapshotsHandler)
+		r.Get("/reports/stock-history", handlers.StockHistoryHandler)
+		r.Get("/reports/capacity", handlers.CapacityReportHandler)
+		r.Get("/warehouses", handlers.ListWarehousesHandler)
+		r.Get("/warehouses/{id}", handlers.GetWarehouseHandler)
+		r.With(mw.RequireRole("admin")).Post("/warehouses", handlers.CreateWarehouseHandler)
+		r.With(mw.RequireRole("admin")).Put("/warehouses/{id}", handlers.UpdateWarehouseHandler)
+		r.With(mw.RequireRole("admin")).Delete("/warehouses/{id}", handlers.DeleteWarehouseHandler)
+		r.Get("/suppliers", handlers.ListSuppliersHandler)
+		r.Get("/suppliers/{id}", handlers.GetSupplierHandler)
+		r.With(mw.RequireRole("admin")).Post("/suppliers", handlers.CreateSupplierHandler)
+		r.With(mw.RequireRole("admin")).Put("/suppliers/{id}", handlers.UpdateSupplierHandler)
+		r.With(mw.RequireRole("admin")).Delete("/suppliers/{id}", handlers.DeleteSupplierHandler)
+		r.Get("/promotions", handlers.ListPromotionsHandler)
+		r.Get("/promotions/{id}", handlers.GetPromotionHandler)
+		r.With(mw.RequireRole("admin")).Post("/promotions", handlers.CreatePromotionHandler)
+		r.With(mw.RequireRole("admin")).Delete("/promotions/{id}", handlers.DeletePromotionHandler)
 
 		r.Post("/logout", handlers.LogoutHandler)
 		r.Post("/logout/all", handlers.LogoutAllHandler)
 
 		r.Get("/me", handlers.MeHandler)
+		r.Put("/me/timezone", handlers.SetTimezoneHandler)
+		r.Get("/me/usage", handlers.MeUsageHandler)
+		r.Get("/me/activity", handlers.MeActivityHandler)
+		r.Get("/me/logins", handlers.MeLoginsHandler)
+		r.Get("/me/sessions", handlers.MeSessionsHandler)
+		r.Delete("/me/sessions/{sessionKey}", handlers.RevokeMySessionHandler)
+		r.Get("/me/data-export", handlers.MeDataExportHandler)
 	})
 
 	r.Route("/admin", func(r chi.Router) {
 		r.Use(mw.AuthMiddleware, mw.RequireRole("admin"))
 		r.Post("/users", handlers.RegisterAsAdminHandler)
+		r.Post("/users/import", handlers.ImportUsersHandler)
+		r.Post("/users/password-rotation", handlers.ExpirePasswordsHandler)
+		r.Put("/users/{username}/role", handlers.SetUserRoleHandler)
+		r.Post("/users/{username}/disable", handlers.DisableUserHandler)
+		r.Post("/users/{username}/enable", handlers.EnableUserHandler)
+		r.Get("/users/{username}/grants", handlers.ListAccessGrantsHandler)
+		r.Post("/users/{username}/grants", handlers.CreateAccessGrantHandler)
+		r.Delete("/users/{username}/grants/{id}", handlers.DeleteAccessGrantHandler)
 		r.Get("/tokens", handlers.ListRefreshTokensHandler)
 		r.Delete("/tokens/{username}", handlers.RevokeRefreshTokenHandler)
+		r.Get("/sessions/active", handlers.ListActiveSessionsHandler)
+		r.Post("/service-tokens", handlers.IssueServiceTokenHandler)
+		r.Get("/service-tokens", handlers.ListServiceTokensHandler)
+		r.Delete("/service-tokens/{id}", handlers.RevokeServiceTokenHandler)
+		r.Post("/partners", handlers.CreatePartnerHandler)
+		r.Get("/partners", handlers.ListPartnersHandler)
+		r.Post("/partners/{id}/secret", handlers.RotatePartnerSecretHandler)
+		r.Delete("/partners/{id}", handlers.DeletePartnerHandler)
 		r.Get("/users/{username}/tokens", handlers.ListUserTokensHandler)
+		r.Get("/users/{username}/logins", handlers.UserLoginsHandler)
 		r.Delete("/users/{username}/tokens", handlers.RevokeAllUserSessionsHandler)
 		r.Delete("/users/{username}/tokens/{sessionKey}", handlers.RevokeUserSessionHandler)
+		r.Delete("/users/{username}/personal-data", handlers.ErasePersonalDataHandler)
+		r.Post("/seed", handlers.SeedHandler)
+		r.Post("/sandbox/simulate", handlers.SandboxSimulateHandler)
+		r.Post("/bulk/products", handlers.BulkInsertProductsHandler)
+		r.Post("/products/merge", handlers.MergeProductsHandler)
+		r.Post("/repricing", handlers.RepricingHandler)
+		r.Get("/tax-classes", handlers.ListTaxClassesHandler)
+		r.Post("/tax-classes", handlers.CreateTaxClassHandler)
+		r.Put("/tax-classes/{id}", handlers.UpdateTaxClassHandler)
+		r.Delete("/tax-classes/{id}", handlers.DeleteTaxClassHandler)
+		r.Get("/price-lists", handlers.ListPriceListsHandler)
+		r.Post("/price-lists", handlers.CreatePriceListHandler)
+		r.Get("/price-lists/{id}", handlers.GetPriceListHandler)
+		r.Put("/price-lists/{id}", handlers.UpdatePriceListHandler)
+		r.Delete("/price-lists/{id}", handlers.DeletePriceListHandler)
+		r.Put("/price-lists/{id}/prices", handlers.SetListPricesHandler)
+		r.Delete("/price-lists/{id}/prices/{productId}", handlers.DeleteListPriceHandler)
+		r.Get("/capacities", handlers.ListCapacitiesHandler)
+		r.Put("/capacities", handlers.PutCapacityHandler)
+		r.Delete("/capacities/{id}", handlers.DeleteCapacityHandler)
+		r.Get("/adjustment-limits", handlers.ListAdjustmentLimitsHandler)
+		r.Put("/adjustment-limits", handlers.PutAdjustmentLimitHandler)
+		r.Delete("/adjustment-limits/{id}", handlers.DeleteAdjustmentLimitHandler)
+		r.Get("/validation-policy", handlers.GetValidationPolicyHandler)
+		r.Put("/validation-policy", handlers.UpdateValidationPolicyHandler)
+		r.Get("/price-rules", handlers.GetPriceRulesHandler)
+		r.Put("/price-rules", handlers.UpdatePriceRulesHandler)
+		r.Post("/apply", handlers.ApplyConfigHandler)
+		r.Post("/bulk/movements", handlers.BulkInsertMovementsHandler)
+		r.Get("/diagnostics/products/{id}/movements/explain", handlers.ExplainMovementsQueryHandler)
 		r.With(mw.RedisRateLimitPerRole("admin-impersonate")).Post("/users/{username}/tokens", handlers.AdminImpersonateUserHandler)
 		r.Get("/bans", handlers.ListActiveBansHandler)
+		r.Get("/jobs", handlers.ListJobsHandler)
+		r.Get("/webhooks", handlers.ListWebhooksHandler)
+		r.Post("/webhooks", handlers.CreateWebhookHandler)
+		r.Delete("/webhooks/{id}", handlers.DeleteWebhookHandler)
+		r.Get("/webhooks/{id}/deliveries", handlers.ListWebhookDeliveriesHandler)
+		r.Post("/webhooks/{id}/deliveries/{deliveryId}/redeliver", handlers.RedeliverWebhookHandler)
+		r.Post("/webhooks/{id}/test", handlers.TestWebhookHandler)
+		r.Get("/incidents", handlers.ListIncidentsHandler)
 		r.Delete("/bans/{id}", handlers.UnbanHandler)
 		r.Post("/bans/summary/send", handlers.TriggerDailyBanSummaryHandler)
+		r.Get("/reconciliation", handlers.GetReconciliationHandler)
+		r.Post("/reconciliation/corrections", handlers.CorrectReconciliationHandler)
+		r.Get("/usage", handlers.GetUsageHandler)
+		r.Get("/audit", handlers.ListAuditLogHandler)
+		r.Get("/audit/export", handlers.ExportAuditLogHandler)
+		r.Get("/export-recipients", handlers.ListExportRecipientsHandler)
+		r.Post("/export-recipients", handlers.CreateExportRecipientHandler)
+		r.Delete("/export-recipients/{id}", handlers.DeleteExportRecipientHandler)
+		r.Get("/audit/verify", handlers.VerifyAuditLogHandler)
+		r.Get("/periods", handlers.ListPeriodsHandler)
+		r.Post("/periods/{period}/close", handlers.ClosePeriodHandler)
+	})
+
+	// The dashboard pages are public; the data they show comes from the
+	// admin API, called with the token of the admin who signs in.
+	r.Get("/admin/ui", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/admin/ui/", http.StatusMovedPermanently)
 	})
+	r.Handle("/admin/ui/*", http.StripPrefix("/admin/ui", adminui.Handler()))
 
 	r.Get("/swagger/*", httpSwagger.Handler(
 		httpSwagger.URL("http://localhost:8080/swagger/doc.json"), //The url pointing to API definition
@@ -0,0 +1,57 @@
+// Package incident turns background work that failed on every attempt into
+// an incident record and an alert, so failures stop dying silently in logs.
+package incident
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/jobs"
+	"github.com/rogerio-castellano/inventory-tracker/internal/mailer"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+	"github.com/rogerio-castellano/inventory-tracker/internal/security"
+)
+
+// Start reports every failure the jobs package gives up on.
+func Start(incidents repo.IncidentRepository) {
+	jobs.OnExhausted(func(f jobs.Failure) {
+		if err := Report(incidents, f); err != nil {
+			log.Printf("incident for job %s not fully reported: %v", f.Job, err)
+		}
+	})
+}
+
+// Report records the failure, emails the alert recipients and publishes a
+// security event. The incident is recorded even when the alerts fail.
+func Report(incidents repo.IncidentRepository, f jobs.Failure) error {
+	i := models.Incident{Job: f.Job, Reference: f.Reference, Attempts: f.Attempts, OccurredAt: time.Now().UTC()}
+	if f.Err != nil {
+		i.Error = f.Err.Error()
+	}
+	i, err := incidents.Create(i)
+	if err != nil {
+		return fmt.Errorf("record incident: %w", err)
+	}
+	log.Printf("🚨 Job %s failed after %d attempts (incident %d): %v", i.Job, i.Attempts, i.ID, i.Error)
+
+	// A failed webhook delivery would only fail again on the same webhook.
+	if i.Job != security.WebhookJob {
+		security.Publish(security.Event{
+			Type:       security.EventJobIncident,
+			Severity:   security.SeverityCritical,
+			OccurredAt: i.OccurredAt,
+			Target:     i.Job,
+			Details:    map[string]any{"incident_id": i.ID, "reference": i.Reference, "attempts": i.Attempts, "error": i.Error},
+		})
+	}
+
+	body := fmt.Sprintf("<h2>🚨 Job Failed</h2><p>Job <strong>%s</strong> gave up after %d attempts.</p><ul><li>Reference: %s</li><li>Error: %s</li><li>Incident: %d</li></ul>",
+		html.EscapeString(i.Job), i.Attempts, html.EscapeString(i.Reference), html.EscapeString(i.Error), i.ID)
+	if err := mailer.SendHTML("🚨 Job failed: "+i.Job, body); err != nil {
+		return fmt.Errorf("email incident %d: %w", i.ID, err)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+// Package jobs keeps the outcome of the latest run of each background job,
+// so admins can tell a stalled or failing job from an idle one.
+package jobs
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Status describes a background job's runs since the process started.
+type Status struct {
+	Name      string    `json:"name"`
+	Schedule  string    `json:"schedule"`
+	Runs      int       `json:"runs"`
+	Failures  int       `json:"failures"`
+	LastRun   time.Time `json:"last_run,omitzero"`
+	LastError string    `json:"last_error,omitempty"` // empty when the last run succeeded
+}
+
+var (
+	mu       sync.Mutex
+	statuses = map[string]*Status{}
+)
+
+// Register lists a job before its first run.
+func Register(name, schedule string) {
+	mu.Lock()
+	defer mu.Unlock()
+	if _, ok := statuses[name]; !ok {
+		statuses[name] = &Status{Name: name, Schedule: schedule}
+	}
+}
+
+// Record stores the outcome of a run.
+func Record(name string, err error) {
+	mu.Lock()
+	defer mu.Unlock()
+	s, ok := statuses[name]
+	if !ok {
+		s = &Status{Name: name}
+		statuses[name] = s
+	}
+	s.Runs++
+	s.LastRun = time.Now().UTC()
+	s.LastError = ""
+	if err != nil {
+		s.Failures++
+		s.LastError = err.Error()
+	}
+}
+
+// List returns every known job, by name.
+func List() []Status {
+	mu.Lock()
+	defer mu.Unlock()
+	list := make([]Status, 0, len(statuses))
+	for _, s := range statuses {
+		list = append(list, *s)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
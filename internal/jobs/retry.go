@@ -0,0 +1,65 @@
+package jobs
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// Failure is background work that failed on every attempt.
+type Failure struct {
+	Job string
+	// Reference names what the work was about, such as the day of a
+	// snapshot or the ID of an undelivered event, so it can be redone.
+	Reference string
+	Attempts  int
+	Err       error
+}
+
+// Scheduled runs are retried a few times before giving up, waiting longer
+// each time, so a brief database or mail outage does not cost a day's run.
+const (
+	retryAttempts = 3
+	retryBackoff  = time.Minute
+)
+
+var (
+	hookMu      sync.RWMutex
+	onExhausted func(Failure)
+)
+
+// OnExhausted sets the function told about work that used up its retries.
+func OnExhausted(fn func(Failure)) {
+	hookMu.Lock()
+	defer hookMu.Unlock()
+	onExhausted = fn
+}
+
+// Exhausted reports work that used up its retries. Work with its own retry
+// loop calls it directly; Retry calls it for the rest.
+func Exhausted(f Failure) {
+	hookMu.RLock()
+	fn := onExhausted
+	hookMu.RUnlock()
+	if fn == nil {
+		log.Printf("job %s gave up on %q after %d attempts: %v", f.Job, f.Reference, f.Attempts, f.Err)
+		return
+	}
+	fn(f)
+}
+
+// Retry runs fn until it succeeds or has failed retryAttempts times, and
+// reports the failure if it never succeeds. It returns the last error.
+func Retry(job, reference string, fn func() error) error {
+	var err error
+	for attempt := 1; attempt <= retryAttempts; attempt++ {
+		if err = fn(); err == nil {
+			return nil
+		}
+		if attempt < retryAttempts {
+			time.Sleep(time.Duration(attempt) * retryBackoff)
+		}
+	}
+	Exhausted(Failure{Job: job, Reference: reference, Attempts: retryAttempts, Err: err})
+	return err
+}
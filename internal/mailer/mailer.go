@@ -0,0 +1,107 @@
+// Package mailer sends operational emails, mostly to the configured alert
+// recipient.
+package mailer
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"strings"
+)
+
+// Attachment is a file sent along with an email.
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Content     []byte
+}
+
+// SendHTML emails an HTML body to ALERT_TO through the SMTP_* settings.
+func SendHTML(subject, body string) error {
+	return SendHTMLTo(os.Getenv("ALERT_TO"), subject, body)
+}
+
+// SendHTMLTo emails an HTML body to an address other than the alert
+// recipient, such as a supplier contact, through the same settings.
+func SendHTMLTo(to, subject, body string) error {
+	return send(to, strings.Join([]string{
+		"Subject: " + subject,
+		"MIME-Version: 1.0",
+		"Content-Type: text/html; charset=\"UTF-8\"",
+		"",
+		body,
+	}, "\r\n"))
+}
+
+// SendHTMLWithAttachments emails an HTML body with files attached to to,
+// through the same settings.
+func SendHTMLWithAttachments(to, subject, body string, attachments ...Attachment) error {
+	var parts bytes.Buffer
+	mw := multipart.NewWriter(&parts)
+
+	html, err := mw.CreatePart(textproto.MIMEHeader{"Content-Type": {"text/html; charset=\"UTF-8\""}})
+	if err != nil {
+		return err
+	}
+	if _, err := html.Write([]byte(body)); err != nil {
+		return err
+	}
+	for _, a := range attachments {
+		part, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {a.ContentType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": a.Filename})},
+		})
+		if err != nil {
+			return err
+		}
+		if _, err := part.Write([]byte(wrapBase64(a.Content))); err != nil {
+			return err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return err
+	}
+
+	return send(to, strings.Join([]string{
+		"Subject: " + subject,
+		"MIME-Version: 1.0",
+		"Content-Type: multipart/mixed; boundary=\"" + mw.Boundary() + "\"",
+		"",
+		parts.String(),
+	}, "\r\n"))
+}
+
+// send adds the From and To headers to msg and hands it to the SMTP server.
+func send(to, msg string) error {
+	from := os.Getenv("ALERT_FROM")
+	server := os.Getenv("SMTP_SERVER")
+	if from == "" || to == "" || server == "" {
+		return fmt.Errorf("email alerts are not configured")
+	}
+	msg = "From: " + from + "\r\nTo: " + to + "\r\n" + msg
+
+	var auth smtp.Auth
+	if os.Getenv("SMTP_AUTH_DISABLED") == "" {
+		auth = smtp.PlainAuth("", os.Getenv("SMTP_USER"), os.Getenv("SMTP_PASS"), server)
+	}
+	addr := fmt.Sprintf("%s:%s", server, os.Getenv("SMTP_PORT"))
+	return smtp.SendMail(addr, auth, from, []string{to}, []byte(msg))
+}
+
+// wrapBase64 encodes content in lines of 76 characters, as MIME requires.
+func wrapBase64(content []byte) string {
+	encoded := base64.StdEncoding.EncodeToString(content)
+	var b strings.Builder
+	for len(encoded) > 76 {
+		b.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	b.WriteString(encoded + "\r\n")
+	return b.String()
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// AccessGrant lets a user see and change the products of one category or
+// the stock of one warehouse; exactly one of Category and WarehouseID is
+// set. Categories are stored lower-cased and match products regardless of
+// case. A user without grants is not restricted.
+type AccessGrant struct {
+	ID          int       `json:"id"`
+	Username    string    `json:"username"`
+	Category    string    `json:"category,omitempty"`
+	WarehouseID *int      `json:"warehouse_id,omitempty"`
+	CreatedBy   string    `json:"created_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
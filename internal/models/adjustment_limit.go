@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// AdjustmentLimit bounds the size of a single stock adjustment of a product,
+// or of every product when ProductID is 0. Adjustments larger than WarnDelta
+// units either way are accepted with a warning; larger than MaxDelta they
+// are refused unless the caller may override them. Zero turns a tier off.
+type AdjustmentLimit struct {
+	ID        int       `json:"id"`
+	ProductID int       `json:"product_id,omitempty"`
+	WarnDelta int       `json:"warn_delta,omitempty"`
+	MaxDelta  int       `json:"max_delta,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// Approval is a change that waits for a second admin: one admin requests
+// it and another approves or rejects it.
+type Approval struct {
+	ID       int    `json:"id"`
+	Action   string `json:"action"`
+	Entity   string `json:"entity"`
+	EntityID int    `json:"entity_id"`
+	// Details describes the entity when the request was made, such as the
+	// product's name and stock.
+	Details     string     `json:"details,omitempty"`
+	RequestedBy string     `json:"requested_by"`
+	Status      string     `json:"status"`
+	DecidedBy   string     `json:"decided_by,omitempty"`
+	DecidedAt   *time.Time `json:"decided_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+const (
+	ApprovalStatusPending  = "pending"
+	ApprovalStatusApproved = "approved"
+	ApprovalStatusRejected = "rejected"
+
+	ApprovalActionDeleteProduct = "delete_product"
+)
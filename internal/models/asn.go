@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// ASN statuses. An ASN is open until its shipment is received, which is final.
+const (
+	ASNStatusOpen     = "open"
+	ASNStatusReceived = "received"
+)
+
+// ASNLine is a product on an advance shipping notice: how many units the
+// supplier announced and how many arrived.
+type ASNLine struct {
+	ProductID int `json:"product_id"`
+	Expected  int `json:"expected"`
+	Received  int `json:"received"`
+}
+
+// ASN is an advance shipping notice: a supplier's announcement of an inbound
+// shipment. Receiving it adds what actually arrived to stock and records it
+// per line, including products the notice did not list.
+type ASN struct {
+	ID            int        `json:"id"`
+	Reference     string     `json:"reference"` // the supplier's ASN number
+	Supplier      string     `json:"supplier"`
+	SupplierEmail string     `json:"supplier_email,omitempty"`
+	ExpectedAt    *time.Time `json:"expected_at,omitempty"`
+	Lines         []ASNLine  `json:"lines"`
+	Status        string     `json:"status"`
+	CreatedBy     string     `json:"created_by"`
+	CreatedAt     time.Time  `json:"created_at"`
+	ReceivedBy    string     `json:"received_by,omitempty"`
+	ReceivedAt    *time.Time `json:"received_at,omitempty"`
+}
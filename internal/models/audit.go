@@ -0,0 +1,25 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditEntry is one record of the tamper-evident audit log. Hash covers the
+// entry's content and PrevHash, chaining every entry to the one before it.
+// Personal identifiers enter the hash only through their digests, so Actor and
+// EntityID can be pseudonymized on erasure without breaking the chain.
+type AuditEntry struct {
+	ID             int             `json:"id"`
+	Actor          string          `json:"actor"`
+	ActorDigest    string          `json:"actor_digest"`
+	Action         string          `json:"action"`
+	Entity         string          `json:"entity"`
+	EntityID       string          `json:"entity_id"`
+	EntityIDDigest string          `json:"entity_id_digest"`
+	Details        json.RawMessage `json:"details,omitempty"`
+	RequestID      string          `json:"request_id,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	PrevHash       string          `json:"prev_hash"`
+	Hash           string          `json:"hash"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// BinStock is how many units of a product sit in one bin, such as
+// "aisle 3, shelf B", of a warehouse. Units not in any bin are unassigned;
+// bins never hold more than the product's quantity between them.
+type BinStock struct {
+	ProductID int       `json:"product_id"`
+	Warehouse string    `json:"warehouse,omitempty"` // empty for single-site stock
+	Bin       string    `json:"bin"`
+	Quantity  int       `json:"quantity"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Capacity limits how many units a bin, or a whole warehouse when Bin is
+// empty, may hold across all products.
+type Capacity struct {
+	ID        int       `json:"id"`
+	Warehouse string    `json:"warehouse,omitempty"`
+	Bin       string    `json:"bin,omitempty"`
+	MaxUnits  int       `json:"max_units"`
+	Mode      string    `json:"mode"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Capacity modes: what happens to a placement that would overfill.
+const (
+	CapacityModeWarn   = "warn"   // accepted, with a warning
+	CapacityModeReject = "reject" // refused
+)
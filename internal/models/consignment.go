@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// ConsignmentStock is the part of a product's stock that still belongs to
+// its supplier. It is counted in the product's quantity but left out of the
+// valuation of owned stock until it is consumed.
+type ConsignmentStock struct {
+	ProductID int       `json:"product_id"`
+	Supplier  string    `json:"supplier"`
+	Quantity  int       `json:"quantity"`
+	UnitCost  float64   `json:"unit_cost"` // owed to the supplier per consumed unit
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ConsignmentConsumption records consigned units taken out of stock, which
+// makes them payable to the supplier at the unit cost of the time.
+type ConsignmentConsumption struct {
+	ID         int       `json:"id"`
+	ProductID  int       `json:"product_id"`
+	Supplier   string    `json:"supplier"`
+	Quantity   int       `json:"quantity"`
+	UnitCost   float64   `json:"unit_cost"`
+	Note       string    `json:"note,omitempty"`
+	ConsumedBy string    `json:"consumed_by"`
+	ConsumedAt time.Time `json:"consumed_at"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// Document types.
+const (
+	DocumentTypeDatasheet   = "datasheet"
+	DocumentTypeCertificate = "certificate"
+	DocumentTypeManual      = "manual"
+	DocumentTypeOther       = "other"
+)
+
+// ValidDocumentType reports whether t is a known document type.
+func ValidDocumentType(t string) bool {
+	switch t {
+	case DocumentTypeDatasheet, DocumentTypeCertificate, DocumentTypeManual, DocumentTypeOther:
+		return true
+	}
+	return false
+}
+
+// ProductDocument is a file attached to a product. The content lives in
+// file storage under StorageKey.
+type ProductDocument struct {
+	ID          int        `json:"id"`
+	ProductID   int        `json:"product_id"`
+	Type        string     `json:"type"`
+	Filename    string     `json:"filename"`
+	ContentType string     `json:"content_type"`
+	Size        int64      `json:"size"`
+	StorageKey  string     `json:"-"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	UploadedBy  string     `json:"uploaded_by"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// ExportRecipient is someone encrypted exports can be emailed to: only the
+// holder of the private key matching PublicKey can read them.
+type ExportRecipient struct {
+	ID          int       `json:"id"`
+	Name        string    `json:"name"`
+	Email       string    `json:"email"`
+	PublicKey   string    `json:"public_key"`
+	Fingerprint string    `json:"fingerprint"`
+	CreatedBy   string    `json:"created_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
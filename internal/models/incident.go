@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// Incident records background work that failed on every attempt, so the
+// failure is followed up instead of dying in the logs.
+type Incident struct {
+	ID         int       `json:"id"`
+	Job        string    `json:"job"`
+	Reference  string    `json:"reference,omitempty"` // what the work was about
+	Attempts   int       `json:"attempts"`
+	Error      string    `json:"error"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
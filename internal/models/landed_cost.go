@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// Landed cost allocation methods.
+const (
+	// LandedCostByValue spreads the cost in proportion to what each line's
+	// received units cost to buy.
+	LandedCostByValue = "value"
+	// LandedCostByWeight spreads the cost in proportion to the weight of
+	// each line's received units.
+	LandedCostByWeight = "weight"
+)
+
+// LandedCostAllocation is the share of a landed cost charged to one product
+// of the receipt, and its unit cost before and after the charge.
+type LandedCostAllocation struct {
+	ProductID      int     `json:"product_id"`
+	Received       int     `json:"received"`
+	Basis          float64 `json:"basis"` // value or weight of the received units
+	AllocatedCost  float64 `json:"allocated_cost"`
+	UnitCostBefore float64 `json:"unit_cost_before"`
+	UnitCostAfter  float64 `json:"unit_cost_after"`
+}
+
+// LandedCost is a freight, duty or similar charge attached to a received
+// ASN and allocated across its lines, raising their unit costs.
+type LandedCost struct {
+	ID          int                    `json:"id"`
+	ASNID       int                    `json:"asn_id"`
+	Description string                 `json:"description"`
+	TotalCost   float64                `json:"total_cost"`
+	Method      string                 `json:"method"`
+	Allocations []LandedCostAllocation `json:"allocations"`
+	CreatedBy   string                 `json:"created_by"`
+	CreatedAt   time.Time              `json:"created_at"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// LoginEvent is one attempt to log in, kept for the user's login history.
+// Failed attempts are recorded under the username that was tried, which may
+// not belong to any account.
+type LoginEvent struct {
+	ID        int       `json:"id"`
+	Username  string    `json:"username"`
+	IPAddress string    `json:"ip_address"`
+	UserAgent string    `json:"user_agent"`
+	Outcome   string    `json:"outcome"`
+	Reason    string    `json:"reason,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+const (
+	LoginOutcomeSuccess = "success"
+	LoginOutcomeFailure = "failure"
+)
+
+// Reasons a login failed.
+const (
+	LoginReasonUnknownUser     = "unknown_user"
+	LoginReasonInvalidPassword = "invalid_password"
+	LoginReasonAccountDisabled = "account_disabled"
+	LoginReasonPasswordExpired = "password_expired"
+)
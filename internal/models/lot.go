@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// Lot is a batch of a product sharing an expiry date, such as a production
+// run of food or medicine. Quantity is what remains of the lot.
+type Lot struct {
+	ID        int       `json:"id"`
+	ProductID int       `json:"product_id"`
+	LotNumber string    `json:"lot_number"`
+	Quantity  int       `json:"quantity"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// ProductMapping links a product to its code in an external system, such as
+// an SAP material number or a Shopify variant ID. A code identifies exactly one
+// product within its system, while a product may carry codes in many systems.
+type ProductMapping struct {
+	ID           int       `json:"id"`
+	ProductID    int       `json:"product_id"`
+	System       string    `json:"system"`
+	ExternalCode string    `json:"external_code"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
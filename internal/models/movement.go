@@ -1,8 +1,36 @@
 package models
 
+import "slices"
+
+// Movement reasons say why stock changed.
+const (
+	MovementReasonSale     = "sale"
+	MovementReasonReturn   = "return"
+	MovementReasonDamage   = "damage"
+	MovementReasonRecount  = "recount"
+	MovementReasonTransfer = "transfer"
+)
+
+// MovementReasons lists the valid movement reasons.
+var MovementReasons = []string{MovementReasonSale, MovementReasonReturn, MovementReasonDamage, MovementReasonRecount, MovementReasonTransfer}
+
+// ValidMovementReason reports whether reason is one of MovementReasons.
+func ValidMovementReason(reason string) bool {
+	return slices.Contains(MovementReasons, reason)
+}
+
 type Movement struct {
 	ID        int    `json:"id"`
 	ProductID int    `json:"product_id"`
 	Delta     int    `json:"delta"`
 	CreatedAt string `json:"created_at"`
+	// WorkOrderID is set on movements posted by completing a work order.
+	WorkOrderID *int `json:"work_order_id,omitempty"`
+	// ExternalID is an optional client-chosen UUID; see Product.ExternalID.
+	ExternalID string `json:"external_id,omitempty"`
+	// WarehouseID is the warehouse whose stock the movement changed, if any.
+	WarehouseID *int `json:"warehouse_id,omitempty"`
+	// Reason is one of MovementReasons, or empty when none was given.
+	Reason string `json:"reason,omitempty"`
+	Note   string `json:"note,omitempty"`
 }
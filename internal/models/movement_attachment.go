@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// MovementAttachment is an image or PDF attached to a movement, such as a
+// photo of damaged stock or a signed delivery note. The content lives in
+// file storage under StorageKey.
+type MovementAttachment struct {
+	ID          int       `json:"id"`
+	MovementID  int       `json:"movement_id"`
+	Filename    string    `json:"filename"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	StorageKey  string    `json:"-"`
+	Description string    `json:"description,omitempty"`
+	UploadedBy  string    `json:"uploaded_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// Period is a closed accounting month. Movements dated inside it are rejected,
+// and the movements it contained at closing time are kept as a frozen copy so
+// exports for the period stay reproducible.
+type Period struct {
+	ID        int        `json:"id"`
+	Period    string     `json:"period"` // YYYY-MM
+	ClosedBy  string     `json:"closed_by"`
+	ClosedAt  time.Time  `json:"closed_at"`
+	Movements []Movement `json:"-"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// PriceList is a named set of product prices for a customer tier, such as
+// "wholesale". Products without a price in the list sell at their base price.
+type PriceList struct {
+	ID          int       `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// PriceListItem is the price of one product in a price list.
+type PriceListItem struct {
+	ProductID int     `json:"product_id"`
+	Price     float64 `json:"price"`
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// PriceRules are a tenant's sanity checks on product prices, meant to catch
+// typos and unit mix-ups in imports and integrations. A zero limit and an
+// empty currency turn their check off.
+type PriceRules struct {
+	Tenant string `json:"tenant"`
+	// MaxPrice is the highest price a product may have.
+	MaxPrice float64 `json:"max_price,omitempty"`
+	// MaxChangePercent bounds how far one update may move a price, up or
+	// down, relative to the current one.
+	MaxChangePercent float64 `json:"max_change_percent,omitempty"`
+	// Currency is the ISO 4217 code prices are in; prices must not have
+	// more decimals than it uses.
+	Currency  string    `json:"currency,omitempty"`
+	Mode      string    `json:"mode"`
+	UpdatedAt time.Time `json:"updated_at,omitzero"`
+}
+
+// Price rule modes: what happens to a price that breaks a rule.
+const (
+	PriceRuleModeReject = "reject" // refused unless overridden
+	PriceRuleModeWarn   = "warn"   // accepted, flagged and audited
+)
+
+// currencyDecimals lists the currencies whose minor unit is not a hundredth.
+var currencyDecimals = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0, "PYG": 0,
+	"RWF": 0, "UGX": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+}
+
+// CurrencyDecimals returns the number of decimals prices in currency have.
+func CurrencyDecimals(currency string) int {
+	if d, ok := currencyDecimals[strings.ToUpper(currency)]; ok {
+		return d
+	}
+	return 2
+}
@@ -1,12 +1,91 @@
 package models
 
+import "time"
+
 // Product represents a product entity in the inventory system.
 type Product struct {
-	ID        int     `json:"id"`
-	Name      string  `json:"name"`
-	Price     float64 `json:"price"`
+	ID    int     `json:"id"`
+	Name  string  `json:"name"`
+	Price float64 `json:"price"`
+	// Cost is what one unit costs to buy or make; zero when unknown.
+	Cost      float64 `json:"cost"`
 	Quantity  int     `json:"quantity"`
 	Threshold int     `json:"threshold"`
 	CreatedAt string  `json:"created_at,omitempty"`
 	UpdatedAt string  `json:"updated_at,omitempty"`
+	// BaselineQuantity is the stock level not explained by movements: the
+	// quantity at creation plus any direct overwrites via update or import.
+	BaselineQuantity int `json:"baseline_quantity"`
+
+	Category string `json:"category"`
+	SKU      string `json:"sku"`
+	Barcode  string `json:"barcode"`
+	Supplier string `json:"supplier"`
+	// Description is free text about the product, such as what a shop page
+	// would show.
+	Description  string `json:"description"`
+	Brand        string `json:"brand"`
+	Manufacturer string `json:"manufacturer"`
+	// Weight is the weight of one unit in kilograms; zero when unknown.
+	Weight     float64    `json:"weight"`
+	Dimensions Dimensions `json:"dimensions"`
+	// MaxQuantity is the stock ceiling; zero means none.
+	MaxQuantity int    `json:"max_quantity"`
+	Status      string `json:"status"`
+	// ExternalID is an optional UUID chosen by an integrating system, so it
+	// can address the product without storing our serial ID.
+	ExternalID string `json:"external_id,omitempty"`
+	// TaxClassID names the product's tax class; nil when untaxed.
+	TaxClassID *int `json:"tax_class_id,omitempty"`
+	// Seq counts the writes to the product, starting at 1 when it is
+	// created, so caches can tell whether their copy is current.
+	Seq int `json:"seq"`
+	// NeedsReview flags a placeholder created by a movement import for an
+	// unknown SKU, until someone completes its data.
+	NeedsReview bool `json:"needs_review,omitempty"`
+	// Currency is the ISO 4217 code Price and Cost are in; empty means the
+	// base currency.
+	Currency string `json:"currency,omitempty"`
+}
+
+// Dimensions are the outer measurements of one unit in centimetres; zero
+// when unknown.
+type Dimensions struct {
+	Length float64 `json:"length"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// Volume is the volume of one unit in cubic metres, or zero unless all three
+// dimensions are known.
+func (d Dimensions) Volume() float64 {
+	if d.Length <= 0 || d.Width <= 0 || d.Height <= 0 {
+		return 0
+	}
+	return d.Length * d.Width * d.Height / 1e6
+}
+
+// ProductChange is one write to a product: the fields it changed, keyed by
+// column name, with their new values. Creating a product sets every field.
+type ProductChange struct {
+	ProductID int            `json:"product_id"`
+	Seq       int            `json:"seq"`
+	ChangedAt time.Time      `json:"changed_at"`
+	Fields    map[string]any `json:"fields"`
+}
+
+// Product statuses. A product without one is active.
+const (
+	ProductStatusActive       = "active"
+	ProductStatusInactive     = "inactive"
+	ProductStatusDiscontinued = "discontinued"
+)
+
+// ValidProductStatus reports whether s is a known product status.
+func ValidProductStatus(s string) bool {
+	switch s {
+	case ProductStatusActive, ProductStatusInactive, ProductStatusDiscontinued:
+		return true
+	}
+	return false
 }
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// ProductFlag is a catalog quality issue raised on a product. It waits in
+// the review queue until someone resolves or dismisses it.
+type ProductFlag struct {
+	ID        int    `json:"id"`
+	ProductID int    `json:"product_id"`
+	Source    string `json:"source"`
+	Reason    string `json:"reason"`
+	Status    string `json:"status"`
+	// Note is what the reviewer wrote when closing the flag.
+	Note      string     `json:"note,omitempty"`
+	ClosedBy  string     `json:"closed_by,omitempty"`
+	ClosedAt  *time.Time `json:"closed_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+const (
+	// FlagSourceImport flags placeholder products created by imports.
+	FlagSourceImport = "import"
+	// FlagSourceAnomaly flags products adjusted beyond their warning limit.
+	FlagSourceAnomaly = "anomaly"
+	// FlagSourceValidation flags products saved with price rule warnings.
+	FlagSourceValidation = "validation"
+
+	FlagStatusOpen      = "open"
+	FlagStatusResolved  = "resolved"
+	FlagStatusDismissed = "dismissed"
+)
+
+// ValidFlagSource reports whether s is a known flag source.
+func ValidFlagSource(s string) bool {
+	switch s {
+	case FlagSourceImport, FlagSourceAnomaly, FlagSourceValidation:
+		return true
+	}
+	return false
+}
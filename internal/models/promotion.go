@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// Promotion overrides the sale price of one product, or of every product in
+// a category, from StartsAt until EndsAt. Only active promotions change
+// prices; the promotion scheduler keeps Active in step with the window.
+type Promotion struct {
+	ID        int    `json:"id"`
+	Name      string `json:"name"`
+	ProductID *int   `json:"product_id,omitempty"`
+	Category  string `json:"category,omitempty"`
+	// Price replaces the sale price and PercentOff discounts it; exactly one
+	// of them is set.
+	Price      float64   `json:"price,omitempty"`
+	PercentOff float64   `json:"percent_off,omitempty"`
+	StartsAt   time.Time `json:"starts_at"`
+	EndsAt     time.Time `json:"ends_at"`
+	Active     bool      `json:"active"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Running reports whether t falls inside the promotion's window.
+func (p Promotion) Running(t time.Time) bool {
+	return !t.Before(p.StartsAt) && t.Before(p.EndsAt)
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// StockReservation holds units of a product for a pending order until it
+// expires or is released. Reserved units stay in the product's quantity but
+// are not available to other adjustments.
+type StockReservation struct {
+	ID        int       `json:"id"`
+	ProductID int       `json:"product_id"`
+	Quantity  int       `json:"quantity"`
+	Reference string    `json:"reference,omitempty"` // the order the units are held for
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
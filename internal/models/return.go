@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// Return statuses. A return starts pending; inspection moves it to restock or
+// scrap, which are final, or to refurbish until the item is restocked or
+// scrapped after repair.
+const (
+	ReturnStatusPending   = "pending"
+	ReturnStatusRestock   = "restock"
+	ReturnStatusRefurbish = "refurbish"
+	ReturnStatusScrap     = "scrap"
+)
+
+// ValidReturnTransition reports whether a return may move from one status to another.
+func ValidReturnTransition(from, to string) bool {
+	switch from {
+	case ReturnStatusPending:
+		return to == ReturnStatusRestock || to == ReturnStatusRefurbish || to == ReturnStatusScrap
+	case ReturnStatusRefurbish:
+		return to == ReturnStatusRestock || to == ReturnStatusScrap
+	}
+	return false
+}
+
+// Return is a customer return (RMA) of stock that left through an outbound
+// movement. Returned items are only counted as stock again once restocked,
+// which logs RestockMovementID.
+type Return struct {
+	ID                int        `json:"id"`
+	ProductID         int        `json:"product_id"`
+	MovementID        int        `json:"movement_id"`
+	OrderRef          string     `json:"order_ref,omitempty"`
+	Quantity          int        `json:"quantity"`
+	Reason            string     `json:"reason"`
+	Status            string     `json:"status"`
+	InspectionNote    string     `json:"inspection_note,omitempty"`
+	RestockMovementID *int       `json:"restock_movement_id,omitempty"`
+	CreatedBy         string     `json:"created_by"`
+	CreatedAt         time.Time  `json:"created_at"`
+	InspectedBy       string     `json:"inspected_by,omitempty"`
+	InspectedAt       *time.Time `json:"inspected_at,omitempty"`
+}
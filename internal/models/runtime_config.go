@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// RuntimeConfig is the access configuration an administrator applied, kept
+// so it survives restarts. A nil section means the built-in defaults apply.
+type RuntimeConfig struct {
+	Tenant     string                      `json:"tenant"`
+	Roles      map[string][]string         `json:"roles,omitempty"`
+	RateLimits map[string]RateLimitSetting `json:"rate_limits,omitempty"`
+	UpdatedAt  time.Time                   `json:"updated_at,omitzero"`
+}
+
+// RateLimitSetting is the request budget of a role on rate-limited routes.
+type RateLimitSetting struct {
+	Tier          string `json:"tier" yaml:"tier"`
+	MaxRequests   int    `json:"max_requests" yaml:"max_requests"`
+	WindowSeconds int    `json:"window_seconds" yaml:"window_seconds"`
+}
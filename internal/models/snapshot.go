@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// Snapshot freezes the quantity and price of every product at one moment,
+// so stock can be compared before and after an import or a stocktake.
+type Snapshot struct {
+	ID            int            `json:"id"`
+	Label         string         `json:"label,omitempty"`
+	CreatedBy     string         `json:"created_by"`
+	Scheduled     bool           `json:"scheduled"` // taken by the daily job and subject to retention
+	CreatedAt     time.Time      `json:"created_at"`
+	ProductCount  int            `json:"product_count"`
+	TotalQuantity int            `json:"total_quantity"`
+	TotalValue    float64        `json:"total_value"`
+	Items         []SnapshotItem `json:"items,omitempty"`
+}
+
+type SnapshotItem struct {
+	ProductID int     `json:"product_id"`
+	Name      string  `json:"name"`
+	Quantity  int     `json:"quantity"`
+	Price     float64 `json:"price"`
+}
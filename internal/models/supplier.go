@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// Supplier is a company products are bought from. A product's own Supplier
+// field is free text kept for compatibility; the suppliers linked to it
+// through ProductSupplier carry the purchasing terms.
+type Supplier struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email,omitempty"`
+	Phone     string    `json:"phone,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ProductSupplier holds the terms one supplier sells a product on.
+type ProductSupplier struct {
+	ProductID    int       `json:"product_id"`
+	SupplierID   int       `json:"supplier_id"`
+	Supplier     string    `json:"supplier"`
+	SupplierSKU  string    `json:"supplier_sku,omitempty"`
+	CostPrice    float64   `json:"cost_price"`
+	LeadTimeDays int       `json:"lead_time_days"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
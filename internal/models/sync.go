@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Sync operation statuses.
+const (
+	SyncStatusPending  = "pending"
+	SyncStatusApplied  = "applied"
+	SyncStatusRejected = "rejected"
+)
+
+// SyncOperation is an adjustment queued by an offline client and replayed
+// through the sync batch endpoint. ClientID is the UUID the client generated
+// when queuing it, so a batch resent after a dropped connection applies each
+// operation only once.
+type SyncOperation struct {
+	ClientID   string    `json:"client_id"`
+	ProductID  int       `json:"product_id"`
+	Delta      int       `json:"delta"`
+	Status     string    `json:"status"`
+	Reason     string    `json:"reason,omitempty"`
+	MovementID *int      `json:"movement_id,omitempty"`
+	CreatedBy  string    `json:"created_by"`
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// TaxClass is a named tax rate, such as "standard" VAT at 21%. Products
+// reference a class rather than carrying their own rate, so a rate change
+// applies to every product in the class at once.
+type TaxClass struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Rate      float64   `json:"rate"` // percent, e.g. 21 for 21%
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// UsageRecord aggregates one user's API traffic for one day.
+type UsageRecord struct {
+	Username string `json:"username"`
+	Day      string `json:"day"` // YYYY-MM-DD
+	Requests int64  `json:"requests"`
+	BytesIn  int64  `json:"bytes_in"`
+	BytesOut int64  `json:"bytes_out"`
+}
+
+// Activity is the last request a user made.
+type Activity struct {
+	Username  string    `json:"username"`
+	At        time.Time `json:"at"`
+	Request   string    `json:"request"` // method and route, e.g. "POST /products/{id}/adjust"
+	IPAddress string    `json:"ip_address"`
+	UserAgent string    `json:"user_agent"`
+}
@@ -3,10 +3,21 @@ package models
 import "time"
 
 type User struct {
-	ID           int       `json:"id"`
-	Username     string    `json:"username"`
-	PasswordHash string    `json:"-"`
-	Role         string    `json:"role"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID           int    `json:"id"`
+	Username     string `json:"username"`
+	PasswordHash string `json:"-"`
+	Role         string `json:"role"`
+	// Timezone is an IANA name such as "Europe/Madrid"; empty means UTC.
+	Timezone string `json:"timezone,omitempty"`
+	Email    string `json:"email,omitempty"`
+	// MustChangePassword is set on accounts created with a temporary
+	// password; they cannot log in until the password is changed.
+	MustChangePassword bool `json:"must_change_password,omitempty"`
+	// Disabled accounts cannot log in or refresh their sessions.
+	Disabled bool `json:"disabled,omitempty"`
+	// PasswordChangedAt is when the password was last set; it expires
+	// once older than the configured maximum age.
+	PasswordChangedAt time.Time `json:"password_changed_at"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
 }
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// ValidationPolicy lists the product fields a tenant requires on top of the
+// built-in rules, so requirements can change without a deploy.
+type ValidationPolicy struct {
+	Tenant         string    `json:"tenant"`
+	RequiredFields []string  `json:"required_fields"`
+	UpdatedAt      time.Time `json:"updated_at,omitzero"`
+}
+
+// RequirableProductFields are the optional product fields a policy may make
+// mandatory, by JSON name.
+var RequirableProductFields = []string{"sku", "barcode", "category", "supplier", "threshold", "max_quantity", "cost", "external_id", "tax_class_id",
+	"description", "brand", "manufacturer", "weight"}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Warehouse is a location stock is kept in. A product's quantity is its
+// total across locations; the units adjusted without a warehouse belong to
+// none of them.
+type Warehouse struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Address   string    `json:"address,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// WarehouseStock is how many units of a product one warehouse holds.
+type WarehouseStock struct {
+	WarehouseID int    `json:"warehouse_id"`
+	Warehouse   string `json:"warehouse"`
+	Quantity    int    `json:"quantity"`
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// Work order statuses.
+const (
+	WorkOrderStatusOpen      = "open"
+	WorkOrderStatusCompleted = "completed"
+)
+
+// WorkOrderComponent is a product consumed to assemble one unit of the
+// work order's finished good.
+type WorkOrderComponent struct {
+	ProductID       int `json:"product_id"`
+	QuantityPerUnit int `json:"quantity_per_unit"`
+}
+
+// WorkOrder assembles Quantity units of ProductID from its components.
+// Completing it consumes and produces stock in one step.
+type WorkOrder struct {
+	ID          int                  `json:"id"`
+	ProductID   int                  `json:"product_id"`
+	Quantity    int                  `json:"quantity"`
+	Components  []WorkOrderComponent `json:"components"`
+	Status      string               `json:"status"`
+	Note        string               `json:"note,omitempty"`
+	CreatedBy   string               `json:"created_by"`
+	CreatedAt   time.Time            `json:"created_at"`
+	CompletedAt *time.Time           `json:"completed_at,omitempty"`
+}
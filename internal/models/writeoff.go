@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// Write-off reasons.
+const (
+	WriteOffReasonExpired = "expired"
+	WriteOffReasonDamaged = "damaged"
+	WriteOffReasonSpoiled = "spoiled"
+	WriteOffReasonLost    = "lost"
+	WriteOffReasonTheft   = "theft"
+	WriteOffReasonOther   = "other"
+)
+
+// ValidWriteOffReason reports whether r is a known write-off reason.
+func ValidWriteOffReason(r string) bool {
+	switch r {
+	case WriteOffReasonExpired, WriteOffReasonDamaged, WriteOffReasonSpoiled,
+		WriteOffReasonLost, WriteOffReasonTheft, WriteOffReasonOther:
+		return true
+	}
+	return false
+}
+
+// WriteOff records stock removed from inventory as waste. Category is copied
+// from the product at write-off time so reports are not rewritten when the
+// product is later recategorised.
+type WriteOff struct {
+	ID        int       `json:"id"`
+	ProductID int       `json:"product_id"`
+	Category  string    `json:"category"`
+	Quantity  int       `json:"quantity"`
+	Reason    string    `json:"reason"`
+	UnitCost  float64   `json:"unit_cost"`
+	TotalCost float64   `json:"total_cost"`
+	Note      string    `json:"note,omitempty"`
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	// MovementID is the movement that removed the stock, to which photos
+	// of the damage can be attached; nil if it could not be logged.
+	MovementID *int `json:"movement_id,omitempty"`
+}
@@ -0,0 +1,42 @@
+// Package promotion switches promotions on and off as their windows open and
+// close, so price resolution only has to look at the active ones.
+package promotion
+
+import (
+	"log"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/jobs"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+)
+
+// StartScheduler syncs promotions once at startup and then every interval.
+func StartScheduler(promotions repo.PromotionRepository, interval time.Duration) {
+	jobs.Register("promotion_scheduler", "every "+interval.String())
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		err := Sync(promotions, time.Now())
+		if err != nil {
+			log.Printf("⚠️ Failed to sync promotions: %v", err)
+		}
+		jobs.Record("promotion_scheduler", err)
+		<-ticker.C
+	}
+}
+
+// Sync activates the promotions running at now and deactivates the others.
+func Sync(promotions repo.PromotionRepository, now time.Time) error {
+	activated, deactivated, err := promotions.Sync(now)
+	if err != nil {
+		return err
+	}
+	for _, id := range activated {
+		log.Printf("🏷️ Promotion %d started", id)
+	}
+	for _, id := range deactivated {
+		log.Printf("🏷️ Promotion %d ended", id)
+	}
+	return nil
+}
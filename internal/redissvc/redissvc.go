@@ -2,26 +2,74 @@ package redissvc
 
 import (
 	"context"
+	"sync"
 
 	"github.com/redis/go-redis/v9"
 )
 
 type RedisService struct {
-	rdb *redis.Client
+	rdb redis.UniversalClient
 	ctx context.Context
 }
 
-func NewRedisService(rdb *redis.Client, ctx context.Context) *RedisService {
+func NewRedisService(rdb redis.UniversalClient, ctx context.Context) *RedisService {
 	return &RedisService{
 		rdb: rdb,
 		ctx: ctx,
 	}
 }
 
-func (a *RedisService) Rdb() *redis.Client {
+func (a *RedisService) Rdb() redis.UniversalClient {
 	return a.rdb
 }
 
 func (a *RedisService) Ctx() context.Context {
 	return a.ctx
 }
+
+// Config selects the Redis deployment holding rate limits, bans and sessions.
+type Config struct {
+	// Addrs lists one node, the Sentinels when MasterName is set, or
+	// several Cluster nodes.
+	Addrs            []string
+	MasterName       string // Sentinel master name
+	Password         string
+	SentinelPassword string
+}
+
+// NewClient connects to a single node, a Sentinel-managed master (which
+// follows failovers to the promoted replica) or a Redis Cluster, depending
+// on cfg.
+func NewClient(cfg Config) redis.UniversalClient {
+	return redis.NewUniversalClient(&redis.UniversalOptions{
+		Addrs:            cfg.Addrs,
+		MasterName:       cfg.MasterName,
+		Password:         cfg.Password,
+		SentinelPassword: cfg.SentinelPassword,
+	})
+}
+
+// Keys lists the keys matching pattern. KEYS only sees the node it is sent
+// to, so on a Cluster every master is asked.
+func Keys(ctx context.Context, rdb redis.UniversalClient, pattern string) ([]string, error) {
+	cluster, ok := rdb.(*redis.ClusterClient)
+	if !ok {
+		return rdb.Keys(ctx, pattern).Result()
+	}
+
+	var (
+		mu   sync.Mutex
+		keys []string
+	)
+	err := cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+		found, err := node.Keys(ctx, pattern).Result()
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		keys = append(keys, found...)
+		mu.Unlock()
+		return nil
+	})
+	return keys, err
+}
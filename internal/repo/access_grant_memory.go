@@ -0,0 +1,108 @@
+package repo
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+type InMemoryAccessGrantRepository struct {
+	mu         sync.Mutex
+	grants     []models.AccessGrant
+	nextID     int
+	warehouses WarehouseRepository
+}
+
+var _ AccessGrantRepository = (*InMemoryAccessGrantRepository)(nil)
+
+// NewInMemoryAccessGrantRepository checks warehouse grants against
+// warehouses.
+func NewInMemoryAccessGrantRepository(warehouses WarehouseRepository) *InMemoryAccessGrantRepository {
+	return &InMemoryAccessGrantRepository{nextID: 1, warehouses: warehouses}
+}
+
+func (r *InMemoryAccessGrantRepository) Create(g models.AccessGrant) (models.AccessGrant, error) {
+	if g.WarehouseID != nil {
+		if _, err := r.warehouses.GetByID(*g.WarehouseID); err != nil {
+			return models.AccessGrant{}, err
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.grants {
+		if existing.Username != g.Username {
+			continue
+		}
+		sameWarehouse := existing.WarehouseID != nil && g.WarehouseID != nil && *existing.WarehouseID == *g.WarehouseID
+		sameCategory := existing.WarehouseID == nil && g.WarehouseID == nil && existing.Category == g.Category
+		if sameWarehouse || sameCategory {
+			return models.AccessGrant{}, fmt.Errorf("%w: access grant", ErrDuplicatedValueUnique)
+		}
+	}
+	g.ID = r.nextID
+	g.CreatedAt = time.Now().UTC()
+	r.nextID++
+	r.grants = append(r.grants, g)
+	return g, nil
+}
+
+func (r *InMemoryAccessGrantRepository) List(username string) ([]models.AccessGrant, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	grants := []models.AccessGrant{}
+	for _, g := range r.grants {
+		if g.Username == username {
+			grants = append(grants, g)
+		}
+	}
+	sort.SliceStable(grants, func(i, j int) bool {
+		a, b := grants[i], grants[j]
+		if (a.WarehouseID == nil) != (b.WarehouseID == nil) {
+			return a.WarehouseID == nil
+		}
+		if a.WarehouseID != nil {
+			return *a.WarehouseID < *b.WarehouseID
+		}
+		return a.Category < b.Category
+	})
+	return grants, nil
+}
+
+func (r *InMemoryAccessGrantRepository) Delete(username string, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, g := range r.grants {
+		if g.ID == id && g.Username == username {
+			r.grants = append(r.grants[:i], r.grants[i+1:]...)
+			return nil
+		}
+	}
+	return ErrAccessGrantNotFound
+}
+
+func (r *InMemoryAccessGrantRepository) DeleteByUsername(username string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	kept := r.grants[:0]
+	for _, g := range r.grants {
+		if g.Username != username {
+			kept = append(kept, g)
+		}
+	}
+	r.grants = kept
+	return nil
+}
+
+func (r *InMemoryAccessGrantRepository) InUse() (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.grants) > 0, nil
+}
@@ -0,0 +1,113 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+type PostgresAccessGrantRepository struct {
+	db *sql.DB
+}
+
+var _ AccessGrantRepository = (*PostgresAccessGrantRepository)(nil)
+
+func NewPostgresAccessGrantRepository(db *sql.DB) *PostgresAccessGrantRepository {
+	return &PostgresAccessGrantRepository{db: db}
+}
+
+func (r *PostgresAccessGrantRepository) Create(g models.AccessGrant) (models.AccessGrant, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	g.CreatedAt = time.Now().UTC()
+	var category sql.NullString
+	if g.WarehouseID == nil {
+		category = sql.NullString{String: g.Category, Valid: true}
+	}
+	query := `
+		INSERT INTO access_grants (username, category, warehouse_id, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+	err := r.db.QueryRowContext(ctx, query, g.Username, category, g.WarehouseID, g.CreatedBy, g.CreatedAt).Scan(&g.ID)
+	switch {
+	case err == nil:
+		return g, nil
+	case strings.Contains(err.Error(), "23505"):
+		return models.AccessGrant{}, fmt.Errorf("%w: %v", ErrDuplicatedValueUnique, err)
+	case strings.Contains(err.Error(), "23503"):
+		return models.AccessGrant{}, ErrWarehouseNotFound
+	}
+	return models.AccessGrant{}, fmt.Errorf("failed to create access grant: %w", err)
+}
+
+func (r *PostgresAccessGrantRepository) List(username string) ([]models.AccessGrant, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT id, username, category, warehouse_id, created_by, created_at
+		FROM access_grants
+		WHERE username = $1
+		ORDER BY warehouse_id NULLS FIRST, category, id
+	`
+	rows, err := r.db.QueryContext(ctx, query, username)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	grants := []models.AccessGrant{}
+	for rows.Next() {
+		var g models.AccessGrant
+		var category sql.NullString
+		var warehouseID sql.NullInt64
+		if err := rows.Scan(&g.ID, &g.Username, &category, &warehouseID, &g.CreatedBy, &g.CreatedAt); err != nil {
+			return nil, err
+		}
+		g.Category = category.String
+		if warehouseID.Valid {
+			id := int(warehouseID.Int64)
+			g.WarehouseID = &id
+		}
+		grants = append(grants, g)
+	}
+	return grants, rows.Err()
+}
+
+func (r *PostgresAccessGrantRepository) Delete(username string, id int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, `DELETE FROM access_grants WHERE id = $1 AND username = $2`, id, username)
+	if err != nil {
+		return err
+	}
+	rowsAffected, _ := res.RowsAffected()
+	if rowsAffected == 0 {
+		return ErrAccessGrantNotFound
+	}
+	return nil
+}
+
+func (r *PostgresAccessGrantRepository) DeleteByUsername(username string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `DELETE FROM access_grants WHERE username = $1`, username)
+	return err
+}
+
+func (r *PostgresAccessGrantRepository) InUse() (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var inUse bool
+	err := r.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM access_grants)`).Scan(&inUse)
+	return inUse, err
+}
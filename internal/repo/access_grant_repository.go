@@ -0,0 +1,24 @@
+package repo
+
+import (
+	"errors"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+// AccessGrantRepository defines the interface for the categories and
+// warehouses users are restricted to.
+type AccessGrantRepository interface {
+	// Create fails with ErrDuplicatedValueUnique when the user already has
+	// the grant, and with ErrWarehouseNotFound for an unknown warehouse.
+	Create(g models.AccessGrant) (models.AccessGrant, error)
+	// List returns the grants of username, categories first.
+	List(username string) ([]models.AccessGrant, error)
+	// Delete removes the grant with id from username.
+	Delete(username string, id int) error
+	DeleteByUsername(username string) error
+	// InUse reports whether any user has a grant.
+	InUse() (bool, error)
+}
+
+var ErrAccessGrantNotFound = errors.New("access grant not found")
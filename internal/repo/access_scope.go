@@ -0,0 +1,41 @@
+package repo
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+// AccessScope limits queries to the categories and warehouses a user was
+// granted. A nil list leaves that dimension unrestricted; the zero
+// AccessScope restricts nothing.
+type AccessScope struct {
+	// Categories are lower-cased.
+	Categories   []string
+	WarehouseIDs []int
+}
+
+// ScopeOf builds the scope granted by grants. Without category grants every
+// category is allowed, and likewise for warehouses.
+func ScopeOf(grants []models.AccessGrant) AccessScope {
+	var s AccessScope
+	for _, g := range grants {
+		if g.WarehouseID != nil {
+			s.WarehouseIDs = append(s.WarehouseIDs, *g.WarehouseID)
+		} else {
+			s.Categories = append(s.Categories, g.Category)
+		}
+	}
+	return s
+}
+
+// AllowsCategory reports whether products of category are in the scope.
+func (s AccessScope) AllowsCategory(category string) bool {
+	return s.Categories == nil || slices.Contains(s.Categories, strings.ToLower(category))
+}
+
+// AllowsWarehouse reports whether the warehouse is in the scope.
+func (s AccessScope) AllowsWarehouse(id int) bool {
+	return s.WarehouseIDs == nil || slices.Contains(s.WarehouseIDs, id)
+}
@@ -0,0 +1,61 @@
+package repo
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+type InMemoryAdjustmentLimitRepository struct {
+	mu     sync.Mutex
+	limits []models.AdjustmentLimit
+	nextID int
+}
+
+var _ AdjustmentLimitRepository = (*InMemoryAdjustmentLimitRepository)(nil)
+
+func NewInMemoryAdjustmentLimitRepository() *InMemoryAdjustmentLimitRepository {
+	return &InMemoryAdjustmentLimitRepository{nextID: 1}
+}
+
+func (r *InMemoryAdjustmentLimitRepository) List() ([]models.AdjustmentLimit, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	limits := append([]models.AdjustmentLimit{}, r.limits...)
+	sort.Slice(limits, func(i, j int) bool { return limits[i].ProductID < limits[j].ProductID })
+	return limits, nil
+}
+
+func (r *InMemoryAdjustmentLimitRepository) Put(l models.AdjustmentLimit) (models.AdjustmentLimit, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	l.UpdatedAt = time.Now().UTC()
+	for i, existing := range r.limits {
+		if existing.ProductID == l.ProductID {
+			l.ID = existing.ID
+			r.limits[i] = l
+			return l, nil
+		}
+	}
+	l.ID = r.nextID
+	r.nextID++
+	r.limits = append(r.limits, l)
+	return l, nil
+}
+
+func (r *InMemoryAdjustmentLimitRepository) Delete(id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, l := range r.limits {
+		if l.ID == id {
+			r.limits = append(r.limits[:i], r.limits[i+1:]...)
+			return nil
+		}
+	}
+	return ErrAdjustmentLimitNotFound
+}
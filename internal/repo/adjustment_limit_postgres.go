@@ -0,0 +1,71 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+type PostgresAdjustmentLimitRepository struct {
+	db *sql.DB
+}
+
+var _ AdjustmentLimitRepository = (*PostgresAdjustmentLimitRepository)(nil)
+
+func NewPostgresAdjustmentLimitRepository(db *sql.DB) *PostgresAdjustmentLimitRepository {
+	return &PostgresAdjustmentLimitRepository{db: db}
+}
+
+func (r *PostgresAdjustmentLimitRepository) List() ([]models.AdjustmentLimit, error) {
+	query := `SELECT id, product_id, warn_delta, max_delta, updated_at FROM adjustment_limits ORDER BY product_id`
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	limits := []models.AdjustmentLimit{}
+	for rows.Next() {
+		var l models.AdjustmentLimit
+		if err := rows.Scan(&l.ID, &l.ProductID, &l.WarnDelta, &l.MaxDelta, &l.UpdatedAt); err != nil {
+			return nil, err
+		}
+		l.UpdatedAt = l.UpdatedAt.UTC()
+		limits = append(limits, l)
+	}
+	return limits, rows.Err()
+}
+
+func (r *PostgresAdjustmentLimitRepository) Put(l models.AdjustmentLimit) (models.AdjustmentLimit, error) {
+	query := `
+		INSERT INTO adjustment_limits (product_id, warn_delta, max_delta, updated_at) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (product_id) DO UPDATE SET warn_delta = EXCLUDED.warn_delta, max_delta = EXCLUDED.max_delta, updated_at = EXCLUDED.updated_at
+		RETURNING id`
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	l.UpdatedAt = time.Now().UTC()
+	if err := r.db.QueryRowContext(ctx, query, l.ProductID, l.WarnDelta, l.MaxDelta, l.UpdatedAt).Scan(&l.ID); err != nil {
+		return models.AdjustmentLimit{}, err
+	}
+	return l, nil
+}
+
+func (r *PostgresAdjustmentLimitRepository) Delete(id int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, `DELETE FROM adjustment_limits WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrAdjustmentLimitNotFound
+	}
+	return nil
+}
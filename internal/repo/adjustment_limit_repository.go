@@ -0,0 +1,19 @@
+package repo
+
+import (
+	"errors"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+// AdjustmentLimitRepository defines the interface for the global and
+// per-product limits on the size of stock adjustments.
+type AdjustmentLimitRepository interface {
+	// List returns the limits ordered by product, the global one first.
+	List() ([]models.AdjustmentLimit, error)
+	// Put creates or replaces the limit of l's product.
+	Put(l models.AdjustmentLimit) (models.AdjustmentLimit, error)
+	Delete(id int) error
+}
+
+var ErrAdjustmentLimitNotFound = errors.New("adjustment limit not found")
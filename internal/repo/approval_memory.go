@@ -0,0 +1,82 @@
+package repo
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+type InMemoryApprovalRepository struct {
+	mu        sync.Mutex
+	approvals []models.Approval
+	nextID    int
+}
+
+var _ ApprovalRepository = (*InMemoryApprovalRepository)(nil)
+
+func NewInMemoryApprovalRepository() *InMemoryApprovalRepository {
+	return &InMemoryApprovalRepository{nextID: 1}
+}
+
+func (r *InMemoryApprovalRepository) Create(a models.Approval) (models.Approval, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.approvals {
+		if existing.Status == models.ApprovalStatusPending && existing.Action == a.Action && existing.Entity == a.Entity && existing.EntityID == a.EntityID {
+			return models.Approval{}, fmt.Errorf("%w: approval %d is pending", ErrDuplicatedValueUnique, existing.ID)
+		}
+	}
+	a.ID = r.nextID
+	a.Status = models.ApprovalStatusPending
+	a.CreatedAt = time.Now().UTC()
+	r.nextID++
+	r.approvals = append(r.approvals, a)
+	return a, nil
+}
+
+func (r *InMemoryApprovalRepository) GetByID(id int) (models.Approval, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, a := range r.approvals {
+		if a.ID == id {
+			return a, nil
+		}
+	}
+	return models.Approval{}, ErrApprovalNotFound
+}
+
+func (r *InMemoryApprovalRepository) List(status string) ([]models.Approval, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	approvals := []models.Approval{}
+	for i := len(r.approvals) - 1; i >= 0; i-- {
+		if status == "" || r.approvals[i].Status == status {
+			approvals = append(approvals, r.approvals[i])
+		}
+	}
+	return approvals, nil
+}
+
+func (r *InMemoryApprovalRepository) Decide(id int, status, decidedBy string) (models.Approval, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, a := range r.approvals {
+		if a.ID != id {
+			continue
+		}
+		if a.Status != models.ApprovalStatusPending {
+			return models.Approval{}, ErrApprovalDecided
+		}
+		now := time.Now().UTC()
+		a.Status, a.DecidedBy, a.DecidedAt = status, decidedBy, &now
+		r.approvals[i] = a
+		return a, nil
+	}
+	return models.Approval{}, ErrApprovalNotFound
+}
@@ -0,0 +1,113 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+type PostgresApprovalRepository struct {
+	db *sql.DB
+}
+
+var _ ApprovalRepository = (*PostgresApprovalRepository)(nil)
+
+func NewPostgresApprovalRepository(db *sql.DB) *PostgresApprovalRepository {
+	return &PostgresApprovalRepository{db: db}
+}
+
+const approvalColumns = `id, action, entity, entity_id, details, requested_by, status, decided_by, decided_at, created_at`
+
+func scanApproval(row interface{ Scan(...any) error }) (models.Approval, error) {
+	var a models.Approval
+	var decidedAt sql.NullTime
+	if err := row.Scan(&a.ID, &a.Action, &a.Entity, &a.EntityID, &a.Details, &a.RequestedBy, &a.Status, &a.DecidedBy, &decidedAt, &a.CreatedAt); err != nil {
+		return models.Approval{}, err
+	}
+	if decidedAt.Valid {
+		a.DecidedAt = &decidedAt.Time
+	}
+	return a, nil
+}
+
+func (r *PostgresApprovalRepository) Create(a models.Approval) (models.Approval, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	a.Status = models.ApprovalStatusPending
+	a.CreatedAt = time.Now().UTC()
+	query := `
+		INSERT INTO approvals (action, entity, entity_id, details, requested_by, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`
+	err := r.db.QueryRowContext(ctx, query, a.Action, a.Entity, a.EntityID, a.Details, a.RequestedBy, a.Status, a.CreatedAt).Scan(&a.ID)
+	if err != nil {
+		if strings.Contains(err.Error(), "23505") {
+			return models.Approval{}, fmt.Errorf("%w: %v", ErrDuplicatedValueUnique, err)
+		}
+		return models.Approval{}, fmt.Errorf("failed to create approval: %w", err)
+	}
+	return a, nil
+}
+
+func (r *PostgresApprovalRepository) GetByID(id int) (models.Approval, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	a, err := scanApproval(r.db.QueryRowContext(ctx, `SELECT `+approvalColumns+` FROM approvals WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Approval{}, ErrApprovalNotFound
+	}
+	return a, err
+}
+
+func (r *PostgresApprovalRepository) List(status string) ([]models.Approval, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `SELECT ` + approvalColumns + ` FROM approvals`
+	var args []any
+	if status != "" {
+		query += ` WHERE status = $1`
+		args = append(args, status)
+	}
+	rows, err := r.db.QueryContext(ctx, query+` ORDER BY created_at DESC, id DESC`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	approvals := []models.Approval{}
+	for rows.Next() {
+		a, err := scanApproval(rows)
+		if err != nil {
+			return nil, err
+		}
+		approvals = append(approvals, a)
+	}
+	return approvals, rows.Err()
+}
+
+func (r *PostgresApprovalRepository) Decide(id int, status, decidedBy string) (models.Approval, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		UPDATE approvals SET status = $2, decided_by = $3, decided_at = $4
+		WHERE id = $1 AND status = 'pending'
+		RETURNING ` + approvalColumns
+	a, err := scanApproval(r.db.QueryRowContext(ctx, query, id, status, decidedBy, time.Now().UTC()))
+	if errors.Is(err, sql.ErrNoRows) {
+		if _, err := r.GetByID(id); err != nil {
+			return models.Approval{}, err
+		}
+		return models.Approval{}, ErrApprovalDecided
+	}
+	return a, err
+}
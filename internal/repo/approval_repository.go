@@ -0,0 +1,25 @@
+package repo
+
+import (
+	"errors"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+// ApprovalRepository defines the interface for changes awaiting a second
+// admin.
+type ApprovalRepository interface {
+	// Create fails with ErrDuplicatedValueUnique while the same action on
+	// the same entity is already pending.
+	Create(a models.Approval) (models.Approval, error)
+	GetByID(id int) (models.Approval, error)
+	// List returns the approvals with status, or all when it is empty,
+	// newest first.
+	List(status string) ([]models.Approval, error)
+	// Decide sets the status of a pending approval, failing with
+	// ErrApprovalDecided if it was decided already.
+	Decide(id int, status, decidedBy string) (models.Approval, error)
+}
+
+var ErrApprovalNotFound = errors.New("approval not found")
+var ErrApprovalDecided = errors.New("approval already decided")
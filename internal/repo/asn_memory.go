@@ -0,0 +1,113 @@
+package repo
+
+import (
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+// InMemoryASNRepository posts stock through the given product and movement
+// repositories. Unlike the Postgres implementation it cannot roll back, so
+// it checks every product before touching any stock.
+type InMemoryASNRepository struct {
+	mu        sync.Mutex
+	asns      []models.ASN
+	products  ProductRepository
+	movements MovementRepository
+}
+
+var _ ASNRepository = (*InMemoryASNRepository)(nil)
+
+func NewInMemoryASNRepository(products ProductRepository, movements MovementRepository) *InMemoryASNRepository {
+	return &InMemoryASNRepository{products: products, movements: movements}
+}
+
+func (r *InMemoryASNRepository) Create(a models.ASN) (models.ASN, error) {
+	for _, l := range a.Lines {
+		if _, err := r.products.GetByID(l.ProductID); err != nil {
+			return models.ASN{}, err
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	a.ID = len(r.asns) + 1
+	a.Status = models.ASNStatusOpen
+	a.CreatedAt = time.Now().UTC()
+	a.Lines = slices.Clone(a.Lines)
+	r.asns = append(r.asns, a)
+	return a, nil
+}
+
+func (r *InMemoryASNRepository) GetByID(id int) (models.ASN, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if id < 1 || id > len(r.asns) {
+		return models.ASN{}, ErrASNNotFound
+	}
+	a := r.asns[id-1]
+	a.Lines = slices.Clone(a.Lines)
+	return a, nil
+}
+
+func (r *InMemoryASNRepository) List(status string) ([]models.ASN, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	asns := []models.ASN{}
+	for i := len(r.asns) - 1; i >= 0; i-- {
+		if status == "" || r.asns[i].Status == status {
+			a := r.asns[i]
+			a.Lines = slices.Clone(a.Lines)
+			asns = append(asns, a)
+		}
+	}
+	return asns, nil
+}
+
+func (r *InMemoryASNRepository) Receive(id int, received map[int]int, by string) (models.ASN, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if id < 1 || id > len(r.asns) {
+		return models.ASN{}, ErrASNNotFound
+	}
+	a := &r.asns[id-1]
+	if a.Status != models.ASNStatusOpen {
+		return models.ASN{}, ErrASNNotOpen
+	}
+	for pid := range received {
+		if _, err := r.products.GetByID(pid); err != nil {
+			return models.ASN{}, err
+		}
+	}
+
+	for pid, qty := range received {
+		if _, err := r.products.AdjustQuantity(pid, qty); err != nil {
+			return models.ASN{}, err
+		}
+		if _, err := r.movements.Log(models.Movement{ProductID: pid, Delta: qty}); err != nil {
+			return models.ASN{}, err
+		}
+		i := slices.IndexFunc(a.Lines, func(l models.ASNLine) bool { return l.ProductID == pid })
+		if i < 0 {
+			a.Lines = append(a.Lines, models.ASNLine{ProductID: pid})
+			i = len(a.Lines) - 1
+		}
+		a.Lines[i].Received += qty
+	}
+	sort.Slice(a.Lines, func(i, j int) bool { return a.Lines[i].ProductID < a.Lines[j].ProductID })
+
+	now := time.Now().UTC()
+	a.Status = models.ASNStatusReceived
+	a.ReceivedBy = by
+	a.ReceivedAt = &now
+	result := *a
+	result.Lines = slices.Clone(a.Lines)
+	return result, nil
+}
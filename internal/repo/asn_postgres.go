@@ -0,0 +1,191 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+type PostgresASNRepository struct {
+	db *sql.DB
+}
+
+var _ ASNRepository = (*PostgresASNRepository)(nil)
+
+func NewPostgresASNRepository(db *sql.DB) *PostgresASNRepository {
+	return &PostgresASNRepository{db: db}
+}
+
+const asnColumns = `id, reference, supplier, supplier_email, expected_at, status, created_by, created_at, received_by, received_at`
+
+func scanASN(row rowScanner) (models.ASN, error) {
+	var a models.ASN
+	var expectedAt, receivedAt sql.NullTime
+	err := row.Scan(&a.ID, &a.Reference, &a.Supplier, &a.SupplierEmail, &expectedAt, &a.Status, &a.CreatedBy, &a.CreatedAt, &a.ReceivedBy, &receivedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.ASN{}, ErrASNNotFound
+	}
+	a.CreatedAt = a.CreatedAt.UTC()
+	if expectedAt.Valid {
+		t := expectedAt.Time.UTC()
+		a.ExpectedAt = &t
+	}
+	if receivedAt.Valid {
+		t := receivedAt.Time.UTC()
+		a.ReceivedAt = &t
+	}
+	return a, err
+}
+
+func (r *PostgresASNRepository) Create(a models.ASN) (models.ASN, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.ASN{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	a.Status = models.ASNStatusOpen
+	a.CreatedAt = time.Now().UTC()
+	query := `INSERT INTO asns (reference, supplier, supplier_email, expected_at, status, created_by, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`
+	if err := tx.QueryRowContext(ctx, query, a.Reference, a.Supplier, a.SupplierEmail, a.ExpectedAt, a.Status, a.CreatedBy, a.CreatedAt).Scan(&a.ID); err != nil {
+		return models.ASN{}, err
+	}
+	for _, l := range a.Lines {
+		_, err := tx.ExecContext(ctx, `INSERT INTO asn_lines (asn_id, product_id, expected) VALUES ($1, $2, $3)`, a.ID, l.ProductID, l.Expected)
+		if err != nil {
+			if strings.Contains(err.Error(), "23503") {
+				return models.ASN{}, fmt.Errorf("%w: product %d", ErrProductNotFound, l.ProductID)
+			}
+			return models.ASN{}, err
+		}
+	}
+
+	return a, tx.Commit()
+}
+
+func (r *PostgresASNRepository) GetByID(id int) (models.ASN, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	a, err := scanASN(r.db.QueryRowContext(ctx, `SELECT `+asnColumns+` FROM asns WHERE id = $1`, id))
+	if err != nil {
+		return models.ASN{}, err
+	}
+	a.Lines, err = asnLines(ctx, r.db, id)
+	return a, err
+}
+
+func (r *PostgresASNRepository) List(status string) ([]models.ASN, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `SELECT `+asnColumns+` FROM asns WHERE $1 = '' OR status = $1 ORDER BY created_at DESC, id DESC`, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	asns := []models.ASN{}
+	for rows.Next() {
+		a, err := scanASN(rows)
+		if err != nil {
+			return nil, err
+		}
+		asns = append(asns, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for i := range asns {
+		if asns[i].Lines, err = asnLines(ctx, r.db, asns[i].ID); err != nil {
+			return nil, err
+		}
+	}
+	return asns, nil
+}
+
+func (r *PostgresASNRepository) Receive(id int, received map[int]int, by string) (models.ASN, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), bulkTimeout)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.ASN{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var status string
+	err = tx.QueryRowContext(ctx, `SELECT status FROM asns WHERE id = $1 FOR UPDATE`, id).Scan(&status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.ASN{}, ErrASNNotFound
+	}
+	if err != nil {
+		return models.ASN{}, err
+	}
+	if status != models.ASNStatusOpen {
+		return models.ASN{}, ErrASNNotOpen
+	}
+
+	// Update products in id order so concurrent receipts cannot deadlock.
+	ids := make([]int, 0, len(received))
+	for pid := range received {
+		ids = append(ids, pid)
+	}
+	sort.Ints(ids)
+	now := time.Now().UTC()
+	for _, pid := range ids {
+		qty := received[pid]
+		res, err := tx.ExecContext(ctx, `UPDATE products SET quantity = quantity + $1, updated_at = $2 WHERE id = $3`, qty, now, pid)
+		if err != nil {
+			return models.ASN{}, err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return models.ASN{}, fmt.Errorf("%w: product %d", ErrProductNotFound, pid)
+		}
+		if _, err := tx.ExecContext(ctx, `INSERT INTO movements (product_id, delta, created_at, updated_at) VALUES ($1, $2, $3, $3)`, pid, qty, now); err != nil {
+			return models.ASN{}, err
+		}
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO asn_lines (asn_id, product_id, expected, received) VALUES ($1, $2, 0, $3)
+			ON CONFLICT (asn_id, product_id) DO UPDATE SET received = asn_lines.received + EXCLUDED.received`, id, pid, qty)
+		if err != nil {
+			return models.ASN{}, err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE asns SET status = $1, received_by = $2, received_at = $3 WHERE id = $4`, models.ASNStatusReceived, by, now, id)
+	if err != nil {
+		return models.ASN{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return models.ASN{}, err
+	}
+	return r.GetByID(id)
+}
+
+func asnLines(ctx context.Context, q queryer, asnID int) ([]models.ASNLine, error) {
+	rows, err := q.QueryContext(ctx, `SELECT product_id, expected, received FROM asn_lines WHERE asn_id = $1 ORDER BY product_id`, asnID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lines := []models.ASNLine{}
+	for rows.Next() {
+		var l models.ASNLine
+		if err := rows.Scan(&l.ProductID, &l.Expected, &l.Received); err != nil {
+			return nil, err
+		}
+		lines = append(lines, l)
+	}
+	return lines, rows.Err()
+}
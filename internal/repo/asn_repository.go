@@ -0,0 +1,26 @@
+package repo
+
+import (
+	"errors"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+// ASNRepository defines the interface for advance shipping notices.
+type ASNRepository interface {
+	// Create fails with ErrProductNotFound if a line names a missing product.
+	Create(a models.ASN) (models.ASN, error)
+	GetByID(id int) (models.ASN, error)
+	// List returns ASNs newest first, only those in status when it is set.
+	List(status string) ([]models.ASN, error)
+	// Receive adds the received quantities, keyed by product ID, to stock
+	// and to the ASN's lines, logging a movement per product, and closes the
+	// ASN, all in one transaction. Products the ASN did not list get a line
+	// expecting zero.
+	Receive(id int, received map[int]int, by string) (models.ASN, error)
+}
+
+var (
+	ErrASNNotFound = errors.New("ASN not found")
+	ErrASNNotOpen  = errors.New("ASN is already received")
+)
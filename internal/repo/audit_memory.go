@@ -0,0 +1,99 @@
+package repo
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+type InMemoryAuditRepository struct {
+	mu      sync.Mutex
+	entries []models.AuditEntry
+}
+
+var _ AuditRepository = (*InMemoryAuditRepository)(nil)
+
+func NewInMemoryAuditRepository() *InMemoryAuditRepository {
+	return &InMemoryAuditRepository{
+		entries: []models.AuditEntry{},
+	}
+}
+
+func (r *InMemoryAuditRepository) Append(e models.AuditEntry) (models.AuditEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	prevHash := ""
+	if len(r.entries) > 0 {
+		prevHash = r.entries[len(r.entries)-1].Hash
+	}
+	e = sealAuditEntry(e, prevHash)
+	e.ID = len(r.entries) + 1
+	r.entries = append(r.entries, e)
+	return e, nil
+}
+
+func (r *InMemoryAuditRepository) Find(af AuditFilter) ([]models.AuditEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entries := []models.AuditEntry{}
+	for _, e := range r.entries {
+		if af.Actor != "" && e.Actor != af.Actor {
+			continue
+		}
+		if af.Action != "" && e.Action != af.Action {
+			continue
+		}
+		if af.Entity != "" && e.Entity != af.Entity {
+			continue
+		}
+		if af.EntityID != "" && e.EntityID != af.EntityID {
+			continue
+		}
+		if af.Since != nil && e.CreatedAt.Before(*af.Since) {
+			continue
+		}
+		if af.Until != nil && e.CreatedAt.After(*af.Until) {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	if af.Newest {
+		slices.Reverse(entries)
+	}
+	if af.Limit > 0 {
+		entries = entries[min(af.Offset, len(entries)):]
+		entries = entries[:min(af.Limit, len(entries))]
+	}
+	return entries, nil
+}
+
+func (r *InMemoryAuditRepository) GetLastBefore(t time.Time) (models.AuditEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := len(r.entries) - 1; i >= 0; i-- {
+		if r.entries[i].CreatedAt.Before(t) {
+			return r.entries[i], nil
+		}
+	}
+	return models.AuditEntry{}, ErrAuditEntryNotFound
+}
+
+func (r *InMemoryAuditRepository) Pseudonymize(identifier, pseudonym string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, e := range r.entries {
+		if e.Actor == identifier {
+			r.entries[i].Actor = pseudonym
+		}
+		if e.Entity == "user" && e.EntityID == identifier {
+			r.entries[i].EntityID = pseudonym
+		}
+	}
+	return nil
+}
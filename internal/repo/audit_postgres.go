@@ -0,0 +1,167 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+// auditChainLock is the advisory lock key serializing appends to the chain.
+const auditChainLock = 3683
+
+type PostgresAuditRepository struct {
+	db *sql.DB
+}
+
+var _ AuditRepository = (*PostgresAuditRepository)(nil)
+
+func NewPostgresAuditRepository(db *sql.DB) *PostgresAuditRepository {
+	return &PostgresAuditRepository{db: db}
+}
+
+const auditColumns = `id, actor, actor_digest, action, entity, entity_id, entity_id_digest, details, request_id, created_at, prev_hash, hash`
+
+func scanAuditEntry(row rowScanner) (models.AuditEntry, error) {
+	var e models.AuditEntry
+	var details string
+	err := row.Scan(&e.ID, &e.Actor, &e.ActorDigest, &e.Action, &e.Entity, &e.EntityID, &e.EntityIDDigest, &details, &e.RequestID, &e.CreatedAt, &e.PrevHash, &e.Hash)
+	if details != "" {
+		e.Details = []byte(details)
+	}
+	return e, err
+}
+
+func (r *PostgresAuditRepository) Append(e models.AuditEntry) (models.AuditEntry, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.AuditEntry{}, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, auditChainLock); err != nil {
+		return models.AuditEntry{}, fmt.Errorf("failed to lock audit chain: %w", err)
+	}
+
+	var prevHash string
+	err = tx.QueryRowContext(ctx, `SELECT hash FROM audit_log ORDER BY id DESC LIMIT 1`).Scan(&prevHash)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return models.AuditEntry{}, err
+	}
+
+	e = sealAuditEntry(e, prevHash)
+	query := `
+		INSERT INTO audit_log (actor, actor_digest, action, entity, entity_id, entity_id_digest, details, request_id, created_at, prev_hash, hash)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id
+	`
+	err = tx.QueryRowContext(ctx, query, e.Actor, e.ActorDigest, e.Action, e.Entity, e.EntityID, e.EntityIDDigest,
+		string(e.Details), e.RequestID, e.CreatedAt, e.PrevHash, e.Hash).Scan(&e.ID)
+	if err != nil {
+		return models.AuditEntry{}, fmt.Errorf("failed to insert audit entry: %w", err)
+	}
+
+	return e, tx.Commit()
+}
+
+func (r *PostgresAuditRepository) Find(af AuditFilter) ([]models.AuditEntry, error) {
+	query := `SELECT ` + auditColumns + ` FROM audit_log WHERE 1=1`
+	args := []any{}
+	argIdx := 1
+
+	if af.Actor != "" {
+		query += fmt.Sprintf(" AND actor = $%d", argIdx)
+		args = append(args, af.Actor)
+		argIdx++
+	}
+	if af.Action != "" {
+		query += fmt.Sprintf(" AND action = $%d", argIdx)
+		args = append(args, af.Action)
+		argIdx++
+	}
+	if af.Entity != "" {
+		query += fmt.Sprintf(" AND entity = $%d", argIdx)
+		args = append(args, af.Entity)
+		argIdx++
+	}
+	if af.EntityID != "" {
+		query += fmt.Sprintf(" AND entity_id = $%d", argIdx)
+		args = append(args, af.EntityID)
+		argIdx++
+	}
+	if af.Since != nil {
+		query += fmt.Sprintf(" AND created_at >= $%d", argIdx)
+		args = append(args, *af.Since)
+		argIdx++
+	}
+	if af.Until != nil {
+		query += fmt.Sprintf(" AND created_at <= $%d", argIdx)
+		args = append(args, *af.Until)
+		argIdx++
+	}
+	if af.Newest {
+		query += " ORDER BY id DESC"
+	} else {
+		query += " ORDER BY id"
+	}
+	if af.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", argIdx, argIdx+1)
+		args = append(args, af.Limit, af.Offset)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []models.AuditEntry{}
+	for rows.Next() {
+		e, err := scanAuditEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+func (r *PostgresAuditRepository) GetLastBefore(t time.Time) (models.AuditEntry, error) {
+	query := `SELECT ` + auditColumns + ` FROM audit_log WHERE created_at < $1 ORDER BY id DESC LIMIT 1`
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	e, err := scanAuditEntry(r.db.QueryRowContext(ctx, query, t))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.AuditEntry{}, ErrAuditEntryNotFound
+	}
+	return e, err
+}
+
+func (r *PostgresAuditRepository) Pseudonymize(identifier, pseudonym string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `UPDATE audit_log SET actor = $2 WHERE actor = $1`, identifier, pseudonym); err != nil {
+		return fmt.Errorf("failed to pseudonymize audit actors: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE audit_log SET entity_id = $2 WHERE entity = 'user' AND entity_id = $1`, identifier, pseudonym); err != nil {
+		return fmt.Errorf("failed to pseudonymize audit entities: %w", err)
+	}
+	return tx.Commit()
+}
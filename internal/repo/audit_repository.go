@@ -0,0 +1,93 @@
+package repo
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+// AuditFilter narrows audit log queries. A nil or empty field means "no constraint".
+// Newest lists entries newest first instead of in chain order; Limit and
+// Offset page through them, a zero Limit meaning no limit.
+type AuditFilter struct {
+	Actor    string
+	Action   string
+	Entity   string
+	EntityID string
+	Since    *time.Time
+	Until    *time.Time
+	Newest   bool
+	Limit    int
+	Offset   int
+}
+
+// AuditRepository defines the interface for the append-only audit log.
+type AuditRepository interface {
+	// Append links the entry to the current chain head, computes its hash and stores it.
+	Append(e models.AuditEntry) (models.AuditEntry, error)
+	// Find returns matching entries in chain order, or newest first.
+	Find(af AuditFilter) ([]models.AuditEntry, error)
+	// GetLastBefore returns the newest entry created strictly before t.
+	GetLastBefore(t time.Time) (models.AuditEntry, error)
+	// Pseudonymize replaces a personal identifier wherever it appears as actor
+	// or user entity ID. Digests are kept, so the chain still verifies.
+	Pseudonymize(identifier, pseudonym string) error
+}
+
+var ErrAuditEntryNotFound = errors.New("audit entry not found")
+
+// AuditHash computes the chained hash of an entry. Timestamps are hashed at
+// microsecond precision, the resolution Postgres stores them with. The request
+// ID is hashed only when set, so entries written before it existed still verify.
+func AuditHash(e models.AuditEntry) string {
+	fields := []string{
+		e.PrevHash,
+		e.CreatedAt.UTC().Truncate(time.Microsecond).Format(time.RFC3339Nano),
+		e.ActorDigest,
+		e.Action,
+		e.Entity,
+		e.EntityIDDigest,
+		string(e.Details),
+	}
+	if e.RequestID != "" {
+		fields = append(fields, e.RequestID)
+	}
+	h := sha256.New()
+	h.Write([]byte(strings.Join(fields, "|")))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+var auditDigestKey []byte
+
+// SetAuditDigestKey sets the server-side key personal identifiers are
+// digested with, so that a pseudonymized entry cannot be traced back by
+// hashing candidate usernames.
+func SetAuditDigestKey(key string) {
+	auditDigestKey = []byte(key)
+}
+
+// auditDigest is the HMAC-SHA256 of a personal identifier, for inclusion in
+// the chain.
+func auditDigest(value string) string {
+	mac := hmac.New(sha256.New, auditDigestKey)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// sealAuditEntry prepares an entry for storage on top of prevHash.
+func sealAuditEntry(e models.AuditEntry, prevHash string) models.AuditEntry {
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now()
+	}
+	e.CreatedAt = e.CreatedAt.UTC().Truncate(time.Microsecond)
+	e.ActorDigest = auditDigest(e.Actor)
+	e.EntityIDDigest = auditDigest(e.EntityID)
+	e.PrevHash = prevHash
+	e.Hash = AuditHash(e)
+	return e
+}
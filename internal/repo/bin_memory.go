@@ -0,0 +1,173 @@
+package repo
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+type binKey struct {
+	productID      int
+	warehouse, bin string
+}
+
+type InMemoryBinRepository struct {
+	mu       sync.Mutex
+	bins     map[binKey]models.BinStock
+	products ProductRepository
+}
+
+var _ BinRepository = (*InMemoryBinRepository)(nil)
+
+// NewInMemoryBinRepository checks placements against the quantities in
+// products.
+func NewInMemoryBinRepository(products ProductRepository) *InMemoryBinRepository {
+	return &InMemoryBinRepository{bins: map[binKey]models.BinStock{}, products: products}
+}
+
+func (r *InMemoryBinRepository) List(productID int) ([]models.BinStock, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	bins := []models.BinStock{}
+	for k, b := range r.bins {
+		if k.productID == productID {
+			bins = append(bins, b)
+		}
+	}
+	sort.Slice(bins, func(i, j int) bool {
+		if bins[i].Warehouse != bins[j].Warehouse {
+			return bins[i].Warehouse < bins[j].Warehouse
+		}
+		return bins[i].Bin < bins[j].Bin
+	})
+	return bins, nil
+}
+
+func (r *InMemoryBinRepository) Occupancy(warehouse, bin string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	units := 0
+	for k, b := range r.bins {
+		if k.warehouse == warehouse && (bin == "" || k.bin == bin) {
+			units += b.Quantity
+		}
+	}
+	return units, nil
+}
+
+func (r *InMemoryBinRepository) Set(b models.BinStock) error {
+	p, err := r.products.GetByID(b.ProductID)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := binKey{b.ProductID, b.Warehouse, b.Bin}
+	elsewhere := 0
+	for k, other := range r.bins {
+		if k.productID == b.ProductID && k != key {
+			elsewhere += other.Quantity
+		}
+	}
+	if elsewhere+b.Quantity > p.Quantity {
+		return ErrBinsExceedStock
+	}
+	if b.Quantity == 0 {
+		delete(r.bins, key)
+		return nil
+	}
+	b.UpdatedAt = time.Now().UTC()
+	r.bins[key] = b
+	return nil
+}
+
+func (r *InMemoryBinRepository) Move(productID int, warehouse, from, to string, quantity int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	src, ok := r.bins[binKey{productID, warehouse, from}]
+	if !ok {
+		return ErrBinNotFound
+	}
+	if src.Quantity < quantity {
+		return ErrInvalidQuantityChange
+	}
+
+	now := time.Now().UTC()
+	src.Quantity -= quantity
+	src.UpdatedAt = now
+	if src.Quantity == 0 {
+		delete(r.bins, binKey{productID, warehouse, from})
+	} else {
+		r.bins[binKey{productID, warehouse, from}] = src
+	}
+	dst := r.bins[binKey{productID, warehouse, to}]
+	dst.ProductID, dst.Warehouse, dst.Bin = productID, warehouse, to
+	dst.Quantity += quantity
+	dst.UpdatedAt = now
+	r.bins[binKey{productID, warehouse, to}] = dst
+	return nil
+}
+
+type InMemoryCapacityRepository struct {
+	mu         sync.Mutex
+	capacities []models.Capacity
+	nextID     int
+}
+
+var _ CapacityRepository = (*InMemoryCapacityRepository)(nil)
+
+func NewInMemoryCapacityRepository() *InMemoryCapacityRepository {
+	return &InMemoryCapacityRepository{nextID: 1}
+}
+
+func (r *InMemoryCapacityRepository) List() ([]models.Capacity, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	capacities := append([]models.Capacity{}, r.capacities...)
+	sort.Slice(capacities, func(i, j int) bool {
+		if capacities[i].Warehouse != capacities[j].Warehouse {
+			return capacities[i].Warehouse < capacities[j].Warehouse
+		}
+		return capacities[i].Bin < capacities[j].Bin
+	})
+	return capacities, nil
+}
+
+func (r *InMemoryCapacityRepository) Put(c models.Capacity) (models.Capacity, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c.UpdatedAt = time.Now().UTC()
+	for i, existing := range r.capacities {
+		if existing.Warehouse == c.Warehouse && existing.Bin == c.Bin {
+			c.ID = existing.ID
+			r.capacities[i] = c
+			return c, nil
+		}
+	}
+	c.ID = r.nextID
+	r.nextID++
+	r.capacities = append(r.capacities, c)
+	return c, nil
+}
+
+func (r *InMemoryCapacityRepository) Delete(id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, c := range r.capacities {
+		if c.ID == id {
+			r.capacities = append(r.capacities[:i], r.capacities[i+1:]...)
+			return nil
+		}
+	}
+	return ErrCapacityNotFound
+}
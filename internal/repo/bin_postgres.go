@@ -0,0 +1,201 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+type PostgresBinRepository struct {
+	db *sql.DB
+}
+
+var _ BinRepository = (*PostgresBinRepository)(nil)
+
+func NewPostgresBinRepository(db *sql.DB) *PostgresBinRepository {
+	return &PostgresBinRepository{db: db}
+}
+
+func (r *PostgresBinRepository) List(productID int) ([]models.BinStock, error) {
+	query := `SELECT product_id, warehouse, bin, quantity, updated_at FROM product_bins WHERE product_id = $1 ORDER BY warehouse, bin`
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	bins := []models.BinStock{}
+	for rows.Next() {
+		var b models.BinStock
+		if err := rows.Scan(&b.ProductID, &b.Warehouse, &b.Bin, &b.Quantity, &b.UpdatedAt); err != nil {
+			return nil, err
+		}
+		b.UpdatedAt = b.UpdatedAt.UTC()
+		bins = append(bins, b)
+	}
+	return bins, rows.Err()
+}
+
+func (r *PostgresBinRepository) Occupancy(warehouse, bin string) (int, error) {
+	query := `SELECT COALESCE(SUM(quantity), 0) FROM product_bins WHERE warehouse = $1 AND ($2 = '' OR bin = $2)`
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var units int
+	err := r.db.QueryRowContext(ctx, query, warehouse, bin).Scan(&units)
+	return units, err
+}
+
+func (r *PostgresBinRepository) Set(b models.BinStock) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Locking the product keeps concurrent placements and stock changes from
+	// slipping past the check below.
+	var stock, elsewhere int
+	err = tx.QueryRowContext(ctx, `SELECT quantity FROM products WHERE id = $1 FOR UPDATE`, b.ProductID).Scan(&stock)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrProductNotFound
+	}
+	if err != nil {
+		return err
+	}
+	err = tx.QueryRowContext(ctx, `SELECT COALESCE(SUM(quantity), 0) FROM product_bins WHERE product_id = $1 AND NOT (warehouse = $2 AND bin = $3)`,
+		b.ProductID, b.Warehouse, b.Bin).Scan(&elsewhere)
+	if err != nil {
+		return err
+	}
+	if elsewhere+b.Quantity > stock {
+		return ErrBinsExceedStock
+	}
+
+	if b.Quantity == 0 {
+		_, err = tx.ExecContext(ctx, `DELETE FROM product_bins WHERE product_id = $1 AND warehouse = $2 AND bin = $3`, b.ProductID, b.Warehouse, b.Bin)
+	} else {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO product_bins (product_id, warehouse, bin, quantity, updated_at) VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (product_id, warehouse, bin) DO UPDATE SET quantity = EXCLUDED.quantity, updated_at = EXCLUDED.updated_at`,
+			b.ProductID, b.Warehouse, b.Bin, b.Quantity, time.Now().UTC())
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *PostgresBinRepository) Move(productID int, warehouse, from, to string, quantity int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var held int
+	err = tx.QueryRowContext(ctx, `SELECT quantity FROM product_bins WHERE product_id = $1 AND warehouse = $2 AND bin = $3 FOR UPDATE`,
+		productID, warehouse, from).Scan(&held)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrBinNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if held < quantity {
+		return ErrInvalidQuantityChange
+	}
+
+	now := time.Now().UTC()
+	if held == quantity {
+		_, err = tx.ExecContext(ctx, `DELETE FROM product_bins WHERE product_id = $1 AND warehouse = $2 AND bin = $3`, productID, warehouse, from)
+	} else {
+		_, err = tx.ExecContext(ctx, `UPDATE product_bins SET quantity = quantity - $4, updated_at = $5 WHERE product_id = $1 AND warehouse = $2 AND bin = $3`,
+			productID, warehouse, from, quantity, now)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO product_bins (product_id, warehouse, bin, quantity, updated_at) VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (product_id, warehouse, bin) DO UPDATE SET quantity = product_bins.quantity + EXCLUDED.quantity, updated_at = EXCLUDED.updated_at`,
+		productID, warehouse, to, quantity, now)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+type PostgresCapacityRepository struct {
+	db *sql.DB
+}
+
+var _ CapacityRepository = (*PostgresCapacityRepository)(nil)
+
+func NewPostgresCapacityRepository(db *sql.DB) *PostgresCapacityRepository {
+	return &PostgresCapacityRepository{db: db}
+}
+
+func (r *PostgresCapacityRepository) List() ([]models.Capacity, error) {
+	query := `SELECT id, warehouse, bin, max_units, mode, updated_at FROM bin_capacities ORDER BY warehouse, bin`
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	capacities := []models.Capacity{}
+	for rows.Next() {
+		var c models.Capacity
+		if err := rows.Scan(&c.ID, &c.Warehouse, &c.Bin, &c.MaxUnits, &c.Mode, &c.UpdatedAt); err != nil {
+			return nil, err
+		}
+		c.UpdatedAt = c.UpdatedAt.UTC()
+		capacities = append(capacities, c)
+	}
+	return capacities, rows.Err()
+}
+
+func (r *PostgresCapacityRepository) Put(c models.Capacity) (models.Capacity, error) {
+	query := `
+		INSERT INTO bin_capacities (warehouse, bin, max_units, mode, updated_at) VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (warehouse, bin) DO UPDATE SET max_units = EXCLUDED.max_units, mode = EXCLUDED.mode, updated_at = EXCLUDED.updated_at
+		RETURNING id`
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	c.UpdatedAt = time.Now().UTC()
+	if err := r.db.QueryRowContext(ctx, query, c.Warehouse, c.Bin, c.MaxUnits, c.Mode, c.UpdatedAt).Scan(&c.ID); err != nil {
+		return models.Capacity{}, err
+	}
+	return c, nil
+}
+
+func (r *PostgresCapacityRepository) Delete(id int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, `DELETE FROM bin_capacities WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrCapacityNotFound
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package repo
+
+import (
+	"errors"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+// BinRepository defines the interface for where a product's stock sits
+// within warehouses.
+type BinRepository interface {
+	// List returns the product's bins ordered by warehouse and bin.
+	List(productID int) ([]models.BinStock, error)
+	// Set records the quantity in a bin, removing the bin at zero. It fails
+	// with ErrBinsExceedStock when the product's bins would hold more than
+	// its quantity, and with ErrProductNotFound for a missing product.
+	Set(b models.BinStock) error
+	// Move shifts quantity units from one bin to another of the same
+	// warehouse, creating the destination bin if needed. It fails with
+	// ErrBinNotFound when the source bin is empty and with
+	// ErrInvalidQuantityChange when it holds fewer units.
+	Move(productID int, warehouse, from, to string, quantity int) error
+	// Occupancy returns the units of every product in a bin, or in the whole
+	// warehouse when bin is empty.
+	Occupancy(warehouse, bin string) (int, error)
+}
+
+// CapacityRepository defines the interface for bin and warehouse capacities.
+type CapacityRepository interface {
+	// List returns capacities ordered by warehouse and bin.
+	List() ([]models.Capacity, error)
+	// Put creates or replaces the capacity of c's warehouse and bin.
+	Put(c models.Capacity) (models.Capacity, error)
+	Delete(id int) error
+}
+
+var (
+	ErrBinNotFound      = errors.New("bin not found")
+	ErrBinsExceedStock  = errors.New("bins would hold more than the product's quantity")
+	ErrCapacityNotFound = errors.New("capacity not found")
+)
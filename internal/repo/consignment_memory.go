@@ -0,0 +1,128 @@
+package repo
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+// InMemoryConsignmentRepository takes consumed stock out through the given
+// product and movement repositories.
+type InMemoryConsignmentRepository struct {
+	mu           sync.Mutex
+	stock        map[int]models.ConsignmentStock
+	consumptions []models.ConsignmentConsumption
+	products     ProductRepository
+	movements    MovementRepository
+}
+
+var _ ConsignmentRepository = (*InMemoryConsignmentRepository)(nil)
+
+func NewInMemoryConsignmentRepository(products ProductRepository, movements MovementRepository) *InMemoryConsignmentRepository {
+	return &InMemoryConsignmentRepository{stock: map[int]models.ConsignmentStock{}, products: products, movements: movements}
+}
+
+func (r *InMemoryConsignmentRepository) List() ([]models.ConsignmentStock, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stock := []models.ConsignmentStock{}
+	for _, c := range r.stock {
+		stock = append(stock, c)
+	}
+	slices.SortFunc(stock, func(a, b models.ConsignmentStock) int {
+		return cmp.Or(cmp.Compare(a.Supplier, b.Supplier), cmp.Compare(a.ProductID, b.ProductID))
+	})
+	return stock, nil
+}
+
+func (r *InMemoryConsignmentRepository) Set(c models.ConsignmentStock) error {
+	p, err := r.products.GetByID(c.ProductID)
+	if err != nil {
+		return err
+	}
+	if c.Quantity > p.Quantity {
+		return ErrConsignmentExceedsStock
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if c.Quantity == 0 {
+		delete(r.stock, c.ProductID)
+		return nil
+	}
+	c.UpdatedAt = time.Now().UTC()
+	r.stock[c.ProductID] = c
+	return nil
+}
+
+func (r *InMemoryConsignmentRepository) Consume(c models.ConsignmentConsumption) (models.ConsignmentConsumption, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	held, ok := r.stock[c.ProductID]
+	if !ok {
+		return models.ConsignmentConsumption{}, ErrConsignmentNotFound
+	}
+	if held.Quantity < c.Quantity {
+		return models.ConsignmentConsumption{}, ErrInvalidQuantityChange
+	}
+	if _, err := r.products.AdjustQuantity(c.ProductID, -c.Quantity); err != nil {
+		return models.ConsignmentConsumption{}, err
+	}
+	if _, err := r.movements.Log(models.Movement{ProductID: c.ProductID, Delta: -c.Quantity}); err != nil {
+		return models.ConsignmentConsumption{}, err
+	}
+
+	c.Supplier, c.UnitCost = held.Supplier, held.UnitCost
+	c.ConsumedAt = time.Now().UTC()
+	if held.Quantity -= c.Quantity; held.Quantity == 0 {
+		delete(r.stock, c.ProductID)
+	} else {
+		held.UpdatedAt = c.ConsumedAt
+		r.stock[c.ProductID] = held
+	}
+	c.ID = len(r.consumptions) + 1
+	r.consumptions = append(r.consumptions, c)
+	return c, nil
+}
+
+func (r *InMemoryConsignmentRepository) Settlement(from, to time.Time, supplier string) ([]SupplierSettlement, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	type key struct {
+		supplier  string
+		productID int
+		unitCost  float64
+	}
+	quantities := map[key]int{}
+	for _, c := range r.consumptions {
+		if c.ConsumedAt.Before(from) || !c.ConsumedAt.Before(to) || (supplier != "" && c.Supplier != supplier) {
+			continue
+		}
+		quantities[key{c.Supplier, c.ProductID, c.UnitCost}] += c.Quantity
+	}
+	keys := make([]key, 0, len(quantities))
+	for k := range quantities {
+		keys = append(keys, k)
+	}
+	slices.SortFunc(keys, func(a, b key) int {
+		return cmp.Or(cmp.Compare(a.supplier, b.supplier), cmp.Compare(a.productID, b.productID), cmp.Compare(a.unitCost, b.unitCost))
+	})
+
+	suppliers := make([]string, len(keys))
+	lines := make([]SettlementLine, len(keys))
+	for i, k := range keys {
+		suppliers[i] = k.supplier
+		lines[i] = SettlementLine{ProductID: k.productID, Quantity: quantities[k], UnitCost: k.unitCost}
+		if p, err := r.products.GetByID(k.productID); err == nil {
+			lines[i].Name = p.Name
+		}
+	}
+	return groupSettlements(suppliers, lines), nil
+}
@@ -0,0 +1,166 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+type PostgresConsignmentRepository struct {
+	db *sql.DB
+}
+
+var _ ConsignmentRepository = (*PostgresConsignmentRepository)(nil)
+
+func NewPostgresConsignmentRepository(db *sql.DB) *PostgresConsignmentRepository {
+	return &PostgresConsignmentRepository{db: db}
+}
+
+func (r *PostgresConsignmentRepository) List() ([]models.ConsignmentStock, error) {
+	query := `SELECT product_id, supplier, quantity, unit_cost, updated_at FROM consignment_stock ORDER BY supplier, product_id`
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stock := []models.ConsignmentStock{}
+	for rows.Next() {
+		var c models.ConsignmentStock
+		if err := rows.Scan(&c.ProductID, &c.Supplier, &c.Quantity, &c.UnitCost, &c.UpdatedAt); err != nil {
+			return nil, err
+		}
+		c.UpdatedAt = c.UpdatedAt.UTC()
+		stock = append(stock, c)
+	}
+	return stock, rows.Err()
+}
+
+func (r *PostgresConsignmentRepository) Set(c models.ConsignmentStock) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var stock int
+	err = tx.QueryRowContext(ctx, `SELECT quantity FROM products WHERE id = $1 FOR UPDATE`, c.ProductID).Scan(&stock)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrProductNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if c.Quantity > stock {
+		return ErrConsignmentExceedsStock
+	}
+
+	if c.Quantity == 0 {
+		_, err = tx.ExecContext(ctx, `DELETE FROM consignment_stock WHERE product_id = $1`, c.ProductID)
+	} else {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO consignment_stock (product_id, supplier, quantity, unit_cost, updated_at) VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (product_id) DO UPDATE SET supplier = EXCLUDED.supplier, quantity = EXCLUDED.quantity,
+				unit_cost = EXCLUDED.unit_cost, updated_at = EXCLUDED.updated_at`,
+			c.ProductID, c.Supplier, c.Quantity, c.UnitCost, time.Now().UTC())
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *PostgresConsignmentRepository) Consume(c models.ConsignmentConsumption) (models.ConsignmentConsumption, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.ConsignmentConsumption{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var held int
+	err = tx.QueryRowContext(ctx, `SELECT supplier, quantity, unit_cost FROM consignment_stock WHERE product_id = $1 FOR UPDATE`, c.ProductID).
+		Scan(&c.Supplier, &held, &c.UnitCost)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.ConsignmentConsumption{}, ErrConsignmentNotFound
+	}
+	if err != nil {
+		return models.ConsignmentConsumption{}, err
+	}
+	if held < c.Quantity {
+		return models.ConsignmentConsumption{}, ErrInvalidQuantityChange
+	}
+
+	c.ConsumedAt = time.Now().UTC()
+	res, err := tx.ExecContext(ctx, `UPDATE products SET quantity = quantity - $1, updated_at = $2 WHERE id = $3 AND quantity >= $1`, c.Quantity, c.ConsumedAt, c.ProductID)
+	if err != nil {
+		return models.ConsignmentConsumption{}, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return models.ConsignmentConsumption{}, ErrInvalidQuantityChange
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO movements (product_id, delta, created_at, updated_at) VALUES ($1, $2, $3, $3)`, c.ProductID, -c.Quantity, c.ConsumedAt); err != nil {
+		return models.ConsignmentConsumption{}, err
+	}
+	if held == c.Quantity {
+		_, err = tx.ExecContext(ctx, `DELETE FROM consignment_stock WHERE product_id = $1`, c.ProductID)
+	} else {
+		_, err = tx.ExecContext(ctx, `UPDATE consignment_stock SET quantity = quantity - $1, updated_at = $2 WHERE product_id = $3`, c.Quantity, c.ConsumedAt, c.ProductID)
+	}
+	if err != nil {
+		return models.ConsignmentConsumption{}, err
+	}
+
+	query := `
+		INSERT INTO consignment_consumptions (product_id, supplier, quantity, unit_cost, note, consumed_by, consumed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`
+	if err := tx.QueryRowContext(ctx, query, c.ProductID, c.Supplier, c.Quantity, c.UnitCost, c.Note, c.ConsumedBy, c.ConsumedAt).Scan(&c.ID); err != nil {
+		return models.ConsignmentConsumption{}, err
+	}
+	return c, tx.Commit()
+}
+
+func (r *PostgresConsignmentRepository) Settlement(from, to time.Time, supplier string) ([]SupplierSettlement, error) {
+	query := `
+		SELECT c.supplier, c.product_id, p.name, SUM(c.quantity), c.unit_cost
+		FROM consignment_consumptions c
+		JOIN products p ON p.id = c.product_id
+		WHERE c.consumed_at >= $1 AND c.consumed_at < $2 AND ($3 = '' OR c.supplier = $3)
+		GROUP BY c.supplier, c.product_id, p.name, c.unit_cost
+		ORDER BY c.supplier, c.product_id, c.unit_cost`
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, from, to, supplier)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var suppliers []string
+	var lines []SettlementLine
+	for rows.Next() {
+		var s string
+		var l SettlementLine
+		if err := rows.Scan(&s, &l.ProductID, &l.Name, &l.Quantity, &l.UnitCost); err != nil {
+			return nil, err
+		}
+		suppliers = append(suppliers, s)
+		lines = append(lines, l)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return groupSettlements(suppliers, lines), nil
+}
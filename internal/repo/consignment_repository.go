@@ -0,0 +1,63 @@
+package repo
+
+import (
+	"errors"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+// ConsignmentRepository defines the interface for supplier-owned stock.
+type ConsignmentRepository interface {
+	// List returns consigned stock ordered by supplier and product.
+	List() ([]models.ConsignmentStock, error)
+	// Set flags c.Quantity units of the product's stock as consigned by
+	// c.Supplier, clearing the flag at zero. It fails with
+	// ErrConsignmentExceedsStock when the product holds fewer units, and
+	// with ErrProductNotFound for a missing product.
+	Set(c models.ConsignmentStock) error
+	// Consume takes consigned units out of stock, logging a movement and the
+	// consumption, in one transaction. It fails with ErrConsignmentNotFound
+	// when the product has no consigned stock and with
+	// ErrInvalidQuantityChange when it has fewer units.
+	Consume(c models.ConsignmentConsumption) (models.ConsignmentConsumption, error)
+	// Settlement sums what was consumed in [from, to) per supplier, product
+	// and unit cost, only for supplier when it is set.
+	Settlement(from, to time.Time, supplier string) ([]SupplierSettlement, error)
+}
+
+// SettlementLine is what is owed for one product consumed at one unit cost.
+type SettlementLine struct {
+	ProductID int     `json:"product_id"`
+	Name      string  `json:"name"`
+	Quantity  int     `json:"quantity"`
+	UnitCost  float64 `json:"unit_cost"`
+	TotalCost float64 `json:"total_cost"`
+}
+
+// SupplierSettlement is what is owed to a supplier for consumed consignment stock.
+type SupplierSettlement struct {
+	Supplier  string           `json:"supplier"`
+	Lines     []SettlementLine `json:"lines"`
+	TotalCost float64          `json:"total_cost"`
+}
+
+var (
+	ErrConsignmentNotFound     = errors.New("product has no consigned stock")
+	ErrConsignmentExceedsStock = errors.New("consigned stock would exceed the product's quantity")
+)
+
+// groupSettlements folds lines, ordered by supplier, into one settlement per supplier.
+func groupSettlements(suppliers []string, lines []SettlementLine) []SupplierSettlement {
+	settlements := []SupplierSettlement{}
+	for i, l := range lines {
+		if i == 0 || suppliers[i] != suppliers[i-1] {
+			settlements = append(settlements, SupplierSettlement{Supplier: suppliers[i]})
+		}
+		s := &settlements[len(settlements)-1]
+		l.TotalCost = roundCents(l.UnitCost * float64(l.Quantity))
+		s.Lines = append(s.Lines, l)
+		s.TotalCost = roundCents(s.TotalCost + l.TotalCost)
+	}
+	return settlements
+}
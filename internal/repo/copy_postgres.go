@@ -0,0 +1,40 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// bulkTimeout bounds COPY-based batch operations, which legitimately take
+// longer than the single-row queries elsewhere in this package.
+const bulkTimeout = 30 * time.Second
+
+// copyFrom streams rows into table with the Postgres COPY protocol, which is
+// an order of magnitude faster than row-by-row INSERTs for large batches.
+// The whole batch is rejected if any row violates a constraint.
+func copyFrom(db *sql.DB, table string, columns []string, rows [][]any) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), bulkTimeout)
+	defer cancel()
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	var copied int64
+	err = conn.Raw(func(driverConn any) error {
+		c, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return fmt.Errorf("COPY requires the pgx driver, got %T", driverConn)
+		}
+		copied, err = c.Conn().CopyFrom(ctx, pgx.Identifier{table}, columns, pgx.CopyFromRows(rows))
+		return err
+	})
+	return copied, err
+}
@@ -0,0 +1,84 @@
+package repo
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+type InMemoryDocumentRepository struct {
+	mu     sync.Mutex
+	docs   []models.ProductDocument
+	nextID int
+}
+
+var _ DocumentRepository = (*InMemoryDocumentRepository)(nil)
+
+func NewInMemoryDocumentRepository() *InMemoryDocumentRepository {
+	return &InMemoryDocumentRepository{nextID: 1}
+}
+
+func (r *InMemoryDocumentRepository) Create(d models.ProductDocument) (models.ProductDocument, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	d.ID = r.nextID
+	d.CreatedAt = time.Now().UTC()
+	r.nextID++
+	r.docs = append(r.docs, d)
+	return d, nil
+}
+
+func (r *InMemoryDocumentRepository) GetByID(id int) (models.ProductDocument, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, d := range r.docs {
+		if d.ID == id {
+			return d, nil
+		}
+	}
+	return models.ProductDocument{}, ErrDocumentNotFound
+}
+
+func (r *InMemoryDocumentRepository) GetByProductID(productID int) ([]models.ProductDocument, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	docs := []models.ProductDocument{}
+	for _, d := range r.docs {
+		if d.ProductID == productID {
+			docs = append(docs, d)
+		}
+	}
+	return docs, nil
+}
+
+func (r *InMemoryDocumentRepository) GetExpiringBefore(t time.Time) ([]models.ProductDocument, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	docs := []models.ProductDocument{}
+	for _, d := range r.docs {
+		if d.ExpiresAt != nil && d.ExpiresAt.Before(t) {
+			docs = append(docs, d)
+		}
+	}
+	sort.SliceStable(docs, func(i, j int) bool { return docs[i].ExpiresAt.Before(*docs[j].ExpiresAt) })
+	return docs, nil
+}
+
+func (r *InMemoryDocumentRepository) Delete(id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, d := range r.docs {
+		if d.ID == id {
+			r.docs = append(r.docs[:i], r.docs[i+1:]...)
+			return nil
+		}
+	}
+	return ErrDocumentNotFound
+}
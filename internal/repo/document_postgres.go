@@ -0,0 +1,104 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+type PostgresDocumentRepository struct {
+	db *sql.DB
+}
+
+var _ DocumentRepository = (*PostgresDocumentRepository)(nil)
+
+func NewPostgresDocumentRepository(db *sql.DB) *PostgresDocumentRepository {
+	return &PostgresDocumentRepository{db: db}
+}
+
+const documentColumns = `id, product_id, type, filename, content_type, size, storage_key, expires_at, uploaded_by, created_at`
+
+func scanDocument(row rowScanner) (models.ProductDocument, error) {
+	var d models.ProductDocument
+	var expiresAt sql.NullTime
+	err := row.Scan(&d.ID, &d.ProductID, &d.Type, &d.Filename, &d.ContentType, &d.Size, &d.StorageKey, &expiresAt, &d.UploadedBy, &d.CreatedAt)
+	if expiresAt.Valid {
+		t := expiresAt.Time.UTC()
+		d.ExpiresAt = &t
+	}
+	return d, err
+}
+
+func (r *PostgresDocumentRepository) Create(d models.ProductDocument) (models.ProductDocument, error) {
+	query := `
+		INSERT INTO product_documents (product_id, type, filename, content_type, size, storage_key, expires_at, uploaded_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id
+	`
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	d.CreatedAt = time.Now().UTC()
+	err := r.db.QueryRowContext(ctx, query, d.ProductID, d.Type, d.Filename, d.ContentType, d.Size, d.StorageKey, d.ExpiresAt, d.UploadedBy, d.CreatedAt).Scan(&d.ID)
+	if err != nil {
+		return models.ProductDocument{}, err
+	}
+	return d, nil
+}
+
+func (r *PostgresDocumentRepository) GetByID(id int) (models.ProductDocument, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	d, err := scanDocument(r.db.QueryRowContext(ctx, `SELECT `+documentColumns+` FROM product_documents WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.ProductDocument{}, ErrDocumentNotFound
+	}
+	return d, err
+}
+
+func (r *PostgresDocumentRepository) GetByProductID(productID int) ([]models.ProductDocument, error) {
+	return r.query(`SELECT `+documentColumns+` FROM product_documents WHERE product_id = $1 ORDER BY id`, productID)
+}
+
+func (r *PostgresDocumentRepository) GetExpiringBefore(t time.Time) ([]models.ProductDocument, error) {
+	return r.query(`SELECT `+documentColumns+` FROM product_documents WHERE expires_at IS NOT NULL AND expires_at < $1 ORDER BY expires_at, id`, t)
+}
+
+func (r *PostgresDocumentRepository) Delete(id int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, `DELETE FROM product_documents WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrDocumentNotFound
+	}
+	return nil
+}
+
+func (r *PostgresDocumentRepository) query(query string, args ...any) ([]models.ProductDocument, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	docs := []models.ProductDocument{}
+	for rows.Next() {
+		d, err := scanDocument(rows)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, d)
+	}
+	return docs, rows.Err()
+}
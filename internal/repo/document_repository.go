@@ -0,0 +1,21 @@
+package repo
+
+import (
+	"errors"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+// DocumentRepository defines the interface for product document metadata.
+type DocumentRepository interface {
+	Create(d models.ProductDocument) (models.ProductDocument, error)
+	GetByID(id int) (models.ProductDocument, error)
+	GetByProductID(productID int) ([]models.ProductDocument, error)
+	Delete(id int) error
+	// GetExpiringBefore returns documents with an expiry date before the
+	// given time, soonest first, including ones already expired.
+	GetExpiringBefore(t time.Time) ([]models.ProductDocument, error)
+}
+
+var ErrDocumentNotFound = errors.New("document not found")
@@ -1,5 +1,25 @@
 package repo
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
 
 var ErrDuplicatedValueUnique = errors.New("could not create record: unique field value duplicated")
+
+// ErrDuplicatedExternalID is returned instead of ErrDuplicatedValueUnique
+// when the clashing value is an external ID.
+var ErrDuplicatedExternalID = errors.New("external ID already in use")
+
+// uniqueViolation wraps a Postgres unique violation (SQLSTATE 23505) with
+// the matching sentinel error and returns any other error unchanged.
+func uniqueViolation(err error) error {
+	if err == nil || !strings.Contains(err.Error(), "23505") {
+		return err
+	}
+	if strings.Contains(err.Error(), "external_id") {
+		return fmt.Errorf("%w: %v", ErrDuplicatedExternalID, err)
+	}
+	return fmt.Errorf("%w: %v", ErrDuplicatedValueUnique, err)
+}
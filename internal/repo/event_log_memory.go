@@ -0,0 +1,41 @@
+package repo
+
+import (
+	"sync"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/security"
+)
+
+type InMemoryEventLogRepository struct {
+	mu     sync.Mutex
+	events []security.Event
+}
+
+var _ EventLogRepository = (*InMemoryEventLogRepository)(nil)
+
+func NewInMemoryEventLogRepository() *InMemoryEventLogRepository {
+	return &InMemoryEventLogRepository{}
+}
+
+func (r *InMemoryEventLogRepository) Append(e security.Event) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	e.Sequence = len(r.events) + 1
+	r.events = append(r.events, e)
+	return e.Sequence, nil
+}
+
+func (r *InMemoryEventLogRepository) Since(sinceID int, limit int) ([]security.Event, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	events := []security.Event{}
+	for _, e := range r.events[min(max(sinceID, 0), len(r.events)):] {
+		if len(events) == limit {
+			break
+		}
+		events = append(events, e)
+	}
+	return events, nil
+}
@@ -0,0 +1,62 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/security"
+)
+
+type PostgresEventLogRepository struct {
+	db *sql.DB
+}
+
+var _ EventLogRepository = (*PostgresEventLogRepository)(nil)
+
+func NewPostgresEventLogRepository(db *sql.DB) *PostgresEventLogRepository {
+	return &PostgresEventLogRepository{db: db}
+}
+
+func (r *PostgresEventLogRepository) Append(e security.Event) (int, error) {
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return 0, err
+	}
+	query := `INSERT INTO event_log (event_id, type, occurred_at, payload) VALUES ($1, $2, $3, $4) RETURNING id`
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var id int
+	err = r.db.QueryRowContext(ctx, query, e.ID, e.Type, e.OccurredAt, payload).Scan(&id)
+	return id, err
+}
+
+func (r *PostgresEventLogRepository) Since(sinceID int, limit int) ([]security.Event, error) {
+	query := `SELECT id, payload FROM event_log WHERE id > $1 ORDER BY id LIMIT $2`
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, sinceID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []security.Event{}
+	for rows.Next() {
+		var id int
+		var payload []byte
+		if err := rows.Scan(&id, &payload); err != nil {
+			return nil, err
+		}
+		var e security.Event
+		if err := json.Unmarshal(payload, &e); err != nil {
+			return nil, err
+		}
+		e.Sequence = id
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
@@ -0,0 +1,14 @@
+package repo
+
+import (
+	"github.com/rogerio-castellano/inventory-tracker/internal/security"
+)
+
+// EventLogRepository is the outbox of published events, numbered in the
+// order they were stored.
+type EventLogRepository interface {
+	security.Outbox
+	// Since returns up to limit events with a sequence above sinceID, oldest
+	// first.
+	Since(sinceID int, limit int) ([]security.Event, error)
+}
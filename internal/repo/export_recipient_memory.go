@@ -0,0 +1,74 @@
+package repo
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+type InMemoryExportRecipientRepository struct {
+	mu         sync.Mutex
+	recipients []models.ExportRecipient
+	nextID     int
+}
+
+var _ ExportRecipientRepository = (*InMemoryExportRecipientRepository)(nil)
+
+func NewInMemoryExportRecipientRepository() *InMemoryExportRecipientRepository {
+	return &InMemoryExportRecipientRepository{nextID: 1}
+}
+
+func (r *InMemoryExportRecipientRepository) Create(rcpt models.ExportRecipient) (models.ExportRecipient, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.recipients {
+		if existing.Fingerprint == rcpt.Fingerprint {
+			return models.ExportRecipient{}, fmt.Errorf("%w: fingerprint", ErrDuplicatedValueUnique)
+		}
+	}
+	rcpt.ID = r.nextID
+	rcpt.CreatedAt = time.Now().UTC()
+	r.nextID++
+	r.recipients = append(r.recipients, rcpt)
+	return rcpt, nil
+}
+
+func (r *InMemoryExportRecipientRepository) GetByID(id int) (models.ExportRecipient, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, rcpt := range r.recipients {
+		if rcpt.ID == id {
+			return rcpt, nil
+		}
+	}
+	return models.ExportRecipient{}, ErrExportRecipientNotFound
+}
+
+func (r *InMemoryExportRecipientRepository) List() ([]models.ExportRecipient, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	recipients := append([]models.ExportRecipient{}, r.recipients...)
+	sort.SliceStable(recipients, func(i, j int) bool {
+		return recipients[i].Name < recipients[j].Name
+	})
+	return recipients, nil
+}
+
+func (r *InMemoryExportRecipientRepository) Delete(id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, rcpt := range r.recipients {
+		if rcpt.ID == id {
+			r.recipients = append(r.recipients[:i], r.recipients[i+1:]...)
+			return nil
+		}
+	}
+	return ErrExportRecipientNotFound
+}
@@ -0,0 +1,100 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+type PostgresExportRecipientRepository struct {
+	db *sql.DB
+}
+
+var _ ExportRecipientRepository = (*PostgresExportRecipientRepository)(nil)
+
+func NewPostgresExportRecipientRepository(db *sql.DB) *PostgresExportRecipientRepository {
+	return &PostgresExportRecipientRepository{db: db}
+}
+
+func (r *PostgresExportRecipientRepository) Create(rcpt models.ExportRecipient) (models.ExportRecipient, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rcpt.CreatedAt = time.Now().UTC()
+	query := `
+		INSERT INTO export_recipients (name, email, public_key, fingerprint, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`
+	err := r.db.QueryRowContext(ctx, query, rcpt.Name, rcpt.Email, rcpt.PublicKey, rcpt.Fingerprint, rcpt.CreatedBy, rcpt.CreatedAt).Scan(&rcpt.ID)
+	if err != nil {
+		if strings.Contains(err.Error(), "23505") {
+			return models.ExportRecipient{}, fmt.Errorf("%w: %v", ErrDuplicatedValueUnique, err)
+		}
+		return models.ExportRecipient{}, fmt.Errorf("failed to create export recipient: %w", err)
+	}
+	return rcpt, nil
+}
+
+func (r *PostgresExportRecipientRepository) GetByID(id int) (models.ExportRecipient, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT id, name, email, public_key, fingerprint, created_by, created_at
+		FROM export_recipients
+		WHERE id = $1
+	`
+	var rcpt models.ExportRecipient
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&rcpt.ID, &rcpt.Name, &rcpt.Email, &rcpt.PublicKey, &rcpt.Fingerprint, &rcpt.CreatedBy, &rcpt.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.ExportRecipient{}, ErrExportRecipientNotFound
+	}
+	return rcpt, err
+}
+
+func (r *PostgresExportRecipientRepository) List() ([]models.ExportRecipient, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT id, name, email, public_key, fingerprint, created_by, created_at
+		FROM export_recipients
+		ORDER BY name, id
+	`
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	recipients := []models.ExportRecipient{}
+	for rows.Next() {
+		var rcpt models.ExportRecipient
+		if err := rows.Scan(&rcpt.ID, &rcpt.Name, &rcpt.Email, &rcpt.PublicKey, &rcpt.Fingerprint, &rcpt.CreatedBy, &rcpt.CreatedAt); err != nil {
+			return nil, err
+		}
+		recipients = append(recipients, rcpt)
+	}
+	return recipients, rows.Err()
+}
+
+func (r *PostgresExportRecipientRepository) Delete(id int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, `DELETE FROM export_recipients WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	rowsAffected, _ := res.RowsAffected()
+	if rowsAffected == 0 {
+		return ErrExportRecipientNotFound
+	}
+	return nil
+}
@@ -0,0 +1,20 @@
+package repo
+
+import (
+	"errors"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+// ExportRecipientRepository defines the interface for the people encrypted
+// exports are emailed to.
+type ExportRecipientRepository interface {
+	// Create fails with ErrDuplicatedValueUnique when a recipient already
+	// has the key.
+	Create(rcpt models.ExportRecipient) (models.ExportRecipient, error)
+	GetByID(id int) (models.ExportRecipient, error)
+	List() ([]models.ExportRecipient, error)
+	Delete(id int) error
+}
+
+var ErrExportRecipientNotFound = errors.New("export recipient not found")
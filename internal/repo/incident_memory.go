@@ -0,0 +1,44 @@
+package repo
+
+import (
+	"sync"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+type InMemoryIncidentRepository struct {
+	mu        sync.Mutex
+	incidents []models.Incident
+}
+
+var _ IncidentRepository = (*InMemoryIncidentRepository)(nil)
+
+func NewInMemoryIncidentRepository() *InMemoryIncidentRepository {
+	return &InMemoryIncidentRepository{}
+}
+
+func (r *InMemoryIncidentRepository) Create(i models.Incident) (models.Incident, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if i.OccurredAt.IsZero() {
+		i.OccurredAt = time.Now().UTC()
+	}
+	i.ID = len(r.incidents) + 1
+	r.incidents = append(r.incidents, i)
+	return i, nil
+}
+
+func (r *InMemoryIncidentRepository) List(job string, limit int) ([]models.Incident, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	incidents := []models.Incident{}
+	for i := len(r.incidents) - 1; i >= 0 && len(incidents) < limit; i-- {
+		if job == "" || r.incidents[i].Job == job {
+			incidents = append(incidents, r.incidents[i])
+		}
+	}
+	return incidents, nil
+}
@@ -0,0 +1,57 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+type PostgresIncidentRepository struct {
+	db *sql.DB
+}
+
+var _ IncidentRepository = (*PostgresIncidentRepository)(nil)
+
+func NewPostgresIncidentRepository(db *sql.DB) *PostgresIncidentRepository {
+	return &PostgresIncidentRepository{db: db}
+}
+
+func (r *PostgresIncidentRepository) Create(i models.Incident) (models.Incident, error) {
+	query := `INSERT INTO incidents (job, reference, attempts, error, occurred_at) VALUES ($1, $2, $3, $4, $5) RETURNING id`
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	if i.OccurredAt.IsZero() {
+		i.OccurredAt = time.Now().UTC()
+	}
+	if err := r.db.QueryRowContext(ctx, query, i.Job, i.Reference, i.Attempts, i.Error, i.OccurredAt).Scan(&i.ID); err != nil {
+		return models.Incident{}, err
+	}
+	return i, nil
+}
+
+func (r *PostgresIncidentRepository) List(job string, limit int) ([]models.Incident, error) {
+	query := `SELECT id, job, reference, attempts, error, occurred_at FROM incidents
+		WHERE $1 = '' OR job = $1 ORDER BY occurred_at DESC, id DESC LIMIT $2`
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, job, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	incidents := []models.Incident{}
+	for rows.Next() {
+		var i models.Incident
+		if err := rows.Scan(&i.ID, &i.Job, &i.Reference, &i.Attempts, &i.Error, &i.OccurredAt); err != nil {
+			return nil, err
+		}
+		i.OccurredAt = i.OccurredAt.UTC()
+		incidents = append(incidents, i)
+	}
+	return incidents, rows.Err()
+}
@@ -0,0 +1,13 @@
+package repo
+
+import (
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+// IncidentRepository defines the interface for failed background work.
+type IncidentRepository interface {
+	Create(i models.Incident) (models.Incident, error)
+	// List returns up to limit incidents newest first, only those of job
+	// when it is set.
+	List(job string, limit int) ([]models.Incident, error)
+}
@@ -0,0 +1,71 @@
+package repo
+
+import (
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+// InMemoryLandedCostRepository updates costs through the given product
+// repository. It checks every product before changing any cost.
+type InMemoryLandedCostRepository struct {
+	mu       sync.Mutex
+	costs    []models.LandedCost
+	products ProductRepository
+}
+
+var _ LandedCostRepository = (*InMemoryLandedCostRepository)(nil)
+
+func NewInMemoryLandedCostRepository(products ProductRepository) *InMemoryLandedCostRepository {
+	return &InMemoryLandedCostRepository{products: products}
+}
+
+func (r *InMemoryLandedCostRepository) Create(lc models.LandedCost) (models.LandedCost, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	lc.Allocations = slices.Clone(lc.Allocations)
+	sort.Slice(lc.Allocations, func(i, j int) bool { return lc.Allocations[i].ProductID < lc.Allocations[j].ProductID })
+	products := make([]models.Product, len(lc.Allocations))
+	for i, a := range lc.Allocations {
+		p, err := r.products.GetByID(a.ProductID)
+		if err != nil {
+			return models.LandedCost{}, err
+		}
+		products[i] = p
+	}
+
+	for i, p := range products {
+		a := &lc.Allocations[i]
+		a.UnitCostBefore = p.Cost
+		a.UnitCostAfter = landedUnitCost(p.Cost, a.AllocatedCost, p.Quantity, a.Received)
+		p.Cost = a.UnitCostAfter
+		if _, err := r.products.Update(p); err != nil {
+			return models.LandedCost{}, err
+		}
+	}
+
+	lc.ID = len(r.costs) + 1
+	lc.CreatedAt = time.Now().UTC()
+	r.costs = append(r.costs, lc)
+	result := lc
+	result.Allocations = slices.Clone(lc.Allocations)
+	return result, nil
+}
+
+func (r *InMemoryLandedCostRepository) List(asnID int) ([]models.LandedCost, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	costs := []models.LandedCost{}
+	for _, lc := range r.costs {
+		if lc.ASNID == asnID {
+			lc.Allocations = slices.Clone(lc.Allocations)
+			costs = append(costs, lc)
+		}
+	}
+	return costs, nil
+}
@@ -0,0 +1,117 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+type PostgresLandedCostRepository struct {
+	db *sql.DB
+}
+
+var _ LandedCostRepository = (*PostgresLandedCostRepository)(nil)
+
+func NewPostgresLandedCostRepository(db *sql.DB) *PostgresLandedCostRepository {
+	return &PostgresLandedCostRepository{db: db}
+}
+
+func (r *PostgresLandedCostRepository) Create(lc models.LandedCost) (models.LandedCost, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), bulkTimeout)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.LandedCost{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	lc.CreatedAt = time.Now().UTC()
+	query := `INSERT INTO landed_costs (asn_id, description, total_cost, method, created_by, created_at) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
+	if err := tx.QueryRowContext(ctx, query, lc.ASNID, lc.Description, lc.TotalCost, lc.Method, lc.CreatedBy, lc.CreatedAt).Scan(&lc.ID); err != nil {
+		return models.LandedCost{}, err
+	}
+
+	// Update products in id order so concurrent allocations cannot deadlock.
+	sort.Slice(lc.Allocations, func(i, j int) bool { return lc.Allocations[i].ProductID < lc.Allocations[j].ProductID })
+	for i := range lc.Allocations {
+		a := &lc.Allocations[i]
+		var quantity int
+		err := tx.QueryRowContext(ctx, `SELECT cost, quantity FROM products WHERE id = $1 FOR UPDATE`, a.ProductID).Scan(&a.UnitCostBefore, &quantity)
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.LandedCost{}, fmt.Errorf("%w: product %d", ErrProductNotFound, a.ProductID)
+		}
+		if err != nil {
+			return models.LandedCost{}, err
+		}
+		a.UnitCostAfter = landedUnitCost(a.UnitCostBefore, a.AllocatedCost, quantity, a.Received)
+		if _, err := tx.ExecContext(ctx, `UPDATE products SET cost = $1, updated_at = $2 WHERE id = $3`, a.UnitCostAfter, lc.CreatedAt, a.ProductID); err != nil {
+			return models.LandedCost{}, err
+		}
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO landed_cost_allocations (landed_cost_id, product_id, received, basis, allocated_cost, unit_cost_before, unit_cost_after)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)`, lc.ID, a.ProductID, a.Received, a.Basis, a.AllocatedCost, a.UnitCostBefore, a.UnitCostAfter)
+		if err != nil {
+			return models.LandedCost{}, err
+		}
+	}
+
+	return lc, tx.Commit()
+}
+
+func (r *PostgresLandedCostRepository) List(asnID int) ([]models.LandedCost, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, asn_id, description, total_cost, method, created_by, created_at
+		FROM landed_costs WHERE asn_id = $1 ORDER BY created_at, id`, asnID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	costs := []models.LandedCost{}
+	for rows.Next() {
+		var lc models.LandedCost
+		if err := rows.Scan(&lc.ID, &lc.ASNID, &lc.Description, &lc.TotalCost, &lc.Method, &lc.CreatedBy, &lc.CreatedAt); err != nil {
+			return nil, err
+		}
+		lc.CreatedAt = lc.CreatedAt.UTC()
+		costs = append(costs, lc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for i := range costs {
+		if costs[i].Allocations, err = landedCostAllocations(ctx, r.db, costs[i].ID); err != nil {
+			return nil, err
+		}
+	}
+	return costs, nil
+}
+
+func landedCostAllocations(ctx context.Context, q queryer, landedCostID int) ([]models.LandedCostAllocation, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT product_id, received, basis, allocated_cost, unit_cost_before, unit_cost_after
+		FROM landed_cost_allocations WHERE landed_cost_id = $1 ORDER BY product_id`, landedCostID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	allocations := []models.LandedCostAllocation{}
+	for rows.Next() {
+		var a models.LandedCostAllocation
+		if err := rows.Scan(&a.ProductID, &a.Received, &a.Basis, &a.AllocatedCost, &a.UnitCostBefore, &a.UnitCostAfter); err != nil {
+			return nil, err
+		}
+		allocations = append(allocations, a)
+	}
+	return allocations, rows.Err()
+}
@@ -0,0 +1,27 @@
+package repo
+
+import (
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+// LandedCostRepository defines the interface for landed costs of receipts.
+type LandedCostRepository interface {
+	// Create records the landed cost and, in the same transaction, adds each
+	// allocation to its product's unit cost, spread over the units on hand
+	// (or over the units received when none are left). It fills in the unit
+	// costs before and after. It fails with ErrProductNotFound if a product
+	// is gone.
+	Create(lc models.LandedCost) (models.LandedCost, error)
+	// List returns the landed costs of an ASN, oldest first.
+	List(asnID int) ([]models.LandedCost, error)
+}
+
+// landedUnitCost is cost after adding allocated spread over quantity units,
+// or over received when quantity is not positive.
+func landedUnitCost(cost, allocated float64, quantity, received int) float64 {
+	units := quantity
+	if units <= 0 {
+		units = received
+	}
+	return roundCents(cost + allocated/float64(units))
+}
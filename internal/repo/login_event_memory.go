@@ -0,0 +1,64 @@
+package repo
+
+import (
+	"sync"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+type InMemoryLoginEventRepository struct {
+	mu     sync.Mutex
+	events []models.LoginEvent
+	nextID int
+}
+
+var _ LoginEventRepository = (*InMemoryLoginEventRepository)(nil)
+
+func NewInMemoryLoginEventRepository() *InMemoryLoginEventRepository {
+	return &InMemoryLoginEventRepository{nextID: 1}
+}
+
+func (r *InMemoryLoginEventRepository) Record(e models.LoginEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now().UTC()
+	}
+	e.ID = r.nextID
+	r.nextID++
+	r.events = append(r.events, e)
+	return nil
+}
+
+func (r *InMemoryLoginEventRepository) List(username string, limit, offset int) ([]models.LoginEvent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	events := []models.LoginEvent{}
+	for i := len(r.events) - 1; i >= 0; i-- {
+		if r.events[i].Username == username {
+			events = append(events, r.events[i])
+		}
+	}
+	if limit > 0 {
+		events = events[min(offset, len(events)):]
+		events = events[:min(limit, len(events))]
+	}
+	return events, nil
+}
+
+func (r *InMemoryLoginEventRepository) DeleteByUsername(username string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	kept := r.events[:0]
+	for _, e := range r.events {
+		if e.Username != username {
+			kept = append(kept, e)
+		}
+	}
+	r.events = kept
+	return nil
+}
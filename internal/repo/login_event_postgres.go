@@ -0,0 +1,77 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+type PostgresLoginEventRepository struct {
+	db *sql.DB
+}
+
+var _ LoginEventRepository = (*PostgresLoginEventRepository)(nil)
+
+func NewPostgresLoginEventRepository(db *sql.DB) *PostgresLoginEventRepository {
+	return &PostgresLoginEventRepository{db: db}
+}
+
+func (r *PostgresLoginEventRepository) Record(e models.LoginEvent) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now().UTC()
+	}
+	query := `
+		INSERT INTO login_events (username, ip_address, user_agent, outcome, reason, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	if _, err := r.db.ExecContext(ctx, query, e.Username, e.IPAddress, e.UserAgent, e.Outcome, e.Reason, e.CreatedAt); err != nil {
+		return fmt.Errorf("failed to record login event: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresLoginEventRepository) List(username string, limit, offset int) ([]models.LoginEvent, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT id, username, ip_address, user_agent, outcome, reason, created_at
+		FROM login_events
+		WHERE username = $1
+		ORDER BY created_at DESC, id DESC
+	`
+	args := []any{username}
+	if limit > 0 {
+		query += ` LIMIT $2 OFFSET $3`
+		args = append(args, limit, offset)
+	}
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []models.LoginEvent{}
+	for rows.Next() {
+		var e models.LoginEvent
+		if err := rows.Scan(&e.ID, &e.Username, &e.IPAddress, &e.UserAgent, &e.Outcome, &e.Reason, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+func (r *PostgresLoginEventRepository) DeleteByUsername(username string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `DELETE FROM login_events WHERE username = $1`, username)
+	return err
+}
@@ -0,0 +1,13 @@
+package repo
+
+import "github.com/rogerio-castellano/inventory-tracker/internal/models"
+
+// LoginEventRepository defines the interface for the login history.
+type LoginEventRepository interface {
+	Record(e models.LoginEvent) error
+	// List returns the attempts made under username, newest first. A zero
+	// limit means no limit.
+	List(username string, limit, offset int) ([]models.LoginEvent, error)
+	// DeleteByUsername forgets every attempt made under username.
+	DeleteByUsername(username string) error
+}
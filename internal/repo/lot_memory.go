@@ -0,0 +1,86 @@
+package repo
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+type InMemoryLotRepository struct {
+	mu     sync.Mutex
+	lots   []models.Lot
+	nextID int
+}
+
+var _ LotRepository = (*InMemoryLotRepository)(nil)
+
+func NewInMemoryLotRepository() *InMemoryLotRepository {
+	return &InMemoryLotRepository{nextID: 1}
+}
+
+func (r *InMemoryLotRepository) Create(l models.Lot) (models.Lot, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.lots {
+		if existing.ProductID == l.ProductID && existing.LotNumber == l.LotNumber {
+			return models.Lot{}, fmt.Errorf("%w: lot %q", ErrDuplicatedValueUnique, l.LotNumber)
+		}
+	}
+	l.ID = r.nextID
+	l.CreatedAt = time.Now().UTC()
+	r.nextID++
+	r.lots = append(r.lots, l)
+	return l, nil
+}
+
+func (r *InMemoryLotRepository) GetByID(id int) (models.Lot, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, l := range r.lots {
+		if l.ID == id {
+			return l, nil
+		}
+	}
+	return models.Lot{}, ErrLotNotFound
+}
+
+func (r *InMemoryLotRepository) GetByProductID(productID int) ([]models.Lot, error) {
+	return r.filter(func(l models.Lot) bool { return l.ProductID == productID }), nil
+}
+
+func (r *InMemoryLotRepository) UpdateQuantity(id, quantity int) (models.Lot, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, l := range r.lots {
+		if l.ID == id {
+			r.lots[i].Quantity = quantity
+			return r.lots[i], nil
+		}
+	}
+	return models.Lot{}, ErrLotNotFound
+}
+
+func (r *InMemoryLotRepository) GetExpiringBefore(t time.Time) ([]models.Lot, error) {
+	return r.filter(func(l models.Lot) bool { return l.Quantity > 0 && l.ExpiresAt.Before(t) }), nil
+}
+
+// filter returns matching lots sorted by expiry.
+func (r *InMemoryLotRepository) filter(keep func(models.Lot) bool) []models.Lot {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	lots := []models.Lot{}
+	for _, l := range r.lots {
+		if keep(l) {
+			lots = append(lots, l)
+		}
+	}
+	sort.SliceStable(lots, func(i, j int) bool { return lots[i].ExpiresAt.Before(lots[j].ExpiresAt) })
+	return lots
+}
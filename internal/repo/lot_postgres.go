@@ -0,0 +1,98 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+type PostgresLotRepository struct {
+	db *sql.DB
+}
+
+var _ LotRepository = (*PostgresLotRepository)(nil)
+
+func NewPostgresLotRepository(db *sql.DB) *PostgresLotRepository {
+	return &PostgresLotRepository{db: db}
+}
+
+const lotColumns = `id, product_id, lot_number, quantity, expires_at, created_at`
+
+func scanLot(row rowScanner) (models.Lot, error) {
+	var l models.Lot
+	err := row.Scan(&l.ID, &l.ProductID, &l.LotNumber, &l.Quantity, &l.ExpiresAt, &l.CreatedAt)
+	l.ExpiresAt = l.ExpiresAt.UTC()
+	return l, err
+}
+
+func (r *PostgresLotRepository) Create(l models.Lot) (models.Lot, error) {
+	query := `INSERT INTO product_lots (product_id, lot_number, quantity, expires_at, created_at) VALUES ($1, $2, $3, $4, $5) RETURNING id`
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	l.CreatedAt = time.Now().UTC()
+	err := r.db.QueryRowContext(ctx, query, l.ProductID, l.LotNumber, l.Quantity, l.ExpiresAt, l.CreatedAt).Scan(&l.ID)
+	if err != nil {
+		if strings.Contains(err.Error(), "23505") {
+			err = fmt.Errorf("%w: %v", ErrDuplicatedValueUnique, err)
+		}
+		return models.Lot{}, err
+	}
+	return l, nil
+}
+
+func (r *PostgresLotRepository) GetByID(id int) (models.Lot, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	l, err := scanLot(r.db.QueryRowContext(ctx, `SELECT `+lotColumns+` FROM product_lots WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Lot{}, ErrLotNotFound
+	}
+	return l, err
+}
+
+func (r *PostgresLotRepository) GetByProductID(productID int) ([]models.Lot, error) {
+	return r.query(`SELECT `+lotColumns+` FROM product_lots WHERE product_id = $1 ORDER BY expires_at, id`, productID)
+}
+
+func (r *PostgresLotRepository) UpdateQuantity(id, quantity int) (models.Lot, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	l, err := scanLot(r.db.QueryRowContext(ctx, `UPDATE product_lots SET quantity = $1 WHERE id = $2 RETURNING `+lotColumns, quantity, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Lot{}, ErrLotNotFound
+	}
+	return l, err
+}
+
+func (r *PostgresLotRepository) GetExpiringBefore(t time.Time) ([]models.Lot, error) {
+	return r.query(`SELECT `+lotColumns+` FROM product_lots WHERE quantity > 0 AND expires_at < $1 ORDER BY expires_at, id`, t)
+}
+
+func (r *PostgresLotRepository) query(query string, args ...any) ([]models.Lot, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lots := []models.Lot{}
+	for rows.Next() {
+		l, err := scanLot(rows)
+		if err != nil {
+			return nil, err
+		}
+		lots = append(lots, l)
+	}
+	return lots, rows.Err()
+}
@@ -0,0 +1,21 @@
+package repo
+
+import (
+	"errors"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+// LotRepository defines the interface for product lot data operations.
+type LotRepository interface {
+	Create(l models.Lot) (models.Lot, error)
+	GetByID(id int) (models.Lot, error)
+	GetByProductID(productID int) ([]models.Lot, error)
+	UpdateQuantity(id, quantity int) (models.Lot, error)
+	// GetExpiringBefore returns lots with stock left that expire before the
+	// given time, soonest first, including ones already expired.
+	GetExpiringBefore(t time.Time) ([]models.Lot, error)
+}
+
+var ErrLotNotFound = errors.New("lot not found")
@@ -0,0 +1,137 @@
+package repo
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+type InMemoryMappingRepository struct {
+	mu       sync.Mutex
+	mappings []models.ProductMapping
+	nextID   int
+}
+
+var _ MappingRepository = (*InMemoryMappingRepository)(nil)
+
+func NewInMemoryMappingRepository() *InMemoryMappingRepository {
+	return &InMemoryMappingRepository{nextID: 1}
+}
+
+func (r *InMemoryMappingRepository) Create(m models.ProductMapping) (models.ProductMapping, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.indexOf(m.System, m.ExternalCode) >= 0 {
+		return models.ProductMapping{}, fmt.Errorf("%w: %s code %q", ErrDuplicatedValueUnique, m.System, m.ExternalCode)
+	}
+	m.ID = r.nextID
+	m.CreatedAt = time.Now().UTC()
+	m.UpdatedAt = m.CreatedAt
+	r.nextID++
+	r.mappings = append(r.mappings, m)
+	return m, nil
+}
+
+func (r *InMemoryMappingRepository) GetByID(id int) (models.ProductMapping, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, m := range r.mappings {
+		if m.ID == id {
+			return m, nil
+		}
+	}
+	return models.ProductMapping{}, ErrMappingNotFound
+}
+
+func (r *InMemoryMappingRepository) List(productID int, system string) ([]models.ProductMapping, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	mappings := []models.ProductMapping{}
+	for _, m := range r.mappings {
+		if (productID == 0 || m.ProductID == productID) && (system == "" || m.System == system) {
+			mappings = append(mappings, m)
+		}
+	}
+	sort.Slice(mappings, func(i, j int) bool {
+		if mappings[i].System != mappings[j].System {
+			return mappings[i].System < mappings[j].System
+		}
+		return mappings[i].ExternalCode < mappings[j].ExternalCode
+	})
+	return mappings, nil
+}
+
+func (r *InMemoryMappingRepository) Resolve(system, externalCode string) (models.ProductMapping, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if i := r.indexOf(system, externalCode); i >= 0 {
+		return r.mappings[i], nil
+	}
+	return models.ProductMapping{}, ErrMappingNotFound
+}
+
+func (r *InMemoryMappingRepository) Update(m models.ProductMapping) (models.ProductMapping, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if i := r.indexOf(m.System, m.ExternalCode); i >= 0 && r.mappings[i].ID != m.ID {
+		return models.ProductMapping{}, fmt.Errorf("%w: %s code %q", ErrDuplicatedValueUnique, m.System, m.ExternalCode)
+	}
+	for i, existing := range r.mappings {
+		if existing.ID == m.ID {
+			m.CreatedAt = existing.CreatedAt
+			m.UpdatedAt = time.Now().UTC()
+			r.mappings[i] = m
+			return m, nil
+		}
+	}
+	return models.ProductMapping{}, ErrMappingNotFound
+}
+
+func (r *InMemoryMappingRepository) Delete(id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, m := range r.mappings {
+		if m.ID == id {
+			r.mappings = append(r.mappings[:i], r.mappings[i+1:]...)
+			return nil
+		}
+	}
+	return ErrMappingNotFound
+}
+
+func (r *InMemoryMappingRepository) Upsert(mappings []models.ProductMapping) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now().UTC()
+	for _, m := range mappings {
+		if i := r.indexOf(m.System, m.ExternalCode); i >= 0 {
+			r.mappings[i].ProductID = m.ProductID
+			r.mappings[i].UpdatedAt = now
+			continue
+		}
+		m.ID = r.nextID
+		m.CreatedAt, m.UpdatedAt = now, now
+		r.nextID++
+		r.mappings = append(r.mappings, m)
+	}
+	return len(mappings), nil
+}
+
+func (r *InMemoryMappingRepository) indexOf(system, externalCode string) int {
+	for i, m := range r.mappings {
+		if m.System == system && m.ExternalCode == externalCode {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,163 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+type PostgresMappingRepository struct {
+	db *sql.DB
+}
+
+var _ MappingRepository = (*PostgresMappingRepository)(nil)
+
+func NewPostgresMappingRepository(db *sql.DB) *PostgresMappingRepository {
+	return &PostgresMappingRepository{db: db}
+}
+
+const mappingColumns = `id, product_id, system, external_code, created_at, updated_at`
+
+func scanMapping(row rowScanner) (models.ProductMapping, error) {
+	var m models.ProductMapping
+	err := row.Scan(&m.ID, &m.ProductID, &m.System, &m.ExternalCode, &m.CreatedAt, &m.UpdatedAt)
+	m.CreatedAt, m.UpdatedAt = m.CreatedAt.UTC(), m.UpdatedAt.UTC()
+	return m, err
+}
+
+// mappingWriteError translates constraint violations into repository errors.
+func mappingWriteError(err error) error {
+	switch {
+	case strings.Contains(err.Error(), "23505"):
+		return fmt.Errorf("%w: %v", ErrDuplicatedValueUnique, err)
+	case strings.Contains(err.Error(), "23503"):
+		return fmt.Errorf("%w: %v", ErrProductNotFound, err)
+	}
+	return err
+}
+
+func (r *PostgresMappingRepository) Create(m models.ProductMapping) (models.ProductMapping, error) {
+	query := `INSERT INTO product_mappings (product_id, system, external_code, created_at, updated_at) VALUES ($1, $2, $3, $4, $4) RETURNING id`
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	m.CreatedAt = time.Now().UTC()
+	m.UpdatedAt = m.CreatedAt
+	if err := r.db.QueryRowContext(ctx, query, m.ProductID, m.System, m.ExternalCode, m.CreatedAt).Scan(&m.ID); err != nil {
+		return models.ProductMapping{}, mappingWriteError(err)
+	}
+	return m, nil
+}
+
+func (r *PostgresMappingRepository) GetByID(id int) (models.ProductMapping, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	m, err := scanMapping(r.db.QueryRowContext(ctx, `SELECT `+mappingColumns+` FROM product_mappings WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.ProductMapping{}, ErrMappingNotFound
+	}
+	return m, err
+}
+
+func (r *PostgresMappingRepository) List(productID int, system string) ([]models.ProductMapping, error) {
+	query := `SELECT ` + mappingColumns + ` FROM product_mappings
+		WHERE ($1 = 0 OR product_id = $1) AND ($2 = '' OR system = $2)
+		ORDER BY system, external_code`
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, productID, system)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	mappings := []models.ProductMapping{}
+	for rows.Next() {
+		m, err := scanMapping(rows)
+		if err != nil {
+			return nil, err
+		}
+		mappings = append(mappings, m)
+	}
+	return mappings, rows.Err()
+}
+
+func (r *PostgresMappingRepository) Resolve(system, externalCode string) (models.ProductMapping, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	m, err := scanMapping(r.db.QueryRowContext(ctx, `SELECT `+mappingColumns+` FROM product_mappings WHERE system = $1 AND external_code = $2`, system, externalCode))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.ProductMapping{}, ErrMappingNotFound
+	}
+	return m, err
+}
+
+func (r *PostgresMappingRepository) Update(m models.ProductMapping) (models.ProductMapping, error) {
+	query := `UPDATE product_mappings SET product_id = $1, system = $2, external_code = $3, updated_at = $4
+		WHERE id = $5 RETURNING ` + mappingColumns
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	updated, err := scanMapping(r.db.QueryRowContext(ctx, query, m.ProductID, m.System, m.ExternalCode, time.Now().UTC(), m.ID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.ProductMapping{}, ErrMappingNotFound
+	}
+	if err != nil {
+		return models.ProductMapping{}, mappingWriteError(err)
+	}
+	return updated, nil
+}
+
+func (r *PostgresMappingRepository) Delete(id int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, `DELETE FROM product_mappings WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrMappingNotFound
+	}
+	return nil
+}
+
+func (r *PostgresMappingRepository) Upsert(mappings []models.ProductMapping) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), bulkTimeout)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `INSERT INTO product_mappings (product_id, system, external_code, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $4)
+		ON CONFLICT (system, external_code) DO UPDATE SET product_id = EXCLUDED.product_id, updated_at = EXCLUDED.updated_at`)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	now := time.Now().UTC()
+	for _, m := range mappings {
+		if _, err := stmt.ExecContext(ctx, m.ProductID, m.System, m.ExternalCode, now); err != nil {
+			return 0, mappingWriteError(err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return len(mappings), nil
+}
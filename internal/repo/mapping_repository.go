@@ -0,0 +1,25 @@
+package repo
+
+import (
+	"errors"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+// MappingRepository defines the interface for external system code mappings.
+type MappingRepository interface {
+	Create(m models.ProductMapping) (models.ProductMapping, error)
+	GetByID(id int) (models.ProductMapping, error)
+	// List returns mappings ordered by system and code; a zero productID or an
+	// empty system leaves that filter out.
+	List(productID int, system string) ([]models.ProductMapping, error)
+	// Resolve returns the mapping holding the given code in the given system.
+	Resolve(system, externalCode string) (models.ProductMapping, error)
+	Update(m models.ProductMapping) (models.ProductMapping, error)
+	Delete(id int) error
+	// Upsert creates or re-points every mapping in one transaction, keyed by
+	// system and code, and returns how many rows it wrote.
+	Upsert(mappings []models.ProductMapping) (int, error)
+}
+
+var ErrMappingNotFound = errors.New("mapping not found")
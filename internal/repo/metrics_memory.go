@@ -9,75 +9,146 @@ type InMemoryMetricsRepository struct {
 	movementRepo MovementRepository
 }
 
+var _ MetricsRepository = (*InMemoryMetricsRepository)(nil)
+
 // GetDashboardMetrics implements MetricsRepository.
-func (i *InMemoryMetricsRepository) GetDashboardMetrics() (Metrics, error) {
+func (i *InMemoryMetricsRepository) GetDashboardMetrics(widgets ...string) (Metrics, error) {
 	m := Metrics{}
 
-	// Get total products
 	products, err := i.productRepo.GetAll()
 	if err != nil {
 		return m, err
 	}
-	m.TotalProducts = len(products)
+	counts := make(map[int]int, len(products))
+	if wantsWidget(widgets, WidgetSummary) || wantsWidget(widgets, WidgetTopMovers) || wantsWidget(widgets, WidgetLowMargin) {
+		for _, product := range products {
+			_, count, err := i.movementRepo.GetByProductID(product.ID, MovementFilter{})
+			if err != nil {
+				return m, err
+			}
+			counts[product.ID] = count
+		}
+	}
 
-	// Get total movements
-	for _, product := range products {
-		_, count, err := i.movementRepo.GetByProductID(product.ID, MovementFilter{})
-		if err != nil {
-			return m, err
+	if wantsWidget(widgets, WidgetSummary) {
+		m.TotalProducts = len(products)
+		for _, product := range products {
+			m.TotalMovements += counts[product.ID]
+			m.TotalQuantity += product.Quantity
 		}
-		m.TotalMovements += count
-		// Get most moved product
-		if count > m.MostMovedProduct.MovementCount {
-			m.MostMovedProduct.Name = product.Name
-			m.MostMovedProduct.MovementCount = count
+	}
+
+	if wantsWidget(widgets, WidgetLowStock) {
+		for _, product := range products {
+			if product.Quantity < product.Threshold {
+				m.LowStockCount++
+			}
 		}
 	}
 
-	// Get low stock count
-	for _, product := range products {
-		if product.Quantity < product.Threshold {
-			m.LowStockCount++
+	if wantsWidget(widgets, WidgetValuation) {
+		totalPrice := 0.0
+		totalUnitPrice := 0.0
+		byCurrency := map[string]*CurrencyStock{}
+		for _, product := range products {
+			totalUnitPrice += product.Price
+			totalPrice += product.Price * float64(product.Quantity)
+
+			s, ok := byCurrency[product.Currency]
+			if !ok {
+				s = &CurrencyStock{Currency: product.Currency}
+				byCurrency[product.Currency] = s
+			}
+			s.Products++
+			s.AveragePrice += product.Price
+			s.StockValue += product.Price * float64(product.Quantity)
 		}
+		m.AveragePrice = totalUnitPrice / float64(len(products))
+		m.TotalStockValue = totalPrice
+		for _, s := range byCurrency {
+			s.AveragePrice /= float64(s.Products)
+			m.StockByCurrency = append(m.StockByCurrency, *s)
+		}
+		sort.Slice(m.StockByCurrency, func(a, b int) bool { return m.StockByCurrency[a].Currency < m.StockByCurrency[b].Currency })
 	}
 
-	// Get average price, total stock value, and total quantity
-	totalPrice := 0.0
-	totalQuantity := 0
-	totalUnitPrice := 0.0
-	for _, product := range products {
-		totalUnitPrice += product.Price
-		totalPrice += product.Price * float64(product.Quantity)
-		totalQuantity += product.Quantity
+	if wantsWidget(widgets, WidgetStockLoad) {
+		for _, product := range products {
+			if product.Quantity > 0 {
+				m.TotalStockWeight += max(product.Weight, 0) * float64(product.Quantity)
+				m.TotalStockVolume += product.Dimensions.Volume() * float64(product.Quantity)
+			}
+		}
+		m.TotalStockWeight, m.TotalStockVolume = roundMeasure(m.TotalStockWeight), roundMeasure(m.TotalStockVolume)
 	}
-	m.TotalQuantity = totalQuantity
-	m.AveragePrice = totalUnitPrice / float64(len(products))
-	m.TotalStockValue = totalPrice
 
-	m.Top5Movers = make([]TopMover, 0, 5)
+	if wantsWidget(widgets, WidgetTopMovers) {
+		m.Top5Movers = make([]TopMover, 0, 5)
+		for _, product := range products {
+			count := counts[product.ID]
+			// Get most moved product
+			if count > m.MostMovedProduct.MovementCount {
+				m.MostMovedProduct.Name = product.Name
+				m.MostMovedProduct.MovementCount = count
+			}
 
-	for _, product := range products {
-		_, count, err := i.movementRepo.GetByProductID(product.ID, MovementFilter{})
-		if err != nil {
-			return m, err
-		}
+			// Insert the new mover
+			m.Top5Movers = append(m.Top5Movers, TopMover{Name: product.Name, Count: count})
 
-		// Insert the new mover
-		m.Top5Movers = append(m.Top5Movers, TopMover{Name: product.Name, Count: count})
+			// Sort and keep only top 5
+			sort.Slice(m.Top5Movers, func(i, j int) bool {
+				return m.Top5Movers[i].Count > m.Top5Movers[j].Count
+			})
 
-		// Sort and keep only top 5
-		sort.Slice(m.Top5Movers, func(i, j int) bool {
-			return m.Top5Movers[i].Count > m.Top5Movers[j].Count
-		})
+			if len(m.Top5Movers) > 5 {
+				m.Top5Movers = m.Top5Movers[:5]
+			}
+		}
+	}
 
-		if len(m.Top5Movers) > 5 {
-			m.Top5Movers = m.Top5Movers[:5]
+	if wantsWidget(widgets, WidgetLowMargin) {
+		for _, product := range products {
+			if product.Cost <= 0 {
+				continue
+			}
+			if count := counts[product.ID]; count > 0 {
+				m.LowMarginMovers = append(m.LowMarginMovers, LowMarginMover{Name: product.Name, MarginPercent: marginPercent(product.Price, product.Cost), Count: count})
+			}
+		}
+		sort.SliceStable(m.LowMarginMovers, func(a, b int) bool {
+			x, y := m.LowMarginMovers[a], m.LowMarginMovers[b]
+			if x.MarginPercent != y.MarginPercent {
+				return x.MarginPercent < y.MarginPercent
+			}
+			return x.Count > y.Count
+		})
+		if len(m.LowMarginMovers) > 5 {
+			m.LowMarginMovers = m.LowMarginMovers[:5]
 		}
 	}
 
 	return m, nil
 }
 
+// GetMarginReport implements MetricsRepository.
+func (i *InMemoryMetricsRepository) GetMarginReport() (MarginReport, error) {
+	products, err := i.productRepo.GetAll()
+	if err != nil {
+		return MarginReport{}, err
+	}
+	return buildMarginReport(products, nil), nil
+}
+
+// GetStockLoadReport implements MetricsRepository. The in-memory repository
+// keeps no bins, so all stock is reported as unplaced.
+func (i *InMemoryMetricsRepository) GetStockLoadReport() (StockLoadReport, error) {
+	products, err := i.productRepo.GetAll()
+	if err != nil {
+		return StockLoadReport{}, err
+	}
+	return buildStockLoadReport(products, nil), nil
+}
+
 func NewInMemoryMetricsRepository() *InMemoryMetricsRepository {
 	return &InMemoryMetricsRepository{}
 }
@@ -4,41 +4,47 @@ import (
 	"context"
 	"database/sql"
 	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
 )
 
 type PostgresMetricsRepository struct {
 	db *sql.DB
 }
 
+var _ MetricsRepository = (*PostgresMetricsRepository)(nil)
+
 func NewPostgresMetricsRepository(db *sql.DB) *PostgresMetricsRepository {
 	return &PostgresMetricsRepository{db: db}
 }
 
-func (r *PostgresMetricsRepository) GetDashboardMetrics() (Metrics, error) {
+func (r *PostgresMetricsRepository) GetDashboardMetrics(widgets ...string) (Metrics, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
 	var m Metrics
 
-	_ = r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM products`).Scan(&m.TotalProducts)
-	_ = r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM movements`).Scan(&m.TotalMovements)
-	_ = r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM products WHERE quantity < threshold`).Scan(&m.LowStockCount)
-
-	_ = r.db.QueryRowContext(ctx, `
-		SELECT p.name, COUNT(*) as cnt
-		FROM movements m
-		JOIN products p ON m.product_id = p.id
-		GROUP BY p.name
-		ORDER BY cnt DESC
-		LIMIT 1
-	`).Scan(&m.MostMovedProduct.Name, &m.MostMovedProduct.MovementCount)
-
-	_ = r.db.QueryRowContext(ctx, `SELECT COALESCE(AVG(price), 0) FROM products`).Scan(&m.AveragePrice)
-	_ = r.db.QueryRowContext(ctx, `SELECT COALESCE(SUM(price * quantity), 0) FROM products`).Scan(&m.TotalStockValue)
-	_ = r.db.QueryRowContext(ctx, `SELECT COALESCE(SUM(quantity), 0) FROM products`).Scan(&m.TotalQuantity)
-
-	// Top 5 movers
-	rows, _ := r.db.QueryContext(ctx, `
+	if wantsWidget(widgets, WidgetSummary) {
+		_ = r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM products`).Scan(&m.TotalProducts)
+		_ = r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM movements`).Scan(&m.TotalMovements)
+		_ = r.db.QueryRowContext(ctx, `SELECT COALESCE(SUM(quantity), 0) FROM products`).Scan(&m.TotalQuantity)
+	}
+	if wantsWidget(widgets, WidgetLowStock) {
+		_ = r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM products WHERE quantity < threshold`).Scan(&m.LowStockCount)
+	}
+
+	if wantsWidget(widgets, WidgetTopMovers) {
+		_ = r.db.QueryRowContext(ctx, `
+			SELECT p.name, COUNT(*) as cnt
+			FROM movements m
+			JOIN products p ON m.product_id = p.id
+			GROUP BY p.name
+			ORDER BY cnt DESC
+			LIMIT 1
+		`).Scan(&m.MostMovedProduct.Name, &m.MostMovedProduct.MovementCount)
+
+		// Top 5 movers
+		rows, err := r.db.QueryContext(ctx, `
 			SELECT p.name, COUNT(*) AS cnt
 			FROM movements m
 			JOIN products p ON p.id = m.product_id
@@ -46,12 +52,147 @@ func (r *PostgresMetricsRepository) GetDashboardMetrics() (Metrics, error) {
 			ORDER BY cnt DESC
 			LIMIT 5
 		`)
+		if err == nil {
+			defer rows.Close()
+			for rows.Next() {
+				var mover TopMover
+				_ = rows.Scan(&mover.Name, &mover.Count)
+				m.Top5Movers = append(m.Top5Movers, mover)
+			}
+		}
+	}
+
+	if wantsWidget(widgets, WidgetValuation) {
+		_ = r.db.QueryRowContext(ctx, `SELECT COALESCE(AVG(price), 0) FROM products`).Scan(&m.AveragePrice)
+		_ = r.db.QueryRowContext(ctx, `
+			SELECT COALESCE(SUM(p.price * GREATEST(p.quantity - COALESCE(c.quantity, 0), 0)), 0)
+			FROM products p
+			LEFT JOIN consignment_stock c ON c.product_id = p.id
+		`).Scan(&m.TotalStockValue)
+		byCurrency, err := r.db.QueryContext(ctx, `
+			SELECT p.currency, COUNT(*), AVG(p.price), COALESCE(SUM(p.price * GREATEST(p.quantity - COALESCE(c.quantity, 0), 0)), 0)
+			FROM products p
+			LEFT JOIN consignment_stock c ON c.product_id = p.id
+			GROUP BY p.currency
+			ORDER BY p.currency
+		`)
+		if err == nil {
+			defer byCurrency.Close()
+			for byCurrency.Next() {
+				var s CurrencyStock
+				_ = byCurrency.Scan(&s.Currency, &s.Products, &s.AveragePrice, &s.StockValue)
+				m.StockByCurrency = append(m.StockByCurrency, s)
+			}
+		}
+	}
+
+	if wantsWidget(widgets, WidgetStockLoad) {
+		_ = r.db.QueryRowContext(ctx, `
+			SELECT
+				COALESCE(SUM(GREATEST(quantity, 0) * GREATEST(weight, 0)), 0),
+				COALESCE(SUM(GREATEST(quantity, 0) * length * width * height) FILTER (WHERE length > 0 AND width > 0 AND height > 0), 0) / 1e6
+			FROM products
+		`).Scan(&m.TotalStockWeight, &m.TotalStockVolume)
+		m.TotalStockWeight, m.TotalStockVolume = roundMeasure(m.TotalStockWeight), roundMeasure(m.TotalStockVolume)
+	}
+
+	if wantsWidget(widgets, WidgetLowMargin) {
+		lowMargin, err := r.db.QueryContext(ctx, `
+			SELECT p.name, ROUND((p.price - p.cost) / p.price * 100, 2) AS margin, COUNT(*) AS cnt
+			FROM movements m
+			JOIN products p ON p.id = m.product_id
+			WHERE p.cost > 0
+			GROUP BY p.id, p.name, p.price, p.cost
+			ORDER BY margin, cnt DESC
+			LIMIT 5
+		`)
+		if err == nil {
+			defer lowMargin.Close()
+			for lowMargin.Next() {
+				var mover LowMarginMover
+				_ = lowMargin.Scan(&mover.Name, &mover.MarginPercent, &mover.Count)
+				m.LowMarginMovers = append(m.LowMarginMovers, mover)
+			}
+		}
+	}
+
+	return m, nil
+}
+
+func (r *PostgresMetricsRepository) GetMarginReport() (MarginReport, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), bulkTimeout)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT p.id, p.name, p.category, p.price, p.cost, p.quantity, COALESCE(c.quantity, 0)
+		FROM products p
+		LEFT JOIN consignment_stock c ON c.product_id = p.id
+		ORDER BY p.id`)
+	if err != nil {
+		return MarginReport{}, err
+	}
 	defer rows.Close()
+
+	var products []models.Product
+	consigned := map[int]int{}
 	for rows.Next() {
-		var mover TopMover
-		_ = rows.Scan(&mover.Name, &mover.Count)
-		m.Top5Movers = append(m.Top5Movers, mover)
+		var p models.Product
+		var units int
+		if err := rows.Scan(&p.ID, &p.Name, &p.Category, &p.Price, &p.Cost, &p.Quantity, &units); err != nil {
+			return MarginReport{}, err
+		}
+		products = append(products, p)
+		consigned[p.ID] = units
 	}
+	if err := rows.Err(); err != nil {
+		return MarginReport{}, err
+	}
+	return buildMarginReport(products, consigned), nil
+}
 
-	return m, nil
+// GetStockLoadReport implements MetricsRepository.
+func (r *PostgresMetricsRepository) GetStockLoadReport() (StockLoadReport, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), bulkTimeout)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `SELECT id, quantity, weight, length, width, height FROM products ORDER BY id`)
+	if err != nil {
+		return StockLoadReport{}, err
+	}
+	defer rows.Close()
+
+	var products []models.Product
+	for rows.Next() {
+		var p models.Product
+		if err := rows.Scan(&p.ID, &p.Quantity, &p.Weight, &p.Dimensions.Length, &p.Dimensions.Width, &p.Dimensions.Height); err != nil {
+			return StockLoadReport{}, err
+		}
+		products = append(products, p)
+	}
+	if err := rows.Err(); err != nil {
+		return StockLoadReport{}, err
+	}
+
+	bins, err := r.db.QueryContext(ctx, `SELECT warehouse, product_id, SUM(quantity) FROM product_bins GROUP BY warehouse, product_id`)
+	if err != nil {
+		return StockLoadReport{}, err
+	}
+	defer bins.Close()
+
+	placed := map[string]map[int]int{}
+	for bins.Next() {
+		var warehouse string
+		var productID, units int
+		if err := bins.Scan(&warehouse, &productID, &units); err != nil {
+			return StockLoadReport{}, err
+		}
+		if placed[warehouse] == nil {
+			placed[warehouse] = map[int]int{}
+		}
+		placed[warehouse][productID] = units
+	}
+	if err := bins.Err(); err != nil {
+		return StockLoadReport{}, err
+	}
+	return buildStockLoadReport(products, placed), nil
 }
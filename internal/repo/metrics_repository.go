@@ -1,5 +1,13 @@
 package repo
 
+import (
+	"cmp"
+	"math"
+	"slices"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
 type MostMovedProduct struct {
 	Name          string `json:"name"`
 	MovementCount int    `json:"movement_count"`
@@ -10,17 +18,307 @@ type TopMover struct {
 	Count int    `json:"count"`
 }
 
+// LowMarginMover is a product that keeps moving although it earns little.
+type LowMarginMover struct {
+	Name          string  `json:"name"`
+	MarginPercent float64 `json:"margin_percent"`
+	Count         int     `json:"count"`
+}
+
+// CurrencyStock is the share of the dashboard's money figures priced in one
+// currency, in that currency. An empty Currency is the base currency.
+type CurrencyStock struct {
+	Currency     string  `json:"currency"`
+	Products     int     `json:"products"`
+	AveragePrice float64 `json:"average_price"`
+	StockValue   float64 `json:"stock_value"` // owned stock at sale price
+}
+
 type Metrics struct {
 	TotalProducts    int              `json:"total_products"`
 	TotalMovements   int              `json:"total_movements"`
 	LowStockCount    int              `json:"low_stock_count"`
 	MostMovedProduct MostMovedProduct `json:"most_moved_product"`
 	AveragePrice     float64          `json:"average_price"`
-	TotalStockValue  float64          `json:"total_stock_value"`
+	TotalStockValue  float64          `json:"total_stock_value"` // owned stock at sale price
 	TotalQuantity    int              `json:"total_quantity"`
 	Top5Movers       []TopMover       `json:"top_5_movers"`
+	// TotalStockWeight and TotalStockVolume cover every unit on hand,
+	// consigned ones included; products without a weight or dimensions add
+	// nothing.
+	TotalStockWeight float64 `json:"total_stock_weight_kg"`
+	TotalStockVolume float64 `json:"total_stock_volume_m3"`
+	// LowMarginMovers are the five lowest-margin products with movements;
+	// products without a cost are left out.
+	LowMarginMovers []LowMarginMover `json:"low_margin_movers"`
+	// StockByCurrency splits AveragePrice and TotalStockValue, which add up
+	// prices as they are stored, by the currency products are priced in.
+	StockByCurrency []CurrencyStock `json:"stock_by_currency"`
+
+	// MovementLogFailures is filled in by the API process, not the repository:
+	// it counts adjustments whose movement row could not be persisted.
+	MovementLogFailures int64 `json:"movement_log_failures"`
+	// ExpiringSoonCount is also filled in by the API process: lots with stock
+	// left that expire within the default expiry window, expired ones included.
+	ExpiringSoonCount int `json:"expiring_soon_count"`
+	// Currency is also filled in by the API process, which converts
+	// AveragePrice and TotalStockValue into it.
+	Currency string `json:"currency,omitempty"`
+	// Trends is also filled in by the API process, from the movements and
+	// the daily snapshots.
+	Trends *Trends `json:"trends,omitempty"`
+}
+
+// Trend compares a figure over the last seven days with the seven before.
+type Trend struct {
+	Current  float64 `json:"current"`
+	Previous float64 `json:"previous"`
+	// ChangePercent is nil when Previous is zero.
+	ChangePercent *float64 `json:"change_percent"`
+	Direction     string   `json:"direction"` // up, down or flat
+}
+
+// Trends are the week-over-week changes the dashboard shows. A trend is
+// nil when there is nothing a week old to compare with.
+type Trends struct {
+	// MovementVolume is the units moved in or out.
+	MovementVolume *Trend `json:"movement_volume,omitempty"`
+	// StockValue is the total value of the latest daily snapshot against
+	// the one taken a week before it.
+	StockValue *Trend `json:"stock_value,omitempty"`
+}
+
+// NewTrend compares current with previous.
+func NewTrend(current, previous float64) *Trend {
+	t := &Trend{Current: current, Previous: previous, Direction: "flat"}
+	switch {
+	case current > previous:
+		t.Direction = "up"
+	case current < previous:
+		t.Direction = "down"
+	}
+	if previous != 0 {
+		change := roundCents((current - previous) / math.Abs(previous) * 100)
+		t.ChangePercent = &change
+	}
+	return t
+}
+
+// Dashboard widgets: each names a group of Metrics fields computed together.
+const (
+	WidgetSummary   = "summary"    // TotalProducts, TotalMovements, TotalQuantity
+	WidgetLowStock  = "low_stock"  // LowStockCount
+	WidgetTopMovers = "top_movers" // MostMovedProduct, Top5Movers
+	WidgetValuation = "valuation"  // AveragePrice, TotalStockValue, StockByCurrency
+	WidgetStockLoad = "stock_load" // TotalStockWeight, TotalStockVolume
+	WidgetLowMargin = "low_margin" // LowMarginMovers
+)
+
+// DashboardWidgets lists the widgets GetDashboardMetrics can compute.
+var DashboardWidgets = []string{WidgetSummary, WidgetLowStock, WidgetTopMovers, WidgetValuation, WidgetStockLoad, WidgetLowMargin}
+
+// wantsWidget reports whether name is among widgets, an empty list meaning
+// all of them.
+func wantsWidget(widgets []string, name string) bool {
+	return len(widgets) == 0 || slices.Contains(widgets, name)
+}
+
+// ProductMargin is what a product earns per unit and on its current stock.
+type ProductMargin struct {
+	ProductID       int     `json:"product_id"`
+	Name            string  `json:"name"`
+	Category        string  `json:"category"`
+	Price           float64 `json:"price"`
+	Cost            float64 `json:"cost"`
+	Quantity        int     `json:"quantity"`
+	UnitMargin      float64 `json:"unit_margin"`
+	MarginPercent   float64 `json:"margin_percent"` // of the sale price
+	ProjectedProfit float64 `json:"projected_profit"`
+}
+
+type CategoryMargin struct {
+	Category        string  `json:"category"`
+	Products        int     `json:"products"`
+	StockValue      float64 `json:"stock_value"`
+	StockCost       float64 `json:"stock_cost"`
+	ProjectedProfit float64 `json:"projected_profit"`
+	MarginPercent   float64 `json:"margin_percent"`
+}
+
+// MarginReport covers products with a known cost; the others are only
+// counted, since their margin would be meaningless. Stock figures cover
+// owned stock: consigned units still belong to the supplier.
+type MarginReport struct {
+	StockValue       float64          `json:"stock_value"`
+	StockCost        float64          `json:"stock_cost"`
+	ProjectedProfit  float64          `json:"projected_profit"`
+	MarginPercent    float64          `json:"margin_percent"`
+	UncostedProducts int              `json:"uncosted_products"`
+	ConsignedUnits   int              `json:"consigned_units"`
+	Categories       []CategoryMargin `json:"categories"`
+	Products         []ProductMargin  `json:"products"` // lowest margin first
+}
+
+// WarehouseLoad is the weight and volume of the units placed in one
+// warehouse's bins. An empty warehouse is single-site stock.
+type WarehouseLoad struct {
+	Warehouse string  `json:"warehouse"`
+	Units     int     `json:"units"`
+	Weight    float64 `json:"weight_kg"`
+	Volume    float64 `json:"volume_m3"`
+}
+
+// StockLoadReport is the physical size of the stock on hand. Units not
+// placed in any bin are reported as unplaced. Products without a weight, or
+// without all three dimensions, are counted and add nothing to that figure.
+type StockLoadReport struct {
+	Units              int             `json:"units"`
+	Weight             float64         `json:"weight_kg"`
+	Volume             float64         `json:"volume_m3"`
+	Warehouses         []WarehouseLoad `json:"warehouses"`
+	Unplaced           WarehouseLoad   `json:"unplaced"`
+	UnweighedProducts  int             `json:"unweighed_products"`
+	UnmeasuredProducts int             `json:"unmeasured_products"`
 }
 
 type MetricsRepository interface {
-	GetDashboardMetrics() (Metrics, error)
+	// GetDashboardMetrics fills in the fields of the given widgets, or of
+	// every widget when none is given.
+	GetDashboardMetrics(widgets ...string) (Metrics, error)
+	GetMarginReport() (MarginReport, error)
+	GetStockLoadReport() (StockLoadReport, error)
+}
+
+// buildMarginReport computes margins in Go so both repositories agree on
+// rounding and on how uncosted products are treated. consigned holds the
+// consigned units of each product, which are left out of its quantity.
+func buildMarginReport(products []models.Product, consigned map[int]int) MarginReport {
+	report := MarginReport{Categories: []CategoryMargin{}, Products: []ProductMargin{}}
+	categories := map[string]*CategoryMargin{}
+	for _, p := range products {
+		if units := min(consigned[p.ID], max(p.Quantity, 0)); units > 0 {
+			report.ConsignedUnits += units
+			p.Quantity -= units
+		}
+		if p.Cost <= 0 {
+			report.UncostedProducts++
+			continue
+		}
+		value, cost := p.Price*float64(p.Quantity), p.Cost*float64(p.Quantity)
+		report.Products = append(report.Products, ProductMargin{
+			ProductID:       p.ID,
+			Name:            p.Name,
+			Category:        p.Category,
+			Price:           p.Price,
+			Cost:            p.Cost,
+			Quantity:        p.Quantity,
+			UnitMargin:      roundCents(p.Price - p.Cost),
+			MarginPercent:   marginPercent(p.Price, p.Cost),
+			ProjectedProfit: roundCents(value - cost),
+		})
+
+		c, ok := categories[p.Category]
+		if !ok {
+			c = &CategoryMargin{Category: p.Category}
+			categories[p.Category] = c
+		}
+		c.Products++
+		c.StockValue += value
+		c.StockCost += cost
+		report.StockValue += value
+		report.StockCost += cost
+	}
+
+	for _, c := range categories {
+		c.ProjectedProfit = roundCents(c.StockValue - c.StockCost)
+		c.MarginPercent = marginPercent(c.StockValue, c.StockCost)
+		c.StockValue, c.StockCost = roundCents(c.StockValue), roundCents(c.StockCost)
+		report.Categories = append(report.Categories, *c)
+	}
+	slices.SortFunc(report.Categories, func(a, b CategoryMargin) int { return cmp.Compare(a.Category, b.Category) })
+	slices.SortStableFunc(report.Products, func(a, b ProductMargin) int { return cmp.Compare(a.MarginPercent, b.MarginPercent) })
+
+	report.ProjectedProfit = roundCents(report.StockValue - report.StockCost)
+	report.MarginPercent = marginPercent(report.StockValue, report.StockCost)
+	report.StockValue, report.StockCost = roundCents(report.StockValue), roundCents(report.StockCost)
+	return report
+}
+
+// buildStockLoadReport sums weight and volume in Go for the same reason as
+// buildMarginReport. placed holds, per warehouse, the units of each product
+// in its bins; whatever a product holds beyond them is unplaced.
+func buildStockLoadReport(products []models.Product, placed map[string]map[int]int) StockLoadReport {
+	report := StockLoadReport{Warehouses: []WarehouseLoad{}}
+	byID := make(map[int]models.Product, len(products))
+	unplaced := make(map[int]int, len(products))
+	for _, p := range products {
+		byID[p.ID] = p
+		unplaced[p.ID] = max(p.Quantity, 0)
+		if p.Quantity <= 0 {
+			continue
+		}
+		if p.Weight <= 0 {
+			report.UnweighedProducts++
+		}
+		if p.Dimensions.Volume() == 0 {
+			report.UnmeasuredProducts++
+		}
+	}
+
+	for warehouse, units := range placed {
+		load := WarehouseLoad{Warehouse: warehouse}
+		for id, n := range units {
+			p, ok := byID[id]
+			if !ok || n <= 0 {
+				continue
+			}
+			n = min(n, unplaced[id])
+			unplaced[id] -= n
+			load.add(p, n)
+		}
+		report.Warehouses = append(report.Warehouses, load.rounded())
+	}
+	slices.SortFunc(report.Warehouses, func(a, b WarehouseLoad) int { return cmp.Compare(a.Warehouse, b.Warehouse) })
+
+	for id, n := range unplaced {
+		report.Unplaced.add(byID[id], n)
+	}
+	report.Units = report.Unplaced.Units
+	report.Weight, report.Volume = report.Unplaced.Weight, report.Unplaced.Volume
+	for _, w := range report.Warehouses {
+		report.Units += w.Units
+		report.Weight += w.Weight
+		report.Volume += w.Volume
+	}
+	report.Unplaced = report.Unplaced.rounded()
+	report.Weight, report.Volume = roundMeasure(report.Weight), roundMeasure(report.Volume)
+	return report
+}
+
+func (l *WarehouseLoad) add(p models.Product, units int) {
+	l.Units += units
+	l.Weight += max(p.Weight, 0) * float64(units)
+	l.Volume += p.Dimensions.Volume() * float64(units)
+}
+
+func (l WarehouseLoad) rounded() WarehouseLoad {
+	l.Weight, l.Volume = roundMeasure(l.Weight), roundMeasure(l.Volume)
+	return l
+}
+
+func marginPercent(price, cost float64) float64 {
+	if price == 0 {
+		return 0
+	}
+	return roundCents((price - cost) / price * 100)
+}
+
+func roundCents(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// roundMeasure rounds kilograms and cubic metres to three decimals: grams
+// and litres.
+func roundMeasure(v float64) float64 {
+	return math.Round(v*1000) / 1000
 }
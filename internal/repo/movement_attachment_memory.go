@@ -0,0 +1,74 @@
+package repo
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+type InMemoryMovementAttachmentRepository struct {
+	mu          sync.Mutex
+	attachments []models.MovementAttachment
+	nextID      int
+	movements   MovementRepository
+}
+
+var _ MovementAttachmentRepository = (*InMemoryMovementAttachmentRepository)(nil)
+
+func NewInMemoryMovementAttachmentRepository(movements MovementRepository) *InMemoryMovementAttachmentRepository {
+	return &InMemoryMovementAttachmentRepository{nextID: 1, movements: movements}
+}
+
+func (r *InMemoryMovementAttachmentRepository) Create(a models.MovementAttachment) (models.MovementAttachment, error) {
+	if _, err := r.movements.GetByID(a.MovementID); err != nil {
+		return models.MovementAttachment{}, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	a.ID = r.nextID
+	a.CreatedAt = time.Now().UTC()
+	r.nextID++
+	r.attachments = append(r.attachments, a)
+	return a, nil
+}
+
+func (r *InMemoryMovementAttachmentRepository) GetByID(id int) (models.MovementAttachment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, a := range r.attachments {
+		if a.ID == id {
+			return a, nil
+		}
+	}
+	return models.MovementAttachment{}, ErrAttachmentNotFound
+}
+
+func (r *InMemoryMovementAttachmentRepository) GetByMovementID(movementID int) ([]models.MovementAttachment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	attachments := []models.MovementAttachment{}
+	for _, a := range r.attachments {
+		if a.MovementID == movementID {
+			attachments = append(attachments, a)
+		}
+	}
+	return attachments, nil
+}
+
+func (r *InMemoryMovementAttachmentRepository) Delete(id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := slices.IndexFunc(r.attachments, func(a models.MovementAttachment) bool { return a.ID == id })
+	if i < 0 {
+		return ErrAttachmentNotFound
+	}
+	r.attachments = slices.Delete(r.attachments, i, i+1)
+	return nil
+}
@@ -0,0 +1,96 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+type PostgresMovementAttachmentRepository struct {
+	db *sql.DB
+}
+
+var _ MovementAttachmentRepository = (*PostgresMovementAttachmentRepository)(nil)
+
+func NewPostgresMovementAttachmentRepository(db *sql.DB) *PostgresMovementAttachmentRepository {
+	return &PostgresMovementAttachmentRepository{db: db}
+}
+
+const attachmentColumns = `id, movement_id, filename, content_type, size, storage_key, description, uploaded_by, created_at`
+
+func scanAttachment(row rowScanner) (models.MovementAttachment, error) {
+	var a models.MovementAttachment
+	err := row.Scan(&a.ID, &a.MovementID, &a.Filename, &a.ContentType, &a.Size, &a.StorageKey, &a.Description, &a.UploadedBy, &a.CreatedAt)
+	a.CreatedAt = a.CreatedAt.UTC()
+	return a, err
+}
+
+func (r *PostgresMovementAttachmentRepository) Create(a models.MovementAttachment) (models.MovementAttachment, error) {
+	query := `
+		INSERT INTO movement_attachments (movement_id, filename, content_type, size, storage_key, description, uploaded_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id
+	`
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	a.CreatedAt = time.Now().UTC()
+	err := r.db.QueryRowContext(ctx, query, a.MovementID, a.Filename, a.ContentType, a.Size, a.StorageKey, a.Description, a.UploadedBy, a.CreatedAt).Scan(&a.ID)
+	if err != nil {
+		if strings.Contains(err.Error(), "23503") {
+			return models.MovementAttachment{}, ErrMovementNotFound
+		}
+		return models.MovementAttachment{}, err
+	}
+	return a, nil
+}
+
+func (r *PostgresMovementAttachmentRepository) GetByID(id int) (models.MovementAttachment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	a, err := scanAttachment(r.db.QueryRowContext(ctx, `SELECT `+attachmentColumns+` FROM movement_attachments WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.MovementAttachment{}, ErrAttachmentNotFound
+	}
+	return a, err
+}
+
+func (r *PostgresMovementAttachmentRepository) GetByMovementID(movementID int) ([]models.MovementAttachment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `SELECT `+attachmentColumns+` FROM movement_attachments WHERE movement_id = $1 ORDER BY id`, movementID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	attachments := []models.MovementAttachment{}
+	for rows.Next() {
+		a, err := scanAttachment(rows)
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, a)
+	}
+	return attachments, rows.Err()
+}
+
+func (r *PostgresMovementAttachmentRepository) Delete(id int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, `DELETE FROM movement_attachments WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrAttachmentNotFound
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+package repo
+
+import (
+	"errors"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+// MovementAttachmentRepository defines the interface for movement attachment metadata.
+type MovementAttachmentRepository interface {
+	// Create fails with ErrMovementNotFound if the movement does not exist.
+	Create(a models.MovementAttachment) (models.MovementAttachment, error)
+	GetByID(id int) (models.MovementAttachment, error)
+	GetByMovementID(movementID int) ([]models.MovementAttachment, error)
+	Delete(id int) error
+}
+
+var ErrAttachmentNotFound = errors.New("attachment not found")
@@ -1,11 +1,13 @@
-// internal/models/filter.go
 package repo
 
 import "time"
 
+// MovementFilter narrows and paginates movement queries. A nil field means
+// "no constraint"; a Limit of zero asks for the total count only.
 type MovementFilter struct {
 	Since  *time.Time
 	Until  *time.Time
+	Reason string
 	Offset *int
 	Limit  *int
 }
@@ -1,6 +1,8 @@
 package repo
 
 import (
+	"fmt"
+	"sort"
 	"time"
 
 	"github.com/rogerio-castellano/inventory-tracker/internal/models"
@@ -10,6 +12,8 @@ type InMemoryMovementRepository struct {
 	movements []models.Movement
 }
 
+var _ MovementRepository = (*InMemoryMovementRepository)(nil)
+
 func (r *InMemoryMovementRepository) AddMovement(movement models.Movement) {
 
 	r.movements = append(r.movements, movement)
@@ -22,43 +26,162 @@ func NewInMemoryMovementRepository() *InMemoryMovementRepository {
 }
 
 // Log inserts a new inventory movement
-func (r *InMemoryMovementRepository) Log(productID, delta int) error {
-	movement := models.Movement{
-		ID:        len(r.movements) + 1,
-		ProductID: productID,
-		Delta:     delta,
-		CreatedAt: time.Now().Format(time.RFC3339),
+func (r *InMemoryMovementRepository) Log(m models.Movement) (models.Movement, error) {
+	createdAt, err := movementTime(m)
+	if err != nil {
+		return models.Movement{}, err
 	}
-	r.movements = append(r.movements, movement)
-	return nil
+
+	if _, err := r.GetByExternalID(m.ExternalID); err == nil {
+		return models.Movement{}, fmt.Errorf("%w: %s", ErrDuplicatedExternalID, m.ExternalID)
+	}
+
+	m.ID = len(r.movements) + 1
+	m.CreatedAt = createdAt.Format(time.RFC3339)
+	r.movements = append(r.movements, m)
+	return m, nil
+}
+
+// GetByID returns a single movement
+func (r *InMemoryMovementRepository) GetByID(id int) (models.Movement, error) {
+	for _, m := range r.movements {
+		if m.ID == id {
+			return m, nil
+		}
+	}
+	return models.Movement{}, ErrMovementNotFound
+}
+
+// GetByExternalID returns the movement logged with a client-chosen ID
+func (r *InMemoryMovementRepository) GetByExternalID(externalID string) (models.Movement, error) {
+	for _, m := range r.movements {
+		if externalID != "" && m.ExternalID == externalID {
+			return m, nil
+		}
+	}
+	return models.Movement{}, ErrMovementNotFound
+}
+
+// GetBetween returns every movement, across all products, created within [since, until)
+func (r *InMemoryMovementRepository) GetBetween(since, until time.Time) ([]models.Movement, error) {
+	movements := []models.Movement{}
+	for _, m := range r.movements {
+		createdAt, err := time.Parse(time.RFC3339, m.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		if !createdAt.Before(since) && createdAt.Before(until) {
+			movements = append(movements, m)
+		}
+	}
+	return movements, nil
+}
+
+func (r *InMemoryMovementRepository) GetHistory(productID int) ([]models.Movement, error) {
+	movements := []models.Movement{}
+	for _, m := range r.movements {
+		if m.ProductID == productID {
+			movements = append(movements, m)
+		}
+	}
+	sort.SliceStable(movements, func(i, j int) bool { return movements[i].CreatedAt < movements[j].CreatedAt })
+	return movements, nil
+}
+
+// SumDeltasByProduct returns the sum of all movement deltas keyed by product ID
+func (r *InMemoryMovementRepository) SumDeltasByProduct() (map[int]int, error) {
+	sums := map[int]int{}
+	for _, m := range r.movements {
+		sums[m.ProductID] += m.Delta
+	}
+	return sums, nil
+}
+
+func (r *InMemoryMovementRepository) Stats(productID int, since time.Time) (MovementStats, error) {
+	var stats MovementStats
+	for _, m := range r.movements {
+		if m.ProductID != productID {
+			continue
+		}
+		createdAt, err := time.Parse(time.RFC3339, m.CreatedAt)
+		if err != nil {
+			return MovementStats{}, err
+		}
+		stats.Count++
+		switch {
+		case m.Delta > 0:
+			stats.Inbound++
+		case m.Delta < 0:
+			stats.Outbound++
+			if !createdAt.Before(since) {
+				stats.UnitsOutSince -= m.Delta
+			}
+		}
+		if stats.LastMovementAt == nil || createdAt.After(*stats.LastMovementAt) {
+			stats.LastMovementAt = &createdAt
+		}
+	}
+	return stats, nil
 }
 
-// GetByProductID returns all movements for a specific product, optionally filtered by date range and paginated
+// GetByProductID returns all movements for a specific product, optionally filtered by date range and paginated.
+// Pagination mirrors PostgresMovementRepository: newest first, a zero limit returns only the total,
+// and the page size is capped at defaultLimit.
 func (r *InMemoryMovementRepository) GetByProductID(productID int, mf MovementFilter) ([]models.Movement, int, error) {
-	var filtered []models.Movement
+	filtered := []models.Movement{}
 	for _, m := range r.movements {
 		if m.ProductID == productID {
 			if (mf.Since != nil && m.CreatedAt < mf.Since.Format(time.RFC3339)) ||
-				(mf.Until != nil && m.CreatedAt > mf.Until.Format(time.RFC3339)) {
+				(mf.Until != nil && m.CreatedAt > mf.Until.Format(time.RFC3339)) ||
+				(mf.Reason != "" && m.Reason != mf.Reason) {
 				continue
 			}
 			filtered = append(filtered, m)
 		}
 	}
 
-	if mf.Offset != nil && *mf.Offset > len(filtered) {
-		return nil, 0, nil // If offset is greater than the number of filtered products, return empty slice
+	sort.SliceStable(filtered, func(i, j int) bool {
+		return filtered[i].CreatedAt > filtered[j].CreatedAt
+	})
+
+	total := len(filtered)
+
+	if mf.Limit != nil && *mf.Limit == 0 {
+		return []models.Movement{}, total, nil
+	}
+
+	if mf.Offset != nil && *mf.Offset < 0 {
+		return nil, 0, fmt.Errorf("offset must be non-negative")
+	}
+
+	if mf.Offset != nil && *mf.Offset >= total {
+		return []models.Movement{}, total, nil
 	}
 
 	start := 0
 	if mf.Offset != nil {
-		start = clamp(*mf.Offset, 0, len(filtered))
+		start = clamp(*mf.Offset, 0, total)
 	}
 
-	end := len(filtered)
+	limit := defaultLimit
 	if mf.Limit != nil && *mf.Limit > 0 {
-		end = clamp(start+*mf.Limit, start, len(filtered))
+		limit = min(*mf.Limit, defaultLimit)
 	}
+	end := clamp(start+limit, start, total)
 
-	return filtered[start:end], len(filtered), nil
+	return filtered[start:end], total, nil
+}
+
+func (r *InMemoryMovementRepository) LogBatch(movements []models.Movement) (int, error) {
+	for _, m := range movements {
+		if _, err := movementTime(m); err != nil {
+			return 0, err
+		}
+	}
+	for _, m := range movements {
+		if _, err := r.Log(m); err != nil {
+			return 0, err
+		}
+	}
+	return len(movements), nil
 }
@@ -3,28 +3,159 @@ package repo
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/rogerio-castellano/inventory-tracker/internal/models"
 )
 
+const movementColumns = `id, product_id, delta, created_at, work_order_id, external_id, warehouse_id, reason, note`
+
+// scanMovement reads movementColumns, followed by any extra selected columns.
+func scanMovement(row rowScanner, extra ...any) (models.Movement, error) {
+	var m models.Movement
+	var workOrderID sql.NullInt64
+	var externalID sql.NullString
+	var warehouseID sql.NullInt64
+	dest := []any{&m.ID, &m.ProductID, &m.Delta, &m.CreatedAt, &workOrderID, &externalID, &warehouseID, &m.Reason, &m.Note}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return models.Movement{}, err
+	}
+	if workOrderID.Valid {
+		id := int(workOrderID.Int64)
+		m.WorkOrderID = &id
+	}
+	if warehouseID.Valid {
+		id := int(warehouseID.Int64)
+		m.WarehouseID = &id
+	}
+	m.ExternalID = externalID.String
+	return m, nil
+}
+
 type PostgresMovementRepository struct {
 	db *sql.DB
 }
 
+var _ MovementRepository = (*PostgresMovementRepository)(nil)
+
 func NewPostgresMovementRepository(db *sql.DB) *PostgresMovementRepository {
 	return &PostgresMovementRepository{db: db}
 }
 
-// Log inserts a new inventory movement
-func (r *PostgresMovementRepository) Log(productID, delta int) error {
-	query := `INSERT INTO movements (product_id, delta, created_at, updated_at) VALUES ($1, $2, $3, $4)`
+// Log inserts a new inventory movement. An empty CreatedAt means "now"; a
+// set one backdates the movement.
+func (r *PostgresMovementRepository) Log(m models.Movement) (models.Movement, error) {
+	createdAt, err := movementTime(m)
+	if err != nil {
+		return models.Movement{}, err
+	}
+
+	query := `INSERT INTO movements (product_id, delta, created_at, updated_at, work_order_id, external_id, warehouse_id, reason, note) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id`
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err = r.db.QueryRowContext(ctx, query, m.ProductID, m.Delta, createdAt, time.Now().UTC(), m.WorkOrderID, nullableExternalID(m.ExternalID), m.WarehouseID, m.Reason, m.Note).Scan(&m.ID)
+	if err != nil {
+		if err := uniqueViolation(err); errors.Is(err, ErrDuplicatedExternalID) {
+			return models.Movement{}, err
+		}
+		return models.Movement{}, fmt.Errorf("%w: %w", ErrMovementLogFailed, err)
+	}
+	m.CreatedAt = createdAt.Format(time.RFC3339)
+	return m, nil
+}
+
+// GetByID returns a single movement
+func (r *PostgresMovementRepository) GetByID(id int) (models.Movement, error) {
+	movements, err := r.executeQuery(`SELECT `+movementColumns+` FROM movements WHERE id = $1`, []any{id})
+	if err != nil {
+		return models.Movement{}, fmt.Errorf("failed to execute query: %w", err)
+	}
+	if len(movements) == 0 {
+		return models.Movement{}, ErrMovementNotFound
+	}
+	return movements[0], nil
+}
+
+// GetByExternalID returns the movement logged with a client-chosen ID
+func (r *PostgresMovementRepository) GetByExternalID(externalID string) (models.Movement, error) {
+	movements, err := r.executeQuery(`SELECT `+movementColumns+` FROM movements WHERE external_id = $1`, []any{externalID})
+	if err != nil {
+		return models.Movement{}, fmt.Errorf("failed to execute query: %w", err)
+	}
+	if len(movements) == 0 {
+		return models.Movement{}, ErrMovementNotFound
+	}
+	return movements[0], nil
+}
+
+// GetBetween returns every movement, across all products, created within [since, until)
+func (r *PostgresMovementRepository) GetBetween(since, until time.Time) ([]models.Movement, error) {
+	query := `SELECT ` + movementColumns + ` FROM movements WHERE created_at >= $1 AND created_at < $2 ORDER BY created_at, id`
+	movements, err := r.executeQuery(query, []any{since, until})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	return movements, nil
+}
+
+func (r *PostgresMovementRepository) GetHistory(productID int) ([]models.Movement, error) {
+	query := `SELECT ` + movementColumns + ` FROM movements WHERE product_id = $1 ORDER BY created_at, id`
+	movements, err := r.executeQuery(query, []any{productID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	return movements, nil
+}
+
+// SumDeltasByProduct returns the sum of all movement deltas keyed by product ID
+func (r *PostgresMovementRepository) SumDeltasByProduct() (map[int]int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `SELECT product_id, COALESCE(SUM(delta), 0) FROM movements GROUP BY product_id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum movements: %w", err)
+	}
+	defer rows.Close()
+
+	sums := map[int]int{}
+	for rows.Next() {
+		var productID, sum int
+		if err := rows.Scan(&productID, &sum); err != nil {
+			return nil, err
+		}
+		sums[productID] = sum
+	}
+
+	return sums, rows.Err()
+}
+
+func (r *PostgresMovementRepository) Stats(productID int, since time.Time) (MovementStats, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	_, err := r.db.ExecContext(ctx, query, productID, delta, time.Now().UTC(), time.Now().UTC())
-	return fmt.Errorf("failed to insert movement: %w", err)
+	var stats MovementStats
+	var last sql.NullTime
+	err := r.db.QueryRowContext(ctx, `
+		SELECT COUNT(*),
+		       COUNT(*) FILTER (WHERE delta > 0),
+		       COUNT(*) FILTER (WHERE delta < 0),
+		       MAX(created_at),
+		       COALESCE(SUM(-delta) FILTER (WHERE delta < 0 AND created_at >= $2), 0)
+		FROM movements WHERE product_id = $1`, productID, since,
+	).Scan(&stats.Count, &stats.Inbound, &stats.Outbound, &last, &stats.UnitsOutSince)
+	if err != nil {
+		return MovementStats{}, fmt.Errorf("failed to summarize movements: %w", err)
+	}
+	if last.Valid {
+		t := last.Time.UTC()
+		stats.LastMovementAt = &t
+	}
+	return stats, nil
 }
 
 const defaultLimit = 100
@@ -84,6 +215,12 @@ func (r *PostgresMovementRepository) buildWhereClause(productID int, mf Movement
 	if mf.Until != nil {
 		whereClause += fmt.Sprintf(" AND created_at <= $%d", argIndex)
 		args = append(args, *mf.Until)
+		argIndex++
+	}
+
+	if mf.Reason != "" {
+		whereClause += fmt.Sprintf(" AND reason = $%d", argIndex)
+		args = append(args, mf.Reason)
 	}
 
 	return whereClause, args
@@ -91,7 +228,7 @@ func (r *PostgresMovementRepository) buildWhereClause(productID int, mf Movement
 
 // buildMainQuery constructs the main SELECT query with pagination
 func (r *PostgresMovementRepository) buildMainQuery(whereClause string, baseArgs []any, mf MovementFilter) (string, []any) {
-	query := fmt.Sprintf("SELECT id, product_id, delta, created_at FROM movements %s ORDER BY created_at DESC", whereClause)
+	query := fmt.Sprintf("SELECT %s FROM movements %s ORDER BY created_at DESC", movementColumns, whereClause)
 	args := make([]any, len(baseArgs))
 	copy(args, baseArgs)
 	argIndex := len(baseArgs) + 1
@@ -143,8 +280,8 @@ func (r *PostgresMovementRepository) executeQuery(query string, args []any) ([]m
 
 	var movements []models.Movement
 	for rows.Next() {
-		var m models.Movement
-		if err := rows.Scan(&m.ID, &m.ProductID, &m.Delta, &m.CreatedAt); err != nil {
+		m, err := scanMovement(rows)
+		if err != nil {
 			return nil, err
 		}
 		movements = append(movements, m)
@@ -157,3 +294,72 @@ func (r *PostgresMovementRepository) executeQuery(query string, args []any) ([]m
 
 	return movements, nil
 }
+
+// LogBatch inserts movements with COPY in a single round trip.
+func (r *PostgresMovementRepository) LogBatch(movements []models.Movement) (int, error) {
+	now := time.Now().UTC()
+	rows := make([][]any, len(movements))
+	for i, m := range movements {
+		createdAt, err := movementTime(m)
+		if err != nil {
+			return 0, err
+		}
+		rows[i] = []any{m.ProductID, m.Delta, createdAt, now, nullableExternalID(m.ExternalID), m.Reason, m.Note}
+	}
+
+	n, err := copyFrom(r.db, "movements", []string{"product_id", "delta", "created_at", "updated_at", "external_id", "reason", "note"}, rows)
+	if err != nil {
+		if err := uniqueViolation(err); errors.Is(err, ErrDuplicatedExternalID) {
+			return 0, err
+		}
+		return 0, fmt.Errorf("%w: %w", ErrMovementLogFailed, err)
+	}
+	return int(n), nil
+}
+
+var _ MovementQueryExplainer = (*PostgresMovementRepository)(nil)
+
+// ExplainGetByProductID runs EXPLAIN ANALYZE on the count and page queries
+// GetByProductID would issue for the same arguments.
+func (r *PostgresMovementRepository) ExplainGetByProductID(productID int, mf MovementFilter) ([]QueryPlan, error) {
+	whereClause, args := r.buildWhereClause(productID, mf)
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM movements %s", whereClause)
+	pageQuery, pageArgs := r.buildMainQuery(whereClause, args, mf)
+
+	plans := make([]QueryPlan, 0, 2)
+	for _, q := range []struct {
+		query string
+		args  []any
+	}{{countQuery, args}, {pageQuery, pageArgs}} {
+		plan, err := r.explain(q.query, q.args)
+		if err != nil {
+			return nil, fmt.Errorf("failed to explain query: %w", err)
+		}
+		plans = append(plans, plan)
+	}
+	return plans, nil
+}
+
+func (r *PostgresMovementRepository) explain(query string, args []any) (QueryPlan, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, "EXPLAIN (ANALYZE, BUFFERS) "+query, args...)
+	if err != nil {
+		return QueryPlan{}, err
+	}
+	defer rows.Close()
+
+	plan := QueryPlan{Query: query, Plan: []string{}}
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return QueryPlan{}, err
+		}
+		if strings.Contains(line, "Index Scan") || strings.Contains(line, "Index Only Scan") || strings.Contains(line, "Bitmap Index Scan") {
+			plan.IndexScan = true
+		}
+		plan.Plan = append(plan.Plan, line)
+	}
+	return plan, rows.Err()
+}
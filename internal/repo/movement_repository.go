@@ -1,10 +1,57 @@
 package repo
 
 import (
+	"errors"
+	"fmt"
+	"time"
+
 	"github.com/rogerio-castellano/inventory-tracker/internal/models"
 )
 
+// MovementRepository defines the interface for movement data operations.
+// All implementations must accept a MovementFilter rather than positional
+// arguments so that filtering behaves identically across backends.
 type MovementRepository interface {
-	Log(productID, delta int) error
+	Log(m models.Movement) (models.Movement, error)
+	GetByID(id int) (models.Movement, error)
+	GetByExternalID(externalID string) (models.Movement, error)
 	GetByProductID(productID int, mf MovementFilter) ([]models.Movement, int, error)
+	GetBetween(since, until time.Time) ([]models.Movement, error)
+	// GetHistory returns every movement of a product, oldest first.
+	GetHistory(productID int) ([]models.Movement, error)
+	SumDeltasByProduct() (map[int]int, error)
+	// Stats summarizes a product's movements, counting the units that went
+	// out since the given time separately.
+	Stats(productID int, since time.Time) (MovementStats, error)
+	// LogBatch inserts all movements or none of them and returns how many were
+	// inserted. Like Log, it does not touch product quantities.
+	LogBatch(movements []models.Movement) (int, error)
+}
+
+// MovementStats summarizes the movements of one product.
+type MovementStats struct {
+	Count          int        `json:"movement_count"`
+	Inbound        int        `json:"inbound_count"`
+	Outbound       int        `json:"outbound_count"`
+	LastMovementAt *time.Time `json:"last_movement_at,omitempty"`
+	// UnitsOutSince is how many units left stock since the time Stats was
+	// asked about.
+	UnitsOutSince int `json:"-"`
+}
+
+var (
+	ErrMovementLogFailed = errors.New("failed to insert movement")
+	ErrMovementNotFound  = errors.New("movement not found")
+)
+
+// movementTime resolves when a movement happened: its CreatedAt if set, now otherwise.
+func movementTime(m models.Movement) (time.Time, error) {
+	if m.CreatedAt == "" {
+		return time.Now().UTC(), nil
+	}
+	t, err := time.Parse(time.RFC3339, m.CreatedAt)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid movement timestamp: %w", err)
+	}
+	return t.UTC(), nil
 }
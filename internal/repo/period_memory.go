@@ -0,0 +1,47 @@
+package repo
+
+import (
+	"sort"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+type InMemoryPeriodRepository struct {
+	periods map[string]models.Period
+}
+
+var _ PeriodRepository = (*InMemoryPeriodRepository)(nil)
+
+func NewInMemoryPeriodRepository() *InMemoryPeriodRepository {
+	return &InMemoryPeriodRepository{
+		periods: map[string]models.Period{},
+	}
+}
+
+func (r *InMemoryPeriodRepository) Close(p models.Period) (models.Period, error) {
+	if _, exists := r.periods[p.Period]; exists {
+		return models.Period{}, ErrPeriodAlreadyClosed
+	}
+	p.ID = len(r.periods) + 1
+	r.periods[p.Period] = p
+	return p, nil
+}
+
+func (r *InMemoryPeriodRepository) GetByPeriod(period string) (models.Period, error) {
+	p, ok := r.periods[period]
+	if !ok {
+		return models.Period{}, ErrPeriodNotFound
+	}
+	return p, nil
+}
+
+func (r *InMemoryPeriodRepository) GetAll() ([]models.Period, error) {
+	periods := make([]models.Period, 0, len(r.periods))
+	for _, p := range r.periods {
+		periods = append(periods, p)
+	}
+	sort.Slice(periods, func(i, j int) bool {
+		return periods[i].Period < periods[j].Period
+	})
+	return periods, nil
+}
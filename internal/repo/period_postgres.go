@@ -0,0 +1,85 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+type PostgresPeriodRepository struct {
+	db *sql.DB
+}
+
+var _ PeriodRepository = (*PostgresPeriodRepository)(nil)
+
+func NewPostgresPeriodRepository(db *sql.DB) *PostgresPeriodRepository {
+	return &PostgresPeriodRepository{db: db}
+}
+
+func (r *PostgresPeriodRepository) Close(p models.Period) (models.Period, error) {
+	frozen, err := json.Marshal(p.Movements)
+	if err != nil {
+		return models.Period{}, fmt.Errorf("failed to freeze movements: %w", err)
+	}
+
+	query := `INSERT INTO accounting_periods (period, closed_by, closed_at, frozen_movements) VALUES ($1, $2, $3, $4) RETURNING id`
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err = r.db.QueryRowContext(ctx, query, p.Period, p.ClosedBy, p.ClosedAt, frozen).Scan(&p.ID)
+	if err != nil {
+		if strings.Contains(err.Error(), "23505") {
+			return models.Period{}, ErrPeriodAlreadyClosed
+		}
+		return models.Period{}, err
+	}
+	return p, nil
+}
+
+func (r *PostgresPeriodRepository) GetByPeriod(period string) (models.Period, error) {
+	query := `SELECT id, period, closed_by, closed_at, frozen_movements FROM accounting_periods WHERE period = $1`
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var p models.Period
+	var frozen []byte
+	err := r.db.QueryRowContext(ctx, query, period).Scan(&p.ID, &p.Period, &p.ClosedBy, &p.ClosedAt, &frozen)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Period{}, ErrPeriodNotFound
+	}
+	if err != nil {
+		return models.Period{}, err
+	}
+	if err := json.Unmarshal(frozen, &p.Movements); err != nil {
+		return models.Period{}, fmt.Errorf("failed to read frozen movements: %w", err)
+	}
+	return p, nil
+}
+
+func (r *PostgresPeriodRepository) GetAll() ([]models.Period, error) {
+	query := `SELECT id, period, closed_by, closed_at FROM accounting_periods ORDER BY period`
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	periods := []models.Period{}
+	for rows.Next() {
+		var p models.Period
+		if err := rows.Scan(&p.ID, &p.Period, &p.ClosedBy, &p.ClosedAt); err != nil {
+			return nil, err
+		}
+		periods = append(periods, p)
+	}
+	return periods, rows.Err()
+}
@@ -0,0 +1,35 @@
+package repo
+
+import (
+	"errors"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+// PeriodRepository defines the interface for accounting period data operations.
+type PeriodRepository interface {
+	Close(p models.Period) (models.Period, error)
+	GetByPeriod(period string) (models.Period, error)
+	GetAll() ([]models.Period, error)
+}
+
+// PeriodLayout is the format of an accounting period identifier (YYYY-MM).
+const PeriodLayout = "2006-01"
+
+var ErrPeriodNotFound = errors.New("accounting period not found")
+var ErrPeriodAlreadyClosed = errors.New("accounting period already closed")
+
+// PeriodOf returns the accounting period a point in time belongs to.
+func PeriodOf(t time.Time) string {
+	return t.UTC().Format(PeriodLayout)
+}
+
+// PeriodBounds returns the [start, end) range covered by an accounting period.
+func PeriodBounds(period string) (time.Time, time.Time, error) {
+	start, err := time.Parse(PeriodLayout, period)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return start, start.AddDate(0, 1, 0), nil
+}
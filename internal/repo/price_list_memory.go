@@ -0,0 +1,168 @@
+package repo
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+type InMemoryPriceListRepository struct {
+	mu       sync.Mutex
+	lists    []models.PriceList
+	prices   map[int]map[int]float64 // list ID -> product ID -> price
+	nextID   int
+	products ProductRepository
+}
+
+var _ PriceListRepository = (*InMemoryPriceListRepository)(nil)
+
+// NewInMemoryPriceListRepository checks products, when given, before
+// setting prices.
+func NewInMemoryPriceListRepository(products ProductRepository) *InMemoryPriceListRepository {
+	return &InMemoryPriceListRepository{prices: map[int]map[int]float64{}, nextID: 1, products: products}
+}
+
+func (r *InMemoryPriceListRepository) nameTaken(name string, exceptID int) bool {
+	for _, l := range r.lists {
+		if l.ID != exceptID && strings.EqualFold(l.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *InMemoryPriceListRepository) indexOf(id int) int {
+	for i, l := range r.lists {
+		if l.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (r *InMemoryPriceListRepository) Create(l models.PriceList) (models.PriceList, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.nameTaken(l.Name, 0) {
+		return models.PriceList{}, fmt.Errorf("%w: price list %q", ErrDuplicatedValueUnique, l.Name)
+	}
+	l.ID = r.nextID
+	l.CreatedAt = time.Now().UTC()
+	l.UpdatedAt = l.CreatedAt
+	r.nextID++
+	r.lists = append(r.lists, l)
+	return l, nil
+}
+
+func (r *InMemoryPriceListRepository) GetByID(id int) (models.PriceList, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if i := r.indexOf(id); i >= 0 {
+		return r.lists[i], nil
+	}
+	return models.PriceList{}, ErrPriceListNotFound
+}
+
+func (r *InMemoryPriceListRepository) GetByName(name string) (models.PriceList, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, l := range r.lists {
+		if strings.EqualFold(l.Name, name) {
+			return l, nil
+		}
+	}
+	return models.PriceList{}, ErrPriceListNotFound
+}
+
+func (r *InMemoryPriceListRepository) List() ([]models.PriceList, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	lists := append([]models.PriceList{}, r.lists...)
+	sort.Slice(lists, func(i, j int) bool { return lists[i].Name < lists[j].Name })
+	return lists, nil
+}
+
+func (r *InMemoryPriceListRepository) Update(l models.PriceList) (models.PriceList, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.nameTaken(l.Name, l.ID) {
+		return models.PriceList{}, fmt.Errorf("%w: price list %q", ErrDuplicatedValueUnique, l.Name)
+	}
+	i := r.indexOf(l.ID)
+	if i < 0 {
+		return models.PriceList{}, ErrPriceListNotFound
+	}
+	l.CreatedAt = r.lists[i].CreatedAt
+	l.UpdatedAt = time.Now().UTC()
+	r.lists[i] = l
+	return l, nil
+}
+
+func (r *InMemoryPriceListRepository) Delete(id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := r.indexOf(id)
+	if i < 0 {
+		return ErrPriceListNotFound
+	}
+	r.lists = append(r.lists[:i], r.lists[i+1:]...)
+	delete(r.prices, id)
+	return nil
+}
+
+func (r *InMemoryPriceListRepository) Prices(listID int) ([]models.PriceListItem, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	items := []models.PriceListItem{}
+	for productID, price := range r.prices[listID] {
+		items = append(items, models.PriceListItem{ProductID: productID, Price: price})
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].ProductID < items[j].ProductID })
+	return items, nil
+}
+
+func (r *InMemoryPriceListRepository) SetPrices(listID int, items []models.PriceListItem) (int, error) {
+	if r.products != nil {
+		for _, item := range items {
+			if _, err := r.products.GetByID(item.ProductID); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.indexOf(listID) < 0 {
+		return 0, ErrPriceListNotFound
+	}
+	if r.prices[listID] == nil {
+		r.prices[listID] = map[int]float64{}
+	}
+	for _, item := range items {
+		r.prices[listID][item.ProductID] = item.Price
+	}
+	return len(items), nil
+}
+
+func (r *InMemoryPriceListRepository) DeletePrice(listID, productID int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.prices[listID][productID]; !ok {
+		return ErrListPriceNotFound
+	}
+	delete(r.prices[listID], productID)
+	return nil
+}
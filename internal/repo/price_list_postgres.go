@@ -0,0 +1,182 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+type PostgresPriceListRepository struct {
+	db *sql.DB
+}
+
+var _ PriceListRepository = (*PostgresPriceListRepository)(nil)
+
+func NewPostgresPriceListRepository(db *sql.DB) *PostgresPriceListRepository {
+	return &PostgresPriceListRepository{db: db}
+}
+
+const priceListColumns = `id, name, description, created_at, updated_at`
+
+func scanPriceList(row rowScanner) (models.PriceList, error) {
+	var l models.PriceList
+	err := row.Scan(&l.ID, &l.Name, &l.Description, &l.CreatedAt, &l.UpdatedAt)
+	l.CreatedAt, l.UpdatedAt = l.CreatedAt.UTC(), l.UpdatedAt.UTC()
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.PriceList{}, ErrPriceListNotFound
+	}
+	return l, err
+}
+
+func (r *PostgresPriceListRepository) Create(l models.PriceList) (models.PriceList, error) {
+	query := `INSERT INTO price_lists (name, description, created_at, updated_at) VALUES ($1, $2, $3, $3) RETURNING id`
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	l.CreatedAt = time.Now().UTC()
+	l.UpdatedAt = l.CreatedAt
+	if err := r.db.QueryRowContext(ctx, query, l.Name, l.Description, l.CreatedAt).Scan(&l.ID); err != nil {
+		return models.PriceList{}, uniqueViolation(err)
+	}
+	return l, nil
+}
+
+func (r *PostgresPriceListRepository) GetByID(id int) (models.PriceList, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return scanPriceList(r.db.QueryRowContext(ctx, `SELECT `+priceListColumns+` FROM price_lists WHERE id = $1`, id))
+}
+
+func (r *PostgresPriceListRepository) GetByName(name string) (models.PriceList, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return scanPriceList(r.db.QueryRowContext(ctx, `SELECT `+priceListColumns+` FROM price_lists WHERE LOWER(name) = LOWER($1)`, name))
+}
+
+func (r *PostgresPriceListRepository) List() ([]models.PriceList, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `SELECT `+priceListColumns+` FROM price_lists ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lists := []models.PriceList{}
+	for rows.Next() {
+		l, err := scanPriceList(rows)
+		if err != nil {
+			return nil, err
+		}
+		lists = append(lists, l)
+	}
+	return lists, rows.Err()
+}
+
+func (r *PostgresPriceListRepository) Update(l models.PriceList) (models.PriceList, error) {
+	query := `UPDATE price_lists SET name = $1, description = $2, updated_at = $3 WHERE id = $4 RETURNING created_at`
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	l.UpdatedAt = time.Now().UTC()
+	err := r.db.QueryRowContext(ctx, query, l.Name, l.Description, l.UpdatedAt, l.ID).Scan(&l.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.PriceList{}, ErrPriceListNotFound
+	}
+	if err != nil {
+		return models.PriceList{}, uniqueViolation(err)
+	}
+	l.CreatedAt = l.CreatedAt.UTC()
+	return l, nil
+}
+
+func (r *PostgresPriceListRepository) Delete(id int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, `DELETE FROM price_lists WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrPriceListNotFound
+	}
+	return nil
+}
+
+func (r *PostgresPriceListRepository) Prices(listID int) ([]models.PriceListItem, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `SELECT product_id, price FROM price_list_items WHERE price_list_id = $1 ORDER BY product_id`, listID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []models.PriceListItem{}
+	for rows.Next() {
+		var item models.PriceListItem
+		if err := rows.Scan(&item.ProductID, &item.Price); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+func (r *PostgresPriceListRepository) SetPrices(listID int, items []models.PriceListItem) (int, error) {
+	query := `
+		INSERT INTO price_list_items (price_list_id, product_id, price, updated_at) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (price_list_id, product_id) DO UPDATE SET price = EXCLUDED.price, updated_at = EXCLUDED.updated_at`
+	ctx, cancel := context.WithTimeout(context.Background(), bulkTimeout)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	now := time.Now().UTC()
+	for _, item := range items {
+		if _, err := stmt.ExecContext(ctx, listID, item.ProductID, item.Price, now); err != nil {
+			if strings.Contains(err.Error(), "23503") {
+				if strings.Contains(err.Error(), "price_list_id") {
+					return 0, fmt.Errorf("%w: %v", ErrPriceListNotFound, err)
+				}
+				return 0, fmt.Errorf("%w: product %d", ErrProductNotFound, item.ProductID)
+			}
+			return 0, err
+		}
+	}
+	return len(items), tx.Commit()
+}
+
+func (r *PostgresPriceListRepository) DeletePrice(listID, productID int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, `DELETE FROM price_list_items WHERE price_list_id = $1 AND product_id = $2`, listID, productID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrListPriceNotFound
+	}
+	return nil
+}
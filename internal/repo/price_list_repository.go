@@ -0,0 +1,29 @@
+package repo
+
+import (
+	"errors"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+// PriceListRepository defines the interface for price lists and their prices.
+type PriceListRepository interface {
+	Create(l models.PriceList) (models.PriceList, error)
+	GetByID(id int) (models.PriceList, error)
+	// GetByName matches names regardless of case.
+	GetByName(name string) (models.PriceList, error)
+	List() ([]models.PriceList, error)
+	Update(l models.PriceList) (models.PriceList, error)
+	// Delete removes the list and every price in it.
+	Delete(id int) error
+	// Prices returns the list's prices ordered by product ID.
+	Prices(listID int) ([]models.PriceListItem, error)
+	// SetPrices creates or replaces the given prices in one transaction and
+	// returns how many it wrote. It fails with ErrProductNotFound, changing
+	// nothing, if any product is missing.
+	SetPrices(listID int, items []models.PriceListItem) (int, error)
+	DeletePrice(listID, productID int) error
+}
+
+var ErrPriceListNotFound = errors.New("price list not found")
+var ErrListPriceNotFound = errors.New("product has no price in this list")
@@ -0,0 +1,39 @@
+package repo
+
+import (
+	"sync"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+type InMemoryPriceRulesRepository struct {
+	mu    sync.Mutex
+	rules map[string]models.PriceRules
+}
+
+var _ PriceRulesRepository = (*InMemoryPriceRulesRepository)(nil)
+
+func NewInMemoryPriceRulesRepository() *InMemoryPriceRulesRepository {
+	return &InMemoryPriceRulesRepository{rules: map[string]models.PriceRules{}}
+}
+
+func (r *InMemoryPriceRulesRepository) Get(tenant string) (models.PriceRules, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, ok := r.rules[tenant]
+	if !ok {
+		return models.PriceRules{Tenant: tenant, Mode: models.PriceRuleModeReject}, nil
+	}
+	return p, nil
+}
+
+func (r *InMemoryPriceRulesRepository) Put(p models.PriceRules) (models.PriceRules, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p.UpdatedAt = time.Now().UTC()
+	r.rules[p.Tenant] = p
+	return p, nil
+}
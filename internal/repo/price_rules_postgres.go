@@ -0,0 +1,52 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+type PostgresPriceRulesRepository struct {
+	db *sql.DB
+}
+
+var _ PriceRulesRepository = (*PostgresPriceRulesRepository)(nil)
+
+func NewPostgresPriceRulesRepository(db *sql.DB) *PostgresPriceRulesRepository {
+	return &PostgresPriceRulesRepository{db: db}
+}
+
+func (r *PostgresPriceRulesRepository) Get(tenant string) (models.PriceRules, error) {
+	query := `SELECT max_price, max_change_percent, currency, mode, updated_at FROM price_rules WHERE tenant = $1`
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	p := models.PriceRules{Tenant: tenant, Mode: models.PriceRuleModeReject}
+	err := r.db.QueryRowContext(ctx, query, tenant).Scan(&p.MaxPrice, &p.MaxChangePercent, &p.Currency, &p.Mode, &p.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return p, nil
+	}
+	if err != nil {
+		return models.PriceRules{}, err
+	}
+	p.UpdatedAt = p.UpdatedAt.UTC()
+	return p, nil
+}
+
+func (r *PostgresPriceRulesRepository) Put(p models.PriceRules) (models.PriceRules, error) {
+	query := `
+		INSERT INTO price_rules (tenant, max_price, max_change_percent, currency, mode, updated_at) VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (tenant) DO UPDATE SET max_price = EXCLUDED.max_price, max_change_percent = EXCLUDED.max_change_percent,
+			currency = EXCLUDED.currency, mode = EXCLUDED.mode, updated_at = EXCLUDED.updated_at`
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	p.UpdatedAt = time.Now().UTC()
+	if _, err := r.db.ExecContext(ctx, query, p.Tenant, p.MaxPrice, p.MaxChangePercent, p.Currency, p.Mode, p.UpdatedAt); err != nil {
+		return models.PriceRules{}, err
+	}
+	return p, nil
+}
@@ -0,0 +1,10 @@
+package repo
+
+import "github.com/rogerio-castellano/inventory-tracker/internal/models"
+
+// PriceRulesRepository stores the price rules of each tenant.
+type PriceRulesRepository interface {
+	// Get returns rules that check nothing when the tenant has none.
+	Get(tenant string) (models.PriceRules, error)
+	Put(p models.PriceRules) (models.PriceRules, error)
+}
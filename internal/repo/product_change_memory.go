@@ -0,0 +1,42 @@
+package repo
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+// InMemoryProductChangeRepository reads from the given product repository,
+// which keeps no history: a product written since sinceSeq is reported as a
+// single change carrying all its fields.
+type InMemoryProductChangeRepository struct {
+	products ProductRepository
+}
+
+var _ ProductChangeRepository = (*InMemoryProductChangeRepository)(nil)
+
+func NewInMemoryProductChangeRepository(products ProductRepository) *InMemoryProductChangeRepository {
+	return &InMemoryProductChangeRepository{products: products}
+}
+
+func (r *InMemoryProductChangeRepository) Since(productID, sinceSeq, limit int) ([]models.ProductChange, error) {
+	p, err := r.products.GetByID(productID)
+	if err != nil {
+		return nil, err
+	}
+	if p.Seq <= sinceSeq || limit <= 0 {
+		return []models.ProductChange{}, nil
+	}
+
+	raw, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+	delete(fields, "seq")
+	return []models.ProductChange{{ProductID: p.ID, Seq: p.Seq, ChangedAt: time.Now().UTC(), Fields: fields}}, nil
+}
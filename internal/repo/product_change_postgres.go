@@ -0,0 +1,49 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+// PostgresProductChangeRepository reads product_changes, which a trigger on
+// products fills on every insert and update, whichever code path wrote them.
+type PostgresProductChangeRepository struct {
+	db *sql.DB
+}
+
+var _ ProductChangeRepository = (*PostgresProductChangeRepository)(nil)
+
+func NewPostgresProductChangeRepository(db *sql.DB) *PostgresProductChangeRepository {
+	return &PostgresProductChangeRepository{db: db}
+}
+
+func (r *PostgresProductChangeRepository) Since(productID, sinceSeq, limit int) ([]models.ProductChange, error) {
+	query := `SELECT seq, changed_at, fields FROM product_changes WHERE product_id = $1 AND seq > $2 ORDER BY seq LIMIT $3`
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, productID, sinceSeq, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := []models.ProductChange{}
+	for rows.Next() {
+		c := models.ProductChange{ProductID: productID}
+		var fields []byte
+		if err := rows.Scan(&c.Seq, &c.ChangedAt, &fields); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(fields, &c.Fields); err != nil {
+			return nil, err
+		}
+		c.ChangedAt = c.ChangedAt.UTC()
+		changes = append(changes, c)
+	}
+	return changes, rows.Err()
+}
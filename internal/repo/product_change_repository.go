@@ -0,0 +1,12 @@
+package repo
+
+import (
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+// ProductChangeRepository reads the writes recorded for each product.
+type ProductChangeRepository interface {
+	// Since returns up to limit changes of the product with a seq above
+	// sinceSeq, oldest first.
+	Since(productID, sinceSeq, limit int) ([]models.ProductChange, error)
+}
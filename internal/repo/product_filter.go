@@ -1,12 +1,73 @@
-// internal/models/filter.go
 package repo
 
+import "time"
+
+// ProductFilter narrows and paginates product queries.
 type ProductFilter struct {
-	Name     string
+	Name string
+	// Query is full-text search over name, SKU and description, tolerant
+	// of typos in the name. With it, Sort defaults to ProductSortRelevance.
+	Query    string
 	MinPrice *float64
 	MaxPrice *float64
 	MinQty   *int
 	MaxQty   *int
-	Offset   *int
-	Limit    *int
+	// LowStock keeps only products below their threshold.
+	LowStock bool
+	// NeedsReview keeps only placeholder products awaiting review.
+	NeedsReview bool
+	// Brand keeps only products of this brand, regardless of case.
+	Brand string
+	// UpdatedSince keeps only products changed at or after this time.
+	UpdatedSince *time.Time
+	// MinHealth and MaxHealth bound the health score; see HealthOf.
+	MinHealth *int
+	MaxHealth *int
+	// Scope keeps only products in the categories the caller was granted.
+	Scope AccessScope
+	// Sort is one of the ProductSort orders; empty means ProductSortID.
+	Sort string
+	// Descending reverses Sort, but not the ranking of Query matches.
+	Descending bool
+	Offset     *int
+	Limit      *int
+	// SkipTotal avoids counting every match. Filter then returns a lower
+	// bound instead of the total: offset + page size, plus one if more
+	// matches follow the page.
+	SkipTotal bool
+}
+
+// Orders ProductFilter.Sort accepts, ascending unless Descending is set.
+// Ties are broken by ID, in the same direction.
+const (
+	ProductSortID              = "id"
+	ProductSortRelevance       = "relevance" // best Query match first; id without one
+	ProductSortName            = "name"      // case-insensitive
+	ProductSortPrice           = "price"
+	ProductSortQuantity        = "quantity"
+	ProductSortUpdatedAt       = "updated_at"  // least recently changed first
+	ProductSortUpdatedAtNewest = "-updated_at" // most recently changed first, whatever Descending says
+	ProductSortHealth          = "health"      // lowest health score, the most in need of attention, first
+)
+
+// ProductSorts lists the orders ProductFilter.Sort accepts.
+var ProductSorts = []string{ProductSortID, ProductSortRelevance, ProductSortName, ProductSortPrice, ProductSortQuantity, ProductSortUpdatedAt, ProductSortUpdatedAtNewest, ProductSortHealth}
+
+// productSortColumns maps the sortable orders to their column. Only these
+// reach the SQL; ProductSortHealth is computed by healthScoreSQL.
+var productSortColumns = map[string]string{
+	ProductSortID:        "id",
+	ProductSortName:      "LOWER(name)",
+	ProductSortPrice:     "price",
+	ProductSortQuantity:  "quantity",
+	ProductSortUpdatedAt: "updated_at",
+}
+
+// sortOrder resolves the ProductSortUpdatedAtNewest alias: it returns the
+// order pf sorts by and whether it is descending.
+func (pf ProductFilter) sortOrder() (string, bool) {
+	if pf.Sort == ProductSortUpdatedAtNewest {
+		return ProductSortUpdatedAt, true
+	}
+	return pf.Sort, pf.Descending
 }
@@ -0,0 +1,79 @@
+package repo
+
+import (
+	"sync"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+type InMemoryProductFlagRepository struct {
+	mu     sync.Mutex
+	flags  []models.ProductFlag
+	nextID int
+}
+
+var _ ProductFlagRepository = (*InMemoryProductFlagRepository)(nil)
+
+func NewInMemoryProductFlagRepository() *InMemoryProductFlagRepository {
+	return &InMemoryProductFlagRepository{nextID: 1}
+}
+
+func (r *InMemoryProductFlagRepository) Create(f models.ProductFlag) (models.ProductFlag, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	f.ID = r.nextID
+	f.Status = models.FlagStatusOpen
+	f.CreatedAt = time.Now().UTC()
+	r.nextID++
+	r.flags = append(r.flags, f)
+	return f, nil
+}
+
+func (r *InMemoryProductFlagRepository) GetByID(id int) (models.ProductFlag, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, f := range r.flags {
+		if f.ID == id {
+			return f, nil
+		}
+	}
+	return models.ProductFlag{}, ErrProductFlagNotFound
+}
+
+func (r *InMemoryProductFlagRepository) List(filter ProductFlagFilter) ([]models.ProductFlag, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	flags := []models.ProductFlag{}
+	for i := len(r.flags) - 1; i >= 0; i-- {
+		f := r.flags[i]
+		if (filter.ProductID == 0 || f.ProductID == filter.ProductID) &&
+			(filter.Source == "" || f.Source == filter.Source) &&
+			(filter.Status == "" || f.Status == filter.Status) {
+			flags = append(flags, f)
+		}
+	}
+	return flags, nil
+}
+
+func (r *InMemoryProductFlagRepository) Close(id int, status, closedBy, note string) (models.ProductFlag, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, f := range r.flags {
+		if f.ID != id {
+			continue
+		}
+		if f.Status != models.FlagStatusOpen {
+			return models.ProductFlag{}, ErrProductFlagClosed
+		}
+		now := time.Now().UTC()
+		f.Status, f.ClosedBy, f.Note, f.ClosedAt = status, closedBy, note, &now
+		r.flags[i] = f
+		return f, nil
+	}
+	return models.ProductFlag{}, ErrProductFlagNotFound
+}
@@ -0,0 +1,116 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+type PostgresProductFlagRepository struct {
+	db *sql.DB
+}
+
+var _ ProductFlagRepository = (*PostgresProductFlagRepository)(nil)
+
+func NewPostgresProductFlagRepository(db *sql.DB) *PostgresProductFlagRepository {
+	return &PostgresProductFlagRepository{db: db}
+}
+
+const productFlagColumns = `id, product_id, source, reason, status, note, closed_by, closed_at, created_at`
+
+func scanProductFlag(row interface{ Scan(...any) error }) (models.ProductFlag, error) {
+	var f models.ProductFlag
+	var closedAt sql.NullTime
+	if err := row.Scan(&f.ID, &f.ProductID, &f.Source, &f.Reason, &f.Status, &f.Note, &f.ClosedBy, &closedAt, &f.CreatedAt); err != nil {
+		return models.ProductFlag{}, err
+	}
+	if closedAt.Valid {
+		f.ClosedAt = &closedAt.Time
+	}
+	return f, nil
+}
+
+func (r *PostgresProductFlagRepository) Create(f models.ProductFlag) (models.ProductFlag, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	f.Status = models.FlagStatusOpen
+	f.CreatedAt = time.Now().UTC()
+	query := `
+		INSERT INTO product_flags (product_id, source, reason, status, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+	if err := r.db.QueryRowContext(ctx, query, f.ProductID, f.Source, f.Reason, f.Status, f.CreatedAt).Scan(&f.ID); err != nil {
+		return models.ProductFlag{}, fmt.Errorf("failed to create product flag: %w", err)
+	}
+	return f, nil
+}
+
+func (r *PostgresProductFlagRepository) GetByID(id int) (models.ProductFlag, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	f, err := scanProductFlag(r.db.QueryRowContext(ctx, `SELECT `+productFlagColumns+` FROM product_flags WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.ProductFlag{}, ErrProductFlagNotFound
+	}
+	return f, err
+}
+
+func (r *PostgresProductFlagRepository) List(filter ProductFlagFilter) ([]models.ProductFlag, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `SELECT ` + productFlagColumns + ` FROM product_flags WHERE 1=1`
+	var args []any
+	if filter.ProductID != 0 {
+		args = append(args, filter.ProductID)
+		query += fmt.Sprintf(` AND product_id = $%d`, len(args))
+	}
+	if filter.Source != "" {
+		args = append(args, filter.Source)
+		query += fmt.Sprintf(` AND source = $%d`, len(args))
+	}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		query += fmt.Sprintf(` AND status = $%d`, len(args))
+	}
+	rows, err := r.db.QueryContext(ctx, query+` ORDER BY created_at DESC, id DESC`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	flags := []models.ProductFlag{}
+	for rows.Next() {
+		f, err := scanProductFlag(rows)
+		if err != nil {
+			return nil, err
+		}
+		flags = append(flags, f)
+	}
+	return flags, rows.Err()
+}
+
+func (r *PostgresProductFlagRepository) Close(id int, status, closedBy, note string) (models.ProductFlag, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		UPDATE product_flags SET status = $2, closed_by = $3, note = $4, closed_at = $5
+		WHERE id = $1 AND status = 'open'
+		RETURNING ` + productFlagColumns
+	f, err := scanProductFlag(r.db.QueryRowContext(ctx, query, id, status, closedBy, note, time.Now().UTC()))
+	if errors.Is(err, sql.ErrNoRows) {
+		if _, err := r.GetByID(id); err != nil {
+			return models.ProductFlag{}, err
+		}
+		return models.ProductFlag{}, ErrProductFlagClosed
+	}
+	return f, err
+}
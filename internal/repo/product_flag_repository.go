@@ -0,0 +1,30 @@
+package repo
+
+import (
+	"errors"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+// ProductFlagFilter narrows a listing of product flags; zero fields match
+// everything.
+type ProductFlagFilter struct {
+	ProductID int
+	Source    string
+	Status    string
+}
+
+// ProductFlagRepository defines the interface for the review queue of
+// flagged products.
+type ProductFlagRepository interface {
+	Create(f models.ProductFlag) (models.ProductFlag, error)
+	GetByID(id int) (models.ProductFlag, error)
+	// List returns the flags matching filter, newest first.
+	List(filter ProductFlagFilter) ([]models.ProductFlag, error)
+	// Close resolves or dismisses an open flag, failing with
+	// ErrProductFlagClosed if it was closed already.
+	Close(id int, status, closedBy, note string) (models.ProductFlag, error)
+}
+
+var ErrProductFlagNotFound = errors.New("product flag not found")
+var ErrProductFlagClosed = errors.New("product flag already closed")
@@ -0,0 +1,83 @@
+package repo
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+// The health score of a product adds four components of up to
+// healthPoints each, so it runs from 0 (fix first) to 100:
+//   - stock: on-hand units against the threshold; none when out of stock
+//   - freshness: full when changed within healthFreshDays, none after
+//     healthStaleDays without a stock or catalog change
+//   - margin: full from healthTargetMargin of the price up; unknown costs
+//     count against completeness instead
+//   - completeness: the share of SKU, barcode, category, description,
+//     brand, cost and weight that are filled in
+const (
+	healthPoints       = 25
+	healthFreshDays    = 30
+	healthStaleDays    = 180
+	healthTargetMargin = 0.3
+	healthFields       = 7
+)
+
+// ProductHealth is the health score of a product and its components.
+type ProductHealth struct {
+	Score        int     `json:"score"`
+	Stock        float64 `json:"stock"`
+	Freshness    float64 `json:"freshness"`
+	Margin       float64 `json:"margin_score"`
+	Completeness float64 `json:"completeness"`
+}
+
+// HealthOf scores p as of now. It matches healthScoreSQL.
+func HealthOf(p models.Product, now time.Time) ProductHealth {
+	var h ProductHealth
+	switch {
+	case p.Quantity <= 0:
+	case p.Threshold <= 0:
+		h.Stock = healthPoints
+	default:
+		h.Stock = healthPoints * math.Min(float64(p.Quantity)/float64(p.Threshold), 1)
+	}
+
+	if updatedAt := productUpdatedAt(p); !updatedAt.IsZero() {
+		days := now.Sub(updatedAt).Hours() / 24
+		h.Freshness = healthPoints * clampUnit((healthStaleDays-days)/(healthStaleDays-healthFreshDays))
+	}
+
+	if p.Price > 0 {
+		h.Margin = healthPoints * clampUnit((p.Price-p.Cost)/p.Price/healthTargetMargin)
+	}
+
+	filled := 0
+	for _, ok := range []bool{p.SKU != "", p.Barcode != "", p.Category != "", p.Description != "", p.Brand != "", p.Cost > 0, p.Weight > 0} {
+		if ok {
+			filled++
+		}
+	}
+	h.Completeness = healthPoints * float64(filled) / healthFields
+
+	h.Score = int(math.Round(h.Stock + h.Freshness + h.Margin + h.Completeness))
+	h.Stock, h.Freshness, h.Margin, h.Completeness = roundCents(h.Stock), roundCents(h.Freshness), roundCents(h.Margin), roundCents(h.Completeness)
+	return h
+}
+
+func clampUnit(v float64) float64 {
+	return math.Max(0, math.Min(v, 1))
+}
+
+// healthScoreSQL is the SQL of HealthOf's score, with now taken from the
+// placeholder nowArg.
+func healthScoreSQL(nowArg string) string {
+	return fmt.Sprintf(`ROUND((
+		CASE WHEN quantity <= 0 THEN 0 WHEN threshold <= 0 THEN %[1]d ELSE %[1]d * LEAST(quantity::float8 / threshold, 1) END
+		+ COALESCE(%[1]d * LEAST(GREATEST((%[3]d - EXTRACT(EPOCH FROM %[2]s::timestamp - updated_at) / 86400) / %[4]d, 0), 1), 0)
+		+ CASE WHEN price <= 0 THEN 0 ELSE %[1]d * LEAST(GREATEST(((price - cost) / price)::float8 / %[5]g, 0), 1) END
+		+ %[1]d * ((sku <> '')::int + (barcode <> '')::int + (category <> '')::int + (description <> '')::int + (brand <> '')::int + (cost > 0)::int + (weight > 0)::int)::float8 / %[6]d
+	)::numeric)`, healthPoints, nowArg, healthStaleDays, healthStaleDays-healthFreshDays, healthTargetMargin, healthFields)
+}
@@ -1,7 +1,12 @@
 package repo
 
 import (
+	"cmp"
+	"fmt"
+	"slices"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/rogerio-castellano/inventory-tracker/internal/models"
 )
@@ -10,13 +15,25 @@ import (
 type InMemoryProductRepository struct {
 	products []models.Product
 	nextID   int
+	// locations holds the units of each product in each warehouse; see
+	// NewInMemoryWarehouseRepository.
+	locations    map[location]int
+	warehouses   *InMemoryWarehouseRepository
+	reservations *InMemoryReservationRepository
 }
 
+type location struct {
+	productID, warehouseID int
+}
+
+var _ ProductRepository = (*InMemoryProductRepository)(nil)
+
 // NewInMemoryProductRepository creates a new instance of InMemoryProductRepository.
 func NewInMemoryProductRepository() *InMemoryProductRepository {
 	return &InMemoryProductRepository{
-		products: []models.Product{},
-		nextID:   1,
+		products:  []models.Product{},
+		nextID:    1,
+		locations: map[location]int{},
 	}
 }
 
@@ -36,9 +53,57 @@ func matchesFilter(p models.Product, pf ProductFilter) bool {
 	if pf.MaxQty != nil && p.Quantity > *pf.MaxQty {
 		return false
 	}
+	if pf.Query != "" && searchScore(p, SearchTerms(pf.Query)) == 0 {
+		return false
+	}
+	if pf.LowStock && p.Quantity >= p.Threshold {
+		return false
+	}
+	if pf.NeedsReview && !p.NeedsReview {
+		return false
+	}
+	if pf.Brand != "" && !strings.EqualFold(p.Brand, pf.Brand) {
+		return false
+	}
+	if pf.UpdatedSince != nil && productUpdatedAt(p).Before(*pf.UpdatedSince) {
+		return false
+	}
+	if pf.MinHealth != nil || pf.MaxHealth != nil {
+		score := HealthOf(p, time.Now().UTC()).Score
+		if pf.MinHealth != nil && score < *pf.MinHealth || pf.MaxHealth != nil && score > *pf.MaxHealth {
+			return false
+		}
+	}
+	if !pf.Scope.AllowsCategory(p.Category) {
+		return false
+	}
 	return true
 }
 
+// compareProducts compares a and b by one of the ProductSort orders.
+func compareProducts(a, b models.Product, order string) int {
+	switch order {
+	case ProductSortName:
+		return cmp.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	case ProductSortPrice:
+		return cmp.Compare(a.Price, b.Price)
+	case ProductSortQuantity:
+		return cmp.Compare(a.Quantity, b.Quantity)
+	case ProductSortUpdatedAt:
+		return productUpdatedAt(a).Compare(productUpdatedAt(b))
+	case ProductSortHealth:
+		now := time.Now().UTC()
+		return cmp.Compare(HealthOf(a, now).Score, HealthOf(b, now).Score)
+	}
+	return cmp.Compare(a.ID, b.ID)
+}
+
+// productUpdatedAt parses UpdatedAt; products without one sort first.
+func productUpdatedAt(p models.Product) time.Time {
+	t, _ := time.Parse(time.RFC3339Nano, p.UpdatedAt)
+	return t
+}
+
 func (r *InMemoryProductRepository) Filter(pf ProductFilter) ([]models.Product, int, error) {
 	var filtered []models.Product
 
@@ -48,6 +113,31 @@ func (r *InMemoryProductRepository) Filter(pf ProductFilter) ([]models.Product,
 		}
 	}
 
+	switch order, descending := pf.sortOrder(); order {
+	case "", ProductSortRelevance:
+		// Without full-text ranking, the products matching more of the query
+		// in their name or SKU come first.
+		if pf.Query != "" {
+			terms := SearchTerms(pf.Query)
+			sort.SliceStable(filtered, func(i, j int) bool {
+				return searchScore(filtered[i], terms) > searchScore(filtered[j], terms)
+			})
+		} else if descending {
+			slices.Reverse(filtered)
+		}
+	default:
+		slices.SortStableFunc(filtered, func(a, b models.Product) int {
+			c := compareProducts(a, b, order)
+			if c == 0 {
+				c = cmp.Compare(a.ID, b.ID)
+			}
+			if descending {
+				return -c
+			}
+			return c
+		})
+	}
+
 	// If offset is greater than the number of filtered products, return empty slice
 	if pf.Offset != nil && *pf.Offset > len(filtered) {
 		return []models.Product{}, 0, nil
@@ -63,12 +153,42 @@ func (r *InMemoryProductRepository) Filter(pf ProductFilter) ([]models.Product,
 		end = clamp(start+*pf.Limit, start, len(filtered))
 	}
 
+	if pf.SkipTotal {
+		total := end
+		if end < len(filtered) {
+			total++
+		}
+		return filtered[start:end], total, nil
+	}
 	return filtered[start:end], len(filtered), nil
 }
 
+func (r *InMemoryProductRepository) Each(pf ProductFilter, _ time.Duration, fn func(models.Product) error) error {
+	pf.Offset, pf.Limit = nil, nil
+	products, _, err := r.Filter(pf)
+	if err != nil {
+		return err
+	}
+	for _, p := range products {
+		if err := fn(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Create adds a new product to the repository.
 func (r *InMemoryProductRepository) Create(product models.Product) (models.Product, error) {
+	if r.nameTaken(product.Name, 0) {
+		return models.Product{}, fmt.Errorf("%w: product %q", ErrDuplicatedValueUnique, product.Name)
+	}
+	if r.externalIDTaken(product.ExternalID, 0) {
+		return models.Product{}, fmt.Errorf("%w: %s", ErrDuplicatedExternalID, product.ExternalID)
+	}
 	product.ID = r.nextID
+	product.BaselineQuantity = product.Quantity
+	product.Status = productStatus(product.Status)
+	product.Seq = 1
 	r.nextID++
 	r.products = append(r.products, product)
 	return product, nil
@@ -91,8 +211,20 @@ func (r *InMemoryProductRepository) GetByID(id int) (models.Product, error) {
 
 // Update modifies an existing product in the repository.
 func (r *InMemoryProductRepository) Update(product models.Product) (models.Product, error) {
+	if r.nameTaken(product.Name, product.ID) {
+		return models.Product{}, fmt.Errorf("%w: product %q", ErrDuplicatedValueUnique, product.Name)
+	}
+	if r.externalIDTaken(product.ExternalID, product.ID) {
+		return models.Product{}, fmt.Errorf("%w: %s", ErrDuplicatedExternalID, product.ExternalID)
+	}
 	for i, p := range r.products {
 		if p.ID == product.ID {
+			if product.ExternalID == "" {
+				product.ExternalID = p.ExternalID
+			}
+			product.BaselineQuantity = p.BaselineQuantity + (product.Quantity - p.Quantity)
+			product.Status = productStatus(product.Status)
+			product.Seq = p.Seq + 1
 			r.products[i] = product
 			return product, nil
 		}
@@ -105,6 +237,11 @@ func (r *InMemoryProductRepository) Delete(id int) error {
 	for i, p := range r.products {
 		if p.ID == id {
 			r.products = append(r.products[:i], r.products[i+1:]...)
+			for k := range r.locations {
+				if k.productID == id {
+					delete(r.locations, k)
+				}
+			}
 			return nil
 		}
 	}
@@ -113,6 +250,7 @@ func (r *InMemoryProductRepository) Delete(id int) error {
 
 func (r *InMemoryProductRepository) Clear() {
 	r.products = []models.Product{}
+	r.locations = map[location]int{}
 }
 
 // AdjustQuantity implements ProductRepository.
@@ -122,8 +260,13 @@ func (r *InMemoryProductRepository) AdjustQuantity(productId int, delta int) (mo
 	if product.Quantity+delta < 0 {
 		return models.Product{}, ErrInvalidQuantityChange
 	}
+	if err := r.checkUnreserved(productId, product.Quantity, delta); err != nil {
+		return models.Product{}, err
+	}
 
 	product.Quantity += delta
+	product.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+	product.Seq++
 	for i, p := range r.products {
 		if p.ID == productId {
 			r.products[i] = product
@@ -134,11 +277,247 @@ func (r *InMemoryProductRepository) AdjustQuantity(productId int, delta int) (mo
 	return models.Product{}, ErrProductNotFound
 }
 
+// checkUnreserved fails with ErrStockReserved when adding delta to quantity
+// would take units reserved for the product.
+func (r *InMemoryProductRepository) checkUnreserved(productID, quantity, delta int) error {
+	if delta >= 0 || r.reservations == nil {
+		return nil
+	}
+	if reserved := r.reservations.reserved(productID, time.Now().UTC()); quantity+delta >= 0 && quantity+delta < reserved {
+		return fmt.Errorf("%w: %d of %d units are reserved", ErrStockReserved, reserved, quantity)
+	}
+	return nil
+}
+
+// AdjustWithMovements implements ProductRepository. Movements are not kept
+// in memory, so only the quantities change.
+func (r *InMemoryProductRepository) AdjustWithMovements(productID int, movements []models.Movement) (models.Product, error) {
+	net := 0
+	byWarehouse := map[int]int{}
+	for _, m := range movements {
+		net += m.Delta
+		if m.WarehouseID != nil {
+			byWarehouse[*m.WarehouseID] += m.Delta
+		}
+	}
+	for id, delta := range byWarehouse {
+		if r.warehouses == nil || !r.warehouses.exists(id) {
+			return models.Product{}, fmt.Errorf("%w: %d", ErrWarehouseNotFound, id)
+		}
+		if r.locations[location{productID, id}]+delta < 0 {
+			return models.Product{}, fmt.Errorf("%w: warehouse %d", ErrInvalidQuantityChange, id)
+		}
+	}
+	for i, p := range r.products {
+		if p.ID == productID {
+			if p.Quantity+net < 0 {
+				return models.Product{}, ErrInvalidQuantityChange
+			}
+			if err := r.checkUnreserved(productID, p.Quantity, net); err != nil {
+				return models.Product{}, err
+			}
+			for id, delta := range byWarehouse {
+				r.locations[location{productID, id}] += delta
+			}
+			r.products[i].Quantity += net
+			r.products[i].Seq++
+			return r.products[i], nil
+		}
+	}
+	return models.Product{}, ErrInvalidQuantityChange
+}
+
 func (r *InMemoryProductRepository) GetByName(name string) (models.Product, error) {
 	for _, p := range r.products {
-		if p.Name == name {
+		if NameKey(p.Name) == NameKey(name) {
+			return p, nil
+		}
+	}
+	return models.Product{}, ErrProductNotFound
+}
+
+func (r *InMemoryProductRepository) GetByExternalID(externalID string) (models.Product, error) {
+	for _, p := range r.products {
+		if externalID != "" && p.ExternalID == externalID {
 			return p, nil
 		}
 	}
 	return models.Product{}, ErrProductNotFound
 }
+
+func (r *InMemoryProductRepository) GetByBarcode(barcode string) (models.Product, error) {
+	var match *models.Product
+	for i, p := range r.products {
+		if p.Barcode != barcode {
+			continue
+		}
+		if match != nil {
+			return models.Product{}, ErrAmbiguousBarcode
+		}
+		match = &r.products[i]
+	}
+	if match == nil {
+		return models.Product{}, ErrProductNotFound
+	}
+	return *match, nil
+}
+
+func (r *InMemoryProductRepository) GetBySKU(sku string) (models.Product, error) {
+	var matches []models.Product
+	for _, p := range r.products {
+		if p.SKU == sku {
+			matches = append(matches, p)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return models.Product{}, ErrProductNotFound
+	case 1:
+		return matches[0], nil
+	}
+	return models.Product{}, ErrAmbiguousSKU
+}
+
+func (r *InMemoryProductRepository) GetByNames(names []string) (map[string]models.Product, error) {
+	wanted := make(map[string]bool, len(names))
+	for _, n := range names {
+		wanted[NameKey(n)] = true
+	}
+	products := make(map[string]models.Product, len(names))
+	for _, p := range r.products {
+		if key := NameKey(p.Name); wanted[key] {
+			products[key] = p
+		}
+	}
+	return products, nil
+}
+
+func (r *InMemoryProductRepository) CreateBatch(products []models.Product) (int, error) {
+	seen := make(map[string]bool, len(products))
+	for _, p := range products {
+		if r.nameTaken(p.Name, 0) || seen[NameKey(p.Name)] {
+			return 0, fmt.Errorf("%w: product %q", ErrDuplicatedValueUnique, p.Name)
+		}
+		seen[NameKey(p.Name)] = true
+	}
+	for _, p := range products {
+		if _, err := r.Create(p); err != nil {
+			return 0, err
+		}
+	}
+	return len(products), nil
+}
+
+func (r *InMemoryProductRepository) AdjustQuantities(deltas map[int]int) error {
+	for id, delta := range deltas {
+		p, err := r.GetByID(id)
+		if err != nil || p.Quantity+delta < 0 {
+			return ErrInvalidQuantityChange
+		}
+	}
+	for i, p := range r.products {
+		if delta, ok := deltas[p.ID]; ok {
+			r.products[i].Quantity += delta
+			r.products[i].Seq++
+		}
+	}
+	return nil
+}
+
+func (r *InMemoryProductRepository) Reprice(changes []PriceChange) error {
+	for _, c := range changes {
+		p, err := r.GetByID(c.ProductID)
+		if err != nil || p.Price != c.From {
+			return ErrPriceChanged
+		}
+	}
+	prices := make(map[int]float64, len(changes))
+	for _, c := range changes {
+		prices[c.ProductID] = c.To
+	}
+	for i, p := range r.products {
+		if price, ok := prices[p.ID]; ok {
+			r.products[i].Price = price
+			r.products[i].Seq++
+		}
+	}
+	return nil
+}
+
+// Merge folds the source's stock into the target. This repository keeps no
+// movements, so there is no history to move.
+func (r *InMemoryProductRepository) Merge(sourceID, targetID int) (models.Product, error) {
+	if sourceID == targetID {
+		return models.Product{}, ErrInvalidMerge
+	}
+	source, err := r.GetByID(sourceID)
+	if err != nil {
+		return models.Product{}, err
+	}
+	for i, p := range r.products {
+		if p.ID == targetID {
+			r.products[i].Quantity += source.Quantity
+			r.products[i].BaselineQuantity += source.BaselineQuantity
+			for k, units := range r.locations {
+				if k.productID == sourceID {
+					r.locations[location{targetID, k.warehouseID}] += units
+				}
+			}
+			r.products[i].Seq++
+			target := r.products[i]
+			return target, r.Delete(sourceID)
+		}
+	}
+	return models.Product{}, ErrProductNotFound
+}
+
+func (r *InMemoryProductRepository) Suggest(q string, limit int, scope AccessScope) ([]ProductSuggestion, error) {
+	q = strings.ToLower(q)
+	var matches []models.Product
+	for _, p := range r.products {
+		if p.Status == models.ProductStatusDiscontinued || !scope.AllowsCategory(p.Category) {
+			continue
+		}
+		if strings.Contains(strings.ToLower(p.Name), q) || strings.Contains(strings.ToLower(p.SKU), q) || strings.Contains(strings.ToLower(p.Brand), q) {
+			matches = append(matches, p)
+		}
+	}
+	// Without trigram similarity, prefix matches come first and the rest
+	// keep name order.
+	sort.SliceStable(matches, func(i, j int) bool {
+		pi, pj := strings.HasPrefix(strings.ToLower(matches[i].Name), q), strings.HasPrefix(strings.ToLower(matches[j].Name), q)
+		if pi != pj {
+			return pi
+		}
+		return matches[i].Name < matches[j].Name
+	})
+
+	suggestions := []ProductSuggestion{}
+	for _, p := range matches[:min(limit, len(matches))] {
+		suggestions = append(suggestions, ProductSuggestion{ID: p.ID, Name: p.Name, SKU: p.SKU, Brand: p.Brand})
+	}
+	return suggestions, nil
+}
+
+// externalIDTaken reports whether a product other than exceptID already uses externalID.
+func (r *InMemoryProductRepository) externalIDTaken(externalID string, exceptID int) bool {
+	if externalID == "" {
+		return false
+	}
+	for _, p := range r.products {
+		if p.ID != exceptID && p.ExternalID == externalID {
+			return true
+		}
+	}
+	return false
+}
+
+// nameTaken reports whether a product other than exceptID already uses name.
+func (r *InMemoryProductRepository) nameTaken(name string, exceptID int) bool {
+	for _, p := range r.products {
+		if p.ID != exceptID && NameKey(p.Name) == NameKey(name) {
+			return true
+		}
+	}
+	return false
+}
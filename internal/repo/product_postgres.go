@@ -15,27 +15,57 @@ type PostgresProductRepository struct {
 	db *sql.DB
 }
 
+var _ ProductRepository = (*PostgresProductRepository)(nil)
+
+const productColumns = `id, name, price, quantity, threshold, created_at, updated_at, baseline_quantity, category, sku, barcode, supplier, max_quantity, status, external_id, cost, tax_class_id, description, brand, manufacturer, weight, length, width, height, seq, needs_review, currency`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanProduct reads productColumns, followed by any extra selected columns.
+func scanProduct(row rowScanner, extra ...any) (models.Product, error) {
+	var p models.Product
+	var externalID sql.NullString
+	var taxClassID sql.NullInt64
+	dest := []any{&p.ID, &p.Name, &p.Price, &p.Quantity, &p.Threshold, &p.CreatedAt, &p.UpdatedAt, &p.BaselineQuantity,
+		&p.Category, &p.SKU, &p.Barcode, &p.Supplier, &p.MaxQuantity, &p.Status, &externalID, &p.Cost, &taxClassID,
+		&p.Description, &p.Brand, &p.Manufacturer, &p.Weight, &p.Dimensions.Length, &p.Dimensions.Width, &p.Dimensions.Height, &p.Seq, &p.NeedsReview, &p.Currency}
+	err := row.Scan(append(dest, extra...)...)
+	p.ExternalID = externalID.String
+	if taxClassID.Valid {
+		id := int(taxClassID.Int64)
+		p.TaxClassID = &id
+	}
+	return p, err
+}
+
 func NewPostgresProductRepository(db *sql.DB) *PostgresProductRepository {
 	return &PostgresProductRepository{db: db}
 }
 
 func (r *PostgresProductRepository) Create(p models.Product) (models.Product, error) {
-	query := `INSERT INTO products (name, price, quantity, threshold, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
+	p.Status = productStatus(p.Status)
+	query := `
+		INSERT INTO products (name, price, quantity, threshold, created_at, updated_at, baseline_quantity, category, sku, barcode, supplier, max_quantity, status, external_id, cost, tax_class_id,
+			description, brand, manufacturer, weight, length, width, height, needs_review, currency)
+		VALUES ($1, $2, $3, $4, $5, $6, $3, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24)
+		RETURNING id, seq
+	`
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	err := r.db.QueryRowContext(ctx, query, p.Name, p.Price, p.Quantity, p.Threshold, p.CreatedAt, p.UpdatedAt).Scan(&p.ID)
-	if err != nil {
-		if strings.Contains(err.Error(), "23505") {
-			err = fmt.Errorf("%w: %v", ErrDuplicatedValueUnique, err)
-		}
-	}
+	err := r.db.QueryRowContext(ctx, query, p.Name, p.Price, p.Quantity, p.Threshold, p.CreatedAt, p.UpdatedAt,
+		p.Category, p.SKU, p.Barcode, p.Supplier, p.MaxQuantity, p.Status, nullableExternalID(p.ExternalID), p.Cost, nullableTaxClassID(p.TaxClassID),
+		p.Description, p.Brand, p.Manufacturer, p.Weight, p.Dimensions.Length, p.Dimensions.Width, p.Dimensions.Height, p.NeedsReview, p.Currency).Scan(&p.ID, &p.Seq)
+	err = uniqueViolation(err)
+	p.BaselineQuantity = p.Quantity
 
 	return p, err
 }
 
 func (r *PostgresProductRepository) GetAll() ([]models.Product, error) {
-	query := `SELECT id, name, price, quantity, threshold FROM products ORDER BY id`
+	query := `SELECT ` + productColumns + ` FROM products ORDER BY id`
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
@@ -47,8 +77,8 @@ func (r *PostgresProductRepository) GetAll() ([]models.Product, error) {
 
 	var products []models.Product
 	for rows.Next() {
-		var p models.Product
-		if err := rows.Scan(&p.ID, &p.Name, &p.Price, &p.Quantity, &p.Threshold); err != nil {
+		p, err := scanProduct(rows)
+		if err != nil {
 			return nil, err
 		}
 		products = append(products, p)
@@ -57,12 +87,11 @@ func (r *PostgresProductRepository) GetAll() ([]models.Product, error) {
 }
 
 func (r *PostgresProductRepository) GetByID(id int) (models.Product, error) {
-	query := `SELECT id, name, price, quantity, threshold FROM products WHERE id = $1`
+	query := `SELECT ` + productColumns + ` FROM products WHERE id = $1`
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	var p models.Product
-	err := r.db.QueryRowContext(ctx, query, id).Scan(&p.ID, &p.Name, &p.Price, &p.Quantity, &p.Threshold)
+	p, err := scanProduct(r.db.QueryRowContext(ctx, query, id))
 	if errors.Is(err, sql.ErrNoRows) {
 		return models.Product{}, ErrProductNotFound
 	}
@@ -70,18 +99,33 @@ func (r *PostgresProductRepository) GetByID(id int) (models.Product, error) {
 }
 
 func (r *PostgresProductRepository) Update(p models.Product) (models.Product, error) {
-	query := `UPDATE products SET name = $1, price = $2, quantity = $3, threshold = $4, updated_at = $5 WHERE id = $6`
+	// Setting the quantity directly bypasses the movement ledger, so the
+	// baseline absorbs the difference to keep reconciliation meaningful. An
+	// empty external ID keeps the stored one: integrations rely on it.
+	query := `
+		UPDATE products
+		SET name = $1, price = $2, baseline_quantity = baseline_quantity + ($3 - quantity), quantity = $3, threshold = $4, updated_at = $5,
+			category = $7, sku = $8, barcode = $9, supplier = $10, max_quantity = $11, status = $12,
+			external_id = COALESCE($13, external_id), cost = $14, tax_class_id = $15,
+			description = $16, brand = $17, manufacturer = $18, weight = $19, length = $20, width = $21, height = $22, needs_review = $23, currency = $24
+		WHERE id = $6
+		RETURNING baseline_quantity, external_id, seq, created_at
+	`
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	res, err := r.db.ExecContext(ctx, query, p.Name, p.Price, p.Quantity, p.Threshold, p.UpdatedAt, p.ID)
-	if err != nil {
-		return models.Product{}, err
-	}
-	rowsAffected, _ := res.RowsAffected()
-	if rowsAffected == 0 {
+	p.Status = productStatus(p.Status)
+	var externalID sql.NullString
+	err := r.db.QueryRowContext(ctx, query, p.Name, p.Price, p.Quantity, p.Threshold, p.UpdatedAt, p.ID,
+		p.Category, p.SKU, p.Barcode, p.Supplier, p.MaxQuantity, p.Status, nullableExternalID(p.ExternalID), p.Cost, nullableTaxClassID(p.TaxClassID),
+		p.Description, p.Brand, p.Manufacturer, p.Weight, p.Dimensions.Length, p.Dimensions.Width, p.Dimensions.Height, p.NeedsReview, p.Currency).Scan(&p.BaselineQuantity, &externalID, &p.Seq, &p.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
 		return models.Product{}, ErrProductNotFound
 	}
+	if err != nil {
+		return models.Product{}, uniqueViolation(err)
+	}
+	p.ExternalID = externalID.String
 	return p, nil
 }
 
@@ -102,32 +146,29 @@ func (r *PostgresProductRepository) Delete(id int) error {
 }
 
 func (r *PostgresProductRepository) Filter(pf ProductFilter) ([]models.Product, int, error) {
-
-	conditions, args, argIdx := filterConditions(pf)
+	query, args, argIdx := filterQuery(pf)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	var totalCount int
-	filterClause := conditions
-	countQuery := "SELECT COUNT(*) FROM products WHERE 1=1" + filterClause
-	row := r.db.QueryRowContext(ctx, countQuery, args...)
-	if err := row.Scan(&totalCount); err != nil {
-		return nil, 0, err
+	offset := 0
+	if pf.Offset != nil && *pf.Offset > 0 {
+		offset = *pf.Offset
 	}
-
-	query := `SELECT id, name, price, quantity, threshold FROM products WHERE 1=1`
-	query += conditions
-	query += " ORDER BY id"
-
+	limit := 0
 	if pf.Limit != nil && *pf.Limit > 0 {
+		limit = *pf.Limit
+		if pf.SkipTotal {
+			// One extra row tells whether another page exists.
+			limit++
+		}
 		query += fmt.Sprintf(" LIMIT $%d", argIdx)
-		args = append(args, pf.Limit)
+		args = append(args, limit)
 		argIdx++
 	}
-	if pf.Offset != nil && *pf.Offset > 0 {
+	if offset > 0 {
 		query += fmt.Sprintf(" OFFSET $%d", argIdx)
-		args = append(args, pf.Offset)
+		args = append(args, offset)
 	}
 
 	rows, err := r.db.QueryContext(ctx, query, args...)
@@ -137,17 +178,111 @@ func (r *PostgresProductRepository) Filter(pf ProductFilter) ([]models.Product,
 	defer rows.Close()
 
 	var products []models.Product
+	var totalCount int
 	for rows.Next() {
-		var p models.Product
-		if err := rows.Scan(&p.ID, &p.Name, &p.Price, &p.Quantity, &p.Threshold); err != nil {
+		p, err := scanProduct(rows, &totalCount)
+		if err != nil {
 			return nil, 0, err
 		}
 		products = append(products, p)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	if pf.SkipTotal {
+		totalCount = offset + len(products)
+		if pf.Limit != nil && *pf.Limit > 0 && len(products) > *pf.Limit {
+			products = products[:*pf.Limit]
+		}
+		return products, totalCount, nil
+	}
+
+	// A page past the end has no rows to carry the window count.
+	if len(products) == 0 && offset > 0 {
+		conditions, countArgs, _ := filterConditions(pf)
+		if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM products WHERE 1=1"+conditions, countArgs...).Scan(&totalCount); err != nil {
+			return nil, 0, err
+		}
+	}
 
 	return products, totalCount, nil
 }
 
+func (r *PostgresProductRepository) Each(pf ProductFilter, timeout time.Duration, fn func(models.Product) error) error {
+	pf.SkipTotal = true
+	query, args, _ := filterQuery(pf)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var total int
+	for rows.Next() {
+		p, err := scanProduct(rows, &total)
+		if err != nil {
+			return err
+		}
+		if err := fn(p); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// filterQuery returns the ordered SELECT of the products matching pf, each
+// row followed by the total count unless pf.SkipTotal is set, without
+// pagination. argIdx numbers the next argument.
+func filterQuery(pf ProductFilter) (query string, args []any, argIdx int) {
+	conditions, args, argIdx := filterConditions(pf)
+
+	// The total is computed by a window function over the same scan as the
+	// page, instead of a separate COUNT query.
+	query = `SELECT ` + productColumns + `, COUNT(*) OVER() FROM products WHERE 1=1`
+	if pf.SkipTotal {
+		query = `SELECT ` + productColumns + `, 0 FROM products WHERE 1=1`
+	}
+	query += conditions
+	if pf.Query != "" && (pf.Sort == "" || pf.Sort == ProductSortRelevance) {
+		query += fmt.Sprintf(" ORDER BY ts_rank(search_vector, websearch_to_tsquery('english', $%d)) + word_similarity($%d, name) DESC, id", argIdx, argIdx)
+		args = append(args, pf.Query)
+		argIdx++
+	} else {
+		order, orderArgs := productOrder(pf, argIdx)
+		query += " ORDER BY " + order
+		args = append(args, orderArgs...)
+		argIdx += len(orderArgs)
+	}
+	return query, args, argIdx
+}
+
+// productOrder returns the ORDER BY clause of pf and the arguments it
+// takes, numbered from argIdx.
+func productOrder(pf ProductFilter, argIdx int) (string, []any) {
+	sort, descending := pf.sortOrder()
+	var args []any
+	column, ok := productSortColumns[sort]
+	if sort == ProductSortHealth {
+		column = healthScoreSQL(fmt.Sprintf("$%d", argIdx))
+		args = append(args, time.Now().UTC())
+	} else if !ok {
+		column = "id"
+	}
+	direction := ""
+	if descending {
+		direction = " DESC"
+	}
+	if column == "id" {
+		return "id" + direction, args
+	}
+	return column + direction + ", id" + direction, args
+}
+
 func filterConditions(pf ProductFilter) (string, []any, int) {
 	query := ""
 	argIdx := 1
@@ -178,6 +313,49 @@ func filterConditions(pf ProductFilter) (string, []any, int) {
 		args = append(args, pf.MaxQty)
 		argIdx++
 	}
+	if pf.Query != "" {
+		// Full-text matches are served by the search_vector index, typos in
+		// the name by the trigram one.
+		query += fmt.Sprintf(" AND (search_vector @@ websearch_to_tsquery('english', $%d) OR name %% $%d OR $%d <%% name OR sku ILIKE $%d)", argIdx, argIdx, argIdx, argIdx+1)
+		args = append(args, pf.Query, likeEscaper.Replace(pf.Query)+"%")
+		argIdx += 2
+	}
+	if pf.LowStock {
+		query += " AND quantity < threshold"
+	}
+	if pf.NeedsReview {
+		query += " AND needs_review"
+	}
+	if pf.Brand != "" {
+		query += fmt.Sprintf(" AND lower(brand) = lower($%d)", argIdx)
+		args = append(args, pf.Brand)
+		argIdx++
+	}
+	if pf.UpdatedSince != nil {
+		query += fmt.Sprintf(" AND updated_at >= $%d", argIdx)
+		args = append(args, pf.UpdatedSince.UTC())
+		argIdx++
+	}
+	if pf.MinHealth != nil || pf.MaxHealth != nil {
+		score := healthScoreSQL(fmt.Sprintf("$%d", argIdx))
+		args = append(args, time.Now().UTC())
+		argIdx++
+		if pf.MinHealth != nil {
+			query += fmt.Sprintf(" AND %s >= $%d", score, argIdx)
+			args = append(args, *pf.MinHealth)
+			argIdx++
+		}
+		if pf.MaxHealth != nil {
+			query += fmt.Sprintf(" AND %s <= $%d", score, argIdx)
+			args = append(args, *pf.MaxHealth)
+			argIdx++
+		}
+	}
+	if pf.Scope.Categories != nil {
+		query += fmt.Sprintf(" AND lower(category) = ANY($%d)", argIdx)
+		args = append(args, pf.Scope.Categories)
+		argIdx++
+	}
 
 	return query, args, argIdx
 }
@@ -187,31 +365,421 @@ func (r *PostgresProductRepository) AdjustQuantity(productID int, delta int) (mo
 		UPDATE products
 		SET quantity = quantity + $1, updated_at = $2
 		WHERE id = $3 AND quantity + $1 >= 0
-		RETURNING id, name, price, quantity, threshold, created_at, updated_at
+		RETURNING ` + productColumns + `
 	`
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	var p models.Product
-	err := r.db.QueryRowContext(ctx, query, delta, time.Now().UTC(), productID).
-		Scan(&p.ID, &p.Name, &p.Price, &p.Quantity, &p.Threshold, &p.CreatedAt, &p.UpdatedAt)
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Product{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
 
+	now := time.Now().UTC()
+	if err := checkUnreserved(ctx, tx, productID, delta, now); err != nil {
+		return models.Product{}, err
+	}
+	p, err := scanProduct(tx.QueryRowContext(ctx, query, delta, now, productID))
 	if errors.Is(err, sql.ErrNoRows) {
 		return models.Product{}, ErrInvalidQuantityChange
 	}
-	return p, err
+	if err != nil {
+		return models.Product{}, err
+	}
+	return p, tx.Commit()
 }
 
 func (r *PostgresProductRepository) GetByName(name string) (models.Product, error) {
-	query := `SELECT id, name, price, quantity, threshold, created_at, updated_at FROM products WHERE name = $1`
+	query := `SELECT ` + productColumns + ` FROM products WHERE lower(name) = lower($1)`
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	var p models.Product
-	err := r.db.QueryRowContext(ctx, query, name).Scan(
-		&p.ID, &p.Name, &p.Price, &p.Quantity, &p.Threshold, &p.CreatedAt, &p.UpdatedAt)
+	p, err := scanProduct(r.db.QueryRowContext(ctx, query, name))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Product{}, ErrProductNotFound
+	}
+	return p, err
+}
+
+func (r *PostgresProductRepository) GetByExternalID(externalID string) (models.Product, error) {
+	query := `SELECT ` + productColumns + ` FROM products WHERE external_id = $1`
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	p, err := scanProduct(r.db.QueryRowContext(ctx, query, externalID))
 	if errors.Is(err, sql.ErrNoRows) {
 		return models.Product{}, ErrProductNotFound
 	}
 	return p, err
 }
+
+// nullableExternalID stores an unset external ID as NULL so the unique
+// index only applies to IDs actually given.
+func nullableExternalID(id string) any {
+	if id == "" {
+		return nil
+	}
+	return id
+}
+
+func nullableTaxClassID(id *int) any {
+	if id == nil {
+		return nil
+	}
+	return int32(*id)
+}
+
+func (r *PostgresProductRepository) GetByBarcode(barcode string) (models.Product, error) {
+	query := `SELECT ` + productColumns + ` FROM products WHERE barcode = $1 LIMIT 2`
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, barcode)
+	if err != nil {
+		return models.Product{}, err
+	}
+	defer rows.Close()
+
+	var matches []models.Product
+	for rows.Next() {
+		p, err := scanProduct(rows)
+		if err != nil {
+			return models.Product{}, err
+		}
+		matches = append(matches, p)
+	}
+	if err := rows.Err(); err != nil {
+		return models.Product{}, err
+	}
+
+	switch len(matches) {
+	case 0:
+		return models.Product{}, ErrProductNotFound
+	case 1:
+		return matches[0], nil
+	}
+	return models.Product{}, ErrAmbiguousBarcode
+}
+
+func (r *PostgresProductRepository) GetBySKU(sku string) (models.Product, error) {
+	query := `SELECT ` + productColumns + ` FROM products WHERE sku = $1 LIMIT 2`
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, sku)
+	if err != nil {
+		return models.Product{}, err
+	}
+	defer rows.Close()
+
+	var matches []models.Product
+	for rows.Next() {
+		p, err := scanProduct(rows)
+		if err != nil {
+			return models.Product{}, err
+		}
+		matches = append(matches, p)
+	}
+	if err := rows.Err(); err != nil {
+		return models.Product{}, err
+	}
+
+	switch len(matches) {
+	case 0:
+		return models.Product{}, ErrProductNotFound
+	case 1:
+		return matches[0], nil
+	}
+	return models.Product{}, ErrAmbiguousSKU
+}
+
+func (r *PostgresProductRepository) GetByNames(names []string) (map[string]models.Product, error) {
+	keys := make([]string, len(names))
+	for i, n := range names {
+		keys[i] = NameKey(n)
+	}
+
+	query := `SELECT ` + productColumns + ` FROM products WHERE lower(name) = ANY($1)`
+	ctx, cancel := context.WithTimeout(context.Background(), bulkTimeout)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, keys)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	products := make(map[string]models.Product, len(names))
+	for rows.Next() {
+		p, err := scanProduct(rows)
+		if err != nil {
+			return nil, err
+		}
+		products[NameKey(p.Name)] = p
+	}
+	return products, rows.Err()
+}
+
+// CreateBatch inserts products with COPY in a single round trip.
+func (r *PostgresProductRepository) CreateBatch(products []models.Product) (int, error) {
+	now := time.Now().UTC()
+	rows := make([][]any, len(products))
+	for i, p := range products {
+		rows[i] = []any{p.Name, p.Price, p.Quantity, p.Threshold, batchTime(p.CreatedAt, now), batchTime(p.UpdatedAt, now), p.Quantity,
+			p.Category, p.SKU, p.Barcode, p.Supplier, p.MaxQuantity, productStatus(p.Status), nullableExternalID(p.ExternalID), p.Cost, nullableTaxClassID(p.TaxClassID),
+			p.Description, p.Brand, p.Manufacturer, p.Weight, p.Dimensions.Length, p.Dimensions.Width, p.Dimensions.Height, p.NeedsReview, p.Currency}
+	}
+
+	columns := []string{"name", "price", "quantity", "threshold", "created_at", "updated_at", "baseline_quantity",
+		"category", "sku", "barcode", "supplier", "max_quantity", "status", "external_id", "cost", "tax_class_id",
+		"description", "brand", "manufacturer", "weight", "length", "width", "height", "needs_review", "currency"}
+	n, err := copyFrom(r.db, "products", columns, rows)
+	if err != nil {
+		return 0, uniqueViolation(err)
+	}
+	return int(n), nil
+}
+
+func (r *PostgresProductRepository) AdjustQuantities(deltas map[int]int) error {
+	ids := make([]int32, 0, len(deltas))
+	amounts := make([]int32, 0, len(deltas))
+	for id, delta := range deltas {
+		ids = append(ids, int32(id))
+		amounts = append(amounts, int32(delta))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), bulkTimeout)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	query := `
+		UPDATE products p
+		SET quantity = p.quantity + d.delta, updated_at = $3
+		FROM unnest($1::int[], $2::int[]) AS d(id, delta)
+		WHERE p.id = d.id AND p.quantity + d.delta >= 0
+	`
+	res, err := tx.ExecContext(ctx, query, ids, amounts, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); int(n) != len(deltas) {
+		return ErrInvalidQuantityChange
+	}
+	return tx.Commit()
+}
+
+func (r *PostgresProductRepository) Reprice(changes []PriceChange) error {
+	ids := make([]int32, len(changes))
+	from := make([]float64, len(changes))
+	to := make([]float64, len(changes))
+	for i, c := range changes {
+		ids[i], from[i], to[i] = int32(c.ProductID), c.From, c.To
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), bulkTimeout)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	query := `
+		UPDATE products p
+		SET price = c.new_price, updated_at = $4
+		FROM unnest($1::int[], $2::numeric[], $3::numeric[]) AS c(id, old_price, new_price)
+		WHERE p.id = c.id AND p.price = c.old_price
+	`
+	res, err := tx.ExecContext(ctx, query, ids, from, to, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); int(n) != len(changes) {
+		return ErrPriceChanged
+	}
+	return tx.Commit()
+}
+
+func (r *PostgresProductRepository) AdjustWithMovements(productID int, movements []models.Movement) (models.Product, error) {
+	now := time.Now().UTC()
+	net := 0
+	deltas := make([]int32, len(movements))
+	createdAt := make([]time.Time, len(movements))
+	externalIDs := make([]string, len(movements))
+	warehouseIDs := make([]int32, len(movements))
+	reasons := make([]string, len(movements))
+	notes := make([]string, len(movements))
+	byWarehouse := map[int]int{}
+	for i, m := range movements {
+		at, err := movementTime(m)
+		if err != nil {
+			return models.Product{}, err
+		}
+		net += m.Delta
+		deltas[i], createdAt[i], externalIDs[i] = int32(m.Delta), at, m.ExternalID
+		reasons[i], notes[i] = m.Reason, m.Note
+		if m.WarehouseID != nil {
+			warehouseIDs[i] = int32(*m.WarehouseID)
+			byWarehouse[*m.WarehouseID] += m.Delta
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), bulkTimeout)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Product{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := checkUnreserved(ctx, tx, productID, net, now); err != nil {
+		return models.Product{}, err
+	}
+	query := `
+		UPDATE products
+		SET quantity = quantity + $1, updated_at = $2
+		WHERE id = $3 AND quantity + $1 >= 0
+		RETURNING ` + productColumns + `
+	`
+	p, err := scanProduct(tx.QueryRowContext(ctx, query, net, now, productID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Product{}, ErrInvalidQuantityChange
+	}
+	if err != nil {
+		return models.Product{}, err
+	}
+
+	for warehouseID, delta := range byWarehouse {
+		if err := adjustWarehouseStock(ctx, tx, productID, warehouseID, delta, now); err != nil {
+			return models.Product{}, err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO movements (product_id, delta, created_at, updated_at, external_id, warehouse_id, reason, note)
+		SELECT $1, m.delta, m.created_at, $2, NULLIF(m.external_id, ''), NULLIF(m.warehouse_id, 0), m.reason, m.note
+		FROM unnest($3::int[], $4::timestamp[], $5::text[], $6::int[], $7::text[], $8::text[]) AS m(delta, created_at, external_id, warehouse_id, reason, note)
+	`, productID, now, deltas, createdAt, externalIDs, warehouseIDs, reasons, notes)
+	if err != nil {
+		if err := uniqueViolation(err); errors.Is(err, ErrDuplicatedExternalID) {
+			return models.Product{}, err
+		}
+		return models.Product{}, fmt.Errorf("%w: %v", ErrMovementLogFailed, err)
+	}
+	return p, tx.Commit()
+}
+
+// batchTime parses an RFC3339 timestamp, defaulting to fallback. COPY sends
+// binary values, so timestamps cannot be passed as strings as in Create.
+func batchTime(s string, fallback time.Time) time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return fallback
+	}
+	return t.UTC()
+}
+
+func (r *PostgresProductRepository) Merge(sourceID, targetID int) (models.Product, error) {
+	if sourceID == targetID {
+		return models.Product{}, ErrInvalidMerge
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), bulkTimeout)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Product{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var quantity, baseline int
+	err = tx.QueryRowContext(ctx, `SELECT quantity, baseline_quantity FROM products WHERE id = $1 FOR UPDATE`, sourceID).Scan(&quantity, &baseline)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Product{}, ErrProductNotFound
+	}
+	if err != nil {
+		return models.Product{}, err
+	}
+
+	query := `
+		UPDATE products
+		SET quantity = quantity + $1, baseline_quantity = baseline_quantity + $2, updated_at = $3
+		WHERE id = $4
+		RETURNING ` + productColumns
+	p, err := scanProduct(tx.QueryRowContext(ctx, query, quantity, baseline, time.Now().UTC(), targetID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Product{}, ErrProductNotFound
+	}
+	if err != nil {
+		return models.Product{}, err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO warehouse_stock (warehouse_id, product_id, quantity, updated_at)
+		SELECT warehouse_id, $1, quantity, $3 FROM warehouse_stock WHERE product_id = $2
+		ON CONFLICT (warehouse_id, product_id)
+		DO UPDATE SET quantity = warehouse_stock.quantity + EXCLUDED.quantity, updated_at = EXCLUDED.updated_at
+	`, targetID, sourceID, time.Now().UTC())
+	if err != nil {
+		return models.Product{}, err
+	}
+
+	// History is re-pointed first: deleting the source cascades to whatever
+	// still references it.
+	for _, table := range []string{"movements", "write_offs", "product_returns", "work_orders", "work_order_components", "product_mappings"} {
+		if _, err := tx.ExecContext(ctx, `UPDATE `+table+` SET product_id = $1 WHERE product_id = $2`, targetID, sourceID); err != nil {
+			return models.Product{}, err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM products WHERE id = $1`, sourceID); err != nil {
+		return models.Product{}, err
+	}
+
+	return p, tx.Commit()
+}
+
+// likeEscaper escapes LIKE wildcards so user input matches literally.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func (r *PostgresProductRepository) Suggest(q string, limit int, scope AccessScope) ([]ProductSuggestion, error) {
+	// The ILIKEs are served by the trigram indexes on name, sku and brand.
+	query := `
+		SELECT id, name, sku, brand FROM products
+		WHERE status <> $1 AND (name ILIKE $2 OR sku ILIKE $2 OR brand ILIKE $2)`
+	escaped := likeEscaper.Replace(q)
+	args := []any{models.ProductStatusDiscontinued, "%" + escaped + "%", escaped + "%", q, limit}
+	if scope.Categories != nil {
+		query += ` AND lower(category) = ANY($6)`
+		args = append(args, scope.Categories)
+	}
+	query += `
+		ORDER BY name ILIKE $3 DESC, similarity(name, $4) DESC, name
+		LIMIT $5`
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	suggestions := []ProductSuggestion{}
+	for rows.Next() {
+		var s ProductSuggestion
+		if err := rows.Scan(&s.ID, &s.Name, &s.SKU, &s.Brand); err != nil {
+			return nil, err
+		}
+		suggestions = append(suggestions, s)
+	}
+	return suggestions, rows.Err()
+}
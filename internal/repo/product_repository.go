@@ -2,6 +2,8 @@ package repo
 
 import (
 	"errors"
+	"strings"
+	"time"
 
 	"github.com/rogerio-castellano/inventory-tracker/internal/models"
 )
@@ -14,12 +16,89 @@ type ProductRepository interface {
 	Update(product models.Product) (models.Product, error)
 	Delete(id int) error
 	Filter(pf ProductFilter) ([]models.Product, int, error)
+	// Each calls fn with every product matching pf, in pf's order, as the
+	// rows are read, and stops at fn's first error. Offset and Limit are
+	// ignored. Meant for exports, the query may run for up to timeout
+	// rather than the usual few seconds.
+	Each(pf ProductFilter, timeout time.Duration, fn func(models.Product) error) error
+	// AdjustQuantity fails with ErrInvalidQuantityChange if the product is
+	// missing or would go negative, and with ErrStockReserved if a reduction
+	// would take units held by reservations.
 	AdjustQuantity(productId int, delta int) (models.Product, error)
 	GetByName(name string) (models.Product, error)
+	GetByExternalID(externalID string) (models.Product, error)
+	// GetByBarcode fails with ErrAmbiguousBarcode when several products
+	// share the barcode.
+	GetByBarcode(barcode string) (models.Product, error)
+	// GetBySKU fails with ErrAmbiguousSKU when several products share the
+	// SKU.
+	GetBySKU(sku string) (models.Product, error)
+	// GetByNames looks up many products in one round trip, keyed by
+	// NameKey. Names without a product are absent from the result.
+	GetByNames(names []string) (map[string]models.Product, error)
+	// CreateBatch inserts all products or none of them and returns how many were inserted.
+	CreateBatch(products []models.Product) (int, error)
+	// AdjustQuantities applies net deltas keyed by product ID all at once. It
+	// fails with ErrInvalidQuantityChange, changing nothing, if any product is
+	// missing or would go negative.
+	AdjustQuantities(deltas map[int]int) error
+	// AdjustWithMovements adds the movements' summed delta to one product
+	// and logs each movement, in a single transaction. Movements with a
+	// WarehouseID also change that warehouse's stock of the product. It
+	// fails with ErrInvalidQuantityChange if the product is missing or it or
+	// a warehouse's stock would go negative, with ErrStockReserved if a net
+	// reduction would take reserved units, with ErrWarehouseNotFound for an
+	// unknown warehouse and with ErrDuplicatedExternalID if an external ID is
+	// taken.
+	AdjustWithMovements(productID int, movements []models.Movement) (models.Product, error)
+	// Reprice applies every price change at once. It fails with
+	// ErrPriceChanged, changing nothing, if any product is missing or its
+	// price is no longer the change's From price.
+	Reprice(changes []PriceChange) error
+	// Merge folds the source product's stock and movement history into the
+	// target and deletes the source, returning the updated target.
+	Merge(sourceID, targetID int) (models.Product, error)
+	// Suggest returns up to limit products, discontinued ones aside, whose
+	// name or SKU contains q regardless of case. Names starting with q come
+	// first, then the closest names. Products outside scope are left out.
+	Suggest(q string, limit int, scope AccessScope) ([]ProductSuggestion, error)
 }
 
 var ErrInvalidQuantityChange = errors.New("insufficient quantity or product not found")
 var ErrProductNotFound = errors.New("product not found")
+var ErrInvalidMerge = errors.New("a product cannot be merged into itself")
+var ErrAmbiguousBarcode = errors.New("barcode matches more than one product")
+var ErrAmbiguousSKU = errors.New("SKU matches more than one product")
+var ErrPriceChanged = errors.New("product price changed or product no longer exists")
+
+// PriceChange moves a product's price from From to To.
+type PriceChange struct {
+	ProductID int     `json:"product_id"`
+	From      float64 `json:"from"`
+	To        float64 `json:"to"`
+}
+
+// ProductSuggestion is the little of a product an autocomplete box shows.
+type ProductSuggestion struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	SKU   string `json:"sku,omitempty"`
+	Brand string `json:"brand,omitempty"`
+}
+
+// NameKey is the form product names are compared in: they are unique
+// regardless of case, so "Mouse" and "mouse" are the same product.
+func NameKey(name string) string {
+	return strings.ToLower(name)
+}
+
+// productStatus defaults an empty status to active.
+func productStatus(s string) string {
+	if s == "" {
+		return models.ProductStatusActive
+	}
+	return s
+}
 
 func clamp(n, min, max int) int {
 	if n < min {
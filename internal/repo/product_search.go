@@ -0,0 +1,50 @@
+package repo
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+// SearchTerms splits a search query into lower-cased words.
+func SearchTerms(q string) []string {
+	return strings.FieldsFunc(strings.ToLower(q), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// MatchesTerm reports whether word matches a search term without a
+// dictionary: either starts with the other, regardless of case, so "chairs"
+// finds "chair" and the reverse. Words shorter than three letters only
+// match a term that starts with them exactly.
+func MatchesTerm(word, term string) bool {
+	word = strings.ToLower(word)
+	return strings.HasPrefix(word, term) || (len(word) >= 3 && strings.HasPrefix(term, word))
+}
+
+// searchScore ranks p for terms the way the in-memory repository can: every
+// term must match a word of the name, SKU or description, and matches in the
+// name or SKU weigh double. Zero means no match.
+func searchScore(p models.Product, terms []string) int {
+	fields := []struct {
+		words  []string
+		weight int
+	}{{SearchTerms(p.Name), 2}, {SearchTerms(p.SKU), 2}, {SearchTerms(p.Description), 1}}
+	score := 0
+	for _, term := range terms {
+		termScore := 0
+		for _, f := range fields {
+			for _, w := range f.words {
+				if MatchesTerm(w, term) {
+					termScore = max(termScore, f.weight)
+				}
+			}
+		}
+		if termScore == 0 {
+			return 0
+		}
+		score += termScore
+	}
+	return score
+}
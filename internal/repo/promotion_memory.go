@@ -0,0 +1,105 @@
+package repo
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+type InMemoryPromotionRepository struct {
+	mu         sync.Mutex
+	promotions []models.Promotion
+	nextID     int
+	products   ProductRepository
+}
+
+var _ PromotionRepository = (*InMemoryPromotionRepository)(nil)
+
+// NewInMemoryPromotionRepository checks products, when given, before
+// creating product promotions.
+func NewInMemoryPromotionRepository(products ProductRepository) *InMemoryPromotionRepository {
+	return &InMemoryPromotionRepository{nextID: 1, products: products}
+}
+
+func (r *InMemoryPromotionRepository) Create(p models.Promotion) (models.Promotion, error) {
+	if r.products != nil && p.ProductID != nil {
+		if _, err := r.products.GetByID(*p.ProductID); err != nil {
+			return models.Promotion{}, fmt.Errorf("product %d: %w", *p.ProductID, err)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p.ID = r.nextID
+	p.CreatedAt = time.Now().UTC()
+	r.nextID++
+	r.promotions = append(r.promotions, p)
+	return p, nil
+}
+
+func (r *InMemoryPromotionRepository) GetByID(id int) (models.Promotion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, p := range r.promotions {
+		if p.ID == id {
+			return p, nil
+		}
+	}
+	return models.Promotion{}, ErrPromotionNotFound
+}
+
+func (r *InMemoryPromotionRepository) List(activeOnly bool) ([]models.Promotion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	promotions := []models.Promotion{}
+	for _, p := range r.promotions {
+		if !activeOnly || p.Active {
+			promotions = append(promotions, p)
+		}
+	}
+	sort.Slice(promotions, func(i, j int) bool {
+		if !promotions[i].StartsAt.Equal(promotions[j].StartsAt) {
+			return promotions[i].StartsAt.After(promotions[j].StartsAt)
+		}
+		return promotions[i].ID > promotions[j].ID
+	})
+	return promotions, nil
+}
+
+func (r *InMemoryPromotionRepository) Delete(id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, p := range r.promotions {
+		if p.ID == id {
+			r.promotions = append(r.promotions[:i], r.promotions[i+1:]...)
+			return nil
+		}
+	}
+	return ErrPromotionNotFound
+}
+
+func (r *InMemoryPromotionRepository) Sync(now time.Time) (activated, deactivated []int, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, p := range r.promotions {
+		running := p.Running(now)
+		if running == p.Active {
+			continue
+		}
+		r.promotions[i].Active = running
+		if running {
+			activated = append(activated, p.ID)
+		} else {
+			deactivated = append(deactivated, p.ID)
+		}
+	}
+	return activated, deactivated, nil
+}
@@ -0,0 +1,137 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+type PostgresPromotionRepository struct {
+	db *sql.DB
+}
+
+var _ PromotionRepository = (*PostgresPromotionRepository)(nil)
+
+func NewPostgresPromotionRepository(db *sql.DB) *PostgresPromotionRepository {
+	return &PostgresPromotionRepository{db: db}
+}
+
+const promotionColumns = `id, name, product_id, category, price, percent_off, starts_at, ends_at, active, created_at`
+
+func scanPromotion(row rowScanner) (models.Promotion, error) {
+	var p models.Promotion
+	var productID sql.NullInt64
+	err := row.Scan(&p.ID, &p.Name, &productID, &p.Category, &p.Price, &p.PercentOff, &p.StartsAt, &p.EndsAt, &p.Active, &p.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Promotion{}, ErrPromotionNotFound
+	}
+	if productID.Valid {
+		id := int(productID.Int64)
+		p.ProductID = &id
+	}
+	p.StartsAt, p.EndsAt, p.CreatedAt = p.StartsAt.UTC(), p.EndsAt.UTC(), p.CreatedAt.UTC()
+	return p, err
+}
+
+func (r *PostgresPromotionRepository) Create(p models.Promotion) (models.Promotion, error) {
+	query := `
+		INSERT INTO promotions (name, product_id, category, price, percent_off, starts_at, ends_at, active, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id`
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var productID any
+	if p.ProductID != nil {
+		productID = *p.ProductID
+	}
+	p.CreatedAt = time.Now().UTC()
+	err := r.db.QueryRowContext(ctx, query, p.Name, productID, p.Category, p.Price, p.PercentOff, p.StartsAt, p.EndsAt, p.Active, p.CreatedAt).Scan(&p.ID)
+	if err != nil {
+		if strings.Contains(err.Error(), "23503") {
+			return models.Promotion{}, fmt.Errorf("%w: %v", ErrProductNotFound, err)
+		}
+		return models.Promotion{}, err
+	}
+	return p, nil
+}
+
+func (r *PostgresPromotionRepository) GetByID(id int) (models.Promotion, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return scanPromotion(r.db.QueryRowContext(ctx, `SELECT `+promotionColumns+` FROM promotions WHERE id = $1`, id))
+}
+
+func (r *PostgresPromotionRepository) List(activeOnly bool) ([]models.Promotion, error) {
+	query := `SELECT ` + promotionColumns + ` FROM promotions`
+	if activeOnly {
+		query += ` WHERE active`
+	}
+	query += ` ORDER BY starts_at DESC, id DESC`
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	promotions := []models.Promotion{}
+	for rows.Next() {
+		p, err := scanPromotion(rows)
+		if err != nil {
+			return nil, err
+		}
+		promotions = append(promotions, p)
+	}
+	return promotions, rows.Err()
+}
+
+func (r *PostgresPromotionRepository) Delete(id int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, `DELETE FROM promotions WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrPromotionNotFound
+	}
+	return nil
+}
+
+func (r *PostgresPromotionRepository) Sync(now time.Time) (activated, deactivated []int, err error) {
+	query := `
+		UPDATE promotions SET active = (starts_at <= $1 AND $1 < ends_at)
+		WHERE active <> (starts_at <= $1 AND $1 < ends_at)
+		RETURNING id, active`
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, now.UTC())
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int
+		var active bool
+		if err := rows.Scan(&id, &active); err != nil {
+			return nil, nil, err
+		}
+		if active {
+			activated = append(activated, id)
+		} else {
+			deactivated = append(deactivated, id)
+		}
+	}
+	return activated, deactivated, rows.Err()
+}
@@ -0,0 +1,25 @@
+package repo
+
+import (
+	"errors"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+// PromotionRepository defines the interface for time-bound promotions.
+type PromotionRepository interface {
+	// Create fails with ErrProductNotFound when the promotion targets a
+	// missing product.
+	Create(p models.Promotion) (models.Promotion, error)
+	GetByID(id int) (models.Promotion, error)
+	// List returns promotions by start, latest first; with activeOnly, just
+	// the ones currently changing prices.
+	List(activeOnly bool) ([]models.Promotion, error)
+	Delete(id int) error
+	// Sync activates the promotions whose window holds now and deactivates
+	// the rest, returning the IDs whose state changed.
+	Sync(now time.Time) (activated, deactivated []int, err error)
+}
+
+var ErrPromotionNotFound = errors.New("promotion not found")
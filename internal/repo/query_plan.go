@@ -0,0 +1,16 @@
+package repo
+
+// QueryPlan is the execution plan Postgres chose for one query.
+type QueryPlan struct {
+	Query string   `json:"query"`
+	Plan  []string `json:"plan"`
+	// IndexScan reports whether any node of the plan reads through an index.
+	IndexScan bool `json:"index_scan"`
+}
+
+// MovementQueryExplainer is implemented by movement repositories backed by a
+// database able to explain its plans. It is kept out of MovementRepository so
+// the in-memory implementation need not fake one.
+type MovementQueryExplainer interface {
+	ExplainGetByProductID(productID int, mf MovementFilter) ([]QueryPlan, error)
+}
@@ -0,0 +1,124 @@
+package repo
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+type InMemoryReservationRepository struct {
+	mu           sync.Mutex
+	reservations []models.StockReservation
+	nextID       int
+	products     *InMemoryProductRepository
+}
+
+var _ ReservationRepository = (*InMemoryReservationRepository)(nil)
+
+// NewInMemoryReservationRepository reserves the stock of products, which
+// then keeps reductions of its quantities clear of the reserved units.
+func NewInMemoryReservationRepository(products *InMemoryProductRepository) *InMemoryReservationRepository {
+	r := &InMemoryReservationRepository{nextID: 1, products: products}
+	products.reservations = r
+	return r
+}
+
+// reserved returns the units of the product held at now.
+func (r *InMemoryReservationRepository) reserved(productID int, now time.Time) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	units := 0
+	for _, res := range r.reservations {
+		if res.ProductID == productID && res.ExpiresAt.After(now) {
+			units += res.Quantity
+		}
+	}
+	return units
+}
+
+func (r *InMemoryReservationRepository) Create(res models.StockReservation) (models.StockReservation, error) {
+	p, err := r.products.GetByID(res.ProductID)
+	if err != nil {
+		return models.StockReservation{}, err
+	}
+	res.CreatedAt = time.Now().UTC()
+	reserved := r.reserved(res.ProductID, res.CreatedAt)
+	if p.Quantity-reserved < res.Quantity {
+		return models.StockReservation{}, fmt.Errorf("%w: %d of %d units are available", ErrStockReserved, max(p.Quantity-reserved, 0), p.Quantity)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	res.ID = r.nextID
+	res.ExpiresAt = res.ExpiresAt.UTC()
+	r.nextID++
+	r.reservations = append(r.reservations, res)
+	return res, nil
+}
+
+func (r *InMemoryReservationRepository) GetByID(id int) (models.StockReservation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, res := range r.reservations {
+		if res.ID == id {
+			return res, nil
+		}
+	}
+	return models.StockReservation{}, ErrReservationNotFound
+}
+
+func (r *InMemoryReservationRepository) Delete(id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, res := range r.reservations {
+		if res.ID == id {
+			r.reservations = append(r.reservations[:i], r.reservations[i+1:]...)
+			return nil
+		}
+	}
+	return ErrReservationNotFound
+}
+
+func (r *InMemoryReservationRepository) Reserved(productIDs []int, now time.Time) (map[int]int, error) {
+	reserved := map[int]int{}
+	for _, id := range productIDs {
+		if units := r.reserved(id, now); units > 0 {
+			reserved[id] = units
+		}
+	}
+	return reserved, nil
+}
+
+func (r *InMemoryReservationRepository) Count(productID int, now time.Time) (int, int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	reservations, units := 0, 0
+	for _, res := range r.reservations {
+		if res.ProductID == productID && res.ExpiresAt.After(now) {
+			reservations++
+			units += res.Quantity
+		}
+	}
+	return reservations, units, nil
+}
+
+func (r *InMemoryReservationRepository) DeleteExpired(now time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	kept := r.reservations[:0]
+	for _, res := range r.reservations {
+		if res.ExpiresAt.After(now) {
+			kept = append(kept, res)
+		}
+	}
+	expired := len(r.reservations) - len(kept)
+	r.reservations = kept
+	return expired, nil
+}
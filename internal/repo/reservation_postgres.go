@@ -0,0 +1,170 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+type PostgresReservationRepository struct {
+	db *sql.DB
+}
+
+var _ ReservationRepository = (*PostgresReservationRepository)(nil)
+
+func NewPostgresReservationRepository(db *sql.DB) *PostgresReservationRepository {
+	return &PostgresReservationRepository{db: db}
+}
+
+const reservationColumns = `id, product_id, quantity, reference, expires_at, created_by, created_at`
+
+func scanReservation(row rowScanner) (models.StockReservation, error) {
+	var r models.StockReservation
+	err := row.Scan(&r.ID, &r.ProductID, &r.Quantity, &r.Reference, &r.ExpiresAt, &r.CreatedBy, &r.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.StockReservation{}, ErrReservationNotFound
+	}
+	r.ExpiresAt, r.CreatedAt = r.ExpiresAt.UTC(), r.CreatedAt.UTC()
+	return r, err
+}
+
+// lockStock locks the product's row until tx ends, so reservations and
+// adjustments of the product queue behind each other, and returns its
+// quantity and the units reserved at now.
+func lockStock(ctx context.Context, tx *sql.Tx, productID int, now time.Time) (quantity, reserved int, err error) {
+	err = tx.QueryRowContext(ctx, `SELECT quantity FROM products WHERE id = $1 FOR UPDATE`, productID).Scan(&quantity)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, 0, ErrProductNotFound
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+	err = tx.QueryRowContext(ctx, `SELECT COALESCE(SUM(quantity), 0) FROM stock_reservations
+		WHERE product_id = $1 AND expires_at > $2`, productID, now).Scan(&reserved)
+	return quantity, reserved, err
+}
+
+// checkUnreserved fails with ErrStockReserved when adding delta to the
+// product would leave less than its reserved stock. Only reductions are
+// checked: stock coming in never takes reserved units.
+func checkUnreserved(ctx context.Context, tx *sql.Tx, productID, delta int, now time.Time) error {
+	if delta >= 0 {
+		return nil
+	}
+	quantity, reserved, err := lockStock(ctx, tx, productID, now)
+	switch {
+	case errors.Is(err, ErrProductNotFound):
+		return ErrInvalidQuantityChange
+	case err != nil:
+		return err
+	case quantity+delta >= 0 && quantity+delta < reserved:
+		return fmt.Errorf("%w: %d of %d units are reserved", ErrStockReserved, reserved, quantity)
+	}
+	return nil
+}
+
+func (r *PostgresReservationRepository) Create(res models.StockReservation) (models.StockReservation, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.StockReservation{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res.CreatedAt = time.Now().UTC()
+	quantity, reserved, err := lockStock(ctx, tx, res.ProductID, res.CreatedAt)
+	if err != nil {
+		return models.StockReservation{}, err
+	}
+	if quantity-reserved < res.Quantity {
+		return models.StockReservation{}, fmt.Errorf("%w: %d of %d units are available", ErrStockReserved, max(quantity-reserved, 0), quantity)
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO stock_reservations (product_id, quantity, reference, expires_at, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`,
+		res.ProductID, res.Quantity, res.Reference, res.ExpiresAt.UTC(), res.CreatedBy, res.CreatedAt).Scan(&res.ID)
+	if err != nil {
+		return models.StockReservation{}, err
+	}
+	res.ExpiresAt = res.ExpiresAt.UTC()
+	return res, tx.Commit()
+}
+
+func (r *PostgresReservationRepository) GetByID(id int) (models.StockReservation, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return scanReservation(r.db.QueryRowContext(ctx, `SELECT `+reservationColumns+` FROM stock_reservations WHERE id = $1`, id))
+}
+
+func (r *PostgresReservationRepository) Delete(id int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, `DELETE FROM stock_reservations WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrReservationNotFound
+	}
+	return nil
+}
+
+func (r *PostgresReservationRepository) Reserved(productIDs []int, now time.Time) (map[int]int, error) {
+	reserved := map[int]int{}
+	if len(productIDs) == 0 {
+		return reserved, nil
+	}
+	ids := make([]int32, len(productIDs))
+	for i, id := range productIDs {
+		ids[i] = int32(id)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `SELECT product_id, SUM(quantity) FROM stock_reservations
+		WHERE product_id = ANY($1) AND expires_at > $2 GROUP BY product_id`, ids, now.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id, units int
+		if err := rows.Scan(&id, &units); err != nil {
+			return nil, err
+		}
+		reserved[id] = units
+	}
+	return reserved, rows.Err()
+}
+
+func (r *PostgresReservationRepository) Count(productID int, now time.Time) (int, int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var reservations, units int
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*), COALESCE(SUM(quantity), 0) FROM stock_reservations
+		WHERE product_id = $1 AND expires_at > $2`, productID, now.UTC()).Scan(&reservations, &units)
+	return reservations, units, err
+}
+
+func (r *PostgresReservationRepository) DeleteExpired(now time.Time) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), bulkTimeout)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, `DELETE FROM stock_reservations WHERE expires_at <= $1`, now.UTC())
+	if err != nil {
+		return 0, err
+	}
+	n, _ := res.RowsAffected()
+	return int(n), nil
+}
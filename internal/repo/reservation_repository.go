@@ -0,0 +1,35 @@
+package repo
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+// ReservationRepository defines the interface for stock reservations. A
+// reservation counts against the product's stock until it expires, whether
+// or not DeleteExpired has removed it yet.
+type ReservationRepository interface {
+	// Create fails with ErrProductNotFound for a missing product and with
+	// ErrStockReserved when fewer units than asked for are unreserved.
+	Create(r models.StockReservation) (models.StockReservation, error)
+	GetByID(id int) (models.StockReservation, error)
+	Delete(id int) error
+	// Reserved returns the units held at now by unexpired reservations of
+	// the given products. Products without any are absent.
+	Reserved(productIDs []int, now time.Time) (map[int]int, error)
+	// Count returns how many unexpired reservations of the product there are
+	// at now and how many units they hold.
+	Count(productID int, now time.Time) (reservations, units int, err error)
+	// DeleteExpired removes the reservations expired at now and returns how
+	// many there were.
+	DeleteExpired(now time.Time) (int, error)
+}
+
+var ErrReservationNotFound = errors.New("reservation not found")
+
+// ErrStockReserved is returned when the units asked for exist but some of
+// them are held by reservations. It is an ErrInvalidQuantityChange.
+var ErrStockReserved = fmt.Errorf("%w: the units are reserved", ErrInvalidQuantityChange)
@@ -0,0 +1,96 @@
+package repo
+
+import (
+	"sync"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+type InMemoryReturnRepository struct {
+	mu      sync.Mutex
+	returns []models.Return
+}
+
+var _ ReturnRepository = (*InMemoryReturnRepository)(nil)
+
+func NewInMemoryReturnRepository() *InMemoryReturnRepository {
+	return &InMemoryReturnRepository{}
+}
+
+func (r *InMemoryReturnRepository) Create(ret models.Return) (models.Return, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ret.ID = len(r.returns) + 1
+	ret.Status = models.ReturnStatusPending
+	ret.CreatedAt = time.Now().UTC()
+	r.returns = append(r.returns, ret)
+	return ret, nil
+}
+
+func (r *InMemoryReturnRepository) GetByID(id int) (models.Return, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if id < 1 || id > len(r.returns) {
+		return models.Return{}, ErrReturnNotFound
+	}
+	return r.returns[id-1], nil
+}
+
+func (r *InMemoryReturnRepository) List(status string) ([]models.Return, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	returns := []models.Return{}
+	for i := len(r.returns) - 1; i >= 0; i-- {
+		if status == "" || r.returns[i].Status == status {
+			returns = append(returns, r.returns[i])
+		}
+	}
+	return returns, nil
+}
+
+func (r *InMemoryReturnRepository) ReturnedQuantity(movementID int) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	total := 0
+	for _, ret := range r.returns {
+		if ret.MovementID == movementID {
+			total += ret.Quantity
+		}
+	}
+	return total, nil
+}
+
+func (r *InMemoryReturnRepository) Inspect(id int, from string, ret models.Return) (models.Return, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if id < 1 || id > len(r.returns) {
+		return models.Return{}, ErrReturnNotFound
+	}
+	existing := &r.returns[id-1]
+	if existing.Status != from {
+		return models.Return{}, ErrReturnStatusChanged
+	}
+	now := time.Now().UTC()
+	existing.Status = ret.Status
+	existing.InspectionNote = ret.InspectionNote
+	existing.InspectedBy = ret.InspectedBy
+	existing.InspectedAt = &now
+	return *existing, nil
+}
+
+func (r *InMemoryReturnRepository) SetRestockMovement(id, movementID int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if id < 1 || id > len(r.returns) {
+		return ErrReturnNotFound
+	}
+	r.returns[id-1].RestockMovementID = &movementID
+	return nil
+}
@@ -0,0 +1,123 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+type PostgresReturnRepository struct {
+	db *sql.DB
+}
+
+var _ ReturnRepository = (*PostgresReturnRepository)(nil)
+
+func NewPostgresReturnRepository(db *sql.DB) *PostgresReturnRepository {
+	return &PostgresReturnRepository{db: db}
+}
+
+const returnColumns = `id, product_id, movement_id, order_ref, quantity, reason, status, inspection_note,
+	restock_movement_id, created_by, created_at, inspected_by, inspected_at`
+
+func scanReturn(row rowScanner) (models.Return, error) {
+	var ret models.Return
+	var restockMovementID sql.NullInt64
+	var inspectedAt sql.NullTime
+	err := row.Scan(&ret.ID, &ret.ProductID, &ret.MovementID, &ret.OrderRef, &ret.Quantity, &ret.Reason, &ret.Status,
+		&ret.InspectionNote, &restockMovementID, &ret.CreatedBy, &ret.CreatedAt, &ret.InspectedBy, &inspectedAt)
+	if restockMovementID.Valid {
+		id := int(restockMovementID.Int64)
+		ret.RestockMovementID = &id
+	}
+	if inspectedAt.Valid {
+		t := inspectedAt.Time.UTC()
+		ret.InspectedAt = &t
+	}
+	ret.CreatedAt = ret.CreatedAt.UTC()
+	return ret, err
+}
+
+func (r *PostgresReturnRepository) Create(ret models.Return) (models.Return, error) {
+	query := `INSERT INTO product_returns (product_id, movement_id, order_ref, quantity, reason, status, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	ret.Status = models.ReturnStatusPending
+	ret.CreatedAt = time.Now().UTC()
+	err := r.db.QueryRowContext(ctx, query, ret.ProductID, ret.MovementID, ret.OrderRef, ret.Quantity, ret.Reason,
+		ret.Status, ret.CreatedBy, ret.CreatedAt).Scan(&ret.ID)
+	if err != nil {
+		return models.Return{}, err
+	}
+	return ret, nil
+}
+
+func (r *PostgresReturnRepository) GetByID(id int) (models.Return, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	ret, err := scanReturn(r.db.QueryRowContext(ctx, `SELECT `+returnColumns+` FROM product_returns WHERE id = $1`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Return{}, ErrReturnNotFound
+	}
+	return ret, err
+}
+
+func (r *PostgresReturnRepository) List(status string) ([]models.Return, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `SELECT ` + returnColumns + ` FROM product_returns WHERE ($1 = '' OR status = $1) ORDER BY created_at DESC, id DESC`
+	rows, err := r.db.QueryContext(ctx, query, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	returns := []models.Return{}
+	for rows.Next() {
+		ret, err := scanReturn(rows)
+		if err != nil {
+			return nil, err
+		}
+		returns = append(returns, ret)
+	}
+	return returns, rows.Err()
+}
+
+func (r *PostgresReturnRepository) ReturnedQuantity(movementID int) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var total int
+	err := r.db.QueryRowContext(ctx, `SELECT COALESCE(SUM(quantity), 0) FROM product_returns WHERE movement_id = $1`, movementID).Scan(&total)
+	return total, err
+}
+
+func (r *PostgresReturnRepository) Inspect(id int, from string, ret models.Return) (models.Return, error) {
+	query := `UPDATE product_returns SET status = $1, inspection_note = $2, inspected_by = $3, inspected_at = $4
+		WHERE id = $5 AND status = $6 RETURNING ` + returnColumns
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	updated, err := scanReturn(r.db.QueryRowContext(ctx, query, ret.Status, ret.InspectionNote, ret.InspectedBy, time.Now().UTC(), id, from))
+	if errors.Is(err, sql.ErrNoRows) {
+		if _, err := r.GetByID(id); err != nil {
+			return models.Return{}, err
+		}
+		return models.Return{}, ErrReturnStatusChanged
+	}
+	return updated, err
+}
+
+func (r *PostgresReturnRepository) SetRestockMovement(id, movementID int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `UPDATE product_returns SET restock_movement_id = $1 WHERE id = $2`, movementID, id)
+	return err
+}
@@ -0,0 +1,27 @@
+package repo
+
+import (
+	"errors"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+// ReturnRepository defines the interface for customer return data operations.
+type ReturnRepository interface {
+	Create(ret models.Return) (models.Return, error)
+	GetByID(id int) (models.Return, error)
+	// List returns all returns, newest first; an empty status matches any.
+	List(status string) ([]models.Return, error)
+	// ReturnedQuantity sums the quantity already returned against a movement.
+	ReturnedQuantity(movementID int) (int, error)
+	// Inspect records an inspection outcome. It fails with
+	// ErrReturnStatusChanged when the return is no longer in status from, so
+	// two concurrent inspections cannot both restock.
+	Inspect(id int, from string, ret models.Return) (models.Return, error)
+	SetRestockMovement(id, movementID int) error
+}
+
+var (
+	ErrReturnNotFound      = errors.New("return not found")
+	ErrReturnStatusChanged = errors.New("return status changed concurrently")
+)
@@ -0,0 +1,39 @@
+package repo
+
+import (
+	"sync"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+type InMemoryRuntimeConfigRepository struct {
+	mu      sync.Mutex
+	configs map[string]models.RuntimeConfig
+}
+
+var _ RuntimeConfigRepository = (*InMemoryRuntimeConfigRepository)(nil)
+
+func NewInMemoryRuntimeConfigRepository() *InMemoryRuntimeConfigRepository {
+	return &InMemoryRuntimeConfigRepository{configs: map[string]models.RuntimeConfig{}}
+}
+
+func (r *InMemoryRuntimeConfigRepository) Get(tenant string) (models.RuntimeConfig, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.configs[tenant]
+	if !ok {
+		return models.RuntimeConfig{Tenant: tenant}, nil
+	}
+	return c, nil
+}
+
+func (r *InMemoryRuntimeConfigRepository) Put(c models.RuntimeConfig) (models.RuntimeConfig, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c.UpdatedAt = time.Now().UTC()
+	r.configs[c.Tenant] = c
+	return c, nil
+}
@@ -0,0 +1,73 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+type PostgresRuntimeConfigRepository struct {
+	db *sql.DB
+}
+
+var _ RuntimeConfigRepository = (*PostgresRuntimeConfigRepository)(nil)
+
+func NewPostgresRuntimeConfigRepository(db *sql.DB) *PostgresRuntimeConfigRepository {
+	return &PostgresRuntimeConfigRepository{db: db}
+}
+
+func (r *PostgresRuntimeConfigRepository) Get(tenant string) (models.RuntimeConfig, error) {
+	query := `SELECT roles, rate_limits, updated_at FROM runtime_configs WHERE tenant = $1`
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	c := models.RuntimeConfig{Tenant: tenant}
+	var roles, limits []byte
+	err := r.db.QueryRowContext(ctx, query, tenant).Scan(&roles, &limits, &c.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return c, nil
+	}
+	if err != nil {
+		return models.RuntimeConfig{}, err
+	}
+	if len(roles) > 0 {
+		if err := json.Unmarshal(roles, &c.Roles); err != nil {
+			return models.RuntimeConfig{}, fmt.Errorf("failed to read roles: %w", err)
+		}
+	}
+	if len(limits) > 0 {
+		if err := json.Unmarshal(limits, &c.RateLimits); err != nil {
+			return models.RuntimeConfig{}, fmt.Errorf("failed to read rate limits: %w", err)
+		}
+	}
+	c.UpdatedAt = c.UpdatedAt.UTC()
+	return c, nil
+}
+
+func (r *PostgresRuntimeConfigRepository) Put(c models.RuntimeConfig) (models.RuntimeConfig, error) {
+	roles, err := json.Marshal(c.Roles)
+	if err != nil {
+		return models.RuntimeConfig{}, err
+	}
+	limits, err := json.Marshal(c.RateLimits)
+	if err != nil {
+		return models.RuntimeConfig{}, err
+	}
+
+	query := `
+		INSERT INTO runtime_configs (tenant, roles, rate_limits, updated_at) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (tenant) DO UPDATE SET roles = EXCLUDED.roles, rate_limits = EXCLUDED.rate_limits, updated_at = EXCLUDED.updated_at`
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	c.UpdatedAt = time.Now().UTC()
+	if _, err := r.db.ExecContext(ctx, query, c.Tenant, roles, limits, c.UpdatedAt); err != nil {
+		return models.RuntimeConfig{}, err
+	}
+	return c, nil
+}
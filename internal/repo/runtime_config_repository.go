@@ -0,0 +1,10 @@
+package repo
+
+import "github.com/rogerio-castellano/inventory-tracker/internal/models"
+
+// RuntimeConfigRepository stores one applied runtime configuration per tenant.
+type RuntimeConfigRepository interface {
+	// Get returns a configuration with no sections when the tenant has none.
+	Get(tenant string) (models.RuntimeConfig, error)
+	Put(c models.RuntimeConfig) (models.RuntimeConfig, error)
+}
@@ -0,0 +1,113 @@
+package repo
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+type InMemorySnapshotRepository struct {
+	mu        sync.Mutex
+	products  ProductRepository
+	snapshots []models.Snapshot
+	nextID    int
+}
+
+var _ SnapshotRepository = (*InMemorySnapshotRepository)(nil)
+
+func NewInMemorySnapshotRepository(products ProductRepository) *InMemorySnapshotRepository {
+	return &InMemorySnapshotRepository{products: products, nextID: 1}
+}
+
+func (r *InMemorySnapshotRepository) Capture(label, createdBy string, scheduled bool) (models.Snapshot, error) {
+	products, err := r.products.GetAll()
+	if err != nil {
+		return models.Snapshot{}, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s := models.Snapshot{ID: r.nextID, Label: label, CreatedBy: createdBy, Scheduled: scheduled, CreatedAt: time.Now().UTC(), Items: []models.SnapshotItem{}}
+	for _, p := range products {
+		s.Items = append(s.Items, models.SnapshotItem{ProductID: p.ID, Name: p.Name, Quantity: p.Quantity, Price: p.Price})
+	}
+	slices.SortFunc(s.Items, func(a, b models.SnapshotItem) int { return a.ProductID - b.ProductID })
+	summarizeSnapshot(&s)
+	r.nextID++
+	r.snapshots = append(r.snapshots, s)
+	return s, nil
+}
+
+func (r *InMemorySnapshotRepository) GetByID(id int) (models.Snapshot, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, s := range r.snapshots {
+		if s.ID == id {
+			return s, nil
+		}
+	}
+	return models.Snapshot{}, ErrSnapshotNotFound
+}
+
+func (r *InMemorySnapshotRepository) List() ([]models.Snapshot, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	snapshots := make([]models.Snapshot, 0, len(r.snapshots))
+	for i := len(r.snapshots) - 1; i >= 0; i-- {
+		s := r.snapshots[i]
+		s.Items = nil
+		snapshots = append(snapshots, s)
+	}
+	return snapshots, nil
+}
+
+func (r *InMemorySnapshotRepository) ListScheduledSince(since time.Time, withItems bool) ([]models.Snapshot, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	snapshots := []models.Snapshot{}
+	for _, s := range r.snapshots {
+		if !s.Scheduled || s.CreatedAt.Before(since) {
+			continue
+		}
+		if !withItems {
+			s.Items = nil
+		}
+		snapshots = append(snapshots, s)
+	}
+	return snapshots, nil
+}
+
+func (r *InMemorySnapshotRepository) Prune(keepDailyAfter, keepMonthlyAfter time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Snapshots are appended in capture order, so the first one seen in a
+	// month is that month's earliest.
+	firstOfMonth := map[string]int{}
+	for _, s := range r.snapshots {
+		month := s.CreatedAt.Format("2006-01")
+		if _, ok := firstOfMonth[month]; s.Scheduled && !ok {
+			firstOfMonth[month] = s.ID
+		}
+	}
+
+	kept := r.snapshots[:0]
+	pruned := 0
+	for _, s := range r.snapshots {
+		expired := s.Scheduled && s.CreatedAt.Before(keepDailyAfter) &&
+			(s.CreatedAt.Before(keepMonthlyAfter) || firstOfMonth[s.CreatedAt.Format("2006-01")] != s.ID)
+		if expired {
+			pruned++
+			continue
+		}
+		kept = append(kept, s)
+	}
+	r.snapshots = kept
+	return pruned, nil
+}
@@ -0,0 +1,201 @@
+package repo
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+type PostgresSnapshotRepository struct {
+	db *sql.DB
+}
+
+var _ SnapshotRepository = (*PostgresSnapshotRepository)(nil)
+
+func NewPostgresSnapshotRepository(db *sql.DB) *PostgresSnapshotRepository {
+	return &PostgresSnapshotRepository{db: db}
+}
+
+const snapshotColumns = `id, label, created_by, scheduled, created_at, product_count, total_quantity, total_value`
+
+func scanSnapshot(row rowScanner, extra ...any) (models.Snapshot, error) {
+	var s models.Snapshot
+	dest := append([]any{&s.ID, &s.Label, &s.CreatedBy, &s.Scheduled, &s.CreatedAt, &s.ProductCount, &s.TotalQuantity, &s.TotalValue}, extra...)
+	err := row.Scan(dest...)
+	s.CreatedAt = s.CreatedAt.UTC()
+	return s, err
+}
+
+// Capture reads every product in a repeatable-read transaction, so the
+// snapshot is consistent even while stock keeps moving.
+func (r *PostgresSnapshotRepository) Capture(label, createdBy string, scheduled bool) (models.Snapshot, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), bulkTimeout)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead})
+	if err != nil {
+		return models.Snapshot{}, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `SELECT id, name, quantity, price FROM products ORDER BY id`)
+	if err != nil {
+		return models.Snapshot{}, err
+	}
+	s := models.Snapshot{Label: label, CreatedBy: createdBy, Scheduled: scheduled, CreatedAt: time.Now().UTC(), Items: []models.SnapshotItem{}}
+	for rows.Next() {
+		var item models.SnapshotItem
+		if err := rows.Scan(&item.ProductID, &item.Name, &item.Quantity, &item.Price); err != nil {
+			rows.Close()
+			return models.Snapshot{}, err
+		}
+		s.Items = append(s.Items, item)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return models.Snapshot{}, err
+	}
+	summarizeSnapshot(&s)
+
+	items, err := encodeSnapshotItems(s.Items)
+	if err != nil {
+		return models.Snapshot{}, err
+	}
+	query := `INSERT INTO inventory_snapshots (label, created_by, scheduled, created_at, product_count, total_quantity, total_value, items)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`
+	if err := tx.QueryRowContext(ctx, query, s.Label, s.CreatedBy, s.Scheduled, s.CreatedAt, s.ProductCount, s.TotalQuantity, s.TotalValue, items).Scan(&s.ID); err != nil {
+		return models.Snapshot{}, err
+	}
+	return s, tx.Commit()
+}
+
+func (r *PostgresSnapshotRepository) GetByID(id int) (models.Snapshot, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), bulkTimeout)
+	defer cancel()
+
+	var items []byte
+	s, err := scanSnapshot(r.db.QueryRowContext(ctx, `SELECT `+snapshotColumns+`, items FROM inventory_snapshots WHERE id = $1`, id), &items)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Snapshot{}, ErrSnapshotNotFound
+	}
+	if err != nil {
+		return models.Snapshot{}, err
+	}
+	s.Items, err = decodeSnapshotItems(items)
+	return s, err
+}
+
+func (r *PostgresSnapshotRepository) List() ([]models.Snapshot, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `SELECT `+snapshotColumns+` FROM inventory_snapshots ORDER BY created_at DESC, id DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	snapshots := []models.Snapshot{}
+	for rows.Next() {
+		s, err := scanSnapshot(rows)
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, s)
+	}
+	return snapshots, rows.Err()
+}
+
+func (r *PostgresSnapshotRepository) ListScheduledSince(since time.Time, withItems bool) ([]models.Snapshot, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), bulkTimeout)
+	defer cancel()
+
+	columns := snapshotColumns
+	if withItems {
+		columns += ", items"
+	}
+	rows, err := r.db.QueryContext(ctx, `SELECT `+columns+` FROM inventory_snapshots
+		WHERE scheduled AND created_at >= $1 ORDER BY created_at, id`, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	snapshots := []models.Snapshot{}
+	for rows.Next() {
+		var items []byte
+		var extra []any
+		if withItems {
+			extra = append(extra, &items)
+		}
+		s, err := scanSnapshot(rows, extra...)
+		if err != nil {
+			return nil, err
+		}
+		if withItems {
+			if s.Items, err = decodeSnapshotItems(items); err != nil {
+				return nil, err
+			}
+		}
+		snapshots = append(snapshots, s)
+	}
+	return snapshots, rows.Err()
+}
+
+func (r *PostgresSnapshotRepository) Prune(keepDailyAfter, keepMonthlyAfter time.Time) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), bulkTimeout)
+	defer cancel()
+
+	query := `DELETE FROM inventory_snapshots
+		WHERE scheduled AND created_at < $1
+		AND (created_at < $2 OR id NOT IN (
+			SELECT DISTINCT ON (date_trunc('month', created_at)) id
+			FROM inventory_snapshots
+			WHERE scheduled
+			ORDER BY date_trunc('month', created_at), created_at, id))`
+	res, err := r.db.ExecContext(ctx, query, keepDailyAfter, keepMonthlyAfter)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+func summarizeSnapshot(s *models.Snapshot) {
+	s.ProductCount, s.TotalQuantity, s.TotalValue = len(s.Items), 0, 0
+	for _, item := range s.Items {
+		s.TotalQuantity += item.Quantity
+		s.TotalValue += float64(item.Quantity) * item.Price
+	}
+}
+
+// encodeSnapshotItems stores items as gzip-compressed JSON: a catalog of tens
+// of thousands of products then takes a few hundred kilobytes per snapshot.
+func encodeSnapshotItems(items []models.SnapshotItem) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(zw).Encode(items); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeSnapshotItems(data []byte) ([]models.SnapshotItem, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	items := []models.SnapshotItem{}
+	return items, json.NewDecoder(zr).Decode(&items)
+}
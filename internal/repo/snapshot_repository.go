@@ -0,0 +1,27 @@
+package repo
+
+import (
+	"errors"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+// SnapshotRepository defines the interface for inventory snapshots.
+type SnapshotRepository interface {
+	// Capture records the current quantity and price of every product.
+	Capture(label, createdBy string, scheduled bool) (models.Snapshot, error)
+	// GetByID returns a snapshot with its items.
+	GetByID(id int) (models.Snapshot, error)
+	// List returns snapshots newest first, without their items.
+	List() ([]models.Snapshot, error)
+	// ListScheduledSince returns the scheduled snapshots taken at or after
+	// since, oldest first, with their items only when withItems is set.
+	ListScheduledSince(since time.Time, withItems bool) ([]models.Snapshot, error)
+	// Prune deletes scheduled snapshots taken before keepDailyAfter, except the
+	// first one of each month taken after keepMonthlyAfter. Manual snapshots
+	// are never pruned.
+	Prune(keepDailyAfter, keepMonthlyAfter time.Time) (int, error)
+}
+
+var ErrSnapshotNotFound = errors.New("snapshot not found")
@@ -0,0 +1,55 @@
+package repo
+
+import (
+	"errors"
+	"slices"
+	"sync"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+// InMemorySubstituteRepository keeps links by product ID and reads the
+// products themselves from the product repository.
+type InMemorySubstituteRepository struct {
+	mu          sync.Mutex
+	substitutes map[int][]int
+	products    ProductRepository
+}
+
+var _ SubstituteRepository = (*InMemorySubstituteRepository)(nil)
+
+func NewInMemorySubstituteRepository(products ProductRepository) *InMemorySubstituteRepository {
+	return &InMemorySubstituteRepository{substitutes: map[int][]int{}, products: products}
+}
+
+func (r *InMemorySubstituteRepository) List(productID int) ([]models.Product, error) {
+	r.mu.Lock()
+	ids := slices.Clone(r.substitutes[productID])
+	r.mu.Unlock()
+
+	products := []models.Product{}
+	for _, id := range ids {
+		p, err := r.products.GetByID(id)
+		if errors.Is(err, ErrProductNotFound) {
+			continue // deleted since it was linked
+		}
+		if err != nil {
+			return nil, err
+		}
+		products = append(products, p)
+	}
+	return products, nil
+}
+
+func (r *InMemorySubstituteRepository) Set(productID int, substituteIDs []int) error {
+	for _, id := range append([]int{productID}, substituteIDs...) {
+		if _, err := r.products.GetByID(id); err != nil {
+			return err
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.substitutes[productID] = slices.Clone(substituteIDs)
+	return nil
+}
@@ -0,0 +1,72 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+type PostgresSubstituteRepository struct {
+	db *sql.DB
+}
+
+var _ SubstituteRepository = (*PostgresSubstituteRepository)(nil)
+
+func NewPostgresSubstituteRepository(db *sql.DB) *PostgresSubstituteRepository {
+	return &PostgresSubstituteRepository{db: db}
+}
+
+func (r *PostgresSubstituteRepository) List(productID int) ([]models.Product, error) {
+	query := `SELECT ` + productColumns + ` FROM products
+		JOIN (SELECT substitute_id, rank FROM product_substitutes WHERE product_id = $1) s ON s.substitute_id = products.id
+		ORDER BY s.rank`
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	products := []models.Product{}
+	for rows.Next() {
+		p, err := scanProduct(rows)
+		if err != nil {
+			return nil, err
+		}
+		products = append(products, p)
+	}
+	return products, rows.Err()
+}
+
+func (r *PostgresSubstituteRepository) Set(productID int, substituteIDs []int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM product_substitutes WHERE product_id = $1`, productID); err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	for rank, id := range substituteIDs {
+		_, err := tx.ExecContext(ctx, `INSERT INTO product_substitutes (product_id, substitute_id, rank, created_at) VALUES ($1, $2, $3, $4)`,
+			productID, id, rank, now)
+		if err != nil {
+			if strings.Contains(err.Error(), "23503") {
+				return fmt.Errorf("%w: %v", ErrProductNotFound, err)
+			}
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,14 @@
+package repo
+
+import "github.com/rogerio-castellano/inventory-tracker/internal/models"
+
+// SubstituteRepository stores, per product, the products that can stand in
+// for it when it runs out. Links are one-way: B substituting A says nothing
+// about A substituting B.
+type SubstituteRepository interface {
+	// List returns the substitutes of productID in order of preference.
+	List(productID int) ([]models.Product, error)
+	// Set replaces the substitutes of productID at once. It fails with
+	// ErrProductNotFound, changing nothing, if any product is missing.
+	Set(productID int, substituteIDs []int) error
+}
@@ -0,0 +1,175 @@
+package repo
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+type InMemorySupplierRepository struct {
+	mu        sync.Mutex
+	suppliers []models.Supplier
+	links     []models.ProductSupplier
+	nextID    int
+	products  ProductRepository
+}
+
+var _ SupplierRepository = (*InMemorySupplierRepository)(nil)
+
+func NewInMemorySupplierRepository(products ProductRepository) *InMemorySupplierRepository {
+	return &InMemorySupplierRepository{nextID: 1, products: products}
+}
+
+func (r *InMemorySupplierRepository) nameTaken(name string, exceptID int) bool {
+	for _, s := range r.suppliers {
+		if s.ID != exceptID && strings.EqualFold(s.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *InMemorySupplierRepository) find(id int) (int, bool) {
+	for i, s := range r.suppliers {
+		if s.ID == id {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func (r *InMemorySupplierRepository) Create(s models.Supplier) (models.Supplier, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.nameTaken(s.Name, 0) {
+		return models.Supplier{}, fmt.Errorf("%w: supplier %q", ErrDuplicatedValueUnique, s.Name)
+	}
+	s.ID = r.nextID
+	s.CreatedAt = time.Now().UTC()
+	s.UpdatedAt = s.CreatedAt
+	r.nextID++
+	r.suppliers = append(r.suppliers, s)
+	return s, nil
+}
+
+func (r *InMemorySupplierRepository) GetByID(id int) (models.Supplier, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if i, ok := r.find(id); ok {
+		return r.suppliers[i], nil
+	}
+	return models.Supplier{}, ErrSupplierNotFound
+}
+
+func (r *InMemorySupplierRepository) List() ([]models.Supplier, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	suppliers := append([]models.Supplier{}, r.suppliers...)
+	sort.Slice(suppliers, func(i, j int) bool { return suppliers[i].Name < suppliers[j].Name })
+	return suppliers, nil
+}
+
+func (r *InMemorySupplierRepository) Update(s models.Supplier) (models.Supplier, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i, ok := r.find(s.ID)
+	if !ok {
+		return models.Supplier{}, ErrSupplierNotFound
+	}
+	if r.nameTaken(s.Name, s.ID) {
+		return models.Supplier{}, fmt.Errorf("%w: supplier %q", ErrDuplicatedValueUnique, s.Name)
+	}
+	s.CreatedAt = r.suppliers[i].CreatedAt
+	s.UpdatedAt = time.Now().UTC()
+	r.suppliers[i] = s
+	for j := range r.links {
+		if r.links[j].SupplierID == s.ID {
+			r.links[j].Supplier = s.Name
+		}
+	}
+	return s, nil
+}
+
+func (r *InMemorySupplierRepository) Delete(id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i, ok := r.find(id)
+	if !ok {
+		return ErrSupplierNotFound
+	}
+	r.suppliers = append(r.suppliers[:i], r.suppliers[i+1:]...)
+	kept := r.links[:0]
+	for _, l := range r.links {
+		if l.SupplierID != id {
+			kept = append(kept, l)
+		}
+	}
+	r.links = kept
+	return nil
+}
+
+func (r *InMemorySupplierRepository) Link(ps models.ProductSupplier) (models.ProductSupplier, error) {
+	if _, err := r.products.GetByID(ps.ProductID); err != nil {
+		return models.ProductSupplier{}, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i, ok := r.find(ps.SupplierID)
+	if !ok {
+		return models.ProductSupplier{}, ErrSupplierNotFound
+	}
+	ps.Supplier = r.suppliers[i].Name
+	ps.UpdatedAt = time.Now().UTC()
+	for j, l := range r.links {
+		if l.ProductID == ps.ProductID && l.SupplierID == ps.SupplierID {
+			r.links[j] = ps
+			return ps, nil
+		}
+	}
+	r.links = append(r.links, ps)
+	return ps, nil
+}
+
+func (r *InMemorySupplierRepository) Unlink(productID, supplierID int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, l := range r.links {
+		if l.ProductID == productID && l.SupplierID == supplierID {
+			r.links = append(r.links[:i], r.links[i+1:]...)
+			return nil
+		}
+	}
+	return ErrSupplierNotLinked
+}
+
+func (r *InMemorySupplierRepository) ForProducts(productIDs []int) (map[int][]models.ProductSupplier, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	wanted := map[int]bool{}
+	for _, id := range productIDs {
+		wanted[id] = true
+	}
+	linked := map[int][]models.ProductSupplier{}
+	for _, l := range r.links {
+		if wanted[l.ProductID] {
+			linked[l.ProductID] = append(linked[l.ProductID], l)
+		}
+	}
+	for _, links := range linked {
+		sort.Slice(links, func(i, j int) bool { return links[i].Supplier < links[j].Supplier })
+	}
+	return linked, nil
+}
@@ -0,0 +1,183 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+type PostgresSupplierRepository struct {
+	db *sql.DB
+}
+
+var _ SupplierRepository = (*PostgresSupplierRepository)(nil)
+
+func NewPostgresSupplierRepository(db *sql.DB) *PostgresSupplierRepository {
+	return &PostgresSupplierRepository{db: db}
+}
+
+const supplierColumns = `id, name, email, phone, created_at, updated_at`
+
+func scanSupplier(row rowScanner) (models.Supplier, error) {
+	var s models.Supplier
+	err := row.Scan(&s.ID, &s.Name, &s.Email, &s.Phone, &s.CreatedAt, &s.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Supplier{}, ErrSupplierNotFound
+	}
+	s.CreatedAt, s.UpdatedAt = s.CreatedAt.UTC(), s.UpdatedAt.UTC()
+	return s, err
+}
+
+func (r *PostgresSupplierRepository) Create(s models.Supplier) (models.Supplier, error) {
+	query := `INSERT INTO suppliers (name, email, phone, created_at, updated_at) VALUES ($1, $2, $3, $4, $4) RETURNING id`
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	s.CreatedAt = time.Now().UTC()
+	s.UpdatedAt = s.CreatedAt
+	if err := r.db.QueryRowContext(ctx, query, s.Name, s.Email, s.Phone, s.CreatedAt).Scan(&s.ID); err != nil {
+		return models.Supplier{}, uniqueViolation(err)
+	}
+	return s, nil
+}
+
+func (r *PostgresSupplierRepository) GetByID(id int) (models.Supplier, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return scanSupplier(r.db.QueryRowContext(ctx, `SELECT `+supplierColumns+` FROM suppliers WHERE id = $1`, id))
+}
+
+func (r *PostgresSupplierRepository) List() ([]models.Supplier, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `SELECT `+supplierColumns+` FROM suppliers ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	suppliers := []models.Supplier{}
+	for rows.Next() {
+		s, err := scanSupplier(rows)
+		if err != nil {
+			return nil, err
+		}
+		suppliers = append(suppliers, s)
+	}
+	return suppliers, rows.Err()
+}
+
+func (r *PostgresSupplierRepository) Update(s models.Supplier) (models.Supplier, error) {
+	query := `UPDATE suppliers SET name = $1, email = $2, phone = $3, updated_at = $4 WHERE id = $5 RETURNING created_at`
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	s.UpdatedAt = time.Now().UTC()
+	err := r.db.QueryRowContext(ctx, query, s.Name, s.Email, s.Phone, s.UpdatedAt, s.ID).Scan(&s.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Supplier{}, ErrSupplierNotFound
+	}
+	if err != nil {
+		return models.Supplier{}, uniqueViolation(err)
+	}
+	s.CreatedAt = s.CreatedAt.UTC()
+	return s, nil
+}
+
+func (r *PostgresSupplierRepository) Delete(id int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, `DELETE FROM suppliers WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrSupplierNotFound
+	}
+	return nil
+}
+
+func (r *PostgresSupplierRepository) Link(ps models.ProductSupplier) (models.ProductSupplier, error) {
+	query := `
+		WITH link AS (
+			INSERT INTO product_suppliers (product_id, supplier_id, supplier_sku, cost_price, lead_time_days, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (product_id, supplier_id) DO UPDATE
+			SET supplier_sku = EXCLUDED.supplier_sku, cost_price = EXCLUDED.cost_price,
+				lead_time_days = EXCLUDED.lead_time_days, updated_at = EXCLUDED.updated_at
+			RETURNING supplier_id
+		)
+		SELECT s.name FROM link JOIN suppliers s ON s.id = link.supplier_id`
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	ps.UpdatedAt = time.Now().UTC()
+	err := r.db.QueryRowContext(ctx, query, ps.ProductID, ps.SupplierID, ps.SupplierSKU, ps.CostPrice, ps.LeadTimeDays, ps.UpdatedAt).Scan(&ps.Supplier)
+	if err != nil {
+		if strings.Contains(err.Error(), "23503") {
+			if strings.Contains(err.Error(), "product_suppliers_supplier_id_fk") {
+				return models.ProductSupplier{}, fmt.Errorf("%w: %v", ErrSupplierNotFound, err)
+			}
+			return models.ProductSupplier{}, fmt.Errorf("%w: %v", ErrProductNotFound, err)
+		}
+		return models.ProductSupplier{}, err
+	}
+	return ps, nil
+}
+
+func (r *PostgresSupplierRepository) Unlink(productID, supplierID int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, `DELETE FROM product_suppliers WHERE product_id = $1 AND supplier_id = $2`, productID, supplierID)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrSupplierNotLinked
+	}
+	return nil
+}
+
+func (r *PostgresSupplierRepository) ForProducts(productIDs []int) (map[int][]models.ProductSupplier, error) {
+	linked := map[int][]models.ProductSupplier{}
+	if len(productIDs) == 0 {
+		return linked, nil
+	}
+	ids := make([]int32, len(productIDs))
+	for i, id := range productIDs {
+		ids[i] = int32(id)
+	}
+	query := `
+		SELECT ps.product_id, ps.supplier_id, s.name, ps.supplier_sku, ps.cost_price, ps.lead_time_days, ps.updated_at
+		FROM product_suppliers ps
+		JOIN suppliers s ON s.id = ps.supplier_id
+		WHERE ps.product_id = ANY($1)
+		ORDER BY ps.product_id, s.name`
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var ps models.ProductSupplier
+		if err := rows.Scan(&ps.ProductID, &ps.SupplierID, &ps.Supplier, &ps.SupplierSKU, &ps.CostPrice, &ps.LeadTimeDays, &ps.UpdatedAt); err != nil {
+			return nil, err
+		}
+		ps.UpdatedAt = ps.UpdatedAt.UTC()
+		linked[ps.ProductID] = append(linked[ps.ProductID], ps)
+	}
+	return linked, rows.Err()
+}
@@ -0,0 +1,30 @@
+package repo
+
+import (
+	"errors"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+// SupplierRepository defines the interface for suppliers and the terms they
+// sell products on.
+type SupplierRepository interface {
+	Create(s models.Supplier) (models.Supplier, error)
+	GetByID(id int) (models.Supplier, error)
+	List() ([]models.Supplier, error)
+	Update(s models.Supplier) (models.Supplier, error)
+	// Delete also unlinks the supplier from its products.
+	Delete(id int) error
+	// Link sets the terms of a supplier for a product, replacing earlier
+	// ones. It fails with ErrProductNotFound or ErrSupplierNotFound when
+	// either is missing.
+	Link(ps models.ProductSupplier) (models.ProductSupplier, error)
+	// Unlink fails with ErrSupplierNotLinked when there are no such terms.
+	Unlink(productID, supplierID int) error
+	// ForProducts returns the suppliers of each product, by supplier name.
+	// Products without any are absent.
+	ForProducts(productIDs []int) (map[int][]models.ProductSupplier, error)
+}
+
+var ErrSupplierNotFound = errors.New("supplier not found")
+var ErrSupplierNotLinked = errors.New("supplier is not linked to the product")
@@ -0,0 +1,147 @@
+package repo
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+// InMemorySyncRepository reads changes from the given repositories. The
+// in-memory product repository keeps no tombstones and movements carry no
+// recording time, so deletions are never reported and movements are ordered
+// by CreatedAt.
+type InMemorySyncRepository struct {
+	mu         sync.Mutex
+	operations map[string]models.SyncOperation
+	products   ProductRepository
+	movements  MovementRepository
+}
+
+var _ SyncRepository = (*InMemorySyncRepository)(nil)
+
+func NewInMemorySyncRepository(products ProductRepository, movements MovementRepository) *InMemorySyncRepository {
+	return &InMemorySyncRepository{
+		operations: map[string]models.SyncOperation{},
+		products:   products,
+		movements:  movements,
+	}
+}
+
+func (r *InMemorySyncRepository) Changes(after SyncCursor, limit int) (SyncChanges, error) {
+	changes := SyncChanges{
+		Products:          []models.Product{},
+		Movements:         []models.Movement{},
+		DeletedProductIDs: []int{},
+		Next:              after,
+	}
+
+	products, err := r.products.GetAll()
+	if err != nil {
+		return SyncChanges{}, err
+	}
+	type positioned[T any] struct {
+		item T
+		pos  SyncPosition
+	}
+	var ps []positioned[models.Product]
+	for _, p := range products {
+		at, _ := time.Parse(time.RFC3339Nano, p.UpdatedAt)
+		pos := SyncPosition{At: at, ID: p.ID}
+		if positionAfter(pos, after.Products) {
+			ps = append(ps, positioned[models.Product]{p, pos})
+		}
+	}
+	sort.Slice(ps, func(i, j int) bool { return positionAfter(ps[j].pos, ps[i].pos) })
+	for _, p := range ps[:min(len(ps), limit)] {
+		changes.Products = append(changes.Products, p.item)
+		changes.Next.Products = p.pos
+	}
+
+	movements, err := r.movements.GetBetween(after.Movements.At, time.Now().UTC().Add(time.Second))
+	if err != nil {
+		return SyncChanges{}, err
+	}
+	var ms []positioned[models.Movement]
+	for _, m := range movements {
+		at, _ := time.Parse(time.RFC3339, m.CreatedAt)
+		pos := SyncPosition{At: at, ID: m.ID}
+		if positionAfter(pos, after.Movements) {
+			ms = append(ms, positioned[models.Movement]{m, pos})
+		}
+	}
+	sort.Slice(ms, func(i, j int) bool { return positionAfter(ms[j].pos, ms[i].pos) })
+	for _, m := range ms[:min(len(ms), limit)] {
+		changes.Movements = append(changes.Movements, m.item)
+		changes.Next.Movements = m.pos
+	}
+
+	changes.HasMore = len(ps) > limit || len(ms) > limit
+	return changes, nil
+}
+
+// ProductChanges reports product writes only: the in-memory product
+// repository keeps no tombstones.
+func (r *InMemorySyncRepository) ProductChanges(after SyncPosition, limit int) ([]ProductChange, bool, error) {
+	products, err := r.products.GetAll()
+	if err != nil {
+		return nil, false, err
+	}
+	var changes []ProductChange
+	for _, p := range products {
+		at, _ := time.Parse(time.RFC3339Nano, p.UpdatedAt)
+		c := ProductChange{ProductID: p.ID, Product: &p, At: at}
+		if positionAfter(c.Position(), after) {
+			changes = append(changes, c)
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return positionAfter(changes[j].Position(), changes[i].Position()) })
+	if len(changes) > limit {
+		return changes[:limit], true, nil
+	}
+	return changes, false, nil
+}
+
+// mergeProductChanges merges two streams already in keyset order.
+func mergeProductChanges(a, b []ProductChange) []ProductChange {
+	merged := make([]ProductChange, 0, len(a)+len(b))
+	for len(a) > 0 && len(b) > 0 {
+		if positionAfter(a[0].Position(), b[0].Position()) {
+			merged, b = append(merged, b[0]), b[1:]
+		} else {
+			merged, a = append(merged, a[0]), a[1:]
+		}
+	}
+	merged = append(merged, a...)
+	return append(merged, b...)
+}
+
+// positionAfter reports whether a comes after b in keyset order.
+func positionAfter(a, b SyncPosition) bool {
+	if !a.At.Equal(b.At) {
+		return a.At.After(b.At)
+	}
+	return a.ID > b.ID
+}
+
+func (r *InMemorySyncRepository) Claim(op models.SyncOperation) (models.SyncOperation, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if existing, ok := r.operations[op.ClientID]; ok {
+		return existing, false, nil
+	}
+	op.Status = models.SyncStatusPending
+	op.CreatedAt = time.Now().UTC()
+	r.operations[op.ClientID] = op
+	return op, true, nil
+}
+
+func (r *InMemorySyncRepository) Finish(op models.SyncOperation) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.operations[op.ClientID] = op
+	return nil
+}
@@ -0,0 +1,194 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+type PostgresSyncRepository struct {
+	db *sql.DB
+}
+
+var _ SyncRepository = (*PostgresSyncRepository)(nil)
+
+func NewPostgresSyncRepository(db *sql.DB) *PostgresSyncRepository {
+	return &PostgresSyncRepository{db: db}
+}
+
+func (r *PostgresSyncRepository) Changes(after SyncCursor, limit int) (SyncChanges, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), bulkTimeout)
+	defer cancel()
+
+	changes := SyncChanges{
+		Products:          []models.Product{},
+		Movements:         []models.Movement{},
+		DeletedProductIDs: []int{},
+		Next:              after,
+	}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT `+productColumns+`, updated_at FROM products
+		WHERE (updated_at, id) > ($1, $2) ORDER BY updated_at, id LIMIT $3`, after.Products.At, after.Products.ID, limit)
+	if err != nil {
+		return SyncChanges{}, err
+	}
+	for rows.Next() {
+		var at time.Time
+		p, err := scanProduct(rows, &at)
+		if err != nil {
+			rows.Close()
+			return SyncChanges{}, err
+		}
+		changes.Products = append(changes.Products, p)
+		changes.Next.Products = SyncPosition{At: at, ID: p.ID}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return SyncChanges{}, err
+	}
+
+	rows, err = r.db.QueryContext(ctx, `SELECT `+movementColumns+`, updated_at FROM movements
+		WHERE (updated_at, id) > ($1, $2) ORDER BY updated_at, id LIMIT $3`, after.Movements.At, after.Movements.ID, limit)
+	if err != nil {
+		return SyncChanges{}, err
+	}
+	for rows.Next() {
+		var at time.Time
+		m, err := scanMovement(rows, &at)
+		if err != nil {
+			rows.Close()
+			return SyncChanges{}, err
+		}
+		changes.Movements = append(changes.Movements, m)
+		changes.Next.Movements = SyncPosition{At: at, ID: m.ID}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return SyncChanges{}, err
+	}
+
+	rows, err = r.db.QueryContext(ctx, `SELECT product_id, deleted_at FROM product_tombstones
+		WHERE (deleted_at, product_id) > ($1, $2) ORDER BY deleted_at, product_id LIMIT $3`, after.DeletedProducts.At, after.DeletedProducts.ID, limit)
+	if err != nil {
+		return SyncChanges{}, err
+	}
+	for rows.Next() {
+		var id int
+		var at time.Time
+		if err := rows.Scan(&id, &at); err != nil {
+			rows.Close()
+			return SyncChanges{}, err
+		}
+		changes.DeletedProductIDs = append(changes.DeletedProductIDs, id)
+		changes.Next.DeletedProducts = SyncPosition{At: at, ID: id}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return SyncChanges{}, err
+	}
+
+	changes.HasMore = len(changes.Products) == limit || len(changes.Movements) == limit || len(changes.DeletedProductIDs) == limit
+	return changes, nil
+}
+
+func (r *PostgresSyncRepository) ProductChanges(after SyncPosition, limit int) ([]ProductChange, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), bulkTimeout)
+	defer cancel()
+
+	// Read one row past the limit from each stream; merged, they tell
+	// whether another page follows.
+	var written []ProductChange
+	rows, err := r.db.QueryContext(ctx, `SELECT `+productColumns+`, updated_at FROM products
+		WHERE (updated_at, id) > ($1, $2) ORDER BY updated_at, id LIMIT $3`, after.At, after.ID, limit+1)
+	if err != nil {
+		return nil, false, err
+	}
+	for rows.Next() {
+		var at time.Time
+		p, err := scanProduct(rows, &at)
+		if err != nil {
+			rows.Close()
+			return nil, false, err
+		}
+		written = append(written, ProductChange{ProductID: p.ID, Product: &p, At: at})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, false, err
+	}
+
+	var deleted []ProductChange
+	rows, err = r.db.QueryContext(ctx, `SELECT product_id, deleted_at FROM product_tombstones
+		WHERE (deleted_at, product_id) > ($1, $2) ORDER BY deleted_at, product_id LIMIT $3`, after.At, after.ID, limit+1)
+	if err != nil {
+		return nil, false, err
+	}
+	for rows.Next() {
+		c := ProductChange{Deleted: true}
+		if err := rows.Scan(&c.ProductID, &c.At); err != nil {
+			rows.Close()
+			return nil, false, err
+		}
+		c.At = c.At.UTC()
+		deleted = append(deleted, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, false, err
+	}
+
+	changes := mergeProductChanges(written, deleted)
+	if len(changes) > limit {
+		return changes[:limit], true, nil
+	}
+	return changes, false, nil
+}
+
+const syncOperationColumns = `client_id, product_id, delta, status, reason, movement_id, created_by, created_at`
+
+func scanSyncOperation(row rowScanner) (models.SyncOperation, error) {
+	var op models.SyncOperation
+	var movementID sql.NullInt64
+	err := row.Scan(&op.ClientID, &op.ProductID, &op.Delta, &op.Status, &op.Reason, &movementID, &op.CreatedBy, &op.CreatedAt)
+	if movementID.Valid {
+		id := int(movementID.Int64)
+		op.MovementID = &id
+	}
+	op.CreatedAt = op.CreatedAt.UTC()
+	return op, err
+}
+
+func (r *PostgresSyncRepository) Claim(op models.SyncOperation) (models.SyncOperation, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	op.Status = models.SyncStatusPending
+	op.CreatedAt = time.Now().UTC()
+	res, err := r.db.ExecContext(ctx, `INSERT INTO sync_operations (client_id, product_id, delta, status, reason, created_by, created_at)
+		VALUES ($1, $2, $3, $4, '', $5, $6) ON CONFLICT (client_id) DO NOTHING`,
+		op.ClientID, op.ProductID, op.Delta, op.Status, op.CreatedBy, op.CreatedAt)
+	if err != nil {
+		return models.SyncOperation{}, false, err
+	}
+	if n, _ := res.RowsAffected(); n == 1 {
+		return op, true, nil
+	}
+
+	existing, err := scanSyncOperation(r.db.QueryRowContext(ctx, `SELECT `+syncOperationColumns+` FROM sync_operations WHERE client_id = $1`, op.ClientID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.SyncOperation{}, false, errors.New("sync operation vanished after conflicting insert")
+	}
+	return existing, false, err
+}
+
+func (r *PostgresSyncRepository) Finish(op models.SyncOperation) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `UPDATE sync_operations SET status = $1, reason = $2, movement_id = $3 WHERE client_id = $4`,
+		op.Status, op.Reason, op.MovementID, op.ClientID)
+	return err
+}
@@ -0,0 +1,59 @@
+package repo
+
+import (
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+// SyncPosition is a keyset position in one change stream: the next page
+// starts after the row with this timestamp and ID.
+type SyncPosition struct {
+	At time.Time `json:"at"`
+	ID int       `json:"id"`
+}
+
+// SyncCursor tracks how far a client has read each change stream.
+type SyncCursor struct {
+	Products        SyncPosition `json:"p"`
+	Movements       SyncPosition `json:"m"`
+	DeletedProducts SyncPosition `json:"d"`
+}
+
+// SyncChanges is one page of changes after a cursor. Products are current
+// state, movements are those recorded (not backdated to) after the cursor.
+type SyncChanges struct {
+	Products          []models.Product
+	Movements         []models.Movement
+	DeletedProductIDs []int
+	Next              SyncCursor
+	HasMore           bool
+}
+
+// ProductChange is one entry of the product change feed: the product's
+// current state, or a tombstone when Deleted is set and Product is nil.
+type ProductChange struct {
+	ProductID int
+	Product   *models.Product
+	Deleted   bool
+	At        time.Time
+}
+
+// Position is the keyset position of the change in the feed.
+func (c ProductChange) Position() SyncPosition {
+	return SyncPosition{At: c.At, ID: c.ProductID}
+}
+
+// SyncRepository backs the offline sync protocol for mobile clients.
+type SyncRepository interface {
+	// Changes returns up to limit rows of each stream after the cursor.
+	Changes(after SyncCursor, limit int) (SyncChanges, error)
+	// ProductChanges returns up to limit product writes and deletions after
+	// the position as one stream ordered by time, and whether more follow.
+	ProductChanges(after SyncPosition, limit int) ([]ProductChange, bool, error)
+	// Claim records op as pending unless its ClientID was seen before, in
+	// which case it returns the earlier operation and claimed is false.
+	Claim(op models.SyncOperation) (existing models.SyncOperation, claimed bool, err error)
+	// Finish stores the outcome of a claimed operation.
+	Finish(op models.SyncOperation) error
+}
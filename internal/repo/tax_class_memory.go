@@ -0,0 +1,125 @@
+package repo
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+type InMemoryTaxClassRepository struct {
+	mu       sync.Mutex
+	classes  []models.TaxClass
+	nextID   int
+	products ProductRepository
+}
+
+var _ TaxClassRepository = (*InMemoryTaxClassRepository)(nil)
+
+// NewInMemoryTaxClassRepository checks products, when given, before deleting
+// a class.
+func NewInMemoryTaxClassRepository(products ProductRepository) *InMemoryTaxClassRepository {
+	return &InMemoryTaxClassRepository{nextID: 1, products: products}
+}
+
+func (r *InMemoryTaxClassRepository) nameTaken(name string, exceptID int) bool {
+	for _, c := range r.classes {
+		if c.ID != exceptID && strings.EqualFold(c.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *InMemoryTaxClassRepository) Create(c models.TaxClass) (models.TaxClass, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.nameTaken(c.Name, 0) {
+		return models.TaxClass{}, fmt.Errorf("%w: tax class %q", ErrDuplicatedValueUnique, c.Name)
+	}
+	c.ID = r.nextID
+	c.CreatedAt = time.Now().UTC()
+	c.UpdatedAt = c.CreatedAt
+	r.nextID++
+	r.classes = append(r.classes, c)
+	return c, nil
+}
+
+func (r *InMemoryTaxClassRepository) GetByID(id int) (models.TaxClass, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, c := range r.classes {
+		if c.ID == id {
+			return c, nil
+		}
+	}
+	return models.TaxClass{}, ErrTaxClassNotFound
+}
+
+func (r *InMemoryTaxClassRepository) GetByName(name string) (models.TaxClass, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, c := range r.classes {
+		if strings.EqualFold(c.Name, name) {
+			return c, nil
+		}
+	}
+	return models.TaxClass{}, ErrTaxClassNotFound
+}
+
+func (r *InMemoryTaxClassRepository) List() ([]models.TaxClass, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	classes := append([]models.TaxClass{}, r.classes...)
+	sort.Slice(classes, func(i, j int) bool { return classes[i].Name < classes[j].Name })
+	return classes, nil
+}
+
+func (r *InMemoryTaxClassRepository) Update(c models.TaxClass) (models.TaxClass, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.nameTaken(c.Name, c.ID) {
+		return models.TaxClass{}, fmt.Errorf("%w: tax class %q", ErrDuplicatedValueUnique, c.Name)
+	}
+	for i, existing := range r.classes {
+		if existing.ID == c.ID {
+			c.CreatedAt = existing.CreatedAt
+			c.UpdatedAt = time.Now().UTC()
+			r.classes[i] = c
+			return c, nil
+		}
+	}
+	return models.TaxClass{}, ErrTaxClassNotFound
+}
+
+func (r *InMemoryTaxClassRepository) Delete(id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.products != nil {
+		products, err := r.products.GetAll()
+		if err != nil {
+			return err
+		}
+		for _, p := range products {
+			if p.TaxClassID != nil && *p.TaxClassID == id {
+				return ErrTaxClassInUse
+			}
+		}
+	}
+	for i, c := range r.classes {
+		if c.ID == id {
+			r.classes = append(r.classes[:i], r.classes[i+1:]...)
+			return nil
+		}
+	}
+	return ErrTaxClassNotFound
+}
@@ -0,0 +1,124 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+type PostgresTaxClassRepository struct {
+	db *sql.DB
+}
+
+var _ TaxClassRepository = (*PostgresTaxClassRepository)(nil)
+
+func NewPostgresTaxClassRepository(db *sql.DB) *PostgresTaxClassRepository {
+	return &PostgresTaxClassRepository{db: db}
+}
+
+const taxClassColumns = `id, name, rate, created_at, updated_at`
+
+func scanTaxClass(row rowScanner) (models.TaxClass, error) {
+	var c models.TaxClass
+	err := row.Scan(&c.ID, &c.Name, &c.Rate, &c.CreatedAt, &c.UpdatedAt)
+	c.CreatedAt, c.UpdatedAt = c.CreatedAt.UTC(), c.UpdatedAt.UTC()
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.TaxClass{}, ErrTaxClassNotFound
+	}
+	return c, err
+}
+
+// taxClassWriteError translates constraint violations into repository errors.
+func taxClassWriteError(err error) error {
+	switch {
+	case strings.Contains(err.Error(), "23505"):
+		return fmt.Errorf("%w: %v", ErrDuplicatedValueUnique, err)
+	case strings.Contains(err.Error(), "23503"):
+		return fmt.Errorf("%w: %v", ErrTaxClassInUse, err)
+	}
+	return err
+}
+
+func (r *PostgresTaxClassRepository) Create(c models.TaxClass) (models.TaxClass, error) {
+	query := `INSERT INTO tax_classes (name, rate, created_at, updated_at) VALUES ($1, $2, $3, $3) RETURNING id`
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	c.CreatedAt = time.Now().UTC()
+	c.UpdatedAt = c.CreatedAt
+	if err := r.db.QueryRowContext(ctx, query, c.Name, c.Rate, c.CreatedAt).Scan(&c.ID); err != nil {
+		return models.TaxClass{}, taxClassWriteError(err)
+	}
+	return c, nil
+}
+
+func (r *PostgresTaxClassRepository) GetByID(id int) (models.TaxClass, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return scanTaxClass(r.db.QueryRowContext(ctx, `SELECT `+taxClassColumns+` FROM tax_classes WHERE id = $1`, id))
+}
+
+func (r *PostgresTaxClassRepository) GetByName(name string) (models.TaxClass, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return scanTaxClass(r.db.QueryRowContext(ctx, `SELECT `+taxClassColumns+` FROM tax_classes WHERE LOWER(name) = LOWER($1)`, name))
+}
+
+func (r *PostgresTaxClassRepository) List() ([]models.TaxClass, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, `SELECT `+taxClassColumns+` FROM tax_classes ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	classes := []models.TaxClass{}
+	for rows.Next() {
+		c, err := scanTaxClass(rows)
+		if err != nil {
+			return nil, err
+		}
+		classes = append(classes, c)
+	}
+	return classes, rows.Err()
+}
+
+func (r *PostgresTaxClassRepository) Update(c models.TaxClass) (models.TaxClass, error) {
+	query := `UPDATE tax_classes SET name = $1, rate = $2, updated_at = $3 WHERE id = $4 RETURNING created_at`
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	c.UpdatedAt = time.Now().UTC()
+	err := r.db.QueryRowContext(ctx, query, c.Name, c.Rate, c.UpdatedAt, c.ID).Scan(&c.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.TaxClass{}, ErrTaxClassNotFound
+	}
+	if err != nil {
+		return models.TaxClass{}, taxClassWriteError(err)
+	}
+	c.CreatedAt = c.CreatedAt.UTC()
+	return c, nil
+}
+
+func (r *PostgresTaxClassRepository) Delete(id int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, `DELETE FROM tax_classes WHERE id = $1`, id)
+	if err != nil {
+		return taxClassWriteError(err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrTaxClassNotFound
+	}
+	return nil
+}
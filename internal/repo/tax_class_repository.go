@@ -0,0 +1,22 @@
+package repo
+
+import (
+	"errors"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+// TaxClassRepository defines the interface for tax class data operations.
+type TaxClassRepository interface {
+	Create(c models.TaxClass) (models.TaxClass, error)
+	GetByID(id int) (models.TaxClass, error)
+	// GetByName matches names regardless of case.
+	GetByName(name string) (models.TaxClass, error)
+	List() ([]models.TaxClass, error)
+	Update(c models.TaxClass) (models.TaxClass, error)
+	// Delete fails with ErrTaxClassInUse while products reference the class.
+	Delete(id int) error
+}
+
+var ErrTaxClassNotFound = errors.New("tax class not found")
+var ErrTaxClassInUse = errors.New("tax class is assigned to products")
@@ -0,0 +1,81 @@
+package repo
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+type InMemoryUsageRepository struct {
+	records map[string]models.UsageRecord
+}
+
+var _ UsageRepository = (*InMemoryUsageRepository)(nil)
+
+func NewInMemoryUsageRepository() *InMemoryUsageRepository {
+	return &InMemoryUsageRepository{
+		records: map[string]models.UsageRecord{},
+	}
+}
+
+func (r *InMemoryUsageRepository) AddDaily(u models.UsageRecord) error {
+	key := u.Username + "|" + u.Day
+	existing := r.records[key]
+	existing.Username = u.Username
+	existing.Day = u.Day
+	existing.Requests += u.Requests
+	existing.BytesIn += u.BytesIn
+	existing.BytesOut += u.BytesOut
+	r.records[key] = existing
+	return nil
+}
+
+func (r *InMemoryUsageRepository) GetMonthly(username, month string) ([]models.UsageRecord, error) {
+	if _, _, err := PeriodBounds(month); err != nil {
+		return nil, err
+	}
+
+	records := []models.UsageRecord{}
+	for _, u := range r.records {
+		if !strings.HasPrefix(u.Day, month+"-") {
+			continue
+		}
+		if username != "" && u.Username != username {
+			continue
+		}
+		records = append(records, u)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].Day != records[j].Day {
+			return records[i].Day < records[j].Day
+		}
+		return records[i].Username < records[j].Username
+	})
+	return records, nil
+}
+
+func (r *InMemoryUsageRepository) GetByUser(username string) ([]models.UsageRecord, error) {
+	records := []models.UsageRecord{}
+	for _, u := range r.records {
+		if u.Username == username {
+			records = append(records, u)
+		}
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Day < records[j].Day
+	})
+	return records, nil
+}
+
+func (r *InMemoryUsageRepository) Pseudonymize(username, pseudonym string) error {
+	for key, u := range r.records {
+		if u.Username != username {
+			continue
+		}
+		delete(r.records, key)
+		u.Username = pseudonym
+		_ = r.AddDaily(u)
+	}
+	return nil
+}
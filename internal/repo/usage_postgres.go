@@ -0,0 +1,102 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+type PostgresUsageRepository struct {
+	db *sql.DB
+}
+
+var _ UsageRepository = (*PostgresUsageRepository)(nil)
+
+func NewPostgresUsageRepository(db *sql.DB) *PostgresUsageRepository {
+	return &PostgresUsageRepository{db: db}
+}
+
+func (r *PostgresUsageRepository) AddDaily(u models.UsageRecord) error {
+	query := `
+		INSERT INTO api_usage (username, day, requests, bytes_in, bytes_out)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (username, day) DO UPDATE
+		SET requests = api_usage.requests + EXCLUDED.requests,
+			bytes_in = api_usage.bytes_in + EXCLUDED.bytes_in,
+			bytes_out = api_usage.bytes_out + EXCLUDED.bytes_out
+	`
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, query, u.Username, u.Day, u.Requests, u.BytesIn, u.BytesOut)
+	return err
+}
+
+func (r *PostgresUsageRepository) GetMonthly(username, month string) ([]models.UsageRecord, error) {
+	start, end, err := PeriodBounds(month)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT username, to_char(day, 'YYYY-MM-DD'), requests, bytes_in, bytes_out
+		FROM api_usage
+		WHERE day >= $1 AND day < $2 AND ($3 = '' OR username = $3)
+		ORDER BY day, username
+	`
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, start, end, username)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := []models.UsageRecord{}
+	for rows.Next() {
+		var u models.UsageRecord
+		if err := rows.Scan(&u.Username, &u.Day, &u.Requests, &u.BytesIn, &u.BytesOut); err != nil {
+			return nil, err
+		}
+		records = append(records, u)
+	}
+	return records, rows.Err()
+}
+
+func (r *PostgresUsageRepository) GetByUser(username string) ([]models.UsageRecord, error) {
+	query := `
+		SELECT username, to_char(day, 'YYYY-MM-DD'), requests, bytes_in, bytes_out
+		FROM api_usage
+		WHERE username = $1
+		ORDER BY day
+	`
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, username)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := []models.UsageRecord{}
+	for rows.Next() {
+		var u models.UsageRecord
+		if err := rows.Scan(&u.Username, &u.Day, &u.Requests, &u.BytesIn, &u.BytesOut); err != nil {
+			return nil, err
+		}
+		records = append(records, u)
+	}
+	return records, rows.Err()
+}
+
+func (r *PostgresUsageRepository) Pseudonymize(username, pseudonym string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `UPDATE api_usage SET username = $2 WHERE username = $1`, username, pseudonym)
+	return err
+}
@@ -0,0 +1,15 @@
+package repo
+
+import "github.com/rogerio-castellano/inventory-tracker/internal/models"
+
+// UsageRepository stores daily API usage aggregates.
+type UsageRepository interface {
+	// AddDaily adds the record's counters to the stored totals for its user and day.
+	AddDaily(u models.UsageRecord) error
+	// GetMonthly returns the daily records of a month (YYYY-MM); an empty username means every user.
+	GetMonthly(username, month string) ([]models.UsageRecord, error)
+	// GetByUser returns every daily record of a user.
+	GetByUser(username string) ([]models.UsageRecord, error)
+	// Pseudonymize reassigns a user's records to a pseudonym.
+	Pseudonymize(username, pseudonym string) error
+}
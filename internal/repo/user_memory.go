@@ -2,6 +2,7 @@ package repo
 
 import (
 	"errors"
+	"time"
 
 	"github.com/rogerio-castellano/inventory-tracker/internal/models"
 )
@@ -10,6 +11,8 @@ type InMemoryUserRepository struct {
 	users []models.User
 }
 
+var _ UserRepository = (*InMemoryUserRepository)(nil)
+
 func NewInMemoryUserRepository() *InMemoryUserRepository {
 	return &InMemoryUserRepository{
 		users: []models.User{},
@@ -34,6 +37,71 @@ func (r *InMemoryUserRepository) CreateUser(u models.User) (models.User, error)
 	}
 
 	u.ID = len(r.users) + 1
+	u.PasswordChangedAt = time.Now().UTC()
 	r.users = append(r.users, u)
 	return u, nil
 }
+
+func (r *InMemoryUserRepository) Anonymize(username, pseudonym string) error {
+	for i, user := range r.users {
+		if user.Username == username {
+			r.users[i].Username = pseudonym
+			r.users[i].PasswordHash = ""
+			r.users[i].Email = ""
+			return nil
+		}
+	}
+	return ErrUserNotFound
+}
+
+func (r *InMemoryUserRepository) SetTimezone(username, timezone string) error {
+	for i, user := range r.users {
+		if user.Username == username {
+			r.users[i].Timezone = timezone
+			return nil
+		}
+	}
+	return ErrUserNotFound
+}
+
+func (r *InMemoryUserRepository) SetPassword(username, passwordHash string) error {
+	for i, user := range r.users {
+		if user.Username == username {
+			r.users[i].PasswordHash = passwordHash
+			r.users[i].MustChangePassword = false
+			r.users[i].PasswordChangedAt = time.Now().UTC()
+			return nil
+		}
+	}
+	return ErrUserNotFound
+}
+
+func (r *InMemoryUserRepository) RequirePasswordChange(username string) error {
+	for i, user := range r.users {
+		if user.Username == username {
+			r.users[i].MustChangePassword = true
+			return nil
+		}
+	}
+	return ErrUserNotFound
+}
+
+func (r *InMemoryUserRepository) SetRole(username, role string) error {
+	for i, user := range r.users {
+		if user.Username == username {
+			r.users[i].Role = role
+			return nil
+		}
+	}
+	return ErrUserNotFound
+}
+
+func (r *InMemoryUserRepository) SetDisabled(username string, disabled bool) error {
+	for i, user := range r.users {
+		if user.Username == username {
+			r.users[i].Disabled = disabled
+			return nil
+		}
+	}
+	return ErrUserNotFound
+}
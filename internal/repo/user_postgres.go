@@ -13,6 +13,8 @@ type PostgresUserRepository struct {
 	db *sql.DB
 }
 
+var _ UserRepository = (*PostgresUserRepository)(nil)
+
 func NewPostgresUserRepository(db *sql.DB) *PostgresUserRepository {
 	return &PostgresUserRepository{db: db}
 }
@@ -22,8 +24,8 @@ func (r *PostgresUserRepository) GetByUsername(username string) (models.User, er
 	defer cancel()
 
 	var u models.User
-	err := r.db.QueryRowContext(ctx, `SELECT id, username, password_hash, role FROM users WHERE username = $1`, username).
-		Scan(&u.ID, &u.Username, &u.PasswordHash, &u.Role)
+	err := r.db.QueryRowContext(ctx, `SELECT id, username, password_hash, role, timezone, email, must_change_password, disabled, password_changed_at FROM users WHERE username = $1`, username).
+		Scan(&u.ID, &u.Username, &u.PasswordHash, &u.Role, &u.Timezone, &u.Email, &u.MustChangePassword, &u.Disabled, &u.PasswordChangedAt)
 
 	if errors.Is(err, sql.ErrNoRows) {
 		return models.User{}, ErrUserNotFound
@@ -41,10 +43,101 @@ func (r *PostgresUserRepository) CreateUser(u models.User) (models.User, error)
 		u.Role = "user"
 	}
 
-	query := `INSERT INTO users (username, password_hash, role) VALUES ($1, $2, $3) RETURNING id`
-	err := r.db.QueryRowContext(ctx, query, u.Username, u.PasswordHash, u.Role).Scan(&u.ID)
+	query := `INSERT INTO users (username, password_hash, role, email, must_change_password) VALUES ($1, $2, $3, $4, $5) RETURNING id, password_changed_at`
+	err := r.db.QueryRowContext(ctx, query, u.Username, u.PasswordHash, u.Role, u.Email, u.MustChangePassword).Scan(&u.ID, &u.PasswordChangedAt)
 	if err != nil {
-		return models.User{}, err
+		return models.User{}, uniqueViolation(err)
 	}
 	return u, nil
 }
+
+func (r *PostgresUserRepository) Anonymize(username, pseudonym string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `UPDATE users SET username = $2, password_hash = '', email = '', updated_at = now() WHERE username = $1`
+	res, err := r.db.ExecContext(ctx, query, username, pseudonym)
+	if err != nil {
+		return err
+	}
+	rowsAffected, _ := res.RowsAffected()
+	if rowsAffected == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *PostgresUserRepository) SetTimezone(username, timezone string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, `UPDATE users SET timezone = $2, updated_at = now() WHERE username = $1`, username, timezone)
+	if err != nil {
+		return err
+	}
+	rowsAffected, _ := res.RowsAffected()
+	if rowsAffected == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *PostgresUserRepository) SetPassword(username, passwordHash string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, `UPDATE users SET password_hash = $2, must_change_password = false, password_changed_at = now(), updated_at = now() WHERE username = $1`, username, passwordHash)
+	if err != nil {
+		return err
+	}
+	rowsAffected, _ := res.RowsAffected()
+	if rowsAffected == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *PostgresUserRepository) RequirePasswordChange(username string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, `UPDATE users SET must_change_password = true, updated_at = now() WHERE username = $1`, username)
+	if err != nil {
+		return err
+	}
+	rowsAffected, _ := res.RowsAffected()
+	if rowsAffected == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *PostgresUserRepository) SetRole(username, role string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, `UPDATE users SET role = $2, updated_at = now() WHERE username = $1`, username, role)
+	if err != nil {
+		return err
+	}
+	rowsAffected, _ := res.RowsAffected()
+	if rowsAffected == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+func (r *PostgresUserRepository) SetDisabled(username string, disabled bool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, `UPDATE users SET disabled = $2, updated_at = now() WHERE username = $1`, username, disabled)
+	if err != nil {
+		return err
+	}
+	rowsAffected, _ := res.RowsAffected()
+	if rowsAffected == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
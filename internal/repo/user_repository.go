@@ -5,4 +5,15 @@ import "github.com/rogerio-castellano/inventory-tracker/internal/models"
 type UserRepository interface {
 	GetByUsername(username string) (models.User, error)
 	CreateUser(u models.User) (models.User, error)
+	// Anonymize replaces the user's personal data with a pseudonym and makes the account unusable.
+	Anonymize(username, pseudonym string) error
+	SetTimezone(username, timezone string) error
+	// SetPassword replaces the user's password hash and clears
+	// MustChangePassword.
+	SetPassword(username, passwordHash string) error
+	// RequirePasswordChange sets MustChangePassword, so the user cannot
+	// log in until the password is changed.
+	RequirePasswordChange(username string) error
+	SetRole(username, role string) error
+	SetDisabled(username string, disabled bool) error
 }
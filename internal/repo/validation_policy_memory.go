@@ -0,0 +1,42 @@
+package repo
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+type InMemoryValidationPolicyRepository struct {
+	mu       sync.Mutex
+	policies map[string]models.ValidationPolicy
+}
+
+var _ ValidationPolicyRepository = (*InMemoryValidationPolicyRepository)(nil)
+
+func NewInMemoryValidationPolicyRepository() *InMemoryValidationPolicyRepository {
+	return &InMemoryValidationPolicyRepository{policies: map[string]models.ValidationPolicy{}}
+}
+
+func (r *InMemoryValidationPolicyRepository) Get(tenant string) (models.ValidationPolicy, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, ok := r.policies[tenant]
+	if !ok {
+		return models.ValidationPolicy{Tenant: tenant, RequiredFields: []string{}}, nil
+	}
+	p.RequiredFields = slices.Clone(p.RequiredFields)
+	return p, nil
+}
+
+func (r *InMemoryValidationPolicyRepository) Put(p models.ValidationPolicy) (models.ValidationPolicy, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p.UpdatedAt = time.Now().UTC()
+	p.RequiredFields = slices.Clone(p.RequiredFields)
+	r.policies[p.Tenant] = p
+	return p, nil
+}
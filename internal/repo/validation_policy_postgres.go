@@ -0,0 +1,56 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+type PostgresValidationPolicyRepository struct {
+	db *sql.DB
+}
+
+var _ ValidationPolicyRepository = (*PostgresValidationPolicyRepository)(nil)
+
+func NewPostgresValidationPolicyRepository(db *sql.DB) *PostgresValidationPolicyRepository {
+	return &PostgresValidationPolicyRepository{db: db}
+}
+
+func (r *PostgresValidationPolicyRepository) Get(tenant string) (models.ValidationPolicy, error) {
+	query := `SELECT required_fields, updated_at FROM validation_policies WHERE tenant = $1`
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	p := models.ValidationPolicy{Tenant: tenant, RequiredFields: []string{}}
+	var fields string
+	err := r.db.QueryRowContext(ctx, query, tenant).Scan(&fields, &p.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return p, nil
+	}
+	if err != nil {
+		return models.ValidationPolicy{}, err
+	}
+	if fields != "" {
+		p.RequiredFields = strings.Split(fields, ",")
+	}
+	p.UpdatedAt = p.UpdatedAt.UTC()
+	return p, nil
+}
+
+func (r *PostgresValidationPolicyRepository) Put(p models.ValidationPolicy) (models.ValidationPolicy, error) {
+	query := `
+		INSERT INTO validation_policies (tenant, required_fields, updated_at) VALUES ($1, $2, $3)
+		ON CONFLICT (tenant) DO UPDATE SET required_fields = EXCLUDED.required_fields, updated_at = EXCLUDED.updated_at`
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	p.UpdatedAt = time.Now().UTC()
+	if _, err := r.db.ExecContext(ctx, query, p.Tenant, strings.Join(p.RequiredFields, ","), p.UpdatedAt); err != nil {
+		return models.ValidationPolicy{}, err
+	}
+	return p, nil
+}
@@ -0,0 +1,10 @@
+package repo
+
+import "github.com/rogerio-castellano/inventory-tracker/internal/models"
+
+// ValidationPolicyRepository stores one validation policy per tenant.
+type ValidationPolicyRepository interface {
+	// Get returns an empty policy when the tenant has none.
+	Get(tenant string) (models.ValidationPolicy, error)
+	Put(p models.ValidationPolicy) (models.ValidationPolicy, error)
+}
@@ -0,0 +1,133 @@
+package repo
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+type InMemoryWarehouseRepository struct {
+	mu         sync.Mutex
+	warehouses []models.Warehouse
+	nextID     int
+	products   *InMemoryProductRepository
+}
+
+var _ WarehouseRepository = (*InMemoryWarehouseRepository)(nil)
+
+// NewInMemoryWarehouseRepository reads warehouse stock from products, which
+// keeps it along with the quantities it splits.
+func NewInMemoryWarehouseRepository(products *InMemoryProductRepository) *InMemoryWarehouseRepository {
+	r := &InMemoryWarehouseRepository{nextID: 1, products: products}
+	products.warehouses = r
+	return r
+}
+
+func (r *InMemoryWarehouseRepository) nameTaken(name string, exceptID int) bool {
+	for _, w := range r.warehouses {
+		if w.ID != exceptID && strings.EqualFold(w.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *InMemoryWarehouseRepository) Create(w models.Warehouse) (models.Warehouse, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.nameTaken(w.Name, 0) {
+		return models.Warehouse{}, fmt.Errorf("%w: warehouse %q", ErrDuplicatedValueUnique, w.Name)
+	}
+	w.ID = r.nextID
+	w.CreatedAt = time.Now().UTC()
+	w.UpdatedAt = w.CreatedAt
+	r.nextID++
+	r.warehouses = append(r.warehouses, w)
+	return w, nil
+}
+
+func (r *InMemoryWarehouseRepository) GetByID(id int) (models.Warehouse, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, w := range r.warehouses {
+		if w.ID == id {
+			return w, nil
+		}
+	}
+	return models.Warehouse{}, ErrWarehouseNotFound
+}
+
+func (r *InMemoryWarehouseRepository) List(scope AccessScope) ([]models.Warehouse, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	warehouses := []models.Warehouse{}
+	for _, w := range r.warehouses {
+		if scope.AllowsWarehouse(w.ID) {
+			warehouses = append(warehouses, w)
+		}
+	}
+	sort.Slice(warehouses, func(i, j int) bool { return warehouses[i].Name < warehouses[j].Name })
+	return warehouses, nil
+}
+
+func (r *InMemoryWarehouseRepository) Update(w models.Warehouse) (models.Warehouse, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.nameTaken(w.Name, w.ID) {
+		return models.Warehouse{}, fmt.Errorf("%w: warehouse %q", ErrDuplicatedValueUnique, w.Name)
+	}
+	for i, existing := range r.warehouses {
+		if existing.ID == w.ID {
+			w.CreatedAt = existing.CreatedAt
+			w.UpdatedAt = time.Now().UTC()
+			r.warehouses[i] = w
+			return w, nil
+		}
+	}
+	return models.Warehouse{}, ErrWarehouseNotFound
+}
+
+func (r *InMemoryWarehouseRepository) Delete(id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for k, units := range r.products.locations {
+		if k.warehouseID == id && units > 0 {
+			return ErrWarehouseNotEmpty
+		}
+	}
+	for i, w := range r.warehouses {
+		if w.ID == id {
+			r.warehouses = append(r.warehouses[:i], r.warehouses[i+1:]...)
+			return nil
+		}
+	}
+	return ErrWarehouseNotFound
+}
+
+func (r *InMemoryWarehouseRepository) Stock(productID int) ([]models.WarehouseStock, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stock := []models.WarehouseStock{}
+	for _, w := range r.warehouses {
+		if units := r.products.locations[location{productID, w.ID}]; units > 0 {
+			stock = append(stock, models.WarehouseStock{WarehouseID: w.ID, Warehouse: w.Name, Quantity: units})
+		}
+	}
+	sort.Slice(stock, func(i, j int) bool { return stock[i].Warehouse < stock[j].Warehouse })
+	return stock, nil
+}
+
+func (r *InMemoryWarehouseRepository) exists(id int) bool {
+	_, err := r.GetByID(id)
+	return err == nil
+}
@@ -0,0 +1,182 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+type PostgresWarehouseRepository struct {
+	db *sql.DB
+}
+
+var _ WarehouseRepository = (*PostgresWarehouseRepository)(nil)
+
+func NewPostgresWarehouseRepository(db *sql.DB) *PostgresWarehouseRepository {
+	return &PostgresWarehouseRepository{db: db}
+}
+
+const warehouseColumns = `id, name, address, created_at, updated_at`
+
+func scanWarehouse(row rowScanner) (models.Warehouse, error) {
+	var w models.Warehouse
+	err := row.Scan(&w.ID, &w.Name, &w.Address, &w.CreatedAt, &w.UpdatedAt)
+	w.CreatedAt, w.UpdatedAt = w.CreatedAt.UTC(), w.UpdatedAt.UTC()
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Warehouse{}, ErrWarehouseNotFound
+	}
+	return w, err
+}
+
+// warehouseWriteError translates constraint violations into repository errors.
+func warehouseWriteError(err error) error {
+	switch {
+	case strings.Contains(err.Error(), "23505"):
+		return fmt.Errorf("%w: %v", ErrDuplicatedValueUnique, err)
+	case strings.Contains(err.Error(), "23503"):
+		return fmt.Errorf("%w: %v", ErrWarehouseNotEmpty, err)
+	}
+	return err
+}
+
+func (r *PostgresWarehouseRepository) Create(w models.Warehouse) (models.Warehouse, error) {
+	query := `INSERT INTO warehouses (name, address, created_at, updated_at) VALUES ($1, $2, $3, $3) RETURNING id`
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	w.CreatedAt = time.Now().UTC()
+	w.UpdatedAt = w.CreatedAt
+	if err := r.db.QueryRowContext(ctx, query, w.Name, w.Address, w.CreatedAt).Scan(&w.ID); err != nil {
+		return models.Warehouse{}, warehouseWriteError(err)
+	}
+	return w, nil
+}
+
+func (r *PostgresWarehouseRepository) GetByID(id int) (models.Warehouse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return scanWarehouse(r.db.QueryRowContext(ctx, `SELECT `+warehouseColumns+` FROM warehouses WHERE id = $1`, id))
+}
+
+func (r *PostgresWarehouseRepository) List(scope AccessScope) ([]models.Warehouse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `SELECT ` + warehouseColumns + ` FROM warehouses`
+	var args []any
+	if scope.WarehouseIDs != nil {
+		query += ` WHERE id = ANY($1)`
+		args = append(args, scope.WarehouseIDs)
+	}
+	rows, err := r.db.QueryContext(ctx, query+` ORDER BY name`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	warehouses := []models.Warehouse{}
+	for rows.Next() {
+		w, err := scanWarehouse(rows)
+		if err != nil {
+			return nil, err
+		}
+		warehouses = append(warehouses, w)
+	}
+	return warehouses, rows.Err()
+}
+
+func (r *PostgresWarehouseRepository) Update(w models.Warehouse) (models.Warehouse, error) {
+	query := `UPDATE warehouses SET name = $1, address = $2, updated_at = $3 WHERE id = $4 RETURNING created_at`
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	w.UpdatedAt = time.Now().UTC()
+	err := r.db.QueryRowContext(ctx, query, w.Name, w.Address, w.UpdatedAt, w.ID).Scan(&w.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Warehouse{}, ErrWarehouseNotFound
+	}
+	if err != nil {
+		return models.Warehouse{}, warehouseWriteError(err)
+	}
+	w.CreatedAt = w.CreatedAt.UTC()
+	return w, nil
+}
+
+func (r *PostgresWarehouseRepository) Delete(id int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Stock that was taken back out leaves empty rows, which must not keep
+	// the warehouse alive.
+	if _, err := tx.ExecContext(ctx, `DELETE FROM warehouse_stock WHERE warehouse_id = $1 AND quantity = 0`, id); err != nil {
+		return err
+	}
+	res, err := tx.ExecContext(ctx, `DELETE FROM warehouses WHERE id = $1`, id)
+	if err != nil {
+		return warehouseWriteError(err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrWarehouseNotFound
+	}
+	return tx.Commit()
+}
+
+func (r *PostgresWarehouseRepository) Stock(productID int) ([]models.WarehouseStock, error) {
+	query := `
+		SELECT s.warehouse_id, w.name, s.quantity
+		FROM warehouse_stock s
+		JOIN warehouses w ON w.id = s.warehouse_id
+		WHERE s.product_id = $1 AND s.quantity > 0
+		ORDER BY w.name`
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stock := []models.WarehouseStock{}
+	for rows.Next() {
+		var s models.WarehouseStock
+		if err := rows.Scan(&s.WarehouseID, &s.Warehouse, &s.Quantity); err != nil {
+			return nil, err
+		}
+		stock = append(stock, s)
+	}
+	return stock, rows.Err()
+}
+
+// adjustWarehouseStock adds delta to the units of a product a warehouse
+// holds, within tx. It fails with ErrInvalidQuantityChange if the warehouse
+// would hold fewer than none and with ErrWarehouseNotFound if it does not
+// exist.
+func adjustWarehouseStock(ctx context.Context, tx *sql.Tx, productID, warehouseID, delta int, at time.Time) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO warehouse_stock (warehouse_id, product_id, quantity, updated_at) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (warehouse_id, product_id)
+		DO UPDATE SET quantity = warehouse_stock.quantity + EXCLUDED.quantity, updated_at = EXCLUDED.updated_at
+	`, warehouseID, productID, delta, at)
+	switch {
+	case err == nil:
+		return nil
+	case strings.Contains(err.Error(), "23514"):
+		return fmt.Errorf("%w: warehouse %d", ErrInvalidQuantityChange, warehouseID)
+	case strings.Contains(err.Error(), "23503"):
+		return fmt.Errorf("%w: %d", ErrWarehouseNotFound, warehouseID)
+	}
+	return err
+}
@@ -0,0 +1,26 @@
+package repo
+
+import (
+	"errors"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+// WarehouseRepository defines the interface for warehouse data operations.
+// Stock is moved in and out of warehouses by ProductRepository's
+// AdjustWithMovements, so a product's total and its split change together.
+type WarehouseRepository interface {
+	Create(w models.Warehouse) (models.Warehouse, error)
+	GetByID(id int) (models.Warehouse, error)
+	// List returns the warehouses in scope by name.
+	List(scope AccessScope) ([]models.Warehouse, error)
+	Update(w models.Warehouse) (models.Warehouse, error)
+	// Delete fails with ErrWarehouseNotEmpty while the warehouse holds stock.
+	Delete(id int) error
+	// Stock returns the units of the product each warehouse holds, by
+	// warehouse name, leaving out warehouses without any.
+	Stock(productID int) ([]models.WarehouseStock, error)
+}
+
+var ErrWarehouseNotFound = errors.New("warehouse not found")
+var ErrWarehouseNotEmpty = errors.New("warehouse still holds stock")
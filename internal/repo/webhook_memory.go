@@ -0,0 +1,175 @@
+package repo
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+type InMemoryWebhookRepository struct {
+	mu             sync.Mutex
+	webhooks       []models.Webhook
+	deliveries     []models.WebhookDelivery
+	nextID         int
+	nextDeliveryID int
+}
+
+var _ WebhookRepository = (*InMemoryWebhookRepository)(nil)
+
+func NewInMemoryWebhookRepository() *InMemoryWebhookRepository {
+	return &InMemoryWebhookRepository{nextID: 1, nextDeliveryID: 1}
+}
+
+func (r *InMemoryWebhookRepository) Create(w models.Webhook) (models.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	w.ID = r.nextID
+	w.CreatedAt = time.Now().UTC()
+	r.nextID++
+	r.webhooks = append(r.webhooks, w)
+	return w, nil
+}
+
+func (r *InMemoryWebhookRepository) GetByID(id int) (models.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, w := range r.webhooks {
+		if w.ID == id {
+			return w, nil
+		}
+	}
+	return models.Webhook{}, ErrWebhookNotFound
+}
+
+func (r *InMemoryWebhookRepository) List() ([]models.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]models.Webhook{}, r.webhooks...), nil
+}
+
+func (r *InMemoryWebhookRepository) SetEvents(id int, events []string) (models.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, w := range r.webhooks {
+		if w.ID == id {
+			r.webhooks[i].Events = slices.Clone(events)
+			return r.webhooks[i], nil
+		}
+	}
+	return models.Webhook{}, ErrWebhookNotFound
+}
+
+func (r *InMemoryWebhookRepository) Subscribed(eventType string) ([]models.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	webhooks := []models.Webhook{}
+	for _, w := range r.webhooks {
+		if slices.Contains(w.Events, eventType) {
+			webhooks = append(webhooks, w)
+		}
+	}
+	return webhooks, nil
+}
+
+func (r *InMemoryWebhookRepository) Delete(id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n := len(r.webhooks)
+	r.webhooks = slices.DeleteFunc(r.webhooks, func(w models.Webhook) bool { return w.ID == id })
+	if len(r.webhooks) == n {
+		return ErrWebhookNotFound
+	}
+	r.deliveries = slices.DeleteFunc(r.deliveries, func(d models.WebhookDelivery) bool { return d.WebhookID == id })
+	return nil
+}
+
+func (r *InMemoryWebhookRepository) Enqueue(deliveries []models.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now().UTC()
+	for _, d := range deliveries {
+		d.ID = r.nextDeliveryID
+		d.Status = models.WebhookDeliveryPending
+		d.CreatedAt = now
+		r.nextDeliveryID++
+		r.deliveries = append(r.deliveries, d)
+	}
+	return nil
+}
+
+func (r *InMemoryWebhookRepository) AddDelivery(d models.WebhookDelivery) (models.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	d.ID = r.nextDeliveryID
+	d.Status = models.WebhookDeliveryPending
+	d.CreatedAt = time.Now().UTC()
+	r.nextDeliveryID++
+	r.deliveries = append(r.deliveries, d)
+	return d, nil
+}
+
+func (r *InMemoryWebhookRepository) Delivery(webhookID, id int) (models.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, d := range r.deliveries {
+		if d.ID == id && d.WebhookID == webhookID {
+			return d, nil
+		}
+	}
+	return models.WebhookDelivery{}, ErrWebhookDeliveryNotFound
+}
+
+func (r *InMemoryWebhookRepository) ClaimDue(now time.Time, lease time.Duration, limit int) ([]models.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	due := []models.WebhookDelivery{}
+	for i, d := range r.deliveries {
+		if len(due) == limit {
+			break
+		}
+		if d.Status == models.WebhookDeliveryPending && !d.NextAttemptAt.After(now) {
+			r.deliveries[i].NextAttemptAt = now.Add(lease)
+			due = append(due, r.deliveries[i])
+		}
+	}
+	return due, nil
+}
+
+func (r *InMemoryWebhookRepository) RecordAttempt(d models.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.deliveries {
+		if r.deliveries[i].ID == d.ID {
+			r.deliveries[i] = d
+			return nil
+		}
+	}
+	return nil
+}
+
+func (r *InMemoryWebhookRepository) Deliveries(webhookID int, status string, limit int) ([]models.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	deliveries := []models.WebhookDelivery{}
+	for i := len(r.deliveries) - 1; i >= 0 && len(deliveries) < limit; i-- {
+		d := r.deliveries[i]
+		if d.WebhookID == webhookID && (status == "" || d.Status == status) {
+			deliveries = append(deliveries, d)
+		}
+	}
+	return deliveries, nil
+}
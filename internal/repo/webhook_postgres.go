@@ -0,0 +1,246 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+type PostgresWebhookRepository struct {
+	db *sql.DB
+}
+
+var _ WebhookRepository = (*PostgresWebhookRepository)(nil)
+
+func NewPostgresWebhookRepository(db *sql.DB) *PostgresWebhookRepository {
+	return &PostgresWebhookRepository{db: db}
+}
+
+// Event types are stored comma-separated: there are few of them and they
+// never contain commas.
+const webhookColumns = `id, url, events, secret, created_by, created_at`
+
+func scanWebhook(row rowScanner) (models.Webhook, error) {
+	var w models.Webhook
+	var events string
+	err := row.Scan(&w.ID, &w.URL, &events, &w.Secret, &w.CreatedBy, &w.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Webhook{}, ErrWebhookNotFound
+	}
+	w.Events = strings.Split(events, ",")
+	w.CreatedAt = w.CreatedAt.UTC()
+	return w, err
+}
+
+const webhookDeliveryColumns = `id, webhook_id, event_id, event_type, payload, status, attempts, response_status, last_error, next_attempt_at, delivered_at, created_at`
+
+func scanWebhookDelivery(row rowScanner) (models.WebhookDelivery, error) {
+	var d models.WebhookDelivery
+	var payload string
+	var deliveredAt sql.NullTime
+	err := row.Scan(&d.ID, &d.WebhookID, &d.EventID, &d.EventType, &payload, &d.Status, &d.Attempts,
+		&d.ResponseStatus, &d.LastError, &d.NextAttemptAt, &deliveredAt, &d.CreatedAt)
+	if err != nil {
+		return models.WebhookDelivery{}, err
+	}
+	d.Payload = []byte(payload)
+	if deliveredAt.Valid {
+		t := deliveredAt.Time.UTC()
+		d.DeliveredAt = &t
+	}
+	d.NextAttemptAt, d.CreatedAt = d.NextAttemptAt.UTC(), d.CreatedAt.UTC()
+	return d, nil
+}
+
+func (r *PostgresWebhookRepository) Create(w models.Webhook) (models.Webhook, error) {
+	query := `INSERT INTO webhooks (url, events, secret, created_by, created_at) VALUES ($1, $2, $3, $4, $5) RETURNING id`
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	w.CreatedAt = time.Now().UTC()
+	if err := r.db.QueryRowContext(ctx, query, w.URL, strings.Join(w.Events, ","), w.Secret, w.CreatedBy, w.CreatedAt).Scan(&w.ID); err != nil {
+		return models.Webhook{}, err
+	}
+	return w, nil
+}
+
+func (r *PostgresWebhookRepository) GetByID(id int) (models.Webhook, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return scanWebhook(r.db.QueryRowContext(ctx, `SELECT `+webhookColumns+` FROM webhooks WHERE id = $1`, id))
+}
+
+func (r *PostgresWebhookRepository) List() ([]models.Webhook, error) {
+	return r.query(`SELECT ` + webhookColumns + ` FROM webhooks ORDER BY id`)
+}
+
+func (r *PostgresWebhookRepository) SetEvents(id int, events []string) (models.Webhook, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	return scanWebhook(r.db.QueryRowContext(ctx, `UPDATE webhooks SET events = $2 WHERE id = $1 RETURNING `+webhookColumns, id, strings.Join(events, ",")))
+}
+
+func (r *PostgresWebhookRepository) Subscribed(eventType string) ([]models.Webhook, error) {
+	return r.query(`SELECT `+webhookColumns+` FROM webhooks WHERE $1 = ANY(string_to_array(events, ',')) ORDER BY id`, eventType)
+}
+
+func (r *PostgresWebhookRepository) query(query string, args ...any) ([]models.Webhook, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	webhooks := []models.Webhook{}
+	for rows.Next() {
+		w, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, w)
+	}
+	return webhooks, rows.Err()
+}
+
+func (r *PostgresWebhookRepository) Delete(id int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	res, err := r.db.ExecContext(ctx, `DELETE FROM webhooks WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrWebhookNotFound
+	}
+	return nil
+}
+
+func (r *PostgresWebhookRepository) Enqueue(deliveries []models.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), bulkTimeout)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, payload, status, next_attempt_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	now := time.Now().UTC()
+	for _, d := range deliveries {
+		if _, err := stmt.ExecContext(ctx, d.WebhookID, d.EventID, d.EventType, string(d.Payload), models.WebhookDeliveryPending, d.NextAttemptAt, now); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *PostgresWebhookRepository) AddDelivery(d models.WebhookDelivery) (models.WebhookDelivery, error) {
+	query := `INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, payload, status, next_attempt_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	d.Status = models.WebhookDeliveryPending
+	d.CreatedAt = time.Now().UTC()
+	if err := r.db.QueryRowContext(ctx, query, d.WebhookID, d.EventID, d.EventType, string(d.Payload), d.Status, d.NextAttemptAt, d.CreatedAt).Scan(&d.ID); err != nil {
+		return models.WebhookDelivery{}, err
+	}
+	return d, nil
+}
+
+func (r *PostgresWebhookRepository) Delivery(webhookID, id int) (models.WebhookDelivery, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	d, err := scanWebhookDelivery(r.db.QueryRowContext(ctx, `SELECT `+webhookDeliveryColumns+` FROM webhook_deliveries WHERE id = $1 AND webhook_id = $2`, id, webhookID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.WebhookDelivery{}, ErrWebhookDeliveryNotFound
+	}
+	return d, err
+}
+
+func (r *PostgresWebhookRepository) ClaimDue(now time.Time, lease time.Duration, limit int) ([]models.WebhookDelivery, error) {
+	query := `
+		UPDATE webhook_deliveries SET next_attempt_at = $2
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= $1
+			ORDER BY next_attempt_at, id
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + webhookDeliveryColumns
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, now, now.Add(lease), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []models.WebhookDelivery{}
+	for rows.Next() {
+		d, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+func (r *PostgresWebhookRepository) RecordAttempt(d models.WebhookDelivery) error {
+	query := `UPDATE webhook_deliveries
+		SET status = $2, attempts = $3, response_status = $4, last_error = $5, next_attempt_at = $6, delivered_at = $7
+		WHERE id = $1`
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, query, d.ID, d.Status, d.Attempts, d.ResponseStatus, d.LastError, d.NextAttemptAt, d.DeliveredAt)
+	return err
+}
+
+func (r *PostgresWebhookRepository) Deliveries(webhookID int, status string, limit int) ([]models.WebhookDelivery, error) {
+	query := `SELECT ` + webhookDeliveryColumns + ` FROM webhook_deliveries
+		WHERE webhook_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY id DESC LIMIT $3`
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, webhookID, status, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []models.WebhookDelivery{}
+	for rows.Next() {
+		d, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
@@ -0,0 +1,41 @@
+package repo
+
+import (
+	"errors"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+type WebhookRepository interface {
+	Create(w models.Webhook) (models.Webhook, error)
+	GetByID(id int) (models.Webhook, error)
+	List() ([]models.Webhook, error)
+	// SetEvents replaces the event types the webhook is subscribed to.
+	SetEvents(id int, events []string) (models.Webhook, error)
+	// Delete removes the webhook and its deliveries.
+	Delete(id int) error
+	// Subscribed returns the webhooks subscribed to eventType.
+	Subscribed(eventType string) ([]models.Webhook, error)
+	// Enqueue stores pending deliveries, due at their NextAttemptAt.
+	Enqueue(deliveries []models.WebhookDelivery) error
+	// AddDelivery stores one pending delivery and returns it with its ID.
+	AddDelivery(d models.WebhookDelivery) (models.WebhookDelivery, error)
+	// Delivery returns the delivery with id of the webhook.
+	Delivery(webhookID, id int) (models.WebhookDelivery, error)
+	// ClaimDue returns up to limit pending deliveries due at now and pushes
+	// their next attempt back by lease, so other dispatchers skip them while
+	// they are attempted.
+	ClaimDue(now time.Time, lease time.Duration, limit int) ([]models.WebhookDelivery, error)
+	// RecordAttempt saves the outcome of an attempt: status, attempts,
+	// response status, last error, next attempt and delivery time.
+	RecordAttempt(d models.WebhookDelivery) error
+	// Deliveries returns up to limit deliveries of the webhook, newest
+	// first, only those with status when it is set.
+	Deliveries(webhookID int, status string, limit int) ([]models.WebhookDelivery, error)
+}
+
+var (
+	ErrWebhookNotFound         = errors.New("webhook not found")
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+)
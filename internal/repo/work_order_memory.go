@@ -0,0 +1,95 @@
+package repo
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+// InMemoryWorkOrderRepository posts stock through the given product and
+// movement repositories. Unlike the Postgres implementation it cannot roll
+// back, so it checks every component before touching any stock.
+type InMemoryWorkOrderRepository struct {
+	mu         sync.Mutex
+	workOrders []models.WorkOrder
+	products   ProductRepository
+	movements  MovementRepository
+}
+
+var _ WorkOrderRepository = (*InMemoryWorkOrderRepository)(nil)
+
+func NewInMemoryWorkOrderRepository(products ProductRepository, movements MovementRepository) *InMemoryWorkOrderRepository {
+	return &InMemoryWorkOrderRepository{products: products, movements: movements}
+}
+
+func (r *InMemoryWorkOrderRepository) Create(wo models.WorkOrder) (models.WorkOrder, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	wo.ID = len(r.workOrders) + 1
+	wo.Status = models.WorkOrderStatusOpen
+	wo.CreatedAt = time.Now().UTC()
+	wo.Components = slices.Clone(wo.Components)
+	r.workOrders = append(r.workOrders, wo)
+	return wo, nil
+}
+
+func (r *InMemoryWorkOrderRepository) GetByID(id int) (models.WorkOrder, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if id < 1 || id > len(r.workOrders) {
+		return models.WorkOrder{}, ErrWorkOrderNotFound
+	}
+	return r.workOrders[id-1], nil
+}
+
+func (r *InMemoryWorkOrderRepository) Complete(id int) (models.WorkOrder, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if id < 1 || id > len(r.workOrders) {
+		return models.WorkOrder{}, ErrWorkOrderNotFound
+	}
+	wo := &r.workOrders[id-1]
+	if wo.Status != models.WorkOrderStatusOpen {
+		return models.WorkOrder{}, ErrWorkOrderNotOpen
+	}
+
+	var shortages []Shortage
+	for _, c := range wo.Components {
+		p, err := r.products.GetByID(c.ProductID)
+		if err != nil {
+			return models.WorkOrder{}, err
+		}
+		if required := c.QuantityPerUnit * wo.Quantity; p.Quantity < required {
+			shortages = append(shortages, Shortage{ProductID: c.ProductID, Required: required, Available: p.Quantity})
+		}
+	}
+	if len(shortages) > 0 {
+		return models.WorkOrder{}, &ShortageError{Shortages: shortages}
+	}
+
+	post := func(productID, delta int) error {
+		if _, err := r.products.AdjustQuantity(productID, delta); err != nil {
+			return err
+		}
+		_, err := r.movements.Log(models.Movement{ProductID: productID, Delta: delta, WorkOrderID: &wo.ID})
+		return err
+	}
+	for _, c := range wo.Components {
+		if err := post(c.ProductID, -c.QuantityPerUnit*wo.Quantity); err != nil {
+			return models.WorkOrder{}, err
+		}
+	}
+	if err := post(wo.ProductID, wo.Quantity); err != nil {
+		return models.WorkOrder{}, err
+	}
+
+	now := time.Now().UTC()
+	wo.Status = models.WorkOrderStatusCompleted
+	wo.CompletedAt = &now
+	return *wo, nil
+}
@@ -0,0 +1,185 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+type PostgresWorkOrderRepository struct {
+	db *sql.DB
+}
+
+var _ WorkOrderRepository = (*PostgresWorkOrderRepository)(nil)
+
+func NewPostgresWorkOrderRepository(db *sql.DB) *PostgresWorkOrderRepository {
+	return &PostgresWorkOrderRepository{db: db}
+}
+
+func (r *PostgresWorkOrderRepository) Create(wo models.WorkOrder) (models.WorkOrder, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.WorkOrder{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	wo.Status = models.WorkOrderStatusOpen
+	wo.CreatedAt = time.Now().UTC()
+	query := `INSERT INTO work_orders (product_id, quantity, status, note, created_by, created_at) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
+	if err := tx.QueryRowContext(ctx, query, wo.ProductID, wo.Quantity, wo.Status, wo.Note, wo.CreatedBy, wo.CreatedAt).Scan(&wo.ID); err != nil {
+		return models.WorkOrder{}, err
+	}
+	for _, c := range wo.Components {
+		_, err := tx.ExecContext(ctx, `INSERT INTO work_order_components (work_order_id, product_id, quantity_per_unit) VALUES ($1, $2, $3)`,
+			wo.ID, c.ProductID, c.QuantityPerUnit)
+		if err != nil {
+			return models.WorkOrder{}, err
+		}
+	}
+
+	return wo, tx.Commit()
+}
+
+func (r *PostgresWorkOrderRepository) GetByID(id int) (models.WorkOrder, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var wo models.WorkOrder
+	var completedAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, `SELECT id, product_id, quantity, status, note, created_by, created_at, completed_at FROM work_orders WHERE id = $1`, id).
+		Scan(&wo.ID, &wo.ProductID, &wo.Quantity, &wo.Status, &wo.Note, &wo.CreatedBy, &wo.CreatedAt, &completedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.WorkOrder{}, ErrWorkOrderNotFound
+	}
+	if err != nil {
+		return models.WorkOrder{}, err
+	}
+	wo.CreatedAt = wo.CreatedAt.UTC()
+	if completedAt.Valid {
+		t := completedAt.Time.UTC()
+		wo.CompletedAt = &t
+	}
+
+	wo.Components, err = workOrderComponents(ctx, r.db, id)
+	return wo, err
+}
+
+func (r *PostgresWorkOrderRepository) Complete(id int) (models.WorkOrder, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), bulkTimeout)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.WorkOrder{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var productID, quantity int
+	var status string
+	err = tx.QueryRowContext(ctx, `SELECT product_id, quantity, status FROM work_orders WHERE id = $1 FOR UPDATE`, id).Scan(&productID, &quantity, &status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.WorkOrder{}, ErrWorkOrderNotFound
+	}
+	if err != nil {
+		return models.WorkOrder{}, err
+	}
+	if status != models.WorkOrderStatusOpen {
+		return models.WorkOrder{}, ErrWorkOrderNotOpen
+	}
+
+	parts, err := workOrderComponents(ctx, tx, id)
+	if err != nil {
+		return models.WorkOrder{}, err
+	}
+
+	// Lock component rows in id order so concurrent completions cannot deadlock.
+	ids := make([]int, len(parts))
+	for i, c := range parts {
+		ids[i] = c.ProductID
+	}
+	rows, err := tx.QueryContext(ctx, `SELECT id, quantity FROM products WHERE id = ANY($1) ORDER BY id FOR UPDATE`, ids)
+	if err != nil {
+		return models.WorkOrder{}, err
+	}
+	stock := map[int]int{}
+	for rows.Next() {
+		var pid, qty int
+		if err := rows.Scan(&pid, &qty); err != nil {
+			rows.Close()
+			return models.WorkOrder{}, err
+		}
+		stock[pid] = qty
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return models.WorkOrder{}, err
+	}
+
+	var shortages []Shortage
+	for _, c := range parts {
+		if required := c.QuantityPerUnit * quantity; stock[c.ProductID] < required {
+			shortages = append(shortages, Shortage{ProductID: c.ProductID, Required: required, Available: stock[c.ProductID]})
+		}
+	}
+	if len(shortages) > 0 {
+		return models.WorkOrder{}, &ShortageError{Shortages: shortages}
+	}
+
+	now := time.Now().UTC()
+	post := func(pid, delta int) error {
+		res, err := tx.ExecContext(ctx, `UPDATE products SET quantity = quantity + $1, updated_at = $2 WHERE id = $3`, delta, now, pid)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return ErrProductNotFound
+		}
+		_, err = tx.ExecContext(ctx, `INSERT INTO movements (product_id, delta, created_at, updated_at, work_order_id) VALUES ($1, $2, $3, $3, $4)`,
+			pid, delta, now, id)
+		return err
+	}
+	for _, c := range parts {
+		if err := post(c.ProductID, -c.QuantityPerUnit*quantity); err != nil {
+			return models.WorkOrder{}, err
+		}
+	}
+	if err := post(productID, quantity); err != nil {
+		return models.WorkOrder{}, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE work_orders SET status = $1, completed_at = $2 WHERE id = $3`, models.WorkOrderStatusCompleted, now, id); err != nil {
+		return models.WorkOrder{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return models.WorkOrder{}, err
+	}
+	return r.GetByID(id)
+}
+
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+func workOrderComponents(ctx context.Context, q queryer, workOrderID int) ([]models.WorkOrderComponent, error) {
+	rows, err := q.QueryContext(ctx, `SELECT product_id, quantity_per_unit FROM work_order_components WHERE work_order_id = $1 ORDER BY product_id`, workOrderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	parts := []models.WorkOrderComponent{}
+	for rows.Next() {
+		var c models.WorkOrderComponent
+		if err := rows.Scan(&c.ProductID, &c.QuantityPerUnit); err != nil {
+			return nil, err
+		}
+		parts = append(parts, c)
+	}
+	return parts, rows.Err()
+}
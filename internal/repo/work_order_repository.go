@@ -0,0 +1,40 @@
+package repo
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+// WorkOrderRepository defines the interface for kitting/assembly work orders.
+type WorkOrderRepository interface {
+	Create(wo models.WorkOrder) (models.WorkOrder, error)
+	GetByID(id int) (models.WorkOrder, error)
+	// Complete consumes the components and produces the finished good in a
+	// single transaction, logging a movement tagged with the work order for
+	// each product touched. Nothing changes when a component is short; the
+	// error is then a *ShortageError.
+	Complete(id int) (models.WorkOrder, error)
+}
+
+var (
+	ErrWorkOrderNotFound = errors.New("work order not found")
+	ErrWorkOrderNotOpen  = errors.New("work order is not open")
+)
+
+// Shortage is a component without enough stock to complete a work order.
+type Shortage struct {
+	ProductID int `json:"product_id"`
+	Required  int `json:"required"`
+	Available int `json:"available"`
+}
+
+// ShortageError lists every short component of a work order.
+type ShortageError struct {
+	Shortages []Shortage
+}
+
+func (e *ShortageError) Error() string {
+	return fmt.Sprintf("%d component(s) short", len(e.Shortages))
+}
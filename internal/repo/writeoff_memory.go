@@ -0,0 +1,65 @@
+package repo
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+type InMemoryWriteOffRepository struct {
+	mu        sync.Mutex
+	writeOffs []models.WriteOff
+}
+
+var _ WriteOffRepository = (*InMemoryWriteOffRepository)(nil)
+
+func NewInMemoryWriteOffRepository() *InMemoryWriteOffRepository {
+	return &InMemoryWriteOffRepository{}
+}
+
+func (r *InMemoryWriteOffRepository) Create(w models.WriteOff) (models.WriteOff, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if w.CreatedAt.IsZero() {
+		w.CreatedAt = time.Now().UTC()
+	}
+	w.ID = len(r.writeOffs) + 1
+	r.writeOffs = append(r.writeOffs, w)
+	return w, nil
+}
+
+func (r *InMemoryWriteOffRepository) TotalsSince(since time.Time) ([]WasteTotal, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	type key struct{ reason, category string }
+	byKey := map[key]*WasteTotal{}
+	for _, w := range r.writeOffs {
+		if w.CreatedAt.Before(since) {
+			continue
+		}
+		k := key{w.Reason, w.Category}
+		t, ok := byKey[k]
+		if !ok {
+			t = &WasteTotal{Reason: w.Reason, Category: w.Category}
+			byKey[k] = t
+		}
+		t.Quantity += w.Quantity
+		t.Value += w.TotalCost
+	}
+
+	totals := make([]WasteTotal, 0, len(byKey))
+	for _, t := range byKey {
+		totals = append(totals, *t)
+	}
+	sort.Slice(totals, func(i, j int) bool {
+		if totals[i].Reason != totals[j].Reason {
+			return totals[i].Reason < totals[j].Reason
+		}
+		return totals[i].Category < totals[j].Category
+	})
+	return totals, nil
+}
@@ -0,0 +1,58 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+type PostgresWriteOffRepository struct {
+	db *sql.DB
+}
+
+var _ WriteOffRepository = (*PostgresWriteOffRepository)(nil)
+
+func NewPostgresWriteOffRepository(db *sql.DB) *PostgresWriteOffRepository {
+	return &PostgresWriteOffRepository{db: db}
+}
+
+func (r *PostgresWriteOffRepository) Create(w models.WriteOff) (models.WriteOff, error) {
+	query := `INSERT INTO write_offs (product_id, category, quantity, reason, unit_cost, total_cost, note, created_by, created_at, movement_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id`
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	if w.CreatedAt.IsZero() {
+		w.CreatedAt = time.Now().UTC()
+	}
+	err := r.db.QueryRowContext(ctx, query, w.ProductID, w.Category, w.Quantity, w.Reason, w.UnitCost, w.TotalCost, w.Note, w.CreatedBy, w.CreatedAt, w.MovementID).Scan(&w.ID)
+	if err != nil {
+		return models.WriteOff{}, err
+	}
+	return w, nil
+}
+
+func (r *PostgresWriteOffRepository) TotalsSince(since time.Time) ([]WasteTotal, error) {
+	query := `SELECT reason, category, SUM(quantity), SUM(total_cost) FROM write_offs
+		WHERE created_at >= $1 GROUP BY reason, category ORDER BY reason, category`
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, query, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	totals := []WasteTotal{}
+	for rows.Next() {
+		var t WasteTotal
+		if err := rows.Scan(&t.Reason, &t.Category, &t.Quantity, &t.Value); err != nil {
+			return nil, err
+		}
+		totals = append(totals, t)
+	}
+	return totals, rows.Err()
+}
@@ -0,0 +1,24 @@
+package repo
+
+import (
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+// WasteTotal is the written-off quantity and value for one reason and
+// category pair.
+type WasteTotal struct {
+	Reason   string
+	Category string
+	Quantity int
+	Value    float64
+}
+
+// WriteOffRepository defines the interface for write-off data operations.
+type WriteOffRepository interface {
+	Create(w models.WriteOff) (models.WriteOff, error)
+	// TotalsSince aggregates write-offs recorded at or after since by reason
+	// and category.
+	TotalsSince(since time.Time) ([]WasteTotal, error)
+}
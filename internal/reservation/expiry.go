@@ -0,0 +1,31 @@
+// Package reservation releases stock reservations whose orders never came
+// through, once their time to live runs out.
+package reservation
+
+import (
+	"log"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/jobs"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+)
+
+// StartExpiryWorker removes expired reservations once at startup and then
+// every interval. Expired reservations already stop holding stock; removing
+// them keeps the table small.
+func StartExpiryWorker(reservations repo.ReservationRepository, interval time.Duration) {
+	jobs.Register("reservation_expiry", "every "+interval.String())
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := reservations.DeleteExpired(time.Now().UTC())
+		if err != nil {
+			log.Printf("⚠️ Failed to expire reservations: %v", err)
+		} else if n > 0 {
+			log.Printf("📦 Released %d expired reservations", n)
+		}
+		jobs.Record("reservation_expiry", err)
+		<-ticker.C
+	}
+}
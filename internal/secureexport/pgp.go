@@ -0,0 +1,70 @@
+package secureexport
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+)
+
+// ErrInvalidPublicKey is returned for a key that is not an ASCII-armored
+// PGP public key able to encrypt.
+var ErrInvalidPublicKey = errors.New("not an armored PGP public key with an encryption subkey")
+
+// ParsePublicKey reads an ASCII-armored PGP public key and returns its
+// fingerprint in upper-case hex.
+func ParsePublicKey(armored string) (string, error) {
+	entity, err := readEntity(armored)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%X", entity.PrimaryKey.Fingerprint), nil
+}
+
+// EncryptPGP encrypts content to the armored public key and returns the
+// ASCII-armored message, named filename for the recipient's client.
+func EncryptPGP(armored, filename string, content []byte) ([]byte, error) {
+	entity, err := readEntity(armored)
+	if err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	aw, err := armor.Encode(&out, "PGP MESSAGE", nil)
+	if err != nil {
+		return nil, err
+	}
+	pw, err := openpgp.Encrypt(aw, []*openpgp.Entity{entity}, nil, &openpgp.FileHints{IsBinary: true, FileName: filename}, nil)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := pw.Write(content); err != nil {
+		return nil, err
+	}
+	if err := pw.Close(); err != nil {
+		return nil, err
+	}
+	if err := aw.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+func readEntity(armored string) (*openpgp.Entity, error) {
+	entities, err := openpgp.ReadArmoredKeyRing(strings.NewReader(armored))
+	if err != nil || len(entities) != 1 {
+		return nil, ErrInvalidPublicKey
+	}
+	entity := entities[0]
+	// Encrypting to a key without an encryption subkey fails; find out now
+	// rather than when an export is due.
+	probe, err := openpgp.Encrypt(io.Discard, []*openpgp.Entity{entity}, nil, nil, nil)
+	if err != nil {
+		return nil, ErrInvalidPublicKey
+	}
+	return entity, probe.Close()
+}
@@ -0,0 +1,129 @@
+// Package security publishes authentication and abuse events, such as bans or
+// impersonation, and failed background jobs to external monitoring systems
+// like a SIEM.
+package security
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"sync"
+	"time"
+)
+
+// SchemaVersion is bumped whenever a field of Event changes meaning or is removed.
+const SchemaVersion = 1
+
+const (
+	EventLoginFailureBurst = "login_failure_burst"
+	EventBan               = "ban"
+	EventImpersonation     = "impersonation"
+	EventRoleChange        = "role_change"
+	EventJobIncident       = "job_incident"
+	// EventSandbox is only published on demand by sandbox deployments.
+	EventSandbox = "sandbox"
+	// EventAudit mirrors every audit log entry; it is chatty, so sinks only
+	// get it when explicitly enabled.
+	EventAudit = "audit"
+)
+
+// EventTypes lists every event type, so each can be switched on or off.
+var EventTypes = []string{EventLoginFailureBurst, EventBan, EventImpersonation, EventRoleChange, EventJobIncident, EventSandbox, EventAudit}
+
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// Event is the structured record sent to every sink.
+type Event struct {
+	SchemaVersion int            `json:"schema_version"`
+	ID            string         `json:"id"`
+	Type          string         `json:"type"`
+	Severity      string         `json:"severity"`
+	OccurredAt    time.Time      `json:"occurred_at"`
+	Actor         string         `json:"actor,omitempty"`  // who caused the event, when known
+	Target        string         `json:"target,omitempty"` // user or client the event is about
+	SourceIP      string         `json:"source_ip,omitempty"`
+	Details       map[string]any `json:"details,omitempty"`
+	// Sequence numbers events in the order they were stored in the outbox,
+	// so consumers can resume from the last one they saw. Zero without one.
+	Sequence int `json:"sequence,omitempty"`
+}
+
+// Sink delivers events to one destination.
+type Sink interface {
+	Send(e Event) error
+}
+
+// Outbox stores events before they are delivered, so consumers that missed
+// a delivery can replay them. Append returns the event's sequence number.
+type Outbox interface {
+	Append(e Event) (int, error)
+}
+
+var (
+	mu      sync.RWMutex
+	sinks   []Sink
+	enabled = map[string]bool{}
+	outbox  Outbox
+)
+
+// Configure replaces the sinks and the set of enabled event types. Types
+// missing from the map are not published.
+func Configure(s []Sink, enabledTypes map[string]bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	sinks = s
+	enabled = enabledTypes
+}
+
+// SetOutbox stores every published event in o before delivering it; nil
+// stops storing them.
+func SetOutbox(o Outbox) {
+	mu.Lock()
+	defer mu.Unlock()
+	outbox = o
+}
+
+// Publish stamps the event, stores it in the outbox and hands it to every
+// sink in the background, so a slow endpoint never holds up the request that
+// raised it.
+func Publish(e Event) {
+	mu.RLock()
+	active := sinks
+	on := enabled[e.Type]
+	store := outbox
+	mu.RUnlock()
+	if !on || (len(active) == 0 && store == nil) {
+		return
+	}
+
+	e.SchemaVersion = SchemaVersion
+	e.ID = newEventID()
+	if e.OccurredAt.IsZero() {
+		e.OccurredAt = time.Now().UTC()
+	}
+	if store != nil {
+		seq, err := store.Append(e)
+		if err != nil {
+			// Still deliver it; only replay will miss it.
+			log.Printf("Failed to store security event %s (%s): %v", e.ID, e.Type, err)
+		}
+		e.Sequence = seq
+	}
+	for _, s := range active {
+		go func(s Sink) {
+			if err := s.Send(e); err != nil {
+				log.Printf("Failed to deliver security event %s (%s): %v", e.ID, e.Type, err)
+			}
+		}(s)
+	}
+}
+
+func newEventID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
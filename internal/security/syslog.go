@@ -0,0 +1,232 @@
+package security
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	FormatRFC5424 = "rfc5424"
+	FormatCEF     = "cef"
+
+	syslogAppName = "inventory-tracker"
+)
+
+// Syslog severities, RFC 5424 section 6.2.1.
+const (
+	syslogEmergency = iota
+	syslogAlert
+	syslogCritical
+	syslogError
+	syslogWarning
+	syslogNotice
+	syslogInformational
+	syslogDebug
+)
+
+var syslogFacilities = map[string]int{
+	"kern": 0, "user": 1, "mail": 2, "daemon": 3, "auth": 4, "syslog": 5, "lpr": 6, "news": 7,
+	"uucp": 8, "cron": 9, "authpriv": 10, "ftp": 11,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19, "local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+var syslogSeverities = map[string]int{
+	"emergency": syslogEmergency, "alert": syslogAlert, "critical": syslogCritical, "error": syslogError,
+	"warning": syslogWarning, "notice": syslogNotice, "informational": syslogInformational, "debug": syslogDebug,
+}
+
+// cefSeverities maps event severities onto CEF's 0-10 scale.
+var cefSeverities = map[string]int{SeverityInfo: 3, SeverityWarning: 6, SeverityCritical: 9}
+
+// SyslogConfig describes where and how to ship events over syslog.
+type SyslogConfig struct {
+	Network  string // udp, tcp or tls
+	Addr     string // host:port
+	Format   string // rfc5424 or cef
+	Facility string // e.g. authpriv or local0
+	// SeverityMap overrides the syslog severity of event severities, as
+	// comma-separated pairs such as "info=notice,critical=alert".
+	SeverityMap string
+	CAFile      string // PEM bundle to verify the collector; system roots when empty
+	// InsecureSkipVerify disables certificate checks, for test collectors only.
+	InsecureSkipVerify bool
+}
+
+// SyslogSink writes events to a syslog collector. Stream connections are
+// framed with octet counting (RFC 6587) and re-dialled after a failed write.
+type SyslogSink struct {
+	cfg        SyslogConfig
+	facility   int
+	severities map[string]int
+	tlsConfig  *tls.Config
+	hostname   string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+func NewSyslogSink(cfg SyslogConfig) (*SyslogSink, error) {
+	if cfg.Network == "" {
+		cfg.Network = "udp"
+	}
+	if cfg.Format == "" {
+		cfg.Format = FormatRFC5424
+	}
+	if cfg.Facility == "" {
+		cfg.Facility = "authpriv"
+	}
+	if cfg.Network != "udp" && cfg.Network != "tcp" && cfg.Network != "tls" {
+		return nil, fmt.Errorf("unknown syslog network %q", cfg.Network)
+	}
+	if cfg.Format != FormatRFC5424 && cfg.Format != FormatCEF {
+		return nil, fmt.Errorf("unknown syslog format %q", cfg.Format)
+	}
+	facility, ok := syslogFacilities[strings.ToLower(cfg.Facility)]
+	if !ok {
+		return nil, fmt.Errorf("unknown syslog facility %q", cfg.Facility)
+	}
+	severities, err := parseSeverityMap(cfg.SeverityMap)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &SyslogSink{cfg: cfg, facility: facility, severities: severities}
+	if s.hostname, err = os.Hostname(); err != nil {
+		s.hostname = "-"
+	}
+	if cfg.Network == "tls" {
+		host, _, err := net.SplitHostPort(cfg.Addr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid syslog address %q: %w", cfg.Addr, err)
+		}
+		s.tlsConfig = &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12, InsecureSkipVerify: cfg.InsecureSkipVerify}
+		if cfg.CAFile != "" {
+			pem, err := os.ReadFile(cfg.CAFile)
+			if err != nil {
+				return nil, fmt.Errorf("reading syslog CA file: %w", err)
+			}
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("no certificates found in %s", cfg.CAFile)
+			}
+			s.tlsConfig.RootCAs = pool
+		}
+	}
+	return s, nil
+}
+
+func parseSeverityMap(spec string) (map[string]int, error) {
+	severities := map[string]int{
+		SeverityInfo:     syslogInformational,
+		SeverityWarning:  syslogWarning,
+		SeverityCritical: syslogCritical,
+	}
+	for _, pair := range strings.Split(spec, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		from, to, ok := strings.Cut(pair, "=")
+		level, known := syslogSeverities[strings.ToLower(strings.TrimSpace(to))]
+		if !ok || !known {
+			return nil, fmt.Errorf("invalid syslog severity mapping %q", pair)
+		}
+		severities[strings.ToLower(strings.TrimSpace(from))] = level
+	}
+	return severities, nil
+}
+
+func (s *SyslogSink) Send(e Event) error {
+	msg, err := s.format(e)
+	if err != nil {
+		return err
+	}
+	if s.cfg.Network != "udp" {
+		msg = strconv.Itoa(len(msg)) + " " + msg
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for attempt := 0; ; attempt++ {
+		if s.conn == nil {
+			if s.conn, err = s.dial(); err != nil {
+				return err
+			}
+		}
+		_ = s.conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
+		if _, err = s.conn.Write([]byte(msg)); err == nil || attempt == 1 {
+			return err
+		}
+		s.conn.Close()
+		s.conn = nil
+	}
+}
+
+func (s *SyslogSink) dial() (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+	if s.cfg.Network == "tls" {
+		return tls.DialWithDialer(dialer, "tcp", s.cfg.Addr, s.tlsConfig)
+	}
+	return dialer.Dial(s.cfg.Network, s.cfg.Addr)
+}
+
+// format renders an RFC 5424 record whose message is either the event as JSON
+// or, in CEF mode, a CEF line.
+func (s *SyslogSink) format(e Event) (string, error) {
+	severity, ok := s.severities[e.Severity]
+	if !ok {
+		severity = syslogNotice
+	}
+
+	var msg string
+	if s.cfg.Format == FormatCEF {
+		msg = formatCEF(e)
+	} else {
+		raw, err := json.Marshal(e)
+		if err != nil {
+			return "", err
+		}
+		msg = string(raw)
+	}
+	return fmt.Sprintf("<%d>1 %s %s %s %d %s - %s",
+		s.facility*8+severity, e.OccurredAt.UTC().Format(time.RFC3339Nano), s.hostname, syslogAppName, os.Getpid(), e.Type, msg), nil
+}
+
+func formatCEF(e Event) string {
+	name := strings.ReplaceAll(e.Type, "_", " ")
+	ext := []string{
+		"rt=" + strconv.FormatInt(e.OccurredAt.UnixMilli(), 10),
+		"externalId=" + cefValue(e.ID),
+	}
+	if e.Actor != "" {
+		ext = append(ext, "suser="+cefValue(e.Actor))
+	}
+	if e.Target != "" {
+		ext = append(ext, "duser="+cefValue(e.Target))
+	}
+	if e.SourceIP != "" {
+		ext = append(ext, "src="+cefValue(e.SourceIP))
+	}
+	if len(e.Details) > 0 {
+		if raw, err := json.Marshal(e.Details); err == nil {
+			ext = append(ext, "msg="+cefValue(string(raw)))
+		}
+	}
+	return fmt.Sprintf("CEF:0|%s|%s|1.0|%s|%s|%d|%s",
+		cefHeader("Inventory Tracker"), cefHeader(syslogAppName), cefHeader(e.Type), cefHeader(name), cefSeverities[e.Severity], strings.Join(ext, " "))
+}
+
+var (
+	cefHeaderEscaper = strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\n", " ", "\r", " ")
+	cefValueEscaper  = strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\n", `\n`, "\r", `\r`)
+)
+
+func cefHeader(s string) string { return cefHeaderEscaper.Replace(s) }
+func cefValue(s string) string  { return cefValueEscaper.Replace(s) }
@@ -0,0 +1,74 @@
+package security
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/jobs"
+)
+
+const webhookAttempts = 3
+
+// WebhookJob names webhook deliveries when they fail for good.
+const WebhookJob = "security_webhook"
+
+// WebhookSink POSTs each event as JSON. When a secret is set, the body is
+// signed with HMAC-SHA256 in the X-Signature-256 header so receivers can
+// check it came from us.
+type WebhookSink struct {
+	URL    string
+	Secret string
+	Client *http.Client
+}
+
+func NewWebhookSink(url, secret string) *WebhookSink {
+	return &WebhookSink{URL: url, Secret: secret, Client: &http.Client{Timeout: 5 * time.Second}}
+}
+
+func (s *WebhookSink) Send(e Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	for attempt := 1; ; attempt++ {
+		err = s.post(body)
+		if err == nil {
+			return nil
+		}
+		if attempt == webhookAttempts {
+			jobs.Exhausted(jobs.Failure{Job: WebhookJob, Reference: fmt.Sprintf("security event %s (%s)", e.ID, e.Type), Attempts: attempt, Err: err})
+			return err
+		}
+		time.Sleep(time.Duration(attempt) * time.Second)
+	}
+}
+
+func (s *WebhookSink) post(body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(s.Secret))
+		mac.Write(body)
+		req.Header.Set("X-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook %s answered %s", s.URL, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+// Package seed generates fake but realistic-looking inventory data for demos
+// and load tests. Nothing it produces is derived from real customers.
+package seed
+
+import (
+	crand "crypto/rand"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const maxNameAttempts = 5
+
+// Options controls how much data is generated.
+type Options struct {
+	Products            int   `json:"products"`
+	Users               int   `json:"users"`
+	Months              int   `json:"months"`
+	MovementsPerProduct int   `json:"movements_per_product"`
+	RandomSeed          int64 `json:"random_seed"`
+}
+
+// Result summarizes what was generated.
+type Result struct {
+	Products  int `json:"products"`
+	Users     int `json:"users"`
+	Movements int `json:"movements"`
+	// Password is shared by the users of this run. It is random, so a
+	// dataset seeded by mistake does not open known accounts, and is only
+	// reported here.
+	Password string `json:"password,omitempty"`
+}
+
+// Limits bound a single run so an oversized request cannot stall the API.
+var Limits = Options{Products: 1000, Users: 100, Months: 36, MovementsPerProduct: 500}
+
+// DefaultOptions is a small but useful demo dataset.
+var DefaultOptions = Options{Products: 50, Users: 5, Months: 6, MovementsPerProduct: 20}
+
+var ErrInvalidOptions = errors.New("invalid seed options")
+
+var (
+	categories = []string{"Hardware", "Electrical", "Plumbing", "Garden", "Office", "Kitchen", "Safety", "Paint"}
+	adjectives = []string{"Steel", "Compact", "Heavy-Duty", "Galvanized", "Portable", "Premium", "Basic", "Cordless", "Ergonomic", "Stainless"}
+	nouns      = []string{"Bolt", "Hinge", "Drill", "Cable", "Valve", "Hose", "Stapler", "Kettle", "Glove", "Brush", "Ladder", "Clamp"}
+	firstNames = []string{"alex", "sam", "jordan", "casey", "riley", "taylor", "morgan", "jamie", "quinn", "avery"}
+	roles      = []string{"user", "user", "warehouse"}
+)
+
+// Validate applies defaults to zero values and rejects values beyond Limits.
+func (o *Options) Validate() error {
+	if o.Products == 0 {
+		o.Products = DefaultOptions.Products
+	}
+	if o.Months == 0 {
+		o.Months = DefaultOptions.Months
+	}
+	if o.MovementsPerProduct == 0 {
+		o.MovementsPerProduct = DefaultOptions.MovementsPerProduct
+	}
+	if o.RandomSeed == 0 {
+		o.RandomSeed = time.Now().UnixNano()
+	}
+
+	if o.Products < 0 || o.Products > Limits.Products {
+		return fmt.Errorf("%w: products must be between 0 and %d", ErrInvalidOptions, Limits.Products)
+	}
+	if o.Users < 0 || o.Users > Limits.Users {
+		return fmt.Errorf("%w: users must be between 0 and %d", ErrInvalidOptions, Limits.Users)
+	}
+	if o.Months < 1 || o.Months > Limits.Months {
+		return fmt.Errorf("%w: months must be between 1 and %d", ErrInvalidOptions, Limits.Months)
+	}
+	if o.MovementsPerProduct < 0 || o.MovementsPerProduct > Limits.MovementsPerProduct {
+		return fmt.Errorf("%w: movements_per_product must be between 0 and %d", ErrInvalidOptions, Limits.MovementsPerProduct)
+	}
+	return nil
+}
+
+// Generator writes generated data through the regular repositories, so seeded
+// stock reconciles against its movement history like real data does.
+type Generator struct {
+	Products  repo.ProductRepository
+	Movements repo.MovementRepository
+	Users     repo.UserRepository
+}
+
+// Run generates a dataset. Options must have been validated.
+func (g Generator) Run(opts Options) (Result, error) {
+	rng := rand.New(rand.NewSource(opts.RandomSeed))
+	var res Result
+
+	password := crand.Text()
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		return res, err
+	}
+	for i := 0; i < opts.Users; i++ {
+		user := models.User{PasswordHash: string(hash), Role: roles[rng.Intn(len(roles))]}
+		for attempt := 0; attempt < maxNameAttempts; attempt++ {
+			user.Username = fmt.Sprintf("%s.%04d", pick(rng, firstNames), rng.Intn(10000))
+			if _, err = g.Users.CreateUser(user); err == nil {
+				res.Users++
+				break
+			}
+		}
+	}
+	if res.Users > 0 {
+		res.Password = password
+	}
+
+	now := time.Now().UTC()
+	start := now.AddDate(0, -opts.Months, 0)
+	for i := 0; i < opts.Products; i++ {
+		product, ok := g.createProduct(rng)
+		if !ok {
+			continue
+		}
+		res.Products++
+
+		for _, at := range movementTimes(rng, start, now, opts.MovementsPerProduct) {
+			delta := rng.Intn(40) + 1
+			// Outbound stock is roughly as common as restocks, but never
+			// pushes the quantity below zero.
+			if rng.Intn(2) == 0 && product.Quantity >= delta {
+				delta = -delta
+			}
+			product, err = g.Products.AdjustQuantity(product.ID, delta)
+			if err != nil {
+				return res, err
+			}
+			if _, err := g.Movements.Log(models.Movement{ProductID: product.ID, Delta: delta, CreatedAt: at.Format(time.RFC3339)}); err != nil {
+				return res, err
+			}
+			res.Movements++
+		}
+	}
+
+	return res, nil
+}
+
+func (g Generator) createProduct(rng *rand.Rand) (models.Product, bool) {
+	for attempt := 0; attempt < maxNameAttempts; attempt++ {
+		p := models.Product{
+			Name:      fmt.Sprintf("%s %s %s %04d", pick(rng, categories), pick(rng, adjectives), pick(rng, nouns), rng.Intn(10000)),
+			Price:     float64(rng.Intn(50000)+99) / 100,
+			Quantity:  rng.Intn(200),
+			Threshold: rng.Intn(20) + 5,
+		}
+		if created, err := g.Products.Create(p); err == nil {
+			return created, true
+		}
+	}
+	return models.Product{}, false
+}
+
+// movementTimes returns n ascending timestamps between start and end.
+func movementTimes(rng *rand.Rand, start, end time.Time, n int) []time.Time {
+	span := end.Sub(start)
+	times := make([]time.Time, n)
+	for i := range times {
+		times[i] = start.Add(time.Duration(rng.Int63n(int64(span)))).Truncate(time.Second)
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+	return times
+}
+
+func pick(rng *rand.Rand, values []string) string {
+	return values[rng.Intn(len(values))]
+}
@@ -0,0 +1,65 @@
+// Package snapshot takes a daily inventory snapshot and thins out old ones,
+// so stock history can be charted without replaying every movement.
+package snapshot
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/jobs"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+)
+
+// Retention decides how long scheduled snapshots are kept: every snapshot for
+// Days days, then the first of each month for Months months. Days of zero
+// disables pruning altogether.
+type Retention struct {
+	Days   int
+	Months int
+}
+
+// StartDailyScheduler captures a snapshot every night at midnight local time,
+// recording stock as it stood at the end of the day, then applies retention.
+func StartDailyScheduler(snapshots repo.SnapshotRepository, retention Retention) {
+	jobs.Register("daily_snapshot", "daily at 00:00")
+	for {
+		now := time.Now()
+		next := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).AddDate(0, 0, 1)
+		time.Sleep(time.Until(next))
+		day := next.AddDate(0, 0, -1)
+		err := jobs.Retry("daily_snapshot", "snapshot of "+day.Format(time.DateOnly), func() error {
+			return RunDaily(snapshots, retention, day)
+		})
+		if err != nil {
+			log.Printf("⚠️ Daily snapshot failed: %v", err)
+		}
+		jobs.Record("daily_snapshot", err)
+	}
+}
+
+// RunDaily captures the snapshot for day and prunes expired ones.
+func RunDaily(snapshots repo.SnapshotRepository, retention Retention, day time.Time) error {
+	s, err := snapshots.Capture("daily "+day.Format(time.DateOnly), "system", true)
+	if err != nil {
+		return fmt.Errorf("capture snapshot: %w", err)
+	}
+	log.Printf("📸 Daily snapshot %d captured (%d products)", s.ID, s.ProductCount)
+
+	if retention.Days <= 0 {
+		return nil
+	}
+	keepDailyAfter := s.CreatedAt.AddDate(0, 0, -retention.Days)
+	keepMonthlyAfter := keepDailyAfter
+	if retention.Months > 0 {
+		keepMonthlyAfter = s.CreatedAt.AddDate(0, -retention.Months, 0)
+	}
+	pruned, err := snapshots.Prune(keepDailyAfter, keepMonthlyAfter)
+	if err != nil {
+		return fmt.Errorf("prune snapshots: %w", err)
+	}
+	if pruned > 0 {
+		log.Printf("🧹 Pruned %d expired snapshots", pruned)
+	}
+	return nil
+}
@@ -0,0 +1,277 @@
+package storage
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const gcsScope = "https://www.googleapis.com/auth/devstorage.read_write"
+
+// GCSConfig points a GCSStore at a Google Cloud Storage bucket.
+type GCSConfig struct {
+	Bucket string
+	Prefix string // prepended to every key, e.g. "inventory/prod"
+	// CredentialsFile is a service account key in JSON, as downloaded from
+	// the Google Cloud console. The account needs to read, create and delete
+	// objects of the bucket.
+	CredentialsFile string
+	// Endpoint defaults to https://storage.googleapis.com; set it to reach
+	// an emulator.
+	Endpoint string
+}
+
+// GCSStore keeps files as objects of a Google Cloud Storage bucket, through
+// the JSON API with OAuth tokens of a service account.
+type GCSStore struct {
+	cfg         GCSConfig
+	clientEmail string
+	privateKey  *rsa.PrivateKey
+	tokenURI    string
+	client      *http.Client
+	now         func() time.Time
+
+	mu          sync.Mutex
+	accessToken string
+	tokenExpiry time.Time
+}
+
+var (
+	_ Store     = (*GCSStore)(nil)
+	_ Presigner = (*GCSStore)(nil)
+)
+
+func NewGCSStore(cfg GCSConfig) (*GCSStore, error) {
+	if cfg.Bucket == "" || cfg.CredentialsFile == "" {
+		return nil, errors.New("gcs storage needs a bucket and a credentials file")
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = "https://storage.googleapis.com"
+	}
+	cfg.Endpoint = strings.TrimSuffix(cfg.Endpoint, "/")
+
+	raw, err := os.ReadFile(cfg.CredentialsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read gcs credentials: %w", err)
+	}
+	var account struct {
+		ClientEmail string `json:"client_email"`
+		PrivateKey  string `json:"private_key"`
+		TokenURI    string `json:"token_uri"`
+	}
+	if err := json.Unmarshal(raw, &account); err != nil || account.ClientEmail == "" {
+		return nil, errors.New("gcs credentials are not a service account key")
+	}
+	key, err := parseRSAPrivateKey(account.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	if account.TokenURI == "" {
+		account.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+	return &GCSStore{
+		cfg:         cfg,
+		clientEmail: account.ClientEmail,
+		privateKey:  key,
+		tokenURI:    account.TokenURI,
+		client:      &http.Client{Timeout: time.Minute},
+		now:         time.Now,
+	}, nil
+}
+
+func (s *GCSStore) Put(key string, r io.Reader) (int64, error) {
+	name, err := objectName(s.cfg.Prefix, key)
+	if err != nil {
+		return 0, err
+	}
+	body, size, cleanup, err := sizedBody(r)
+	if err != nil {
+		return 0, err
+	}
+	defer cleanup()
+	target := s.cfg.Endpoint + "/upload/storage/v1/b/" + url.PathEscape(s.cfg.Bucket) + "/o?uploadType=media&name=" + url.QueryEscape(name)
+	resp, err := s.do(http.MethodPost, target, body, size)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if err := responseError(resp); err != nil {
+		return 0, err
+	}
+	return size, nil
+}
+
+func (s *GCSStore) Get(key string) (io.ReadCloser, error) {
+	target, err := s.objectURL(key)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.do(http.MethodGet, target+"?alt=media", nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	if err := responseError(resp); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func (s *GCSStore) Delete(key string) error {
+	target, err := s.objectURL(key)
+	if err != nil {
+		return err
+	}
+	resp, err := s.do(http.MethodDelete, target, nil, 0)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return responseError(resp)
+}
+
+// PresignGet returns a V4 signed URL that downloads the file without
+// credentials until expiry, at most seven days away.
+func (s *GCSStore) PresignGet(key, filename string, expiry time.Duration) (string, error) {
+	if expiry <= 0 || expiry > maxPresignExpiry {
+		return "", fmt.Errorf("presigned URLs must expire within %s", maxPresignExpiry)
+	}
+	name, err := objectName(s.cfg.Prefix, key)
+	if err != nil {
+		return "", err
+	}
+	endpoint, err := url.Parse(s.cfg.Endpoint)
+	if err != nil {
+		return "", err
+	}
+
+	googDate := s.now().UTC().Format("20060102T150405Z")
+	scope := googDate[:8] + "/auto/storage/goog4_request"
+	escapedPath := endpoint.EscapedPath() + "/" + uriEncode(s.cfg.Bucket, true) + "/" + uriEncode(name, false)
+	query := url.Values{
+		"X-Goog-Algorithm":     {"GOOG4-RSA-SHA256"},
+		"X-Goog-Credential":    {s.clientEmail + "/" + scope},
+		"X-Goog-Date":          {googDate},
+		"X-Goog-Expires":       {strconv.Itoa(int(expiry.Seconds()))},
+		"X-Goog-SignedHeaders": {"host"},
+	}
+	setDownloadName(query, filename)
+	canonical, _ := canonicalRequest(http.MethodGet, escapedPath, query, map[string]string{"host": endpoint.Host}, unsignedPayload)
+	digest := sha256.Sum256([]byte("GOOG4-RSA-SHA256\n" + googDate + "\n" + scope + "\n" + sha256Hex([]byte(canonical))))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, s.privateKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	query.Set("X-Goog-Signature", hex.EncodeToString(signature))
+	return endpoint.Scheme + "://" + endpoint.Host + escapedPath + "?" + canonicalQuery(query), nil
+}
+
+func (s *GCSStore) objectURL(key string) (string, error) {
+	name, err := objectName(s.cfg.Prefix, key)
+	if err != nil {
+		return "", err
+	}
+	return s.cfg.Endpoint + "/storage/v1/b/" + url.PathEscape(s.cfg.Bucket) + "/o/" + url.PathEscape(name), nil
+}
+
+// do sends an authorized request to target, streaming body, of the given
+// size, if any.
+func (s *GCSStore) do(method, target string, body io.Reader, size int64) (*http.Response, error) {
+	token, err := s.token()
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(method, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if body != nil {
+		setBody(req, body, size)
+		req.Header.Set("Content-Type", "application/octet-stream")
+	}
+	return s.client.Do(req)
+}
+
+// token returns an OAuth access token of the service account, exchanging a
+// signed assertion for a new one when the cached token is about to expire.
+func (s *GCSStore) token() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if s.accessToken != "" && now.Add(time.Minute).Before(s.tokenExpiry) {
+		return s.accessToken, nil
+	}
+
+	assertion, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   s.clientEmail,
+		"scope": gcsScope,
+		"aud":   s.tokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}).SignedString(s.privateKey)
+	if err != nil {
+		return "", err
+	}
+	resp, err := s.client.PostForm(s.tokenURI, url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get a gcs access token: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", fmt.Errorf("gcs token endpoint answered %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+
+	var granted struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&granted); err != nil || granted.AccessToken == "" {
+		return "", errors.New("gcs token endpoint sent no access token")
+	}
+	s.accessToken = granted.AccessToken
+	s.tokenExpiry = now.Add(time.Duration(granted.ExpiresIn) * time.Second)
+	return s.accessToken, nil
+}
+
+func parseRSAPrivateKey(pemKey string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(pemKey))
+	if block == nil {
+		return nil, errors.New("gcs credentials hold no PEM private key")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid gcs private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("gcs private key is not an RSA key")
+	}
+	return key, nil
+}
@@ -0,0 +1,212 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxPresignExpiry is the longest a presigned URL may stay valid, a limit
+// both S3 and GCS enforce.
+const maxPresignExpiry = 7 * 24 * time.Hour
+
+// S3Config points an S3Store at a bucket of Amazon S3 or of a compatible
+// service such as MinIO, Ceph or Cloudflare R2.
+type S3Config struct {
+	// Endpoint defaults to Amazon's endpoint for Region.
+	Endpoint        string
+	Region          string // default us-east-1
+	Bucket          string
+	Prefix          string // prepended to every key, e.g. "inventory/prod"
+	AccessKeyID     string
+	SecretAccessKey string
+	// PathStyle puts the bucket in the URL path instead of the host name,
+	// as most self-hosted services need.
+	PathStyle bool
+}
+
+// S3Store keeps files as objects of an S3-compatible bucket, signing
+// requests with AWS Signature Version 4.
+type S3Store struct {
+	cfg      S3Config
+	endpoint *url.URL
+	client   *http.Client
+	now      func() time.Time
+}
+
+var (
+	_ Store     = (*S3Store)(nil)
+	_ Presigner = (*S3Store)(nil)
+)
+
+func NewS3Store(cfg S3Config) (*S3Store, error) {
+	if cfg.Bucket == "" || cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+		return nil, errors.New("s3 storage needs a bucket, an access key ID and a secret access key")
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = "https://s3." + cfg.Region + ".amazonaws.com"
+	}
+	endpoint, err := url.Parse(strings.TrimSuffix(cfg.Endpoint, "/"))
+	if err != nil || endpoint.Host == "" || (endpoint.Scheme != "https" && endpoint.Scheme != "http") {
+		return nil, fmt.Errorf("invalid s3 endpoint %q", cfg.Endpoint)
+	}
+	return &S3Store{cfg: cfg, endpoint: endpoint, client: &http.Client{Timeout: time.Minute}, now: time.Now}, nil
+}
+
+func (s *S3Store) Put(key string, r io.Reader) (int64, error) {
+	body, size, cleanup, err := sizedBody(r)
+	if err != nil {
+		return 0, err
+	}
+	defer cleanup()
+	resp, err := s.do(http.MethodPut, key, body, size)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if err := responseError(resp); err != nil {
+		return 0, err
+	}
+	return size, nil
+}
+
+func (s *S3Store) Get(key string) (io.ReadCloser, error) {
+	resp, err := s.do(http.MethodGet, key, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	if err := responseError(resp); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func (s *S3Store) Delete(key string) error {
+	// Deleting a missing object succeeds on S3, so look for it first.
+	resp, err := s.do(http.MethodHead, key, nil, 0)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if err := responseError(resp); err != nil {
+		return err
+	}
+
+	resp, err = s.do(http.MethodDelete, key, nil, 0)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return responseError(resp)
+}
+
+// PresignGet returns a URL that downloads the file without credentials
+// until expiry, at most seven days away.
+func (s *S3Store) PresignGet(key, filename string, expiry time.Duration) (string, error) {
+	if expiry <= 0 || expiry > maxPresignExpiry {
+		return "", fmt.Errorf("presigned URLs must expire within %s", maxPresignExpiry)
+	}
+	host, escapedPath, err := s.location(key)
+	if err != nil {
+		return "", err
+	}
+
+	amzDate, scope := s.scope()
+	query := url.Values{
+		"X-Amz-Algorithm":     {"AWS4-HMAC-SHA256"},
+		"X-Amz-Credential":    {s.cfg.AccessKeyID + "/" + scope},
+		"X-Amz-Date":          {amzDate},
+		"X-Amz-Expires":       {strconv.Itoa(int(expiry.Seconds()))},
+		"X-Amz-SignedHeaders": {"host"},
+	}
+	setDownloadName(query, filename)
+	canonical, _ := canonicalRequest(http.MethodGet, escapedPath, query, map[string]string{"host": host}, unsignedPayload)
+	query.Set("X-Amz-Signature", s.signature(amzDate, scope, canonical))
+	return s.endpoint.Scheme + "://" + host + escapedPath + "?" + canonicalQuery(query), nil
+}
+
+// setDownloadName asks the store to serve a presigned download as an
+// attachment named filename rather than under its key.
+func setDownloadName(query url.Values, filename string) {
+	if filename != "" {
+		query.Set("response-content-disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	}
+}
+
+// do sends a request for key, signed in the Authorization header. A body,
+// of the given size, streams unsigned.
+func (s *S3Store) do(method, key string, body io.Reader, size int64) (*http.Response, error) {
+	host, escapedPath, err := s.location(key)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(method, s.endpoint.Scheme+"://"+host+escapedPath, nil)
+	if err != nil {
+		return nil, err
+	}
+	payloadHash := sha256Hex(nil)
+	if body != nil {
+		setBody(req, body, size)
+		payloadHash = unsignedPayload
+	}
+
+	amzDate, scope := s.scope()
+	headers := map[string]string{"host": host, "x-amz-content-sha256": payloadHash, "x-amz-date": amzDate}
+	canonical, signedHeaders := canonicalRequest(method, escapedPath, nil, headers, payloadHash)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.cfg.AccessKeyID, scope, signedHeaders, s.signature(amzDate, scope, canonical)))
+	return s.client.Do(req)
+}
+
+// location returns the host and escaped path addressing key's object.
+func (s *S3Store) location(key string) (host, escapedPath string, err error) {
+	name, err := objectName(s.cfg.Prefix, key)
+	if err != nil {
+		return "", "", err
+	}
+	if s.cfg.PathStyle {
+		return s.endpoint.Host, s.endpoint.EscapedPath() + "/" + uriEncode(s.cfg.Bucket, true) + "/" + uriEncode(name, false), nil
+	}
+	return s.cfg.Bucket + "." + s.endpoint.Host, s.endpoint.EscapedPath() + "/" + uriEncode(name, false), nil
+}
+
+func (s *S3Store) scope() (amzDate, scope string) {
+	amzDate = s.now().UTC().Format("20060102T150405Z")
+	return amzDate, amzDate[:8] + "/" + s.cfg.Region + "/s3/aws4_request"
+}
+
+func (s *S3Store) signature(amzDate, scope, canonical string) string {
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonical))
+	key := []byte("AWS4" + s.cfg.SecretAccessKey)
+	for _, part := range strings.Split(scope, "/") {
+		key = hmacSHA256(key, part)
+	}
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+// responseError maps an unsuccessful response to ErrNotFound or an error
+// quoting the service's message.
+func responseError(resp *http.Response) error {
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusNotFound:
+		return ErrNotFound
+	}
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("object storage answered %s: %s", resp.Status, bytes.TrimSpace(msg))
+}
@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"net/url"
+	"testing"
+)
+
+// The AWS Signature Version 4 test suite signs with these credentials; see
+// https://docs.aws.amazon.com/IAM/latest/UserGuide/signature-v4-test-suite.html
+const (
+	suiteAccessKeyID = "AKIDEXAMPLE"
+	suiteSecretKey   = "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"
+	suiteDate        = "20150830T123600Z"
+	suiteScope       = "20150830/us-east-1/service/aws4_request"
+	emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+func TestSigV4TestSuite(t *testing.T) {
+	s := &S3Store{cfg: S3Config{SecretAccessKey: suiteSecretKey}}
+	cases := []struct {
+		name      string
+		method    string
+		path      string
+		query     url.Values
+		canonical string
+		signature string
+	}{
+		{
+			name:      "get-vanilla",
+			method:    "GET",
+			path:      "/",
+			canonical: "GET\n/\n\nhost:example.amazonaws.com\nx-amz-date:20150830T123600Z\n\nhost;x-amz-date\n" + emptyPayloadHash,
+			signature: "5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		{
+			name:      "get-vanilla-query-order-key-case",
+			method:    "GET",
+			path:      "/",
+			query:     url.Values{"Param2": {"value2"}, "Param1": {"value1"}},
+			canonical: "GET\n/\nParam1=value1&Param2=value2\nhost:example.amazonaws.com\nx-amz-date:20150830T123600Z\n\nhost;x-amz-date\n" + emptyPayloadHash,
+			signature: "b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500",
+		},
+		{
+			name:      "get-vanilla-query-order-value",
+			method:    "GET",
+			path:      "/",
+			query:     url.Values{"Param1": {"value2", "value1"}},
+			canonical: "GET\n/\nParam1=value1&Param1=value2\nhost:example.amazonaws.com\nx-amz-date:20150830T123600Z\n\nhost;x-amz-date\n" + emptyPayloadHash,
+			signature: "5772eed61e12b33fae39ee5e7012498b51d56abc0abb7c60486157bd471c4694",
+		},
+		{
+			name:      "get-vanilla-utf8-query",
+			method:    "GET",
+			path:      "/",
+			query:     url.Values{"ሴ": {"bar"}},
+			canonical: "GET\n/\n%E1%88%B4=bar\nhost:example.amazonaws.com\nx-amz-date:20150830T123600Z\n\nhost;x-amz-date\n" + emptyPayloadHash,
+			signature: "2cdec8eed098649ff3a119c94853b13c643bcf08f8b0a1d91e12c9027818dd04",
+		},
+		{
+			name:      "post-vanilla",
+			method:    "POST",
+			path:      "/",
+			canonical: "POST\n/\n\nhost:example.amazonaws.com\nx-amz-date:20150830T123600Z\n\nhost;x-amz-date\n" + emptyPayloadHash,
+			signature: "5da7c1a2acd57cee7505fc6676e4e544621c30862966e37dddb68e92efbe5d6b",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			headers := map[string]string{"host": "example.amazonaws.com", "x-amz-date": suiteDate}
+			canonical, signed := canonicalRequest(c.method, c.path, c.query, headers, emptyPayloadHash)
+			if canonical != c.canonical {
+				t.Errorf("canonical request:\n%s\nexpected:\n%s", canonical, c.canonical)
+			}
+			if signed != "host;x-amz-date" {
+				t.Errorf("expected signed headers host;x-amz-date, got %s", signed)
+			}
+			if got := s.signature(suiteDate, suiteScope, canonical); got != c.signature {
+				t.Errorf("expected signature %s, got %s", c.signature, got)
+			}
+		})
+	}
+}
+
+func TestURIEncode(t *testing.T) {
+	cases := []struct {
+		in          string
+		encodeSlash bool
+		want        string
+	}{
+		{"photos/2024/cat.jpg", false, "photos/2024/cat.jpg"},
+		{"photos/2024/cat.jpg", true, "photos%2F2024%2Fcat.jpg"},
+		{"test$file.text", false, "test%24file.text"},
+		{"a b+c~d", true, "a%20b%2Bc~d"},
+		{"é", true, "%C3%A9"},
+	}
+	for _, c := range cases {
+		if got := uriEncode(c.in, c.encodeSlash); got != c.want {
+			t.Errorf("uriEncode(%q, %v) = %q, expected %q", c.in, c.encodeSlash, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+// Package storage keeps uploaded files outside the database.
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+var ErrNotFound = errors.New("stored file not found")
+
+// Store saves and serves opaque blobs by key.
+type Store interface {
+	Put(key string, r io.Reader) (int64, error)
+	Get(key string) (io.ReadCloser, error)
+	Delete(key string) error
+}
+
+// Presigner is implemented by stores that can hand out a URL letting anyone
+// holding it download a file directly from the store until expiry. The
+// download is named filename, when given.
+type Presigner interface {
+	PresignGet(key, filename string, expiry time.Duration) (string, error)
+}
+
+// LocalStore keeps files under a directory on the local filesystem.
+type LocalStore struct {
+	dir string
+}
+
+var _ Store = (*LocalStore)(nil)
+
+// NewLocalStore creates the directory if needed.
+func NewLocalStore(dir string) (*LocalStore, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+	return &LocalStore{dir: dir}, nil
+}
+
+func (s *LocalStore) Put(key string, r io.Reader) (int64, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return 0, err
+	}
+
+	// Write to a temporary file first so a failed upload never leaves a
+	// truncated file under the final key.
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name())
+
+	n, err := io.Copy(tmp, r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, err
+	}
+	return n, os.Rename(tmp.Name(), path)
+}
+
+func (s *LocalStore) Get(key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+func (s *LocalStore) Delete(key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// path maps a key to a file, refusing keys that would escape the directory.
+func (s *LocalStore) path(key string) (string, error) {
+	clean, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(clean)), nil
+}
+
+// cleanKey normalizes a slash-separated key, refusing keys that would escape
+// the store's root.
+func cleanKey(key string) (string, error) {
+	clean := path.Clean(filepath.ToSlash(key))
+	if clean == "." || path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", fmt.Errorf("invalid storage key %q", key)
+	}
+	return clean, nil
+}
+
+// sizedBody returns the rest of r with its length, which S3 and GCS need
+// before the upload starts. Seekable readers, such as uploaded multipart
+// files, are measured in place; anything else is spooled to a temporary
+// file rather than held in memory. cleanup must be called once the body has
+// been sent.
+func sizedBody(r io.Reader) (body io.Reader, size int64, cleanup func(), err error) {
+	if rs, ok := r.(io.ReadSeeker); ok {
+		if start, err := rs.Seek(0, io.SeekCurrent); err == nil {
+			end, err := rs.Seek(0, io.SeekEnd)
+			if err != nil {
+				return nil, 0, nil, err
+			}
+			if _, err := rs.Seek(start, io.SeekStart); err != nil {
+				return nil, 0, nil, err
+			}
+			return rs, end - start, func() {}, nil
+		}
+	}
+
+	tmp, err := os.CreateTemp("", "upload-*")
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	cleanup = func() {
+		tmp.Close()
+		os.Remove(tmp.Name())
+	}
+	if size, err = io.Copy(tmp, r); err == nil {
+		_, err = tmp.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		cleanup()
+		return nil, 0, nil, err
+	}
+	return tmp, size, cleanup, nil
+}
+
+// setBody makes body, of the given size, the body of req. An empty body is
+// sent as such rather than chunked.
+func setBody(req *http.Request, body io.Reader, size int64) {
+	req.ContentLength = size
+	if size == 0 {
+		req.Body = http.NoBody
+		return
+	}
+	req.Body = io.NopCloser(body)
+}
+
+// objectName joins a bucket prefix and a key.
+func objectName(prefix, key string) (string, error) {
+	clean, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	if prefix = strings.Trim(prefix, "/"); prefix != "" {
+		return prefix + "/" + clean, nil
+	}
+	return clean, nil
+}
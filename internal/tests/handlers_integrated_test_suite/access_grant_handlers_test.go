@@ -0,0 +1,276 @@
+package handlers_integrated_test_suite
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+)
+
+func TestAccessGrants(t *testing.T) {
+	t.Cleanup(func() {
+		clearAccessGrants()
+		clearAllProducts()
+		clearWarehouses()
+		clearAllUsersExceptAdmin()
+	})
+	r := router.NewRouter()
+
+	send := func(method, path, bearer string, body any) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		if body != nil {
+			_ = json.NewEncoder(&buf).Encode(body)
+		}
+		req := httptest.NewRequest(method, path, &buf)
+		req.Header.Set("Authorization", "Bearer "+bearer)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	newProduct := func(name, category string) int {
+		w := createProduct(r, handlers.ProductRequest{Name: name, Price: 10, Quantity: 5, Category: category})
+		var p handlers.ProductResponse
+		if err := json.NewDecoder(w.Body).Decode(&p); err != nil {
+			t.Fatalf("failed to decode product: %v", err)
+		}
+		return p.Id
+	}
+	newWarehouse := func(name string) int {
+		w := send(http.MethodPost, "/warehouses", token, handlers.WarehouseRequest{Name: name})
+		var wh models.Warehouse
+		if err := json.NewDecoder(w.Body).Decode(&wh); err != nil {
+			t.Fatalf("failed to decode warehouse: %v", err)
+		}
+		return wh.ID
+	}
+	hammer := newProduct("Hammer", "Tools")
+	rake := newProduct("Rake", "Garden")
+	createProduct(r, handlers.ProductRequest{Name: "Chisel", Price: 10, Quantity: 5, Category: "Tools", Barcode: "4006381333931"})
+	north := newWarehouse("North")
+	south := newWarehouse("South")
+
+	managerToken, err := roleToken(r, "site-manager", "user")
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	var warehouseGrant models.AccessGrant
+	t.Run("Grant a category and a warehouse", func(t *testing.T) {
+		if w := send(http.MethodPost, "/admin/users/site-manager/grants", token, handlers.AccessGrantRequest{Category: "tools"}); w.Code != http.StatusCreated {
+			t.Fatalf("expected 201 Created, got %d: %s", w.Code, w.Body.String())
+		}
+		w := send(http.MethodPost, "/admin/users/site-manager/grants", token, handlers.AccessGrantRequest{WarehouseID: &north})
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected 201 Created, got %d: %s", w.Code, w.Body.String())
+		}
+		if err := json.NewDecoder(w.Body).Decode(&warehouseGrant); err != nil {
+			t.Fatalf("failed to decode grant: %v", err)
+		}
+
+		w = send(http.MethodGet, "/admin/users/site-manager/grants", token, nil)
+		var grants []models.AccessGrant
+		if err := json.NewDecoder(w.Body).Decode(&grants); err != nil {
+			t.Fatalf("failed to decode grants: %v", err)
+		}
+		if len(grants) != 2 || grants[0].Category != "tools" {
+			t.Errorf("expected the category grant first, got %+v", grants)
+		}
+	})
+
+	t.Run("Products outside the granted categories are hidden", func(t *testing.T) {
+		w := send(http.MethodGet, "/products/filter", managerToken, nil)
+		var result handlers.ProductsSearchResult
+		if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(result.Data) != 1 || result.Data[0].Id != hammer {
+			t.Errorf("expected only the hammer, got %+v", result.Data)
+		}
+		if w := send(http.MethodPost, fmt.Sprintf("/products/%d/adjust", rake), managerToken, handlers.QuantityAdjustmentRequest{Delta: 1, WarehouseID: &north}); w.Code != http.StatusNotFound {
+			t.Errorf("expected 404 Not Found, got %d", w.Code)
+		}
+		if w := send(http.MethodPost, "/products", managerToken, handlers.ProductRequest{Name: "Hose", Price: 5, Category: "Garden"}); w.Code != http.StatusForbidden {
+			t.Errorf("expected 403 Forbidden, got %d", w.Code)
+		}
+	})
+
+	t.Run("Stock changes only in granted warehouses", func(t *testing.T) {
+		w := send(http.MethodGet, "/warehouses", managerToken, nil)
+		var warehouses []models.Warehouse
+		if err := json.NewDecoder(w.Body).Decode(&warehouses); err != nil {
+			t.Fatalf("failed to decode warehouses: %v", err)
+		}
+		if len(warehouses) != 1 || warehouses[0].ID != north {
+			t.Errorf("expected only North, got %+v", warehouses)
+		}
+		if w := send(http.MethodGet, fmt.Sprintf("/warehouses/%d", south), managerToken, nil); w.Code != http.StatusNotFound {
+			t.Errorf("expected 404 Not Found, got %d", w.Code)
+		}
+
+		adjust := fmt.Sprintf("/products/%d/adjust", hammer)
+		if w := send(http.MethodPost, adjust, managerToken, handlers.QuantityAdjustmentRequest{Delta: 1}); w.Code != http.StatusForbidden {
+			t.Errorf("expected 403 Forbidden without a warehouse, got %d", w.Code)
+		}
+		if w := send(http.MethodPost, adjust, managerToken, handlers.QuantityAdjustmentRequest{Delta: 1, WarehouseID: &south}); w.Code != http.StatusForbidden {
+			t.Errorf("expected 403 Forbidden in South, got %d", w.Code)
+		}
+		if w := send(http.MethodPost, adjust, managerToken, handlers.QuantityAdjustmentRequest{Delta: 1, WarehouseID: &north}); w.Code != http.StatusOK {
+			t.Errorf("expected 200 OK in North, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("Every product and stock path applies the grants", func(t *testing.T) {
+		hidden := []struct {
+			method string
+			path   string
+			body   any
+		}{
+			{http.MethodGet, fmt.Sprintf("/products/%d", rake), nil},
+			{http.MethodGet, fmt.Sprintf("/products/%d/movements", rake), nil},
+			{http.MethodGet, fmt.Sprintf("/products/%d/movements/export", rake), nil},
+			{http.MethodGet, fmt.Sprintf("/products/%d/substitutes", rake), nil},
+			{http.MethodGet, fmt.Sprintf("/products/%d/bins", rake), nil},
+			{http.MethodGet, fmt.Sprintf("/products/%d/lots", rake), nil},
+			{http.MethodPost, fmt.Sprintf("/products/%d/lots", rake), handlers.LotRequest{LotNumber: "L1", Quantity: 1}},
+			{http.MethodPost, fmt.Sprintf("/products/%d/reservations", rake), handlers.ReservationRequest{Quantity: 1}},
+			{http.MethodPost, "/work-orders", handlers.WorkOrderRequest{ProductID: hammer, Quantity: 1, Components: []models.WorkOrderComponent{{ProductID: rake, QuantityPerUnit: 1}}}},
+		}
+		for _, c := range hidden {
+			if w := send(c.method, c.path, managerToken, c.body); w.Code != http.StatusNotFound {
+				t.Errorf("%s %s: expected 404 Not Found, got %d", c.method, c.path, w.Code)
+			}
+		}
+
+		// Changes that name no warehouse are refused to a user restricted
+		// to warehouses, even on a product of a granted category.
+		forbidden := []struct {
+			path string
+			body any
+		}{
+			{"/scan", handlers.ScanRequest{Barcode: "4006381333931", Delta: 1}},
+			{fmt.Sprintf("/products/%d/write-off", hammer), handlers.WriteOffRequest{Quantity: 1, Reason: "damaged"}},
+			{fmt.Sprintf("/products/%d/consignment/consume", hammer), handlers.ConsumeConsignmentRequest{Quantity: 1}},
+		}
+		for _, c := range forbidden {
+			if w := send(http.MethodPost, c.path, managerToken, c.body); w.Code != http.StatusForbidden {
+				t.Errorf("POST %s: expected 403 Forbidden, got %d", c.path, w.Code)
+			}
+		}
+
+		w := send(http.MethodPost, "/sync/batch", managerToken, handlers.SyncBatchRequest{Operations: []handlers.SyncOperationRequest{
+			{ClientID: "3f2b8c1e-0d4a-4c8e-9a51-6a0f5c2d7e10", ProductID: hammer, Delta: 1},
+		}})
+		var batch handlers.SyncBatchResponse
+		if err := json.NewDecoder(w.Body).Decode(&batch); err != nil {
+			t.Fatalf("failed to decode sync results: %v", err)
+		}
+		if len(batch.Results) != 1 || batch.Results[0].Reason != "outside_access_grants" {
+			t.Errorf("expected the operation rejected as outside the grants, got %+v", batch.Results)
+		}
+
+		w = send(http.MethodGet, "/products", managerToken, nil)
+		var products []handlers.ProductResponse
+		if err := json.NewDecoder(w.Body).Decode(&products); err != nil {
+			t.Fatalf("failed to decode products: %v", err)
+		}
+		for _, p := range products {
+			if p.Id == rake {
+				t.Errorf("expected the rake hidden from GET /products, got %+v", products)
+			}
+		}
+
+		w = send(http.MethodGet, "/products/suggest?q=ra", managerToken, nil)
+		var suggestions []repo.ProductSuggestion
+		if err := json.NewDecoder(w.Body).Decode(&suggestions); err != nil {
+			t.Fatalf("failed to decode suggestions: %v", err)
+		}
+		if len(suggestions) != 0 {
+			t.Errorf("expected no suggestions, got %+v", suggestions)
+		}
+
+		w = send(http.MethodGet, "/sync/changes", managerToken, nil)
+		var changes handlers.SyncChangesResponse
+		if err := json.NewDecoder(w.Body).Decode(&changes); err != nil {
+			t.Fatalf("failed to decode changes: %v", err)
+		}
+		for _, p := range changes.Products {
+			if p.Id == rake {
+				t.Errorf("expected the rake hidden from /sync/changes, got %+v", changes.Products)
+			}
+		}
+
+		w = send(http.MethodGet, "/products/changed", managerToken, nil)
+		var feed handlers.ProductChangesResponse
+		if err := json.NewDecoder(w.Body).Decode(&feed); err != nil {
+			t.Fatalf("failed to decode change feed: %v", err)
+		}
+		for _, c := range feed.Changes {
+			if c.ProductID == rake {
+				t.Errorf("expected the rake hidden from /products/changed, got %+v", feed.Changes)
+			}
+		}
+	})
+
+	t.Run("Anonymous callers see nothing once grants are in use", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/products", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		var products []handlers.ProductResponse
+		if err := json.NewDecoder(w.Body).Decode(&products); err != nil {
+			t.Fatalf("failed to decode products: %v", err)
+		}
+		if len(products) != 0 {
+			t.Errorf("expected no products, got %+v", products)
+		}
+
+		req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/products/%d", hammer), nil)
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusNotFound {
+			t.Errorf("expected 404 Not Found, got %d", w.Code)
+		}
+	})
+
+	t.Run("Removing the last warehouse grant lifts the restriction", func(t *testing.T) {
+		if w := send(http.MethodDelete, fmt.Sprintf("/admin/users/site-manager/grants/%d", warehouseGrant.ID), token, nil); w.Code != http.StatusNoContent {
+			t.Fatalf("expected 204 No Content, got %d", w.Code)
+		}
+		w := send(http.MethodGet, "/warehouses", managerToken, nil)
+		var warehouses []models.Warehouse
+		if err := json.NewDecoder(w.Body).Decode(&warehouses); err != nil {
+			t.Fatalf("failed to decode warehouses: %v", err)
+		}
+		if len(warehouses) != 2 {
+			t.Errorf("expected both warehouses, got %+v", warehouses)
+		}
+	})
+
+	missing := 999999
+	cases := []struct {
+		name string
+		path string
+		body handlers.AccessGrantRequest
+		code int
+	}{
+		{"Duplicate grant", "/admin/users/site-manager/grants", handlers.AccessGrantRequest{Category: "Tools"}, http.StatusConflict},
+		{"Both kinds", "/admin/users/site-manager/grants", handlers.AccessGrantRequest{Category: "tools", WarehouseID: &north}, http.StatusBadRequest},
+		{"Neither kind", "/admin/users/site-manager/grants", handlers.AccessGrantRequest{}, http.StatusBadRequest},
+		{"Unknown warehouse", "/admin/users/site-manager/grants", handlers.AccessGrantRequest{WarehouseID: &missing}, http.StatusNotFound},
+		{"Unknown user", "/admin/users/nobody-here/grants", handlers.AccessGrantRequest{Category: "tools"}, http.StatusNotFound},
+		{"Admins are unrestricted", "/admin/users/admin/grants", handlers.AccessGrantRequest{Category: "tools"}, http.StatusBadRequest},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if w := send(http.MethodPost, c.path, token, c.body); w.Code != c.code {
+				t.Errorf("expected %d, got %d: %s", c.code, w.Code, w.Body.String())
+			}
+		})
+	}
+}
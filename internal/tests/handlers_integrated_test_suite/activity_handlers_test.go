@@ -0,0 +1,195 @@
+package handlers_integrated_test_suite
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+func TestProductActivityHandler(t *testing.T) {
+	t.Cleanup(clearAuditLog)
+	t.Cleanup(clearAllProducts)
+	r := router.NewRouter()
+
+	w := createProduct(r, handlers.ProductRequest{Name: "Lantern", Price: 20, Quantity: 10, Threshold: 5})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201 Created, got %d: %s", w.Code, w.Body.String())
+	}
+	var product handlers.ProductResponse
+	if err := json.NewDecoder(w.Body).Decode(&product); err != nil {
+		t.Fatalf("failed to decode product: %v", err)
+	}
+
+	// Backdated movements sort before the product's audit entries.
+	now := time.Now().UTC()
+	addMovement(models.Movement{ProductID: product.Id, Delta: -3, CreatedAt: now.Add(-3 * time.Hour).Format(time.RFC3339)})
+	addMovement(models.Movement{ProductID: product.Id, Delta: -4, CreatedAt: now.Add(-2 * time.Hour).Format(time.RFC3339)})
+
+	body, _ := json.Marshal(handlers.ProductRequest{Name: "Lantern", Price: 25, Quantity: 10, Threshold: 5})
+	req := httptest.NewRequest(http.MethodPut, fmt.Sprintf("/products/%d", product.Id), bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+	}
+
+	get := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/products/%d/activity%s", product.Id, query), nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	decode := func(t *testing.T, w *httptest.ResponseRecorder) handlers.ActivityFeed {
+		t.Helper()
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		var feed handlers.ActivityFeed
+		if err := json.NewDecoder(w.Body).Decode(&feed); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return feed
+	}
+
+	t.Run("Feed interleaves activity newest first", func(t *testing.T) {
+		feed := decode(t, get(""))
+		var types []string
+		for _, item := range feed.Items {
+			types = append(types, item.Type)
+		}
+		want := []string{handlers.ActivityRevision, handlers.ActivityRevision, handlers.ActivityMovement, handlers.ActivityAlert, handlers.ActivityMovement}
+		if fmt.Sprint(types) != fmt.Sprint(want) {
+			t.Fatalf("expected %v, got %v", want, types)
+		}
+		update := feed.Items[0]
+		if update.Action != "update" || len(update.Changes) != 1 || update.Changes[0].Field != "price" {
+			t.Errorf("unexpected update revision: %+v", update)
+		}
+		if alert := feed.Items[3]; alert.Quantity == nil || *alert.Quantity != 3 {
+			t.Errorf("unexpected alert: %+v", alert)
+		}
+		if feed.NextCursor != "" {
+			t.Errorf("expected no next cursor, got %q", feed.NextCursor)
+		}
+	})
+
+	t.Run("Cursor pages through the feed", func(t *testing.T) {
+		var ids []string
+		cursor := ""
+		for range 5 {
+			feed := decode(t, get("?limit=2&cursor="+cursor))
+			for _, item := range feed.Items {
+				ids = append(ids, item.ID)
+			}
+			if cursor = feed.NextCursor; cursor == "" {
+				break
+			}
+		}
+		if len(ids) != 5 {
+			t.Errorf("expected 5 items across pages, got %v", ids)
+		}
+	})
+
+	cases := []struct {
+		name string
+		path string
+		code int
+	}{
+		{"Unknown product", "/products/999999/activity", http.StatusNotFound},
+		{"Invalid cursor", fmt.Sprintf("/products/%d/activity?cursor=bogus", product.Id), http.StatusBadRequest},
+		{"Invalid limit", fmt.Sprintf("/products/%d/activity?limit=0", product.Id), http.StatusBadRequest},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, c.path, nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != c.code {
+				t.Errorf("expected %d, got %d", c.code, w.Code)
+			}
+		})
+	}
+}
+
+func TestMeActivityHandler(t *testing.T) {
+	t.Cleanup(clearAuditLog)
+	t.Cleanup(clearAllUsersExceptAdmin)
+	t.Cleanup(clearAllProducts)
+	r := router.NewRouter()
+
+	clerkToken, err := roleToken(r, "night-clerk", "warehouse")
+	if err != nil {
+		t.Fatalf("failed to create warehouse user: %v", err)
+	}
+
+	w := createProduct(r, handlers.ProductRequest{Name: "Crate", Price: 5, Quantity: 10})
+	var product handlers.ProductResponse
+	if err := json.NewDecoder(w.Body).Decode(&product); err != nil {
+		t.Fatalf("failed to decode product: %v", err)
+	}
+
+	for _, delta := range []int{-2, 4} {
+		body, _ := json.Marshal(handlers.QuantityAdjustmentRequest{Delta: delta})
+		req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/products/%d/adjust", product.Id), bytes.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+clerkToken)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+	}
+
+	get := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/me/activity"+query, nil)
+		req.Header.Set("Authorization", "Bearer "+clerkToken)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Lists only the caller's actions, newest first", func(t *testing.T) {
+		w := get("")
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		var actions []handlers.UserAction
+		if err := json.NewDecoder(w.Body).Decode(&actions); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(actions) != 2 {
+			t.Fatalf("expected 2 actions, got %+v", actions)
+		}
+		var details struct{ Delta int }
+		_ = json.Unmarshal(actions[0].Details, &details)
+		if actions[0].Action != "adjust" || actions[0].EntityID != fmt.Sprint(product.Id) || details.Delta != 4 {
+			t.Errorf("unexpected latest action: %+v", actions[0])
+		}
+	})
+
+	t.Run("Limit", func(t *testing.T) {
+		var actions []handlers.UserAction
+		if err := json.NewDecoder(get("?limit=1").Body).Decode(&actions); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(actions) != 1 {
+			t.Errorf("expected 1 action, got %d", len(actions))
+		}
+	})
+
+	t.Run("Invalid since", func(t *testing.T) {
+		if w := get("?since=yesterday"); w.Code != http.StatusBadRequest {
+			t.Errorf("expected 400 Bad Request, got %d", w.Code)
+		}
+	})
+}
@@ -0,0 +1,164 @@
+package handlers_integrated_test_suite
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/auth"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+func TestAdjustmentLimits(t *testing.T) {
+	t.Cleanup(func() {
+		clearAdjustmentLimits()
+		clearAllProducts()
+		clearAllUsersExceptAdmin()
+		auth.SetRolePermissions(nil)
+	})
+	r := router.NewRouter()
+
+	send := func(method, path, bearer string, body any) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		if body != nil {
+			_ = json.NewEncoder(&buf).Encode(body)
+		}
+		req := httptest.NewRequest(method, path, &buf)
+		req.Header.Set("Authorization", "Bearer "+bearer)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	newProduct := func(name string) int {
+		w := createProduct(r, handlers.ProductRequest{Name: name, Price: 10, Quantity: 500})
+		var p handlers.ProductResponse
+		if err := json.NewDecoder(w.Body).Decode(&p); err != nil {
+			t.Fatalf("failed to decode product: %v", err)
+		}
+		return p.Id
+	}
+	bolts := newProduct("Bolts")
+	nuts := newProduct("Nuts")
+	clerkToken, err := roleToken(r, "clerk", "user")
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	var global models.AdjustmentLimit
+	t.Run("Set limits", func(t *testing.T) {
+		w := send(http.MethodPut, "/admin/adjustment-limits", token, handlers.AdjustmentLimitRequest{WarnDelta: 50, MaxDelta: 100})
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		if err := json.NewDecoder(w.Body).Decode(&global); err != nil {
+			t.Fatalf("failed to decode limit: %v", err)
+		}
+		w = send(http.MethodPut, "/admin/adjustment-limits", token, handlers.AdjustmentLimitRequest{ProductID: nuts, MaxDelta: 300})
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var limits []models.AdjustmentLimit
+		w = send(http.MethodGet, "/admin/adjustment-limits", token, nil)
+		if err := json.NewDecoder(w.Body).Decode(&limits); err != nil {
+			t.Fatalf("failed to decode limits: %v", err)
+		}
+		if len(limits) != 2 || limits[0].ProductID != 0 {
+			t.Errorf("expected the global limit first of 2, got %+v", limits)
+		}
+	})
+
+	adjust := func(bearer string, product, delta int) *httptest.ResponseRecorder {
+		return send(http.MethodPost, fmt.Sprintf("/products/%d/adjust", product), bearer, handlers.QuantityAdjustmentRequest{Delta: delta})
+	}
+
+	t.Run("Below the warning limit", func(t *testing.T) {
+		w := adjust(clerkToken, bolts, 10)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		var p handlers.ProductResponse
+		_ = json.NewDecoder(w.Body).Decode(&p)
+		if len(p.Warnings) != 0 {
+			t.Errorf("expected no warnings, got %v", p.Warnings)
+		}
+	})
+
+	t.Run("Over the warning limit", func(t *testing.T) {
+		w := adjust(clerkToken, bolts, -60)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		var p handlers.ProductResponse
+		_ = json.NewDecoder(w.Body).Decode(&p)
+		if len(p.Warnings) != 1 || p.Quantity != 450 {
+			t.Errorf("expected the adjustment applied with a warning, got %+v", p)
+		}
+	})
+
+	t.Run("Over the maximum", func(t *testing.T) {
+		if w := adjust(clerkToken, bolts, 150); w.Code != http.StatusUnprocessableEntity {
+			t.Errorf("expected 422, got %d: %s", w.Code, w.Body.String())
+		}
+		w := send(http.MethodPost, fmt.Sprintf("/products/%d/adjust/batch", bolts), clerkToken, []handlers.QuantityAdjustmentRequest{{Delta: 5}, {Delta: 150}})
+		if w.Code != http.StatusUnprocessableEntity {
+			t.Errorf("expected 422 for the batch, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("Product limit replaces the global one", func(t *testing.T) {
+		if w := adjust(clerkToken, nuts, 150); w.Code != http.StatusOK {
+			t.Errorf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("Override", func(t *testing.T) {
+		w := adjust(token, bolts, 150)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK for an admin, got %d: %s", w.Code, w.Body.String())
+		}
+		var p handlers.ProductResponse
+		_ = json.NewDecoder(w.Body).Decode(&p)
+		if len(p.Warnings) != 1 {
+			t.Errorf("expected the override to be flagged, got %v", p.Warnings)
+		}
+
+		auth.SetRolePermissions(map[string][]string{"user": {auth.PermPricingRead, auth.PermAdjustmentOverride}})
+		if w := adjust(clerkToken, bolts, -150); w.Code != http.StatusOK {
+			t.Errorf("expected 200 OK with the permission, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("Remove the global limit", func(t *testing.T) {
+		auth.SetRolePermissions(nil)
+		if w := send(http.MethodDelete, fmt.Sprintf("/admin/adjustment-limits/%d", global.ID), token, nil); w.Code != http.StatusNoContent {
+			t.Fatalf("expected 204 No Content, got %d: %s", w.Code, w.Body.String())
+		}
+		if w := adjust(clerkToken, bolts, 150); w.Code != http.StatusOK {
+			t.Errorf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	cases := []struct {
+		name string
+		body handlers.AdjustmentLimitRequest
+		code int
+	}{
+		{"No tier", handlers.AdjustmentLimitRequest{}, http.StatusBadRequest},
+		{"Negative", handlers.AdjustmentLimitRequest{WarnDelta: -1}, http.StatusBadRequest},
+		{"Warning above maximum", handlers.AdjustmentLimitRequest{WarnDelta: 10, MaxDelta: 5}, http.StatusBadRequest},
+		{"Unknown product", handlers.AdjustmentLimitRequest{ProductID: 999999, MaxDelta: 5}, http.StatusNotFound},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if w := send(http.MethodPut, "/admin/adjustment-limits", token, c.body); w.Code != c.code {
+				t.Errorf("expected %d, got %d: %s", c.code, w.Code, w.Body.String())
+			}
+		})
+	}
+}
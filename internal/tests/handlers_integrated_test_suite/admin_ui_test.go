@@ -0,0 +1,77 @@
+package handlers_integrated_test_suite
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+	"github.com/rogerio-castellano/inventory-tracker/internal/jobs"
+)
+
+func TestAdminUI(t *testing.T) {
+	t.Cleanup(clearAllProducts)
+	r := router.NewRouter()
+
+	get := func(path, bearer string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if bearer != "" {
+			req.Header.Set("Authorization", "Bearer "+bearer)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Pages are served without a token", func(t *testing.T) {
+		w := get("/admin/ui/", "")
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "<title>Inventory Tracker") {
+			t.Fatalf("expected the dashboard page, got %d: %.100s", w.Code, w.Body.String())
+		}
+		if w := get("/admin/ui/app.js", ""); w.Code != http.StatusOK {
+			t.Errorf("expected 200 OK for app.js, got %d", w.Code)
+		}
+	})
+
+	t.Run("Job statuses", func(t *testing.T) {
+		jobs.Register("test_job", "never")
+		if w := get("/admin/jobs", ""); w.Code != http.StatusUnauthorized {
+			t.Errorf("expected 401 without a token, got %d", w.Code)
+		}
+		w := get("/admin/jobs", token)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d", w.Code)
+		}
+		var statuses []jobs.Status
+		if err := json.NewDecoder(w.Body).Decode(&statuses); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		found := false
+		for _, s := range statuses {
+			found = found || s.Name == "test_job"
+		}
+		if !found {
+			t.Errorf("expected test_job in %+v", statuses)
+		}
+	})
+
+	t.Run("Low-stock filter", func(t *testing.T) {
+		createProduct(r, handlers.ProductRequest{Name: "Gloves", Price: 4, Quantity: 1, Threshold: 10})
+		createProduct(r, handlers.ProductRequest{Name: "Helmet", Price: 30, Quantity: 20, Threshold: 10})
+
+		w := get("/products/filter?low_stock=true", "")
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d", w.Code)
+		}
+		var result handlers.ProductsSearchResult
+		if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(result.Data) != 1 || result.Data[0].Name != "Gloves" {
+			t.Errorf("expected only Gloves, got %+v", result.Data)
+		}
+	})
+}
@@ -0,0 +1,186 @@
+package handlers_integrated_test_suite
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+const testManifest = `
+roles:
+  user:
+    permissions: [pricing:read]
+  guest:
+    permissions: [pricing:read]
+  warehouse:
+    permissions: [pricing:read]
+rate_limits:
+  admin: {tier: elevated, max_requests: 20, window_seconds: 60}
+  user: {tier: standard, max_requests: 10, window_seconds: 60}
+  guest: {tier: basic, max_requests: 3, window_seconds: 60}
+  warehouse: {tier: scanner, max_requests: 120, window_seconds: 60}
+validation_policy:
+  required_fields: [sku]
+`
+
+func TestApplyConfigHandler(t *testing.T) {
+	t.Cleanup(clearRuntimeConfigs)
+	t.Cleanup(clearWebhooks)
+	t.Cleanup(clearValidationPolicies)
+	t.Cleanup(clearAllUsersExceptAdmin)
+	r := router.NewRouter()
+
+	clerkToken, err := roleToken(r, "apply-clerk", "warehouse")
+	if err != nil {
+		t.Fatalf("failed to create warehouse user: %v", err)
+	}
+
+	apply := func(query, manifest string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/admin/apply"+query, strings.NewReader(manifest))
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/yaml")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	decode := func(t *testing.T, w *httptest.ResponseRecorder) handlers.ApplyResult {
+		t.Helper()
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		var result handlers.ApplyResult
+		if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return result
+	}
+	me := func(t *testing.T) handlers.MeResponse {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		req.Header.Set("Authorization", "Bearer "+clerkToken)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		var resp handlers.MeResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode /me: %v", err)
+		}
+		return resp
+	}
+	keys := func(changes []handlers.ConfigChange) []string {
+		var out []string
+		for _, c := range changes {
+			out = append(out, c.Section+"/"+c.Key+":"+c.Action)
+		}
+		return out
+	}
+	want := []string{"roles/guest:update", "roles/warehouse:update", "rate_limits/warehouse:add", "validation_policy/required_fields:update"}
+
+	t.Run("Dry run reports the diff without applying it", func(t *testing.T) {
+		result := decode(t, apply("?dry_run=true", testManifest))
+		if !result.DryRun || !slices.Equal(keys(result.Changes), want) {
+			t.Fatalf("unexpected result: %+v", result)
+		}
+		if slices.Contains(me(t).Permissions, "pricing:read") {
+			t.Error("dry run changed the live roles")
+		}
+	})
+
+	t.Run("Apply reconciles the live configuration", func(t *testing.T) {
+		result := decode(t, apply("", testManifest))
+		if result.DryRun || !slices.Equal(keys(result.Changes), want) {
+			t.Fatalf("unexpected result: %+v", result)
+		}
+		resp := me(t)
+		if !slices.Contains(resp.Permissions, "pricing:read") || resp.RateLimit.Tier != "scanner" {
+			t.Errorf("configuration not applied: %+v", resp)
+		}
+	})
+
+	t.Run("Applying again changes nothing", func(t *testing.T) {
+		if result := decode(t, apply("", testManifest)); len(result.Changes) != 0 {
+			t.Errorf("expected no changes, got %+v", result.Changes)
+		}
+	})
+
+	t.Run("Roles left out are removed", func(t *testing.T) {
+		result := decode(t, apply("", `{"roles": {"user": {"permissions": ["pricing:read"]}, "guest": {"permissions": []}}}`))
+		if got := keys(result.Changes); !slices.Equal(got, []string{"roles/guest:update", "roles/warehouse:remove"}) {
+			t.Errorf("unexpected changes: %v", got)
+		}
+		if perms := me(t).Permissions; len(perms) != 0 {
+			t.Errorf("expected no permissions, got %v", perms)
+		}
+	})
+
+	t.Run("Webhooks are reconciled by URL", func(t *testing.T) {
+		listWebhooks := func(t *testing.T) []models.Webhook {
+			t.Helper()
+			req := httptest.NewRequest(http.MethodGet, "/admin/webhooks", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			var hooks []models.Webhook
+			if err := json.NewDecoder(w.Body).Decode(&hooks); err != nil {
+				t.Fatalf("failed to decode webhooks: %v", err)
+			}
+			return hooks
+		}
+
+		result := decode(t, apply("", "webhooks: [{url: 'https://a.example.com/hook', events: [stock.low]}, {url: 'https://b.example.com/hook', events: [product.created]}]"))
+		if got := keys(result.Changes); !slices.Equal(got, []string{"webhooks/https://a.example.com/hook:add", "webhooks/https://b.example.com/hook:add"}) {
+			t.Errorf("unexpected changes: %v", got)
+		}
+		if len(result.WebhookSecrets) != 2 || result.WebhookSecrets["https://a.example.com/hook"] == "" {
+			t.Errorf("expected the secrets of both webhooks, got %v", result.WebhookSecrets)
+		}
+		before := listWebhooks(t)
+		if len(before) != 2 {
+			t.Fatalf("expected 2 webhooks, got %+v", before)
+		}
+
+		result = decode(t, apply("", "webhooks: [{url: 'https://a.example.com/hook', events: [stock.low, movement.created]}]"))
+		if got := keys(result.Changes); !slices.Equal(got, []string{"webhooks/https://a.example.com/hook:update", "webhooks/https://b.example.com/hook:remove"}) {
+			t.Errorf("unexpected changes: %v", got)
+		}
+		if len(result.WebhookSecrets) != 0 {
+			t.Errorf("expected no secrets for an update, got %v", result.WebhookSecrets)
+		}
+		after := listWebhooks(t)
+		if len(after) != 1 || after[0].ID != before[0].ID || !slices.Equal(after[0].Events, []string{"stock.low", "movement.created"}) {
+			t.Errorf("expected the first webhook updated in place, got %+v", after)
+		}
+
+		if result := decode(t, apply("", "webhooks: []")); len(result.Changes) != 1 || len(listWebhooks(t)) != 0 {
+			t.Errorf("expected an empty section to remove every webhook, got %+v", result.Changes)
+		}
+	})
+
+	cases := []struct {
+		name     string
+		manifest string
+	}{
+		{"Empty body", ""},
+		{"Unknown section", "quotas: {products: 10}"},
+		{"Admin role", "roles: {admin: {permissions: []}}"},
+		{"Unknown permission", "roles: {user: {permissions: [stock:write]}}"},
+		{"Invalid rate limit", "rate_limits: {user: {tier: standard, max_requests: 0, window_seconds: 60}}"},
+		{"Alert rules", "alert_rules: [{name: low-stock}]"},
+		{"Webhook without events", "webhooks: [{url: 'https://example.com/hook'}]"},
+		{"Unknown webhook event", "webhooks: [{url: 'https://example.com/hook', events: [order.shipped]}]"},
+		{"Webhook listed twice", "webhooks: [{url: 'https://example.com/hook', events: [stock.low]}, {url: 'https://example.com/hook', events: [product.created]}]"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if w := apply("", c.manifest); w.Code != http.StatusBadRequest {
+				t.Errorf("expected 400 Bad Request, got %d: %s", w.Code, w.Body.String())
+			}
+		})
+	}
+}
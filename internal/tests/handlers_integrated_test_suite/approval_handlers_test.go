@@ -0,0 +1,139 @@
+package handlers_integrated_test_suite
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+func TestProductDeletionApproval(t *testing.T) {
+	handlers.SetDeleteApprovalRequired(true)
+	t.Cleanup(func() {
+		handlers.SetDeleteApprovalRequired(false)
+		clearApprovals()
+		clearAllProducts()
+		clearAllUsersExceptAdmin()
+	})
+	r := router.NewRouter()
+
+	send := func(method, path, bearer string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+bearer)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	decode := func(w *httptest.ResponseRecorder, v any) {
+		t.Helper()
+		if err := json.NewDecoder(w.Body).Decode(v); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+	}
+	newProduct := func(name string, quantity int) int {
+		var p handlers.ProductResponse
+		decode(createProduct(r, handlers.ProductRequest{Name: name, Price: 10, Quantity: quantity}), &p)
+		return p.Id
+	}
+	stocked := newProduct("Stocked", 5)
+	kept := newProduct("Kept", 3)
+	empty := newProduct("Empty", 0)
+
+	secondAdmin, err := roleToken(r, "second-admin", "admin")
+	if err != nil {
+		t.Fatalf("failed to create admin: %v", err)
+	}
+	userToken, err := roleToken(r, "plain-user", "user")
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	var approval models.Approval
+	t.Run("Deleting stock needs approval", func(t *testing.T) {
+		w := send(http.MethodDelete, fmt.Sprintf("/products/%d", stocked), token)
+		if w.Code != http.StatusAccepted {
+			t.Fatalf("expected 202 Accepted, got %d: %s", w.Code, w.Body.String())
+		}
+		decode(w, &approval)
+		if approval.Status != models.ApprovalStatusPending || approval.RequestedBy != "admin" || approval.EntityID != stocked {
+			t.Errorf("unexpected approval: %+v", approval)
+		}
+		if w := send(http.MethodDelete, fmt.Sprintf("/products/%d", stocked), token); w.Code != http.StatusConflict {
+			t.Errorf("expected 409 Conflict for a second request, got %d", w.Code)
+		}
+		if w := send(http.MethodGet, fmt.Sprintf("/products/%d", stocked), token); w.Code != http.StatusOK {
+			t.Errorf("expected the product to remain until approved, got %d", w.Code)
+		}
+	})
+
+	t.Run("Products without stock are deleted at once", func(t *testing.T) {
+		if w := send(http.MethodDelete, fmt.Sprintf("/products/%d", empty), token); w.Code != http.StatusNoContent {
+			t.Errorf("expected 204 No Content, got %d", w.Code)
+		}
+	})
+
+	t.Run("Pending list", func(t *testing.T) {
+		var pending []models.Approval
+		decode(send(http.MethodGet, "/approvals", secondAdmin), &pending)
+		if len(pending) != 1 || pending[0].ID != approval.ID {
+			t.Errorf("expected the one pending approval, got %+v", pending)
+		}
+		if w := send(http.MethodGet, "/approvals", userToken); w.Code != http.StatusForbidden {
+			t.Errorf("expected 403 Forbidden for a non-admin, got %d", w.Code)
+		}
+	})
+
+	approvePath := fmt.Sprintf("/approvals/%d/approve", approval.ID)
+	t.Run("Requester cannot approve", func(t *testing.T) {
+		if w := send(http.MethodPost, approvePath, token); w.Code != http.StatusForbidden {
+			t.Errorf("expected 403 Forbidden, got %d", w.Code)
+		}
+	})
+
+	t.Run("Second admin approves", func(t *testing.T) {
+		w := send(http.MethodPost, approvePath, secondAdmin)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		var decided models.Approval
+		decode(w, &decided)
+		if decided.Status != models.ApprovalStatusApproved || decided.DecidedBy != "second-admin" || decided.DecidedAt == nil {
+			t.Errorf("unexpected approval: %+v", decided)
+		}
+		if w := send(http.MethodGet, fmt.Sprintf("/products/%d", stocked), token); w.Code != http.StatusNotFound {
+			t.Errorf("expected the product to be deleted, got %d", w.Code)
+		}
+		if w := send(http.MethodPost, approvePath, secondAdmin); w.Code != http.StatusConflict {
+			t.Errorf("expected 409 Conflict approving twice, got %d", w.Code)
+		}
+	})
+
+	t.Run("Rejected requests keep the product", func(t *testing.T) {
+		w := send(http.MethodDelete, fmt.Sprintf("/products/%d", kept), token)
+		var request models.Approval
+		decode(w, &request)
+		if w := send(http.MethodPost, fmt.Sprintf("/approvals/%d/reject", request.ID), secondAdmin); w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d", w.Code)
+		}
+		if w := send(http.MethodGet, fmt.Sprintf("/products/%d", kept), token); w.Code != http.StatusOK {
+			t.Errorf("expected the product to remain, got %d", w.Code)
+		}
+
+		var all []models.Approval
+		decode(send(http.MethodGet, "/approvals?status=all", token), &all)
+		if len(all) != 2 || all[0].Status != models.ApprovalStatusRejected {
+			t.Errorf("expected both approvals, newest first, got %+v", all)
+		}
+	})
+
+	t.Run("Unknown approval", func(t *testing.T) {
+		if w := send(http.MethodPost, "/approvals/999999/approve", secondAdmin); w.Code != http.StatusNotFound {
+			t.Errorf("expected 404 Not Found, got %d", w.Code)
+		}
+	})
+}
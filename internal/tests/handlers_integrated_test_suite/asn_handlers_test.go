@@ -0,0 +1,159 @@
+package handlers_integrated_test_suite
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+func TestASNHandlers(t *testing.T) {
+	t.Cleanup(func() {
+		clearASNs()
+		clearAllProducts()
+	})
+	r := router.NewRouter()
+
+	send := func(method, path string, body any) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		if body != nil {
+			_ = json.NewEncoder(&buf).Encode(body)
+		}
+		req := httptest.NewRequest(method, path, &buf)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	newProduct := func(name, barcode string) int {
+		w := createProduct(r, handlers.ProductRequest{Name: name, Price: 10, Quantity: 5, Barcode: barcode})
+		var p handlers.ProductResponse
+		if err := json.NewDecoder(w.Body).Decode(&p); err != nil {
+			t.Fatalf("failed to decode product: %v", err)
+		}
+		return p.Id
+	}
+	bolts := newProduct("Bolts", "1000000000011")
+	nuts := newProduct("Nuts", "1000000000028")
+	washers := newProduct("Washers", "1000000000035")
+	screws := newProduct("Screws", "1000000000042")
+
+	var asn models.ASN
+	t.Run("Create ASN", func(t *testing.T) {
+		w := send(http.MethodPost, "/asns", handlers.ASNRequest{
+			Reference: "PO-100",
+			Supplier:  "Acme",
+			Lines: []handlers.ASNLineRequest{
+				{ProductID: bolts, Quantity: 2},
+				{ProductID: nuts, Quantity: 3},
+				{ProductID: washers, Quantity: 1},
+			},
+		})
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected 201 Created, got %d: %s", w.Code, w.Body.String())
+		}
+		if err := json.NewDecoder(w.Body).Decode(&asn); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if asn.Status != models.ASNStatusOpen || len(asn.Lines) != 3 {
+			t.Errorf("unexpected ASN: %+v", asn)
+		}
+	})
+	asnPath := fmt.Sprintf("/asns/%d", asn.ID)
+
+	t.Run("Discrepancies wait for receiving", func(t *testing.T) {
+		if w := send(http.MethodGet, asnPath+"/discrepancies", nil); w.Code != http.StatusConflict {
+			t.Errorf("expected 409 Conflict, got %d", w.Code)
+		}
+	})
+
+	t.Run("Receive against the ASN", func(t *testing.T) {
+		w := send(http.MethodPost, asnPath+"/receive", handlers.ReceiveASNRequest{Items: []handlers.ReceivedItem{
+			{Barcode: "1000000000011"},
+			{Barcode: "1000000000011"},
+			{Barcode: "1000000000028"},
+			{ProductID: washers, Quantity: 3},
+			{Barcode: "1000000000042"},
+		}})
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		var report handlers.ASNReport
+		if err := json.NewDecoder(w.Body).Decode(&report); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if report.ASN.Status != models.ASNStatusReceived {
+			t.Errorf("expected the ASN to be received, got %s", report.ASN.Status)
+		}
+
+		kinds := map[int]string{}
+		for _, d := range report.Discrepancies {
+			kinds[d.ProductID] = d.Kind
+		}
+		want := map[int]string{nuts: "short", washers: "over", screws: "unexpected"}
+		if len(kinds) != len(want) {
+			t.Errorf("expected %d discrepancies, got %+v", len(want), report.Discrepancies)
+		}
+		for id, kind := range want {
+			if kinds[id] != kind {
+				t.Errorf("expected product %d to be %s, got %q", id, kind, kinds[id])
+			}
+		}
+
+		quantities := map[int]int{bolts: 7, nuts: 6, washers: 8, screws: 6}
+		for id, quantity := range quantities {
+			w := send(http.MethodGet, fmt.Sprintf("/products/%d", id), nil)
+			var p handlers.ProductResponse
+			if err := json.NewDecoder(w.Body).Decode(&p); err != nil {
+				t.Fatalf("failed to decode product: %v", err)
+			}
+			if p.Quantity != quantity {
+				t.Errorf("expected product %d to have %d units, got %d", id, quantity, p.Quantity)
+			}
+		}
+	})
+
+	t.Run("Discrepancy report", func(t *testing.T) {
+		w := send(http.MethodGet, asnPath+"/discrepancies", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		var discrepancies []handlers.ASNDiscrepancy
+		if err := json.NewDecoder(w.Body).Decode(&discrepancies); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(discrepancies) != 3 {
+			t.Errorf("expected 3 discrepancies, got %+v", discrepancies)
+		}
+	})
+
+	cases := []struct {
+		name   string
+		method string
+		path   string
+		body   any
+		code   int
+	}{
+		{"Receive twice", http.MethodPost, asnPath + "/receive", handlers.ReceiveASNRequest{Items: []handlers.ReceivedItem{{ProductID: bolts}}}, http.StatusConflict},
+		{"Missing supplier", http.MethodPost, "/asns", handlers.ASNRequest{Reference: "PO-101", Lines: []handlers.ASNLineRequest{{ProductID: bolts, Quantity: 1}}}, http.StatusBadRequest},
+		{"No lines", http.MethodPost, "/asns", handlers.ASNRequest{Reference: "PO-101", Supplier: "Acme"}, http.StatusBadRequest},
+		{"Invalid supplier email", http.MethodPost, "/asns", handlers.ASNRequest{Reference: "PO-101", Supplier: "Acme", SupplierEmail: "acme", Lines: []handlers.ASNLineRequest{{ProductID: bolts, Quantity: 1}}}, http.StatusBadRequest},
+		{"Unknown product", http.MethodPost, "/asns", handlers.ASNRequest{Reference: "PO-101", Supplier: "Acme", Lines: []handlers.ASNLineRequest{{ProductID: 999999, Quantity: 1}}}, http.StatusNotFound},
+		{"Unknown ASN", http.MethodPost, "/asns/999999/receive", handlers.ReceiveASNRequest{Items: []handlers.ReceivedItem{{ProductID: bolts}}}, http.StatusNotFound},
+		{"Unknown barcode", http.MethodPost, asnPath + "/receive", handlers.ReceiveASNRequest{Items: []handlers.ReceivedItem{{Barcode: "999"}}}, http.StatusNotFound},
+		{"Item without product", http.MethodPost, asnPath + "/receive", handlers.ReceiveASNRequest{Items: []handlers.ReceivedItem{{Quantity: 2}}}, http.StatusBadRequest},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if w := send(c.method, c.path, c.body); w.Code != c.code {
+				t.Errorf("expected %d, got %d: %s", c.code, w.Code, w.Body.String())
+			}
+		})
+	}
+}
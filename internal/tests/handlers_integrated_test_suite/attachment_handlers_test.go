@@ -0,0 +1,136 @@
+package handlers_integrated_test_suite
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+func TestAttachmentHandlers(t *testing.T) {
+	t.Cleanup(clearAllProducts)
+	r := router.NewRouter()
+
+	w := createProduct(r, handlers.ProductRequest{Name: "Vase", Price: 40.0, Quantity: 5})
+	var product handlers.ProductResponse
+	if err := json.NewDecoder(w.Body).Decode(&product); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	send := func(method, path string, body any) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		if body != nil {
+			_ = json.NewEncoder(&buf).Encode(body)
+		}
+		req := httptest.NewRequest(method, path, &buf)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	var writeOff models.WriteOff
+	w = send(http.MethodPost, fmt.Sprintf("/products/%d/write-off", product.Id), handlers.WriteOffRequest{Quantity: 1, Reason: models.WriteOffReasonDamaged})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201 Created, got %d: %s", w.Code, w.Body.String())
+	}
+	if err := json.NewDecoder(w.Body).Decode(&writeOff); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if writeOff.MovementID == nil {
+		t.Fatalf("expected the write-off to name its movement")
+	}
+	base := fmt.Sprintf("/movements/%d/attachments", *writeOff.MovementID)
+
+	upload := func(path, filename string, content []byte) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		writer := multipart.NewWriter(&buf)
+		_ = writer.WriteField("description", "Broken in transit")
+		part, _ := writer.CreateFormFile("file", filename)
+		_, _ = part.Write(content)
+		writer.Close()
+
+		req := httptest.NewRequest(http.MethodPost, path, &buf)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	content := []byte("%PDF-1.4 damage report")
+	var attachment models.MovementAttachment
+
+	t.Run("Attach PDF", func(t *testing.T) {
+		w := upload(base, "damage.pdf", content)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected 201 Created, got %d: %s", w.Code, w.Body.String())
+		}
+		if err := json.NewDecoder(w.Body).Decode(&attachment); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if attachment.ContentType != "application/pdf" || attachment.Description != "Broken in transit" {
+			t.Errorf("unexpected attachment: %+v", attachment)
+		}
+	})
+
+	t.Run("List attachments", func(t *testing.T) {
+		var attachments []models.MovementAttachment
+		if err := json.NewDecoder(send(http.MethodGet, base, nil).Body).Decode(&attachments); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(attachments) != 1 || attachments[0].ID != attachment.ID {
+			t.Errorf("unexpected attachments: %+v", attachments)
+		}
+	})
+
+	t.Run("Download returns the content", func(t *testing.T) {
+		w := send(http.MethodGet, fmt.Sprintf("%s/%d", base, attachment.ID), nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d", w.Code)
+		}
+		if !bytes.Equal(w.Body.Bytes(), content) {
+			t.Errorf("downloaded content differs from upload")
+		}
+	})
+
+	t.Run("Local storage cannot presign", func(t *testing.T) {
+		if w := send(http.MethodGet, fmt.Sprintf("%s/%d/url", base, attachment.ID), nil); w.Code != http.StatusNotImplemented {
+			t.Errorf("expected 501 Not Implemented, got %d", w.Code)
+		}
+	})
+
+	t.Run("Only images and PDFs", func(t *testing.T) {
+		if w := upload(base, "notes.txt", []byte("plain text")); w.Code != http.StatusUnsupportedMediaType {
+			t.Errorf("expected 415 Unsupported Media Type, got %d", w.Code)
+		}
+	})
+
+	t.Run("Unknown movement", func(t *testing.T) {
+		if w := upload("/movements/999999/attachments", "damage.pdf", content); w.Code != http.StatusNotFound {
+			t.Errorf("expected 404 Not Found, got %d", w.Code)
+		}
+	})
+
+	t.Run("Attachment of another movement", func(t *testing.T) {
+		if w := send(http.MethodGet, fmt.Sprintf("/movements/999999/attachments/%d", attachment.ID), nil); w.Code != http.StatusNotFound {
+			t.Errorf("expected 404 Not Found, got %d", w.Code)
+		}
+	})
+
+	t.Run("Delete attachment", func(t *testing.T) {
+		if w := send(http.MethodDelete, fmt.Sprintf("%s/%d", base, attachment.ID), nil); w.Code != http.StatusNoContent {
+			t.Fatalf("expected 204 No Content, got %d", w.Code)
+		}
+		if w := send(http.MethodGet, fmt.Sprintf("%s/%d", base, attachment.ID), nil); w.Code != http.StatusNotFound {
+			t.Errorf("expected 404 Not Found, got %d", w.Code)
+		}
+	})
+}
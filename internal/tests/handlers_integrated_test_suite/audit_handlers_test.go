@@ -0,0 +1,148 @@
+package handlers_integrated_test_suite
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+func TestAuditLogHashChain(t *testing.T) {
+	t.Cleanup(clearAuditLog)
+	t.Cleanup(clearAllPeriods)
+	r := router.NewRouter()
+
+	for _, period := range []string{"2020-02", "2020-03"} {
+		if w := closePeriod(r, period); w.Code != http.StatusCreated {
+			t.Fatalf("failed to close period %s: %d", period, w.Code)
+		}
+	}
+
+	t.Run("Export links entries", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/admin/audit/export?format=json", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d", w.Code)
+		}
+		var entries []models.AuditEntry
+		if err := json.NewDecoder(w.Body).Decode(&entries); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(entries) < 2 {
+			t.Fatalf("expected at least 2 entries, got %d", len(entries))
+		}
+		last := entries[len(entries)-1]
+		if last.PrevHash != entries[len(entries)-2].Hash {
+			t.Errorf("expected last entry to link to the previous one")
+		}
+	})
+
+	t.Run("Intact chain verifies", func(t *testing.T) {
+		result := verifyAuditLog(t, r)
+		if !result.Valid {
+			t.Errorf("expected valid chain, got %+v", result)
+		}
+	})
+
+	t.Run("Tampering is detected", func(t *testing.T) {
+		if _, err := database.Exec(`UPDATE audit_log SET actor = 'mallory' WHERE id = (SELECT MIN(id) FROM audit_log)`); err != nil {
+			t.Fatalf("failed to tamper with audit log: %v", err)
+		}
+		result := verifyAuditLog(t, r)
+		if result.Valid {
+			t.Errorf("expected tampering to be detected")
+		}
+	})
+}
+
+func TestListAuditLog(t *testing.T) {
+	t.Cleanup(clearAuditLog)
+	t.Cleanup(clearAllProducts)
+	r := router.NewRouter()
+
+	req := httptest.NewRequest(http.MethodPost, "/products", strings.NewReader(`{"name":"Audited","price":10,"quantity":5}`))
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("X-Request-ID", "req-audit-1")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("failed to create product: %d %s", w.Code, w.Body.String())
+	}
+	if got := w.Header().Get("X-Request-ID"); got != "req-audit-1" {
+		t.Errorf("expected the request ID to be echoed, got %q", got)
+	}
+
+	list := func(query string) (*httptest.ResponseRecorder, handlers.AuditLogPage) {
+		req := httptest.NewRequest(http.MethodGet, "/admin/audit"+query, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		var page handlers.AuditLogPage
+		if w.Code == http.StatusOK {
+			if err := json.NewDecoder(w.Body).Decode(&page); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+		}
+		return w, page
+	}
+
+	t.Run("Filter by user and entity", func(t *testing.T) {
+		w, page := list("?user=admin&entity=product&action=create")
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d", w.Code)
+		}
+		if len(page.Entries) != 1 {
+			t.Fatalf("expected 1 entry, got %+v", page.Entries)
+		}
+		e := page.Entries[0]
+		if e.Actor != "admin" || e.RequestID != "req-audit-1" || len(e.Details) == 0 {
+			t.Errorf("unexpected entry: %+v", e)
+		}
+	})
+
+	t.Run("Date range excludes entries", func(t *testing.T) {
+		_, page := list("?entity=product&until=2000-01-01T00:00:00Z")
+		if len(page.Entries) != 0 {
+			t.Errorf("expected no entries, got %+v", page.Entries)
+		}
+	})
+
+	t.Run("Request ID keeps the chain valid", func(t *testing.T) {
+		if result := verifyAuditLog(t, r); !result.Valid {
+			t.Errorf("expected valid chain, got %+v", result)
+		}
+	})
+
+	for _, query := range []string{"?since=yesterday", "?limit=0", "?offset=-1", "?entity_id=1"} {
+		t.Run("Rejects "+query, func(t *testing.T) {
+			if w, _ := list(query); w.Code != http.StatusBadRequest {
+				t.Errorf("expected 400 Bad Request, got %d", w.Code)
+			}
+		})
+	}
+}
+
+func verifyAuditLog(t *testing.T, r http.Handler) handlers.AuditVerificationResult {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/admin/audit/verify", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 OK, got %d", w.Code)
+	}
+	var result handlers.AuditVerificationResult
+	if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	return result
+}
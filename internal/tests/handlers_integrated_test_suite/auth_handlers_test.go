@@ -5,12 +5,16 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/rogerio-castellano/inventory-tracker/internal/auth"
 	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
 	rl "github.com/rogerio-castellano/inventory-tracker/internal/http/rate_limiter"
 	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+	"github.com/rogerio-castellano/inventory-tracker/internal/usage"
 )
 
 func runWithVisitorCleanup(t *testing.T, name string, testFunc func(t *testing.T)) {
@@ -159,3 +163,123 @@ func TestRegisterHandler(t *testing.T) {
 		}
 	})
 }
+
+func TestMeHandler(t *testing.T) {
+	t.Cleanup(func() {
+		handlers.SetProductQuota(0)
+		clearAllProducts()
+	})
+	r := router.NewRouter()
+	createProduct(r, handlers.ProductRequest{Name: "Quota item", Price: 1, Quantity: 1})
+	handlers.SetProductQuota(1)
+
+	req := httptest.NewRequest(http.MethodGet, "/me", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var me handlers.MeResponse
+	if err := json.NewDecoder(w.Body).Decode(&me); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if me.Username != "admin" || me.Tenant == "" || len(me.Permissions) == 0 {
+		t.Errorf("unexpected identity: %+v", me)
+	}
+	if me.Quota.ProductsUsed != 1 || me.Quota.ProductsAllowed == nil || *me.Quota.ProductsAllowed != 1 {
+		t.Errorf("unexpected quota: %+v", me.Quota)
+	}
+	if me.RateLimit.Tier != "elevated" || me.RateLimit.MaxRequests == 0 {
+		t.Errorf("unexpected rate limit: %+v", me.RateLimit)
+	}
+
+	t.Run("Creating past the quota is refused", func(t *testing.T) {
+		w := createProduct(r, handlers.ProductRequest{Name: "Over quota", Price: 1})
+		if w.Code != http.StatusForbidden {
+			t.Errorf("expected 403 Forbidden, got %d", w.Code)
+		}
+	})
+}
+
+func TestRateLimitHeaders(t *testing.T) {
+	r := router.NewRouter()
+
+	post := func(path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	runWithVisitorCleanup(t, "In-memory limiter", func(t *testing.T) {
+		for i := range 3 {
+			w := post("/register", `{invalid`)
+			if w.Header().Get("X-RateLimit-Limit") != "3" || w.Header().Get("X-RateLimit-Remaining") != strconv.Itoa(2-i) {
+				t.Errorf("request %d: unexpected headers %v", i, w.Header())
+			}
+			if w.Header().Get("X-RateLimit-Reset") == "" || w.Header().Get("Retry-After") != "" {
+				t.Errorf("request %d: unexpected headers %v", i, w.Header())
+			}
+		}
+		w := post("/register", `{invalid`)
+		if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "1" || w.Header().Get("X-RateLimit-Remaining") != "0" {
+			t.Errorf("expected 429 with Retry-After, got %d %v", w.Code, w.Header())
+		}
+	})
+
+	runWithVisitorCleanup(t, "Redis limiter on a successful login", func(t *testing.T) {
+		w := post("/login", `{"username":"admin","password":"secret"}`)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d", w.Code)
+		}
+		if w.Header().Get("X-RateLimit-Limit") != "3" || w.Header().Get("X-RateLimit-Remaining") != "2" || w.Header().Get("X-RateLimit-Reset") != "60" {
+			t.Errorf("unexpected headers %v", w.Header())
+		}
+	})
+}
+
+func TestSessionIdleTimeout(t *testing.T) {
+	auth.SetSessionIdleTimeout(time.Hour)
+	t.Cleanup(func() { auth.SetSessionIdleTimeout(0) })
+	r := router.NewRouter()
+
+	post := func(path string, body any) *httptest.ResponseRecorder {
+		raw, _ := json.Marshal(body)
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(raw))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	login := func(t *testing.T) handlers.LoginResult {
+		w := post("/login", handlers.CredentialsRequest{Username: "admin", Password: "secret"})
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d", w.Code)
+		}
+		var resp handlers.LoginResult
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode token response: %v", err)
+		}
+		return resp
+	}
+
+	runWithVisitorCleanup(t, "Refresh within the idle timeout", func(t *testing.T) {
+		session := login(t)
+		if w := post("/refresh", handlers.RefreshRequest{Username: "admin", RefreshToken: session.RefreshToken}); w.Code != http.StatusOK {
+			t.Errorf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	runWithVisitorCleanup(t, "Refresh of an idle session is refused", func(t *testing.T) {
+		session := login(t)
+		// Dropping the activity is what the idle timeout running out does.
+		if err := usage.Forget("admin"); err != nil {
+			t.Fatalf("failed to clear activity: %v", err)
+		}
+		if w := post("/refresh", handlers.RefreshRequest{Username: "admin", RefreshToken: session.RefreshToken}); w.Code != http.StatusUnauthorized {
+			t.Errorf("expected 401 Unauthorized, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+}
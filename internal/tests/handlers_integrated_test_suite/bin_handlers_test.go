@@ -0,0 +1,96 @@
+package handlers_integrated_test_suite
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+)
+
+func TestBinHandlers(t *testing.T) {
+	t.Cleanup(clearAllProducts)
+	r := router.NewRouter()
+
+	w := createProduct(r, handlers.ProductRequest{Name: "Hammer", Price: 12, Quantity: 10, Barcode: "4006381333931"})
+	var hammer handlers.ProductResponse
+	if err := json.NewDecoder(w.Body).Decode(&hammer); err != nil {
+		t.Fatalf("failed to decode product: %v", err)
+	}
+	binsPath := fmt.Sprintf("/products/%d/bins", hammer.Id)
+
+	send := func(method, path string, body any) *httptest.ResponseRecorder {
+		payload, _ := json.Marshal(body)
+		req := httptest.NewRequest(method, path, bytes.NewReader(payload))
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	decode := func(t *testing.T, w *httptest.ResponseRecorder) handlers.BinsResponse {
+		t.Helper()
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		var resp handlers.BinsResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return resp
+	}
+
+	t.Run("Put stock away", func(t *testing.T) {
+		decode(t, send(http.MethodPut, binsPath, handlers.BinRequest{Warehouse: "north", Bin: "A3-B", Quantity: 6}))
+		resp := decode(t, send(http.MethodPut, binsPath, handlers.BinRequest{Warehouse: "south", Bin: "C1", Quantity: 2}))
+		if resp.Unassigned != 2 || len(resp.Bins) != 1 || resp.Bins[0].Bin != "C1" {
+			t.Errorf("unexpected bins: %+v", resp)
+		}
+	})
+
+	t.Run("Move between bins", func(t *testing.T) {
+		resp := decode(t, send(http.MethodPost, binsPath+"/move", handlers.BinMoveRequest{Warehouse: "north", From: "A3-B", To: "A4-A", Quantity: 4}))
+		if len(resp.Bins) != 2 || resp.Bins[0].Bin != "A3-B" || resp.Bins[0].Quantity != 2 || resp.Bins[1].Quantity != 4 {
+			t.Errorf("unexpected bins: %+v", resp.Bins)
+		}
+	})
+
+	t.Run("Scan lists bins of the warehouse", func(t *testing.T) {
+		w := send(http.MethodPost, "/scan", handlers.ScanRequest{Barcode: "4006381333931", Delta: -1, Warehouse: "north"})
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		var resp handlers.ScanResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(resp.Bins) != 2 || resp.Bins[0].Warehouse != "north" {
+			t.Errorf("unexpected bins: %+v", resp.Bins)
+		}
+	})
+
+	cases := []struct {
+		name   string
+		method string
+		path   string
+		body   any
+		code   int
+	}{
+		{"More than in stock", http.MethodPut, binsPath, handlers.BinRequest{Warehouse: "south", Bin: "C2", Quantity: 5}, http.StatusConflict},
+		{"Missing bin", http.MethodPut, binsPath, handlers.BinRequest{Warehouse: "south", Quantity: 1}, http.StatusBadRequest},
+		{"Unknown product", http.MethodPut, "/products/999999/bins", handlers.BinRequest{Bin: "A1", Quantity: 1}, http.StatusNotFound},
+		{"Empty source bin", http.MethodPost, binsPath + "/move", handlers.BinMoveRequest{Warehouse: "north", From: "Z9", To: "A1", Quantity: 1}, http.StatusNotFound},
+		{"Not enough in source bin", http.MethodPost, binsPath + "/move", handlers.BinMoveRequest{Warehouse: "south", From: "C1", To: "C2", Quantity: 3}, http.StatusConflict},
+		{"Same bin", http.MethodPost, binsPath + "/move", handlers.BinMoveRequest{Warehouse: "south", From: "C1", To: "C1", Quantity: 1}, http.StatusBadRequest},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if w := send(c.method, c.path, c.body); w.Code != c.code {
+				t.Errorf("expected %d, got %d: %s", c.code, w.Code, w.Body.String())
+			}
+		})
+	}
+}
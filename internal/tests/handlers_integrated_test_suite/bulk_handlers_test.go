@@ -0,0 +1,97 @@
+package handlers_integrated_test_suite
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+func TestBulkHandlers(t *testing.T) {
+	clearAllProducts()
+	t.Cleanup(clearAllProducts)
+	r := router.NewRouter()
+
+	post := func(path string, payload any) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(payload)
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Insert products", func(t *testing.T) {
+		var products []handlers.ProductRequest
+		for i := 0; i < 250; i++ {
+			products = append(products, handlers.ProductRequest{Name: fmt.Sprintf("Bulk %03d", i), Price: 1.5, Quantity: 10, Threshold: 1})
+		}
+
+		w := post("/admin/bulk/products", products)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected 201 Created, got %d: %s", w.Code, w.Body.String())
+		}
+		var res handlers.BulkInsertResult
+		if err := json.NewDecoder(w.Body).Decode(&res); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if res.Inserted != 250 {
+			t.Errorf("expected 250 inserted, got %d", res.Inserted)
+		}
+	})
+
+	t.Run("Existing name rejects the whole batch", func(t *testing.T) {
+		w := post("/admin/bulk/products", []handlers.ProductRequest{
+			{Name: "Bulk new", Price: 1, Quantity: 1},
+			{Name: "Bulk 000", Price: 1, Quantity: 1},
+		})
+		if w.Code != http.StatusConflict {
+			t.Fatalf("expected 409 Conflict, got %d", w.Code)
+		}
+		if _, err := productRepo.GetByName("Bulk new"); err == nil {
+			t.Error("expected no product from the rejected batch")
+		}
+	})
+
+	t.Run("Insert movements and update quantities", func(t *testing.T) {
+		p, err := productRepo.GetByName("Bulk 001")
+		if err != nil {
+			t.Fatalf("failed to fetch product: %v", err)
+		}
+
+		w := post("/admin/bulk/movements", []models.Movement{
+			{ProductID: p.ID, Delta: 5},
+			{ProductID: p.ID, Delta: -3, CreatedAt: "2021-03-04T10:00:00Z"},
+		})
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected 201 Created, got %d: %s", w.Code, w.Body.String())
+		}
+
+		updated, _ := productRepo.GetByID(p.ID)
+		if updated.Quantity != 12 {
+			t.Errorf("expected quantity 12, got %d", updated.Quantity)
+		}
+	})
+
+	t.Run("Negative stock rejects the whole batch", func(t *testing.T) {
+		p, _ := productRepo.GetByName("Bulk 002")
+
+		w := post("/admin/bulk/movements", []models.Movement{
+			{ProductID: p.ID, Delta: 1},
+			{ProductID: p.ID, Delta: -100},
+		})
+		if w.Code != http.StatusConflict {
+			t.Fatalf("expected 409 Conflict, got %d", w.Code)
+		}
+		unchanged, _ := productRepo.GetByID(p.ID)
+		if unchanged.Quantity != 10 {
+			t.Errorf("expected quantity 10, got %d", unchanged.Quantity)
+		}
+	})
+}
@@ -0,0 +1,108 @@
+package handlers_integrated_test_suite
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+func TestCapacityHandlers(t *testing.T) {
+	t.Cleanup(func() {
+		clearCapacities()
+		clearAllProducts()
+	})
+	r := router.NewRouter()
+
+	w := createProduct(r, handlers.ProductRequest{Name: "Paint can", Price: 15, Quantity: 40})
+	var paint handlers.ProductResponse
+	if err := json.NewDecoder(w.Body).Decode(&paint); err != nil {
+		t.Fatalf("failed to decode product: %v", err)
+	}
+	binsPath := fmt.Sprintf("/products/%d/bins", paint.Id)
+
+	send := func(method, path string, body any) *httptest.ResponseRecorder {
+		payload, _ := json.Marshal(body)
+		req := httptest.NewRequest(method, path, bytes.NewReader(payload))
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	for _, c := range []handlers.CapacityRequest{
+		{Warehouse: "east", Bin: "A1", MaxUnits: 10, Mode: models.CapacityModeReject},
+		{Warehouse: "east", MaxUnits: 25},
+	} {
+		if w := send(http.MethodPut, "/admin/capacities", c); w.Code != http.StatusOK {
+			t.Fatalf("failed to set capacity: %d %s", w.Code, w.Body.String())
+		}
+	}
+
+	t.Run("Reject mode refuses overfilling", func(t *testing.T) {
+		if w := send(http.MethodPut, binsPath, handlers.BinRequest{Warehouse: "east", Bin: "A1", Quantity: 11}); w.Code != http.StatusConflict {
+			t.Errorf("expected 409 Conflict, got %d: %s", w.Code, w.Body.String())
+		}
+		if w := send(http.MethodPut, binsPath, handlers.BinRequest{Warehouse: "east", Bin: "A1", Quantity: 10}); w.Code != http.StatusOK {
+			t.Errorf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("Warn mode accepts with a warning", func(t *testing.T) {
+		w := send(http.MethodPut, binsPath, handlers.BinRequest{Warehouse: "east", Bin: "B1", Quantity: 20})
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		var resp handlers.BinsResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(resp.Warnings) != 1 {
+			t.Errorf("expected one warning, got %v", resp.Warnings)
+		}
+	})
+
+	t.Run("Moving into a full bin is refused", func(t *testing.T) {
+		w := send(http.MethodPost, binsPath+"/move", handlers.BinMoveRequest{Warehouse: "east", From: "B1", To: "A1", Quantity: 1})
+		if w.Code != http.StatusConflict {
+			t.Errorf("expected 409 Conflict, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("Utilization report", func(t *testing.T) {
+		var report []handlers.CapacityUsage
+		if err := json.NewDecoder(send(http.MethodGet, "/reports/capacity", nil).Body).Decode(&report); err != nil {
+			t.Fatalf("failed to decode report: %v", err)
+		}
+		if len(report) != 2 {
+			t.Fatalf("expected 2 lines, got %+v", report)
+		}
+		// Ordered by warehouse and bin: the warehouse-wide capacity first.
+		if report[0].Used != 30 || !report[0].Over || report[0].Utilization != 120 {
+			t.Errorf("unexpected warehouse line: %+v", report[0])
+		}
+		if report[1].Used != 10 || report[1].Over || report[1].Utilization != 100 {
+			t.Errorf("unexpected bin line: %+v", report[1])
+		}
+	})
+
+	cases := []struct {
+		name string
+		req  handlers.CapacityRequest
+	}{
+		{"Zero capacity", handlers.CapacityRequest{Warehouse: "east", MaxUnits: 0}},
+		{"Unknown mode", handlers.CapacityRequest{Warehouse: "east", MaxUnits: 5, Mode: "block"}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if w := send(http.MethodPut, "/admin/capacities", c.req); w.Code != http.StatusBadRequest {
+				t.Errorf("expected 400 Bad Request, got %d", w.Code)
+			}
+		})
+	}
+}
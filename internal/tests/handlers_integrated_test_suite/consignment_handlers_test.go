@@ -0,0 +1,116 @@
+package handlers_integrated_test_suite
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+)
+
+func TestConsignmentHandlers(t *testing.T) {
+	t.Cleanup(func() {
+		clearConsignments()
+		clearAllProducts()
+	})
+	r := router.NewRouter()
+
+	send := func(method, path string, body any) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		if body != nil {
+			_ = json.NewEncoder(&buf).Encode(body)
+		}
+		req := httptest.NewRequest(method, path, &buf)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	newProduct := func(name string) int {
+		w := createProduct(r, handlers.ProductRequest{Name: name, Price: 10, Cost: 5, Quantity: 10, Supplier: "Acme"})
+		var p handlers.ProductResponse
+		if err := json.NewDecoder(w.Body).Decode(&p); err != nil {
+			t.Fatalf("failed to decode product: %v", err)
+		}
+		return p.Id
+	}
+	gloves := newProduct("Gloves")
+	masks := newProduct("Masks")
+	consignmentPath := fmt.Sprintf("/products/%d/consignment", gloves)
+
+	t.Run("Flag consignment stock", func(t *testing.T) {
+		w := send(http.MethodPut, consignmentPath, handlers.ConsignmentRequest{Quantity: 4, UnitCost: 6})
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("Consigned units are left out of the valuation", func(t *testing.T) {
+		var report repo.MarginReport
+		if err := json.NewDecoder(send(http.MethodGet, "/margins", nil).Body).Decode(&report); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		// 6 owned gloves and 10 masks at a cost of 5.
+		if report.ConsignedUnits != 4 || report.StockCost != 80 {
+			t.Errorf("expected 4 consigned units and a stock cost of 80, got %d and %v", report.ConsignedUnits, report.StockCost)
+		}
+	})
+
+	t.Run("Report consumption", func(t *testing.T) {
+		w := send(http.MethodPost, consignmentPath+"/consume", handlers.ConsumeConsignmentRequest{Quantity: 3, Note: "Ward 4"})
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected 201 Created, got %d: %s", w.Code, w.Body.String())
+		}
+		var p handlers.ProductResponse
+		if err := json.NewDecoder(send(http.MethodGet, fmt.Sprintf("/products/%d", gloves), nil).Body).Decode(&p); err != nil {
+			t.Fatalf("failed to decode product: %v", err)
+		}
+		if p.Quantity != 7 {
+			t.Errorf("expected 7 gloves left, got %d", p.Quantity)
+		}
+	})
+
+	t.Run("Settlement report", func(t *testing.T) {
+		today := time.Now().UTC().Format(time.DateOnly)
+		tomorrow := time.Now().UTC().AddDate(0, 0, 1).Format(time.DateOnly)
+		w := send(http.MethodGet, fmt.Sprintf("/reports/consignment-settlement?from=%s&to=%s", today, tomorrow), nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		var report handlers.ConsignmentSettlementReport
+		if err := json.NewDecoder(w.Body).Decode(&report); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(report.Suppliers) != 1 || report.Suppliers[0].Supplier != "Acme" || report.TotalCost != 18 {
+			t.Errorf("expected 18 owed to Acme, got %+v", report)
+		}
+	})
+
+	cases := []struct {
+		name   string
+		method string
+		path   string
+		body   any
+		code   int
+	}{
+		{"Consume more than consigned", http.MethodPost, consignmentPath + "/consume", handlers.ConsumeConsignmentRequest{Quantity: 2}, http.StatusConflict},
+		{"Consume unconsigned product", http.MethodPost, fmt.Sprintf("/products/%d/consignment/consume", masks), handlers.ConsumeConsignmentRequest{Quantity: 1}, http.StatusNotFound},
+		{"Consign more than in stock", http.MethodPut, fmt.Sprintf("/products/%d/consignment", masks), handlers.ConsignmentRequest{Quantity: 11}, http.StatusConflict},
+		{"Negative quantity", http.MethodPut, consignmentPath, handlers.ConsignmentRequest{Quantity: -1}, http.StatusBadRequest},
+		{"Unknown product", http.MethodPut, "/products/999999/consignment", handlers.ConsignmentRequest{Quantity: 1}, http.StatusNotFound},
+		{"Invalid period", http.MethodGet, "/reports/consignment-settlement?from=2025-02-01&to=2025-01-01", nil, http.StatusBadRequest},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if w := send(c.method, c.path, c.body); w.Code != c.code {
+				t.Errorf("expected %d, got %d: %s", c.code, w.Code, w.Body.String())
+			}
+		})
+	}
+}
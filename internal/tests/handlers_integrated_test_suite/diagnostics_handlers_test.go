@@ -0,0 +1,61 @@
+package handlers_integrated_test_suite
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+)
+
+func TestExplainMovementsQueryHandler(t *testing.T) {
+	t.Cleanup(clearAllProducts)
+	r := router.NewRouter()
+
+	w := createProduct(r, handlers.ProductRequest{Name: "Explained", Price: 3.0, Quantity: 1})
+	var created handlers.ProductResponse
+	if err := json.NewDecoder(w.Body).Decode(&created); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	path := "/admin/diagnostics/products/" + strconv.Itoa(created.Id) + "/movements/explain?since=2020-01-01T00:00:00Z&limit=10"
+
+	explain := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Plans for count and page queries", func(t *testing.T) {
+		w := explain()
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d", w.Code)
+		}
+		var plans []repo.QueryPlan
+		if err := json.NewDecoder(w.Body).Decode(&plans); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(plans) != 2 {
+			t.Fatalf("expected 2 plans, got %d", len(plans))
+		}
+		for _, p := range plans {
+			if len(p.Plan) == 0 {
+				t.Errorf("expected a plan for %q", p.Query)
+			}
+		}
+	})
+
+	t.Run("Hidden when disabled", func(t *testing.T) {
+		handlers.SetQueryDiagnosticsEnabled(false)
+		defer handlers.SetQueryDiagnosticsEnabled(true)
+
+		if w := explain(); w.Code != http.StatusNotFound {
+			t.Errorf("expected 404 Not Found, got %d", w.Code)
+		}
+	})
+}
@@ -0,0 +1,117 @@
+package handlers_integrated_test_suite
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+func TestDocumentHandlers(t *testing.T) {
+	t.Cleanup(clearAllProducts)
+	r := router.NewRouter()
+
+	w := createProduct(r, handlers.ProductRequest{Name: "Insulin pen", Price: 30.0, Quantity: 5})
+	var product handlers.ProductResponse
+	if err := json.NewDecoder(w.Body).Decode(&product); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	base := fmt.Sprintf("/products/%d/documents", product.Id)
+
+	upload := func(docType, expiresAt string, content []byte) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		writer := multipart.NewWriter(&buf)
+		_ = writer.WriteField("type", docType)
+		if expiresAt != "" {
+			_ = writer.WriteField("expires_at", expiresAt)
+		}
+		part, _ := writer.CreateFormFile("file", "certificate.pdf")
+		_, _ = part.Write(content)
+		writer.Close()
+
+		req := httptest.NewRequest(http.MethodPost, base, &buf)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	content := []byte("%PDF-1.4 test certificate")
+	soon := time.Now().AddDate(0, 0, 10).Format(time.DateOnly)
+	var doc models.ProductDocument
+
+	t.Run("Upload certificate", func(t *testing.T) {
+		w := upload("certificate", soon, content)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected 201 Created, got %d: %s", w.Code, w.Body.String())
+		}
+		if err := json.NewDecoder(w.Body).Decode(&doc); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if doc.Size != int64(len(content)) || doc.Filename != "certificate.pdf" || doc.ExpiresAt == nil {
+			t.Errorf("unexpected document: %+v", doc)
+		}
+	})
+
+	t.Run("Download returns the content", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("%s/%d", base, doc.ID), nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d", w.Code)
+		}
+		if !bytes.Equal(w.Body.Bytes(), content) {
+			t.Errorf("downloaded content differs from upload")
+		}
+	})
+
+	t.Run("Invalid type", func(t *testing.T) {
+		if w := upload("invoice", "", content); w.Code != http.StatusBadRequest {
+			t.Errorf("expected 400 Bad Request, got %d", w.Code)
+		}
+	})
+
+	t.Run("Expiring certificates report", func(t *testing.T) {
+		get := func(within string) []handlers.ExpiringDocument {
+			req := httptest.NewRequest(http.MethodGet, "/reports/expiring-certificates?within="+within, nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			var report []handlers.ExpiringDocument
+			if err := json.NewDecoder(w.Body).Decode(&report); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			return report
+		}
+
+		if report := get("30d"); len(report) != 1 || report[0].ProductName != "Insulin pen" || report[0].Expired {
+			t.Errorf("expected the certificate in a 30 day window, got %+v", report)
+		}
+		if report := get("5d"); len(report) != 0 {
+			t.Errorf("expected nothing in a 5 day window, got %+v", report)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodDelete, fmt.Sprintf("%s/%d", base, doc.ID), nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusNoContent {
+			t.Fatalf("expected 204 No Content, got %d", w.Code)
+		}
+	})
+}
@@ -0,0 +1,76 @@
+package handlers_integrated_test_suite
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+)
+
+func TestFindDuplicatesHandler(t *testing.T) {
+	t.Cleanup(clearAllProducts)
+	r := router.NewRouter()
+
+	for _, p := range []handlers.ProductRequest{
+		{Name: "Wireless Mouse", Price: 20, Category: "Peripherals"},
+		{Name: "Wireless mouse (black)", Price: 20, Category: "Peripherals"},
+		{Name: "Keyboard", Price: 30, Barcode: "4006381333931"},
+		{Name: "Mechanical Keyboard", Price: 80, Barcode: "4006381333931"},
+		{Name: "Monitor", Price: 150, Category: "Displays"},
+	} {
+		if w := createProduct(r, p); w.Code != http.StatusCreated {
+			t.Fatalf("expected 201 Created, got %d: %s", w.Code, w.Body.String())
+		}
+	}
+
+	get := func(t *testing.T, path string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Find duplicates", func(t *testing.T) {
+		w := get(t, "/products/duplicates")
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		var candidates []handlers.DuplicateCandidate
+		if err := json.NewDecoder(w.Body).Decode(&candidates); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(candidates) != 2 {
+			t.Fatalf("expected 2 candidate pairs, got %+v", candidates)
+		}
+		if !slices.Contains(candidates[0].Reasons, "barcode") || candidates[0].Score < 0.95 {
+			t.Errorf("expected the shared barcode to rank first, got %+v", candidates[0])
+		}
+		mice := candidates[1]
+		if !slices.Contains(mice.Reasons, "name") || !slices.Contains(mice.Reasons, "price_category") {
+			t.Errorf("unexpected reasons for the mice: %+v", mice.Reasons)
+		}
+		if mice.SuggestedMerge.TargetID != mice.Products[0].Id || mice.SuggestedMerge.SourceID != mice.Products[1].Id {
+			t.Errorf("expected the older product to be kept, got %+v", mice.SuggestedMerge)
+		}
+	})
+
+	t.Run("Limit", func(t *testing.T) {
+		var candidates []handlers.DuplicateCandidate
+		_ = json.NewDecoder(get(t, "/products/duplicates?limit=1").Body).Decode(&candidates)
+		if len(candidates) != 1 {
+			t.Errorf("expected 1 pair, got %d", len(candidates))
+		}
+	})
+
+	t.Run("Invalid min_score", func(t *testing.T) {
+		if w := get(t, "/products/duplicates?min_score=2"); w.Code != http.StatusBadRequest {
+			t.Errorf("expected 400 Bad Request, got %d", w.Code)
+		}
+	})
+}
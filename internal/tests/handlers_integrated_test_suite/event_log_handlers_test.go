@@ -0,0 +1,91 @@
+package handlers_integrated_test_suite
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+	"github.com/rogerio-castellano/inventory-tracker/internal/security"
+)
+
+func TestListEventLogHandler(t *testing.T) {
+	clearEventLog()
+	security.Configure(nil, map[string]bool{security.EventRoleChange: true})
+	t.Cleanup(func() {
+		security.Configure(nil, nil)
+		clearEventLog()
+		clearAllUsersExceptAdmin()
+	})
+	r := router.NewRouter()
+
+	list := func(t *testing.T, query string) handlers.EventLogPage {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/events"+query, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		var page handlers.EventLogPage
+		if err := json.NewDecoder(w.Body).Decode(&page); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return page
+	}
+
+	for _, username := range []string{"replay-one", "replay-two"} {
+		body, _ := json.Marshal(handlers.RegisterAsAdminRequest{Username: username, Password: "secret", Role: "admin"})
+		req := httptest.NewRequest(http.MethodPost, "/admin/users", bytes.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected 201 Created, got %d: %s", w.Code, w.Body.String())
+		}
+	}
+
+	var first handlers.EventLogPage
+	t.Run("Replay from the beginning", func(t *testing.T) {
+		first = list(t, "")
+		if len(first.Events) != 2 {
+			t.Fatalf("expected 2 events, got %+v", first.Events)
+		}
+		a, b := first.Events[0], first.Events[1]
+		if a.Type != security.EventRoleChange || a.Target != "replay-one" || b.Target != "replay-two" {
+			t.Errorf("unexpected events: %+v", first.Events)
+		}
+		if a.Sequence <= 0 || b.Sequence <= a.Sequence || first.NextSinceID != b.Sequence {
+			t.Errorf("expected increasing sequences ending at next_since_id, got %d, %d and %d", a.Sequence, b.Sequence, first.NextSinceID)
+		}
+	})
+
+	t.Run("Resume after a sequence", func(t *testing.T) {
+		page := list(t, fmt.Sprintf("?since_id=%d&limit=5", first.Events[0].Sequence))
+		if len(page.Events) != 1 || page.Events[0].Target != "replay-two" {
+			t.Errorf("expected only the second event, got %+v", page.Events)
+		}
+	})
+
+	t.Run("Caught up", func(t *testing.T) {
+		page := list(t, fmt.Sprintf("?since_id=%d", first.NextSinceID))
+		if len(page.Events) != 0 || page.NextSinceID != first.NextSinceID {
+			t.Errorf("expected no events and an unchanged cursor, got %+v", page)
+		}
+	})
+
+	t.Run("Invalid since_id", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/events?since_id=-1", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected 400 Bad Request, got %d", w.Code)
+		}
+	})
+}
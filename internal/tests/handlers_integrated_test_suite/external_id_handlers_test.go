@@ -0,0 +1,109 @@
+package handlers_integrated_test_suite
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+)
+
+func TestExternalIDHandlers(t *testing.T) {
+	t.Cleanup(clearAllProducts)
+	r := router.NewRouter()
+
+	productUUID := newUUID()
+	w := createProduct(r, handlers.ProductRequest{Name: "Tablet", Price: 300, Quantity: 4, ExternalID: strings.ToUpper(productUUID)})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201 Created, got %d: %s", w.Code, w.Body.String())
+	}
+	var created handlers.ProductResponse
+	if err := json.NewDecoder(w.Body).Decode(&created); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if created.ExternalID != productUUID {
+		t.Fatalf("expected external ID %s, got %q", productUUID, created.ExternalID)
+	}
+
+	get := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Get product by external ID", func(t *testing.T) {
+		w := get("/products/by-external/" + productUUID)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		var p handlers.ProductResponse
+		if err := json.NewDecoder(w.Body).Decode(&p); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if p.Id != created.Id {
+			t.Errorf("expected product %d, got %d", created.Id, p.Id)
+		}
+	})
+
+	t.Run("Duplicate product external ID", func(t *testing.T) {
+		w := createProduct(r, handlers.ProductRequest{Name: "Phone", Price: 200, ExternalID: productUUID})
+		if w.Code != http.StatusConflict {
+			t.Errorf("expected 409 Conflict, got %d", w.Code)
+		}
+	})
+
+	t.Run("Invalid product external ID", func(t *testing.T) {
+		w := createProduct(r, handlers.ProductRequest{Name: "Phone", Price: 200, ExternalID: "not-a-uuid"})
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected 400 Bad Request, got %d", w.Code)
+		}
+	})
+
+	movementUUID := newUUID()
+	t.Run("Movement external ID", func(t *testing.T) {
+		w := adjustProduct(r, created.Id, handlers.QuantityAdjustmentRequest{Delta: -1, ExternalID: movementUUID})
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+
+		w = get("/movements/by-external/" + movementUUID)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		var m handlers.MovementResponse
+		if err := json.NewDecoder(w.Body).Decode(&m); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if m.ProductID != created.Id || m.Delta != -1 {
+			t.Errorf("unexpected movement: %+v", m)
+		}
+	})
+
+	t.Run("Duplicate movement external ID", func(t *testing.T) {
+		w := adjustProduct(r, created.Id, handlers.QuantityAdjustmentRequest{Delta: -1, ExternalID: movementUUID})
+		if w.Code != http.StatusConflict {
+			t.Errorf("expected 409 Conflict, got %d", w.Code)
+		}
+	})
+
+	cases := []struct {
+		name string
+		path string
+		code int
+	}{
+		{"Unknown product", "/products/by-external/" + newUUID(), http.StatusNotFound},
+		{"Malformed product ID", "/products/by-external/123", http.StatusBadRequest},
+		{"Unknown movement", "/movements/by-external/" + newUUID(), http.StatusNotFound},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if w := get(c.path); w.Code != c.code {
+				t.Errorf("expected %d, got %d", c.code, w.Code)
+			}
+		})
+	}
+}
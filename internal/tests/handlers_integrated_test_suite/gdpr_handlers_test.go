@@ -0,0 +1,103 @@
+package handlers_integrated_test_suite
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+)
+
+func TestGDPRHandlers(t *testing.T) {
+	clearAllUsersExceptAdmin()
+	r := router.NewRouter()
+
+	userToken, err := roleToken(r, "gdpr-subject", "user")
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	t.Run("Export own personal data", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPut, "/me/timezone", strings.NewReader(`{"timezone": "Europe/Madrid"}`))
+		req.Header.Set("Authorization", "Bearer "+userToken)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code >= 300 {
+			t.Fatalf("failed to set the timezone: %d %s", w.Code, w.Body.String())
+		}
+
+		req = httptest.NewRequest(http.MethodGet, "/me/data-export", nil)
+		req.Header.Set("Authorization", "Bearer "+userToken)
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d", w.Code)
+		}
+		var export handlers.PersonalDataExport
+		if err := json.NewDecoder(w.Body).Decode(&export); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if export.Profile.Username != "gdpr-subject" || export.Profile.Role != "user" || export.Profile.Timezone != "Europe/Madrid" {
+			t.Errorf("unexpected profile: %+v", export.Profile)
+		}
+		if len(export.Sessions) == 0 {
+			t.Error("expected at least one session")
+		}
+	})
+
+	t.Run("Erase personal data", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodDelete, "/admin/users/gdpr-subject/personal-data", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d", w.Code)
+		}
+		var resp map[string]string
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if !strings.HasPrefix(resp["pseudonym"], "deleted-user-") {
+			t.Errorf("unexpected pseudonym %q", resp["pseudonym"])
+		}
+
+		exists, err := userExists("gdpr-subject")
+		if err != nil {
+			t.Fatalf("failed to check user: %v", err)
+		}
+		if exists {
+			t.Error("expected username to be gone after erasure")
+		}
+	})
+
+	t.Run("Audit chain still verifies after erasure", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/admin/audit/verify", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		var result handlers.AuditVerificationResult
+		if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if !result.Valid {
+			t.Errorf("expected valid chain, got %+v", result)
+		}
+	})
+
+	t.Run("Erase unknown user", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodDelete, "/admin/users/nobody/personal-data", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("expected 404 Not Found, got %d", w.Code)
+		}
+	})
+}
@@ -0,0 +1,87 @@
+package handlers_integrated_test_suite
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	mw "github.com/rogerio-castellano/inventory-tracker/internal/http/middleware"
+	rl "github.com/rogerio-castellano/inventory-tracker/internal/http/rate_limiter"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+	"github.com/rogerio-castellano/inventory-tracker/internal/redissvc"
+)
+
+func TestRateLimiterCircuitBreaker(t *testing.T) {
+	r := router.NewRouter()
+	handlers.Rdb.FlushDB(handlers.Ctx)
+
+	// Only the limiter loses Redis; handlers keep theirs, so logins still work.
+	down := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1})
+	mw.SetRedisService(redissvc.NewRedisService(down, context.Background()))
+	rl.ResetBreakers()
+	t.Cleanup(func() {
+		mw.SetRedisService(redissvc.NewRedisService(handlers.Rdb, handlers.Ctx))
+		_ = rl.SetFailMode(rl.FailOpen)
+		rl.ResetBreakers()
+		down.Close()
+	})
+
+	login := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(`{"username":"admin","password":"secret"}`))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	readyz := func(t *testing.T) (int, handlers.ReadinessResponse) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		var resp handlers.ReadinessResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return w.Code, resp
+	}
+
+	t.Run("Ready before any failure", func(t *testing.T) {
+		if code, resp := readyz(t); code != http.StatusOK || resp.Status != "ready" || resp.RateLimiter.Degraded {
+			t.Errorf("unexpected readiness %d: %+v", code, resp)
+		}
+	})
+
+	t.Run("Fails open", func(t *testing.T) {
+		for range 10 {
+			if w := login(); w.Code != http.StatusOK {
+				t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+			}
+		}
+		code, resp := readyz(t)
+		if code != http.StatusOK || resp.Status != "degraded" || !slices.Contains(resp.RateLimiter.OpenRoutes, "login") {
+			t.Errorf("unexpected readiness %d: %+v", code, resp)
+		}
+	})
+
+	t.Run("Fails closed", func(t *testing.T) {
+		if err := rl.SetFailMode(rl.FailClosed); err != nil {
+			t.Fatal(err)
+		}
+		if w := login(); w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") == "" {
+			t.Errorf("expected 503 with Retry-After, got %d", w.Code)
+		}
+		if code, resp := readyz(t); code != http.StatusServiceUnavailable || resp.Status != "unavailable" {
+			t.Errorf("unexpected readiness %d: %+v", code, resp)
+		}
+	})
+
+	t.Run("Invalid fail mode", func(t *testing.T) {
+		if err := rl.SetFailMode("sideways"); err == nil {
+			t.Error("expected an error")
+		}
+	})
+}
@@ -1,8 +1,10 @@
 package handlers_integrated_test_suite
 
 import (
+	"archive/zip"
 	"bytes"
 	"encoding/json"
+	"io"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
@@ -103,6 +105,9 @@ Keyboard,45.00,5,1`
 		if !strings.Contains(resp.Errors[0].Description, wantErrorContains) {
 			t.Errorf("expected error to constains %s , got %s", wantErrorContains, resp.Errors[0].Description)
 		}
+		if resp.Errors[0].Code != handlers.ErrCodeDuplicateName {
+			t.Errorf("expected code %q, got %q", handlers.ErrCodeDuplicateName, resp.Errors[0].Code)
+		}
 	})
 
 	t.Run("File with a duplicated product (Mouse) providing explicitly the skip mode", func(t *testing.T) {
@@ -196,6 +201,7 @@ Monitor,99.0,1,1`
 
 		// Check updated product
 		get := httptest.NewRequest(http.MethodGet, "/products", nil)
+		get.Header.Set("Authorization", "Bearer "+token)
 		getW := httptest.NewRecorder()
 		r.ServeHTTP(getW, get)
 
@@ -237,6 +243,11 @@ func TestImportProductsHandler_InvalidFields(t *testing.T) {
 			payload:        "InvalidPrice,1,0,-1\n",
 			expectedErrors: []string{"invalid threshold"},
 		},
+		{
+			name:           "Formula in name",
+			payload:        "\"=HYPERLINK(\"\"http://example.com\"\")\",1,3,1\n",
+			expectedErrors: []string{"name must not start with"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -296,3 +307,215 @@ Keyboard,45.00,5,1
 		})
 	}
 }
+
+func TestImportExportCatalogColumns(t *testing.T) {
+	t.Cleanup(clearAllProducts)
+	r := router.NewRouter()
+
+	importCSV := func(data, mode string) handlers.ImportProductsResult {
+		var buf bytes.Buffer
+		writer := multipart.NewWriter(&buf)
+		part, err := writer.CreateFormFile("file", "products.csv")
+		if err != nil {
+			t.Fatalf("fail to create form file: %v", err)
+		}
+		if _, err := part.Write([]byte(data)); err != nil {
+			t.Fatalf("fail to write file: %v", err)
+		}
+		writer.Close()
+
+		req := httptest.NewRequest(http.MethodPost, "/products/import?mode="+mode, &buf)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		var resp handlers.ImportProductsResult
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return resp
+	}
+
+	t.Run("Columns in any order with extended fields", func(t *testing.T) {
+		resp := importCSV(`sku,name,quantity,price,category,status,max_quantity
+SKU-1,Drill,3,89.90,Tools,active,10
+SKU-2,Saw,1,19.90,Tools,discontinued,0`, "skip")
+		if resp.ImportedProductsCount != 2 || len(resp.Errors) != 0 {
+			t.Fatalf("expected 2 imported and no errors, got %d and %v", resp.ImportedProductsCount, resp.Errors)
+		}
+
+		p, err := productRepo.GetByName("Saw")
+		if err != nil {
+			t.Fatalf("failed to fetch product: %v", err)
+		}
+		if p.SKU != "SKU-2" || p.Category != "Tools" || p.Status != "discontinued" {
+			t.Errorf("unexpected product: %+v", p)
+		}
+	})
+
+	t.Run("Update keeps columns missing from the file", func(t *testing.T) {
+		resp := importCSV(`name,price,quantity
+Drill,99.90,4`, "update")
+		if resp.ImportedProductsCount != 1 {
+			t.Fatalf("expected 1 update, got %d: %v", resp.ImportedProductsCount, resp.Errors)
+		}
+
+		p, _ := productRepo.GetByName("Drill")
+		if p.Price != 99.90 || p.SKU != "SKU-1" || p.MaxQuantity != 10 {
+			t.Errorf("unexpected product: %+v", p)
+		}
+	})
+
+	t.Run("Invalid status", func(t *testing.T) {
+		resp := importCSV(`name,price,quantity,status
+Hammer,9.90,1,lost`, "skip")
+		if len(resp.Errors) != 1 || !strings.Contains(resp.Errors[0].Description, "invalid status") {
+			t.Errorf("expected invalid status error, got %v", resp.Errors)
+		}
+	})
+
+	t.Run("Export round-trips the columns", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/products/export", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d", w.Code)
+		}
+		lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+		if lines[0] != "name,price,quantity,threshold,category,sku,barcode,supplier,max_quantity,status,cost,tax_class,description,brand,manufacturer,weight,length,width,height" {
+			t.Errorf("unexpected header: %s", lines[0])
+		}
+		if len(lines) != 3 {
+			t.Errorf("expected 2 products, got %d lines", len(lines))
+		}
+	})
+
+	t.Run("Export selected columns", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/products/export?columns=sku,name", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d", w.Code)
+		}
+		if !strings.HasPrefix(w.Body.String(), "sku,name\nSKU-") {
+			t.Errorf("unexpected export: %s", w.Body.String())
+		}
+	})
+
+	t.Run("Export unknown column", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/products/export?columns=name,password", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected 400 Bad Request, got %d", w.Code)
+		}
+	})
+}
+
+func TestExportNeutralizesFormulas(t *testing.T) {
+	t.Cleanup(clearAllProducts)
+	r := router.NewRouter()
+	createProduct(r, handlers.ProductRequest{Name: "@SUM(1+1)", Price: 10})
+
+	req := httptest.NewRequest(http.MethodGet, "/products/export?columns=name,quantity", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 OK, got %d", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), "'@SUM(1+1),0") {
+		t.Errorf("expected the formula to be quoted as text, got %q", w.Body.String())
+	}
+}
+
+func TestExportProductFormats(t *testing.T) {
+	t.Cleanup(clearAllProducts)
+	r := router.NewRouter()
+	for _, p := range []handlers.ProductRequest{
+		{Name: "Drill", Price: 89.9, Quantity: 3, Threshold: 5, SKU: "SKU-1"},
+		{Name: "Saw & Blade", Price: 19.9, Quantity: 8, Threshold: 2, SKU: "SKU-2"},
+	} {
+		if w := createProduct(r, p); w.Code != http.StatusCreated {
+			t.Fatalf("failed to create product %s: %d", p.Name, w.Code)
+		}
+	}
+
+	export := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/products/export?"+query, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("JSON", func(t *testing.T) {
+		w := export("format=json&columns=sku,name,price,weight&sort=name&order=desc")
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		if got := w.Header().Get("Content-Disposition"); got != `attachment; filename="products.json"` {
+			t.Errorf("unexpected Content-Disposition %q", got)
+		}
+		if !strings.HasPrefix(w.Body.String(), `[{"sku":"SKU-2","name":"Saw & Blade","price":19.9,"weight":null}`) {
+			t.Errorf("expected keys in column order with typed values, got %s", w.Body.String())
+		}
+		var rows []map[string]any
+		if err := json.Unmarshal(w.Body.Bytes(), &rows); err != nil || len(rows) != 2 {
+			t.Errorf("expected a JSON array of 2 products, got %v (%v)", rows, err)
+		}
+	})
+
+	t.Run("Excel", func(t *testing.T) {
+		w := export("format=xlsx&columns=name,quantity")
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		if got := w.Header().Get("Content-Type"); got != "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet" {
+			t.Errorf("unexpected Content-Type %q", got)
+		}
+		zr, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+		if err != nil {
+			t.Fatalf("expected a ZIP archive: %v", err)
+		}
+		var sheet string
+		for _, f := range zr.File {
+			if f.Name == "xl/worksheets/sheet1.xml" {
+				rc, _ := f.Open()
+				raw, _ := io.ReadAll(rc)
+				rc.Close()
+				sheet = string(raw)
+			}
+		}
+		for _, want := range []string{">name</t>", ">Drill</t>", ">Saw &amp; Blade</t>", `<c r="B2"><v>3</v></c>`} {
+			if !strings.Contains(sheet, want) {
+				t.Errorf("expected the sheet to contain %s, got %s", want, sheet)
+			}
+		}
+	})
+
+	t.Run("Filtered", func(t *testing.T) {
+		w := export("low_stock=true&columns=name")
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d", w.Code)
+		}
+		if got := w.Body.String(); got != "name\nDrill\n" {
+			t.Errorf("expected only the low-stock Drill, got %q", got)
+		}
+	})
+
+	for _, query := range []string{"format=pdf", "low_stock=maybe", "sort=cost"} {
+		t.Run("Invalid "+query, func(t *testing.T) {
+			if w := export(query); w.Code != http.StatusBadRequest {
+				t.Errorf("expected 400 Bad Request, got %d", w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package handlers_integrated_test_suite
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+	"github.com/rogerio-castellano/inventory-tracker/internal/jobs"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+func TestListIncidentsHandler(t *testing.T) {
+	t.Cleanup(clearIncidents)
+	r := router.NewRouter()
+
+	list := func(query string) []models.Incident {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/admin/incidents"+query, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		var incidents []models.Incident
+		if err := json.NewDecoder(w.Body).Decode(&incidents); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return incidents
+	}
+
+	jobs.Exhausted(jobs.Failure{Job: "daily_snapshot", Reference: "snapshot of 2025-09-01", Attempts: 3, Err: errors.New("database is down")})
+	time.Sleep(10 * time.Millisecond)
+	jobs.Exhausted(jobs.Failure{Job: "expiry_digest", Reference: "digest of 2025-09-02", Attempts: 3, Err: errors.New("smtp refused")})
+
+	t.Run("Newest first", func(t *testing.T) {
+		incidents := list("")
+		if len(incidents) != 2 {
+			t.Fatalf("expected 2 incidents, got %+v", incidents)
+		}
+		got := incidents[0]
+		if got.Job != "expiry_digest" || got.Reference != "digest of 2025-09-02" || got.Attempts != 3 || got.Error != "smtp refused" {
+			t.Errorf("unexpected incident: %+v", got)
+		}
+	})
+
+	t.Run("Filter by job", func(t *testing.T) {
+		incidents := list("?job=daily_snapshot")
+		if len(incidents) != 1 || incidents[0].Reference != "snapshot of 2025-09-01" {
+			t.Errorf("expected only the snapshot incident, got %+v", incidents)
+		}
+	})
+
+	t.Run("Limit", func(t *testing.T) {
+		if incidents := list("?limit=1"); len(incidents) != 1 {
+			t.Errorf("expected 1 incident, got %d", len(incidents))
+		}
+	})
+
+	t.Run("Invalid limit", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/admin/incidents?limit=0", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected 400 Bad Request, got %d", w.Code)
+		}
+	})
+}
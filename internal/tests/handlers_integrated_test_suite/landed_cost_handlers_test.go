@@ -0,0 +1,162 @@
+package handlers_integrated_test_suite
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+func TestLandedCostHandlers(t *testing.T) {
+	t.Cleanup(func() {
+		clearASNs()
+		clearAllProducts()
+	})
+	r := router.NewRouter()
+
+	send := func(method, path string, body any) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		if body != nil {
+			_ = json.NewEncoder(&buf).Encode(body)
+		}
+		req := httptest.NewRequest(method, path, &buf)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	newProduct := func(name string, cost float64) int {
+		w := createProduct(r, handlers.ProductRequest{Name: name, Price: 50, Cost: cost, Quantity: 5})
+		var p handlers.ProductResponse
+		if err := json.NewDecoder(w.Body).Decode(&p); err != nil {
+			t.Fatalf("failed to decode product: %v", err)
+		}
+		return p.Id
+	}
+	costOf := func(t *testing.T, id int) float64 {
+		t.Helper()
+		var p handlers.ProductResponse
+		if err := json.NewDecoder(send(http.MethodGet, fmt.Sprintf("/products/%d", id), nil).Body).Decode(&p); err != nil {
+			t.Fatalf("failed to decode product: %v", err)
+		}
+		return p.Cost
+	}
+	newASN := func(lines ...handlers.ASNLineRequest) string {
+		var a models.ASN
+		if err := json.NewDecoder(send(http.MethodPost, "/asns", handlers.ASNRequest{Reference: "PO-200", Supplier: "Acme", Lines: lines}).Body).Decode(&a); err != nil {
+			t.Fatalf("failed to decode ASN: %v", err)
+		}
+		return fmt.Sprintf("/asns/%d", a.ID)
+	}
+	lamps := newProduct("Lamps", 10)
+	chairs := newProduct("Chairs", 20)
+
+	asnPath := newASN(handlers.ASNLineRequest{ProductID: lamps, Quantity: 5}, handlers.ASNLineRequest{ProductID: chairs, Quantity: 5})
+	costsPath := asnPath + "/landed-costs"
+
+	t.Run("Receipt must be received first", func(t *testing.T) {
+		w := send(http.MethodPost, costsPath, handlers.LandedCostRequest{Description: "Freight", TotalCost: 30, Method: models.LandedCostByValue})
+		if w.Code != http.StatusConflict {
+			t.Errorf("expected 409 Conflict, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	items := []handlers.ReceivedItem{{ProductID: lamps, Quantity: 5}, {ProductID: chairs, Quantity: 5}}
+	if w := send(http.MethodPost, asnPath+"/receive", handlers.ReceiveASNRequest{Items: items}); w.Code != http.StatusOK {
+		t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+	}
+
+	create := func(t *testing.T, req handlers.LandedCostRequest) models.LandedCost {
+		t.Helper()
+		w := send(http.MethodPost, costsPath, req)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected 201 Created, got %d: %s", w.Code, w.Body.String())
+		}
+		var lc models.LandedCost
+		if err := json.NewDecoder(w.Body).Decode(&lc); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return lc
+	}
+
+	t.Run("Allocate by value", func(t *testing.T) {
+		// 50 of lamps and 100 of chairs were received, so they carry a third and two thirds.
+		lc := create(t, handlers.LandedCostRequest{Description: "Freight", TotalCost: 30, Method: models.LandedCostByValue})
+		if len(lc.Allocations) != 2 || lc.Allocations[0].AllocatedCost != 10 || lc.Allocations[1].AllocatedCost != 20 {
+			t.Errorf("unexpected allocations: %+v", lc.Allocations)
+		}
+		// Each share is spread over the 10 units on hand.
+		if cost := costOf(t, lamps); cost != 11 {
+			t.Errorf("expected lamps to cost 11, got %v", cost)
+		}
+		if cost := costOf(t, chairs); cost != 22 {
+			t.Errorf("expected chairs to cost 22, got %v", cost)
+		}
+	})
+
+	t.Run("Allocate by weight", func(t *testing.T) {
+		lc := create(t, handlers.LandedCostRequest{Description: "Duty", TotalCost: 15, Method: models.LandedCostByWeight, Weights: []handlers.UnitWeightRequest{
+			{ProductID: lamps, UnitWeight: 2},
+			{ProductID: chairs, UnitWeight: 1},
+		}})
+		if len(lc.Allocations) != 2 || lc.Allocations[0].AllocatedCost != 10 || lc.Allocations[1].AllocatedCost != 5 {
+			t.Errorf("unexpected allocations: %+v", lc.Allocations)
+		}
+		if cost := costOf(t, lamps); cost != 12 {
+			t.Errorf("expected lamps to cost 12, got %v", cost)
+		}
+		if cost := costOf(t, chairs); cost != 22.5 {
+			t.Errorf("expected chairs to cost 22.5, got %v", cost)
+		}
+	})
+
+	t.Run("Shares add up to the total", func(t *testing.T) {
+		lc := create(t, handlers.LandedCostRequest{Description: "Insurance", TotalCost: 10, Method: models.LandedCostByWeight, Weights: []handlers.UnitWeightRequest{
+			{ProductID: lamps, UnitWeight: 1},
+			{ProductID: chairs, UnitWeight: 2},
+		}})
+		var sum float64
+		for _, a := range lc.Allocations {
+			sum += a.AllocatedCost
+		}
+		if fmt.Sprintf("%.2f", sum) != "10.00" {
+			t.Errorf("expected the shares to add up to 10, got %v", sum)
+		}
+	})
+
+	t.Run("List landed costs", func(t *testing.T) {
+		var costs []models.LandedCost
+		if err := json.NewDecoder(send(http.MethodGet, costsPath, nil).Body).Decode(&costs); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(costs) != 3 || costs[0].Description != "Freight" {
+			t.Errorf("unexpected landed costs: %+v", costs)
+		}
+	})
+
+	cases := []struct {
+		name string
+		path string
+		body handlers.LandedCostRequest
+		code int
+	}{
+		{"Missing description", costsPath, handlers.LandedCostRequest{TotalCost: 5, Method: models.LandedCostByValue}, http.StatusBadRequest},
+		{"Zero total", costsPath, handlers.LandedCostRequest{Description: "Freight", Method: models.LandedCostByValue}, http.StatusBadRequest},
+		{"Unknown method", costsPath, handlers.LandedCostRequest{Description: "Freight", TotalCost: 5, Method: "volume"}, http.StatusBadRequest},
+		{"Missing weight", costsPath, handlers.LandedCostRequest{Description: "Freight", TotalCost: 5, Method: models.LandedCostByWeight, Weights: []handlers.UnitWeightRequest{{ProductID: lamps, UnitWeight: 1}}}, http.StatusBadRequest},
+		{"Unknown ASN", "/asns/999999/landed-costs", handlers.LandedCostRequest{Description: "Freight", TotalCost: 5, Method: models.LandedCostByValue}, http.StatusNotFound},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if w := send(http.MethodPost, c.path, c.body); w.Code != c.code {
+				t.Errorf("expected %d, got %d: %s", c.code, w.Code, w.Body.String())
+			}
+		})
+	}
+}
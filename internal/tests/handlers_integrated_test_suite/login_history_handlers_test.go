@@ -0,0 +1,105 @@
+package handlers_integrated_test_suite
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+func TestLoginHistory(t *testing.T) {
+	t.Cleanup(func() {
+		clearLoginEvents()
+		clearAllUsersExceptAdmin()
+	})
+	r := router.NewRouter()
+
+	login := func(username, password string) *httptest.ResponseRecorder {
+		raw, _ := json.Marshal(handlers.CredentialsRequest{Username: username, Password: password})
+		req := httptest.NewRequest(http.MethodPost, "/login", bytes.NewReader(raw))
+		req.Header.Set("User-Agent", "history-test")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	history := func(path, bearer string) (*httptest.ResponseRecorder, handlers.LoginHistoryPage) {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer "+bearer)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		var page handlers.LoginHistoryPage
+		if w.Code == http.StatusOK {
+			if err := json.NewDecoder(w.Body).Decode(&page); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+		}
+		return w, page
+	}
+
+	userToken, err := roleToken(r, "historian", "user")
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	if w := login("historian", "wrong-password"); w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 Unauthorized, got %d", w.Code)
+	}
+	if w := login("historian", "secret-password"); w.Code != http.StatusOK {
+		t.Fatalf("expected 200 OK, got %d", w.Code)
+	}
+	login("nobody-here", "secret-password")
+
+	t.Run("Own history, newest first", func(t *testing.T) {
+		w, page := history("/me/logins", userToken)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d", w.Code)
+		}
+		if len(page.Logins) != 3 {
+			t.Fatalf("expected 3 logins, got %+v", page.Logins)
+		}
+		latest, failed := page.Logins[0], page.Logins[1]
+		if latest.Outcome != models.LoginOutcomeSuccess || latest.UserAgent != "history-test" || latest.IPAddress == "" {
+			t.Errorf("unexpected latest login: %+v", latest)
+		}
+		if failed.Outcome != models.LoginOutcomeFailure || failed.Reason != models.LoginReasonInvalidPassword {
+			t.Errorf("unexpected failed login: %+v", failed)
+		}
+	})
+
+	t.Run("Pagination", func(t *testing.T) {
+		_, page := history("/me/logins?limit=2", userToken)
+		if len(page.Logins) != 2 || !page.HasMore {
+			t.Errorf("expected a full first page, got %+v", page)
+		}
+		_, page = history("/me/logins?limit=2&offset=2", userToken)
+		if len(page.Logins) != 1 || page.HasMore {
+			t.Errorf("expected a last page of 1, got %+v", page)
+		}
+	})
+
+	t.Run("Admin sees attempts on unknown users", func(t *testing.T) {
+		w, page := history("/admin/users/nobody-here/logins", token)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d", w.Code)
+		}
+		if len(page.Logins) != 1 || page.Logins[0].Reason != models.LoginReasonUnknownUser {
+			t.Errorf("unexpected logins: %+v", page.Logins)
+		}
+	})
+
+	t.Run("Admins only", func(t *testing.T) {
+		if w, _ := history("/admin/users/historian/logins", userToken); w.Code != http.StatusForbidden {
+			t.Errorf("expected 403 Forbidden, got %d", w.Code)
+		}
+	})
+
+	t.Run("Invalid limit", func(t *testing.T) {
+		if w, _ := history("/me/logins?limit=0", userToken); w.Code != http.StatusBadRequest {
+			t.Errorf("expected 400 Bad Request, got %d", w.Code)
+		}
+	})
+}
@@ -0,0 +1,118 @@
+package handlers_integrated_test_suite
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+)
+
+func TestLotHandlers(t *testing.T) {
+	t.Cleanup(clearAllProducts)
+	r := router.NewRouter()
+
+	w := createProduct(r, handlers.ProductRequest{Name: "Yogurt", Price: 1.5, Quantity: 40})
+	var product handlers.ProductResponse
+	if err := json.NewDecoder(w.Body).Decode(&product); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	base := fmt.Sprintf("/products/%d/lots", product.Id)
+
+	send := func(method, url string, body any) *httptest.ResponseRecorder {
+		payload, _ := json.Marshal(body)
+		req := httptest.NewRequest(method, url, bytes.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	day := func(days int) string { return time.Now().AddDate(0, 0, days).Format(time.DateOnly) }
+
+	var soon models.Lot
+	t.Run("Create lots", func(t *testing.T) {
+		w := send(http.MethodPost, base, handlers.LotRequest{LotNumber: "L-001", Quantity: 10, ExpiresAt: day(5)})
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected 201 Created, got %d: %s", w.Code, w.Body.String())
+		}
+		if err := json.NewDecoder(w.Body).Decode(&soon); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if w := send(http.MethodPost, base, handlers.LotRequest{LotNumber: "L-002", Quantity: 30, ExpiresAt: day(90)}); w.Code != http.StatusCreated {
+			t.Fatalf("expected 201 Created, got %d", w.Code)
+		}
+	})
+
+	t.Run("Duplicate lot number", func(t *testing.T) {
+		w := send(http.MethodPost, base, handlers.LotRequest{LotNumber: "L-001", Quantity: 1, ExpiresAt: day(5)})
+		if w.Code != http.StatusConflict {
+			t.Errorf("expected 409 Conflict, got %d", w.Code)
+		}
+	})
+
+	t.Run("Missing expiry", func(t *testing.T) {
+		w := send(http.MethodPost, base, handlers.LotRequest{LotNumber: "L-003", Quantity: 1})
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected 400 Bad Request, got %d", w.Code)
+		}
+	})
+
+	getReport := func(within string) []handlers.ExpiringLot {
+		req := httptest.NewRequest(http.MethodGet, "/reports/expiring?within="+within, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var report []handlers.ExpiringLot
+		if err := json.NewDecoder(w.Body).Decode(&report); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return report
+	}
+
+	t.Run("Expiring report", func(t *testing.T) {
+		report := getReport("30d")
+		if len(report) != 1 || report[0].LotNumber != "L-001" || report[0].ProductName != "Yogurt" || report[0].Expired {
+			t.Errorf("expected only lot L-001 in a 30 day window, got %+v", report)
+		}
+		if report := getReport("120d"); len(report) != 2 {
+			t.Errorf("expected both lots in a 120 day window, got %+v", report)
+		}
+	})
+
+	t.Run("Dashboard counts expiring lots", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/metrics/dashboard", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		var m repo.Metrics
+		if err := json.NewDecoder(w.Body).Decode(&m); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if m.ExpiringSoonCount != 1 {
+			t.Errorf("expected expiring_soon_count 1, got %d", m.ExpiringSoonCount)
+		}
+	})
+
+	t.Run("Used up lots leave the report", func(t *testing.T) {
+		w := send(http.MethodPut, fmt.Sprintf("%s/%d", base, soon.ID), handlers.LotQuantityRequest{Quantity: 0})
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		if report := getReport("30d"); len(report) != 0 {
+			t.Errorf("expected an empty report, got %+v", report)
+		}
+	})
+}
@@ -0,0 +1,134 @@
+package handlers_integrated_test_suite
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+func TestMappingHandlers(t *testing.T) {
+	t.Cleanup(clearAllProducts)
+	r := router.NewRouter()
+
+	productID := func(name string) int {
+		w := createProduct(r, handlers.ProductRequest{Name: name, Price: 10, Quantity: 1})
+		var p handlers.ProductResponse
+		if err := json.NewDecoder(w.Body).Decode(&p); err != nil {
+			t.Fatalf("failed to decode product: %v", err)
+		}
+		return p.Id
+	}
+	shirt := productID("Shirt")
+	hat := productID("Hat")
+
+	send := func(method, path string, body any) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		if body != nil {
+			_ = json.NewEncoder(&buf).Encode(body)
+		}
+		req := httptest.NewRequest(method, path, &buf)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	var created models.ProductMapping
+	t.Run("Create mapping", func(t *testing.T) {
+		w := send(http.MethodPost, "/integrations/mappings", handlers.MappingRequest{ProductID: shirt, System: " SAP ", ExternalCode: "MAT-1001"})
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected 201 Created, got %d: %s", w.Code, w.Body.String())
+		}
+		if err := json.NewDecoder(w.Body).Decode(&created); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if created.System != "sap" || created.ExternalCode != "MAT-1001" {
+			t.Errorf("unexpected mapping: %+v", created)
+		}
+	})
+
+	t.Run("Bulk upload re-points existing codes", func(t *testing.T) {
+		w := send(http.MethodPost, "/integrations/mappings/bulk", []handlers.MappingRequest{
+			{ProductID: hat, System: "sap", ExternalCode: "MAT-1001"},
+			{ProductID: shirt, System: "shopify", ExternalCode: "gid://shopify/ProductVariant/42"},
+		})
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+
+		w = send(http.MethodGet, "/integrations/mappings/"+fmt.Sprint(created.ID), nil)
+		var m models.ProductMapping
+		if err := json.NewDecoder(w.Body).Decode(&m); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if m.ProductID != hat {
+			t.Errorf("expected MAT-1001 to map to product %d, got %d", hat, m.ProductID)
+		}
+	})
+
+	t.Run("Resolve external code", func(t *testing.T) {
+		w := send(http.MethodGet, "/integrations/mappings/resolve?system=sap&code=MAT-1001", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		var res handlers.MappingResolution
+		if err := json.NewDecoder(w.Body).Decode(&res); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if res.Product.Id != hat {
+			t.Errorf("expected product %d, got %d", hat, res.Product.Id)
+		}
+	})
+
+	t.Run("Resolve internal product", func(t *testing.T) {
+		w := send(http.MethodGet, fmt.Sprintf("/integrations/mappings/resolve?system=shopify&product_id=%d", shirt), nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		var res handlers.MappingResolution
+		if err := json.NewDecoder(w.Body).Decode(&res); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(res.Mappings) != 1 || res.Mappings[0].ExternalCode != "gid://shopify/ProductVariant/42" {
+			t.Errorf("unexpected mappings: %+v", res.Mappings)
+		}
+	})
+
+	t.Run("Delete mapping", func(t *testing.T) {
+		if w := send(http.MethodDelete, "/integrations/mappings/"+fmt.Sprint(created.ID), nil); w.Code != http.StatusNoContent {
+			t.Fatalf("expected 204 No Content, got %d", w.Code)
+		}
+		if w := send(http.MethodGet, "/integrations/mappings/resolve?system=sap&code=MAT-1001", nil); w.Code != http.StatusNotFound {
+			t.Errorf("expected 404 after delete, got %d", w.Code)
+		}
+	})
+
+	cases := []struct {
+		name   string
+		method string
+		path   string
+		body   any
+		code   int
+	}{
+		{"Duplicate code", http.MethodPost, "/integrations/mappings", handlers.MappingRequest{ProductID: hat, System: "shopify", ExternalCode: "gid://shopify/ProductVariant/42"}, http.StatusConflict},
+		{"Unknown product", http.MethodPost, "/integrations/mappings", handlers.MappingRequest{ProductID: 999999, System: "sap", ExternalCode: "MAT-9"}, http.StatusNotFound},
+		{"Missing system", http.MethodPost, "/integrations/mappings", handlers.MappingRequest{ProductID: hat, ExternalCode: "MAT-9"}, http.StatusBadRequest},
+		{"Invalid bulk item", http.MethodPost, "/integrations/mappings/bulk", []handlers.MappingRequest{{ProductID: hat, System: "sap"}}, http.StatusBadRequest},
+		{"Resolve needs one direction", http.MethodGet, "/integrations/mappings/resolve?system=sap", nil, http.StatusBadRequest},
+		{"Unknown mapping", http.MethodGet, "/integrations/mappings/999999", nil, http.StatusNotFound},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if w := send(c.method, c.path, c.body); w.Code != c.code {
+				t.Errorf("expected %d, got %d: %s", c.code, w.Code, w.Body.String())
+			}
+		})
+	}
+}
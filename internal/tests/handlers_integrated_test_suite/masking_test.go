@@ -0,0 +1,106 @@
+package handlers_integrated_test_suite
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/auth"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+)
+
+func TestPriceMasking(t *testing.T) {
+	t.Cleanup(func() {
+		clearAllProducts()
+		clearAllUsersExceptAdmin()
+		auth.SetRolePermissions(nil)
+	})
+	r := router.NewRouter()
+
+	w := createProduct(r, handlers.ProductRequest{Name: "Pallet", Price: 80.0, Quantity: 4})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("failed to create product: %d", w.Code)
+	}
+	var created handlers.ProductResponse
+	if err := json.NewDecoder(w.Body).Decode(&created); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	warehouseToken, err := roleToken(r, "picker", "warehouse")
+	if err != nil {
+		t.Fatalf("failed to get warehouse token: %v", err)
+	}
+
+	getProduct := func(authToken string) map[string]any {
+		req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/products/%d", created.Id), nil)
+		if authToken != "" {
+			req.Header.Set("Authorization", "Bearer "+authToken)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d", w.Code)
+		}
+		var body map[string]any
+		if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return body
+	}
+
+	t.Run("Warehouse role does not see price", func(t *testing.T) {
+		body := getProduct(warehouseToken)
+		if _, ok := body["price"]; ok {
+			t.Errorf("expected price to be hidden, got %v", body["price"])
+		}
+		if body["quantity"] != float64(4) {
+			t.Errorf("expected quantity 4, got %v", body["quantity"])
+		}
+	})
+
+	t.Run("User role sees price", func(t *testing.T) {
+		userToken, err := roleToken(r, "clerk", "user")
+		if err != nil {
+			t.Fatalf("failed to get user token: %v", err)
+		}
+		if body := getProduct(userToken); body["price"] != 80.0 {
+			t.Errorf("expected price 80, got %v", body["price"])
+		}
+	})
+
+	t.Run("Admin sees price", func(t *testing.T) {
+		body := getProduct(token)
+		if body["price"] != 80.0 {
+			t.Errorf("expected price 80, got %v", body["price"])
+		}
+	})
+
+	t.Run("Anonymous callers do not see price", func(t *testing.T) {
+		body := getProduct("")
+		if _, ok := body["price"]; ok {
+			t.Errorf("expected price to be hidden, got %v", body["price"])
+		}
+	})
+
+	t.Run("Malformed token is treated as a guest", func(t *testing.T) {
+		for _, bogus := range []string{"x", "not.a.jwt"} {
+			body := getProduct(bogus)
+			if _, ok := body["price"]; ok {
+				t.Errorf("token %q: expected price to be hidden, got %v", bogus, body["price"])
+			}
+		}
+	})
+
+	t.Run("Pricing reports require pricing:read", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/reports/consignment-settlement", nil)
+		req.Header.Set("Authorization", "Bearer "+warehouseToken)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusForbidden {
+			t.Errorf("expected 403 Forbidden, got %d", w.Code)
+		}
+	})
+}
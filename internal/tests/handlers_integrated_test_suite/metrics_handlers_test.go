@@ -1,13 +1,18 @@
 package handlers_integrated_test_suite
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/rogerio-castellano/inventory-tracker/internal/fxrate"
 	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
 	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
 	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
 )
 
@@ -168,6 +173,202 @@ func TestDashboardMetricsHandler_Enhanced(t *testing.T) {
 	}
 }
 
+func TestDashboardMetricsHandler_Currency(t *testing.T) {
+	handlers.SetExchangeRates(fxrate.NewStatic("USD", map[string]float64{"EUR": 0.5}))
+	t.Cleanup(func() {
+		handlers.SetExchangeRates(fxrate.NewStatic("USD", nil))
+		clearAllProducts()
+	})
+	r := router.NewRouter()
+
+	for _, p := range []handlers.ProductRequest{
+		{Name: "Lamp", Price: 10, Quantity: 2, Currency: "eur"},
+		{Name: "Desk", Price: 20, Quantity: 1},
+	} {
+		if w := createProduct(r, p); w.Code != http.StatusCreated {
+			t.Fatalf("failed to create product %s: %d %s", p.Name, w.Code, w.Body.String())
+		}
+	}
+
+	dashboard := func(query string) (repo.Metrics, int) {
+		req := httptest.NewRequest(http.MethodGet, "/metrics/dashboard"+query, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		var m repo.Metrics
+		if w.Code == http.StatusOK {
+			if err := json.NewDecoder(w.Body).Decode(&m); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+		}
+		return m, w.Code
+	}
+
+	cases := []struct {
+		query        string
+		currency     string
+		stockValue   float64
+		averagePrice float64
+	}{
+		{"", "USD", 60, 20},
+		{"?currency=eur", "EUR", 30, 10},
+	}
+	for _, c := range cases {
+		m, code := dashboard(c.query)
+		if code != http.StatusOK {
+			t.Fatalf("%q: expected 200 OK, got %d", c.query, code)
+		}
+		if m.Currency != c.currency || m.TotalStockValue != c.stockValue || m.AveragePrice != c.averagePrice {
+			t.Errorf("%q: expected %v %s at an average of %v, got %v %s at %v", c.query, c.stockValue, c.currency, c.averagePrice, m.TotalStockValue, m.Currency, m.AveragePrice)
+		}
+		if len(m.StockByCurrency) != 2 {
+			t.Errorf("%q: expected a breakdown by 2 currencies, got %+v", c.query, m.StockByCurrency)
+		}
+	}
+
+	if _, code := dashboard("?currency=GBP"); code != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422 for a currency without a rate, got %d", code)
+	}
+	if _, code := dashboard("?currency=euro"); code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an invalid currency, got %d", code)
+	}
+}
+
+func TestDashboardMetricsHandler_Widgets(t *testing.T) {
+	handlers.Rdb.FlushDB(handlers.Ctx)
+	t.Cleanup(clearAllProducts)
+	r := router.NewRouter()
+
+	for _, p := range []handlers.ProductRequest{
+		{Name: "Keyboard", Price: 40, Quantity: 10, Threshold: 5},
+		{Name: "Mouse", Price: 20, Quantity: 1, Threshold: 5},
+	} {
+		if w := createProduct(r, p); w.Code != http.StatusCreated {
+			t.Fatalf("failed to create product %s: %d %s", p.Name, w.Code, w.Body.String())
+		}
+	}
+
+	dashboard := func(query string) (handlers.DashboardWidgets, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodGet, "/metrics/dashboard"+query, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		var widgets handlers.DashboardWidgets
+		if w.Code == http.StatusOK {
+			if err := json.Unmarshal(w.Body.Bytes(), &widgets); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+		}
+		return widgets, w
+	}
+
+	t.Run("Only the widgets asked for", func(t *testing.T) {
+		widgets, w := dashboard("?widgets=low_stock,valuation")
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		if widgets.LowStock == nil || widgets.LowStock.LowStockCount != 1 {
+			t.Errorf("expected 1 low stock product, got %+v", widgets.LowStock)
+		}
+		if widgets.Valuation == nil || widgets.Valuation.TotalStockValue != 420 || widgets.Valuation.Currency != "USD" {
+			t.Errorf("expected a valuation of 420 USD, got %+v", widgets.Valuation)
+		}
+		if widgets.Summary != nil || widgets.TopMovers != nil || widgets.Expiring != nil {
+			t.Errorf("expected only low_stock and valuation, got %s", w.Body.String())
+		}
+		if w.Header().Get("Cache-Control") == "" {
+			t.Error("expected a Cache-Control header")
+		}
+	})
+
+	t.Run("Widgets are cached separately", func(t *testing.T) {
+		if w := createProduct(r, handlers.ProductRequest{Name: "Monitor", Price: 150, Quantity: 1, Threshold: 3}); w.Code != http.StatusCreated {
+			t.Fatalf("failed to create product: %d", w.Code)
+		}
+		widgets, _ := dashboard("?widgets=low_stock,summary")
+		if widgets.LowStock == nil || widgets.LowStock.LowStockCount != 1 {
+			t.Errorf("expected the cached low stock count of 1, got %+v", widgets.LowStock)
+		}
+		if widgets.Summary == nil || widgets.Summary.TotalProducts != 3 {
+			t.Errorf("expected a fresh summary of 3 products, got %+v", widgets.Summary)
+		}
+	})
+
+	t.Run("Unknown widget", func(t *testing.T) {
+		if _, w := dashboard("?widgets=low_stock,weather"); w.Code != http.StatusBadRequest {
+			t.Errorf("expected 400 Bad Request, got %d", w.Code)
+		}
+	})
+}
+
+func TestDashboardMetricsHandler_Trends(t *testing.T) {
+	clearSnapshots()
+	t.Cleanup(func() {
+		clearSnapshots()
+		clearAllProducts()
+	})
+	r := router.NewRouter()
+
+	w := createProduct(r, handlers.ProductRequest{Name: "Kettle", Price: 30, Quantity: 10})
+	var product handlers.ProductResponse
+	if err := json.NewDecoder(w.Body).Decode(&product); err != nil {
+		t.Fatalf("failed to decode product: %v", err)
+	}
+	now := time.Now().UTC()
+	if _, err := movementRepo.Log(models.Movement{ProductID: product.Id, Delta: -4, CreatedAt: now.AddDate(0, 0, -10).Format(time.RFC3339)}); err != nil {
+		t.Fatalf("failed to log movement: %v", err)
+	}
+	if w := adjustProduct(r, product.Id, handlers.QuantityAdjustmentRequest{Delta: 2}); w.Code != http.StatusOK {
+		t.Fatalf("failed to adjust quantity: %d", w.Code)
+	}
+	for _, s := range []struct {
+		age   int
+		value float64
+	}{{15, 80}, {8, 100}, {1, 150}} {
+		_, err := database.Exec(`INSERT INTO inventory_snapshots (label, created_by, scheduled, created_at, product_count, total_quantity, total_value, items)
+			VALUES ('daily', 'system', true, $1, 1, 1, $2, '')`, now.AddDate(0, 0, -s.age), s.value)
+		if err != nil {
+			t.Fatalf("failed to insert snapshot: %v", err)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics/dashboard", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 OK, got %d", w.Code)
+	}
+	var m repo.Metrics
+	if err := json.NewDecoder(w.Body).Decode(&m); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if m.Trends == nil {
+		t.Fatal("expected trends")
+	}
+
+	cases := []struct {
+		name              string
+		trend             *repo.Trend
+		current, previous float64
+		change            float64
+		direction         string
+	}{
+		{"movement volume", m.Trends.MovementVolume, 2, 4, -50, "down"},
+		{"stock value", m.Trends.StockValue, 150, 100, 50, "up"},
+	}
+	for _, c := range cases {
+		tr := c.trend
+		if tr == nil {
+			t.Errorf("expected a %s trend", c.name)
+			continue
+		}
+		if tr.Current != c.current || tr.Previous != c.previous || tr.ChangePercent == nil || *tr.ChangePercent != c.change || tr.Direction != c.direction {
+			t.Errorf("expected %s to go %s from %v to %v (%v%%), got %+v", c.name, c.direction, c.previous, c.current, c.change, tr)
+		}
+	}
+}
+
 func TestForbiddenAccessToNonAdminUser(t *testing.T) {
 	r := router.NewRouter()
 	userToken, err := userRoleToken(r)
@@ -184,3 +385,136 @@ func TestForbiddenAccessToNonAdminUser(t *testing.T) {
 		t.Fatalf("expected 403 Forbidden, got %d", w.Code)
 	}
 }
+
+func TestMarginsHandler(t *testing.T) {
+	t.Cleanup(clearAllProducts)
+	r := router.NewRouter()
+
+	var beansID int
+	for _, p := range []handlers.ProductRequest{
+		{Name: "Beans", Price: 10, Cost: 9, Quantity: 4, Category: "Coffee"},
+		{Name: "Grinder", Price: 50, Cost: 30, Quantity: 2, Category: "Equipment"},
+		{Name: "Filter", Price: 5, Quantity: 100, Category: "Equipment"}, // no cost
+	} {
+		w := createProduct(r, p)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("product creation failed: %d", w.Code)
+		}
+		if p.Name == "Beans" {
+			var resp handlers.ProductResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			beansID = resp.Id
+		}
+	}
+	adjustProduct(r, beansID, handlers.QuantityAdjustmentRequest{Delta: 1})
+
+	get := func(t *testing.T, path string, v any) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d", w.Code)
+		}
+		if err := json.NewDecoder(w.Body).Decode(v); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+	}
+
+	t.Run("Margin report", func(t *testing.T) {
+		var report repo.MarginReport
+		get(t, "/metrics/margins", &report)
+
+		if report.UncostedProducts != 1 || len(report.Products) != 2 {
+			t.Fatalf("unexpected report: %+v", report)
+		}
+		if beans := report.Products[0]; beans.Name != "Beans" || beans.MarginPercent != 10 || beans.ProjectedProfit != 5 {
+			t.Errorf("expected Beans first with a 10%% margin, got %+v", beans)
+		}
+		// Beans: 5 × (10 − 9); Grinder: 2 × (50 − 30)
+		if report.ProjectedProfit != 45 || report.StockValue != 150 {
+			t.Errorf("unexpected totals: profit %v, value %v", report.ProjectedProfit, report.StockValue)
+		}
+		if len(report.Categories) != 2 || report.Categories[1].Category != "Equipment" || report.Categories[1].MarginPercent != 40 {
+			t.Errorf("unexpected categories: %+v", report.Categories)
+		}
+	})
+
+	t.Run("Dashboard lists low-margin movers", func(t *testing.T) {
+		var metrics repo.Metrics
+		get(t, "/metrics/dashboard", &metrics)
+		if len(metrics.LowMarginMovers) != 1 || metrics.LowMarginMovers[0].Name != "Beans" || metrics.LowMarginMovers[0].MarginPercent != 10 {
+			t.Errorf("unexpected low-margin movers: %+v", metrics.LowMarginMovers)
+		}
+	})
+}
+
+func TestStockLoadHandler(t *testing.T) {
+	t.Cleanup(clearAllProducts)
+	r := router.NewRouter()
+
+	send := func(t *testing.T, method, path string, body any) *httptest.ResponseRecorder {
+		t.Helper()
+		var buf bytes.Buffer
+		if body != nil {
+			_ = json.NewEncoder(&buf).Encode(body)
+		}
+		req := httptest.NewRequest(method, path, &buf)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	newProduct := func(p handlers.ProductRequest) int {
+		w := createProduct(r, p)
+		var resp handlers.ProductResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode product: %v", err)
+		}
+		return resp.Id
+	}
+	// 2 kg and 20 × 50 × 10 cm = 0.01 m³ a unit
+	crate := newProduct(handlers.ProductRequest{Name: "Crate", Price: 10, Quantity: 10, Weight: 2, Dimensions: models.Dimensions{Length: 20, Width: 50, Height: 10}})
+	newProduct(handlers.ProductRequest{Name: "Sticker", Price: 1, Quantity: 100})
+
+	if w := send(t, http.MethodPut, fmt.Sprintf("/products/%d/bins", crate), handlers.BinRequest{Warehouse: "north", Bin: "A1", Quantity: 4}); w.Code != http.StatusOK {
+		t.Fatalf("failed to place stock: %d %s", w.Code, w.Body.String())
+	}
+
+	t.Run("Stock load per warehouse", func(t *testing.T) {
+		w := send(t, http.MethodGet, "/metrics/stock-load", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d", w.Code)
+		}
+		var report repo.StockLoadReport
+		if err := json.NewDecoder(w.Body).Decode(&report); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if report.Units != 110 || report.Weight != 20 || report.Volume != 0.1 {
+			t.Errorf("unexpected totals: %+v", report)
+		}
+		if len(report.Warehouses) != 1 || report.Warehouses[0].Warehouse != "north" || report.Warehouses[0].Weight != 8 || report.Warehouses[0].Volume != 0.04 {
+			t.Errorf("unexpected warehouses: %+v", report.Warehouses)
+		}
+		if report.Unplaced.Units != 106 || report.Unplaced.Weight != 12 {
+			t.Errorf("unexpected unplaced stock: %+v", report.Unplaced)
+		}
+		if report.UnweighedProducts != 1 || report.UnmeasuredProducts != 1 {
+			t.Errorf("expected the sticker to be counted as unweighed and unmeasured, got %+v", report)
+		}
+	})
+
+	t.Run("Dashboard totals", func(t *testing.T) {
+		w := send(t, http.MethodGet, "/metrics/dashboard", nil)
+		var metrics repo.Metrics
+		if err := json.NewDecoder(w.Body).Decode(&metrics); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if metrics.TotalStockWeight != 20 || metrics.TotalStockVolume != 0.1 {
+			t.Errorf("expected 20 kg and 0.1 m³, got %v kg and %v m³", metrics.TotalStockWeight, metrics.TotalStockVolume)
+		}
+	})
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
 	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
 	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
 )
 
 func TestAdjustQuantityHandler(t *testing.T) {
@@ -475,6 +476,97 @@ func TestLowStockAlert(t *testing.T) {
 	})
 }
 
+func TestAdjustQuantityBatchHandler(t *testing.T) {
+	t.Cleanup(clearAllProducts)
+	r := router.NewRouter()
+
+	w := createProduct(r, handlers.ProductRequest{Name: "Scanned", Price: 5, Quantity: 2})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("failed to create product: %d", w.Code)
+	}
+	var created handlers.ProductResponse
+	if err := json.NewDecoder(w.Body).Decode(&created); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	batch := func(adjustments []handlers.QuantityAdjustmentRequest) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(adjustments)
+		req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/products/%d/adjust/batch", created.Id), bytes.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	earlier := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	externalID := newUUID()
+	t.Run("Applies every movement", func(t *testing.T) {
+		// Goes below zero midway; only the final quantity matters.
+		w := batch([]handlers.QuantityAdjustmentRequest{
+			{Delta: -3, OccurredAt: earlier},
+			{Delta: 5, ExternalID: externalID},
+			{Delta: -1},
+		})
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		var res handlers.BatchAdjustmentResult
+		if err := json.NewDecoder(w.Body).Decode(&res); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if res.Applied != 3 || res.NetDelta != 1 || res.Product.Quantity != 3 {
+			t.Errorf("unexpected result: %+v", res)
+		}
+
+		movements, _, err := movementRepo.GetByProductID(created.Id, repo.MovementFilter{})
+		if err != nil {
+			t.Fatalf("failed to fetch movements: %v", err)
+		}
+		if len(movements) != 3 {
+			t.Fatalf("expected 3 movements, got %d", len(movements))
+		}
+		found := false
+		for _, m := range movements {
+			if m.Delta == -3 && m.CreatedAt == earlier {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("backdated movement not found in %+v", movements)
+		}
+	})
+
+	t.Run("Rejects the whole batch", func(t *testing.T) {
+		if w := batch([]handlers.QuantityAdjustmentRequest{{Delta: 1}, {Delta: -10}}); w.Code != http.StatusConflict {
+			t.Errorf("expected 409 Conflict, got %d", w.Code)
+		}
+		if w := batch([]handlers.QuantityAdjustmentRequest{{Delta: 1}, {Delta: 1, ExternalID: externalID}}); w.Code != http.StatusConflict {
+			t.Errorf("expected 409 Conflict for a taken external_id, got %d", w.Code)
+		}
+		if p, _ := productRepo.GetByID(created.Id); p.Quantity != 3 {
+			t.Errorf("expected quantity to stay 3, got %d", p.Quantity)
+		}
+	})
+
+	repeated := newUUID()
+	cases := []struct {
+		name  string
+		batch []handlers.QuantityAdjustmentRequest
+	}{
+		{"Empty batch", []handlers.QuantityAdjustmentRequest{}},
+		{"Zero delta", []handlers.QuantityAdjustmentRequest{{Delta: 0}}},
+		{"Future timestamp", []handlers.QuantityAdjustmentRequest{{Delta: 1, OccurredAt: time.Now().Add(time.Hour).Format(time.RFC3339)}}},
+		{"Repeated external ID", []handlers.QuantityAdjustmentRequest{{Delta: 1, ExternalID: repeated}, {Delta: 1, ExternalID: repeated}}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if w := batch(c.batch); w.Code != http.StatusBadRequest {
+				t.Errorf("expected 400 Bad Request, got %d: %s", w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
 func TestExportMovementsHandler(t *testing.T) {
 	t.Cleanup(clearAllProducts)
 	r := router.NewRouter()
@@ -516,11 +608,104 @@ func TestExportMovementsHandler(t *testing.T) {
 		if ct := w.Header().Get("Content-Type"); !strings.Contains(ct, "text/csv") {
 			t.Errorf("expected text/csv, got %s", ct)
 		}
-		if !strings.Contains(w.Body.String(), "product_id,delta") {
+		if !strings.HasPrefix(w.Body.String(), "id,product_id,delta,created_at\n") {
 			t.Errorf("expected CSV header in response, got: %s", w.Body.String())
 		}
 	})
 
+	t.Run("Export selected columns", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/products/%d/movements/export?format=csv&columns=delta,%%20id", created.Id), nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if !strings.HasPrefix(w.Body.String(), "delta,id\n") || !strings.Contains(w.Body.String(), "\n3,") {
+			t.Errorf("unexpected CSV: %s", w.Body.String())
+		}
+	})
+
+	t.Run("Invalid columns", func(t *testing.T) {
+		for _, columns := range []string{"delta,c", "id,id", "id,,delta"} {
+			req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/products/%d/movements/export?format=csv&columns=%s", created.Id, columns), nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("columns=%s: expected 400 Bad Request, got %d", columns, w.Code)
+			}
+		}
+	})
+
+	exportCSV := func(t *testing.T, query string, auth bool) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/products/%d/movements/export?format=csv&columns=created_at%s", created.Id, query), nil)
+		if auth {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Export in a timezone and date format", func(t *testing.T) {
+		w := exportCSV(t, "&tz=Asia/Kolkata&date_format=datetime", false)
+		lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+		if w.Code != http.StatusOK || len(lines) < 2 {
+			t.Fatalf("unexpected export (%d): %s", w.Code, w.Body.String())
+		}
+		at, err := time.ParseInLocation(time.DateTime, lines[1], time.FixedZone("IST", 5*3600+1800))
+		if err != nil || time.Since(at).Abs() > time.Hour {
+			t.Errorf("expected a current Asia/Kolkata timestamp, got %q", lines[1])
+		}
+	})
+
+	t.Run("Export JSON in a timezone and date format", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/products/%d/movements/export?format=json&tz=Asia/Kolkata&date_format=datetime", created.Id), nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		var movements []models.Movement
+		if err := json.NewDecoder(w.Body).Decode(&movements); err != nil || len(movements) == 0 {
+			t.Fatalf("unexpected export (%d): %v", w.Code, err)
+		}
+		at, err := time.ParseInLocation(time.DateTime, movements[0].CreatedAt, time.FixedZone("IST", 5*3600+1800))
+		if err != nil || time.Since(at).Abs() > time.Hour {
+			t.Errorf("expected a current Asia/Kolkata timestamp, got %q", movements[0].CreatedAt)
+		}
+	})
+
+	t.Run("Export in the profile timezone", func(t *testing.T) {
+		setTimezone := func(tz string) int {
+			body, _ := json.Marshal(handlers.TimezoneRequest{Timezone: tz})
+			req := httptest.NewRequest(http.MethodPut, "/me/timezone", bytes.NewReader(body))
+			req.Header.Set("Authorization", "Bearer "+token)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			return w.Code
+		}
+		if code := setTimezone("Pacific/Chatham"); code != http.StatusNoContent {
+			t.Fatalf("expected 204 No Content, got %d", code)
+		}
+		defer setTimezone("")
+
+		if body := exportCSV(t, "", true).Body.String(); !strings.Contains(body, "+12:45") && !strings.Contains(body, "+13:45") {
+			t.Errorf("expected Chatham offsets, got %s", body)
+		}
+		if body := exportCSV(t, "&tz=UTC", true).Body.String(); !strings.Contains(body, "Z") {
+			t.Errorf("expected tz to override the profile, got %s", body)
+		}
+		if code := setTimezone("Mars/Olympus_Mons"); code != http.StatusBadRequest {
+			t.Errorf("expected 400 for an unknown timezone, got %d", code)
+		}
+	})
+
+	t.Run("Invalid timezone or date format", func(t *testing.T) {
+		for _, query := range []string{"&tz=Nowhere/City", "&tz=Local", "&date_format=dd/mm/yyyy"} {
+			if w := exportCSV(t, query, false); w.Code != http.StatusBadRequest {
+				t.Errorf("%s: expected 400 Bad Request, got %d", query, w.Code)
+			}
+		}
+	})
+
 	t.Run("Invalid format", func(t *testing.T) {
 		req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/products/%d/movements/export?format=pdf", created.Id), nil)
 		w := httptest.NewRecorder()
@@ -603,3 +788,70 @@ func TestExportMovementsHandler_Filtered(t *testing.T) {
 		}
 	})
 }
+
+func TestMovementReasons(t *testing.T) {
+	t.Cleanup(clearAllProducts)
+	r := router.NewRouter()
+
+	w := createProduct(r, handlers.ProductRequest{Name: "ReasonBox", Price: 20.0, Quantity: 10})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("failed to create product")
+	}
+	var created handlers.ProductResponse
+	if err := json.NewDecoder(w.Body).Decode(&created); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	for _, adj := range []handlers.QuantityAdjustmentRequest{
+		{Delta: -2, Reason: "sale"},
+		{Delta: -1, Reason: "Damage", Note: "  dropped by forklift  "},
+		{Delta: 3},
+	} {
+		if w := adjustProduct(r, created.Id, adj); w.Code != http.StatusOK {
+			t.Fatalf("failed to adjust product: %d %s", w.Code, w.Body.String())
+		}
+	}
+
+	t.Run("Filter movements by reason", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/products/%d/movements?reason=damage", created.Id), nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		var result handlers.MovementsSearchResult
+		if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(result.Data) != 1 || result.Meta.TotalCount != 1 {
+			t.Fatalf("expected 1 damage movement, got %+v", result)
+		}
+		if m := result.Data[0]; m.Delta != -1 || m.Reason != "damage" || m.Note != "dropped by forklift" {
+			t.Errorf("unexpected movement: %+v", m)
+		}
+	})
+
+	t.Run("Export filtered by reason", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/products/%d/movements/export?format=csv&reason=sale&columns=delta,reason,note", created.Id), nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d", w.Code)
+		}
+		lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+		if len(lines) != 2 || lines[1] != "-2,sale," {
+			t.Errorf("expected a single sale row, got %q", lines)
+		}
+	})
+
+	t.Run("Unknown reason", func(t *testing.T) {
+		if w := adjustProduct(r, created.Id, handlers.QuantityAdjustmentRequest{Delta: 1, Reason: "gift"}); w.Code != http.StatusBadRequest {
+			t.Errorf("expected 400 Bad Request, got %d", w.Code)
+		}
+		req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/products/%d/movements?reason=gift", created.Id), nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected 400 Bad Request, got %d", w.Code)
+		}
+	})
+}
@@ -0,0 +1,101 @@
+package handlers_integrated_test_suite
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+)
+
+func TestImportMovementsHandler(t *testing.T) {
+	t.Cleanup(clearAllProducts)
+	r := router.NewRouter()
+
+	w := createProduct(r, handlers.ProductRequest{Name: "Bolts", Price: 10, Quantity: 5, SKU: "BLT-1"})
+	var bolts handlers.ProductResponse
+	if err := json.NewDecoder(w.Body).Decode(&bolts); err != nil {
+		t.Fatalf("failed to decode product: %v", err)
+	}
+
+	importCSV := func(query, csv string) handlers.ImportMovementsResult {
+		var buf bytes.Buffer
+		writer := multipart.NewWriter(&buf)
+		part, _ := writer.CreateFormFile("file", "movements.csv")
+		_, _ = part.Write([]byte(csv))
+		writer.Close()
+
+		req := httptest.NewRequest(http.MethodPost, "/movements/import"+query, &buf)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var resp handlers.ImportMovementsResult
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return resp
+	}
+	reviewQueue := func() []handlers.ProductResponse {
+		req := httptest.NewRequest(http.MethodGet, "/products/filter?needs_review=true", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		var resp handlers.ProductsSearchResult
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return resp.Data
+	}
+
+	t.Run("Unknown SKUs fail without create_missing", func(t *testing.T) {
+		resp := importCSV("", "sku,delta,occurred_at\nBLT-1,3,2025-01-10\nNUT-9,4,2025-01-11\nBLT-1,0,\nBLT-1,1,2999-01-01")
+		if resp.Imported != 1 || len(resp.Errors) != 3 || len(resp.Placeholders) != 0 {
+			t.Errorf("unexpected result: %+v", resp)
+		}
+		if resp.Errors[0].Code != handlers.ErrCodeNotFound {
+			t.Errorf("expected the unknown SKU to fail with not_found, got %+v", resp.Errors[0])
+		}
+	})
+
+	var placeholder int
+	t.Run("Placeholders for unknown SKUs", func(t *testing.T) {
+		resp := importCSV("?create_missing=true", "sku,delta,name\nNUT-9,4,Nuts\nNUT-9,2,\nBLT-1,-1,")
+		if resp.Imported != 3 || len(resp.Errors) != 0 || len(resp.Placeholders) != 1 {
+			t.Fatalf("unexpected result: %+v", resp)
+		}
+		placeholder = resp.Placeholders[0]
+
+		queue := reviewQueue()
+		if len(queue) != 1 || queue[0].Id != placeholder {
+			t.Fatalf("expected the placeholder in the review queue, got %+v", queue)
+		}
+		p := queue[0]
+		if p.Name != "Nuts" || p.Quantity != 6 || p.Status != "inactive" || !p.NeedsReview {
+			t.Errorf("unexpected placeholder: %+v", p)
+		}
+	})
+
+	t.Run("Updating a placeholder marks it reviewed", func(t *testing.T) {
+		body, _ := json.Marshal(handlers.ProductRequest{Name: "Nuts", Price: 2.5, Quantity: 6, SKU: "NUT-9"})
+		req := httptest.NewRequest(http.MethodPut, fmt.Sprintf("/products/%d", placeholder), bytes.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		if queue := reviewQueue(); len(queue) != 0 {
+			t.Errorf("expected an empty review queue, got %+v", queue)
+		}
+	})
+}
@@ -0,0 +1,106 @@
+package handlers_integrated_test_suite
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/auth"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+)
+
+func TestSignedPartnerRequests(t *testing.T) {
+	t.Cleanup(clearAllProducts)
+	r := router.NewRouter()
+
+	w := createProduct(r, handlers.ProductRequest{Name: "Partner stock", Price: 3, Quantity: 10})
+	var product handlers.ProductResponse
+	if err := json.NewDecoder(w.Body).Decode(&product); err != nil {
+		t.Fatalf("failed to decode product: %v", err)
+	}
+
+	body, _ := json.Marshal(handlers.PartnerRequest{Name: "acme-erp", Scopes: []string{"POST /products/*/adjust"}})
+	req := httptest.NewRequest(http.MethodPost, "/admin/partners", bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201 Created, got %d: %s", w.Code, w.Body.String())
+	}
+	var partner handlers.PartnerSecretResponse
+	if err := json.NewDecoder(w.Body).Decode(&partner); err != nil {
+		t.Fatalf("failed to decode partner: %v", err)
+	}
+	t.Cleanup(func() {
+		req := httptest.NewRequest(http.MethodDelete, "/admin/partners/"+partner.ID, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	})
+
+	signed := func(path string, body []byte, ts int64, secret string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+		req.Header.Set("X-Partner-Id", partner.ID)
+		req.Header.Set("X-Timestamp", strconv.FormatInt(ts, 10))
+		req.Header.Set("X-Signature", auth.SignRequest(secret, ts, http.MethodPost, path, body))
+		return req
+	}
+	serve := func(req *http.Request) int {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	adjustPath := fmt.Sprintf("/products/%d/adjust", product.Id)
+	adjust, _ := json.Marshal(handlers.QuantityAdjustmentRequest{Delta: -2})
+	now := time.Now().Unix()
+
+	t.Run("Valid signature", func(t *testing.T) {
+		if code := serve(signed(adjustPath, adjust, now, partner.Secret)); code != http.StatusOK {
+			t.Errorf("expected 200 OK, got %d", code)
+		}
+	})
+
+	t.Run("Prices are hidden from partners", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, signed(adjustPath, adjust, now+3, partner.Secret))
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d", w.Code)
+		}
+		var body map[string]any
+		if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if _, ok := body["price"]; ok {
+			t.Errorf("expected price to be hidden, got %v", body["price"])
+		}
+	})
+
+	t.Run("Replayed request", func(t *testing.T) {
+		if code := serve(signed(adjustPath, adjust, now, partner.Secret)); code != http.StatusUnauthorized {
+			t.Errorf("expected 401 Unauthorized, got %d", code)
+		}
+	})
+
+	cases := []struct {
+		name string
+		req  *http.Request
+		code int
+	}{
+		{"Wrong secret", signed(adjustPath, adjust, now+1, "not-the-secret"), http.StatusUnauthorized},
+		{"Stale timestamp", signed(adjustPath, adjust, now-int64(time.Hour.Seconds()), partner.Secret), http.StatusUnauthorized},
+		{"Outside scope", signed("/products", []byte(`{"name":"x","price":1}`), now+2, partner.Secret), http.StatusForbidden},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if code := serve(c.req); code != c.code {
+				t.Errorf("expected %d, got %d", c.code, code)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package handlers_integrated_test_suite
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+)
+
+func TestPasswordExpiry(t *testing.T) {
+	t.Cleanup(func() {
+		handlers.SetPasswordMaxAge(0)
+		clearAllUsersExceptAdmin()
+	})
+	r := router.NewRouter()
+
+	post := func(path string, body any, authorized bool) *httptest.ResponseRecorder {
+		raw, _ := json.Marshal(body)
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(raw))
+		req.Header.Set("Content-Type", "application/json")
+		if authorized {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	login := func(password string) *httptest.ResponseRecorder {
+		return post("/login", handlers.CredentialsRequest{Username: "rotator", Password: password}, false)
+	}
+	expectExpired := func(t *testing.T, w *httptest.ResponseRecorder) {
+		t.Helper()
+		if w.Code != http.StatusForbidden {
+			t.Fatalf("expected 403 Forbidden, got %d: %s", w.Code, w.Body.String())
+		}
+		var e handlers.ErrorResponse
+		if err := json.NewDecoder(w.Body).Decode(&e); err != nil {
+			t.Fatalf("failed to decode error: %v", err)
+		}
+		if e.Code != handlers.ErrCodePasswordExpired {
+			t.Errorf("expected code %s, got %q", handlers.ErrCodePasswordExpired, e.Code)
+		}
+	}
+
+	if w := post("/register", handlers.CredentialsRequest{Username: "rotator", Password: "first-pass"}, false); w.Code != http.StatusCreated {
+		t.Fatalf("expected 201 Created, got %d: %s", w.Code, w.Body.String())
+	}
+
+	t.Run("Admin forces rotation", func(t *testing.T) {
+		w := post("/admin/users/password-rotation", handlers.PasswordRotationRequest{Usernames: []string{"rotator", "nobody"}}, true)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		var result handlers.PasswordRotationResult
+		if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(result.Expired) != 1 || len(result.NotFound) != 1 || result.NotFound[0] != "nobody" {
+			t.Errorf("unexpected result: %+v", result)
+		}
+		expectExpired(t, login("first-pass"))
+	})
+
+	t.Run("Changing the password lifts it", func(t *testing.T) {
+		w := post("/password/change", handlers.ChangePasswordRequest{Username: "rotator", CurrentPassword: "first-pass", NewPassword: "second-pass"}, false)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		if w := login("second-pass"); w.Code != http.StatusOK {
+			t.Errorf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("Passwords past the maximum age expire", func(t *testing.T) {
+		handlers.SetPasswordMaxAge(time.Nanosecond)
+		expectExpired(t, login("second-pass"))
+
+		handlers.SetPasswordMaxAge(24 * time.Hour)
+		if w := login("second-pass"); w.Code != http.StatusOK {
+			t.Errorf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("Empty list", func(t *testing.T) {
+		if w := post("/admin/users/password-rotation", handlers.PasswordRotationRequest{}, true); w.Code != http.StatusBadRequest {
+			t.Errorf("expected 400 Bad Request, got %d", w.Code)
+		}
+	})
+}
@@ -0,0 +1,91 @@
+package handlers_integrated_test_suite
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+func TestClosePeriodHandler(t *testing.T) {
+	t.Cleanup(clearAllProducts)
+	t.Cleanup(clearAllPeriods)
+	r := router.NewRouter()
+
+	w := createProduct(r, handlers.ProductRequest{Name: "Audited", Price: 10.0, Quantity: 10})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("failed to create product: %d", w.Code)
+	}
+	var created handlers.ProductResponse
+	if err := json.NewDecoder(w.Body).Decode(&created); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	backdated := handlers.QuantityAdjustmentRequest{Delta: -2, OccurredAt: "2020-01-15T10:00:00Z"}
+	if w := adjustProduct(r, created.Id, backdated); w.Code != http.StatusOK {
+		t.Fatalf("expected backdated adjustment to succeed, got %d", w.Code)
+	}
+
+	t.Run("Close period", func(t *testing.T) {
+		w := closePeriod(r, "2020-01")
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected 201 Created, got %d", w.Code)
+		}
+	})
+
+	t.Run("Closing twice conflicts", func(t *testing.T) {
+		w := closePeriod(r, "2020-01")
+		if w.Code != http.StatusConflict {
+			t.Errorf("expected 409 Conflict, got %d", w.Code)
+		}
+	})
+
+	t.Run("Invalid period", func(t *testing.T) {
+		w := closePeriod(r, "2020-13")
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected 400 Bad Request, got %d", w.Code)
+		}
+	})
+
+	t.Run("Backdated movement into closed period is rejected", func(t *testing.T) {
+		adj := handlers.QuantityAdjustmentRequest{Delta: 1, OccurredAt: "2020-01-20T10:00:00Z"}
+		w := adjustProduct(r, created.Id, adj)
+		if w.Code != http.StatusConflict {
+			t.Errorf("expected 409 Conflict, got %d", w.Code)
+		}
+	})
+
+	t.Run("Export of closed period uses frozen movements", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/products/%d/movements/export?format=json&period=2020-01", created.Id), nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d", w.Code)
+		}
+		if w.Header().Get("X-Period-Status") != "closed" {
+			t.Errorf("expected closed period header")
+		}
+
+		var movements []models.Movement
+		if err := json.NewDecoder(w.Body).Decode(&movements); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(movements) != 1 || movements[0].Delta != -2 {
+			t.Errorf("expected the single frozen movement, got %v", movements)
+		}
+	})
+}
+
+func closePeriod(r http.Handler, period string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/admin/periods/"+period+"/close", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
@@ -0,0 +1,144 @@
+package handlers_integrated_test_suite
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+func TestPriceListHandlers(t *testing.T) {
+	t.Cleanup(func() {
+		clearPriceLists()
+		clearAllProducts()
+	})
+	r := router.NewRouter()
+
+	send := func(method, path string, body any) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		if body != nil {
+			_ = json.NewEncoder(&buf).Encode(body)
+		}
+		req := httptest.NewRequest(method, path, &buf)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	newProduct := func(name string, price float64) int {
+		w := createProduct(r, handlers.ProductRequest{Name: name, Price: price, Quantity: 1})
+		var p handlers.ProductResponse
+		if err := json.NewDecoder(w.Body).Decode(&p); err != nil {
+			t.Fatalf("failed to decode product: %v", err)
+		}
+		return p.Id
+	}
+	drill := newProduct("Drill", 100)
+	saw := newProduct("Saw", 60)
+
+	var wholesale models.PriceList
+	t.Run("Create price list", func(t *testing.T) {
+		w := send(http.MethodPost, "/admin/price-lists", handlers.PriceListRequest{Name: "Wholesale", Description: "Trade customers"})
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected 201 Created, got %d: %s", w.Code, w.Body.String())
+		}
+		if err := json.NewDecoder(w.Body).Decode(&wholesale); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+	})
+	pricesPath := fmt.Sprintf("/admin/price-lists/%d/prices", wholesale.ID)
+
+	t.Run("Set prices", func(t *testing.T) {
+		w := send(http.MethodPut, pricesPath, []models.PriceListItem{{ProductID: drill, Price: 80}})
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		var detail handlers.PriceListDetail
+		if err := json.NewDecoder(w.Body).Decode(&detail); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(detail.Prices) != 1 || detail.Prices[0].Price != 80 {
+			t.Errorf("unexpected prices: %+v", detail.Prices)
+		}
+	})
+
+	getProduct := func(t *testing.T, id int, query string) handlers.ProductResponse {
+		t.Helper()
+		w := send(http.MethodGet, fmt.Sprintf("/products/%d%s", id, query), nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		var p handlers.ProductResponse
+		if err := json.NewDecoder(w.Body).Decode(&p); err != nil {
+			t.Fatalf("failed to decode product: %v", err)
+		}
+		return p
+	}
+
+	t.Run("Product reads use the list price", func(t *testing.T) {
+		if p := getProduct(t, drill, "?price_list=wholesale"); p.Price != 80 || p.PriceList != "Wholesale" {
+			t.Errorf("expected the wholesale price, got %+v", p)
+		}
+		if p := getProduct(t, drill, ""); p.Price != 100 || p.PriceList != "" {
+			t.Errorf("expected the regular price, got %+v", p)
+		}
+	})
+
+	t.Run("Products without a list price keep the regular one", func(t *testing.T) {
+		if p := getProduct(t, saw, "?price_list=Wholesale"); p.Price != 60 || p.PriceList != "" {
+			t.Errorf("expected the regular price, got %+v", p)
+		}
+	})
+
+	t.Run("Removed price falls back to the regular one", func(t *testing.T) {
+		if w := send(http.MethodDelete, fmt.Sprintf("%s/%d", pricesPath, drill), nil); w.Code != http.StatusNoContent {
+			t.Fatalf("expected 204 No Content, got %d: %s", w.Code, w.Body.String())
+		}
+		if p := getProduct(t, drill, "?price_list=Wholesale"); p.Price != 100 {
+			t.Errorf("expected the regular price, got %+v", p)
+		}
+	})
+
+	t.Run("Unknown price list is rejected", func(t *testing.T) {
+		if w := send(http.MethodGet, fmt.Sprintf("/products/%d?price_list=retail", drill), nil); w.Code != http.StatusBadRequest {
+			t.Errorf("expected 400 Bad Request, got %d", w.Code)
+		}
+	})
+
+	cases := []struct {
+		name   string
+		method string
+		path   string
+		body   any
+		code   int
+	}{
+		{"Duplicate name", http.MethodPost, "/admin/price-lists", handlers.PriceListRequest{Name: "WHOLESALE"}, http.StatusConflict},
+		{"Blank name", http.MethodPost, "/admin/price-lists", handlers.PriceListRequest{Name: " "}, http.StatusBadRequest},
+		{"Zero price", http.MethodPut, pricesPath, []models.PriceListItem{{ProductID: drill, Price: 0}}, http.StatusBadRequest},
+		{"Duplicate product", http.MethodPut, pricesPath, []models.PriceListItem{{ProductID: saw, Price: 5}, {ProductID: saw, Price: 6}}, http.StatusBadRequest},
+		{"Unknown product", http.MethodPut, pricesPath, []models.PriceListItem{{ProductID: 999999, Price: 5}}, http.StatusNotFound},
+		{"Unknown price list", http.MethodPut, "/admin/price-lists/999999/prices", []models.PriceListItem{{ProductID: saw, Price: 5}}, http.StatusNotFound},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if w := send(c.method, c.path, c.body); w.Code != c.code {
+				t.Errorf("expected %d, got %d: %s", c.code, w.Code, w.Body.String())
+			}
+		})
+	}
+
+	t.Run("Delete price list", func(t *testing.T) {
+		if w := send(http.MethodDelete, fmt.Sprintf("/admin/price-lists/%d", wholesale.ID), nil); w.Code != http.StatusNoContent {
+			t.Fatalf("expected 204 No Content, got %d: %s", w.Code, w.Body.String())
+		}
+		if w := send(http.MethodGet, fmt.Sprintf("/admin/price-lists/%d", wholesale.ID), nil); w.Code != http.StatusNotFound {
+			t.Errorf("expected 404 Not Found, got %d", w.Code)
+		}
+	})
+}
@@ -0,0 +1,154 @@
+package handlers_integrated_test_suite
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+)
+
+func TestPriceRulesHandlers(t *testing.T) {
+	t.Cleanup(func() {
+		clearPriceRules()
+		clearAllProducts()
+		clearAllUsersExceptAdmin()
+	})
+	r := router.NewRouter()
+
+	send := func(method, path, bearer string, body any) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		if body != nil {
+			_ = json.NewEncoder(&buf).Encode(body)
+		}
+		req := httptest.NewRequest(method, path, &buf)
+		req.Header.Set("Authorization", "Bearer "+bearer)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	clerkToken, err := roleToken(r, "price-clerk", "user")
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	t.Run("Set rules", func(t *testing.T) {
+		w := send(http.MethodPut, "/admin/price-rules", token, handlers.PriceRulesRequest{MaxPrice: 5000, MaxChangePercent: 50, Currency: "jpy"})
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	var id int
+	t.Run("Sane price", func(t *testing.T) {
+		w := send(http.MethodPost, "/products", clerkToken, handlers.ProductRequest{Name: "Teapot", Price: 1200})
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected 201 Created, got %d: %s", w.Code, w.Body.String())
+		}
+		var p handlers.ProductResponse
+		_ = json.NewDecoder(w.Body).Decode(&p)
+		id = p.Id
+	})
+
+	rejected := []struct {
+		name  string
+		price float64
+	}{
+		{"Above the maximum", 9000},
+		{"Too many decimals for the currency", 1200.5},
+	}
+	for _, c := range rejected {
+		t.Run(c.name, func(t *testing.T) {
+			w := send(http.MethodPost, "/products", clerkToken, handlers.ProductRequest{Name: "Kettle", Price: c.price})
+			if w.Code != http.StatusUnprocessableEntity {
+				t.Fatalf("expected 422, got %d: %s", w.Code, w.Body.String())
+			}
+			var resp handlers.ErrorResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil || resp.Code != handlers.ErrCodeSuspiciousPrice {
+				t.Errorf("expected a suspicious_price error, got %+v", resp)
+			}
+		})
+	}
+
+	t.Run("Change too large", func(t *testing.T) {
+		if w := send(http.MethodPut, fmt.Sprintf("/products/%d", id), clerkToken, handlers.ProductRequest{Name: "Teapot", Price: 120}); w.Code != http.StatusUnprocessableEntity {
+			t.Errorf("expected 422, got %d: %s", w.Code, w.Body.String())
+		}
+		if w := send(http.MethodPut, fmt.Sprintf("/products/%d", id), clerkToken, handlers.ProductRequest{Name: "Teapot", Price: 1500}); w.Code != http.StatusOK {
+			t.Errorf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("Override needs the permission", func(t *testing.T) {
+		if w := send(http.MethodPost, "/products?override_price_rules=true", clerkToken, handlers.ProductRequest{Name: "Kettle", Price: 9000}); w.Code != http.StatusUnprocessableEntity {
+			t.Errorf("expected 422, got %d: %s", w.Code, w.Body.String())
+		}
+		w := send(http.MethodPost, "/products?override_price_rules=true", token, handlers.ProductRequest{Name: "Kettle", Price: 9000})
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected 201 Created, got %d: %s", w.Code, w.Body.String())
+		}
+		var p handlers.ProductResponse
+		_ = json.NewDecoder(w.Body).Decode(&p)
+		if len(p.Warnings) != 1 {
+			t.Errorf("expected the override to be flagged, got %v", p.Warnings)
+		}
+	})
+
+	importCSV := func(csv string) handlers.ImportProductsResult {
+		var buf bytes.Buffer
+		writer := multipart.NewWriter(&buf)
+		part, _ := writer.CreateFormFile("file", "products.csv")
+		_, _ = part.Write([]byte(csv))
+		writer.Close()
+
+		req := httptest.NewRequest(http.MethodPost, "/products/import", &buf)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		req.Header.Set("Authorization", "Bearer "+clerkToken)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		var resp handlers.ImportProductsResult
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return resp
+	}
+
+	t.Run("Import rejects suspicious rows", func(t *testing.T) {
+		resp := importCSV("name,price,quantity\nCup,300,2\nMug,30000,4")
+		if resp.ImportedProductsCount != 1 || len(resp.Errors) != 1 || resp.Errors[0].Code != handlers.ErrCodeSuspiciousPrice {
+			t.Errorf("unexpected import result: %+v", resp)
+		}
+	})
+
+	t.Run("Warn mode flags instead", func(t *testing.T) {
+		if w := send(http.MethodPut, "/admin/price-rules", token, handlers.PriceRulesRequest{MaxPrice: 5000, Mode: "warn"}); w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		resp := importCSV("name,price,quantity\nMug,30000,4")
+		if resp.ImportedProductsCount != 1 || len(resp.Warnings) != 1 {
+			t.Errorf("unexpected import result: %+v", resp)
+		}
+	})
+
+	cases := []struct {
+		name string
+		body handlers.PriceRulesRequest
+	}{
+		{"Negative maximum", handlers.PriceRulesRequest{MaxPrice: -1}},
+		{"Unknown mode", handlers.PriceRulesRequest{Mode: "ignore"}},
+		{"Invalid currency", handlers.PriceRulesRequest{Currency: "euro"}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if w := send(http.MethodPut, "/admin/price-rules", token, c.body); w.Code != http.StatusBadRequest {
+				t.Errorf("expected 400 Bad Request, got %d: %s", w.Code, w.Body.String())
+			}
+		})
+	}
+}
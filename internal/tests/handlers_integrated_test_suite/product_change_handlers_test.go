@@ -0,0 +1,102 @@
+package handlers_integrated_test_suite
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+)
+
+func TestGetProductChangesHandler(t *testing.T) {
+	t.Cleanup(clearAllProducts)
+	r := router.NewRouter()
+
+	send := func(method, path string, body any) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		if body != nil {
+			_ = json.NewEncoder(&buf).Encode(body)
+		}
+		req := httptest.NewRequest(method, path, &buf)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := createProduct(r, handlers.ProductRequest{Name: "Sequenced Crate", Price: 5, Quantity: 10})
+	var product handlers.ProductResponse
+	if err := json.NewDecoder(w.Body).Decode(&product); err != nil {
+		t.Fatalf("failed to decode product: %v", err)
+	}
+	if product.Seq != 1 {
+		t.Fatalf("expected a new product to have seq 1, got %d", product.Seq)
+	}
+	path := fmt.Sprintf("/products/%d", product.Id)
+
+	if w := send(http.MethodPost, path+"/adjust", handlers.QuantityAdjustmentRequest{Delta: 3}); w.Code != http.StatusOK {
+		t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+	}
+	w = send(http.MethodPut, path, handlers.ProductRequest{Name: "Sequenced Crate", Price: 6, Quantity: 13})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+	}
+	if err := json.NewDecoder(w.Body).Decode(&product); err != nil {
+		t.Fatalf("failed to decode product: %v", err)
+	}
+	if product.Seq != 3 {
+		t.Errorf("expected seq 3 after two writes, got %d", product.Seq)
+	}
+
+	t.Run("Changes since a seq", func(t *testing.T) {
+		w := send(http.MethodGet, path+"/changes?since_seq=1", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		var page handlers.ProductChangesPage
+		if err := json.NewDecoder(w.Body).Decode(&page); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if page.Seq != 3 || len(page.Changes) != 2 {
+			t.Fatalf("expected 2 changes up to seq 3, got %+v", page)
+		}
+		adjust, update := page.Changes[0], page.Changes[1]
+		if adjust.Seq != 2 || adjust.Fields["quantity"] != float64(13) {
+			t.Errorf("unexpected adjustment change: %+v", adjust)
+		}
+		if _, ok := adjust.Fields["name"]; ok {
+			t.Errorf("expected only changed fields, got %+v", adjust.Fields)
+		}
+		if update.Seq != 3 || update.Fields["price"] != float64(6) {
+			t.Errorf("unexpected update change: %+v", update)
+		}
+	})
+
+	t.Run("Up to date", func(t *testing.T) {
+		var page handlers.ProductChangesPage
+		_ = json.NewDecoder(send(http.MethodGet, path+"/changes?since_seq=3", nil).Body).Decode(&page)
+		if len(page.Changes) != 0 {
+			t.Errorf("expected no changes, got %+v", page.Changes)
+		}
+	})
+
+	cases := []struct {
+		name string
+		path string
+		code int
+	}{
+		{"Invalid since_seq", path + "/changes?since_seq=x", http.StatusBadRequest},
+		{"Unknown product", "/products/999999/changes", http.StatusNotFound},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if w := send(http.MethodGet, c.path, nil); w.Code != c.code {
+				t.Errorf("expected %d, got %d", c.code, w.Code)
+			}
+		})
+	}
+}
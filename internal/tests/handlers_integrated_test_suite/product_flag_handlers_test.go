@@ -0,0 +1,154 @@
+package handlers_integrated_test_suite
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+func TestReviewQueueHandlers(t *testing.T) {
+	t.Cleanup(func() {
+		clearProductFlags()
+		clearPriceRules()
+		clearAdjustmentLimits()
+		clearAllProducts()
+	})
+	r := router.NewRouter()
+
+	send := func(method, path string, body any) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		if body != nil {
+			_ = json.NewEncoder(&buf).Encode(body)
+		}
+		req := httptest.NewRequest(method, path, &buf)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	queue := func(query string) []handlers.ReviewQueueEntry {
+		w := send(http.MethodGet, "/products/review-queue"+query, nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		var resp handlers.ReviewQueueResult
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return resp.Data
+	}
+	flagOf := func(source string) handlers.ReviewQueueEntry {
+		entries := queue("?source=" + source)
+		if len(entries) != 1 {
+			t.Fatalf("expected one open %s flag, got %+v", source, entries)
+		}
+		return entries[0]
+	}
+
+	if w := send(http.MethodPut, "/admin/price-rules", handlers.PriceRulesRequest{MaxPrice: 100, Mode: models.PriceRuleModeWarn}); w.Code != http.StatusOK {
+		t.Fatalf("failed to set price rules: %d %s", w.Code, w.Body.String())
+	}
+	if w := send(http.MethodPut, "/admin/adjustment-limits", handlers.AdjustmentLimitRequest{WarnDelta: 5}); w.Code != http.StatusOK {
+		t.Fatalf("failed to set adjustment limits: %d %s", w.Code, w.Body.String())
+	}
+
+	var chair handlers.ProductResponse
+	t.Run("Price warnings are flagged for validation", func(t *testing.T) {
+		w := createProduct(r, handlers.ProductRequest{Name: "Chair", Price: 500, Quantity: 1})
+		if err := json.NewDecoder(w.Body).Decode(&chair); err != nil {
+			t.Fatalf("failed to decode product: %v", err)
+		}
+		if f := flagOf(models.FlagSourceValidation); f.ProductID != chair.Id || f.ProductName != "Chair" {
+			t.Errorf("unexpected flag: %+v", f)
+		}
+	})
+
+	t.Run("Dismiss a flag", func(t *testing.T) {
+		f := flagOf(models.FlagSourceValidation)
+		path := fmt.Sprintf("/products/review-queue/%d/dismiss", f.ID)
+		w := send(http.MethodPost, path, handlers.CloseFlagRequest{Note: "premium chair"})
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		var closed models.ProductFlag
+		if err := json.NewDecoder(w.Body).Decode(&closed); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if closed.Status != models.FlagStatusDismissed || closed.Note != "premium chair" || closed.ClosedBy == "" {
+			t.Errorf("unexpected flag: %+v", closed)
+		}
+		if w := send(http.MethodPost, path, nil); w.Code != http.StatusConflict {
+			t.Errorf("expected 409 Conflict closing it twice, got %d", w.Code)
+		}
+		if entries := queue("?status=dismissed"); len(entries) != 1 {
+			t.Errorf("expected the dismissed flag, got %+v", entries)
+		}
+	})
+
+	t.Run("Large adjustments are flagged as anomalies", func(t *testing.T) {
+		adjustProduct(r, chair.Id, handlers.QuantityAdjustmentRequest{Delta: 8})
+		f := flagOf(models.FlagSourceAnomaly)
+		if w := send(http.MethodPost, fmt.Sprintf("/products/review-queue/%d/resolve", f.ID), nil); w.Code != http.StatusOK {
+			t.Errorf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("Resolving a placeholder's flag completes its review", func(t *testing.T) {
+		var buf bytes.Buffer
+		writer := multipart.NewWriter(&buf)
+		part, _ := writer.CreateFormFile("file", "movements.csv")
+		_, _ = part.Write([]byte("sku,delta\nTBL-1,2"))
+		writer.Close()
+		req := httptest.NewRequest(http.MethodPost, "/movements/import?create_missing=true", &buf)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+
+		f := flagOf(models.FlagSourceImport)
+		if f.SKU != "TBL-1" {
+			t.Errorf("unexpected flag: %+v", f)
+		}
+		if w := send(http.MethodPost, fmt.Sprintf("/products/review-queue/%d/resolve", f.ID), nil); w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		w = send(http.MethodGet, fmt.Sprintf("/products/%d", f.ProductID), nil)
+		var p handlers.ProductResponse
+		if err := json.NewDecoder(w.Body).Decode(&p); err != nil {
+			t.Fatalf("failed to decode product: %v", err)
+		}
+		if p.NeedsReview {
+			t.Errorf("expected the placeholder to be reviewed, got %+v", p)
+		}
+		if entries := queue(""); len(entries) != 0 {
+			t.Errorf("expected an empty queue, got %+v", entries)
+		}
+	})
+
+	cases := []struct {
+		name, method, path string
+		code               int
+	}{
+		{"Unknown source", http.MethodGet, "/products/review-queue?source=ai", http.StatusBadRequest},
+		{"Unknown status", http.MethodGet, "/products/review-queue?status=pending", http.StatusBadRequest},
+		{"Unknown flag", http.MethodPost, "/products/review-queue/999999/resolve", http.StatusNotFound},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if w := send(c.method, c.path, nil); w.Code != c.code {
+				t.Errorf("expected %d, got %d: %s", c.code, w.Code, w.Body.String())
+			}
+		})
+	}
+}
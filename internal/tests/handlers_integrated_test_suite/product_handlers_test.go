@@ -6,11 +6,16 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
 	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
 )
 
 func TestCreateProductHandler_Valid(t *testing.T) {
@@ -73,6 +78,18 @@ func TestCreateProductHandler_Invalid(t *testing.T) {
 			expectCode:     http.StatusBadRequest,
 			expectedErrors: []string{"Quantity"},
 		},
+		{
+			name:           "Negative weight and dimensions",
+			payload:        handlers.ProductRequest{Name: "Crate", Price: 10.0, Weight: -1, Dimensions: models.Dimensions{Height: -2}},
+			expectCode:     http.StatusBadRequest,
+			expectedErrors: []string{"Weight", "Dimensions"},
+		},
+		{
+			name:           "Overlong brand",
+			payload:        handlers.ProductRequest{Name: "Crate", Price: 10.0, Brand: strings.Repeat("b", 101)},
+			expectCode:     http.StatusBadRequest,
+			expectedErrors: []string{"Brand"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -104,6 +121,52 @@ func TestCreateProductHandler_Invalid(t *testing.T) {
 	}
 }
 
+func TestProductMetadata(t *testing.T) {
+	t.Cleanup(clearAllProducts)
+	r := router.NewRouter()
+
+	dimensions := models.Dimensions{Length: 30, Width: 20, Height: 12.5}
+	w := createProduct(r, handlers.ProductRequest{Name: "Cordless Drill", Price: 89.9, Quantity: 3,
+		Description: "18V drill with two batteries", Brand: "Makita", Manufacturer: "Makita Corp", Weight: 1.6, Dimensions: dimensions})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201 Created, got %d: %s", w.Code, w.Body.String())
+	}
+	var created handlers.ProductResponse
+	if err := json.NewDecoder(w.Body).Decode(&created); err != nil {
+		t.Fatalf("error decoding response: %v", err)
+	}
+	if created.Brand != "Makita" || created.Manufacturer != "Makita Corp" || created.Weight != 1.6 || created.Dimensions != dimensions {
+		t.Errorf("metadata not returned: %+v", created)
+	}
+	if w := createProduct(r, handlers.ProductRequest{Name: "Hammer", Price: 12, Quantity: 5, Brand: "Stanley"}); w.Code != http.StatusCreated {
+		t.Fatalf("expected 201 Created, got %d", w.Code)
+	}
+
+	t.Run("Stored", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/products/%d", created.Id), nil))
+		var p handlers.ProductResponse
+		if err := json.NewDecoder(w.Body).Decode(&p); err != nil {
+			t.Fatalf("error decoding response: %v", err)
+		}
+		if p.Description != "18V drill with two batteries" || p.Dimensions != dimensions {
+			t.Errorf("metadata not stored: %+v", p)
+		}
+	})
+
+	t.Run("Filter by brand", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/products/filter?brand=makita", nil))
+		var resp handlers.ProductsSearchResult
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(resp.Data) != 1 || resp.Data[0].Id != created.Id {
+			t.Errorf("expected only the Makita drill, got %+v", resp.Data)
+		}
+	})
+}
+
 func TestCreateProductHandler_MalformedJSON(t *testing.T) {
 	t.Cleanup(clearAllProducts)
 	r := router.NewRouter()
@@ -145,6 +208,7 @@ func TestGetProductsHandler(t *testing.T) {
 	}
 
 	getReq := httptest.NewRequest(http.MethodGet, "/products", nil)
+	getReq.Header.Set("Authorization", "Bearer "+token)
 	getW := httptest.NewRecorder()
 	r.ServeHTTP(getW, getReq)
 
@@ -184,6 +248,24 @@ func TestGetProductsHandler(t *testing.T) {
 			t.Errorf("expected product quantity 2, got %v", products[1].Quantity)
 		}
 	}
+
+	sortedReq := httptest.NewRequest(http.MethodGet, "/products?sort=price", nil)
+	sortedW := httptest.NewRecorder()
+	r.ServeHTTP(sortedW, sortedReq)
+	var sorted []handlers.ProductResponse
+	if err := json.NewDecoder(sortedW.Body).Decode(&sorted); err != nil {
+		t.Fatalf("error decoding response: %v", err)
+	}
+	if len(sorted) != 2 || sorted[0].Name != "Tablet" {
+		t.Errorf("expected the cheaper Tablet first, got %+v", sorted)
+	}
+
+	invalidReq := httptest.NewRequest(http.MethodGet, "/products?sort=price&order=sideways", nil)
+	invalidW := httptest.NewRecorder()
+	r.ServeHTTP(invalidW, invalidReq)
+	if invalidW.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 Bad Request for an invalid order, got %d", invalidW.Code)
+	}
 }
 
 func TestUpdateProductHandler_Valid(t *testing.T) {
@@ -342,6 +424,7 @@ func TestFilterProductsHandler(t *testing.T) {
 
 	t.Run("Filter by price range", func(t *testing.T) {
 		req := httptest.NewRequest(http.MethodGet, "/products/filter?minPrice=100&maxPrice=1000", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
 		w := httptest.NewRecorder()
 		r.ServeHTTP(w, req)
 
@@ -433,4 +516,254 @@ func TestFilterProductsHandler(t *testing.T) {
 			t.Errorf("expected empty result, got %d items", got)
 		}
 	})
+
+	t.Run("Total count comes with the page", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/products/filter?offset=1&limit=2", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		var resp handlers.ProductsSearchResult
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("error decoding response: %v", err)
+		}
+		if resp.Meta.TotalCount != 4 {
+			t.Errorf("expected total 4, got %d", resp.Meta.TotalCount)
+		}
+	})
+
+	t.Run("Total count past the last page", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/products/filter?offset=10&limit=2", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		var resp handlers.ProductsSearchResult
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("error decoding response: %v", err)
+		}
+		if resp.Meta.TotalCount != 4 {
+			t.Errorf("expected total 4, got %d", resp.Meta.TotalCount)
+		}
+	})
+
+	t.Run("Skip total", func(t *testing.T) {
+		for _, tc := range []struct {
+			offset  string
+			hasMore bool
+		}{{"0", true}, {"2", false}} {
+			req := httptest.NewRequest(http.MethodGet, "/products/filter?include_total=false&limit=2&offset="+tc.offset, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			var resp handlers.ProductsSearchResult
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("error decoding response: %v", err)
+			}
+			if len(resp.Data) != 2 {
+				t.Errorf("offset %s: expected 2 products, got %d", tc.offset, len(resp.Data))
+			}
+			if resp.Meta.TotalCount != -1 || resp.Meta.HasMore == nil || *resp.Meta.HasMore != tc.hasMore {
+				t.Errorf("offset %s: expected total -1 and has_more %v, got %+v", tc.offset, tc.hasMore, resp.Meta)
+			}
+		}
+	})
+
+	t.Run("Invalid include_total", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/products/filter?include_total=maybe", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected 400 Bad Request, got %d", w.Code)
+		}
+	})
+
+	filter := func(t *testing.T, query string) []handlers.ProductResponse {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/products/filter?"+query, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		var resp handlers.ProductsSearchResult
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("error decoding response: %v", err)
+		}
+		return resp.Data
+	}
+
+	t.Run("Timestamps are returned", func(t *testing.T) {
+		for _, p := range filter(t, "") {
+			created, err := time.Parse(time.RFC3339, p.CreatedAt)
+			if err != nil {
+				t.Fatalf("expected an RFC3339 created_at, got %q", p.CreatedAt)
+			}
+			if updated, err := time.Parse(time.RFC3339, p.UpdatedAt); err != nil || updated.Before(created) {
+				t.Errorf("expected updated_at not before created_at, got %q and %q", p.CreatedAt, p.UpdatedAt)
+			}
+		}
+	})
+
+	t.Run("Changed since", func(t *testing.T) {
+		all := filter(t, "sort=updated_at")
+		if len(all) != 4 {
+			t.Fatalf("expected 4 products, got %d", len(all))
+		}
+		if got := filter(t, "updated_since="+url.QueryEscape(all[0].UpdatedAt)); len(got) != 4 {
+			t.Errorf("expected every product changed since the oldest change, got %d", len(got))
+		}
+		future := time.Now().UTC().Add(time.Hour).Format(time.RFC3339)
+		if got := filter(t, "updated_since="+url.QueryEscape(future)); len(got) != 0 {
+			t.Errorf("expected no product changed in the future, got %d", len(got))
+		}
+	})
+
+	t.Run("Newest changes first", func(t *testing.T) {
+		got := filter(t, "sort=-updated_at")
+		for i := 1; i < len(got); i++ {
+			if got[i].UpdatedAt > got[i-1].UpdatedAt {
+				t.Errorf("expected newest first, got %s after %s", got[i].UpdatedAt, got[i-1].UpdatedAt)
+			}
+		}
+	})
+
+	t.Run("Sort and order", func(t *testing.T) {
+		cases := []struct {
+			query string
+			names []string
+		}{
+			{"sort=name", []string{"Laptop", "Monitor", "Mouse", "Phone"}},
+			{"sort=price&order=desc", []string{"Laptop", "Phone", "Monitor", "Mouse"}},
+			{"sort=quantity", []string{"Laptop", "Phone", "Monitor", "Mouse"}},
+			{"sort=quantity&order=desc&limit=2", []string{"Mouse", "Monitor"}},
+			{"order=desc", []string{"Monitor", "Mouse", "Laptop", "Phone"}},
+		}
+		for _, c := range cases {
+			got := filter(t, c.query)
+			names := make([]string, len(got))
+			for i, p := range got {
+				names[i] = p.Name
+			}
+			if !slices.Equal(names, c.names) {
+				t.Errorf("%s: expected %v, got %v", c.query, c.names, names)
+			}
+		}
+	})
+
+	for _, query := range []string{"sort=cost", "sort=name;DROP", "order=up", "updated_since=yesterday"} {
+		t.Run("Invalid "+query, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/products/filter?"+query, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("expected 400 Bad Request, got %d", w.Code)
+			}
+		})
+	}
+}
+
+func TestProductNameUniqueness(t *testing.T) {
+	t.Cleanup(clearAllProducts)
+	r := router.NewRouter()
+
+	if w := createProduct(r, handlers.ProductRequest{Name: "Mouse", Price: 10, Quantity: 1}); w.Code != http.StatusCreated {
+		t.Fatalf("failed to create product: %d", w.Code)
+	}
+
+	t.Run("Create with a name differing only in case", func(t *testing.T) {
+		w := createProduct(r, handlers.ProductRequest{Name: "mouse", Price: 10, Quantity: 1})
+		if w.Code != http.StatusConflict {
+			t.Fatalf("expected 409 Conflict, got %d", w.Code)
+		}
+		var resp handlers.ErrorResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if resp.Code != handlers.ErrCodeDuplicateName {
+			t.Errorf("expected code %q, got %q", handlers.ErrCodeDuplicateName, resp.Code)
+		}
+	})
+
+	t.Run("Rename onto an existing name", func(t *testing.T) {
+		w := createProduct(r, handlers.ProductRequest{Name: "Keyboard", Price: 10, Quantity: 1})
+		var created handlers.ProductResponse
+		if err := json.NewDecoder(w.Body).Decode(&created); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+
+		body, _ := json.Marshal(handlers.ProductRequest{Name: "MOUSE", Price: 10, Quantity: 1})
+		req := httptest.NewRequest(http.MethodPut, fmt.Sprintf("/products/%d", created.Id), bytes.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusConflict {
+			t.Errorf("expected 409 Conflict, got %d", rec.Code)
+		}
+	})
+}
+
+func TestMergeProductsHandler(t *testing.T) {
+	t.Cleanup(clearAllProducts)
+	r := router.NewRouter()
+
+	create := func(name string, qty int) int {
+		w := createProduct(r, handlers.ProductRequest{Name: name, Price: 5, Quantity: qty})
+		var p handlers.ProductResponse
+		if err := json.NewDecoder(w.Body).Decode(&p); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return p.Id
+	}
+	target := create("Cable", 10)
+	source := create("Cable (old)", 4)
+	if w := adjustProduct(r, source, handlers.QuantityAdjustmentRequest{Delta: 3}); w.Code != http.StatusOK {
+		t.Fatalf("failed to adjust quantity: %d", w.Code)
+	}
+
+	merge := func(source, target int) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(handlers.MergeProductsRequest{SourceID: source, TargetID: target})
+		req := httptest.NewRequest(http.MethodPost, "/admin/products/merge", bytes.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Merge folds stock and history", func(t *testing.T) {
+		w := merge(source, target)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d", w.Code)
+		}
+		var merged handlers.ProductResponse
+		if err := json.NewDecoder(w.Body).Decode(&merged); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if merged.Quantity != 17 {
+			t.Errorf("expected quantity 17, got %d", merged.Quantity)
+		}
+
+		if _, err := productRepo.GetByID(source); err == nil {
+			t.Error("expected source product to be deleted")
+		}
+		_, total, err := movementRepo.GetByProductID(target, repo.MovementFilter{})
+		if err != nil {
+			t.Fatalf("failed to fetch movements: %v", err)
+		}
+		if total != 1 {
+			t.Errorf("expected 1 movement on target, got %d", total)
+		}
+	})
+
+	t.Run("Merge into itself", func(t *testing.T) {
+		if w := merge(target, target); w.Code != http.StatusBadRequest {
+			t.Errorf("expected 400 Bad Request, got %d", w.Code)
+		}
+	})
+
+	t.Run("Unknown source", func(t *testing.T) {
+		if w := merge(999999, target); w.Code != http.StatusNotFound {
+			t.Errorf("expected 404 Not Found, got %d", w.Code)
+		}
+	})
 }
@@ -0,0 +1,117 @@
+package handlers_integrated_test_suite
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+)
+
+func TestProductHealthScore(t *testing.T) {
+	t.Cleanup(clearAllProducts)
+	r := router.NewRouter()
+
+	create := func(p handlers.ProductRequest) int {
+		w := createProduct(r, p)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("failed to create %s: %d %s", p.Name, w.Code, w.Body.String())
+		}
+		var created handlers.ProductResponse
+		if err := json.NewDecoder(w.Body).Decode(&created); err != nil {
+			t.Fatalf("failed to decode product: %v", err)
+		}
+		return created.Id
+	}
+	completeID := create(handlers.ProductRequest{Name: "Complete", Price: 10, Cost: 5, Quantity: 10, Threshold: 5,
+		SKU: "CMP-1", Barcode: "4006381333931", Category: "tools", Description: "Fully described", Brand: "Acme", Weight: 1})
+	create(handlers.ProductRequest{Name: "Out of stock", Price: 10, Cost: 9.5, Quantity: 0, Threshold: 5})
+	bareID := create(handlers.ProductRequest{Name: "Bare", Price: 10, Quantity: 2, Threshold: 5})
+	if _, err := database.Exec("UPDATE products SET updated_at = $1 WHERE id = $2", time.Now().UTC().AddDate(0, 0, -120), bareID); err != nil {
+		t.Fatalf("failed to age product: %v", err)
+	}
+
+	get := func(t *testing.T, path string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	filter := func(t *testing.T, query string) []handlers.ProductResponse {
+		t.Helper()
+		w := get(t, "/products/filter?"+query)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		var resp handlers.ProductsSearchResult
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return resp.Data
+	}
+	names := func(products []handlers.ProductResponse) []string {
+		names := make([]string, len(products))
+		for i, p := range products {
+			names[i] = p.Name
+		}
+		return names
+	}
+
+	t.Run("Least healthy first", func(t *testing.T) {
+		got := filter(t, "sort=health&include=health")
+		if want := []string{"Out of stock", "Bare", "Complete"}; !slices.Equal(names(got), want) {
+			t.Fatalf("expected %v, got %v", want, names(got))
+		}
+		for i, score := range []int{33, 45, 100} {
+			if got[i].Health == nil || got[i].Health.Score != score {
+				t.Errorf("%s: expected score %d, got %+v", got[i].Name, score, got[i].Health)
+			}
+		}
+		if h := got[1].Health; h.Stock != 10 || h.Freshness != 10 || h.Margin != 25 || h.Completeness != 0 {
+			t.Errorf("unexpected components for Bare: %+v", h)
+		}
+	})
+
+	t.Run("Filter by score", func(t *testing.T) {
+		if got := filter(t, "max_health=50&sort=health"); !slices.Equal(names(got), []string{"Out of stock", "Bare"}) {
+			t.Errorf("expected the two unhealthy products, got %v", names(got))
+		}
+		if got := filter(t, "min_health=45&max_health=99"); !slices.Equal(names(got), []string{"Bare"}) {
+			t.Errorf("expected Bare, got %v", names(got))
+		}
+	})
+
+	t.Run("Health only when asked for", func(t *testing.T) {
+		for _, p := range filter(t, "sort=health") {
+			if p.Health != nil {
+				t.Errorf("expected no health for %s, got %+v", p.Name, p.Health)
+			}
+		}
+	})
+
+	t.Run("Product detail", func(t *testing.T) {
+		w := get(t, fmt.Sprintf("/products/%d?include=health", completeID))
+		var p handlers.ProductResponse
+		if err := json.NewDecoder(w.Body).Decode(&p); err != nil {
+			t.Fatalf("failed to decode product: %v", err)
+		}
+		if p.Health == nil || p.Health.Score != 100 {
+			t.Errorf("expected a perfect score, got %+v", p.Health)
+		}
+	})
+
+	for _, query := range []string{"min_health=-1", "max_health=101", "max_health=low"} {
+		t.Run("Invalid "+query, func(t *testing.T) {
+			if w := get(t, "/products/filter?"+query); w.Code != http.StatusBadRequest {
+				t.Errorf("expected 400 Bad Request, got %d", w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package handlers_integrated_test_suite
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+)
+
+func TestProductSearch(t *testing.T) {
+	t.Cleanup(clearAllProducts)
+	r := router.NewRouter()
+
+	for _, p := range []handlers.ProductRequest{
+		{Name: "Office chair", Price: 120, Quantity: 3, Description: "Ergonomic chair with a mesh back and adjustable armrests"},
+		{Name: "Chair cushion", Price: 15, Quantity: 10},
+		{Name: "Desk lamp", Price: 30, Quantity: 4, SKU: "LMP-9"},
+		{Name: "Tom & Jerry mug", Price: 8, Quantity: 20},
+	} {
+		if w := createProduct(r, p); w.Code != http.StatusCreated {
+			t.Fatalf("failed to create product %s: %d %s", p.Name, w.Code, w.Body.String())
+		}
+	}
+
+	search := func(q string) []handlers.ProductResponse {
+		req := httptest.NewRequest(http.MethodGet, "/products/search?q="+url.QueryEscape(q), nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		var resp handlers.ProductsSearchResult
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return resp.Data
+	}
+
+	cases := []struct {
+		q         string
+		names     []string
+		field     string
+		highlight string
+		unordered bool
+	}{
+		{"ergonomic chair", []string{"Office chair"}, "name", "Office <mark>chair</mark>", false},
+		{"chairs", []string{"Office chair", "Chair cushion"}, "", "", true},
+		{"chaiir", []string{"Office chair", "Chair cushion"}, "", "", true},
+		{"lmp-9", []string{"Desk lamp"}, "sku", "<mark>LMP</mark>-<mark>9</mark>", false},
+		{"mug", []string{"Tom & Jerry mug"}, "name", "Tom &amp; Jerry <mark>mug</mark>", false},
+		{"sofa", nil, "", "", false},
+	}
+	for _, c := range cases {
+		t.Run(c.q, func(t *testing.T) {
+			results := search(c.q)
+			if len(results) != len(c.names) {
+				t.Fatalf("expected %v, got %+v", c.names, results)
+			}
+			found := map[string]bool{}
+			for i, p := range results {
+				found[p.Name] = true
+				if !c.unordered && p.Name != c.names[i] {
+					t.Errorf("expected %s at %d, got %s", c.names[i], i, p.Name)
+				}
+			}
+			for _, name := range c.names {
+				if !found[name] {
+					t.Errorf("expected %s among the results", name)
+				}
+			}
+			if c.field != "" && results[0].Highlights[c.field] != c.highlight {
+				t.Errorf("expected %s highlighted as %q, got %+v", c.field, c.highlight, results[0].Highlights)
+			}
+		})
+	}
+
+	t.Run("Description fragments", func(t *testing.T) {
+		results := search("armrests")
+		if len(results) != 1 || results[0].Highlights["description"] == "" {
+			t.Fatalf("expected a description highlight, got %+v", results)
+		}
+	})
+
+	t.Run("Query too long", func(t *testing.T) {
+		long := make([]byte, 201)
+		for i := range long {
+			long[i] = 'a'
+		}
+		req := httptest.NewRequest(http.MethodGet, "/products/search?q="+string(long), nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected 400 Bad Request, got %d", w.Code)
+		}
+	})
+}
@@ -0,0 +1,86 @@
+package handlers_integrated_test_suite
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+)
+
+func TestProductStatsInclude(t *testing.T) {
+	t.Cleanup(clearAllProducts)
+	r := router.NewRouter()
+
+	send := func(method, path string, body any) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		if body != nil {
+			_ = json.NewEncoder(&buf).Encode(body)
+		}
+		req := httptest.NewRequest(method, path, &buf)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	w := createProduct(r, handlers.ProductRequest{Name: "Desk lamp", Price: 30, Quantity: 10})
+	var product handlers.ProductResponse
+	if err := json.NewDecoder(w.Body).Decode(&product); err != nil {
+		t.Fatalf("failed to decode product: %v", err)
+	}
+	productPath := fmt.Sprintf("/products/%d", product.Id)
+
+	for _, delta := range []int{5, -3, -6} {
+		if w := send(http.MethodPost, productPath+"/adjust", handlers.QuantityAdjustmentRequest{Delta: delta}); w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK adjusting by %d, got %d: %s", delta, w.Code, w.Body.String())
+		}
+	}
+	if w := send(http.MethodPost, productPath+"/reservations", handlers.ReservationRequest{Quantity: 2}); w.Code != http.StatusCreated {
+		t.Fatalf("expected 201 Created, got %d: %s", w.Code, w.Body.String())
+	}
+
+	t.Run("Stats are embedded", func(t *testing.T) {
+		w := send(http.MethodGet, productPath+"?include=stats,suppliers", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		var p handlers.ProductResponse
+		if err := json.NewDecoder(w.Body).Decode(&p); err != nil {
+			t.Fatalf("failed to decode product: %v", err)
+		}
+		s := p.Stats
+		if s == nil {
+			t.Fatal("expected stats in the response")
+		}
+		if s.Count != 3 || s.Inbound != 1 || s.Outbound != 2 || s.LastMovementAt == nil {
+			t.Errorf("unexpected movement stats: %+v", s.MovementStats)
+		}
+		if s.UnitsOut30d != 9 || s.Velocity30d != 0.3 {
+			t.Errorf("expected 9 units out at 0.3 a day, got %d at %v", s.UnitsOut30d, s.Velocity30d)
+		}
+		if s.Reservations != 1 || s.ReservedUnits != 2 {
+			t.Errorf("expected 1 reservation of 2 units, got %d of %d", s.Reservations, s.ReservedUnits)
+		}
+	})
+
+	t.Run("Stats are left out unless asked for", func(t *testing.T) {
+		w := send(http.MethodGet, productPath, nil)
+		var p handlers.ProductResponse
+		if err := json.NewDecoder(w.Body).Decode(&p); err != nil {
+			t.Fatalf("failed to decode product: %v", err)
+		}
+		if p.Stats != nil {
+			t.Errorf("expected no stats, got %+v", p.Stats)
+		}
+	})
+
+	t.Run("Stats only on the product detail", func(t *testing.T) {
+		if w := send(http.MethodGet, "/products?include=stats", nil); w.Code != http.StatusBadRequest {
+			t.Errorf("expected 400 Bad Request, got %d", w.Code)
+		}
+	})
+}
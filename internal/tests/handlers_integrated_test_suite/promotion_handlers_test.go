@@ -0,0 +1,139 @@
+package handlers_integrated_test_suite
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+	"github.com/rogerio-castellano/inventory-tracker/internal/promotion"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+)
+
+func TestPromotionHandlers(t *testing.T) {
+	t.Cleanup(func() {
+		clearPromotions()
+		clearAllProducts()
+	})
+	r := router.NewRouter()
+
+	send := func(method, path string, body any) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		if body != nil {
+			_ = json.NewEncoder(&buf).Encode(body)
+		}
+		req := httptest.NewRequest(method, path, &buf)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	newProduct := func(name, category string, price float64) int {
+		w := createProduct(r, handlers.ProductRequest{Name: name, Category: category, Price: price, Quantity: 1})
+		var p handlers.ProductResponse
+		if err := json.NewDecoder(w.Body).Decode(&p); err != nil {
+			t.Fatalf("failed to decode product: %v", err)
+		}
+		return p.Id
+	}
+	getProduct := func(t *testing.T, id int) handlers.ProductResponse {
+		t.Helper()
+		w := send(http.MethodGet, fmt.Sprintf("/products/%d", id), nil)
+		var p handlers.ProductResponse
+		if err := json.NewDecoder(w.Body).Decode(&p); err != nil {
+			t.Fatalf("failed to decode product: %v", err)
+		}
+		return p
+	}
+	create := func(t *testing.T, req handlers.PromotionRequest) models.Promotion {
+		t.Helper()
+		w := send(http.MethodPost, "/promotions", req)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected 201 Created, got %d: %s", w.Code, w.Body.String())
+		}
+		var p models.Promotion
+		if err := json.NewDecoder(w.Body).Decode(&p); err != nil {
+			t.Fatalf("failed to decode promotion: %v", err)
+		}
+		return p
+	}
+
+	tent := newProduct("Tent", "camping", 200)
+	stove := newProduct("Stove", "camping", 50)
+	now := time.Now().UTC()
+	at := func(d time.Duration) string { return now.Add(d).Format(time.RFC3339) }
+
+	t.Run("Running product promotion sets the price", func(t *testing.T) {
+		promo := create(t, handlers.PromotionRequest{Name: "Tent week", ProductID: &tent, Price: 150, StartsAt: at(-time.Hour), EndsAt: at(time.Hour)})
+		if !promo.Active {
+			t.Fatalf("expected the promotion to start at once: %+v", promo)
+		}
+		p := getProduct(t, tent)
+		if p.Price != 150 || p.Promotion == nil || p.Promotion.ID != promo.ID || p.Promotion.RegularPrice != 200 {
+			t.Errorf("unexpected product: %+v", p)
+		}
+	})
+
+	t.Run("Category promotion takes the lowest price", func(t *testing.T) {
+		create(t, handlers.PromotionRequest{Name: "Camping sale", Category: "Camping", PercentOff: 10, StartsAt: at(-time.Hour), EndsAt: at(3 * time.Hour)})
+		if p := getProduct(t, stove); p.Price != 45 || p.Promotion == nil {
+			t.Errorf("expected 10%% off, got %+v", p)
+		}
+		if p := getProduct(t, tent); p.Price != 150 || p.Promotion.Name != "Tent week" {
+			t.Errorf("expected the lower fixed price to win, got %+v", p)
+		}
+	})
+
+	t.Run("Future promotion waits for the scheduler", func(t *testing.T) {
+		promo := create(t, handlers.PromotionRequest{Name: "Late deal", ProductID: &stove, Price: 30, StartsAt: at(2 * time.Hour), EndsAt: at(4 * time.Hour)})
+		if promo.Active || getProduct(t, stove).Price != 45 {
+			t.Fatalf("future promotion applied early: %+v", promo)
+		}
+
+		if err := promotion.Sync(repo.NewPostgresPromotionRepository(database), now.Add(150*time.Minute)); err != nil {
+			t.Fatalf("sync failed: %v", err)
+		}
+		if p := getProduct(t, stove); p.Price != 30 {
+			t.Errorf("expected the late deal, got %+v", p)
+		}
+		if p := getProduct(t, tent); p.Price != 180 || p.Promotion.Name != "Camping sale" {
+			t.Errorf("expected the tent week to have ended, got %+v", p)
+		}
+	})
+
+	t.Run("List active promotions", func(t *testing.T) {
+		var promotions []models.Promotion
+		if err := json.NewDecoder(send(http.MethodGet, "/promotions?active=true", nil).Body).Decode(&promotions); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(promotions) != 2 || promotions[0].Name != "Late deal" {
+			t.Errorf("unexpected promotions: %+v", promotions)
+		}
+	})
+
+	missing := 999999
+	cases := []struct {
+		name string
+		req  handlers.PromotionRequest
+		code int
+	}{
+		{"Product and category", handlers.PromotionRequest{Name: "x", ProductID: &tent, Category: "camping", Price: 1, StartsAt: at(0), EndsAt: at(time.Hour)}, http.StatusBadRequest},
+		{"Price and percent", handlers.PromotionRequest{Name: "x", ProductID: &tent, Price: 1, PercentOff: 5, StartsAt: at(0), EndsAt: at(time.Hour)}, http.StatusBadRequest},
+		{"Window backwards", handlers.PromotionRequest{Name: "x", ProductID: &tent, Price: 1, StartsAt: at(time.Hour), EndsAt: at(0)}, http.StatusBadRequest},
+		{"Already ended", handlers.PromotionRequest{Name: "x", ProductID: &tent, Price: 1, StartsAt: at(-2 * time.Hour), EndsAt: at(-time.Hour)}, http.StatusBadRequest},
+		{"Unknown product", handlers.PromotionRequest{Name: "x", ProductID: &missing, Price: 1, StartsAt: at(0), EndsAt: at(time.Hour)}, http.StatusNotFound},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if w := send(http.MethodPost, "/promotions", c.req); w.Code != c.code {
+				t.Errorf("expected %d, got %d: %s", c.code, w.Code, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package handlers_integrated_test_suite
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+)
+
+func TestReconciliationHandler(t *testing.T) {
+	t.Cleanup(clearAllProducts)
+	r := router.NewRouter()
+
+	w := createProduct(r, handlers.ProductRequest{Name: "Ledger", Price: 10.0, Quantity: 5})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("failed to create product: %d", w.Code)
+	}
+	var created handlers.ProductResponse
+	if err := json.NewDecoder(w.Body).Decode(&created); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if w := adjustProduct(r, created.Id, handlers.QuantityAdjustmentRequest{Delta: 4}); w.Code != http.StatusOK {
+		t.Fatalf("failed to adjust quantity: %d", w.Code)
+	}
+
+	t.Run("Initial quantity counts as baseline", func(t *testing.T) {
+		report := reconcile(t, r, http.MethodGet, "/admin/reconciliation")
+		if len(report.Discrepancies) != 0 {
+			t.Errorf("expected no discrepancies, got %v", report.Discrepancies)
+		}
+	})
+
+	t.Run("Untracked quantity change is reported", func(t *testing.T) {
+		if _, err := database.Exec(`UPDATE products SET quantity = quantity + 3 WHERE id = $1`, created.Id); err != nil {
+			t.Fatalf("failed to tamper with quantity: %v", err)
+		}
+
+		report := reconcile(t, r, http.MethodGet, "/admin/reconciliation")
+		if len(report.Discrepancies) != 1 {
+			t.Fatalf("expected 1 discrepancy, got %d", len(report.Discrepancies))
+		}
+		d := report.Discrepancies[0]
+		if d.ExpectedQuantity != 9 || d.Difference != 3 {
+			t.Errorf("expected expected=9 difference=3, got expected=%d difference=%d", d.ExpectedQuantity, d.Difference)
+		}
+	})
+
+	t.Run("Corrections realign the ledger", func(t *testing.T) {
+		report := reconcile(t, r, http.MethodPost, "/admin/reconciliation/corrections")
+		if len(report.Discrepancies) != 1 || !report.Discrepancies[0].Corrected {
+			t.Fatalf("expected 1 corrected discrepancy, got %v", report.Discrepancies)
+		}
+
+		report = reconcile(t, r, http.MethodGet, "/admin/reconciliation")
+		if len(report.Discrepancies) != 0 {
+			t.Errorf("expected no discrepancies after correction, got %v", report.Discrepancies)
+		}
+	})
+}
+
+func reconcile(t *testing.T, r http.Handler, method, path string) handlers.ReconciliationReport {
+	t.Helper()
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 OK, got %d", w.Code)
+	}
+
+	var report handlers.ReconciliationReport
+	if err := json.NewDecoder(w.Body).Decode(&report); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	return report
+}
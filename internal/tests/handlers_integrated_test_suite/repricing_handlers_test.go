@@ -0,0 +1,122 @@
+package handlers_integrated_test_suite
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+)
+
+func TestRepricingHandler(t *testing.T) {
+	t.Cleanup(clearAllProducts)
+	r := router.NewRouter()
+
+	ids := map[string]int{}
+	for _, p := range []handlers.ProductRequest{
+		{Name: "Espresso", Price: 2.50, Cost: 0.90, Category: "Coffee"},
+		{Name: "Latte", Price: 3.20, Cost: 1.20, Category: "Coffee"},
+		{Name: "Cold Brew", Price: 4.00, Category: "Coffee"},
+		{Name: "Croissant", Price: 2.10, Cost: 0.80, Category: "Bakery"},
+	} {
+		w := createProduct(r, p)
+		var created handlers.ProductResponse
+		if err := json.NewDecoder(w.Body).Decode(&created); err != nil {
+			t.Fatalf("failed to decode product: %v", err)
+		}
+		ids[p.Name] = created.Id
+	}
+
+	send := func(t *testing.T, body handlers.RepricingRequest) *httptest.ResponseRecorder {
+		t.Helper()
+		var buf bytes.Buffer
+		_ = json.NewEncoder(&buf).Encode(body)
+		req := httptest.NewRequest(http.MethodPost, "/admin/repricing", &buf)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	preview := func(t *testing.T, body handlers.RepricingRequest) handlers.RepricingPreview {
+		t.Helper()
+		w := send(t, body)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		var p handlers.RepricingPreview
+		if err := json.NewDecoder(w.Body).Decode(&p); err != nil {
+			t.Fatalf("failed to decode preview: %v", err)
+		}
+		return p
+	}
+	ending := 0.99
+
+	var marginPreview handlers.RepricingPreview
+	t.Run("Preview margin over cost", func(t *testing.T) {
+		marginPreview = preview(t, handlers.RepricingRequest{
+			Filter: handlers.RepricingFilter{Category: "coffee"},
+			Rule:   handlers.PricingRule{Type: "margin", Percent: 60, Rounding: &handlers.PriceRounding{Ending: &ending, Direction: "up"}},
+		})
+		if marginPreview.PreviewID == "" || marginPreview.Matched != 3 {
+			t.Fatalf("unexpected preview: %+v", marginPreview)
+		}
+		if len(marginPreview.Skipped) != 1 || marginPreview.Skipped[0].Reason != "no_cost" {
+			t.Errorf("expected Cold Brew to be skipped, got %+v", marginPreview.Skipped)
+		}
+		// 0.90 / 0.4 = 2.25 -> 2.99; 1.20 / 0.4 = 3.00 -> 3.99
+		want := map[int]float64{ids["Espresso"]: 2.99, ids["Latte"]: 3.99}
+		for _, c := range marginPreview.Changes {
+			if want[c.ProductID] != c.PriceAfter {
+				t.Errorf("product %d: expected %.2f, got %.2f", c.ProductID, want[c.ProductID], c.PriceAfter)
+			}
+		}
+		p, _ := productRepo.GetByID(ids["Espresso"])
+		if p.Price != 2.50 {
+			t.Errorf("preview must not change prices, got %.2f", p.Price)
+		}
+	})
+
+	t.Run("Apply preview", func(t *testing.T) {
+		w := send(t, handlers.RepricingRequest{PreviewID: marginPreview.PreviewID})
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected 201 Created, got %d: %s", w.Code, w.Body.String())
+		}
+		p, _ := productRepo.GetByID(ids["Latte"])
+		if p.Price != 3.99 {
+			t.Errorf("expected 3.99, got %.2f", p.Price)
+		}
+	})
+
+	t.Run("A preview applies only once", func(t *testing.T) {
+		if w := send(t, handlers.RepricingRequest{PreviewID: marginPreview.PreviewID}); w.Code != http.StatusNotFound {
+			t.Errorf("expected 404 Not Found, got %d", w.Code)
+		}
+	})
+
+	t.Run("Stale preview", func(t *testing.T) {
+		p := preview(t, handlers.RepricingRequest{
+			Filter: handlers.RepricingFilter{ProductIDs: []int{ids["Croissant"]}},
+			Rule:   handlers.PricingRule{Type: "percentage", Percent: 10, Rounding: &handlers.PriceRounding{Step: 0.05}},
+		})
+		if len(p.Changes) != 1 || p.Changes[0].PriceAfter != 2.30 {
+			t.Fatalf("unexpected preview: %+v", p)
+		}
+		body, _ := json.Marshal(handlers.ProductRequest{Name: "Croissant", Price: 2.20, Cost: 0.80, Category: "Bakery"})
+		req := httptest.NewRequest(http.MethodPut, fmt.Sprintf("/products/%d", ids["Croissant"]), bytes.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		r.ServeHTTP(httptest.NewRecorder(), req)
+		if w := send(t, handlers.RepricingRequest{PreviewID: p.PreviewID}); w.Code != http.StatusConflict {
+			t.Errorf("expected 409 Conflict, got %d", w.Code)
+		}
+	})
+
+	t.Run("Invalid rule", func(t *testing.T) {
+		if w := send(t, handlers.RepricingRequest{Rule: handlers.PricingRule{Type: "margin", Percent: 100}}); w.Code != http.StatusBadRequest {
+			t.Errorf("expected 400 Bad Request, got %d", w.Code)
+		}
+	})
+}
@@ -0,0 +1,104 @@
+package handlers_integrated_test_suite
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+func TestReservationHandlers(t *testing.T) {
+	t.Cleanup(clearAllProducts)
+	r := router.NewRouter()
+
+	send := func(method, path string, body any) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		if body != nil {
+			_ = json.NewEncoder(&buf).Encode(body)
+		}
+		req := httptest.NewRequest(method, path, &buf)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	w := createProduct(r, handlers.ProductRequest{Name: "Gift box", Price: 15, Quantity: 10})
+	var product handlers.ProductResponse
+	if err := json.NewDecoder(w.Body).Decode(&product); err != nil {
+		t.Fatalf("failed to decode product: %v", err)
+	}
+	productPath := fmt.Sprintf("/products/%d", product.Id)
+
+	var reservation models.StockReservation
+	t.Run("Reserve stock", func(t *testing.T) {
+		w := send(http.MethodPost, productPath+"/reservations", handlers.ReservationRequest{Quantity: 6, Reference: "ORD-1001"})
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected 201 Created, got %d: %s", w.Code, w.Body.String())
+		}
+		if err := json.NewDecoder(w.Body).Decode(&reservation); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if reservation.Quantity != 6 || reservation.Reference != "ORD-1001" || !reservation.ExpiresAt.After(reservation.CreatedAt) {
+			t.Errorf("unexpected reservation: %+v", reservation)
+		}
+	})
+
+	t.Run("Reserved stock is not available", func(t *testing.T) {
+		w := send(http.MethodGet, productPath, nil)
+		var p handlers.ProductResponse
+		if err := json.NewDecoder(w.Body).Decode(&p); err != nil {
+			t.Fatalf("failed to decode product: %v", err)
+		}
+		if p.Quantity != 10 || p.Reserved == nil || *p.Reserved != 6 || *p.Available != 4 {
+			t.Errorf("expected 6 reserved and 4 available, got %+v", p)
+		}
+
+		if w := send(http.MethodPost, productPath+"/adjust", handlers.QuantityAdjustmentRequest{Delta: -5}); w.Code != http.StatusConflict {
+			t.Errorf("expected 409 Conflict taking reserved units, got %d", w.Code)
+		}
+		if w := send(http.MethodPost, productPath+"/reservations", handlers.ReservationRequest{Quantity: 5}); w.Code != http.StatusConflict {
+			t.Errorf("expected 409 Conflict reserving reserved units, got %d", w.Code)
+		}
+		if w := send(http.MethodPost, productPath+"/adjust", handlers.QuantityAdjustmentRequest{Delta: -4}); w.Code != http.StatusOK {
+			t.Errorf("expected 200 OK taking unreserved units, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("Release", func(t *testing.T) {
+		path := fmt.Sprintf("/reservations/%d", reservation.ID)
+		if w := send(http.MethodDelete, path, nil); w.Code != http.StatusNoContent {
+			t.Fatalf("expected 204 No Content, got %d", w.Code)
+		}
+		if w := send(http.MethodDelete, path, nil); w.Code != http.StatusNotFound {
+			t.Errorf("expected 404 Not Found releasing twice, got %d", w.Code)
+		}
+		if w := send(http.MethodPost, productPath+"/adjust", handlers.QuantityAdjustmentRequest{Delta: -6}); w.Code != http.StatusOK {
+			t.Errorf("expected 200 OK once released, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	cases := []struct {
+		name string
+		path string
+		body handlers.ReservationRequest
+		code int
+	}{
+		{"Zero quantity", productPath + "/reservations", handlers.ReservationRequest{}, http.StatusBadRequest},
+		{"Negative TTL", productPath + "/reservations", handlers.ReservationRequest{Quantity: 1, TTLSeconds: -1}, http.StatusBadRequest},
+		{"TTL too long", productPath + "/reservations", handlers.ReservationRequest{Quantity: 1, TTLSeconds: 30 * 24 * 3600}, http.StatusBadRequest},
+		{"Unknown product", "/products/999999/reservations", handlers.ReservationRequest{Quantity: 1}, http.StatusNotFound},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if w := send(http.MethodPost, c.path, c.body); w.Code != c.code {
+				t.Errorf("expected %d, got %d: %s", c.code, w.Code, w.Body.String())
+			}
+		})
+	}
+}
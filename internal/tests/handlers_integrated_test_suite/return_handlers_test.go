@@ -0,0 +1,139 @@
+package handlers_integrated_test_suite
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+func TestReturnHandlers(t *testing.T) {
+	t.Cleanup(clearAllProducts)
+	r := router.NewRouter()
+
+	w := createProduct(r, handlers.ProductRequest{Name: "Headphones", Price: 80.0, Quantity: 10})
+	var product handlers.ProductResponse
+	if err := json.NewDecoder(w.Body).Decode(&product); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if w := adjustProduct(r, product.Id, handlers.QuantityAdjustmentRequest{Delta: -4}); w.Code != http.StatusOK {
+		t.Fatalf("expected 200 OK, got %d", w.Code)
+	}
+	shipment, err := movementRepo.Log(models.Movement{ProductID: product.Id, Delta: -4})
+	if err != nil {
+		t.Fatalf("failed to log shipment: %v", err)
+	}
+
+	post := func(url string, body any) *httptest.ResponseRecorder {
+		payload, _ := json.Marshal(body)
+		req := httptest.NewRequest(http.MethodPost, url, bytes.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	decode := func(w *httptest.ResponseRecorder) models.Return {
+		var ret models.Return
+		if err := json.NewDecoder(w.Body).Decode(&ret); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return ret
+	}
+
+	var restocked, scrapped models.Return
+	t.Run("Register returns", func(t *testing.T) {
+		w := post("/returns", handlers.ReturnRequest{MovementID: shipment.ID, Quantity: 2, Reason: "wrong colour", OrderRef: "SO-1001"})
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected 201 Created, got %d: %s", w.Code, w.Body.String())
+		}
+		restocked = decode(w)
+		if restocked.Status != models.ReturnStatusPending || restocked.ProductID != product.Id {
+			t.Errorf("unexpected return: %+v", restocked)
+		}
+
+		w = post("/returns", handlers.ReturnRequest{MovementID: shipment.ID, Quantity: 1, Reason: "broken"})
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected 201 Created, got %d: %s", w.Code, w.Body.String())
+		}
+		scrapped = decode(w)
+	})
+
+	t.Run("Cannot return more than shipped", func(t *testing.T) {
+		if w := post("/returns", handlers.ReturnRequest{MovementID: shipment.ID, Quantity: 2}); w.Code != http.StatusConflict {
+			t.Errorf("expected 409 Conflict, got %d", w.Code)
+		}
+	})
+
+	t.Run("Rejects inbound movements", func(t *testing.T) {
+		inbound, err := movementRepo.Log(models.Movement{ProductID: product.Id, Delta: 3})
+		if err != nil {
+			t.Fatalf("failed to log movement: %v", err)
+		}
+		if w := post("/returns", handlers.ReturnRequest{MovementID: inbound.ID, Quantity: 1}); w.Code != http.StatusBadRequest {
+			t.Errorf("expected 400 Bad Request, got %d", w.Code)
+		}
+	})
+
+	t.Run("Restock adds stock with an inbound movement", func(t *testing.T) {
+		w := post(fmt.Sprintf("/returns/%d/inspect", restocked.ID), handlers.InspectReturnRequest{Status: "restock"})
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		ret := decode(w)
+		if ret.Status != models.ReturnStatusRestock || ret.RestockMovementID == nil || ret.InspectedBy != "admin" {
+			t.Fatalf("unexpected return: %+v", ret)
+		}
+
+		p, err := productRepo.GetByID(product.Id)
+		if err != nil || p.Quantity != 8 {
+			t.Errorf("expected quantity 8 after restock, got %d (%v)", p.Quantity, err)
+		}
+		m, err := movementRepo.GetByID(*ret.RestockMovementID)
+		if err != nil || m.Delta != 2 {
+			t.Errorf("expected an inbound movement of 2, got %+v (%v)", m, err)
+		}
+	})
+
+	t.Run("Restocked returns are final", func(t *testing.T) {
+		w := post(fmt.Sprintf("/returns/%d/inspect", restocked.ID), handlers.InspectReturnRequest{Status: "restock"})
+		if w.Code != http.StatusConflict {
+			t.Errorf("expected 409 Conflict, got %d", w.Code)
+		}
+	})
+
+	t.Run("Refurbish then scrap leaves stock alone", func(t *testing.T) {
+		for _, status := range []string{"refurbish", "scrap"} {
+			w := post(fmt.Sprintf("/returns/%d/inspect", scrapped.ID), handlers.InspectReturnRequest{Status: status})
+			if w.Code != http.StatusOK {
+				t.Fatalf("%s: expected 200 OK, got %d: %s", status, w.Code, w.Body.String())
+			}
+		}
+
+		p, err := productRepo.GetByID(product.Id)
+		if err != nil || p.Quantity != 8 {
+			t.Errorf("expected quantity to stay 8, got %d (%v)", p.Quantity, err)
+		}
+	})
+
+	t.Run("List by status", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/returns?status=scrap", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		var returns []models.Return
+		if err := json.NewDecoder(w.Body).Decode(&returns); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(returns) != 1 || returns[0].ID != scrapped.ID {
+			t.Errorf("expected only the scrapped return, got %+v", returns)
+		}
+	})
+}
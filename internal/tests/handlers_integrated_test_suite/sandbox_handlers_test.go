@@ -0,0 +1,116 @@
+package handlers_integrated_test_suite
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+	"github.com/rogerio-castellano/inventory-tracker/internal/security"
+)
+
+func TestSandboxSimulateHandler(t *testing.T) {
+	events := make(chan security.Event, 10)
+	sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var e security.Event
+		if err := json.Unmarshal(body, &e); err != nil {
+			t.Errorf("failed to decode event: %v", err)
+		}
+		events <- e
+	}))
+	defer sink.Close()
+	security.Configure([]security.Sink{security.NewWebhookSink(sink.URL, "")}, map[string]bool{security.EventSandbox: true})
+	t.Cleanup(func() {
+		security.Configure(nil, nil)
+		clearAllProducts()
+	})
+	r := router.NewRouter()
+
+	simulate := func(req handlers.SandboxSimulateRequest) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(req)
+		httpReq := httptest.NewRequest(http.MethodPost, "/admin/sandbox/simulate", bytes.NewReader(body))
+		httpReq.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httpReq)
+		return w
+	}
+
+	w := createProduct(r, handlers.ProductRequest{Name: "Sandbox Widget", Price: 10, Quantity: 50})
+	var p handlers.ProductResponse
+	if err := json.NewDecoder(w.Body).Decode(&p); err != nil {
+		t.Fatalf("failed to decode product: %v", err)
+	}
+
+	t.Run("Stock changes are deterministic", func(t *testing.T) {
+		var runs [2]handlers.SandboxSimulation
+		for i := range runs {
+			w := simulate(handlers.SandboxSimulateRequest{Kind: handlers.SimulateStockChanges, Count: 5, Seed: 42, ProductID: p.Id})
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+			}
+			if err := json.NewDecoder(w.Body).Decode(&runs[i]); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+		}
+		if len(runs[0].StockChanges) != 5 {
+			t.Fatalf("expected 5 stock changes, got %+v", runs[0].StockChanges)
+		}
+		quantity := 50
+		for i, c := range runs[0].StockChanges {
+			if c.Delta != runs[1].StockChanges[i].Delta {
+				t.Errorf("change %d differs between runs with the same seed: %d and %d", i, c.Delta, runs[1].StockChanges[i].Delta)
+			}
+			quantity += c.Delta
+			if c.Quantity != quantity {
+				t.Errorf("expected quantity %d after change %d, got %d", quantity, i, c.Quantity)
+			}
+		}
+	})
+
+	t.Run("Rate limit", func(t *testing.T) {
+		w := simulate(handlers.SandboxSimulateRequest{Kind: handlers.SimulateRateLimit})
+		if w.Code != http.StatusTooManyRequests {
+			t.Fatalf("expected 429 Too Many Requests, got %d", w.Code)
+		}
+		if w.Header().Get("Retry-After") == "" || w.Header().Get("X-RateLimit-Remaining") != "0" {
+			t.Errorf("expected rate limit headers, got %v", w.Header())
+		}
+	})
+
+	t.Run("Webhook events", func(t *testing.T) {
+		w := simulate(handlers.SandboxSimulateRequest{Kind: handlers.SimulateWebhookEvents, Count: 2, Seed: 7})
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		for range 2 {
+			select {
+			case e := <-events:
+				if e.Type != security.EventSandbox || e.Details["simulated"] != true {
+					t.Errorf("unexpected event: %+v", e)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("no sandbox event received")
+			}
+		}
+	})
+
+	t.Run("Unknown kind", func(t *testing.T) {
+		if w := simulate(handlers.SandboxSimulateRequest{Kind: "meteor"}); w.Code != http.StatusBadRequest {
+			t.Errorf("expected 400 Bad Request, got %d", w.Code)
+		}
+	})
+
+	t.Run("Sandbox mode off", func(t *testing.T) {
+		handlers.SetSandboxEnabled(false)
+		defer handlers.SetSandboxEnabled(true)
+		if w := simulate(handlers.SandboxSimulateRequest{Kind: handlers.SimulateRateLimit}); w.Code != http.StatusNotFound {
+			t.Errorf("expected 404 Not Found, got %d", w.Code)
+		}
+	})
+}
@@ -0,0 +1,63 @@
+package handlers_integrated_test_suite
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+)
+
+func TestScanHandler(t *testing.T) {
+	t.Cleanup(clearAllProducts)
+	r := router.NewRouter()
+
+	createProduct(r, handlers.ProductRequest{Name: "Stapler", Price: 9.0, Quantity: 3, Threshold: 2, Barcode: "7891234567895"})
+	createProduct(r, handlers.ProductRequest{Name: "Blue pen", Price: 1.0, Quantity: 10, Barcode: "1111111111116"})
+	createProduct(r, handlers.ProductRequest{Name: "Red pen", Price: 1.0, Quantity: 10, Barcode: "1111111111116"})
+
+	scan := func(req handlers.ScanRequest) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(req)
+		httpReq := httptest.NewRequest(http.MethodPost, "/scan", bytes.NewReader(body))
+		httpReq.Header.Set("Content-Type", "application/json")
+		httpReq.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httpReq)
+		return w
+	}
+
+	t.Run("Adjusts by barcode", func(t *testing.T) {
+		w := scan(handlers.ScanRequest{Barcode: "7891234567895", Delta: -2, Warehouse: "north"})
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		var resp handlers.ScanResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if resp.Name != "Stapler" || resp.Quantity != 1 || !resp.LowStock {
+			t.Errorf("unexpected response: %+v", resp)
+		}
+	})
+
+	cases := []struct {
+		name string
+		req  handlers.ScanRequest
+		code int
+	}{
+		{"Unknown barcode", handlers.ScanRequest{Barcode: "0000000000000", Delta: 1}, http.StatusNotFound},
+		{"Ambiguous barcode", handlers.ScanRequest{Barcode: "1111111111116", Delta: 1}, http.StatusConflict},
+		{"Insufficient stock", handlers.ScanRequest{Barcode: "7891234567895", Delta: -5}, http.StatusConflict},
+		{"Zero delta", handlers.ScanRequest{Barcode: "7891234567895"}, http.StatusBadRequest},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if w := scan(c.req); w.Code != c.code {
+				t.Errorf("expected %d, got %d", c.code, w.Code)
+			}
+		})
+	}
+}
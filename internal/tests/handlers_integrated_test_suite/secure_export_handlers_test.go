@@ -0,0 +1,128 @@
+package handlers_integrated_test_suite
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+func TestSecureExports(t *testing.T) {
+	t.Cleanup(func() {
+		clearExportRecipients()
+		clearAllProducts()
+	})
+	r := router.NewRouter()
+
+	send := func(method, path string, body any, headers map[string]string) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		if body != nil {
+			_ = json.NewEncoder(&buf).Encode(body)
+		}
+		req := httptest.NewRequest(method, path, &buf)
+		req.Header.Set("Authorization", "Bearer "+token)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	createProduct(r, handlers.ProductRequest{Name: "Bolts", Price: 10, Cost: 4, Quantity: 5})
+
+	entity, err := openpgp.NewEntity("Auditor", "", "auditor@example.com", nil)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	var publicKey bytes.Buffer
+	aw, _ := armor.Encode(&publicKey, openpgp.PublicKeyType, nil)
+	if err := entity.Serialize(aw); err != nil {
+		t.Fatalf("failed to serialize key: %v", err)
+	}
+	_ = aw.Close()
+
+	var recipient models.ExportRecipient
+	t.Run("Register recipient", func(t *testing.T) {
+		w := send(http.MethodPost, "/admin/export-recipients", handlers.ExportRecipientRequest{Name: "Auditor", Email: "auditor@example.com", PublicKey: publicKey.String()}, nil)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected 201 Created, got %d: %s", w.Code, w.Body.String())
+		}
+		if err := json.NewDecoder(w.Body).Decode(&recipient); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if want := fmt.Sprintf("%X", entity.PrimaryKey.Fingerprint); recipient.Fingerprint != want {
+			t.Errorf("expected fingerprint %s, got %s", want, recipient.Fingerprint)
+		}
+	})
+
+	t.Run("Password-protected archive", func(t *testing.T) {
+		w := send(http.MethodGet, "/metrics/valuation/export?encryption=zip", nil, map[string]string{handlers.ExportPasswordHeader: "correct horse battery"})
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		if ct := w.Header().Get("Content-Type"); ct != "application/zip" {
+			t.Errorf("expected a ZIP archive, got %s", ct)
+		}
+		archive, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+		if err != nil {
+			t.Fatalf("failed to read archive: %v", err)
+		}
+		if len(archive.File) != 1 || archive.File[0].Flags&0x1 == 0 {
+			t.Errorf("expected one encrypted file, got %+v", archive.File)
+		}
+		if bytes.Contains(w.Body.Bytes(), []byte("Bolts")) {
+			t.Error("expected the archive not to hold the export in the clear")
+		}
+	})
+
+	t.Run("PGP-encrypted email", func(t *testing.T) {
+		w := send(http.MethodGet, fmt.Sprintf("/admin/audit/export?format=csv&encryption=pgp&recipient_id=%d", recipient.ID), nil, nil)
+		if w.Code != http.StatusAccepted {
+			t.Fatalf("expected 202 Accepted, got %d: %s", w.Code, w.Body.String())
+		}
+		var delivery handlers.ExportDelivery
+		if err := json.NewDecoder(w.Body).Decode(&delivery); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if delivery.Email != "auditor@example.com" || delivery.Filename != "audit_log.csv.asc" {
+			t.Errorf("unexpected delivery: %+v", delivery)
+		}
+	})
+
+	cases := []struct {
+		name    string
+		method  string
+		path    string
+		body    any
+		headers map[string]string
+		code    int
+	}{
+		{"Plain export", http.MethodGet, "/metrics/valuation/export", nil, nil, http.StatusOK},
+		{"Short password", http.MethodGet, "/metrics/valuation/export?encryption=zip", nil, map[string]string{handlers.ExportPasswordHeader: "secret"}, http.StatusBadRequest},
+		{"Unknown encryption", http.MethodGet, "/admin/audit/export?format=json&encryption=rot13", nil, nil, http.StatusBadRequest},
+		{"PGP without recipient", http.MethodGet, "/metrics/valuation/export?encryption=pgp", nil, nil, http.StatusBadRequest},
+		{"Unknown recipient", http.MethodGet, "/metrics/valuation/export?encryption=pgp&recipient_id=999999", nil, nil, http.StatusNotFound},
+		{"Key already registered", http.MethodPost, "/admin/export-recipients", handlers.ExportRecipientRequest{Name: "Again", Email: "again@example.com", PublicKey: publicKey.String()}, nil, http.StatusConflict},
+		{"Not a key", http.MethodPost, "/admin/export-recipients", handlers.ExportRecipientRequest{Name: "Bad", Email: "bad@example.com", PublicKey: "not a key"}, nil, http.StatusBadRequest},
+		{"Invalid email", http.MethodPost, "/admin/export-recipients", handlers.ExportRecipientRequest{Name: "Bad", Email: "bad", PublicKey: publicKey.String()}, nil, http.StatusBadRequest},
+		{"List recipients", http.MethodGet, "/admin/export-recipients", nil, nil, http.StatusOK},
+		{"Remove recipient", http.MethodDelete, fmt.Sprintf("/admin/export-recipients/%d", recipient.ID), nil, nil, http.StatusNoContent},
+		{"Remove again", http.MethodDelete, fmt.Sprintf("/admin/export-recipients/%d", recipient.ID), nil, nil, http.StatusNotFound},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if w := send(c.method, c.path, c.body, c.headers); w.Code != c.code {
+				t.Errorf("expected %d, got %d: %s", c.code, w.Code, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package handlers_integrated_test_suite
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+	"github.com/rogerio-castellano/inventory-tracker/internal/security"
+)
+
+func TestSecurityEventWebhooks(t *testing.T) {
+	const secret = "webhook-secret"
+	events := make(chan security.Event, 10)
+	siem := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(body)
+		if r.Header.Get("X-Signature-256") != "sha256="+hex.EncodeToString(mac.Sum(nil)) {
+			t.Errorf("unexpected signature %q", r.Header.Get("X-Signature-256"))
+		}
+		var e security.Event
+		if err := json.Unmarshal(body, &e); err != nil {
+			t.Errorf("failed to decode event: %v", err)
+		}
+		events <- e
+	}))
+	defer siem.Close()
+
+	security.Configure([]security.Sink{security.NewWebhookSink(siem.URL, secret)}, map[string]bool{
+		security.EventRoleChange:    true,
+		security.EventImpersonation: true,
+	})
+	t.Cleanup(func() {
+		security.Configure(nil, nil)
+		clearAllUsersExceptAdmin()
+	})
+	r := router.NewRouter()
+
+	next := func(t *testing.T) security.Event {
+		t.Helper()
+		select {
+		case e := <-events:
+			return e
+		case <-time.After(5 * time.Second):
+			t.Fatal("no security event received")
+			return security.Event{}
+		}
+	}
+
+	t.Run("Role change", func(t *testing.T) {
+		body, _ := json.Marshal(handlers.RegisterAsAdminRequest{Username: "siem-auditor", Password: "secret", Role: "admin"})
+		req := httptest.NewRequest(http.MethodPost, "/admin/users", bytes.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected 201 Created, got %d: %s", w.Code, w.Body.String())
+		}
+
+		e := next(t)
+		if e.Type != security.EventRoleChange || e.Target != "siem-auditor" || e.Severity != security.SeverityCritical {
+			t.Errorf("unexpected event: %+v", e)
+		}
+		if e.SchemaVersion != security.SchemaVersion || e.ID == "" {
+			t.Errorf("event is missing envelope fields: %+v", e)
+		}
+	})
+
+	t.Run("Impersonation", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/admin/users/siem-auditor/tokens", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+
+		e := next(t)
+		if e.Type != security.EventImpersonation || e.Actor != "admin" || e.Target != "siem-auditor" {
+			t.Errorf("unexpected event: %+v", e)
+		}
+	})
+
+	t.Run("Disabled event types are not sent", func(t *testing.T) {
+		for range 5 {
+			body, _ := json.Marshal(handlers.CredentialsRequest{Username: "siem-auditor", Password: "wrong"})
+			req := httptest.NewRequest(http.MethodPost, "/login", bytes.NewReader(body))
+			r.ServeHTTP(httptest.NewRecorder(), req)
+		}
+		select {
+		case e := <-events:
+			t.Errorf("unexpected event: %+v", e)
+		case <-time.After(500 * time.Millisecond):
+		}
+	})
+}
+
+func TestSecurityEventSyslog(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer ln.Close()
+
+	messages := make(chan string, 10)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		for {
+			// Octet-counted framing: "<length> <message>".
+			size, err := reader.ReadString(' ')
+			if err != nil {
+				return
+			}
+			n, _ := strconv.Atoi(strings.TrimSpace(size))
+			msg := make([]byte, n)
+			if _, err := io.ReadFull(reader, msg); err != nil {
+				return
+			}
+			messages <- string(msg)
+		}
+	}()
+
+	sink, err := security.NewSyslogSink(security.SyslogConfig{
+		Network:     "tcp",
+		Addr:        ln.Addr().String(),
+		Format:      security.FormatCEF,
+		Facility:    "local4",
+		SeverityMap: "critical=alert",
+	})
+	if err != nil {
+		t.Fatalf("failed to create sink: %v", err)
+	}
+	security.Configure([]security.Sink{sink}, map[string]bool{security.EventRoleChange: true})
+	t.Cleanup(func() {
+		security.Configure(nil, nil)
+		clearAllUsersExceptAdmin()
+	})
+	r := router.NewRouter()
+
+	body, _ := json.Marshal(handlers.RegisterAsAdminRequest{Username: "syslog-admin", Password: "secret", Role: "admin"})
+	req := httptest.NewRequest(http.MethodPost, "/admin/users", bytes.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201 Created, got %d: %s", w.Code, w.Body.String())
+	}
+
+	select {
+	case msg := <-messages:
+		// local4 (20) * 8 + alert (1)
+		if !strings.HasPrefix(msg, "<161>1 ") {
+			t.Errorf("unexpected priority in %q", msg)
+		}
+		if !strings.Contains(msg, "CEF:0|Inventory Tracker|inventory-tracker|1.0|role_change|role change|9|") {
+			t.Errorf("unexpected CEF header in %q", msg)
+		}
+		if !strings.Contains(msg, "duser=syslog-admin") || !strings.Contains(msg, "suser=admin") {
+			t.Errorf("missing users in %q", msg)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no syslog message received")
+	}
+}
@@ -0,0 +1,85 @@
+package handlers_integrated_test_suite
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+	"github.com/rogerio-castellano/inventory-tracker/internal/seed"
+)
+
+func TestSeedHandler(t *testing.T) {
+	clearAllProducts()
+	clearAllUsersExceptAdmin()
+	r := router.NewRouter()
+
+	postSeed := func(opts any) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(opts)
+		req := httptest.NewRequest(http.MethodPost, "/admin/seed", bytes.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Generate dataset", func(t *testing.T) {
+		w := postSeed(seed.Options{Products: 5, Users: 2, Months: 3, MovementsPerProduct: 4, RandomSeed: 42})
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected 201 Created, got %d: %s", w.Code, w.Body.String())
+		}
+		var res seed.Result
+		if err := json.NewDecoder(w.Body).Decode(&res); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if res.Products != 5 || res.Users != 2 || res.Movements != 20 || res.Password == "" {
+			t.Errorf("unexpected result: %+v", res)
+		}
+		if res.Password == "demo-password" {
+			t.Error("expected a random password")
+		}
+
+		products, err := productRepo.GetAll()
+		if err != nil {
+			t.Fatalf("failed to list products: %v", err)
+		}
+		if len(products) != 5 {
+			t.Errorf("expected 5 products, got %d", len(products))
+		}
+	})
+
+	t.Run("Seeded data reconciles", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/admin/reconciliation", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		var report handlers.ReconciliationReport
+		if err := json.NewDecoder(w.Body).Decode(&report); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(report.Discrepancies) != 0 {
+			t.Errorf("expected no discrepancies, got %v", report.Discrepancies)
+		}
+	})
+
+	t.Run("Volume above limits", func(t *testing.T) {
+		w := postSeed(seed.Options{Products: seed.Limits.Products + 1})
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected 400 Bad Request, got %d", w.Code)
+		}
+	})
+
+	t.Run("Disabled unless APP_ENV is development", func(t *testing.T) {
+		handlers.SetSeedingEnabled(false)
+		defer handlers.SetSeedingEnabled(true)
+
+		w := postSeed(seed.Options{})
+		if w.Code != http.StatusNotFound {
+			t.Errorf("expected 404 Not Found, got %d", w.Code)
+		}
+	})
+}
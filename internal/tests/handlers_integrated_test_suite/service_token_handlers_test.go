@@ -0,0 +1,86 @@
+package handlers_integrated_test_suite
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+)
+
+func TestServiceTokenHandlers(t *testing.T) {
+	t.Cleanup(clearAllProducts)
+	r := router.NewRouter()
+	createProduct(r, handlers.ProductRequest{Name: "Kiosk item", Price: 2, Quantity: 5, Barcode: "4006381333931"})
+
+	send := func(method, path, bearer string, body any) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		if body != nil {
+			_ = json.NewEncoder(&buf).Encode(body)
+		}
+		req := httptest.NewRequest(method, path, &buf)
+		req.Header.Set("Authorization", "Bearer "+bearer)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := send(http.MethodPost, "/admin/service-tokens", token, handlers.ServiceTokenRequest{Name: "front-desk", Scopes: []string{"POST /scan"}})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201 Created, got %d: %s", w.Code, w.Body.String())
+	}
+	var issued handlers.ServiceTokenResponse
+	if err := json.NewDecoder(w.Body).Decode(&issued); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if issued.Token == "" || issued.ExpiresAt != nil {
+		t.Fatalf("unexpected token: %+v", issued)
+	}
+
+	t.Run("Scoped endpoint is allowed", func(t *testing.T) {
+		w := send(http.MethodPost, "/scan", issued.Token, handlers.ScanRequest{Barcode: "4006381333931", Delta: -1})
+		if w.Code != http.StatusOK {
+			t.Errorf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("Other endpoints are forbidden", func(t *testing.T) {
+		w := send(http.MethodPost, "/products", issued.Token, handlers.ProductRequest{Name: "Not allowed", Price: 1})
+		if w.Code != http.StatusForbidden {
+			t.Errorf("expected 403 Forbidden, got %d", w.Code)
+		}
+	})
+
+	t.Run("Listed until revoked", func(t *testing.T) {
+		w := send(http.MethodGet, "/admin/service-tokens", token, nil)
+		var tokens []handlers.ServiceTokenInfo
+		if err := json.NewDecoder(w.Body).Decode(&tokens); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		found := false
+		for _, info := range tokens {
+			found = found || info.ID == issued.ID
+		}
+		if !found {
+			t.Errorf("issued token missing from %+v", tokens)
+		}
+
+		if w := send(http.MethodDelete, "/admin/service-tokens/"+issued.ID, token, nil); w.Code != http.StatusNoContent {
+			t.Fatalf("expected 204 No Content, got %d", w.Code)
+		}
+		w = send(http.MethodPost, "/scan", issued.Token, handlers.ScanRequest{Barcode: "4006381333931", Delta: -1})
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("expected 401 Unauthorized after revocation, got %d", w.Code)
+		}
+	})
+
+	t.Run("Invalid scope", func(t *testing.T) {
+		w := send(http.MethodPost, "/admin/service-tokens", token, handlers.ServiceTokenRequest{Name: "bad", Scopes: []string{"scan"}})
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected 400 Bad Request, got %d", w.Code)
+		}
+	})
+}
@@ -0,0 +1,150 @@
+package handlers_integrated_test_suite
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+)
+
+func TestListActiveSessionsHandler(t *testing.T) {
+	r := router.NewRouter()
+
+	get := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("User-Agent", "session-test")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := get("/me"); w.Code != http.StatusOK {
+		t.Fatalf("expected 200 OK from /me, got %d", w.Code)
+	}
+
+	t.Run("Shows the last request of active users", func(t *testing.T) {
+		w := get("/admin/sessions/active?within=5m")
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		var active []handlers.ActiveSession
+		if err := json.NewDecoder(w.Body).Decode(&active); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		for _, s := range active {
+			if s.Username != "admin" {
+				continue
+			}
+			if s.LastRequest != "GET /me" || s.UserAgent != "session-test" || s.RequestsToday == 0 {
+				t.Errorf("unexpected activity: %+v", s)
+			}
+			return
+		}
+		t.Errorf("admin not listed as active: %+v", active)
+	})
+
+	t.Run("Invalid window", func(t *testing.T) {
+		if w := get("/admin/sessions/active?within=soon"); w.Code != http.StatusBadRequest {
+			t.Errorf("expected 400 Bad Request, got %d", w.Code)
+		}
+	})
+}
+
+func TestSessionDeviceNames(t *testing.T) {
+	t.Cleanup(clearAllUsersExceptAdmin)
+	r := router.NewRouter()
+
+	send := func(method, path, bearer string, body any) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		if body != nil {
+			_ = json.NewEncoder(&buf).Encode(body)
+		}
+		req := httptest.NewRequest(method, path, &buf)
+		req.Header.Set("User-Agent", "tablet-3")
+		if bearer != "" {
+			req.Header.Set("Authorization", "Bearer "+bearer)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	sessions := func(bearer string) []handlers.RefreshTokenInfo {
+		w := send(http.MethodGet, "/me/sessions", bearer, nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		var list []handlers.RefreshTokenInfo
+		if err := json.NewDecoder(w.Body).Decode(&list); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return list
+	}
+
+	if _, err := roleToken(r, "tablet-user", "user"); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	w := send(http.MethodPost, "/login", "", handlers.CredentialsRequest{Username: "tablet-user", Password: "secret-password", DeviceName: " Warehouse Tablet 3 "})
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+	}
+	var login handlers.LoginResult
+	if err := json.NewDecoder(w.Body).Decode(&login); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	var other string
+	t.Run("Sessions show their device", func(t *testing.T) {
+		list := sessions(login.AccessToken)
+		if len(list) != 2 {
+			t.Fatalf("expected 2 sessions, got %+v", list)
+		}
+		for _, s := range list {
+			if s.Current {
+				if s.DeviceName != "Warehouse Tablet 3" {
+					t.Errorf("expected the device name on the current session, got %+v", s)
+				}
+			} else {
+				other = s.SessionKey
+			}
+		}
+		if other == "" {
+			t.Errorf("expected one session to be current, got %+v", list)
+		}
+	})
+
+	t.Run("Refreshing keeps the device name", func(t *testing.T) {
+		w := send(http.MethodPost, "/refresh", "", handlers.RefreshRequest{Username: "tablet-user", RefreshToken: login.RefreshToken})
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		w = send(http.MethodGet, "/admin/users/tablet-user/tokens", token, nil)
+		if !strings.Contains(w.Body.String(), `"device_name":"Warehouse Tablet 3"`) {
+			t.Errorf("expected the admin listing to show the device, got %s", w.Body.String())
+		}
+	})
+
+	t.Run("Revoke another device", func(t *testing.T) {
+		if w := send(http.MethodDelete, "/me/sessions/"+other, login.AccessToken, nil); w.Code != http.StatusNoContent {
+			t.Fatalf("expected 204 No Content, got %d", w.Code)
+		}
+		if list := sessions(login.AccessToken); len(list) != 1 || !list[0].Current {
+			t.Errorf("expected only the current session left, got %+v", list)
+		}
+		if w := send(http.MethodDelete, "/me/sessions/"+other, login.AccessToken, nil); w.Code != http.StatusNotFound {
+			t.Errorf("expected 404 Not Found, got %d", w.Code)
+		}
+	})
+
+	t.Run("Device name too long", func(t *testing.T) {
+		w := send(http.MethodPost, "/login", "", handlers.CredentialsRequest{Username: "tablet-user", Password: "secret-password", DeviceName: strings.Repeat("x", 65)})
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected 400 Bad Request, got %d", w.Code)
+		}
+	})
+}
@@ -0,0 +1,79 @@
+package handlers_integrated_test_suite
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+func TestShippingEstimateHandler(t *testing.T) {
+	t.Cleanup(clearAllProducts)
+	r := router.NewRouter()
+
+	estimate := func(body any) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		_ = json.NewEncoder(&buf).Encode(body)
+		req := httptest.NewRequest(http.MethodPost, "/shipping/estimate", &buf)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	newProduct := func(p handlers.ProductRequest) int {
+		w := createProduct(r, p)
+		var resp handlers.ProductResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode product: %v", err)
+		}
+		return resp.Id
+	}
+	// 1.5 kg and 40 × 30 × 20 cm = 0.024 m³ a unit
+	box := newProduct(handlers.ProductRequest{Name: "Box", Price: 10, Quantity: 5, Weight: 1.5, Dimensions: models.Dimensions{Length: 40, Width: 30, Height: 20}})
+	manual := newProduct(handlers.ProductRequest{Name: "Manual", Price: 2, Quantity: 5, Weight: 0.2})
+
+	t.Run("Sums weight and volume", func(t *testing.T) {
+		w := estimate(handlers.ShippingEstimateRequest{Items: []handlers.ShippingItem{{ProductID: box, Quantity: 2}, {ProductID: manual, Quantity: 5}}})
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		var e handlers.ShippingEstimate
+		if err := json.NewDecoder(w.Body).Decode(&e); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(e.Items) != 2 || e.Weight != 4 || e.Volume != 0.048 {
+			t.Errorf("unexpected estimate: %+v", e)
+		}
+		// 48000 cm³ / 5000
+		if e.VolumetricWeight != 9.6 || e.ChargeableWeight != 9.6 {
+			t.Errorf("expected a volumetric and chargeable weight of 9.6 kg, got %+v", e)
+		}
+		if !slices.Equal(e.Unmeasured, []int{manual}) || len(e.Unweighed) != 0 {
+			t.Errorf("expected only the manual to be unmeasured, got %+v", e)
+		}
+	})
+
+	cases := []struct {
+		name string
+		body handlers.ShippingEstimateRequest
+		code int
+	}{
+		{"No items", handlers.ShippingEstimateRequest{}, http.StatusBadRequest},
+		{"Zero quantity", handlers.ShippingEstimateRequest{Items: []handlers.ShippingItem{{ProductID: box}}}, http.StatusBadRequest},
+		{"Negative divisor", handlers.ShippingEstimateRequest{Items: []handlers.ShippingItem{{ProductID: box, Quantity: 1}}, VolumetricDivisor: -1}, http.StatusBadRequest},
+		{"Unknown product", handlers.ShippingEstimateRequest{Items: []handlers.ShippingItem{{ProductID: 999999, Quantity: 1}}}, http.StatusNotFound},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if w := estimate(c.body); w.Code != c.code {
+				t.Errorf("expected %d, got %d: %s", c.code, w.Code, w.Body.String())
+			}
+		})
+	}
+}
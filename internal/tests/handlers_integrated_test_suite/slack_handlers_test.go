@@ -0,0 +1,93 @@
+package handlers_integrated_test_suite
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+)
+
+func TestSlackCommandHandler(t *testing.T) {
+	const secret = "slack-signing-secret"
+	handlers.SetSlackCommands(secret, "https://inventory.example.com/")
+	t.Cleanup(func() {
+		handlers.SetSlackCommands("", "")
+		clearAllProducts()
+	})
+	r := router.NewRouter()
+
+	createProduct(r, handlers.ProductRequest{Name: "Mouse", Price: 20, Quantity: 2, Threshold: 5})
+	createProduct(r, handlers.ProductRequest{Name: "Mouse Pad", Price: 8, Quantity: 40, Threshold: 5})
+
+	send := func(text string, ts time.Time, sign bool) *httptest.ResponseRecorder {
+		body := url.Values{"command": {"/stock"}, "text": {text}}.Encode()
+		req := httptest.NewRequest(http.MethodPost, "/integrations/slack/commands", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		stamp := strconv.FormatInt(ts.Unix(), 10)
+		req.Header.Set("X-Slack-Request-Timestamp", stamp)
+		if sign {
+			mac := hmac.New(sha256.New, []byte(secret))
+			mac.Write([]byte("v0:" + stamp + ":" + body))
+			req.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	reply := func(t *testing.T, w *httptest.ResponseRecorder) string {
+		t.Helper()
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		var resp handlers.SlackCommandResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return resp.Text
+	}
+
+	t.Run("Exact name", func(t *testing.T) {
+		text := reply(t, send("mouse", time.Now(), true))
+		if !strings.Contains(text, "2 in stock") || !strings.Contains(text, "low stock") || strings.Contains(text, "Pad") {
+			t.Errorf("unexpected reply: %q", text)
+		}
+		if !strings.Contains(text, "<https://inventory.example.com/products/") {
+			t.Errorf("expected a dashboard link in %q", text)
+		}
+	})
+
+	t.Run("Partial name", func(t *testing.T) {
+		text := reply(t, send("pad", time.Now(), true))
+		if !strings.Contains(text, "40 in stock") || strings.Contains(text, "low stock") {
+			t.Errorf("unexpected reply: %q", text)
+		}
+	})
+
+	t.Run("No match", func(t *testing.T) {
+		if text := reply(t, send("keyboard", time.Now(), true)); !strings.Contains(text, "No product matches") {
+			t.Errorf("unexpected reply: %q", text)
+		}
+	})
+
+	t.Run("Unsigned request", func(t *testing.T) {
+		if w := send("mouse", time.Now(), false); w.Code != http.StatusUnauthorized {
+			t.Errorf("expected 401 Unauthorized, got %d", w.Code)
+		}
+	})
+
+	t.Run("Stale timestamp", func(t *testing.T) {
+		if w := send("mouse", time.Now().Add(-10*time.Minute), true); w.Code != http.StatusUnauthorized {
+			t.Errorf("expected 401 Unauthorized, got %d", w.Code)
+		}
+	})
+}
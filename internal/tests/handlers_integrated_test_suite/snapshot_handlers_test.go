@@ -0,0 +1,207 @@
+package handlers_integrated_test_suite
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+	"github.com/rogerio-castellano/inventory-tracker/internal/snapshot"
+)
+
+func TestSnapshotHandlers(t *testing.T) {
+	t.Cleanup(func() {
+		clearSnapshots()
+		clearAllProducts()
+	})
+	r := router.NewRouter()
+
+	send := func(method, path string, body any) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		if body != nil {
+			_ = json.NewEncoder(&buf).Encode(body)
+		}
+		req := httptest.NewRequest(method, path, &buf)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	productID := func(name string, price float64, quantity int) int {
+		w := createProduct(r, handlers.ProductRequest{Name: name, Price: price, Quantity: quantity})
+		var p handlers.ProductResponse
+		if err := json.NewDecoder(w.Body).Decode(&p); err != nil {
+			t.Fatalf("failed to decode product: %v", err)
+		}
+		return p.Id
+	}
+	capture := func(t *testing.T, label string) models.Snapshot {
+		t.Helper()
+		w := send(http.MethodPost, "/snapshots", handlers.SnapshotRequest{Label: label})
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected 201 Created, got %d: %s", w.Code, w.Body.String())
+		}
+		var s models.Snapshot
+		if err := json.NewDecoder(w.Body).Decode(&s); err != nil {
+			t.Fatalf("failed to decode snapshot: %v", err)
+		}
+		return s
+	}
+
+	shirt := productID("Shirt", 10, 5)
+	hat := productID("Hat", 4, 10)
+
+	before := capture(t, "before import")
+	if before.ProductCount != 2 || before.TotalQuantity != 15 || before.TotalValue != 90 {
+		t.Errorf("unexpected totals: %+v", before)
+	}
+
+	t.Run("Get snapshot", func(t *testing.T) {
+		w := send(http.MethodGet, fmt.Sprintf("/snapshots/%d", before.ID), nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		var s models.Snapshot
+		if err := json.NewDecoder(w.Body).Decode(&s); err != nil {
+			t.Fatalf("failed to decode snapshot: %v", err)
+		}
+		if s.Label != "before import" || len(s.Items) != 2 || s.Items[0].ProductID != shirt {
+			t.Errorf("unexpected snapshot: %+v", s)
+		}
+	})
+
+	adjustProduct(r, shirt, handlers.QuantityAdjustmentRequest{Delta: -2})
+	send(http.MethodDelete, fmt.Sprintf("/products/%d", hat), nil)
+	scarf := productID("Scarf", 7.5, 2)
+	after := capture(t, "after import")
+
+	t.Run("Diff snapshots", func(t *testing.T) {
+		w := send(http.MethodGet, fmt.Sprintf("/snapshots/%d/diff/%d", before.ID, after.ID), nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		var diff handlers.SnapshotDiff
+		if err := json.NewDecoder(w.Body).Decode(&diff); err != nil {
+			t.Fatalf("failed to decode diff: %v", err)
+		}
+		if diff.Added != 1 || diff.Removed != 1 || diff.Changed != 1 {
+			t.Errorf("unexpected counts: %+v", diff)
+		}
+		if diff.QuantityDelta != -10 || diff.TotalValueDelta != -45 {
+			t.Errorf("unexpected totals: quantity %d, value %v", diff.QuantityDelta, diff.TotalValueDelta)
+		}
+		statuses := map[int]string{}
+		for _, c := range diff.Changes {
+			statuses[c.ProductID] = c.Status
+		}
+		if statuses[shirt] != "changed" || statuses[hat] != "removed" || statuses[scarf] != "added" {
+			t.Errorf("unexpected changes: %+v", diff.Changes)
+		}
+	})
+
+	t.Run("List snapshots", func(t *testing.T) {
+		w := send(http.MethodGet, "/snapshots", nil)
+		var list []models.Snapshot
+		if err := json.NewDecoder(w.Body).Decode(&list); err != nil {
+			t.Fatalf("failed to decode list: %v", err)
+		}
+		if len(list) != 2 || list[0].ID != after.ID || len(list[0].Items) != 0 {
+			t.Errorf("unexpected list: %+v", list)
+		}
+	})
+
+	cases := []struct {
+		name string
+		path string
+		code int
+	}{
+		{"Unknown snapshot", "/snapshots/999999", http.StatusNotFound},
+		{"Invalid snapshot ID", "/snapshots/abc", http.StatusBadRequest},
+		{"Diff with unknown snapshot", fmt.Sprintf("/snapshots/%d/diff/999999", before.ID), http.StatusNotFound},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if w := send(http.MethodGet, c.path, nil); w.Code != c.code {
+				t.Errorf("expected %d, got %d", c.code, w.Code)
+			}
+		})
+	}
+}
+
+func TestStockHistory(t *testing.T) {
+	t.Cleanup(func() {
+		clearSnapshots()
+		clearAllProducts()
+	})
+	r := router.NewRouter()
+	snapshots := repo.NewPostgresSnapshotRepository(database)
+
+	w := createProduct(r, handlers.ProductRequest{Name: "Lamp", Price: 20, Quantity: 3})
+	var lamp handlers.ProductResponse
+	if err := json.NewDecoder(w.Body).Decode(&lamp); err != nil {
+		t.Fatalf("failed to decode product: %v", err)
+	}
+
+	day := time.Now().AddDate(0, 0, -1)
+	if err := snapshot.RunDaily(snapshots, snapshot.Retention{Days: 90, Months: 24}, day); err != nil {
+		t.Fatalf("daily snapshot failed: %v", err)
+	}
+	adjustProduct(r, lamp.Id, handlers.QuantityAdjustmentRequest{Delta: 2})
+	if err := snapshot.RunDaily(snapshots, snapshot.Retention{Days: 90, Months: 24}, day.AddDate(0, 0, 1)); err != nil {
+		t.Fatalf("daily snapshot failed: %v", err)
+	}
+	// Manual snapshots are not part of the history.
+	if _, err := snapshots.Capture("manual", "admin", false); err != nil {
+		t.Fatalf("manual snapshot failed: %v", err)
+	}
+
+	get := func(t *testing.T, path string) handlers.StockHistory {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		var h handlers.StockHistory
+		if err := json.NewDecoder(w.Body).Decode(&h); err != nil {
+			t.Fatalf("failed to decode history: %v", err)
+		}
+		return h
+	}
+
+	t.Run("Whole inventory", func(t *testing.T) {
+		h := get(t, "/reports/stock-history?within=7d")
+		if len(h.Points) != 2 || h.Points[0].Quantity != 3 || h.Points[1].Quantity != 5 {
+			t.Errorf("unexpected points: %+v", h.Points)
+		}
+	})
+
+	t.Run("Single product", func(t *testing.T) {
+		h := get(t, fmt.Sprintf("/reports/stock-history?product_id=%d", lamp.Id))
+		if len(h.Points) != 2 || h.Points[1].TotalValue != 100 {
+			t.Errorf("unexpected points: %+v", h.Points)
+		}
+	})
+
+	t.Run("Retention keeps the first snapshot of each month", func(t *testing.T) {
+		pruned, err := snapshots.Prune(time.Now().Add(time.Hour), time.Now().AddDate(-1, 0, 0))
+		if err != nil {
+			t.Fatalf("prune failed: %v", err)
+		}
+		if pruned != 1 {
+			t.Errorf("expected 1 snapshot pruned, got %d", pruned)
+		}
+		if h := get(t, "/reports/stock-history"); len(h.Points) != 1 || h.Points[0].Quantity != 3 {
+			t.Errorf("unexpected points after pruning: %+v", h.Points)
+		}
+	})
+}
@@ -0,0 +1,115 @@
+package handlers_integrated_test_suite
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+func TestSubstituteHandlers(t *testing.T) {
+	t.Cleanup(clearAllProducts)
+	r := router.NewRouter()
+
+	newProduct := func(name string, quantity int) int {
+		w := createProduct(r, handlers.ProductRequest{Name: name, Price: 3, Quantity: quantity})
+		var p handlers.ProductResponse
+		if err := json.NewDecoder(w.Body).Decode(&p); err != nil {
+			t.Fatalf("failed to decode product: %v", err)
+		}
+		return p.Id
+	}
+	kit := newProduct("Battery kit", 0)
+	aa := newProduct("AA battery", 1)
+	generic := newProduct("Generic AA battery", 2)
+	rechargeable := newProduct("Rechargeable AA battery", 50)
+
+	send := func(method, path string, body any) *httptest.ResponseRecorder {
+		payload, _ := json.Marshal(body)
+		req := httptest.NewRequest(method, path, bytes.NewReader(payload))
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	substitutesPath := fmt.Sprintf("/products/%d/substitutes", aa)
+
+	t.Run("Set and list substitutes in order", func(t *testing.T) {
+		w := send(http.MethodPut, substitutesPath, handlers.SubstitutesRequest{ProductIDs: []int{generic, rechargeable}})
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+
+		w = send(http.MethodGet, substitutesPath, nil)
+		var substitutes []handlers.ProductResponse
+		if err := json.NewDecoder(w.Body).Decode(&substitutes); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(substitutes) != 2 || substitutes[0].Id != generic || substitutes[1].Id != rechargeable {
+			t.Errorf("unexpected substitutes: %+v", substitutes)
+		}
+	})
+
+	t.Run("Work order shortage suggests a substitute with enough stock", func(t *testing.T) {
+		w := send(http.MethodPost, "/work-orders", handlers.WorkOrderRequest{
+			ProductID:  kit,
+			Quantity:   4,
+			Components: []models.WorkOrderComponent{{ProductID: aa, QuantityPerUnit: 1}},
+		})
+		var wo models.WorkOrder
+		if err := json.NewDecoder(w.Body).Decode(&wo); err != nil {
+			t.Fatalf("failed to decode work order: %v", err)
+		}
+
+		w = send(http.MethodPost, fmt.Sprintf("/work-orders/%d/complete", wo.ID), nil)
+		if w.Code != http.StatusConflict {
+			t.Fatalf("expected 409 Conflict, got %d: %s", w.Code, w.Body.String())
+		}
+		var resp handlers.ShortageResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		// The generic battery is preferred but only has 2 of the 4 needed.
+		if len(resp.Shortages) != 1 || resp.Shortages[0].Substitute == nil || resp.Shortages[0].Substitute.ProductID != rechargeable {
+			t.Errorf("unexpected shortages: %+v", resp.Shortages)
+		}
+	})
+
+	t.Run("Empty list removes the links", func(t *testing.T) {
+		if w := send(http.MethodPut, substitutesPath, handlers.SubstitutesRequest{ProductIDs: []int{}}); w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		var substitutes []handlers.ProductResponse
+		if err := json.NewDecoder(send(http.MethodGet, substitutesPath, nil).Body).Decode(&substitutes); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(substitutes) != 0 {
+			t.Errorf("expected no substitutes, got %+v", substitutes)
+		}
+	})
+
+	cases := []struct {
+		name string
+		path string
+		ids  []int
+		code int
+	}{
+		{"Self substitute", substitutesPath, []int{aa}, http.StatusBadRequest},
+		{"Duplicate substitute", substitutesPath, []int{generic, generic}, http.StatusBadRequest},
+		{"Unknown substitute", substitutesPath, []int{999999}, http.StatusNotFound},
+		{"Unknown product", "/products/999999/substitutes", []int{generic}, http.StatusNotFound},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if w := send(http.MethodPut, c.path, handlers.SubstitutesRequest{ProductIDs: c.ids}); w.Code != c.code {
+				t.Errorf("expected %d, got %d: %s", c.code, w.Code, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package handlers_integrated_test_suite
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+)
+
+func TestSuggestProductsHandler(t *testing.T) {
+	handlers.Rdb.FlushDB(handlers.Ctx)
+	t.Cleanup(clearAllProducts)
+	r := router.NewRouter()
+
+	for _, p := range []handlers.ProductRequest{
+		{Name: "Wireless Mouse", Price: 25, SKU: "WM-01"},
+		{Name: "Mouse Pad", Price: 8, SKU: "MP-02"},
+		{Name: "Mouse Trap", Price: 3, SKU: "MT-03", Status: models.ProductStatusDiscontinued},
+		{Name: "Keyboard", Price: 40, SKU: "KB-MOU"},
+		{Name: "100% Cotton Cloth", Price: 2},
+	} {
+		if w := createProduct(r, p); w.Code != http.StatusCreated {
+			t.Fatalf("failed to create %s: %d %s", p.Name, w.Code, w.Body.String())
+		}
+	}
+
+	suggest := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/products/suggest"+query, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	names := func(t *testing.T, w *httptest.ResponseRecorder) []string {
+		t.Helper()
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		var suggestions []repo.ProductSuggestion
+		if err := json.NewDecoder(w.Body).Decode(&suggestions); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		var out []string
+		for _, s := range suggestions {
+			out = append(out, s.Name)
+		}
+		return out
+	}
+
+	t.Run("Prefix matches first, discontinued left out", func(t *testing.T) {
+		got := names(t, suggest("?q=mou"))
+		if len(got) != 3 || got[0] != "Mouse Pad" {
+			t.Errorf("unexpected suggestions: %v", got)
+		}
+	})
+
+	t.Run("Matches SKU", func(t *testing.T) {
+		if got := names(t, suggest("?q=wm-0")); len(got) != 1 || got[0] != "Wireless Mouse" {
+			t.Errorf("unexpected suggestions: %v", got)
+		}
+	})
+
+	t.Run("Wildcards match literally", func(t *testing.T) {
+		if got := names(t, suggest("?q=0%25")); len(got) != 1 || got[0] != "100% Cotton Cloth" {
+			t.Errorf("unexpected suggestions: %v", got)
+		}
+	})
+
+	t.Run("Limit", func(t *testing.T) {
+		if got := names(t, suggest("?q=mou&limit=1")); len(got) != 1 {
+			t.Errorf("expected 1 suggestion, got %v", got)
+		}
+	})
+
+	t.Run("Answers are cached briefly", func(t *testing.T) {
+		if w := createProduct(r, handlers.ProductRequest{Name: "Mouse Cable", Price: 5}); w.Code != http.StatusCreated {
+			t.Fatalf("failed to create product: %d", w.Code)
+		}
+		w := suggest("?q=mou")
+		if got := names(t, w); len(got) != 3 {
+			t.Errorf("expected the cached 3 suggestions, got %v", got)
+		}
+		if w.Header().Get("Cache-Control") == "" {
+			t.Error("missing Cache-Control header")
+		}
+	})
+
+	cases := []struct {
+		name  string
+		query string
+	}{
+		{"Missing q", ""},
+		{"Blank q", "?q=%20"},
+		{"Invalid limit", "?q=mou&limit=0"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if w := suggest(c.query); w.Code != http.StatusBadRequest {
+				t.Errorf("expected 400 Bad Request, got %d", w.Code)
+			}
+		})
+	}
+}
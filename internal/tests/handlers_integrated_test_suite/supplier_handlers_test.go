@@ -0,0 +1,152 @@
+package handlers_integrated_test_suite
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+func TestSupplierHandlers(t *testing.T) {
+	t.Cleanup(func() {
+		clearAllProducts()
+		clearSuppliers()
+	})
+	r := router.NewRouter()
+
+	send := func(method, path string, body any) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		if body != nil {
+			_ = json.NewEncoder(&buf).Encode(body)
+		}
+		req := httptest.NewRequest(method, path, &buf)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	newSupplier := func(name string) int {
+		w := send(http.MethodPost, "/suppliers", handlers.SupplierRequest{Name: name, Email: "orders@example.com"})
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected 201 Created, got %d: %s", w.Code, w.Body.String())
+		}
+		var s models.Supplier
+		if err := json.NewDecoder(w.Body).Decode(&s); err != nil {
+			t.Fatalf("failed to decode supplier: %v", err)
+		}
+		return s.ID
+	}
+	acme := newSupplier("Acme")
+	globex := newSupplier("Globex")
+
+	w := createProduct(r, handlers.ProductRequest{Name: "Gasket", Price: 10, Quantity: 5})
+	var product handlers.ProductResponse
+	if err := json.NewDecoder(w.Body).Decode(&product); err != nil {
+		t.Fatalf("failed to decode product: %v", err)
+	}
+	productPath := fmt.Sprintf("/products/%d", product.Id)
+
+	t.Run("Link suppliers to a product", func(t *testing.T) {
+		for _, link := range []struct {
+			supplier int
+			terms    handlers.ProductSupplierRequest
+		}{
+			{acme, handlers.ProductSupplierRequest{SupplierSKU: "AC-1", CostPrice: 4.5, LeadTimeDays: 7}},
+			{globex, handlers.ProductSupplierRequest{CostPrice: 5, LeadTimeDays: 2}},
+			{acme, handlers.ProductSupplierRequest{SupplierSKU: "AC-1", CostPrice: 4.25, LeadTimeDays: 5}},
+		} {
+			if w := send(http.MethodPut, fmt.Sprintf("%s/suppliers/%d", productPath, link.supplier), link.terms); w.Code != http.StatusOK {
+				t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+			}
+		}
+
+		w := send(http.MethodGet, productPath+"/suppliers", nil)
+		var linked []models.ProductSupplier
+		if err := json.NewDecoder(w.Body).Decode(&linked); err != nil {
+			t.Fatalf("failed to decode suppliers: %v", err)
+		}
+		if len(linked) != 2 {
+			t.Fatalf("expected 2 suppliers, got %+v", linked)
+		}
+		for _, ps := range linked {
+			if ps.SupplierID == acme && (ps.CostPrice != 4.25 || ps.LeadTimeDays != 5 || ps.Supplier != "Acme") {
+				t.Errorf("expected the second link to replace Acme's terms, got %+v", ps)
+			}
+		}
+	})
+
+	t.Run("Embed suppliers in products", func(t *testing.T) {
+		w := send(http.MethodGet, productPath+"?include=suppliers", nil)
+		var p handlers.ProductResponse
+		if err := json.NewDecoder(w.Body).Decode(&p); err != nil {
+			t.Fatalf("failed to decode product: %v", err)
+		}
+		if len(p.Suppliers) != 2 {
+			t.Errorf("expected 2 embedded suppliers, got %+v", p.Suppliers)
+		}
+
+		w = send(http.MethodGet, productPath, nil)
+		p = handlers.ProductResponse{}
+		if err := json.NewDecoder(w.Body).Decode(&p); err != nil {
+			t.Fatalf("failed to decode product: %v", err)
+		}
+		if p.Suppliers != nil {
+			t.Errorf("expected no suppliers without include, got %+v", p.Suppliers)
+		}
+
+		w = send(http.MethodGet, "/products/search?include=suppliers&name=Gasket", nil)
+		var result handlers.ProductsSearchResult
+		if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+			t.Fatalf("failed to decode search result: %v", err)
+		}
+		if len(result.Data) != 1 || len(result.Data[0].Suppliers) != 2 {
+			t.Errorf("expected the match to embed 2 suppliers, got %+v", result.Data)
+		}
+	})
+
+	t.Run("Deleting a supplier unlinks it", func(t *testing.T) {
+		if w := send(http.MethodDelete, fmt.Sprintf("/suppliers/%d", globex), nil); w.Code != http.StatusNoContent {
+			t.Fatalf("expected 204 No Content, got %d: %s", w.Code, w.Body.String())
+		}
+		w := send(http.MethodGet, productPath+"/suppliers", nil)
+		var linked []models.ProductSupplier
+		if err := json.NewDecoder(w.Body).Decode(&linked); err != nil {
+			t.Fatalf("failed to decode suppliers: %v", err)
+		}
+		if len(linked) != 1 || linked[0].SupplierID != acme {
+			t.Errorf("expected only Acme to remain, got %+v", linked)
+		}
+	})
+
+	cases := []struct {
+		name   string
+		method string
+		path   string
+		body   any
+		code   int
+	}{
+		{"Duplicate name", http.MethodPost, "/suppliers", handlers.SupplierRequest{Name: "acme"}, http.StatusConflict},
+		{"Missing name", http.MethodPost, "/suppliers", handlers.SupplierRequest{Email: "a@example.com"}, http.StatusBadRequest},
+		{"Invalid email", http.MethodPost, "/suppliers", handlers.SupplierRequest{Name: "Initech", Email: "initech"}, http.StatusBadRequest},
+		{"Unknown supplier", http.MethodGet, "/suppliers/999999", nil, http.StatusNotFound},
+		{"Negative cost price", http.MethodPut, fmt.Sprintf("%s/suppliers/%d", productPath, acme), handlers.ProductSupplierRequest{CostPrice: -1}, http.StatusBadRequest},
+		{"Link unknown supplier", http.MethodPut, productPath + "/suppliers/999999", handlers.ProductSupplierRequest{CostPrice: 1}, http.StatusNotFound},
+		{"Link unknown product", http.MethodPut, fmt.Sprintf("/products/999999/suppliers/%d", acme), handlers.ProductSupplierRequest{CostPrice: 1}, http.StatusNotFound},
+		{"Unlink supplier not linked", http.MethodDelete, fmt.Sprintf("%s/suppliers/%d", productPath, globex), nil, http.StatusNotFound},
+		{"Unknown include", http.MethodGet, productPath + "?include=lots", nil, http.StatusBadRequest},
+		{"Unlink", http.MethodDelete, fmt.Sprintf("%s/suppliers/%d", productPath, acme), nil, http.StatusNoContent},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if w := send(c.method, c.path, c.body); w.Code != c.code {
+				t.Errorf("expected %d, got %d: %s", c.code, w.Code, w.Body.String())
+			}
+		})
+	}
+}
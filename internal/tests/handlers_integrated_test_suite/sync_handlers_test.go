@@ -0,0 +1,217 @@
+package handlers_integrated_test_suite
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+)
+
+func newUUID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+func TestSyncHandlers(t *testing.T) {
+	t.Cleanup(clearAllProducts)
+	r := router.NewRouter()
+
+	changes := func(cursor string) handlers.SyncChangesResponse {
+		req := httptest.NewRequest(http.MethodGet, "/sync/changes?since="+cursor, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		var resp handlers.SyncChangesResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return resp
+	}
+	batch := func(ops ...handlers.SyncOperationRequest) []handlers.SyncResult {
+		body, _ := json.Marshal(handlers.SyncBatchRequest{Operations: ops})
+		req := httptest.NewRequest(http.MethodPost, "/sync/batch", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		var resp handlers.SyncBatchResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return resp.Results
+	}
+
+	// Catch up with whatever earlier tests left behind.
+	resp := changes("")
+	for resp.HasMore {
+		resp = changes(resp.Cursor)
+	}
+	cursor := resp.Cursor
+
+	w := createProduct(r, handlers.ProductRequest{Name: "Pallet wrap", Price: 12.0, Quantity: 5})
+	var product handlers.ProductResponse
+	if err := json.NewDecoder(w.Body).Decode(&product); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	t.Run("Changes since cursor", func(t *testing.T) {
+		resp := changes(cursor)
+		if len(resp.Products) != 1 || resp.Products[0].Id != product.Id || len(resp.Movements) != 0 {
+			t.Fatalf("expected only the new product, got %+v", resp)
+		}
+		cursor = resp.Cursor
+
+		if resp := changes(cursor); len(resp.Products) != 0 || len(resp.Movements) != 0 {
+			t.Errorf("expected no changes after the new cursor, got %+v", resp)
+		}
+	})
+
+	applied := handlers.SyncOperationRequest{ClientID: newUUID(), ProductID: product.Id, Delta: -3}
+
+	t.Run("Batch applies and rejects", func(t *testing.T) {
+		results := batch(applied, handlers.SyncOperationRequest{ClientID: newUUID(), ProductID: product.Id, Delta: -10})
+		if results[0].Status != "applied" || *results[0].Quantity != 2 {
+			t.Errorf("expected the first operation applied leaving 2, got %+v", results[0])
+		}
+		if results[1].Status != "rejected" || results[1].Reason != "insufficient_stock" || *results[1].Quantity != 2 {
+			t.Errorf("expected the second operation rejected, got %+v", results[1])
+		}
+	})
+
+	t.Run("Resent operations apply once", func(t *testing.T) {
+		results := batch(applied)
+		if !results[0].Duplicate || results[0].Status != "applied" || *results[0].Quantity != 2 {
+			t.Errorf("expected a duplicate of the applied operation, got %+v", results[0])
+		}
+	})
+
+	t.Run("Changes include movements and deletions", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/products/%d", product.Id), nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		r.ServeHTTP(httptest.NewRecorder(), req)
+
+		resp := changes(cursor)
+		if len(resp.Movements) != 1 || resp.Movements[0].Delta != -3 {
+			t.Errorf("expected the synced movement, got %+v", resp.Movements)
+		}
+		if len(resp.DeletedProductIDs) != 1 || resp.DeletedProductIDs[0] != product.Id {
+			t.Errorf("expected the deleted product, got %+v", resp.DeletedProductIDs)
+		}
+	})
+
+	t.Run("Invalid client id", func(t *testing.T) {
+		body, _ := json.Marshal(handlers.SyncBatchRequest{Operations: []handlers.SyncOperationRequest{{ClientID: "42", ProductID: 1, Delta: 1}}})
+		req := httptest.NewRequest(http.MethodPost, "/sync/batch", bytes.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected 400 Bad Request, got %d", w.Code)
+		}
+	})
+}
+
+func TestChangedProductsHandler(t *testing.T) {
+	t.Cleanup(clearAllProducts)
+	r := router.NewRouter()
+
+	changed := func(since string) (handlers.ProductChangesResponse, int) {
+		req := httptest.NewRequest(http.MethodGet, "/products/changed?since="+url.QueryEscape(since), nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		var resp handlers.ProductChangesResponse
+		if w.Code == http.StatusOK {
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+		}
+		return resp, w.Code
+	}
+
+	since := time.Now().UTC().Add(-time.Second).Format(time.RFC3339)
+	w := createProduct(r, handlers.ProductRequest{Name: "Stretch film", Price: 9.0, Quantity: 4})
+	var kept handlers.ProductResponse
+	if err := json.NewDecoder(w.Body).Decode(&kept); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	w = createProduct(r, handlers.ProductRequest{Name: "Corner boards", Price: 3.0, Quantity: 8})
+	var removed handlers.ProductResponse
+	if err := json.NewDecoder(w.Body).Decode(&removed); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	actions := func(resp handlers.ProductChangesResponse) map[int]string {
+		seen := map[int]string{}
+		for _, c := range resp.Changes {
+			seen[c.ProductID] = c.Action
+		}
+		return seen
+	}
+
+	var cursor string
+	t.Run("Changes since a timestamp", func(t *testing.T) {
+		resp, code := changed(since)
+		if code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d", code)
+		}
+		seen := actions(resp)
+		if seen[kept.Id] != "created" || seen[removed.Id] != "created" {
+			t.Errorf("expected both products created, got %+v", resp.Changes)
+		}
+		for resp.HasMore {
+			resp, _ = changed(resp.Cursor)
+		}
+		cursor = resp.Cursor
+	})
+
+	t.Run("Updates and deletions after the cursor", func(t *testing.T) {
+		body, _ := json.Marshal(handlers.ProductRequest{Name: "Stretch film", Price: 11.0, Quantity: 4})
+		req := httptest.NewRequest(http.MethodPut, fmt.Sprintf("/products/%d", kept.Id), bytes.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		r.ServeHTTP(httptest.NewRecorder(), req)
+		req = httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/products/%d", removed.Id), nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		r.ServeHTTP(httptest.NewRecorder(), req)
+
+		resp, code := changed(cursor)
+		if code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d", code)
+		}
+		seen := actions(resp)
+		if seen[kept.Id] != "updated" || seen[removed.Id] != "deleted" {
+			t.Errorf("expected an update and a deletion, got %+v", resp.Changes)
+		}
+		for _, c := range resp.Changes {
+			if c.ProductID == kept.Id && (c.Product == nil || c.Product.Price != 11.0) {
+				t.Errorf("expected the product's current state, got %+v", c.Product)
+			}
+			if c.ProductID == removed.Id && c.Product != nil {
+				t.Errorf("expected a tombstone without a product, got %+v", c.Product)
+			}
+		}
+	})
+
+	t.Run("Invalid since", func(t *testing.T) {
+		if _, code := changed("yesterday"); code != http.StatusBadRequest {
+			t.Errorf("expected 400 Bad Request, got %d", code)
+		}
+	})
+}
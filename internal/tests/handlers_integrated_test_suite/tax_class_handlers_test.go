@@ -0,0 +1,119 @@
+package handlers_integrated_test_suite
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+func TestTaxClassHandlers(t *testing.T) {
+	t.Cleanup(func() {
+		clearAllProducts()
+		clearTaxClasses()
+	})
+	r := router.NewRouter()
+
+	send := func(method, path string, body any) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		if body != nil {
+			_ = json.NewEncoder(&buf).Encode(body)
+		}
+		req := httptest.NewRequest(method, path, &buf)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	var standard models.TaxClass
+	t.Run("Create tax class", func(t *testing.T) {
+		w := send(http.MethodPost, "/admin/tax-classes", handlers.TaxClassRequest{Name: "Standard", Rate: 21})
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected 201 Created, got %d: %s", w.Code, w.Body.String())
+		}
+		if err := json.NewDecoder(w.Body).Decode(&standard); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+	})
+
+	w := createProduct(r, handlers.ProductRequest{Name: "Kettle", Price: 40, Quantity: 1, TaxClassID: &standard.ID})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201 Created, got %d: %s", w.Code, w.Body.String())
+	}
+	var kettle handlers.ProductResponse
+	if err := json.NewDecoder(w.Body).Decode(&kettle); err != nil {
+		t.Fatalf("failed to decode product: %v", err)
+	}
+
+	getProduct := func(t *testing.T, query string) handlers.ProductResponse {
+		t.Helper()
+		w := send(http.MethodGet, fmt.Sprintf("/products/%d%s", kettle.Id, query), nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		var p handlers.ProductResponse
+		if err := json.NewDecoder(w.Body).Decode(&p); err != nil {
+			t.Fatalf("failed to decode product: %v", err)
+		}
+		return p
+	}
+
+	t.Run("Prices exclude tax by default", func(t *testing.T) {
+		p := getProduct(t, "")
+		if p.Price != 40 || p.PriceIncludesTax || p.TaxRate == nil || *p.TaxRate != 21 {
+			t.Errorf("unexpected product: %+v", p)
+		}
+	})
+
+	t.Run("Tax-inclusive prices", func(t *testing.T) {
+		if p := getProduct(t, "?tax=inclusive"); p.Price != 48.40 || !p.PriceIncludesTax {
+			t.Errorf("expected 48.40 including tax, got %+v", p)
+		}
+	})
+
+	t.Run("Rate change applies to the class", func(t *testing.T) {
+		if w := send(http.MethodPut, fmt.Sprintf("/admin/tax-classes/%d", standard.ID), handlers.TaxClassRequest{Name: "Standard", Rate: 10}); w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		if p := getProduct(t, "?tax=inclusive"); p.Price != 44 {
+			t.Errorf("expected 44 including tax, got %v", p.Price)
+		}
+	})
+
+	t.Run("Export", func(t *testing.T) {
+		w := send(http.MethodGet, "/products/export?tax=inclusive", nil)
+		if !strings.Contains(w.Body.String(), "Kettle,44.00,") || !strings.HasSuffix(strings.TrimSpace(w.Body.String()), ",Standard") {
+			t.Errorf("unexpected export: %s", w.Body.String())
+		}
+	})
+
+	cases := []struct {
+		name   string
+		method string
+		path   string
+		body   any
+		code   int
+	}{
+		{"Duplicate name", http.MethodPost, "/admin/tax-classes", handlers.TaxClassRequest{Name: "standard", Rate: 5}, http.StatusConflict},
+		{"Invalid rate", http.MethodPost, "/admin/tax-classes", handlers.TaxClassRequest{Name: "Luxury", Rate: -1}, http.StatusBadRequest},
+		{"Class in use", http.MethodDelete, fmt.Sprintf("/admin/tax-classes/%d", standard.ID), nil, http.StatusConflict},
+		{"Unknown class", http.MethodDelete, "/admin/tax-classes/999999", nil, http.StatusNotFound},
+		{"Invalid tax option", http.MethodGet, "/products?tax=gross", nil, http.StatusBadRequest},
+		{"Product with unknown class", http.MethodPut, fmt.Sprintf("/products/%d", kettle.Id), handlers.ProductRequest{Name: "Kettle", Price: 40, TaxClassID: new(int)}, http.StatusBadRequest},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if w := send(c.method, c.path, c.body); w.Code != c.code {
+				t.Errorf("expected %d, got %d: %s", c.code, w.Code, w.Body.String())
+			}
+		})
+	}
+}
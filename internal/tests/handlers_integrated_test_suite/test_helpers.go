@@ -17,9 +17,14 @@ import (
 	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
 	mw "github.com/rogerio-castellano/inventory-tracker/internal/http/middleware"
 	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+	"github.com/rogerio-castellano/inventory-tracker/internal/incident"
 	"github.com/rogerio-castellano/inventory-tracker/internal/models"
 	"github.com/rogerio-castellano/inventory-tracker/internal/redissvc"
 	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+	"github.com/rogerio-castellano/inventory-tracker/internal/security"
+	"github.com/rogerio-castellano/inventory-tracker/internal/storage"
+	"github.com/rogerio-castellano/inventory-tracker/internal/usage"
+	"github.com/rogerio-castellano/inventory-tracker/internal/webhook"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -28,6 +33,7 @@ var (
 	productRepo  *repo.PostgresProductRepository
 	movementRepo *repo.PostgresMovementRepository
 	userRepo     *repo.PostgresUserRepository
+	webhookRepo  *repo.PostgresWebhookRepository
 	database     *sql.DB
 )
 
@@ -54,6 +60,7 @@ func setupTestRepos(password string) {
 	redisService := redissvc.NewRedisService(rdb, ctx)
 	handlers.SetRedisService(redisService)
 	mw.SetRedisService(redisService)
+	usage.SetRedisService(redisService)
 
 	dbUrl := os.Getenv("DATABASE_URL")
 	if dbUrl == "" {
@@ -67,6 +74,20 @@ func setupTestRepos(password string) {
 
 	productRepo = repo.NewPostgresProductRepository(database)
 	handlers.SetProductRepo(productRepo)
+	handlers.SetProductChangeRepo(repo.NewPostgresProductChangeRepository(database))
+	handlers.SetWarehouseRepo(repo.NewPostgresWarehouseRepository(database))
+	handlers.SetReservationRepo(repo.NewPostgresReservationRepository(database))
+	handlers.SetSupplierRepo(repo.NewPostgresSupplierRepository(database))
+	webhookRepo = repo.NewPostgresWebhookRepository(database)
+	handlers.SetWebhookRepo(webhookRepo)
+	webhook.SetRepo(webhookRepo)
+	handlers.SetLoginEventRepo(repo.NewPostgresLoginEventRepository(database))
+	handlers.SetAccessGrantRepo(repo.NewPostgresAccessGrantRepository(database))
+	handlers.SetApprovalRepo(repo.NewPostgresApprovalRepository(database))
+	handlers.SetExportRecipientRepo(repo.NewPostgresExportRecipientRepository(database))
+	handlers.SetAdjustmentLimitRepo(repo.NewPostgresAdjustmentLimitRepository(database))
+	handlers.SetPriceRulesRepo(repo.NewPostgresPriceRulesRepository(database))
+	handlers.SetProductFlagRepo(repo.NewPostgresProductFlagRepository(database))
 
 	movementRepo = repo.NewPostgresMovementRepository(database)
 	handlers.SetMovementRepo(movementRepo)
@@ -80,6 +101,50 @@ func setupTestRepos(password string) {
 
 	metricsRepo := repo.NewPostgresMetricsRepository(database)
 	handlers.SetMetricsRepo(metricsRepo)
+
+	handlers.SetPeriodRepo(repo.NewPostgresPeriodRepository(database))
+	handlers.SetUsageRepo(repo.NewPostgresUsageRepository(database))
+	handlers.SetAuditRepo(repo.NewPostgresAuditRepository(database))
+	handlers.SetSeedingEnabled(true)
+	handlers.SetSandboxEnabled(true)
+	handlers.SetQueryDiagnosticsEnabled(true)
+
+	handlers.SetDocumentRepo(repo.NewPostgresDocumentRepository(database))
+	handlers.SetMovementAttachmentRepo(repo.NewPostgresMovementAttachmentRepository(database))
+	incidentRepo := repo.NewPostgresIncidentRepository(database)
+	handlers.SetIncidentRepo(incidentRepo)
+	incident.Start(incidentRepo)
+	eventLogRepo := repo.NewPostgresEventLogRepository(database)
+	handlers.SetEventLogRepo(eventLogRepo)
+	security.SetOutbox(eventLogRepo)
+	documentDir, err := os.MkdirTemp("", "inventory-documents-")
+	if err != nil {
+		log.Fatal("❌ Could not create document directory:", err)
+	}
+	documentStore, err := storage.NewLocalStore(documentDir)
+	if err != nil {
+		log.Fatal("❌ Could not prepare document storage:", err)
+	}
+	handlers.SetDocumentStore(documentStore)
+
+	handlers.SetLotRepo(repo.NewPostgresLotRepository(database))
+	handlers.SetWriteOffRepo(repo.NewPostgresWriteOffRepository(database))
+	handlers.SetReturnRepo(repo.NewPostgresReturnRepository(database))
+	handlers.SetWorkOrderRepo(repo.NewPostgresWorkOrderRepository(database))
+	handlers.SetSyncRepo(repo.NewPostgresSyncRepository(database))
+	handlers.SetMappingRepo(repo.NewPostgresMappingRepository(database))
+	handlers.SetSnapshotRepo(repo.NewPostgresSnapshotRepository(database))
+	handlers.SetTaxClassRepo(repo.NewPostgresTaxClassRepository(database))
+	handlers.SetValidationPolicyRepo(repo.NewPostgresValidationPolicyRepository(database))
+	handlers.SetRuntimeConfigRepo(repo.NewPostgresRuntimeConfigRepository(database))
+	handlers.SetSubstituteRepo(repo.NewPostgresSubstituteRepository(database))
+	handlers.SetPriceListRepo(repo.NewPostgresPriceListRepository(database))
+	handlers.SetPromotionRepo(repo.NewPostgresPromotionRepository(database))
+	handlers.SetBinRepo(repo.NewPostgresBinRepository(database))
+	handlers.SetCapacityRepo(repo.NewPostgresCapacityRepository(database))
+	handlers.SetASNRepo(repo.NewPostgresASNRepository(database))
+	handlers.SetLandedCostRepo(repo.NewPostgresLandedCostRepository(database))
+	handlers.SetConsignmentRepo(repo.NewPostgresConsignmentRepository(database))
 }
 
 func createAdminIfNotExists(password string) error {
@@ -124,6 +189,21 @@ func userRoleToken(r http.Handler) (string, error) {
 	return token, nil
 }
 
+func roleToken(r http.Handler, username, role string) (string, error) {
+	password := "secret-password"
+	hash, _ := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	_, err := userRepo.CreateUser(models.User{
+		Username:     username,
+		PasswordHash: string(hash),
+		Role:         role,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return generateToken(r, username, password)
+}
+
 func generateToken(r http.Handler, username, password string) (string, error) {
 	payload := handlers.CredentialsRequest{Username: username, Password: password}
 	body, _ := json.Marshal(payload)
@@ -201,3 +281,241 @@ func addMovement(m models.Movement) {
 		log.Println("Error adding a movement %w", err)
 	}
 }
+
+func clearAllPeriods() {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := database.ExecContext(ctx, "TRUNCATE TABLE accounting_periods RESTART IDENTITY")
+	if err != nil {
+		fmt.Println(fmt.Errorf("failed to truncate accounting_periods table: %w", err))
+	}
+}
+
+func clearAuditLog() {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := database.ExecContext(ctx, "TRUNCATE TABLE audit_log RESTART IDENTITY")
+	if err != nil {
+		fmt.Println(fmt.Errorf("failed to truncate audit_log table: %w", err))
+	}
+}
+
+func clearSnapshots() {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := database.ExecContext(ctx, "TRUNCATE TABLE inventory_snapshots RESTART IDENTITY")
+	if err != nil {
+		fmt.Println(fmt.Errorf("failed to truncate inventory_snapshots table: %w", err))
+	}
+}
+
+// clearTaxClasses must run after clearAllProducts: classes in use cannot be deleted.
+func clearTaxClasses() {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := database.ExecContext(ctx, "DELETE FROM tax_classes")
+	if err != nil {
+		fmt.Println(fmt.Errorf("failed to clear tax_classes table: %w", err))
+	}
+}
+
+func clearValidationPolicies() {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := database.ExecContext(ctx, "DELETE FROM validation_policies")
+	if err != nil {
+		fmt.Println(fmt.Errorf("failed to clear validation_policies table: %w", err))
+	}
+}
+
+// clearRuntimeConfigs drops applied configurations and restores the built-in
+// roles and rate limits.
+func clearRuntimeConfigs() {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := database.ExecContext(ctx, "DELETE FROM runtime_configs")
+	if err != nil {
+		fmt.Println(fmt.Errorf("failed to clear runtime_configs table: %w", err))
+	}
+	if err := handlers.LoadRuntimeConfig(); err != nil {
+		fmt.Println(fmt.Errorf("failed to restore runtime config: %w", err))
+	}
+}
+
+func clearPriceLists() {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := database.ExecContext(ctx, "DELETE FROM price_lists")
+	if err != nil {
+		fmt.Println(fmt.Errorf("failed to clear price_lists table: %w", err))
+	}
+}
+
+func clearPromotions() {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := database.ExecContext(ctx, "DELETE FROM promotions")
+	if err != nil {
+		fmt.Println(fmt.Errorf("failed to clear promotions table: %w", err))
+	}
+}
+
+func clearCapacities() {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := database.ExecContext(ctx, "DELETE FROM bin_capacities")
+	if err != nil {
+		fmt.Println(fmt.Errorf("failed to clear bin_capacities table: %w", err))
+	}
+}
+
+func clearASNs() {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := database.ExecContext(ctx, "DELETE FROM asns")
+	if err != nil {
+		fmt.Println(fmt.Errorf("failed to clear asns table: %w", err))
+	}
+}
+
+func clearConsignments() {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := database.ExecContext(ctx, "DELETE FROM consignment_consumptions; DELETE FROM consignment_stock")
+	if err != nil {
+		fmt.Println(fmt.Errorf("failed to clear consignment tables: %w", err))
+	}
+}
+
+func clearIncidents() {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := database.ExecContext(ctx, "DELETE FROM incidents")
+	if err != nil {
+		fmt.Println(fmt.Errorf("failed to clear incidents table: %w", err))
+	}
+}
+
+func clearEventLog() {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := database.ExecContext(ctx, "DELETE FROM event_log")
+	if err != nil {
+		fmt.Println(fmt.Errorf("failed to clear event log: %w", err))
+	}
+}
+
+// clearWarehouses must run after clearAllProducts: warehouses holding stock
+// cannot be deleted.
+func clearWarehouses() {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := database.ExecContext(ctx, "DELETE FROM warehouses")
+	if err != nil {
+		fmt.Println(fmt.Errorf("failed to clear warehouses table: %w", err))
+	}
+}
+
+func clearSuppliers() {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := database.ExecContext(ctx, "DELETE FROM suppliers")
+	if err != nil {
+		fmt.Println(fmt.Errorf("failed to clear suppliers table: %w", err))
+	}
+}
+
+func clearLoginEvents() {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := database.ExecContext(ctx, "DELETE FROM login_events")
+	if err != nil {
+		fmt.Println(fmt.Errorf("failed to clear login_events table: %w", err))
+	}
+}
+
+func clearAccessGrants() {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := database.ExecContext(ctx, "DELETE FROM access_grants")
+	if err != nil {
+		fmt.Println(fmt.Errorf("failed to clear access_grants table: %w", err))
+	}
+}
+
+func clearApprovals() {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := database.ExecContext(ctx, "DELETE FROM approvals")
+	if err != nil {
+		fmt.Println(fmt.Errorf("failed to clear approvals table: %w", err))
+	}
+}
+
+func clearExportRecipients() {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := database.ExecContext(ctx, "DELETE FROM export_recipients")
+	if err != nil {
+		fmt.Println(fmt.Errorf("failed to clear export_recipients table: %w", err))
+	}
+}
+
+func clearAdjustmentLimits() {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := database.ExecContext(ctx, "DELETE FROM adjustment_limits")
+	if err != nil {
+		fmt.Println(fmt.Errorf("failed to clear adjustment_limits table: %w", err))
+	}
+}
+
+func clearPriceRules() {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := database.ExecContext(ctx, "DELETE FROM price_rules")
+	if err != nil {
+		fmt.Println(fmt.Errorf("failed to clear price_rules table: %w", err))
+	}
+}
+
+func clearProductFlags() {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := database.ExecContext(ctx, "DELETE FROM product_flags")
+	if err != nil {
+		fmt.Println(fmt.Errorf("failed to clear product_flags table: %w", err))
+	}
+}
+
+func clearWebhooks() {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := database.ExecContext(ctx, "DELETE FROM webhooks")
+	if err != nil {
+		fmt.Println(fmt.Errorf("failed to clear webhooks table: %w", err))
+	}
+}
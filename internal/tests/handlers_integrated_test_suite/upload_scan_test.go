@@ -0,0 +1,113 @@
+package handlers_integrated_test_suite
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/avscan"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+)
+
+// eicar is the standard antivirus test string, which scanners report as
+// infected.
+const eicar = `X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`
+
+// stubScanner flags files holding the EICAR string, or fails when down.
+type stubScanner struct{ down bool }
+
+func (s stubScanner) Name() string { return "stub" }
+
+func (s stubScanner) Scan(_ string, r io.Reader) (avscan.Verdict, error) {
+	if s.down {
+		return avscan.Verdict{}, errors.New("scanner unreachable")
+	}
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return avscan.Verdict{}, err
+	}
+	if bytes.Contains(content, []byte("EICAR-STANDARD-ANTIVIRUS-TEST-FILE")) {
+		return avscan.Verdict{Infected: true, Threat: "Eicar-Test-Signature"}, nil
+	}
+	return avscan.Verdict{}, nil
+}
+
+func TestUploadScanning(t *testing.T) {
+	t.Cleanup(func() {
+		handlers.SetFileScanner(avscan.Nop{})
+		clearAllProducts()
+	})
+	r := router.NewRouter()
+
+	upload := func(path, filename, content string) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		writer := multipart.NewWriter(&buf)
+		part, err := writer.CreateFormFile("file", filename)
+		if err != nil {
+			t.Fatalf("fail to create form file %v: %v", filename, err)
+		}
+		_, _ = part.Write([]byte(content))
+		_ = writer.Close()
+
+		req := httptest.NewRequest(http.MethodPost, path, &buf)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	handlers.SetFileScanner(stubScanner{})
+	t.Run("Clean import goes through", func(t *testing.T) {
+		if w := upload("/products/import", "products.csv", "name,price,quantity\nScanned,1.50,3\n"); w.Code != http.StatusOK {
+			t.Errorf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("Infected import is rejected", func(t *testing.T) {
+		w := upload("/products/import", "products.csv", "name,price,quantity\n"+eicar+",1.50,3\n")
+		if w.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("expected 422 Unprocessable Entity, got %d: %s", w.Code, w.Body.String())
+		}
+		var resp handlers.ErrorResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if resp.Code != handlers.ErrCodeInfectedFile {
+			t.Errorf("expected code %s, got %+v", handlers.ErrCodeInfectedFile, resp)
+		}
+	})
+
+	t.Run("Rejection is audited", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/admin/audit?action=reject_upload", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		var page handlers.AuditLogPage
+		if err := json.NewDecoder(w.Body).Decode(&page); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(page.Entries) == 0 || page.Entries[0].EntityID != "products.csv" {
+			t.Errorf("expected an audit entry for products.csv, got %+v", page.Entries)
+		}
+	})
+
+	t.Run("Infected user import is rejected", func(t *testing.T) {
+		if w := upload("/admin/users/import", "users.csv", "username,email,role\n"+eicar+",x@example.com,user\n"); w.Code != http.StatusUnprocessableEntity {
+			t.Errorf("expected 422 Unprocessable Entity, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	handlers.SetFileScanner(stubScanner{down: true})
+	t.Run("Nothing gets through unscanned", func(t *testing.T) {
+		if w := upload("/products/import", "products.csv", "name,price,quantity\nUnscanned,1.50,3\n"); w.Code != http.StatusServiceUnavailable {
+			t.Errorf("expected 503 Service Unavailable, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+}
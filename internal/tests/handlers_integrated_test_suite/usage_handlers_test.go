@@ -0,0 +1,61 @@
+package handlers_integrated_test_suite
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+)
+
+func TestUsageHandlers(t *testing.T) {
+	r := router.NewRouter()
+
+	getUsage := func(path string) (int, handlers.UsageReport) {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		var report handlers.UsageReport
+		if w.Code == http.StatusOK {
+			if err := json.NewDecoder(w.Body).Decode(&report); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+		}
+		return w.Code, report
+	}
+
+	t.Run("Own usage counts authenticated requests", func(t *testing.T) {
+		_, before := getUsage("/me/usage")
+		code, after := getUsage("/me/usage")
+		if code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d", code)
+		}
+		if after.Totals.Requests <= before.Totals.Requests {
+			t.Errorf("expected request count to grow, got %d then %d", before.Totals.Requests, after.Totals.Requests)
+		}
+		if after.User != "admin" {
+			t.Errorf("expected usage for admin, got %q", after.User)
+		}
+	})
+
+	t.Run("Admin usage filtered by user", func(t *testing.T) {
+		code, report := getUsage("/admin/usage?user=admin")
+		if code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d", code)
+		}
+		if len(report.ByUser) != 1 || report.ByUser[0].Username != "admin" {
+			t.Errorf("expected only admin usage, got %v", report.ByUser)
+		}
+	})
+
+	t.Run("Invalid month", func(t *testing.T) {
+		code, _ := getUsage("/admin/usage?month=2025-13")
+		if code != http.StatusBadRequest {
+			t.Errorf("expected 400 Bad Request, got %d", code)
+		}
+	})
+}
@@ -0,0 +1,147 @@
+package handlers_integrated_test_suite
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/auth"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+)
+
+func TestUserAccessChanges(t *testing.T) {
+	t.Cleanup(func() {
+		handlers.Rdb.Del(handlers.Ctx, auth.RevokedBeforeKey("demoted"))
+		clearLoginEvents()
+		clearAllProducts()
+		clearAllUsersExceptAdmin()
+	})
+	r := router.NewRouter()
+
+	send := func(method, path, bearer string, body any) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		if body != nil {
+			_ = json.NewEncoder(&buf).Encode(body)
+		}
+		req := httptest.NewRequest(method, path, &buf)
+		if bearer != "" {
+			req.Header.Set("Authorization", "Bearer "+bearer)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	login := func() (*httptest.ResponseRecorder, handlers.LoginResult) {
+		w := send(http.MethodPost, "/login", "", handlers.CredentialsRequest{Username: "demoted", Password: "secret-password"})
+		var result handlers.LoginResult
+		if w.Code == http.StatusOK {
+			if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+				t.Fatalf("failed to decode login: %v", err)
+			}
+		}
+		return w, result
+	}
+
+	if _, err := roleToken(r, "demoted", "warehouse"); err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	_, session := login()
+	if w := send(http.MethodGet, "/me", session.AccessToken, nil); w.Code != http.StatusOK {
+		t.Fatalf("expected 200 OK, got %d", w.Code)
+	}
+
+	t.Run("Role change revokes tokens", func(t *testing.T) {
+		w := send(http.MethodPut, "/admin/users/demoted/role", token, handlers.RoleChangeRequest{Role: "guest"})
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		if w := send(http.MethodGet, "/me", session.AccessToken, nil); w.Code != http.StatusUnauthorized {
+			t.Errorf("expected the old access token to be rejected, got %d", w.Code)
+		}
+		w = send(http.MethodPost, "/refresh", "", handlers.RefreshRequest{Username: "demoted", RefreshToken: session.RefreshToken})
+		if w.Code == http.StatusOK {
+			t.Error("expected the old refresh token to be rejected")
+		}
+
+		// Logging in again right away yields a token the revocation does
+		// not cover.
+		w, session = login()
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d", w.Code)
+		}
+		w = send(http.MethodGet, "/me", session.AccessToken, nil)
+		var me handlers.MeResponse
+		if err := json.NewDecoder(w.Body).Decode(&me); err != nil {
+			t.Fatalf("failed to decode /me: %v", err)
+		}
+		if me.Role != "guest" {
+			t.Errorf("expected the new token to carry role guest, got %q", me.Role)
+		}
+	})
+
+	t.Run("Revoked tokens are not trusted on public routes", func(t *testing.T) {
+		createProduct(r, handlers.ProductRequest{Name: "Revocation probe", Price: 10, Quantity: 1})
+		if w := send(http.MethodPut, "/admin/users/demoted/role", token, handlers.RoleChangeRequest{Role: "user"}); w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		_, promoted := login()
+		if w := send(http.MethodGet, "/products", promoted.AccessToken, nil); !strings.Contains(w.Body.String(), `"price"`) {
+			t.Fatalf("expected prices for role user, got %s", w.Body.String())
+		}
+
+		if w := send(http.MethodPut, "/admin/users/demoted/role", token, handlers.RoleChangeRequest{Role: "guest"}); w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		w := send(http.MethodGet, "/products", promoted.AccessToken, nil)
+		if w.Code != http.StatusOK || strings.Contains(w.Body.String(), `"price"`) {
+			t.Errorf("expected the revoked token to be served as a guest, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("Disabled accounts cannot log in", func(t *testing.T) {
+		if w := send(http.MethodPost, "/admin/users/demoted/disable", token, nil); w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		if w := send(http.MethodGet, "/me", session.AccessToken, nil); w.Code != http.StatusUnauthorized {
+			t.Errorf("expected the access token to be rejected, got %d", w.Code)
+		}
+		if w, _ := login(); w.Code != http.StatusForbidden {
+			t.Errorf("expected 403 Forbidden, got %d", w.Code)
+		}
+
+		if w := send(http.MethodPost, "/admin/users/demoted/enable", token, nil); w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		w, session := login()
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK after enabling, got %d", w.Code)
+		}
+		if w := send(http.MethodGet, "/me", session.AccessToken, nil); w.Code != http.StatusOK {
+			t.Errorf("expected the new access token to be accepted at once, got %d", w.Code)
+		}
+	})
+
+	cases := []struct {
+		name   string
+		method string
+		path   string
+		body   any
+		code   int
+	}{
+		{"Unknown role", http.MethodPut, "/admin/users/demoted/role", handlers.RoleChangeRequest{Role: "overlord"}, http.StatusBadRequest},
+		{"Unknown user", http.MethodPut, "/admin/users/nobody-here/role", handlers.RoleChangeRequest{Role: "user"}, http.StatusNotFound},
+		{"Own role", http.MethodPut, "/admin/users/admin/role", handlers.RoleChangeRequest{Role: "user"}, http.StatusConflict},
+		{"Own account", http.MethodPost, "/admin/users/admin/disable", nil, http.StatusConflict},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if w := send(c.method, c.path, token, c.body); w.Code != c.code {
+				t.Errorf("expected %d, got %d: %s", c.code, w.Code, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package handlers_integrated_test_suite
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+)
+
+func TestImportUsersHandler(t *testing.T) {
+	t.Cleanup(clearAllUsersExceptAdmin)
+	r := router.NewRouter()
+
+	importUsers := func(csvData string) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		writer := multipart.NewWriter(&buf)
+		part, err := writer.CreateFormFile("file", "users.csv")
+		if err != nil {
+			t.Fatalf("fail to create form file: %v", err)
+		}
+		_, _ = part.Write([]byte(csvData))
+		writer.Close()
+
+		req := httptest.NewRequest(http.MethodPost, "/admin/users/import", &buf)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	post := func(path string, body any) *httptest.ResponseRecorder {
+		raw, _ := json.Marshal(body)
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(raw))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	var resp handlers.UserImportResult
+	t.Run("Per-row validation", func(t *testing.T) {
+		w := importUsers(`username,email,role
+picker1,picker1@example.com,warehouse
+picker2,,user
+x,short@example.com,user
+picker3,not-an-email,user
+picker4,picker4@example.com,overlord
+PICKER1,again@example.com,user`)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(resp.Created) != 2 || resp.Created[0].Username != "picker1" || resp.Created[0].TemporaryPassword == "" {
+			t.Errorf("expected picker1 and picker2 created, got %+v", resp.Created)
+		}
+		rows := map[int]bool{}
+		for _, e := range resp.Errors {
+			rows[e.Row] = true
+		}
+		for _, row := range []int{4, 5, 6, 7} {
+			if !rows[row] {
+				t.Errorf("expected row %d to be rejected, got %+v", row, resp.Errors)
+			}
+		}
+	})
+
+	t.Run("Taken usernames are reported", func(t *testing.T) {
+		w := importUsers("username,email,role\npicker1,picker1@example.com,user\n")
+		var again handlers.UserImportResult
+		if err := json.NewDecoder(w.Body).Decode(&again); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(again.Created) != 0 || len(again.Errors) != 1 {
+			t.Errorf("expected the existing user to be rejected, got %+v", again)
+		}
+	})
+
+	t.Run("Password change forced on first login", func(t *testing.T) {
+		created := resp.Created[0]
+		if w := post("/login", handlers.CredentialsRequest{Username: created.Username, Password: created.TemporaryPassword}); w.Code != http.StatusForbidden {
+			t.Fatalf("expected 403 Forbidden with the temporary password, got %d", w.Code)
+		}
+		change := handlers.ChangePasswordRequest{Username: created.Username, CurrentPassword: created.TemporaryPassword, NewPassword: created.TemporaryPassword}
+		if w := post("/password/change", change); w.Code != http.StatusBadRequest {
+			t.Errorf("expected 400 Bad Request reusing the temporary password, got %d", w.Code)
+		}
+		change.NewPassword = "picking-season"
+		if w := post("/password/change", change); w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		if w := post("/login", handlers.CredentialsRequest{Username: created.Username, Password: "picking-season"}); w.Code != http.StatusOK {
+			t.Errorf("expected 200 OK with the new password, got %d", w.Code)
+		}
+	})
+
+	t.Run("Missing column", func(t *testing.T) {
+		if w := importUsers("username,role\npicker5,user\n"); w.Code != http.StatusBadRequest {
+			t.Errorf("expected 400 Bad Request, got %d", w.Code)
+		}
+	})
+}
@@ -0,0 +1,102 @@
+package handlers_integrated_test_suite
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+func TestValidationPolicyHandlers(t *testing.T) {
+	t.Cleanup(clearValidationPolicies)
+	t.Cleanup(clearAllProducts)
+	r := router.NewRouter()
+
+	putPolicy := func(fields []string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(handlers.ValidationPolicyRequest{RequiredFields: fields})
+		req := httptest.NewRequest(http.MethodPut, "/admin/validation-policy", bytes.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Set policy", func(t *testing.T) {
+		w := putPolicy([]string{" SKU ", "category", "sku"})
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		var policy models.ValidationPolicy
+		if err := json.NewDecoder(w.Body).Decode(&policy); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if strings.Join(policy.RequiredFields, ",") != "sku,category" {
+			t.Errorf("unexpected required fields: %v", policy.RequiredFields)
+		}
+	})
+
+	t.Run("Unknown field", func(t *testing.T) {
+		if w := putPolicy([]string{"colour"}); w.Code != http.StatusBadRequest {
+			t.Errorf("expected 400 Bad Request, got %d", w.Code)
+		}
+	})
+
+	t.Run("Create without required fields", func(t *testing.T) {
+		w := createProduct(r, handlers.ProductRequest{Name: "Drill", Price: 90, SKU: "DR-1"})
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400 Bad Request, got %d: %s", w.Code, w.Body.String())
+		}
+		var errs []handlers.ProductValidationError
+		if err := json.NewDecoder(w.Body).Decode(&errs); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(errs) != 1 || errs[0].Field != "Category" {
+			t.Errorf("expected a Category error, got %+v", errs)
+		}
+	})
+
+	t.Run("Create with required fields", func(t *testing.T) {
+		w := createProduct(r, handlers.ProductRequest{Name: "Drill", Price: 90, SKU: "DR-1", Category: "Tools"})
+		if w.Code != http.StatusCreated {
+			t.Errorf("expected 201 Created, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("Import rejects rows without required fields", func(t *testing.T) {
+		var buf bytes.Buffer
+		writer := multipart.NewWriter(&buf)
+		part, _ := writer.CreateFormFile("file", "products.csv")
+		_, _ = part.Write([]byte("name,price,quantity,sku,category\nSaw,30,2,SA-1,Tools\nHammer,15,4,,Tools"))
+		writer.Close()
+
+		req := httptest.NewRequest(http.MethodPost, "/products/import", &buf)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		var resp handlers.ImportProductsResult
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if resp.ImportedProductsCount != 1 || len(resp.Errors) != 1 || resp.Errors[0].Field != "SKU" {
+			t.Errorf("unexpected import result: %+v", resp)
+		}
+	})
+
+	t.Run("Clearing the policy lifts the requirements", func(t *testing.T) {
+		if w := putPolicy(nil); w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d", w.Code)
+		}
+		if w := createProduct(r, handlers.ProductRequest{Name: "Level", Price: 12}); w.Code != http.StatusCreated {
+			t.Errorf("expected 201 Created, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+}
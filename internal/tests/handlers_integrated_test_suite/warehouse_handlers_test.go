@@ -0,0 +1,162 @@
+package handlers_integrated_test_suite
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+func TestWarehouseHandlers(t *testing.T) {
+	t.Cleanup(func() {
+		clearAllProducts()
+		clearWarehouses()
+	})
+	r := router.NewRouter()
+
+	send := func(method, path string, body any) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		if body != nil {
+			_ = json.NewEncoder(&buf).Encode(body)
+		}
+		req := httptest.NewRequest(method, path, &buf)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	newWarehouse := func(name string) int {
+		w := send(http.MethodPost, "/warehouses", handlers.WarehouseRequest{Name: name})
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected 201 Created, got %d: %s", w.Code, w.Body.String())
+		}
+		var wh models.Warehouse
+		if err := json.NewDecoder(w.Body).Decode(&wh); err != nil {
+			t.Fatalf("failed to decode warehouse: %v", err)
+		}
+		return wh.ID
+	}
+	north := newWarehouse("North")
+	south := newWarehouse("South")
+
+	w := createProduct(r, handlers.ProductRequest{Name: "Pallet", Price: 10, Quantity: 5})
+	var product handlers.ProductResponse
+	if err := json.NewDecoder(w.Body).Decode(&product); err != nil {
+		t.Fatalf("failed to decode product: %v", err)
+	}
+	productPath := fmt.Sprintf("/products/%d", product.Id)
+
+	t.Run("Adjust stock in warehouses", func(t *testing.T) {
+		for _, adj := range []handlers.QuantityAdjustmentRequest{
+			{Delta: 4, WarehouseID: &north},
+			{Delta: 3, WarehouseID: &south},
+			{Delta: -1, WarehouseID: &north},
+		} {
+			if w := adjustProduct(r, product.Id, adj); w.Code != http.StatusOK {
+				t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+			}
+		}
+		w := send(http.MethodPost, productPath+"/adjust/batch", []handlers.QuantityAdjustmentRequest{{Delta: 2, WarehouseID: &south}, {Delta: -1}})
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+
+		w = send(http.MethodGet, productPath, nil)
+		var p handlers.ProductResponse
+		if err := json.NewDecoder(w.Body).Decode(&p); err != nil {
+			t.Fatalf("failed to decode product: %v", err)
+		}
+		// 5 + 4 + 3 - 1 + 2 - 1
+		if p.Quantity != 12 {
+			t.Errorf("expected a total of 12, got %d", p.Quantity)
+		}
+		want := []models.WarehouseStock{{WarehouseID: north, Warehouse: "North", Quantity: 3}, {WarehouseID: south, Warehouse: "South", Quantity: 5}}
+		if fmt.Sprint(p.Warehouses) != fmt.Sprint(want) {
+			t.Errorf("expected %+v, got %+v", want, p.Warehouses)
+		}
+	})
+
+	t.Run("Movements record the warehouse", func(t *testing.T) {
+		w := send(http.MethodGet, productPath+"/movements", nil)
+		var result handlers.MovementsSearchResult
+		if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+			t.Fatalf("failed to decode movements: %v", err)
+		}
+		located := 0
+		for _, m := range result.Data {
+			if m.WarehouseID != nil {
+				located++
+			}
+		}
+		if located != 4 {
+			t.Errorf("expected 4 movements with a warehouse, got %d of %+v", located, result.Data)
+		}
+	})
+
+	t.Run("Warehouse stock cannot go negative", func(t *testing.T) {
+		if w := adjustProduct(r, product.Id, handlers.QuantityAdjustmentRequest{Delta: -4, WarehouseID: &north}); w.Code != http.StatusConflict {
+			t.Errorf("expected 409 Conflict, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("Unknown warehouse", func(t *testing.T) {
+		unknown := 999999
+		if w := adjustProduct(r, product.Id, handlers.QuantityAdjustmentRequest{Delta: 1, WarehouseID: &unknown}); w.Code != http.StatusNotFound {
+			t.Errorf("expected 404 Not Found, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("Rename", func(t *testing.T) {
+		w := send(http.MethodPut, fmt.Sprintf("/warehouses/%d", north), handlers.WarehouseRequest{Name: "North Depot", Address: "1 Quay St"})
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		w = send(http.MethodGet, fmt.Sprintf("/warehouses/%d", north), nil)
+		var wh models.Warehouse
+		if err := json.NewDecoder(w.Body).Decode(&wh); err != nil {
+			t.Fatalf("failed to decode warehouse: %v", err)
+		}
+		if wh.Name != "North Depot" || wh.Address != "1 Quay St" {
+			t.Errorf("unexpected warehouse: %+v", wh)
+		}
+	})
+
+	t.Run("List", func(t *testing.T) {
+		w := send(http.MethodGet, "/warehouses", nil)
+		var warehouses []models.Warehouse
+		if err := json.NewDecoder(w.Body).Decode(&warehouses); err != nil {
+			t.Fatalf("failed to decode warehouses: %v", err)
+		}
+		if len(warehouses) != 2 {
+			t.Errorf("expected 2 warehouses, got %+v", warehouses)
+		}
+	})
+
+	empty := newWarehouse("Overflow")
+	cases := []struct {
+		name   string
+		method string
+		path   string
+		body   any
+		code   int
+	}{
+		{"Missing name", http.MethodPost, "/warehouses", handlers.WarehouseRequest{}, http.StatusBadRequest},
+		{"Duplicate name", http.MethodPost, "/warehouses", handlers.WarehouseRequest{Name: "south"}, http.StatusConflict},
+		{"Unknown warehouse", http.MethodGet, "/warehouses/999999", nil, http.StatusNotFound},
+		{"Delete warehouse holding stock", http.MethodDelete, fmt.Sprintf("/warehouses/%d", south), nil, http.StatusConflict},
+		{"Delete empty warehouse", http.MethodDelete, fmt.Sprintf("/warehouses/%d", empty), nil, http.StatusNoContent},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if w := send(c.method, c.path, c.body); w.Code != c.code {
+				t.Errorf("expected %d, got %d: %s", c.code, w.Code, w.Body.String())
+			}
+		})
+	}
+}
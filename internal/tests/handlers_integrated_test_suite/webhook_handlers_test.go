@@ -0,0 +1,230 @@
+package handlers_integrated_test_suite
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+	"github.com/rogerio-castellano/inventory-tracker/internal/webhook"
+)
+
+func TestWebhookHandlers(t *testing.T) {
+	t.Cleanup(func() {
+		clearWebhooks()
+		clearAllProducts()
+	})
+	r := router.NewRouter()
+
+	var mu sync.Mutex
+	received := map[string][]webhook.Event{}
+	signatures := map[string]string{}
+	bodies := map[string][]byte{}
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var e webhook.Event
+		_ = json.Unmarshal(body, &e)
+		mu.Lock()
+		received[e.Type] = append(received[e.Type], e)
+		signatures[e.ID] = r.Header.Get("X-Signature-256")
+		bodies[e.ID] = body
+		mu.Unlock()
+	}))
+	defer receiver.Close()
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+
+	send := func(method, path string, body any) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		if body != nil {
+			_ = json.NewEncoder(&buf).Encode(body)
+		}
+		req := httptest.NewRequest(method, path, &buf)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	subscribe := func(url string, events ...string) models.Webhook {
+		w := send(http.MethodPost, "/admin/webhooks", handlers.WebhookRequest{URL: url, Events: events})
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected 201 Created, got %d: %s", w.Code, w.Body.String())
+		}
+		var hook models.Webhook
+		if err := json.NewDecoder(w.Body).Decode(&hook); err != nil {
+			t.Fatalf("failed to decode webhook: %v", err)
+		}
+		return hook
+	}
+	deliveries := func(id int, query string) []models.WebhookDelivery {
+		w := send(http.MethodGet, fmt.Sprintf("/admin/webhooks/%d/deliveries%s", id, query), nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		var ds []models.WebhookDelivery
+		if err := json.NewDecoder(w.Body).Decode(&ds); err != nil {
+			t.Fatalf("failed to decode deliveries: %v", err)
+		}
+		return ds
+	}
+
+	hook := subscribe(receiver.URL, webhook.EventProductCreated, webhook.EventStockLow, webhook.EventMovementCreated)
+	if hook.Secret == "" {
+		t.Fatal("expected the secret on creation")
+	}
+	down := subscribe(failing.URL, webhook.EventStockLow)
+
+	w := createProduct(r, handlers.ProductRequest{Name: "Hooked", Price: 10, Quantity: 5, Threshold: 3})
+	var product handlers.ProductResponse
+	if err := json.NewDecoder(w.Body).Decode(&product); err != nil {
+		t.Fatalf("failed to decode product: %v", err)
+	}
+	for _, delta := range []int{-3, -1} {
+		if w := adjustProduct(r, product.Id, handlers.QuantityAdjustmentRequest{Delta: delta}); w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+	}
+	if _, err := webhook.DeliverDue(webhookRepo, time.Now().UTC()); err != nil {
+		t.Fatalf("failed to deliver: %v", err)
+	}
+
+	t.Run("Events are delivered signed", func(t *testing.T) {
+		mu.Lock()
+		defer mu.Unlock()
+		want := map[string]int{webhook.EventProductCreated: 1, webhook.EventMovementCreated: 2, webhook.EventStockLow: 1}
+		for eventType, n := range want {
+			if len(received[eventType]) != n {
+				t.Errorf("expected %d %s events, got %d", n, eventType, len(received[eventType]))
+			}
+		}
+		for id, signature := range signatures {
+			if signature != "sha256="+webhook.Sign(hook.Secret, bodies[id]) {
+				t.Errorf("event %s has a bad signature %q", id, signature)
+			}
+		}
+		if ds := deliveries(hook.ID, "?status=delivered"); len(ds) != 4 {
+			t.Errorf("expected 4 delivered deliveries, got %+v", ds)
+		}
+	})
+
+	t.Run("Failed deliveries stay pending for a retry", func(t *testing.T) {
+		ds := deliveries(down.ID, "")
+		if len(ds) != 1 {
+			t.Fatalf("expected 1 delivery, got %+v", ds)
+		}
+		d := ds[0]
+		if d.Status != models.WebhookDeliveryPending || d.Attempts != 1 || d.ResponseStatus != http.StatusServiceUnavailable || !d.NextAttemptAt.After(time.Now()) {
+			t.Errorf("unexpected delivery: %+v", d)
+		}
+	})
+
+	t.Run("Secrets are not listed", func(t *testing.T) {
+		w := send(http.MethodGet, "/admin/webhooks", nil)
+		var hooks []models.Webhook
+		if err := json.NewDecoder(w.Body).Decode(&hooks); err != nil {
+			t.Fatalf("failed to decode webhooks: %v", err)
+		}
+		for _, h := range hooks {
+			if h.Secret != "" {
+				t.Errorf("webhook %d lists its secret", h.ID)
+			}
+		}
+	})
+
+	sendNow := func(t *testing.T, path string) models.WebhookDelivery {
+		t.Helper()
+		w := send(http.MethodPost, path, nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		var d models.WebhookDelivery
+		if err := json.NewDecoder(w.Body).Decode(&d); err != nil {
+			t.Fatalf("failed to decode delivery: %v", err)
+		}
+		return d
+	}
+
+	t.Run("A test event is sent right away", func(t *testing.T) {
+		d := sendNow(t, fmt.Sprintf("/admin/webhooks/%d/test", hook.ID))
+		if d.Status != models.WebhookDeliveryDelivered || d.EventType != webhook.EventTest || d.Attempts != 1 {
+			t.Errorf("unexpected delivery: %+v", d)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if len(received[webhook.EventTest]) != 1 {
+			t.Fatalf("expected 1 test event, got %d", len(received[webhook.EventTest]))
+		}
+		if signatures[d.EventID] != "sha256="+webhook.Sign(hook.Secret, bodies[d.EventID]) {
+			t.Errorf("test event has a bad signature %q", signatures[d.EventID])
+		}
+	})
+
+	t.Run("A failed test stays pending", func(t *testing.T) {
+		d := sendNow(t, fmt.Sprintf("/admin/webhooks/%d/test", down.ID))
+		if d.Status != models.WebhookDeliveryPending || d.ResponseStatus != http.StatusServiceUnavailable || d.LastError == "" {
+			t.Errorf("unexpected delivery: %+v", d)
+		}
+	})
+
+	t.Run("A recorded delivery is redelivered as a new one", func(t *testing.T) {
+		var original models.WebhookDelivery
+		for _, d := range deliveries(hook.ID, "?status=delivered") {
+			if d.EventType == webhook.EventStockLow {
+				original = d
+			}
+		}
+		if original.ID == 0 {
+			t.Fatal("expected a delivered stock.low delivery")
+		}
+		d := sendNow(t, fmt.Sprintf("/admin/webhooks/%d/deliveries/%d/redeliver", hook.ID, original.ID))
+		if d.ID == original.ID || d.EventID != original.EventID || d.Status != models.WebhookDeliveryDelivered {
+			t.Errorf("unexpected redelivery of %+v: %+v", original, d)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if len(received[webhook.EventStockLow]) != 2 {
+			t.Errorf("expected the stock.low event received twice, got %d", len(received[webhook.EventStockLow]))
+		}
+	})
+
+	pending := deliveries(down.ID, "?status=pending")
+	if len(pending) == 0 {
+		t.Fatal("expected pending deliveries to the failing webhook")
+	}
+
+	cases := []struct {
+		name   string
+		method string
+		path   string
+		body   any
+		code   int
+	}{
+		{"Unknown event", http.MethodPost, "/admin/webhooks", handlers.WebhookRequest{URL: receiver.URL, Events: []string{"product.deleted"}}, http.StatusBadRequest},
+		{"No events", http.MethodPost, "/admin/webhooks", handlers.WebhookRequest{URL: receiver.URL}, http.StatusBadRequest},
+		{"Relative URL", http.MethodPost, "/admin/webhooks", handlers.WebhookRequest{URL: "/hook", Events: []string{webhook.EventStockLow}}, http.StatusBadRequest},
+		{"Invalid status filter", http.MethodGet, fmt.Sprintf("/admin/webhooks/%d/deliveries?status=lost", hook.ID), nil, http.StatusBadRequest},
+		{"Unknown webhook", http.MethodGet, "/admin/webhooks/999999/deliveries", nil, http.StatusNotFound},
+		{"Test an unknown webhook", http.MethodPost, "/admin/webhooks/999999/test", nil, http.StatusNotFound},
+		{"Redeliver a pending delivery", http.MethodPost, fmt.Sprintf("/admin/webhooks/%d/deliveries/%d/redeliver", down.ID, pending[0].ID), nil, http.StatusConflict},
+		{"Redeliver another webhook's delivery", http.MethodPost, fmt.Sprintf("/admin/webhooks/%d/deliveries/%d/redeliver", hook.ID, pending[0].ID), nil, http.StatusNotFound},
+		{"Delete", http.MethodDelete, fmt.Sprintf("/admin/webhooks/%d", down.ID), nil, http.StatusNoContent},
+		{"Delete again", http.MethodDelete, fmt.Sprintf("/admin/webhooks/%d", down.ID), nil, http.StatusNotFound},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if w := send(c.method, c.path, c.body); w.Code != c.code {
+				t.Errorf("expected %d, got %d: %s", c.code, w.Code, w.Body.String())
+			}
+		})
+	}
+}
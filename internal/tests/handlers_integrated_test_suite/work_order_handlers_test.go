@@ -0,0 +1,124 @@
+package handlers_integrated_test_suite
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+)
+
+func TestWorkOrderHandlers(t *testing.T) {
+	t.Cleanup(clearAllProducts)
+	r := router.NewRouter()
+
+	newProduct := func(name string, quantity int) int {
+		w := createProduct(r, handlers.ProductRequest{Name: name, Price: 5.0, Quantity: quantity})
+		var product handlers.ProductResponse
+		if err := json.NewDecoder(w.Body).Decode(&product); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return product.Id
+	}
+	kit := newProduct("Gift box", 0)
+	mug := newProduct("Mug", 10)
+	tea := newProduct("Tea pack", 5)
+
+	post := func(url string, body any) *httptest.ResponseRecorder {
+		payload, _ := json.Marshal(body)
+		req := httptest.NewRequest(http.MethodPost, url, bytes.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	create := func(quantity int) models.WorkOrder {
+		w := post("/work-orders", handlers.WorkOrderRequest{
+			ProductID: kit,
+			Quantity:  quantity,
+			Components: []models.WorkOrderComponent{
+				{ProductID: mug, QuantityPerUnit: 1},
+				{ProductID: tea, QuantityPerUnit: 2},
+			},
+		})
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected 201 Created, got %d: %s", w.Code, w.Body.String())
+		}
+		var wo models.WorkOrder
+		if err := json.NewDecoder(w.Body).Decode(&wo); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return wo
+	}
+	quantity := func(id int) int {
+		p, err := productRepo.GetByID(id)
+		if err != nil {
+			t.Fatalf("failed to fetch product %d: %v", id, err)
+		}
+		return p.Quantity
+	}
+
+	t.Run("Shortage leaves stock untouched", func(t *testing.T) {
+		wo := create(3)
+		w := post(fmt.Sprintf("/work-orders/%d/complete", wo.ID), nil)
+		if w.Code != http.StatusConflict {
+			t.Fatalf("expected 409 Conflict, got %d: %s", w.Code, w.Body.String())
+		}
+		var resp handlers.ShortageResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		want := []repo.Shortage{{ProductID: tea, Required: 6, Available: 5}}
+		if resp.Code != handlers.ErrCodeShortage || len(resp.Shortages) != 1 || resp.Shortages[0].Shortage != want[0] {
+			t.Errorf("unexpected shortage response: %+v", resp)
+		}
+		if quantity(mug) != 10 || quantity(tea) != 5 || quantity(kit) != 0 {
+			t.Errorf("stock changed despite the shortage")
+		}
+	})
+
+	t.Run("Complete consumes components and produces the kit", func(t *testing.T) {
+		wo := create(2)
+		w := post(fmt.Sprintf("/work-orders/%d/complete", wo.ID), nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		var done models.WorkOrder
+		if err := json.NewDecoder(w.Body).Decode(&done); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if done.Status != models.WorkOrderStatusCompleted || done.CompletedAt == nil {
+			t.Errorf("unexpected work order: %+v", done)
+		}
+		if quantity(mug) != 8 || quantity(tea) != 1 || quantity(kit) != 2 {
+			t.Errorf("unexpected stock: mug=%d tea=%d kit=%d", quantity(mug), quantity(tea), quantity(kit))
+		}
+
+		movements, _, err := movementRepo.GetByProductID(kit, repo.MovementFilter{})
+		if err != nil || len(movements) != 1 || movements[0].WorkOrderID == nil || *movements[0].WorkOrderID != wo.ID {
+			t.Errorf("expected one movement tagged with work order %d, got %+v (%v)", wo.ID, movements, err)
+		}
+
+		if w := post(fmt.Sprintf("/work-orders/%d/complete", wo.ID), nil); w.Code != http.StatusConflict {
+			t.Errorf("expected 409 Conflict completing twice, got %d", w.Code)
+		}
+	})
+
+	t.Run("Rejects the kit as its own component", func(t *testing.T) {
+		w := post("/work-orders", handlers.WorkOrderRequest{
+			ProductID:  kit,
+			Quantity:   1,
+			Components: []models.WorkOrderComponent{{ProductID: kit, QuantityPerUnit: 1}},
+		})
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected 400 Bad Request, got %d", w.Code)
+		}
+	})
+}
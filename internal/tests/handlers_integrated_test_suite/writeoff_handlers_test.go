@@ -0,0 +1,120 @@
+package handlers_integrated_test_suite
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/handlers"
+	"github.com/rogerio-castellano/inventory-tracker/internal/http/router"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+)
+
+func TestWriteOffHandlers(t *testing.T) {
+	t.Cleanup(clearAllProducts)
+	r := router.NewRouter()
+
+	newProduct := func(p handlers.ProductRequest) int {
+		w := createProduct(r, p)
+		var product handlers.ProductResponse
+		if err := json.NewDecoder(w.Body).Decode(&product); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return product.Id
+	}
+	milk := newProduct(handlers.ProductRequest{Name: "Milk", Price: 2.0, Quantity: 20, Category: "dairy"})
+	bread := newProduct(handlers.ProductRequest{Name: "Bread", Price: 3.0, Quantity: 10, Category: "bakery"})
+
+	writeOff := func(id int, req handlers.WriteOffRequest) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(req)
+		httpReq := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/products/%d/write-off", id), bytes.NewReader(body))
+		httpReq.Header.Set("Content-Type", "application/json")
+		httpReq.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httpReq)
+		return w
+	}
+
+	t.Run("Write off at product price", func(t *testing.T) {
+		w := writeOff(milk, handlers.WriteOffRequest{Quantity: 5, Reason: "expired"})
+		if w.Code != http.StatusCreated {
+			t.Fatalf("expected 201 Created, got %d: %s", w.Code, w.Body.String())
+		}
+		var wo models.WriteOff
+		if err := json.NewDecoder(w.Body).Decode(&wo); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if wo.TotalCost != 10.0 || wo.Category != "dairy" || wo.CreatedBy != "admin" {
+			t.Errorf("unexpected write-off: %+v", wo)
+		}
+
+		p, err := productRepo.GetByID(milk)
+		if err != nil || p.Quantity != 15 {
+			t.Errorf("expected quantity 15 after write-off, got %d (%v)", p.Quantity, err)
+		}
+	})
+
+	t.Run("Write off with explicit cost", func(t *testing.T) {
+		cost := 1.25
+		if w := writeOff(bread, handlers.WriteOffRequest{Quantity: 4, Reason: "damaged", UnitCost: &cost}); w.Code != http.StatusCreated {
+			t.Fatalf("expected 201 Created, got %d: %s", w.Code, w.Body.String())
+		}
+		if w := writeOff(milk, handlers.WriteOffRequest{Quantity: 1, Reason: "damaged"}); w.Code != http.StatusCreated {
+			t.Fatalf("expected 201 Created, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("Rejects invalid write-offs", func(t *testing.T) {
+		cases := []struct {
+			req  handlers.WriteOffRequest
+			code int
+		}{
+			{handlers.WriteOffRequest{Quantity: 1, Reason: "bored"}, http.StatusBadRequest},
+			{handlers.WriteOffRequest{Quantity: 0, Reason: "expired"}, http.StatusBadRequest},
+			{handlers.WriteOffRequest{Quantity: 100, Reason: "expired"}, http.StatusConflict},
+		}
+		for _, c := range cases {
+			if w := writeOff(milk, c.req); w.Code != c.code {
+				t.Errorf("%+v: expected %d, got %d", c.req, c.code, w.Code)
+			}
+		}
+	})
+
+	t.Run("Waste report", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/reports/waste", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+		}
+		var report handlers.WasteReport
+		if err := json.NewDecoder(w.Body).Decode(&report); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if report.Quantity != 10 || report.WrittenOffValue != 17.0 {
+			t.Errorf("unexpected totals: %+v", report)
+		}
+		if len(report.ByReason) != 2 || report.ByReason[0].Name != "expired" || report.ByReason[0].WrittenOffValue != 10.0 {
+			t.Errorf("unexpected reasons: %+v", report.ByReason)
+		}
+		if len(report.ByCategory) != 2 || report.ByCategory[0].Name != "dairy" || report.ByCategory[0].WrittenOffValue != 12.0 {
+			t.Errorf("unexpected categories: %+v", report.ByCategory)
+		}
+	})
+
+	t.Run("Invalid since", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/reports/waste?since=yesterday", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected 400 Bad Request, got %d", w.Code)
+		}
+	})
+}
@@ -0,0 +1,266 @@
+// Package usage counts API traffic per authenticated user. Counters live in
+// Redis for the current day and are periodically folded into Postgres.
+package usage
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rogerio-castellano/inventory-tracker/internal/jobs"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+	"github.com/rogerio-castellano/inventory-tracker/internal/redissvc"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+)
+
+const (
+	keyPrefix = "usage:"
+	dayLayout = "2006-01-02"
+	// keyTTL keeps live counters around long enough for the aggregator to
+	// pick them up even after a few missed runs.
+	keyTTL = 7 * 24 * time.Hour
+
+	activityKeyPrefix = "activity:"
+	// activityTTL bounds how far back the last request of a user is remembered.
+	activityTTL = 24 * time.Hour
+
+	sessionActivityKeyPrefix = "session_activity:"
+
+	// aggregateLockKey keeps instances from folding the same counters twice.
+	// It sits outside keyPrefix so Aggregate never mistakes it for a counter.
+	aggregateLockKey = "lock:usage_aggregation"
+	aggregateLockTTL = 5 * time.Minute
+)
+
+// releaseLock deletes the lock only while it still holds our token, so a run
+// that outlived the TTL cannot drop a lock another instance has since taken.
+var releaseLock = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+var (
+	rdb redis.UniversalClient
+	ctx context.Context
+)
+
+func SetRedisService(rs *redissvc.RedisService) {
+	rdb = rs.Rdb()
+	ctx = rs.Ctx()
+}
+
+func dailyKey(day, username string) string {
+	return fmt.Sprintf("%s%s:%s", keyPrefix, day, username)
+}
+
+// Today returns the current usage day identifier.
+func Today() string {
+	return time.Now().UTC().Format(dayLayout)
+}
+
+// Record adds one request and its payload sizes to the user's counters for today.
+func Record(username string, bytesIn, bytesOut int64) error {
+	key := dailyKey(Today(), username)
+
+	pipe := rdb.TxPipeline()
+	pipe.HIncrBy(ctx, key, "requests", 1)
+	pipe.HIncrBy(ctx, key, "bytes_in", bytesIn)
+	pipe.HIncrBy(ctx, key, "bytes_out", bytesOut)
+	pipe.Expire(ctx, key, keyTTL)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// Live returns the counters not yet aggregated for a user and day.
+func Live(username, day string) (models.UsageRecord, error) {
+	values, err := rdb.HGetAll(ctx, dailyKey(day, username)).Result()
+	if err != nil {
+		return models.UsageRecord{}, err
+	}
+	return toRecord(username, day, values), nil
+}
+
+// LiveAll returns the counters not yet aggregated for every user on a day.
+func LiveAll(day string) ([]models.UsageRecord, error) {
+	keys, err := redissvc.Keys(ctx, rdb, keyPrefix+day+":*")
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]models.UsageRecord, 0, len(keys))
+	for _, key := range keys {
+		username := strings.TrimPrefix(key, keyPrefix+day+":")
+		r, err := Live(username, day)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, r)
+	}
+	return records, nil
+}
+
+func toRecord(username, day string, values map[string]string) models.UsageRecord {
+	parse := func(field string) int64 {
+		v, _ := strconv.ParseInt(values[field], 10, 64)
+		return v
+	}
+	return models.UsageRecord{
+		Username: username,
+		Day:      day,
+		Requests: parse("requests"),
+		BytesIn:  parse("bytes_in"),
+		BytesOut: parse("bytes_out"),
+	}
+}
+
+// Aggregate moves every completed day's counters from Redis into the
+// repository. Runs are serialized across instances with a Redis lock; when
+// another instance holds it, Aggregate returns without doing anything.
+func Aggregate(usageRepo repo.UsageRepository) error {
+	token := rand.Text()
+	acquired, err := rdb.SetNX(ctx, aggregateLockKey, token, aggregateLockTTL).Result()
+	if err != nil {
+		return err
+	}
+	if !acquired {
+		return nil
+	}
+	defer func() {
+		if err := releaseLock.Run(ctx, rdb, []string{aggregateLockKey}, token).Err(); err != nil {
+			log.Printf("⚠️ Failed to release the usage aggregation lock: %v", err)
+		}
+	}()
+
+	keys, err := redissvc.Keys(ctx, rdb, keyPrefix+"*")
+	if err != nil {
+		return err
+	}
+
+	today := Today()
+	for _, key := range keys {
+		day, username, ok := strings.Cut(strings.TrimPrefix(key, keyPrefix), ":")
+		if !ok || day >= today {
+			continue
+		}
+
+		values, err := rdb.HGetAll(ctx, key).Result()
+		if err != nil {
+			return err
+		}
+		if err := usageRepo.AddDaily(toRecord(username, day, values)); err != nil {
+			return fmt.Errorf("failed to store usage for %s on %s: %w", username, day, err)
+		}
+		if err := rdb.Del(ctx, key).Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StartAggregator periodically folds completed days into the repository.
+func StartAggregator(usageRepo repo.UsageRepository, interval time.Duration) {
+	jobs.Register("usage_aggregation", "every "+interval.String())
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		<-ticker.C
+		err := Aggregate(usageRepo)
+		if err != nil {
+			log.Printf("⚠️ Failed to aggregate API usage: %v", err)
+		}
+		jobs.Record("usage_aggregation", err)
+	}
+}
+
+// Touch remembers a as the user's latest request.
+func Touch(a models.Activity) error {
+	key := activityKeyPrefix + a.Username
+	pipe := rdb.TxPipeline()
+	pipe.HSet(ctx, key, map[string]any{
+		"at":         a.At.UTC().Format(time.RFC3339Nano),
+		"request":    a.Request,
+		"ip_address": a.IPAddress,
+		"user_agent": a.UserAgent,
+	})
+	pipe.Expire(ctx, key, activityTTL)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func sessionActivityKey(username, sessionKey string) string {
+	return sessionActivityKeyPrefix + username + ":" + sessionKey
+}
+
+// TouchSession marks a session as used now. The key lives for idleTimeout,
+// so a session is idle exactly when its key is gone.
+func TouchSession(username, sessionKey string, idleTimeout time.Duration) error {
+	return rdb.Set(ctx, sessionActivityKey(username, sessionKey), time.Now().UTC().Format(time.RFC3339Nano), idleTimeout).Err()
+}
+
+// SessionActive reports whether the session was used within the idle
+// timeout it was last touched with.
+func SessionActive(username, sessionKey string) (bool, error) {
+	n, err := rdb.Exists(ctx, sessionActivityKey(username, sessionKey)).Result()
+	return n > 0, err
+}
+
+// ActiveSince returns the latest request of every user seen at or after since,
+// most recent first.
+func ActiveSince(since time.Time) ([]models.Activity, error) {
+	keys, err := redissvc.Keys(ctx, rdb, activityKeyPrefix+"*")
+	if err != nil {
+		return nil, err
+	}
+
+	activities := []models.Activity{}
+	for _, key := range keys {
+		values, err := rdb.HGetAll(ctx, key).Result()
+		if err != nil {
+			return nil, err
+		}
+		at, err := time.Parse(time.RFC3339Nano, values["at"])
+		if err != nil || at.Before(since) {
+			continue
+		}
+		activities = append(activities, models.Activity{
+			Username:  strings.TrimPrefix(key, activityKeyPrefix),
+			At:        at,
+			Request:   values["request"],
+			IPAddress: values["ip_address"],
+			UserAgent: values["user_agent"],
+		})
+	}
+	sort.Slice(activities, func(i, j int) bool { return activities[i].At.After(activities[j].At) })
+	return activities, nil
+}
+
+// Forget drops every live counter and the last activity of a user and their
+// sessions.
+func Forget(username string) error {
+	keys, err := redissvc.Keys(ctx, rdb, keyPrefix+"*:"+username)
+	if err != nil {
+		return err
+	}
+	sessions, err := redissvc.Keys(ctx, rdb, sessionActivityKeyPrefix+username+":*")
+	if err != nil {
+		return err
+	}
+	keys = append(keys, sessions...)
+	keys = append(keys, activityKeyPrefix+username)
+
+	// One DEL per key: on a Cluster the keys may live in different slots.
+	pipe := rdb.Pipeline()
+	for _, key := range keys {
+		pipe.Del(ctx, key)
+	}
+	_, err = pipe.Exec(ctx)
+	return err
+}
@@ -0,0 +1,263 @@
+// Package webhook delivers inventory events to the URLs admins subscribed to
+// them. Events are stored as deliveries before they are sent, so they survive
+// restarts and are retried with backoff until the receiver accepts them.
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/rogerio-castellano/inventory-tracker/internal/jobs"
+	"github.com/rogerio-castellano/inventory-tracker/internal/models"
+	"github.com/rogerio-castellano/inventory-tracker/internal/repo"
+)
+
+const (
+	EventProductCreated  = "product.created"
+	EventStockLow        = "stock.low"
+	EventMovementCreated = "movement.created"
+)
+
+// EventTypes lists the events webhooks can subscribe to.
+var EventTypes = []string{EventProductCreated, EventStockLow, EventMovementCreated}
+
+// EventTest is sent on request to check a webhook; nothing subscribes to it.
+const EventTest = "webhook.test"
+
+// Event is the JSON body POSTed to webhooks.
+type Event struct {
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Data       any       `json:"data"`
+}
+
+const (
+	// MaxAttempts is how many times a delivery is tried before it is marked
+	// failed.
+	MaxAttempts = 6
+
+	dispatchJob = "webhook_delivery"
+	// claimBatch deliveries are claimed at a time, for claimLease: long
+	// enough to try them all one after another at the client timeout.
+	claimBatch = 20
+	claimLease = 5 * time.Minute
+)
+
+// backoff is the wait after the given failed attempt: 30s, 2m, 8m, 32m and
+// about 2h.
+func backoff(attempt int) time.Duration {
+	return 30 * time.Second << (2 * (attempt - 1))
+}
+
+var (
+	mu     sync.RWMutex
+	store  repo.WebhookRepository
+	client = &http.Client{Timeout: 10 * time.Second}
+	wake   = make(chan struct{}, 1)
+)
+
+// SetRepo sets where deliveries are queued; until it is set, Publish does
+// nothing.
+func SetRepo(r repo.WebhookRepository) {
+	mu.Lock()
+	defer mu.Unlock()
+	store = r
+}
+
+// Publish queues an event of eventType for each item of data to every webhook
+// subscribed to it, and wakes the dispatcher. Failing to queue is logged
+// rather than returned: the change that raised the event has already
+// happened.
+func Publish(eventType string, data ...any) {
+	mu.RLock()
+	s := store
+	mu.RUnlock()
+	if s == nil || len(data) == 0 {
+		return
+	}
+
+	subscribers, err := s.Subscribed(eventType)
+	if err != nil {
+		log.Printf("Failed to look up webhooks for %s: %v", eventType, err)
+		return
+	}
+	if len(subscribers) == 0 {
+		return
+	}
+
+	now := time.Now().UTC()
+	deliveries := make([]models.WebhookDelivery, 0, len(data)*len(subscribers))
+	for _, item := range data {
+		e := Event{ID: newEventID(), Type: eventType, OccurredAt: now, Data: item}
+		payload, err := json.Marshal(e)
+		if err != nil {
+			log.Printf("Failed to encode %s event: %v", eventType, err)
+			continue
+		}
+		for _, w := range subscribers {
+			deliveries = append(deliveries, models.WebhookDelivery{WebhookID: w.ID, EventID: e.ID, EventType: eventType, Payload: payload, NextAttemptAt: now})
+		}
+	}
+	if err := s.Enqueue(deliveries); err != nil {
+		log.Printf("Failed to queue %s webhook deliveries: %v", eventType, err)
+		return
+	}
+
+	select {
+	case wake <- struct{}{}:
+	default:
+	}
+}
+
+// StartDispatcher delivers due deliveries every interval, and right away
+// when events are published.
+func StartDispatcher(webhooks repo.WebhookRepository, interval time.Duration) {
+	jobs.Register(dispatchJob, "every "+interval.String()+" and on new events")
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		_, err := DeliverDue(webhooks, time.Now().UTC())
+		if err != nil {
+			log.Printf("⚠️ Failed to deliver webhooks: %v", err)
+		}
+		jobs.Record(dispatchJob, err)
+		select {
+		case <-ticker.C:
+		case <-wake:
+		}
+	}
+}
+
+// DeliverDue attempts every delivery due at now and returns how many were
+// attempted.
+func DeliverDue(webhooks repo.WebhookRepository, now time.Time) (int, error) {
+	attempted := 0
+	targets := map[int]models.Webhook{}
+	for {
+		due, err := webhooks.ClaimDue(now, claimLease, claimBatch)
+		if err != nil {
+			return attempted, err
+		}
+		for _, d := range due {
+			w, ok := targets[d.WebhookID]
+			if !ok {
+				// A webhook deleted meanwhile takes its deliveries with it.
+				if w, err = webhooks.GetByID(d.WebhookID); err != nil {
+					continue
+				}
+				targets[w.ID] = w
+			}
+			if err := webhooks.RecordAttempt(attempt(w, d, now)); err != nil {
+				return attempted, err
+			}
+			attempted++
+		}
+		if len(due) < claimBatch {
+			return attempted, nil
+		}
+	}
+}
+
+// Test sends a webhook.test event to w right away; see Send.
+func Test(webhooks repo.WebhookRepository, w models.Webhook) (models.WebhookDelivery, error) {
+	e := Event{ID: newEventID(), Type: EventTest, OccurredAt: time.Now().UTC(), Data: map[string]int{"webhook_id": w.ID}}
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return models.WebhookDelivery{}, err
+	}
+	return Send(webhooks, w, models.WebhookDelivery{EventID: e.ID, EventType: e.Type, Payload: payload})
+}
+
+// Redeliver sends the event of a recorded delivery to w again, as a new
+// delivery. The event ID is kept so receivers that deduplicate on
+// X-Webhook-Delivery recognize it.
+func Redeliver(webhooks repo.WebhookRepository, w models.Webhook, d models.WebhookDelivery) (models.WebhookDelivery, error) {
+	return Send(webhooks, w, models.WebhookDelivery{EventID: d.EventID, EventType: d.EventType, Payload: d.Payload})
+}
+
+// Send stores d as a new delivery to w and attempts it at once, returning
+// the outcome. A failed attempt leaves it pending for the dispatcher to
+// retry like any other.
+func Send(webhooks repo.WebhookRepository, w models.Webhook, d models.WebhookDelivery) (models.WebhookDelivery, error) {
+	now := time.Now().UTC()
+	d.WebhookID = w.ID
+	// Leased as if claimed, so the dispatcher leaves it to this attempt.
+	d.NextAttemptAt = now.Add(claimLease)
+	d, err := webhooks.AddDelivery(d)
+	if err != nil {
+		return models.WebhookDelivery{}, err
+	}
+	d = attempt(w, d, now)
+	return d, webhooks.RecordAttempt(d)
+}
+
+// attempt sends d to w once and returns d updated with the outcome.
+func attempt(w models.Webhook, d models.WebhookDelivery, now time.Time) models.WebhookDelivery {
+	d.Attempts++
+	status, err := post(w, d)
+	d.ResponseStatus = status
+	if err == nil {
+		delivered := time.Now().UTC()
+		d.Status = models.WebhookDeliveryDelivered
+		d.LastError = ""
+		d.DeliveredAt = &delivered
+		return d
+	}
+
+	d.LastError = err.Error()
+	if d.Attempts >= MaxAttempts {
+		d.Status = models.WebhookDeliveryFailed
+		jobs.Exhausted(jobs.Failure{Job: dispatchJob, Reference: fmt.Sprintf("%s event %s to webhook %d", d.EventType, d.EventID, w.ID), Attempts: d.Attempts, Err: err})
+		return d
+	}
+	d.NextAttemptAt = now.Add(backoff(d.Attempts))
+	return d
+}
+
+func post(w models.Webhook, d models.WebhookDelivery) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, w.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-Event", d.EventType)
+	req.Header.Set("X-Webhook-Delivery", d.EventID)
+	req.Header.Set("X-Signature-256", "sha256="+Sign(w.Secret, d.Payload))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("webhook answered %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// Sign returns the hex HMAC-SHA256 of body with secret, as sent in
+// X-Signature-256 after "sha256=".
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func newEventID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}